@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -36,13 +37,19 @@ import (
 	applicationctl "github.com/horizoncd/horizon/core/controller/application"
 	applicationregionctl "github.com/horizoncd/horizon/core/controller/applicationregion"
 	badgectl "github.com/horizoncd/horizon/core/controller/badge"
+	batchoperationctl "github.com/horizoncd/horizon/core/controller/batchoperation"
 	"github.com/horizoncd/horizon/core/controller/build"
 	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	clusterconfigctl "github.com/horizoncd/horizon/core/controller/clusterconfig"
 	codectl "github.com/horizoncd/horizon/core/controller/code"
+	configctl "github.com/horizoncd/horizon/core/controller/config"
+	costctl "github.com/horizoncd/horizon/core/controller/cost"
+	deprecatedapictl "github.com/horizoncd/horizon/core/controller/deprecatedapi"
 	environmentctl "github.com/horizoncd/horizon/core/controller/environment"
 	environmentregionctl "github.com/horizoncd/horizon/core/controller/environmentregion"
 	envtemplatectl "github.com/horizoncd/horizon/core/controller/envtemplate"
 	eventctl "github.com/horizoncd/horizon/core/controller/event"
+	gittriggerctl "github.com/horizoncd/horizon/core/controller/gittrigger"
 	groupctl "github.com/horizoncd/horizon/core/controller/group"
 	idpctl "github.com/horizoncd/horizon/core/controller/idp"
 	memberctl "github.com/horizoncd/horizon/core/controller/member"
@@ -50,12 +57,17 @@ import (
 	oauthappctl "github.com/horizoncd/horizon/core/controller/oauthapp"
 	oauthcheckctl "github.com/horizoncd/horizon/core/controller/oauthcheck"
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	policyctl "github.com/horizoncd/horizon/core/controller/policy"
+	previewctl "github.com/horizoncd/horizon/core/controller/preview"
+	quotactl "github.com/horizoncd/horizon/core/controller/quota"
 	regionctl "github.com/horizoncd/horizon/core/controller/region"
 	registryctl "github.com/horizoncd/horizon/core/controller/registry"
+	rightsizingctl "github.com/horizoncd/horizon/core/controller/rightsizing"
 	roltctl "github.com/horizoncd/horizon/core/controller/role"
 	scopectl "github.com/horizoncd/horizon/core/controller/scope"
 	tagctl "github.com/horizoncd/horizon/core/controller/tag"
 	templatectl "github.com/horizoncd/horizon/core/controller/template"
+	templatemigrationctl "github.com/horizoncd/horizon/core/controller/templatemigration"
 	templateschematagctl "github.com/horizoncd/horizon/core/controller/templateschematag"
 	terminalctl "github.com/horizoncd/horizon/core/controller/terminal"
 	userctl "github.com/horizoncd/horizon/core/controller/user"
@@ -86,21 +98,32 @@ import (
 	accesstokenv2 "github.com/horizoncd/horizon/core/http/api/v2/accesstoken"
 	applicationregionv2 "github.com/horizoncd/horizon/core/http/api/v2/applicationregion"
 	"github.com/horizoncd/horizon/core/http/api/v2/badge"
+	batchoperationv2 "github.com/horizoncd/horizon/core/http/api/v2/batchoperation"
 	clusterv2 "github.com/horizoncd/horizon/core/http/api/v2/cluster"
+	clusterconfigv2 "github.com/horizoncd/horizon/core/http/api/v2/clusterconfig"
 	codev2 "github.com/horizoncd/horizon/core/http/api/v2/code"
+	configv2 "github.com/horizoncd/horizon/core/http/api/v2/config"
+	costv2 "github.com/horizoncd/horizon/core/http/api/v2/cost"
+	deprecatedapiv2 "github.com/horizoncd/horizon/core/http/api/v2/deprecatedapi"
 	environmentv2 "github.com/horizoncd/horizon/core/http/api/v2/environment"
 	environmentregionv2 "github.com/horizoncd/horizon/core/http/api/v2/environmentregion"
 	eventv2 "github.com/horizoncd/horizon/core/http/api/v2/event"
+	gittriggerv2 "github.com/horizoncd/horizon/core/http/api/v2/gittrigger"
 	groupv2 "github.com/horizoncd/horizon/core/http/api/v2/group"
 	idpv2 "github.com/horizoncd/horizon/core/http/api/v2/idp"
 	memberv2 "github.com/horizoncd/horizon/core/http/api/v2/member"
 	oauthappv2 "github.com/horizoncd/horizon/core/http/api/v2/oauthapp"
 	pipelinerunv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinerun"
+	policyv2 "github.com/horizoncd/horizon/core/http/api/v2/policy"
+	previewv2 "github.com/horizoncd/horizon/core/http/api/v2/preview"
+	quotav2 "github.com/horizoncd/horizon/core/http/api/v2/quota"
 	regionv2 "github.com/horizoncd/horizon/core/http/api/v2/region"
 	registryv2 "github.com/horizoncd/horizon/core/http/api/v2/registry"
+	rightsizingv2 "github.com/horizoncd/horizon/core/http/api/v2/rightsizing"
 	rolev2 "github.com/horizoncd/horizon/core/http/api/v2/role"
 	scopev2 "github.com/horizoncd/horizon/core/http/api/v2/scope"
 	tagv2 "github.com/horizoncd/horizon/core/http/api/v2/tag"
+	templatemigrationv2 "github.com/horizoncd/horizon/core/http/api/v2/templatemigration"
 	templateschematagv2 "github.com/horizoncd/horizon/core/http/api/v2/templateschematag"
 	terminalv2 "github.com/horizoncd/horizon/core/http/api/v2/terminal"
 	userv2 "github.com/horizoncd/horizon/core/http/api/v2/user"
@@ -108,28 +131,47 @@ import (
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/core/middleware/auth"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/blob"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	"github.com/horizoncd/horizon/lib/s3"
 	"github.com/horizoncd/horizon/pkg/admission"
+	"github.com/horizoncd/horizon/pkg/argocd"
 	"github.com/horizoncd/horizon/pkg/cd"
 	clustermetrcis "github.com/horizoncd/horizon/pkg/cluster/metrics"
 	admissionconfig "github.com/horizoncd/horizon/pkg/config/admission"
+	autofreeconfig "github.com/horizoncd/horizon/pkg/config/autofree"
+	costconfig "github.com/horizoncd/horizon/pkg/config/cost"
+	deprecatedapiconfig "github.com/horizoncd/horizon/pkg/config/deprecatedapi"
+	gittriggerconfig "github.com/horizoncd/horizon/pkg/config/gittrigger"
+	hibernationconfig "github.com/horizoncd/horizon/pkg/config/hibernation"
+	previewconfig "github.com/horizoncd/horizon/pkg/config/preview"
+	rightsizingconfig "github.com/horizoncd/horizon/pkg/config/rightsizing"
+	templateresourceconfig "github.com/horizoncd/horizon/pkg/config/templateresource"
+	deprecatedapiservice "github.com/horizoncd/horizon/pkg/deprecatedapi/service"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	"github.com/horizoncd/horizon/pkg/jobs"
 	"github.com/horizoncd/horizon/pkg/jobs/autofree"
 	"github.com/horizoncd/horizon/pkg/jobs/clean"
+	costjob "github.com/horizoncd/horizon/pkg/jobs/cost"
+	deprecatedapijob "github.com/horizoncd/horizon/pkg/jobs/deprecatedapi"
 	"github.com/horizoncd/horizon/pkg/jobs/eventhandler"
 	"github.com/horizoncd/horizon/pkg/jobs/grafanasync"
+	"github.com/horizoncd/horizon/pkg/jobs/hibernation"
 	"github.com/horizoncd/horizon/pkg/jobs/k8sevent"
+	"github.com/horizoncd/horizon/pkg/jobs/rightsizing"
 	jobwebhook "github.com/horizoncd/horizon/pkg/jobs/webhook"
+	policyservice "github.com/horizoncd/horizon/pkg/policy/service"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
 	"github.com/horizoncd/horizon/pkg/token/generator"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	tokenstore "github.com/horizoncd/horizon/pkg/token/store"
 	"github.com/horizoncd/horizon/pkg/util/kube"
 	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/generic"
 
 	templateschematagapi "github.com/horizoncd/horizon/core/http/api/v1/templateschematag"
 	terminalapi "github.com/horizoncd/horizon/core/http/api/v1/terminal"
@@ -149,6 +191,7 @@ import (
 	scopemiddle "github.com/horizoncd/horizon/core/middleware/scope"
 	tagmiddle "github.com/horizoncd/horizon/core/middleware/tag"
 	tokenmiddle "github.com/horizoncd/horizon/core/middleware/token"
+	tracemiddle "github.com/horizoncd/horizon/core/middleware/trace"
 	usermiddle "github.com/horizoncd/horizon/core/middleware/user"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/pkg/application/gitrepo"
@@ -161,6 +204,7 @@ import (
 	oauthconfig "github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
 	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	terminalconfig "github.com/horizoncd/horizon/pkg/config/terminal"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
@@ -170,11 +214,13 @@ import (
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/rbac"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschemarepo "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
@@ -194,6 +240,9 @@ type Flags struct {
 	Dev                 bool
 	Environment         string
 	LogLevel            string
+	// ConfigReloadInterval is the interval to check whether the config, roles and scopes files
+	// and the cd mappings of regions change
+	ConfigReloadInterval time.Duration
 }
 
 type RegisterRouter interface {
@@ -228,6 +277,9 @@ func ParseFlags() *Flags {
 	flag.StringVar(
 		&flags.LogLevel, "loglevel", "info", "the loglevel(panic/fatal/error/warn/info/debug/trace))")
 
+	flag.DurationVar(&flags.ConfigReloadInterval, "config-reload-interval", 10*time.Second,
+		"the interval to check whether the config, roles and scopes files and the cd mappings of regions change")
+
 	flag.Parse()
 	return &flags
 }
@@ -274,6 +326,19 @@ func LoadConfig(flags *Flags) (*config.Config, error) {
 }
 
 func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
+	// init trace
+	shutdownTrace, err := trace.Init(ctx, coreConfig.TraceConfig)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := shutdownTrace(context.Background()); err != nil {
+			log.Printf("failed to shutdown trace, err: %v", err)
+		}
+	}()
+
+	reloader := config.NewReloader(flags.ConfigFile, coreConfig)
+
 	// init roles
 	file, err := os.OpenFile(flags.RoleConfigFile, os.O_RDONLY, 0644)
 	if err != nil {
@@ -283,11 +348,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	if err != nil {
 		panic(err)
 	}
-	var roleConfig roleconfig.Config
-	if err := yaml.Unmarshal(content, &roleConfig); err != nil {
+	fileRoleService, err := newRoleService(content)
+	if err != nil {
 		panic(err)
 	}
-	log.Printf("the roleConfig = %+v\n", roleConfig)
+	roleService := role.NewReloadableService(fileRoleService)
 
 	// init db
 	mysqlDB, err := orm.NewMySQLDB(&orm.MySQL{
@@ -370,6 +435,8 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		panic(err)
 	}
 
+	migrationGetter := migration.NewGetter(templateRepo, manager)
+
 	gitGetter, err := code.NewGitGetter(ctx, coreConfig.CodeGitRepos)
 	if err != nil {
 		panic(err)
@@ -378,6 +445,15 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	if err != nil {
 		panic(err)
 	}
+	argoCDFty := argocd.NewFactory(coreConfig.ArgoCDMapper)
+	// load argo cd and tekton mapped to environments of regions
+	environmentRegions, err := manager.EnvRegionMgr.ListAllEnvironmentRegions(ctx)
+	if err != nil {
+		panic(err)
+	}
+	if err := regionctl.LoadCDMappings(environmentRegions, argoCDFty, tektonFty); err != nil {
+		panic(err)
+	}
 
 	oauthAppDAO := oauthdao.NewDAO(mysqlDB)
 	tokenStore := tokenstore.NewStore(mysqlDB)
@@ -387,10 +463,6 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		coreConfig.Oauth.AccessTokenExpireIn,
 		coreConfig.Oauth.RefreshTokenExpireIn)
 
-	roleService, err := role.NewFileRoleFrom2(context.TODO(), roleConfig)
-	if err != nil {
-		panic(err)
-	}
 	mservice := memberservice.NewService(roleService, oauthManager, manager)
 	rbacAuthorizer := rbac.NewAuthorizer(roleService, mservice)
 
@@ -403,16 +475,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	if err != nil {
 		panic(err)
 	}
-	var oauthConfig oauthconfig.Scopes
-	if err = yaml.Unmarshal(content, &oauthConfig); err != nil {
-		panic(err)
-	}
-	log.Printf("the oauthScopeConfig = %+v\n", oauthConfig)
-
-	scopeService, err := scopeservice.NewFileScopeService(oauthConfig)
+	fileScopeService, err := newScopeService(content)
 	if err != nil {
 		panic(err)
 	}
+	scopeService := scopeservice.NewReloadableService(fileScopeService)
 
 	autoFreeSvc := service.New(coreConfig.AutoFreeConfig.SupportedEnvs)
 
@@ -445,6 +512,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	clusterSvc := clusterservice.NewService(applicationSvc, clusterGitRepo, manager)
 	userSvc := userservice.NewService(manager)
 	tokenSvc := tokenservice.NewService(manager, coreConfig.TokenConfig)
+	quotaSvc := quotaservice.NewService(manager, clusterGitRepo, func() *templateresourceconfig.Config {
+		return &reloader.Current().TemplateResources
+	})
+	policySvc := policyservice.NewService(manager, clusterGitRepo, templateRepo)
+	deprecatedAPISvc := deprecatedapiservice.NewService(manager, clusterGitRepo, templateRepo)
 
 	// init kube client
 	_, client, err := kube.BuildClient(coreConfig.KubeConfig)
@@ -453,8 +525,17 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	}
 
 	grafanaService := grafana.NewService(coreConfig.GrafanaConfig, manager, client)
+	recordingStore, err := newTerminalRecordingStore(coreConfig.TerminalConfig.Recording)
+	if err != nil {
+		panic(err)
+	}
 	regionInformers := regioninformers.NewRegionInformers(manager.RegionMgr, 0)
 	regionInformers.Register(workload.Resources...)
+	workloadRegistry := generic.NewRegistry(regionInformers)
+	if err := workloadRegistry.Register(ctx, coreConfig.WorkloadConfig.Definitions...); err != nil {
+		panic(err)
+	}
+	go workloadRegistry.WatchTemplateReleases(ctx, manager, templateRepo, 60*time.Second)
 	go regionInformers.WatchRegion(ctx, 60*time.Second)
 	parameter := &param.Param{
 		Manager:              manager,
@@ -467,20 +548,26 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		EventSvc:             eventSvc,
 		UserSvc:              userSvc,
 		TokenSvc:             tokenSvc,
+		QuotaSvc:             quotaSvc,
+		PolicySvc:            policySvc,
+		DeprecatedAPISvc:     deprecatedAPISvc,
 		RoleService:          roleService,
 		ScopeService:         scopeService,
 		ApplicationGitRepo:   applicationGitRepo,
 		TemplateSchemaGetter: templateSchemaGetter,
-		CD: cd.NewCD(regionInformers, clusterGitRepo, coreConfig.ArgoCDMapper,
+		CD: cd.NewCD(regionInformers, clusterGitRepo, argoCDFty,
 			coreConfig.GitopsRepoConfig.DefaultBranch),
-		K8sUtil:        cd.NewK8sUtil(regionInformers, manager.EventMgr),
-		OutputGetter:   outputGetter,
-		TektonFty:      tektonFty,
-		ClusterGitRepo: clusterGitRepo,
-		PRService:      prservice.NewService(manager),
-		GitGetter:      gitGetter,
-		GrafanaService: grafanaService,
-		BuildSchema:    buildSchema,
+		K8sUtil:          cd.NewK8sUtil(regionInformers, manager.EventMgr),
+		OutputGetter:     outputGetter,
+		MigrationGetter:  migrationGetter,
+		TektonFty:        tektonFty,
+		ArgoCDFty:        argoCDFty,
+		ClusterGitRepo:   clusterGitRepo,
+		PRService:        prservice.NewService(manager),
+		GitGetter:        gitGetter,
+		GrafanaService:   grafanaService,
+		BuildSchema:      buildSchema,
+		WorkloadRegistry: workloadRegistry,
 	}
 
 	var (
@@ -497,10 +584,23 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		authzSkippers = []middleware.Skipper{
 			middleware.MethodAndPathSkipper("*",
 				regexp.MustCompile("^/apis/core/v[12]/templates$")),
+			// events are filtered by the authorizer one by one
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v2/events(/stream)?$")),
+			// only admin is allowed to list recordings of all clusters, checked by the controller
+			middleware.MethodAndPathSkipper(http.MethodGet,
+				regexp.MustCompile("^/apis/core/v2/terminalrecordings$")),
+			// clusters of batch operations are authorized one by one, checked by the controller
+			middleware.MethodAndPathSkipper("*",
+				regexp.MustCompile("^/apis/core/v2/batchoperations(/[0-9]+(/cancel)?)?$")),
 		}
 	)
 	authzSkippers = append(authzSkippers, authnSkippers...)
 
+	terminalConfigGetter := func() *terminalconfig.Config {
+		return &reloader.Current().TerminalConfig
+	}
+
 	var (
 		// init controller
 		memberCtl            = memberctl.NewController(parameter)
@@ -510,7 +610,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		prCtl                = prctl.NewController(coreConfig, parameter)
 		templateCtl          = templatectl.NewController(parameter, templateRepo)
 		roleCtl              = roltctl.NewController(parameter)
-		terminalCtl          = terminalctl.NewController(parameter)
+		terminalCtl          = terminalctl.NewController(parameter, terminalConfigGetter, recordingStore, redisClient)
 		codeGitCtl           = codectl.NewController(gitGetter)
 		tagCtl               = tagctl.NewController(parameter)
 		templateSchemaTagCtl = templateschematagctl.NewController(parameter)
@@ -530,8 +630,28 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		accessTokenCtl       = accesstokenctl.NewController(parameter)
 		scopeCtl             = scopectl.NewController(parameter)
 		webhookCtl           = webhookctl.NewController(parameter)
-		eventCtl             = eventctl.NewController(parameter)
+		eventCtl             = eventctl.NewController(parameter, rbacAuthorizer)
 		badgeCtl             = badgectl.NewController(parameter)
+		configCtl            = configctl.NewController(reloader)
+		batchOperationCtl    = batchoperationctl.NewController(parameter, clusterCtl, rbacAuthorizer)
+		clusterConfigCtl     = clusterconfigctl.NewController(parameter, clusterCtl, rbacAuthorizer)
+		templateMigrationCtl = templatemigrationctl.NewController(parameter, clusterCtl)
+		rightsizingCtl       = rightsizingctl.NewController(parameter, clusterCtl,
+			func() *templateresourceconfig.Config {
+				return &reloader.Current().TemplateResources
+			})
+		previewCtl = previewctl.NewController(func() *previewconfig.Config {
+			return &reloader.Current().PreviewConfig
+		}, parameter, clusterCtl, rbacAuthorizer)
+		gitTriggerCtl = gittriggerctl.NewController(func() *gittriggerconfig.Config {
+			return &reloader.Current().GitTriggerConfig
+		}, parameter, clusterCtl, previewCtl, rbacAuthorizer)
+		costCtl = costctl.NewController(parameter, func() *costconfig.Config {
+			return &reloader.Current().CostConfig
+		})
+		quotaCtl         = quotactl.NewController(parameter)
+		policyCtl        = policyctl.NewController(parameter)
+		deprecatedAPICtl = deprecatedapictl.NewController(parameter)
 	)
 
 	var (
@@ -592,21 +712,120 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		userAPIV2              = userv2.NewAPI(userCtl, store)
 		webhookAPIV2           = webhookv2.NewAPI(webhookCtl)
 		badgeAPIV2             = badge.NewAPI(badgeCtl)
+		configAPIV2            = configv2.NewAPI(configCtl)
+		gitTriggerAPIV2        = gittriggerv2.NewAPI(gitTriggerCtl)
+		previewAPIV2           = previewv2.NewAPI(previewCtl)
+		batchOperationAPIV2    = batchoperationv2.NewAPI(batchOperationCtl)
+		clusterConfigAPIV2     = clusterconfigv2.NewAPI(clusterConfigCtl)
+		templateMigrationAPIV2 = templatemigrationv2.NewAPI(templateMigrationCtl)
+		rightsizingAPIV2       = rightsizingv2.NewAPI(rightsizingCtl)
+		costAPIV2              = costv2.NewAPI(costCtl)
+		quotaAPIV2             = quotav2.NewAPI(quotaCtl)
+		policyAPIV2            = policyv2.NewAPI(policyCtl)
+		deprecatedAPIAPIV2     = deprecatedapiv2.NewAPI(deprecatedAPICtl)
 	)
 
 	// start jobs
 	cleaner := clean.New(coreConfig.Clean, manager)
 	autoFreeJob := func(ctx context.Context) {
-		autofree.Run(ctx, &coreConfig.AutoFreeConfig, manager.UserMgr, clusterCtl, prCtl)
+		autofree.Run(ctx, func() *autofreeconfig.Config {
+			return &reloader.Current().AutoFreeConfig
+		}, manager.UserMgr, clusterCtl, prCtl)
+	}
+	rightsizingJob := func(ctx context.Context) {
+		rightsizing.Run(ctx, func() *rightsizingconfig.Config {
+			return &reloader.Current().RightsizingConfig
+		}, func() *templateresourceconfig.Config {
+			return &reloader.Current().TemplateResources
+		}, manager, clusterGitRepo)
+	}
+	costJob := func(ctx context.Context) {
+		costjob.Run(ctx, func() *costconfig.Config {
+			return &reloader.Current().CostConfig
+		}, manager, regionInformers)
+	}
+	deprecatedAPIJob := func(ctx context.Context) {
+		deprecatedapijob.Run(ctx, func() *deprecatedapiconfig.Config {
+			return &reloader.Current().DeprecatedAPIConfig
+		}, manager, clusterGitRepo, deprecatedAPISvc)
+	}
+	hibernationJob := func(ctx context.Context) {
+		hibernation.Run(ctx, func() *hibernationconfig.Config {
+			return &reloader.Current().HibernationConfig
+		}, manager.UserMgr, manager.HibernationMgr, clusterCtl, prCtl)
 	}
 	eventHandlerJob, eventHandlerSvc := eventhandler.New(ctx, coreConfig.EventHandlerConfig, manager)
-	webhookJob, _ := jobwebhook.New(ctx, eventHandlerSvc, coreConfig.WebhookConfig, manager)
+	webhookJob, webhookSvc := jobwebhook.New(ctx, eventHandlerSvc, coreConfig.WebhookConfig, manager)
 	grafanaSyncJob := func(ctx context.Context) {
-		grafanasync.Run(ctx, coreConfig, manager, client)
+		grafanasync.Run(ctx, grafanaService)
+	}
+
+	// reload the config, roles and scopes at runtime
+	reloader.OnReload("argoCDMapper", func(conf *config.Config) error {
+		argoCDFty.Reload(conf.ArgoCDMapper)
+		return nil
+	})
+	reloader.OnReload("tektonMapper", func(conf *config.Config) error {
+		return tektonFty.Reload(conf.TektonMapper)
+	})
+	reloader.OnReload("webhook", func(conf *config.Config) error {
+		webhookSvc.SetConfig(conf.WebhookConfig)
+		return nil
+	})
+	reloader.OnReload("eventHandler", func(conf *config.Config) error {
+		eventHandlerSvc.SetConfig(conf.EventHandlerConfig)
+		return nil
+	})
+	reloader.OnReload("autoFree", func(conf *config.Config) error {
+		autoFreeSvc.SetSupportedEnvs(conf.AutoFreeConfig.SupportedEnvs)
+		return nil
+	})
+	reloader.OnReload("clean", func(conf *config.Config) error {
+		return cleaner.SetConfig(conf.Clean)
+	})
+	reloader.OnReload("grafanaConfig", func(conf *config.Config) error {
+		grafanaService.SetConfig(conf.GrafanaConfig)
+		return nil
+	})
+	// the sections below are read from reloader.Current() whenever they are used,
+	// so they take effect once they are swapped by the reloader
+	for _, section := range []string{"templateResources", "terminal", "preview", "gitTrigger",
+		"cost", "rightsizing", "deprecatedAPI"} {
+		reloader.OnReload(section, func(*config.Config) error {
+			return nil
+		})
 	}
+	reloader.OnReload("hibernation", func(conf *config.Config) error {
+		// the account of the job is only verified when the job starts
+		if conf.HibernationConfig.AccountID != coreConfig.HibernationConfig.AccountID {
+			return errors.New("accountID of hibernation can only be changed by restart")
+		}
+		return nil
+	})
+	go reloader.Watch(ctx, flags.ConfigReloadInterval)
+	go regionctl.WatchCDMappings(ctx, flags.ConfigReloadInterval, manager.EnvRegionMgr, argoCDFty, tektonFty)
+	go config.WatchFile(ctx, flags.RoleConfigFile, flags.ConfigReloadInterval, func(content []byte) error {
+		service, err := newRoleService(content)
+		if err != nil {
+			return err
+		}
+		roleService.Reload(service)
+		log.Printf("roles reloaded")
+		return nil
+	})
+	go config.WatchFile(ctx, flags.ScopeRoleFile, flags.ConfigReloadInterval, func(content []byte) error {
+		service, err := newScopeService(content)
+		if err != nil {
+			return err
+		}
+		scopeService.Reload(service)
+		log.Printf("scopes reloaded")
+		return nil
+	})
 	k8seventJob := k8sevent.New(coreConfig.KubernetesEvent, regionInformers, manager, mysqlDB)
 	go jobs.Run(ctx, &coreConfig.JobConfig, eventHandlerJob, webhookJob,
-		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob)
+		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob, hibernationJob, rightsizingJob, costJob,
+		deprecatedAPIJob)
 
 	// init server
 	r := gin.New()
@@ -616,6 +835,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		gin.Recovery(),
 		requestid.Middleware(), // requestID middleware, attach a requestID to context
 		logmiddle.Middleware(), // log middleware, attach a logger to context
+		tracemiddle.Middleware( // trace middleware, start a span for each request
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics"))),
 
 		metricsmiddle.Middleware( // metrics middleware
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
@@ -705,6 +927,17 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		userAPIV2,
 		webhookAPIV2,
 		badgeAPIV2,
+		configAPIV2,
+		gitTriggerAPIV2,
+		previewAPIV2,
+		batchOperationAPIV2,
+		clusterConfigAPIV2,
+		templateMigrationAPIV2,
+		rightsizingAPIV2,
+		costAPIV2,
+		quotaAPIV2,
+		policyAPIV2,
+		deprecatedAPIAPIV2,
 	}
 
 	// start cloud event server
@@ -726,6 +959,24 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	log.Print(r.Run(fmt.Sprintf(":%d", coreConfig.ServerConfig.Port)))
 }
 
+func newRoleService(content []byte) (role.Service, error) {
+	var roleConfig roleconfig.Config
+	if err := yaml.Unmarshal(content, &roleConfig); err != nil {
+		return nil, err
+	}
+	log.Printf("the roleConfig = %+v\n", roleConfig)
+	return role.NewFileRoleFrom2(context.TODO(), roleConfig)
+}
+
+func newScopeService(content []byte) (scopeservice.Service, error) {
+	var oauthConfig oauthconfig.Scopes
+	if err := yaml.Unmarshal(content, &oauthConfig); err != nil {
+		return nil, err
+	}
+	log.Printf("the oauthScopeConfig = %+v\n", oauthConfig)
+	return scopeservice.NewFileScopeService(oauthConfig)
+}
+
 // Run runs the agent.
 func Run(flags *Flags) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
@@ -770,3 +1021,31 @@ func setTasksBeforeExit(stopFuncs ...func()) {
 		log.Printf("all tasks stopped, exit now.")
 	}()
 }
+
+// newTerminalRecordingStore returns the store of terminal recordings, nil if recording is disabled
+func newTerminalRecordingStore(config terminalconfig.Recording) (blob.Store, error) {
+	switch config.Storage {
+	case "":
+		return nil, nil
+	case blob.TypeLocal:
+		return blob.NewLocalStore(config.LocalDir), nil
+	case blob.TypeS3:
+		driver, err := s3.NewDriver(s3.Params{
+			AccessKey:        config.S3.AccessKey,
+			SecretKey:        config.S3.SecretKey,
+			Region:           config.S3.Region,
+			Endpoint:         config.S3.Endpoint,
+			Bucket:           config.S3.Bucket,
+			DisableSSL:       config.S3.DisableSSL,
+			SkipVerify:       config.S3.SkipVerify,
+			S3ForcePathStyle: config.S3.S3ForcePathStyle,
+			ContentType:      "application/x-asciicast",
+		})
+		if err != nil {
+			return nil, err
+		}
+		return blob.NewS3Store(driver), nil
+	default:
+		return nil, fmt.Errorf("unsupported terminal recording storage: %s", config.Storage)
+	}
+}
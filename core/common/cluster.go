@@ -33,6 +33,13 @@ const (
 	ClusterQueryTailLines     = "tailLines"
 	ClusterQueryExtraOwner    = "extraOwner"
 	ClusterQueryHard          = "hard"
+	ClusterQueryRevision      = "revision"
+	ClusterQuerySinceTime     = "sinceTime"
+	ClusterQueryPrevious      = "previous"
+	ClusterQueryFollow        = "follow"
+	ClusterQueryDownload      = "download"
+	// ClusterQueryLogFilter is a regular expression to filter log lines
+	ClusterQueryLogFilter = "filter"
 
 	// ClusterQueryIsFavorite is used to query cluster with favorite for current user only.
 	ClusterQueryIsFavorite = "isFavorite"
@@ -49,11 +56,12 @@ const (
 
 // status of cluster
 const (
-	ClusterStatusEmpty    = ""
-	ClusterStatusFreeing  = "Freeing"
-	ClusterStatusFreed    = "Freed"
-	ClusterStatusDeleting = "Deleting"
-	ClusterStatusCreating = "Creating"
+	ClusterStatusEmpty      = ""
+	ClusterStatusFreeing    = "Freeing"
+	ClusterStatusFreed      = "Freed"
+	ClusterStatusDeleting   = "Deleting"
+	ClusterStatusCreating   = "Creating"
+	ClusterStatusHibernated = "Hibernated"
 )
 
 const (
@@ -110,6 +110,8 @@ const (
 	ResourceWebhookLog = "webhooklogs"
 
 	ResourceMember = "members"
+
+	ResourceUser = "users"
 )
 
 const (
@@ -1,7 +1,8 @@
 package common
 
 const (
-	PipelineQueryByStatus = "status"
+	PipelineQueryByStatus       = "status"
+	PipelineQueryByConfigCommit = "configCommit"
 
 	MessageQueryBySystem = "system"
 
@@ -9,4 +10,10 @@ const (
 	MessagePipelinerunExecuted  = "executed pipelinerun"
 	MessagePipelinerunCancelled = "cancelled pipelinerun"
 	MessagePipelinerunReady     = "marked pipelinerun as ready to execute"
+
+	MessagePipelinerunPromoted       = "promoted release"
+	MessagePipelinerunPromotedFully  = "fully promoted release"
+	MessagePipelinerunAborted        = "aborted release"
+	MessagePipelinerunScaledDown     = "scaled down previous release"
+	MessagePipelinerunTrafficShifted = "shifted traffic: %s"
 )
@@ -17,29 +17,40 @@ package config
 import (
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/config/admission"
 	"github.com/horizoncd/horizon/pkg/config/argocd"
 	"github.com/horizoncd/horizon/pkg/config/authenticate"
 	"github.com/horizoncd/horizon/pkg/config/autofree"
 	"github.com/horizoncd/horizon/pkg/config/clean"
+	"github.com/horizoncd/horizon/pkg/config/cost"
 	"github.com/horizoncd/horizon/pkg/config/db"
+	"github.com/horizoncd/horizon/pkg/config/deprecatedapi"
 	"github.com/horizoncd/horizon/pkg/config/eventhandler"
 	"github.com/horizoncd/horizon/pkg/config/git"
 	"github.com/horizoncd/horizon/pkg/config/gitlab"
+	"github.com/horizoncd/horizon/pkg/config/gittrigger"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
+	"github.com/horizoncd/horizon/pkg/config/hibernation"
 	"github.com/horizoncd/horizon/pkg/config/job"
 	"github.com/horizoncd/horizon/pkg/config/k8sevent"
 	"github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
+	"github.com/horizoncd/horizon/pkg/config/preview"
 	"github.com/horizoncd/horizon/pkg/config/redis"
+	"github.com/horizoncd/horizon/pkg/config/rightsizing"
 	"github.com/horizoncd/horizon/pkg/config/server"
 	"github.com/horizoncd/horizon/pkg/config/session"
 	"github.com/horizoncd/horizon/pkg/config/tekton"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/templaterepo"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	"github.com/horizoncd/horizon/pkg/config/terminal"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	"github.com/horizoncd/horizon/pkg/config/trace"
 	"github.com/horizoncd/horizon/pkg/config/webhook"
+	"github.com/horizoncd/horizon/pkg/config/workload"
 
 	"gopkg.in/yaml.v3"
 )
@@ -69,15 +80,29 @@ type Config struct {
 	KubernetesEvent        k8sevent.Config         `yaml:"kubernetesEvent"`
 	Clean                  clean.Config            `yaml:"clean"`
 	Admission              admission.Admission     `yaml:"admission"`
+	TraceConfig            trace.Config            `yaml:"traceConfig"`
+	GitTriggerConfig       gittrigger.Config       `yaml:"gitTrigger"`
+	TerminalConfig         terminal.Config         `yaml:"terminal"`
+	HibernationConfig      hibernation.Config      `yaml:"hibernation"`
+	PreviewConfig          preview.Config          `yaml:"preview"`
+	WorkloadConfig         workload.Config         `yaml:"workload"`
+	TemplateResources      templateresource.Config `yaml:"templateResources"`
+	RightsizingConfig      rightsizing.Config      `yaml:"rightsizing"`
+	CostConfig             cost.Config             `yaml:"cost"`
+	DeprecatedAPIConfig    deprecatedapi.Config    `yaml:"deprecatedAPI"`
 }
 
 func LoadConfig(configFilePath string) (*Config, error) {
-	var config Config
 	data, err := ioutil.ReadFile(configFilePath)
 	if err != nil {
 		return nil, err
 	}
+	return ParseConfig(data)
+}
 
+// ParseConfig parses and normalizes the content of config file
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
@@ -121,6 +146,42 @@ func LoadConfig(configFilePath string) (*Config, error) {
 	if config.WebhookConfig.ResponseBodyTruncateSize <= 0 {
 		config.WebhookConfig.ResponseBodyTruncateSize = 16384
 	}
+	if config.GitTriggerConfig.DebounceInterval <= 0 {
+		config.GitTriggerConfig.DebounceInterval = 30 * time.Second
+	}
+	if config.HibernationConfig.JobInterval <= 0 {
+		config.HibernationConfig.JobInterval = time.Minute
+	}
+	if config.RightsizingConfig.JobInterval <= 0 {
+		config.RightsizingConfig.JobInterval = 24 * time.Hour
+	}
+	if config.RightsizingConfig.Window <= 0 {
+		config.RightsizingConfig.Window = 7 * 24 * time.Hour
+	}
+	if config.RightsizingConfig.CPUPercentile <= 0 {
+		config.RightsizingConfig.CPUPercentile = 0.95
+	}
+	if config.RightsizingConfig.MemoryPercentile <= 0 {
+		config.RightsizingConfig.MemoryPercentile = 0.99
+	}
+	if config.RightsizingConfig.Headroom <= 0 {
+		config.RightsizingConfig.Headroom = 1.2
+	}
+	if config.RightsizingConfig.Tolerance <= 0 {
+		config.RightsizingConfig.Tolerance = 0.1
+	}
+	if config.RightsizingConfig.CPUStep <= 0 {
+		config.RightsizingConfig.CPUStep = 100
+	}
+	if config.RightsizingConfig.MemoryStep <= 0 {
+		config.RightsizingConfig.MemoryStep = 128
+	}
+	if config.CostConfig.JobInterval <= 0 {
+		config.CostConfig.JobInterval = time.Hour
+	}
+	if config.DeprecatedAPIConfig.JobInterval <= 0 {
+		config.DeprecatedAPIConfig.JobInterval = 24 * time.Hour
+	}
 
 	return &config, nil
 }
@@ -0,0 +1,29 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadConfig makes sure the config file of the repo stays parsable
+func TestLoadConfig(t *testing.T) {
+	config, err := LoadConfig("../../config.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"busybox:1.36"}, config.TerminalConfig.DebugImages)
+	assert.Equal(t, int64(104857600), config.TerminalConfig.MaxUploadSize)
+}
@@ -0,0 +1,228 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"gopkg.in/yaml.v3"
+)
+
+const _redacted = "******"
+
+// _sensitiveKeys are the key fragments whose values are redacted when showing config
+var _sensitiveKeys = []string{"token", "password", "secret", "accesskey", "privatekey", "certificate"}
+
+// ReloadHandler applies the reloaded config, returns error if it can't be applied
+type ReloadHandler func(config *Config) error
+
+// ReloadStatus is the result of the latest reload
+type ReloadStatus struct {
+	ReloadedAt time.Time `json:"reloadedAt"`
+	// Reloaded are the sections swapped at runtime
+	Reloaded []string `json:"reloaded"`
+	// RestartRequired are the sections changed but can only take effect after restart
+	RestartRequired []string `json:"restartRequired"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// Reloader watches the config file, and reloads the sections registered by OnReload.
+// Changes of other sections are reported as restart required.
+type Reloader struct {
+	sync.RWMutex
+
+	path     string
+	current  *Config
+	status   ReloadStatus
+	handlers map[string][]ReloadHandler
+}
+
+func NewReloader(path string, config *Config) *Reloader {
+	return &Reloader{
+		path:     path,
+		current:  config,
+		handlers: make(map[string][]ReloadHandler),
+	}
+}
+
+// OnReload registers handler for the section, section is the yaml key of Config, such as argoCDMapper
+func (r *Reloader) OnReload(section string, handler ReloadHandler) {
+	r.Lock()
+	defer r.Unlock()
+	r.handlers[section] = append(r.handlers[section], handler)
+}
+
+// Current returns the effective config
+func (r *Reloader) Current() *Config {
+	r.RLock()
+	defer r.RUnlock()
+	return r.current
+}
+
+// Status returns the result of the latest reload
+func (r *Reloader) Status() ReloadStatus {
+	r.RLock()
+	defer r.RUnlock()
+	return r.status
+}
+
+// Watch checks the config file every interval, and reloads it if its content changes
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	WatchFile(ctx, r.path, interval, func(content []byte) error {
+		status := r.Reload(ctx, content)
+		if status.Error != "" {
+			return fmt.Errorf("failed to reload config: %s", status.Error)
+		}
+		log.Infof(ctx, "config reloaded, reloaded sections: %v, restart required sections: %v",
+			status.Reloaded, status.RestartRequired)
+		return nil
+	})
+}
+
+// WatchFile checks the file every interval, and calls onChange with its content if the content changes
+func WatchFile(ctx context.Context, path string, interval time.Duration, onChange func(content []byte) error) {
+	last, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Errorf(ctx, "failed to read file %s, err: %v", path, err)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				log.Errorf(ctx, "failed to read file %s, err: %v", path, err)
+				continue
+			}
+			if bytes.Equal(content, last) {
+				continue
+			}
+			last = content
+			if err := onChange(content); err != nil {
+				log.Errorf(ctx, "failed to apply changes of file %s, err: %v", path, err)
+			}
+		}
+	}
+}
+
+// Reload validates the content and applies the changed sections
+func (r *Reloader) Reload(ctx context.Context, content []byte) ReloadStatus {
+	r.Lock()
+	defer r.Unlock()
+
+	status := ReloadStatus{ReloadedAt: time.Now()}
+	newConfig, err := ParseConfig(content)
+	if err != nil {
+		status.Error = fmt.Sprintf("invalid config: %v", err)
+		r.status = status
+		return status
+	}
+
+	effective := *r.current
+	oldValue := reflect.ValueOf(r.current).Elem()
+	newValue := reflect.ValueOf(newConfig).Elem()
+	effectiveValue := reflect.ValueOf(&effective).Elem()
+	for i := 0; i < oldValue.NumField(); i++ {
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		section := sectionName(oldValue.Type().Field(i))
+		handlers, ok := r.handlers[section]
+		if !ok {
+			status.RestartRequired = append(status.RestartRequired, section)
+			continue
+		}
+		var errs []string
+		for _, handler := range handlers {
+			if err := handler(newConfig); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) > 0 {
+			log.Errorf(ctx, "failed to reload section %s: %s", section, strings.Join(errs, "; "))
+			status.Error = fmt.Sprintf("failed to reload section %s: %s", section, strings.Join(errs, "; "))
+			continue
+		}
+		effectiveValue.Field(i).Set(newValue.Field(i))
+		status.Reloaded = append(status.Reloaded, section)
+	}
+	sort.Strings(status.Reloaded)
+	sort.Strings(status.RestartRequired)
+
+	r.current = &effective
+	r.status = status
+	return status
+}
+
+// Redacted returns the effective config with secrets redacted
+func (r *Reloader) Redacted() (map[string]interface{}, error) {
+	content, err := yaml.Marshal(r.Current())
+	if err != nil {
+		return nil, err
+	}
+	var redacted map[string]interface{}
+	if err := yaml.Unmarshal(content, &redacted); err != nil {
+		return nil, err
+	}
+	redact(redacted)
+	return redacted, nil
+}
+
+func redact(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if s, ok := item.(string); ok && s != "" && isSensitive(key) {
+				v[key] = _redacted
+				continue
+			}
+			redact(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			redact(item)
+		}
+	}
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitiveKey := range _sensitiveKeys {
+		if strings.Contains(key, sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func sectionName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,106 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _testConfig = `
+serverConfig:
+  port: 8080
+dbConfig:
+  host: localhost
+  password: db-password
+argoCDMapper:
+  dev,test:
+    url: http://argocd
+    token: argocd-token
+    namespace: argocd
+`
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	config, err := ParseConfig([]byte(_testConfig))
+	assert.Nil(t, err)
+	assert.Equal(t, "argocd-token", config.ArgoCDMapper["test"].Token)
+
+	reloader := NewReloader("", config)
+	var reloaded *Config
+	reloader.OnReload("argoCDMapper", func(config *Config) error {
+		reloaded = config
+		return nil
+	})
+
+	// unchanged
+	status := reloader.Reload(ctx, []byte(_testConfig))
+	assert.Empty(t, status.Error)
+	assert.Empty(t, status.Reloaded)
+	assert.Empty(t, status.RestartRequired)
+	assert.Nil(t, reloaded)
+
+	// mapper is swapped, and server port requires restart
+	status = reloader.Reload(ctx, []byte(`
+serverConfig:
+  port: 8081
+dbConfig:
+  host: localhost
+  password: db-password
+argoCDMapper:
+  dev,test:
+    url: http://argocd
+    token: new-token
+    namespace: argocd
+`))
+	assert.Empty(t, status.Error)
+	assert.Equal(t, []string{"argoCDMapper"}, status.Reloaded)
+	assert.Equal(t, []string{"serverConfig"}, status.RestartRequired)
+	assert.NotNil(t, reloaded)
+	assert.Equal(t, "new-token", reloader.Current().ArgoCDMapper["dev"].Token)
+	assert.Equal(t, 8080, reloader.Current().ServerConfig.Port)
+	assert.Equal(t, status, reloader.Status())
+
+	// invalid content keeps the current config
+	status = reloader.Reload(ctx, []byte("serverConfig: ["))
+	assert.NotEmpty(t, status.Error)
+	assert.Equal(t, "new-token", reloader.Current().ArgoCDMapper["dev"].Token)
+
+	// failed handler keeps the current section
+	reloader.OnReload("argoCDMapper", func(config *Config) error {
+		return errors.New("invalid mapper")
+	})
+	status = reloader.Reload(ctx, []byte(_testConfig))
+	assert.Contains(t, status.Error, "invalid mapper")
+	assert.Empty(t, status.Reloaded)
+	assert.Equal(t, "new-token", reloader.Current().ArgoCDMapper["dev"].Token)
+}
+
+func TestRedacted(t *testing.T) {
+	config, err := ParseConfig([]byte(_testConfig))
+	assert.Nil(t, err)
+
+	redacted, err := NewReloader("", config).Redacted()
+	assert.Nil(t, err)
+	db := redacted["dbConfig"].(map[string]interface{})
+	assert.Equal(t, "localhost", db["host"])
+	assert.Equal(t, _redacted, db["password"])
+	argoCD := redacted["argoCDMapper"].(map[string]interface{})["dev"].(map[string]interface{})
+	assert.Equal(t, "http://argocd", argoCD["url"])
+	assert.Equal(t, _redacted, argoCD["token"])
+}
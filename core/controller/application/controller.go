@@ -125,7 +125,8 @@ func NewController(param *param.Param) Controller {
 
 func (c *controller) GetApplication(ctx context.Context, id uint) (_ *GetApplicationResponse, err error) {
 	const op = "application controller: get application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get application in db
 	app, err := c.applicationMgr.GetByID(ctx, id)
@@ -165,7 +166,8 @@ func (c *controller) GetApplication(ctx context.Context, id uint) (_ *GetApplica
 
 func (c *controller) GetApplicationV2(ctx context.Context, id uint) (_ *GetApplicationResponseV2, err error) {
 	const op = "application controller: get application v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	// 1. get application in db
 	app, err := c.applicationMgr.GetByID(ctx, id)
 	if err != nil {
@@ -228,7 +230,8 @@ func (c *controller) GetApplicationV2(ctx context.Context, id uint) (_ *GetAppli
 func (c *controller) CreateApplication(ctx context.Context, groupID uint,
 	request *CreateApplicationRequest) (_ *GetApplicationResponse, err error) {
 	const op = "application controller: create application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	if err := validateApplicationName(request.Name); err != nil {
 		return nil, err
@@ -337,7 +340,8 @@ func (c *controller) validateBuildAndTemplateConfigV2(ctx context.Context,
 func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 	request *CreateOrUpdateApplicationRequestV2) (*CreateApplicationResponseV2, error) {
 	const op = "application controller: create application v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	if err := validateApplicationName(request.Name); err != nil {
 		return nil, err
@@ -437,7 +441,8 @@ func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 func (c *controller) UpdateApplication(ctx context.Context, id uint,
 	request *UpdateApplicationRequest) (_ *GetApplicationResponse, err error) {
 	const op = "application controller: update application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get application in db
 	appExistsInDB, err := c.applicationMgr.GetByID(ctx, id)
@@ -514,7 +519,8 @@ func (c *controller) UpdateApplication(ctx context.Context, id uint,
 func (c *controller) UpdateApplicationV2(ctx context.Context, id uint,
 	request *CreateOrUpdateApplicationRequestV2) (err error) {
 	const op = "application controller: update application v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get application in db
 	appExistsInDB, err := c.applicationMgr.GetByID(ctx, id)
@@ -576,7 +582,8 @@ func (c *controller) UpdateApplicationV2(ctx context.Context, id uint,
 
 func (c *controller) DeleteApplication(ctx context.Context, id uint, hard bool) (err error) {
 	const op = "application controller: delete application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get application in db
 	app, err := c.applicationMgr.GetByID(ctx, id)
@@ -630,7 +637,8 @@ func (c *controller) DeleteApplication(ctx context.Context, id uint, hard bool)
 
 func (c *controller) Transfer(ctx context.Context, id uint, groupID uint) error {
 	const op = "application controller: transfer application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	group, err := c.groupMgr.GetByID(ctx, groupID)
 	if err != nil {
@@ -732,7 +740,8 @@ func validateApplicationName(name string) error {
 func (c *controller) List(ctx context.Context, query *q.Query) (
 	listApplicationResp []*ListApplicationResponse, count int, err error) {
 	const op = "application controller: list application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	subGroupIDs := make([]uint, 0)
 	if query.Keywords != nil {
@@ -329,21 +329,21 @@ func TestMain(m *testing.M) {
 func Test(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	applicationGitRepo := appgitrepomock.NewMockApplicationGitRepo2(mockCtl)
-	applicationGitRepo.EXPECT().CreateOrUpdateApplication(ctx, appName, gitrepo.CreateOrUpdateRequest{
+	applicationGitRepo.EXPECT().CreateOrUpdateApplication(gomock.Any(), appName, gitrepo.CreateOrUpdateRequest{
 		Version:      "",
 		Environment:  common.ApplicationRepoDefaultEnv,
 		BuildConf:    pipelineJSONBlob,
 		TemplateConf: applicationJSONBlob,
 	}).Return(nil).AnyTimes()
-	applicationGitRepo.EXPECT().HardDeleteApplication(ctx, appName).Return(nil).AnyTimes()
-	applicationGitRepo.EXPECT().GetApplication(ctx, appName, common.ApplicationRepoDefaultEnv).Return(&gitrepo.GetResponse{
+	applicationGitRepo.EXPECT().HardDeleteApplication(gomock.Any(), appName).Return(nil).AnyTimes()
+	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), appName, common.ApplicationRepoDefaultEnv).Return(&gitrepo.GetResponse{
 		Manifest:     nil,
 		BuildConf:    pipelineJSONBlob,
 		TemplateConf: applicationJSONBlob,
 	}, nil).AnyTimes()
 
 	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
-	templateSchemaGetter.EXPECT().GetTemplateSchema(ctx, "javaapp", "v1.0.0", nil).
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), "javaapp", "v1.0.0", nil).
 		Return(&trschema.Schemas{
 			Application: &trschema.Schema{
 				JSONSchema: applicationSchema,
@@ -493,14 +493,14 @@ func TestV2(t *testing.T) {
 	appName = "appname2"
 	mockCtl := gomock.NewController(t)
 	applicationGitRepo := appgitrepomock.NewMockApplicationGitRepo2(mockCtl)
-	applicationGitRepo.EXPECT().CreateOrUpdateApplication(ctx, appName, gitrepo.CreateOrUpdateRequest{
+	applicationGitRepo.EXPECT().CreateOrUpdateApplication(gomock.Any(), appName, gitrepo.CreateOrUpdateRequest{
 		Version:      common.MetaVersion2,
 		Environment:  common.ApplicationRepoDefaultEnv,
 		BuildConf:    nil,
 		TemplateConf: applicationJSONBlob,
 	}).Return(nil).AnyTimes()
-	applicationGitRepo.EXPECT().HardDeleteApplication(ctx, appName).Return(nil).AnyTimes()
-	applicationGitRepo.EXPECT().GetApplication(ctx, appName,
+	applicationGitRepo.EXPECT().HardDeleteApplication(gomock.Any(), appName).Return(nil).AnyTimes()
+	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), appName,
 		common.ApplicationRepoDefaultEnv).Return(&gitrepo.GetResponse{
 		Manifest:     nil,
 		BuildConf:    nil,
@@ -508,7 +508,7 @@ func TestV2(t *testing.T) {
 	}, nil).AnyTimes()
 
 	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
-	templateSchemaGetter.EXPECT().GetTemplateSchema(ctx, "javaapp", "v1.0.0", nil).
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), "javaapp", "v1.0.0", nil).
 		Return(&trschema.Schemas{
 			Application: &trschema.Schema{
 				JSONSchema: applicationSchema,
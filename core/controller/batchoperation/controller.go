@@ -0,0 +1,471 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchoperation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/batchoperation/manager"
+	"github.com/horizoncd/horizon/pkg/batchoperation/models"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	tagutil "github.com/horizoncd/horizon/pkg/util/tag"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_maxConcurrency = 20
+	_maxClusters    = 1000
+	_maxMessageLen  = 2048
+)
+
+type Controller interface {
+	// Create selects the clusters and executes the action on them asynchronously,
+	// the operator must be allowed to execute the action on every selected cluster
+	Create(ctx context.Context, r *CreateBatchOperationRequest) (*BatchOperation, error)
+	// Get returns the batch operation with the results of its clusters
+	Get(ctx context.Context, id uint) (*BatchOperation, error)
+	// List lists batch operations of the current user, or all of them for admin
+	List(ctx context.Context, query *q.Query) ([]*BatchOperation, int64, error)
+	// Cancel stops the clusters which are not operated yet, running actions are not interrupted
+	Cancel(ctx context.Context, id uint) error
+}
+
+type controller struct {
+	batchOperationMgr manager.Manager
+	clusterMgr        clustermanager.Manager
+	prMgr             *prmanager.PRManager
+	clusterCtl        clusterctl.Controller
+	authorizer        rbac.Authorizer
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param, clusterCtl clusterctl.Controller,
+	authorizer rbac.Authorizer) Controller {
+	return &controller{
+		batchOperationMgr: param.BatchOperationMgr,
+		clusterMgr:        param.ClusterMgr,
+		prMgr:             param.PRMgr,
+		clusterCtl:        clusterCtl,
+		authorizer:        authorizer,
+	}
+}
+
+func (c *controller) Create(ctx context.Context, r *CreateBatchOperationRequest) (*BatchOperation, error) {
+	const op = "batch operation controller: create"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch r.Action {
+	case models.ActionRestart, models.ActionDeploy, models.ActionRollback, models.ActionFree:
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %s", r.Action)
+	}
+	if r.Concurrency == 0 {
+		r.Concurrency = 1
+	}
+	if r.Concurrency > _maxConcurrency {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "concurrency must not exceed %d", _maxConcurrency)
+	}
+	clusters, err := c.selectClusters(ctx, r.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "no cluster is selected")
+	}
+	if len(clusters) > _maxClusters {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"%d clusters are selected, which exceeds %d", len(clusters), _maxClusters)
+	}
+	for _, cluster := range clusters {
+		if err := c.authorize(ctx, currentUser, r.Action, cluster); err != nil {
+			return nil, err
+		}
+	}
+
+	selector, err := json.Marshal(r.Selector)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	items := make([]*models.BatchOperationItem, 0, len(clusters))
+	for _, cluster := range clusters {
+		items = append(items, &models.BatchOperationItem{
+			ClusterID:   cluster.ID,
+			ClusterName: cluster.Name,
+			Status:      models.StatusPending,
+		})
+	}
+	operation, err := c.batchOperationMgr.Create(ctx, &models.BatchOperation{
+		Action:        r.Action,
+		Selector:      string(selector),
+		Concurrency:   r.Concurrency,
+		StopOnFailure: r.StopOnFailure,
+		Status:        models.StatusPending,
+		CreatedBy:     currentUser.GetID(),
+	}, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint
+	runCtx := context.WithValue(context.Background(), requestid.HeaderXRequestID, uuid.NewV4().String())
+	go c.execute(common.WithContext(runCtx, currentUser), operation, items)
+	return ofBatchOperation(operation), nil
+}
+
+func (c *controller) Get(ctx context.Context, id uint) (*BatchOperation, error) {
+	const op = "batch operation controller: get"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	operation, err := c.getBatchOperation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.batchOperationMgr.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := ofBatchOperation(operation)
+	result.Progress = make(map[string]int)
+	result.Items = make([]*Item, 0, len(items))
+	for _, item := range items {
+		result.Progress[item.Status]++
+		result.Items = append(result.Items, ofItem(item))
+	}
+	return result, nil
+}
+
+func (c *controller) List(ctx context.Context, query *q.Query) ([]*BatchOperation, int64, error) {
+	const op = "batch operation controller: list"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if query == nil {
+		query = &q.Query{}
+	}
+	if query.Keywords == nil {
+		query.Keywords = q.KeyWords{}
+	}
+	if !currentUser.IsAdmin() {
+		query.Keywords[common.BatchOperationQueryByUser] = currentUser.GetID()
+	}
+	operations, total, err := c.batchOperationMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*BatchOperation, 0, len(operations))
+	for _, operation := range operations {
+		result = append(result, ofBatchOperation(operation))
+	}
+	return result, total, nil
+}
+
+func (c *controller) Cancel(ctx context.Context, id uint) error {
+	const op = "batch operation controller: cancel"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	operation, err := c.getBatchOperation(ctx, id)
+	if err != nil {
+		return err
+	}
+	cancelled, err := c.batchOperationMgr.UpdateStatus(ctx, id, models.StatusCancelled,
+		models.StatusPending, models.StatusRunning)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"batch operation in status %s can not be cancelled", operation.Status)
+	}
+	// pending items are cancelled here as well in case the runner is gone
+	return c.batchOperationMgr.CancelPendingItems(ctx, id, "cancelled by user")
+}
+
+// getBatchOperation returns the batch operation if it's created by the current user or the user is admin
+func (c *controller) getBatchOperation(ctx context.Context, id uint) (*models.BatchOperation, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	operation, err := c.batchOperationMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() && operation.CreatedBy != currentUser.GetID() {
+		return nil, perror.Wrapf(herrors.ErrForbidden, "batch operation %d is not created by you", id)
+	}
+	return operation, nil
+}
+
+func (c *controller) selectClusters(ctx context.Context, selector *Selector) ([]*clustermodels.Cluster, error) {
+	if selector == nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "selector cannot be empty")
+	}
+	keywords := q.KeyWords{}
+	if selector.ApplicationID != 0 {
+		keywords[common.ParamApplicationID] = selector.ApplicationID
+	}
+	if selector.Environment != "" {
+		keywords[common.ClusterQueryEnvironment] = selector.Environment
+	}
+	if selector.TagSelector != "" {
+		tagSelectors, err := tagutil.ParseTagSelector(selector.TagSelector)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid tagSelector %s: %v", selector.TagSelector, err)
+		}
+		keywords[common.ClusterQueryTagSelector] = tagSelectors
+	}
+	if len(keywords) == 0 && len(selector.ClusterIDs) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "selector cannot be empty")
+	}
+
+	if len(keywords) == 0 {
+		clusters := make([]*clustermodels.Cluster, 0, len(selector.ClusterIDs))
+		seen := make(map[uint]bool)
+		for _, id := range selector.ClusterIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			cluster, err := c.clusterMgr.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			clusters = append(clusters, cluster)
+		}
+		return clusters, nil
+	}
+
+	_, listed, err := c.clusterMgr.List(ctx, &q.Query{Keywords: keywords, WithoutPagination: true})
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[uint]bool)
+	for _, id := range selector.ClusterIDs {
+		ids[id] = true
+	}
+	clusters := make([]*clustermodels.Cluster, 0, len(listed))
+	for _, cluster := range listed {
+		if len(ids) > 0 && !ids[cluster.ID] {
+			continue
+		}
+		clusters = append(clusters, cluster.Cluster)
+	}
+	return clusters, nil
+}
+
+// authorize checks whether the user is allowed to execute the action on the cluster
+// as if the action is requested by the api of the cluster
+func (c *controller) authorize(ctx context.Context, user userauth.User,
+	action string, cluster *clustermodels.Cluster) error {
+	decision, reason, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            user,
+		Verb:            "create",
+		APIGroup:        common.GroupCore,
+		Resource:        common.ResourceCluster,
+		SubResource:     action,
+		Name:            strconv.FormatUint(uint64(cluster.ID), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return err
+	}
+	if decision != auth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden, "%s of cluster %s is not allowed: %s",
+			action, cluster.Name, reason)
+	}
+	return nil
+}
+
+// execute executes the action on the clusters with the concurrency of the operation,
+// it stops once the operation is cancelled, or an action fails if the operation stops on failure
+func (c *controller) execute(ctx context.Context, operation *models.BatchOperation,
+	items []*models.BatchOperationItem) {
+	const op = "batch operation controller: execute"
+	logger := log.WithFiled(ctx, "op", op)
+
+	if _, err := c.batchOperationMgr.UpdateStatus(ctx, operation.ID, models.StatusRunning,
+		models.StatusPending); err != nil {
+		logger.Errorf("failed to start batch operation %d, err: %v", operation.ID, err)
+		return
+	}
+
+	var (
+		wg        sync.WaitGroup
+		lock      sync.Mutex
+		failed    int
+		semaphore = make(chan struct{}, operation.Concurrency)
+	)
+	stopped := func() (bool, string) {
+		lock.Lock()
+		defer lock.Unlock()
+		if operation.StopOnFailure && failed > 0 {
+			return true, "stopped since another cluster failed"
+		}
+		// cancellations may be requested to other instances
+		latest, err := c.batchOperationMgr.Get(ctx, operation.ID)
+		if err != nil {
+			logger.Warningf("failed to get batch operation %d, err: %v", operation.ID, err)
+		} else if latest.Status == models.StatusCancelled {
+			return true, "cancelled by user"
+		}
+		return false, ""
+	}
+
+	var reason string
+	for _, item := range items {
+		semaphore <- struct{}{}
+		var stop bool
+		if stop, reason = stopped(); stop {
+			<-semaphore
+			break
+		}
+		wg.Add(1)
+		go func(item *models.BatchOperationItem) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			if !c.executeItem(ctx, operation.Action, item) {
+				lock.Lock()
+				failed++
+				lock.Unlock()
+			}
+		}(item)
+	}
+	wg.Wait()
+	if reason == "" {
+		_, reason = stopped()
+	}
+
+	if reason != "" {
+		if err := c.batchOperationMgr.CancelPendingItems(ctx, operation.ID, reason); err != nil {
+			logger.Errorf("failed to cancel pending clusters of batch operation %d, err: %v", operation.ID, err)
+		}
+	}
+	status := models.StatusSucceeded
+	if failed > 0 {
+		status = models.StatusFailed
+	}
+	// cancelled operations keep their status
+	if _, err := c.batchOperationMgr.UpdateStatus(ctx, operation.ID, status, models.StatusRunning); err != nil {
+		logger.Errorf("failed to finish batch operation %d, err: %v", operation.ID, err)
+		return
+	}
+	logger.Infof("batch operation %d is finished, %d of %d clusters failed", operation.ID, failed, len(items))
+}
+
+// executeItem executes the action on the cluster of the item, and returns whether it succeeds
+func (c *controller) executeItem(ctx context.Context, action string, item *models.BatchOperationItem) bool {
+	const op = "batch operation controller: execute item"
+	logger := log.WithFiled(ctx, "op", op)
+
+	item.Status = models.StatusRunning
+	if err := c.batchOperationMgr.UpdateItem(ctx, item); err != nil {
+		logger.Warningf("failed to update item of cluster %d, err: %v", item.ClusterID, err)
+	}
+	pipelinerunID, err := c.executeAction(ctx, action, item.ClusterID)
+	item.PipelinerunID = pipelinerunID
+	if err != nil {
+		logger.Errorf("failed to %s cluster %d, err: %v", action, item.ClusterID, err)
+		item.Status = models.StatusFailed
+		item.Message = err.Error()
+		if len(item.Message) > _maxMessageLen {
+			item.Message = item.Message[:_maxMessageLen]
+		}
+	} else {
+		item.Status = models.StatusSucceeded
+	}
+	if err := c.batchOperationMgr.UpdateItem(ctx, item); err != nil {
+		logger.Errorf("failed to update item of cluster %d, err: %v", item.ClusterID, err)
+	}
+	return err == nil
+}
+
+func (c *controller) executeAction(ctx context.Context, action string, clusterID uint) (uint, error) {
+	var (
+		resp *clusterctl.PipelinerunIDResponse
+		err  error
+	)
+	switch action {
+	case models.ActionRestart:
+		resp, err = c.clusterCtl.Restart(ctx, clusterID)
+	case models.ActionDeploy:
+		resp, err = c.clusterCtl.Deploy(ctx, clusterID, &clusterctl.DeployRequest{
+			Title:       "batch deploy",
+			Description: "deployed by batch operation",
+		})
+	case models.ActionRollback:
+		var pipelinerunID uint
+		if pipelinerunID, err = c.previousPipelinerun(ctx, clusterID); err != nil {
+			return 0, err
+		}
+		resp, err = c.clusterCtl.Rollback(ctx, clusterID, &clusterctl.RollbackRequest{
+			PipelinerunID: pipelinerunID,
+		})
+	case models.ActionFree:
+		return 0, c.clusterCtl.FreeCluster(ctx, clusterID)
+	default:
+		return 0, fmt.Errorf("unsupported action: %s", action)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return resp.PipelinerunID, nil
+}
+
+// previousPipelinerun returns the pipelinerun of the deployment before the current one
+func (c *controller) previousPipelinerun(ctx context.Context, clusterID uint) (uint, error) {
+	// the first pipelinerun which can be rolled back is the current deployment, and it's skipped
+	_, pipelineruns, err := c.prMgr.PipelineRun.GetByClusterID(ctx, clusterID, true,
+		q.Query{PageNumber: 1, PageSize: 1})
+	if err != nil {
+		return 0, err
+	}
+	if len(pipelineruns) == 0 {
+		return 0, perror.Wrap(herrors.ErrParamInvalid, "there is no previous deployment to roll back to")
+	}
+	return pipelineruns[0].ID, nil
+}
@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchoperation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/batchoperation/manager"
+	"github.com/horizoncd/horizon/pkg/batchoperation/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// fakeClusterController restarts clusters except the failing ones
+type fakeClusterController struct {
+	clusterctl.Controller
+
+	failing map[uint]bool
+}
+
+func (f *fakeClusterController) Restart(ctx context.Context,
+	clusterID uint) (*clusterctl.PipelinerunIDResponse, error) {
+	if f.failing[clusterID] {
+		return nil, errors.New("restart failed")
+	}
+	return &clusterctl.PipelinerunIDResponse{PipelinerunID: clusterID * 10}, nil
+}
+
+func createOperation(ctx context.Context, t *testing.T, mgr manager.Manager,
+	stopOnFailure bool, concurrency uint) (*models.BatchOperation, []*models.BatchOperationItem) {
+	items := []*models.BatchOperationItem{
+		{ClusterID: 1, ClusterName: "cluster1", Status: models.StatusPending},
+		{ClusterID: 2, ClusterName: "cluster2", Status: models.StatusPending},
+		{ClusterID: 3, ClusterName: "cluster3", Status: models.StatusPending},
+	}
+	operation, err := mgr.Create(ctx, &models.BatchOperation{
+		Action:        models.ActionRestart,
+		Selector:      `{"clusterIDs":[1,2,3]}`,
+		Concurrency:   concurrency,
+		StopOnFailure: stopOnFailure,
+		Status:        models.StatusPending,
+		CreatedBy:     1,
+	}, items)
+	assert.Nil(t, err)
+	return operation, items
+}
+
+func TestBatchOperation(t *testing.T) {
+	db, _ := orm.NewSqliteDB("file::memory:?cache=shared")
+	if err := db.AutoMigrate(&models.BatchOperation{}, &models.BatchOperationItem{}); err != nil {
+		panic(err)
+	}
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "creator"})
+	mgr := manager.New(db)
+	c := &controller{
+		batchOperationMgr: mgr,
+		clusterCtl:        &fakeClusterController{failing: map[uint]bool{2: true}},
+	}
+
+	// the remaining clusters are cancelled once a cluster fails
+	operation, items := createOperation(ctx, t, mgr, true, 1)
+	c.execute(ctx, operation, items)
+	result, err := c.Get(ctx, operation.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusFailed, result.Status)
+	assert.Equal(t, models.StatusSucceeded, result.Items[0].Status)
+	assert.Equal(t, uint(10), result.Items[0].PipelinerunID)
+	assert.Equal(t, models.StatusFailed, result.Items[1].Status)
+	assert.Equal(t, "restart failed", result.Items[1].Message)
+	assert.Equal(t, models.StatusCancelled, result.Items[2].Status)
+	assert.Equal(t, 1, result.Progress[models.StatusCancelled])
+
+	// all clusters are operated without stopping on failure
+	operation, items = createOperation(ctx, t, mgr, false, 2)
+	c.execute(ctx, operation, items)
+	result, err = c.Get(ctx, operation.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusFailed, result.Status)
+	assert.Equal(t, 2, result.Progress[models.StatusSucceeded])
+	assert.Equal(t, models.StatusSucceeded, result.Items[2].Status)
+
+	// cancelled operations do not operate clusters
+	operation, items = createOperation(ctx, t, mgr, false, 1)
+	otherCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 2, Name: "other"})
+	err = c.Cancel(otherCtx, operation.ID)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	assert.Nil(t, c.Cancel(ctx, operation.ID))
+	err = c.Cancel(ctx, operation.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	c.execute(ctx, operation, items)
+	result, err = c.Get(ctx, operation.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusCancelled, result.Status)
+	assert.Equal(t, 3, result.Progress[models.StatusCancelled])
+
+	operations, total, err := c.List(otherCtx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Equal(t, 0, len(operations))
+	operations, total, err = c.List(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []uint{1, 2, 3}, operations[0].Selector.ClusterIDs)
+}
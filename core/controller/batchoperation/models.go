@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchoperation
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/batchoperation/models"
+)
+
+// Selector selects clusters by ids, application, environment and tags,
+// the clusters matching all of the given conditions are selected
+type Selector struct {
+	ClusterIDs    []uint `json:"clusterIDs,omitempty"`
+	ApplicationID uint   `json:"applicationID,omitempty"`
+	Environment   string `json:"environment,omitempty"`
+	// TagSelector is the selector of cluster tags, such as a=b,c!=d
+	TagSelector string `json:"tagSelector,omitempty"`
+}
+
+type CreateBatchOperationRequest struct {
+	// Action is one of restart, deploy, rollback and free,
+	// rollback rolls back clusters to their previous deployments
+	Action   string    `json:"action"`
+	Selector *Selector `json:"selector"`
+	// Concurrency is the count of clusters operated at the same time, 1 by default
+	Concurrency   uint `json:"concurrency"`
+	StopOnFailure bool `json:"stopOnFailure"`
+}
+
+type BatchOperation struct {
+	ID            uint      `json:"id"`
+	Action        string    `json:"action"`
+	Selector      *Selector `json:"selector"`
+	Concurrency   uint      `json:"concurrency"`
+	StopOnFailure bool      `json:"stopOnFailure"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	CreatedBy     uint      `json:"createdBy"`
+	// Progress counts the clusters by their status, only returned by get
+	Progress map[string]int `json:"progress,omitempty"`
+	Items    []*Item        `json:"items,omitempty"`
+}
+
+type Item struct {
+	ClusterID     uint      `json:"clusterID"`
+	ClusterName   string    `json:"clusterName"`
+	Status        string    `json:"status"`
+	PipelinerunID uint      `json:"pipelinerunID,omitempty"`
+	Message       string    `json:"message,omitempty"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func ofBatchOperation(operation *models.BatchOperation) *BatchOperation {
+	var selector Selector
+	_ = json.Unmarshal([]byte(operation.Selector), &selector)
+	return &BatchOperation{
+		ID:            operation.ID,
+		Action:        operation.Action,
+		Selector:      &selector,
+		Concurrency:   operation.Concurrency,
+		StopOnFailure: operation.StopOnFailure,
+		Status:        operation.Status,
+		CreatedAt:     operation.CreatedAt,
+		UpdatedAt:     operation.UpdatedAt,
+		CreatedBy:     operation.CreatedBy,
+	}
+}
+
+func ofItem(item *models.BatchOperationItem) *Item {
+	return &Item{
+		ClusterID:     item.ClusterID,
+		ClusterName:   item.ClusterName,
+		Status:        item.Status,
+		PipelinerunID: item.PipelinerunID,
+		Message:       item.Message,
+		UpdatedAt:     item.UpdatedAt,
+	}
+}
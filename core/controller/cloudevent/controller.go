@@ -69,7 +69,8 @@ func NewController(tektonFty factory.Factory, parameter *param.Param) Controller
 
 func (c *controller) CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) (err error) {
 	const op = "cloudEvent controller: cloudEvent"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	horizonMetaData, err := c.getHorizonMetaData(ctx, wpr)
 	if err != nil {
@@ -82,7 +83,7 @@ func (c *controller) CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) (e
 	pipelinerunID := horizonMetaData.PipelinerunID
 
 	// 1. collect log & pipelinerun object
-	tektonCollector, err := c.tektonFty.GetTektonCollector(environment)
+	tektonCollector, err := c.tektonFty.GetTektonCollector(environment, horizonMetaData.Region)
 	if err != nil {
 		return err
 	}
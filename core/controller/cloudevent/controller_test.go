@@ -226,10 +226,10 @@ func Test(t *testing.T) {
 	tektonFty := tektonftymock.NewMockFactory(mockCtl)
 	tekton := tektonmock.NewMockInterface(mockCtl)
 	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
-	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
-	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	tektonFty.EXPECT().GetTekton(gomock.Any(), gomock.Any()).Return(tekton, nil).AnyTimes()
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any(), gomock.Any()).Return(tektonCollector, nil).AnyTimes()
 
-	tektonCollector.EXPECT().Collect(ctx, gomock.Any(), gomock.Any()).Return(&collector.CollectResult{
+	tektonCollector.EXPECT().Collect(gomock.Any(), gomock.Any(), gomock.Any()).Return(&collector.CollectResult{
 		Bucket:    "bucket",
 		LogObject: "log-object",
 		PrObject:  "pr-object",
@@ -252,7 +252,7 @@ func Test(t *testing.T) {
 	templateReleaseMgr.EXPECT().GetByTemplateNameAndRelease(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(&trmodels.TemplateRelease{}, nil)
 	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
-	clusterGitRepo.EXPECT().GetCluster(ctx, gomock.Any(),
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return(&gitrepo.ClusterFiles{
 		PipelineJSONBlob:    map[string]interface{}{},
 		ApplicationJSONBlob: map[string]interface{}{},
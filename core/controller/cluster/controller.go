@@ -16,6 +16,7 @@ package cluster
 
 import (
 	"context"
+	"io"
 
 	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,28 +38,34 @@ import (
 	registryfty "github.com/horizoncd/horizon/pkg/cluster/registry/factory"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	collectionmanager "github.com/horizoncd/horizon/pkg/collection/manager"
-	"github.com/horizoncd/horizon/pkg/config/grafana"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	deprecatedapiservice "github.com/horizoncd/horizon/pkg/deprecatedapi/service"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	environmentregionmapper "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	gittriggermanager "github.com/horizoncd/horizon/pkg/gittrigger/manager"
 	grafanaservice "github.com/horizoncd/horizon/pkg/grafana"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
+	hibernationmanager "github.com/horizoncd/horizon/pkg/hibernation/manager"
 	"github.com/horizoncd/horizon/pkg/param"
+	policyservice "github.com/horizoncd/horizon/pkg/policy/service"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	templateschematagmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
+	"github.com/horizoncd/horizon/pkg/traffic"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usersvc "github.com/horizoncd/horizon/pkg/user/service"
 )
@@ -85,6 +92,13 @@ type Controller interface {
 	Rollback(ctx context.Context, clusterID uint, request *RollbackRequest) (*PipelinerunIDResponse, error)
 
 	FreeCluster(ctx context.Context, clusterID uint) error
+	// HibernateCluster scales the workloads of the cluster to zero replicas
+	HibernateCluster(ctx context.Context, clusterID uint) error
+	// WakeCluster restores the replicas of a hibernated cluster
+	WakeCluster(ctx context.Context, clusterID uint) error
+	GetHibernation(ctx context.Context, clusterID uint) (*HibernationResponse, error)
+	UpdateHibernation(ctx context.Context, clusterID uint, r *HibernationRequest) (*HibernationResponse, error)
+	DeleteHibernation(ctx context.Context, clusterID uint) error
 
 	// InternalDeploy todo(zx): remove after InternalDeployV2 is stabilized
 	InternalDeploy(ctx context.Context, clusterID uint,
@@ -92,6 +106,10 @@ type Controller interface {
 
 	ExecuteAction(ctx context.Context, clusterID uint, action string,
 		gvk schema.GroupVersionResource) error
+	// GetTraffic gets the traffic weights and the header route of the cluster
+	GetTraffic(ctx context.Context, clusterID uint) (*traffic.Traffic, error)
+	// ShiftTraffic adjusts the traffic weights and the header route of the cluster
+	ShiftTraffic(ctx context.Context, clusterID uint, r *ShiftTrafficRequest) (*traffic.Traffic, error)
 
 	// Deprecated: GetClusterStatus
 	GetClusterStatus(ctx context.Context, clusterID uint) (_ *GetClusterStatusResponse, err error)
@@ -104,6 +122,10 @@ type Controller interface {
 	GetDiff(ctx context.Context, clusterID uint, refType, ref string) (*GetDiffResponse, error)
 	GetContainerLog(ctx context.Context, clusterID uint, podName, containerName string, tailLines int64) (
 		<-chan string, error)
+	// GetClusterLogs streams the logs of the cluster's pods concurrently, lines are prefixed with pod names
+	GetClusterLogs(ctx context.Context, clusterID uint, r *GetClusterLogsRequest) (<-chan string, error)
+	// ArchiveClusterLogs writes the logs of the cluster's pods to w as a zip archive
+	ArchiveClusterLogs(ctx context.Context, clusterID uint, r *GetClusterLogsRequest, w io.Writer) error
 
 	DeleteClusterPods(ctx context.Context, clusterID uint, podName []string) (BatchResponse, error)
 	GetClusterPod(ctx context.Context, clusterID uint, podName string) (
@@ -115,6 +137,9 @@ type Controller interface {
 
 	CreateClusterV2(ctx context.Context, params *CreateClusterParamsV2) (*CreateClusterResponseV2, error)
 	GetClusterV2(ctx context.Context, clusterID uint) (*GetClusterResponseV2, error)
+	// UpdateClusterV2 commits the config of the cluster to its gitops branch,
+	// the change is not deployed until the cluster is deployed. When the template release is switched,
+	// the configs which are merged into or not given are migrated by the migrations of the release.
 	UpdateClusterV2(ctx context.Context, clusterID uint, r *UpdateClusterRequestV2, mergePatch bool) error
 	// InternalDeployV2 deploy only used by internal system
 	InternalDeployV2(ctx context.Context, clusterID uint,
@@ -143,11 +168,14 @@ type controller struct {
 	templateMgr           templatemanager.Manager
 	templateReleaseMgr    trmanager.Manager
 	templateSchemaGetter  templateschema.Getter
+	migrationGetter       migration.Getter
 	outputGetter          output.Getter
 	envMgr                envmanager.Manager
 	envRegionMgr          environmentregionmapper.Manager
 	regionMgr             regionmanager.Manager
 	badgeMgr              badgemanager.Manager
+	gitTriggerMgr         gittriggermanager.Manager
+	hibernationMgr        hibernationmanager.Manager
 	groupSvc              groupsvc.Service
 	prMgr                 *prmanager.PRManager
 	prSvc                 prservice.Service
@@ -161,7 +189,6 @@ type controller struct {
 	schemaTagManager      templateschematagmanager.Manager
 	tagMgr                tagmanager.Manager
 	grafanaService        grafanaservice.Service
-	grafanaConfig         grafana.Config
 	buildSchema           *build.Schema
 	eventSvc              eventservice.Service
 	tokenSvc              tokenservice.Service
@@ -169,6 +196,9 @@ type controller struct {
 	templateUpgradeMapper template.UpgradeMapper
 	collectionManager     collectionmanager.Manager
 	clusterSvc            clusterservice.Service
+	quotaSvc              quotaservice.Service
+	policySvc             policyservice.Service
+	deprecatedAPISvc      deprecatedapiservice.Service
 }
 
 var _ Controller = (*controller)(nil)
@@ -186,9 +216,12 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		templateMgr:           param.TemplateMgr,
 		templateReleaseMgr:    param.TemplateReleaseMgr,
 		templateSchemaGetter:  param.TemplateSchemaGetter,
+		migrationGetter:       param.MigrationGetter,
 		autoFreeSvc:           param.AutoFreeSvc,
 		outputGetter:          param.OutputGetter,
 		badgeMgr:              param.BadgeMgr,
+		gitTriggerMgr:         param.GitTriggerMgr,
+		hibernationMgr:        param.HibernationMgr,
 		envMgr:                param.EnvMgr,
 		envRegionMgr:          param.EnvRegionMgr,
 		regionMgr:             param.RegionMgr,
@@ -205,7 +238,6 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		schemaTagManager:      param.ClusterSchemaTagMgr,
 		tagMgr:                param.TagMgr,
 		grafanaService:        param.GrafanaService,
-		grafanaConfig:         config.GrafanaConfig,
 		buildSchema:           param.BuildSchema,
 		eventSvc:              param.EventSvc,
 		tokenSvc:              param.TokenSvc,
@@ -213,5 +245,8 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		templateUpgradeMapper: config.TemplateUpgradeMapper,
 		collectionManager:     param.CollectionMgr,
 		clusterSvc:            param.ClusterSvc,
+		quotaSvc:              param.QuotaSvc,
+		policySvc:             param.PolicySvc,
+		deprecatedAPISvc:      param.DeprecatedAPISvc,
 	}
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
 	collectionmodels "github.com/horizoncd/horizon/pkg/collection/models"
 	emvregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -135,7 +136,8 @@ func (c *controller) List(ctx context.Context, query *q.Query) ([]*ListClusterWi
 func (c *controller) ListByApplication(ctx context.Context,
 	query *q.Query) (_ int, _ []*ListClusterWithFullResponse, err error) {
 	const op = "cluster controller: list cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -241,7 +243,8 @@ func (c *controller) getFullResponsesWithRegion(ctx context.Context,
 func (c *controller) ListClusterWithExpiry(ctx context.Context,
 	query *q.Query) ([]*ListClusterWithExpiryResponse, error) {
 	const op = "cluster controller: list clusters with expiry"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	clusterList, err := c.clusterMgr.ListClusterWithExpiry(ctx, query)
 	return ofClusterWithExpiry(clusterList), err
 }
@@ -264,7 +267,8 @@ func (c *controller) clusterWillExpireIn(ctx context.Context, cluster *cmodels.C
 
 func (c *controller) GetCluster(ctx context.Context, clusterID uint) (_ *GetClusterResponse, err error) {
 	const op = "cluster controller: get cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get cluster from db
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
@@ -340,7 +344,8 @@ func (c *controller) GetCluster(ctx context.Context, clusterID uint) (_ *GetClus
 
 func (c *controller) GetClusterOutput(ctx context.Context, clusterID uint) (_ interface{}, err error) {
 	const op = "cluster controller: get cluster output"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	// 1. get cluster from db
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
@@ -429,7 +434,8 @@ func RenderOutputObject(outPutStr, templateName string,
 func (c *controller) CreateCluster(ctx context.Context, applicationID uint, environment,
 	region string, r *CreateClusterRequest, mergePatch bool) (_ *GetClusterResponse, err error) {
 	const op = "cluster controller: create cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get application
 	application, err := c.applicationMgr.GetByID(ctx, applicationID)
@@ -495,6 +501,15 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 		return nil, err
 	}
 
+	// 6.1 check resource quotas
+	quotaConfig := map[string]interface{}{
+		resource.SectionApplication: r.TemplateInput.Application,
+		resource.SectionPipeline:    r.TemplateInput.Pipeline,
+	}
+	if err := c.quotaSvc.Check(ctx, cluster, quotaConfig); err != nil {
+		return nil, err
+	}
+
 	// 7. create cluster in db
 	cluster, err = c.clusterMgr.Create(ctx, cluster, tags, r.ExtraMembers)
 	if err != nil {
@@ -535,6 +550,7 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 	if err != nil {
 		return nil, err
 	}
+	c.recordUsage(ctx, cluster, quotaConfig)
 
 	// 9. get full path
 	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
@@ -562,7 +578,8 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 	r *UpdateClusterRequest, mergePatch bool) (_ *GetClusterResponse, err error) {
 	const op = "cluster controller: update cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get cluster from db
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
@@ -635,7 +652,9 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 
 	clusterModel, tags := r.toClusterModel(cluster, templateRelease, er)
 
-	// 4. if templateInput is not empty, validate templateInput and update templateInput in git repo
+	// 4. if templateInput is not empty, validate templateInput, check resource quotas
+	// and update templateInput in git repo
+	var quotaConfig map[string]interface{}
 	if r.TemplateInput != nil {
 		// merge cluster config and request config
 		// merge patch allows users to pass only some fields
@@ -669,6 +688,11 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 			return nil, perror.Wrapf(herrors.ErrParamInvalid,
 				"request body validate err: %v", err)
 		}
+		quotaConfig, err = c.checkQuota(ctx, application, cluster, er.EnvironmentName, cluster.Template,
+			r.TemplateInput.Pipeline, r.TemplateInput.Application)
+		if err != nil {
+			return nil, err
+		}
 		// update cluster in git repo
 		if err := c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
 			BaseParams: &gitrepo.BaseParams{
@@ -694,6 +718,12 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 			Application: files.ApplicationJSONBlob,
 			Pipeline:    files.PipelineJSONBlob,
 		}
+		if er.EnvironmentName != cluster.EnvironmentName {
+			if _, err := c.checkQuota(ctx, application, cluster, er.EnvironmentName, cluster.Template,
+				r.TemplateInput.Pipeline, r.TemplateInput.Application); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// 5. update cluster in db
@@ -702,6 +732,9 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if quotaConfig != nil {
+		c.recordUsage(ctx, cluster, quotaConfig)
+	}
 
 	// 7. update cluster tags
 	tagsInDB, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
@@ -743,7 +776,8 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 func (c *controller) GetClusterByName(ctx context.Context,
 	clusterName string) (_ *GetClusterByNameResponse, err error) {
 	const op = "cluster controller: get cluster by name"
-	wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get cluster
 	cluster, err := c.clusterMgr.GetByName(ctx, clusterName)
@@ -791,7 +825,8 @@ func (c *controller) GetClusterByName(ctx context.Context,
 // TODO(gjq): add a deleting tag for cluster
 func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard bool) (err error) {
 	const op = "cluster controller: delete cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// get some relevant models
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
@@ -844,6 +879,7 @@ func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard boo
 		// 1. delete cluster in cd system
 		if err = c.cd.DeleteCluster(newctx, &cd.DeleteClusterParams{
 			Environment: cluster.EnvironmentName,
+			Region:      cluster.RegionName,
 			Cluster:     cluster.Name,
 		}); err != nil {
 			log.Errorf(newctx, "failed to delete cluster: %v in cd system, err: %v", cluster.Name, err)
@@ -910,6 +946,16 @@ func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard boo
 		if err = c.badgeMgr.DeleteByResource(ctx, common.ResourceCluster, clusterID); err != nil {
 			log.Errorf(newctx, "failed to delete badge of cluster: %v, err: %v", cluster.Name, err)
 		}
+
+		// 7. delete git triggers of cluster
+		if err = c.gitTriggerMgr.DeleteByClusterID(newctx, clusterID); err != nil {
+			log.Errorf(newctx, "failed to delete git triggers of cluster: %v, err: %v", cluster.Name, err)
+		}
+
+		// 8. delete hibernation of cluster
+		if err = c.hibernationMgr.DeleteByClusterID(newctx, clusterID); err != nil {
+			log.Errorf(newctx, "failed to delete hibernation of cluster: %v, err: %v", cluster.Name, err)
+		}
 	}()
 
 	return nil
@@ -918,7 +964,8 @@ func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard boo
 // FreeCluster to set cluster free
 func (c *controller) FreeCluster(ctx context.Context, clusterID uint) (err error) {
 	const op = "cluster controller: free cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// get some relevant models
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
@@ -965,6 +1012,7 @@ func (c *controller) FreeCluster(ctx context.Context, clusterID uint) (err error
 		// 2. delete cluster in cd system
 		if err = c.cd.DeleteCluster(newctx, &cd.DeleteClusterParams{
 			Environment: cluster.EnvironmentName,
+			Region:      cluster.RegionName,
 			Cluster:     cluster.Name,
 		}); err != nil {
 			log.Errorf(newctx, "failed to delete cluster: %v in cd system, err: %v", cluster.Name, err)
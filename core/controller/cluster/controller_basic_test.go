@@ -309,6 +309,8 @@ func testControllerFreeOrDeleteClusterFailed(t *testing.T) {
 		groupManager:   manager.GroupMgr,
 		envMgr:         manager.EnvMgr,
 		badgeMgr:       manager.BadgeMgr,
+		gitTriggerMgr:  manager.GitTriggerMgr,
+		hibernationMgr: manager.HibernationMgr,
 		regionMgr:      manager.RegionMgr,
 		eventSvc:       eventservice.New(manager),
 	}
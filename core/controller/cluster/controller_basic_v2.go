@@ -25,14 +25,18 @@ import (
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
 	collectionmodels "github.com/horizoncd/horizon/pkg/collection/models"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/models"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	"github.com/horizoncd/horizon/pkg/util/jsonschema"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/mergemap"
 	"github.com/horizoncd/horizon/pkg/util/validate"
 
@@ -45,7 +49,8 @@ import (
 
 func (c *controller) GetClusterStatusV2(ctx context.Context, clusterID uint) (*StatusResponseV2, error) {
 	const op = "cluster controller: get cluster status v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
@@ -100,7 +105,8 @@ func (c *controller) GetClusterStatusV2(ctx context.Context, clusterID uint) (*S
 func (c *controller) CreateClusterV2(ctx context.Context,
 	params *CreateClusterParamsV2) (*CreateClusterResponseV2, error) {
 	const op = "cluster controller: create cluster v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. check exist
 	exists, err := c.clusterMgr.CheckClusterExists(ctx, params.Name)
@@ -179,6 +185,15 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	cluster, tags := params.toClusterModel(application,
 		envEntity, buildTemplateInfo, template, expireSeconds)
 
+	// 8.1 check resource quotas
+	quotaConfig := map[string]interface{}{
+		resource.SectionApplication: buildTemplateInfo.TemplateConfig,
+		resource.SectionPipeline:    buildTemplateInfo.BuildConfig,
+	}
+	if err := c.quotaSvc.Check(ctx, cluster, quotaConfig); err != nil {
+		return nil, err
+	}
+
 	// 9. update db and tags
 	clusterResp, err := c.clusterMgr.Create(ctx, cluster, tags, params.ExtraMembers)
 	if err != nil {
@@ -217,6 +232,7 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	c.recordUsage(ctx, updateClusterResp, quotaConfig)
 
 	// 11. get full path
 	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
@@ -253,7 +269,8 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 
 func (c *controller) GetClusterV2(ctx context.Context, clusterID uint) (*GetClusterResponseV2, error) {
 	const op = "cluster controller: get cluster v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get cluster from db
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
@@ -366,7 +383,8 @@ func (c *controller) GetClusterV2(ctx context.Context, clusterID uint) (*GetClus
 func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	r *UpdateClusterRequestV2, mergePatch bool) error {
 	const op = "cluster controller: update cluster v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// validate request
 	if r.Git != nil && r.Git.URL != "" {
@@ -445,7 +463,11 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	}
 
 	buildConfig, templateConfig, err := func() (map[string]interface{}, map[string]interface{}, error) {
-		if r.BuildConfig == nil && r.TemplateConfig == nil {
+		// configs in git are migrated if the template release is switched,
+		// unless they are totally replaced by the request
+		migrate := templateInfo.Name == cluster.Template && templateInfo.Release != cluster.TemplateRelease &&
+			(mergePatch || r.BuildConfig == nil || r.TemplateConfig == nil)
+		if r.BuildConfig == nil && r.TemplateConfig == nil && !migrate {
 			return nil, nil, nil
 		}
 		files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
@@ -453,20 +475,39 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 			return nil, nil, err
 		}
 		if files.Manifest == nil {
-			return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "git repo  %s not support v2 interface",
-				cluster.Name)
+			if r.BuildConfig != nil || r.TemplateConfig != nil {
+				return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "git repo  %s not support v2 interface",
+					cluster.Name)
+			}
+			return nil, nil, nil
+		}
+
+		pipelineJSONBlob, applicationJSONBlob := files.PipelineJSONBlob, files.ApplicationJSONBlob
+		migrated := false
+		if migrate {
+			pipelineJSONBlob, applicationJSONBlob, migrated, err = c.migrateConfigs(ctx, cluster,
+				templateInfo.Release, files)
+			if err != nil {
+				return nil, nil, err
+			}
 		}
 
 		buildConfig := r.BuildConfig
 		templateConfig := r.TemplateConfig
+		if r.BuildConfig == nil && migrated {
+			buildConfig = pipelineJSONBlob
+		}
+		if r.TemplateConfig == nil && migrated {
+			templateConfig = applicationJSONBlob
+		}
 		if r.BuildConfig != nil && mergePatch {
-			buildConfig, err = mergemap.Merge(files.PipelineJSONBlob, r.BuildConfig)
+			buildConfig, err = mergemap.Merge(pipelineJSONBlob, r.BuildConfig)
 			if err != nil {
 				return nil, nil, err
 			}
 		}
 		if r.TemplateConfig != nil && mergePatch {
-			templateConfig, err = mergemap.Merge(files.ApplicationJSONBlob, r.TemplateConfig)
+			templateConfig, err = mergemap.Merge(applicationJSONBlob, r.TemplateConfig)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -494,6 +535,17 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		return err
 	}
 
+	// 5.1 check resource quotas if anything related to resources is changed
+	var quotaConfig map[string]interface{}
+	if buildConfig != nil || templateConfig != nil ||
+		environmentName != cluster.EnvironmentName || templateInfo.Name != cluster.Template {
+		quotaConfig, err = c.checkQuota(ctx, application, cluster, environmentName,
+			templateInfo.Name, buildConfig, templateConfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	// 6. update in git repo
 	if err = c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
 		BaseParams: &gitrepo.BaseParams{
@@ -517,10 +569,13 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	// 8. update cluster in db
 	clusterModel, tags := r.toClusterModel(cluster, expireSeconds, environmentName,
 		regionName, templateInfo.Name, templateInfo.Release)
-	_, err = c.clusterMgr.UpdateByID(ctx, clusterID, clusterModel)
+	updated, err := c.clusterMgr.UpdateByID(ctx, clusterID, clusterModel)
 	if err != nil {
 		return err
 	}
+	if quotaConfig != nil {
+		c.recordUsage(ctx, updated, quotaConfig)
+	}
 
 	// 9. update cluster tags
 	tagsInDB, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
@@ -538,6 +593,41 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	return nil
 }
 
+// migrateConfigs migrates the pipeline and application configs of the cluster to the template release
+// by the migrations in the chart of the release, the configs are returned as they are with false
+// if no migration starts from the current template release of the cluster
+func (c *controller) migrateConfigs(ctx context.Context, cluster *clustermodels.Cluster, templateRelease string,
+	files *gitrepo.ClusterFiles) (map[string]interface{}, map[string]interface{}, bool, error) {
+	migrations, err := c.migrationGetter.GetMigrations(ctx, cluster.Template, templateRelease)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	needed := false
+	for _, m := range migrations {
+		if m.From == cluster.TemplateRelease {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return files.PipelineJSONBlob, files.ApplicationJSONBlob, false, nil
+	}
+	chain, err := migration.Chain(migrations, cluster.TemplateRelease, templateRelease)
+	if err != nil {
+		return nil, nil, false, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	migrated, err := migration.Apply(map[string]interface{}{
+		migration.SectionPipeline:    files.PipelineJSONBlob,
+		migration.SectionApplication: files.ApplicationJSONBlob,
+	}, chain)
+	if err != nil {
+		return nil, nil, false, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	pipelineJSONBlob, _ := migrated[migration.SectionPipeline].(map[string]interface{})
+	applicationJSONBlob, _ := migrated[migration.SectionApplication].(map[string]interface{})
+	return pipelineJSONBlob, applicationJSONBlob, true, nil
+}
+
 type BuildTemplateInfo struct {
 	BuildConfig    map[string]interface{}
 	TemplateInfo   *codemodels.TemplateInfo
@@ -650,7 +740,8 @@ func (c *controller) ToggleLikeStatus(ctx context.Context, clusterID uint, like
 func (c *controller) CreatePipelineRun(ctx context.Context, clusterID uint,
 	r *CreatePipelineRunRequest) (*prmodels.PipelineBasic, error) {
 	const op = "pipelinerun controller: create pipelinerun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pipelineRun, err := c.createPipelineRun(ctx, clusterID, r)
 	if err != nil {
@@ -682,7 +773,8 @@ func (c *controller) CreatePipelineRun(ctx context.Context, clusterID uint,
 
 func (c *controller) createPipelineRun(ctx context.Context, clusterID uint,
 	r *CreatePipelineRunRequest) (*prmodels.Pipelinerun, error) {
-	defer wlog.Start(ctx, "cluster controller: create pipeline run").StopPrint()
+	ctx, l := wlog.Start(ctx, "cluster controller: create pipeline run")
+	defer l.StopPrint()
 
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
@@ -830,3 +922,43 @@ func (c *controller) createPipelineRun(ctx context.Context, clusterID uint,
 		RollbackFrom:     rollbackFrom,
 	}, nil
 }
+
+// checkQuota checks the resource quotas with the configs to update and returns the configs checked,
+// configs which are not updated are read from the cluster git repo
+func (c *controller) checkQuota(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, environment, template string,
+	buildConfig, templateConfig map[string]interface{}) (map[string]interface{}, error) {
+	if buildConfig == nil || templateConfig == nil {
+		files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+		if err != nil {
+			return nil, err
+		}
+		if buildConfig == nil {
+			buildConfig = files.PipelineJSONBlob
+		}
+		if templateConfig == nil {
+			templateConfig = files.ApplicationJSONBlob
+		}
+	}
+
+	updated := *cluster
+	updated.EnvironmentName = environment
+	updated.Template = template
+	config := map[string]interface{}{
+		resource.SectionApplication: templateConfig,
+		resource.SectionPipeline:    buildConfig,
+	}
+	if err := c.quotaSvc.Check(ctx, &updated, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// recordUsage caches the resources requested by the cluster with the config written to its git repo,
+// failures are only logged since the config has been written
+func (c *controller) recordUsage(ctx context.Context, cluster *clustermodels.Cluster,
+	config map[string]interface{}) {
+	if err := c.quotaSvc.Record(ctx, cluster, config); err != nil {
+		log.Warningf(ctx, "failed to record resource usage of cluster %s, err: %v", cluster.Name, err)
+	}
+}
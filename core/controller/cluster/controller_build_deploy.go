@@ -26,6 +26,7 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
 	"github.com/horizoncd/horizon/pkg/git"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
@@ -40,7 +41,8 @@ import (
 func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 	r *BuildDeployRequest) (_ *BuildDeployResponse, err error) {
 	const op = "cluster controller: build deploy"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -91,6 +93,24 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 	// 1. assemble artifact imageURL
 	imageURL := assembleImageURL(regionEntity, application.Name, cluster.Name, gitRef, commit.ID)
 
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	clusterFiles, err := c.clusterGitRepo.GetCluster(ctx,
+		application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return nil, err
+	}
+
+	// check resource quotas before building
+	if err := c.quotaSvc.Check(ctx, cluster, map[string]interface{}{
+		resource.SectionApplication: clusterFiles.ApplicationJSONBlob,
+		resource.SectionPipeline:    clusterFiles.PipelineJSONBlob,
+	}); err != nil {
+		return nil, err
+	}
+
 	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
 	if err != nil {
 		return nil, err
@@ -124,17 +144,7 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 	}
 
 	// 4. create pipelinerun in k8s
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
-	if err != nil {
-		return nil, err
-	}
-
-	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
-	if err != nil {
-		return nil, err
-	}
-	clusterFiles, err := c.clusterGitRepo.GetCluster(ctx,
-		application.Name, cluster.Name, tr.ChartName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.RegionName)
 	if err != nil {
 		return nil, err
 	}
@@ -216,7 +226,8 @@ func assembleImageURL(regionEntity *regionmodels.RegionEntity,
 
 func (c *controller) GetDiff(ctx context.Context, clusterID uint, refType, ref string) (_ *GetDiffResponse, err error) {
 	const op = "cluster controller: get diff"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get cluster
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
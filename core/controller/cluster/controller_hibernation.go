@@ -0,0 +1,279 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cd"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	hibernationmodels "github.com/horizoncd/horizon/pkg/hibernation/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+func (c *controller) HibernateCluster(ctx context.Context, clusterID uint) (err error) {
+	const op = "cluster controller: hibernate cluster"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if cluster.Status == common.ClusterStatusHibernated {
+		return nil
+	}
+	if cluster.Status != common.ClusterStatusEmpty {
+		return perror.Wrapf(herrors.ErrClusterNotHibernatable, "cluster status: %v", cluster.Status)
+	}
+
+	// 1. scale workloads to zero
+	scaled, err := c.executeHibernationAction(ctx, cluster, workload.ActionHibernate)
+	if err != nil {
+		return err
+	}
+	if scaled == 0 {
+		return perror.Wrap(herrors.ErrClusterNotHibernatable, "no workload of cluster is deployed")
+	}
+
+	// 2. set cluster status
+	cluster.Status = common.ClusterStatusHibernated
+	if _, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster); err != nil {
+		return err
+	}
+
+	// 3. create event
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, clusterID,
+		eventmodels.ClusterHibernated, nil)
+	return nil
+}
+
+func (c *controller) WakeCluster(ctx context.Context, clusterID uint) (err error) {
+	const op = "cluster controller: wake cluster"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if cluster.Status != common.ClusterStatusHibernated {
+		return perror.Wrapf(herrors.ErrParamInvalid, "cluster is not hibernated, status: %v", cluster.Status)
+	}
+
+	// 1. restore replicas of workloads
+	if _, err = c.executeHibernationAction(ctx, cluster, workload.ActionWake); err != nil {
+		return err
+	}
+
+	// 2. reset cluster status
+	cluster.Status = common.ClusterStatusEmpty
+	if _, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster); err != nil {
+		return err
+	}
+
+	// 3. create event
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, clusterID,
+		eventmodels.ClusterWoken, nil)
+	return nil
+}
+
+// executeHibernationAction executes action on the top-level workloads of cluster,
+// and returns the number of workloads executed.
+// Waking continues past failures so that as many workloads as possible are restored,
+// while a failed hibernation wakes the workloads already scaled to zero, so the cluster keeps running.
+func (c *controller) executeHibernationAction(ctx context.Context,
+	cluster *cmodels.Cluster, action string) (int, error) {
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return 0, err
+	}
+	resourceTree, err := c.cd.GetResourceTree(ctx, &cd.GetResourceTreeParams{
+		Environment:  cluster.EnvironmentName,
+		Cluster:      cluster.Name,
+		RegionEntity: regionEntity,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	execute := func(node cd.ResourceNode, gvr schema.GroupVersionResource, action string) error {
+		return c.k8sutil.ExecuteAction(ctx, &cd.ExecuteActionParams{
+			RegionEntity: regionEntity,
+			Namespace:    node.Namespace,
+			Action:       action,
+			GVR:          gvr,
+			ResourceName: node.Name,
+			ClusterID:    cluster.ID,
+			SkipEvent:    true,
+		})
+	}
+
+	var (
+		executed []cd.ResourceNode
+		errs     []error
+	)
+	for _, node := range resourceTree {
+		gvr, ok := hibernatableGVR(node)
+		if !ok || len(node.ParentRefs) > 0 {
+			continue
+		}
+		if err := execute(node, gvr, action); err != nil {
+			errs = append(errs, err)
+			if action == workload.ActionHibernate {
+				break
+			}
+			continue
+		}
+		executed = append(executed, node)
+	}
+	if len(errs) == 0 {
+		return len(executed), nil
+	}
+
+	if action == workload.ActionHibernate {
+		for _, node := range executed {
+			gvr, _ := hibernatableGVR(node)
+			if err := execute(node, gvr, workload.ActionWake); err != nil {
+				log.Errorf(ctx, "failed to roll back hibernation of %s %s, err: %v", node.Kind, node.Name, err)
+				errs = append(errs, err)
+			}
+		}
+		executed = nil
+	}
+	msgs := make([]string, 0, len(errs)-1)
+	for _, err := range errs[1:] {
+		msgs = append(msgs, err.Error())
+	}
+	return len(executed), perror.Wrapf(errs[0], "failed to %s workloads of cluster %s, other errors: [%s]",
+		action, cluster.Name, strings.Join(msgs, "; "))
+}
+
+// hibernatableGVR returns the resource of the node if its workload ability is hibernatable
+func hibernatableGVR(node cd.ResourceNode) (schema.GroupVersionResource, bool) {
+	ability, err := workload.GetAbility(schema.GroupKind{Group: node.Group, Kind: node.Kind})
+	if err != nil {
+		return schema.GroupVersionResource{}, false
+	}
+	hibernator, ok := ability.(workload.Hibernator)
+	if !ok {
+		return schema.GroupVersionResource{}, false
+	}
+	return hibernator.HibernatableGVR()
+}
+
+func (c *controller) GetHibernation(ctx context.Context, clusterID uint) (*HibernationResponse, error) {
+	const op = "cluster controller: get hibernation"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	hibernation, err := c.hibernationMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return ofHibernation(hibernation), nil
+}
+
+func (c *controller) UpdateHibernation(ctx context.Context, clusterID uint,
+	r *HibernationRequest) (*HibernationResponse, error) {
+	const op = "cluster controller: update hibernation"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+
+	idleSeconds, err := validateHibernationRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	hibernation, err := c.hibernationMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		hibernation, err = c.hibernationMgr.Create(ctx, &hibernationmodels.Hibernation{
+			ClusterID:         clusterID,
+			HibernateSchedule: r.HibernateSchedule,
+			WakeSchedule:      r.WakeSchedule,
+			IdleSeconds:       idleSeconds,
+			CreatedBy:         currentUser.GetID(),
+			UpdatedBy:         currentUser.GetID(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return ofHibernation(hibernation), nil
+	}
+
+	hibernation.HibernateSchedule = r.HibernateSchedule
+	hibernation.WakeSchedule = r.WakeSchedule
+	hibernation.IdleSeconds = idleSeconds
+	hibernation.UpdatedBy = currentUser.GetID()
+	hibernation, err = c.hibernationMgr.Update(ctx, hibernation)
+	if err != nil {
+		return nil, err
+	}
+	return ofHibernation(hibernation), nil
+}
+
+func (c *controller) DeleteHibernation(ctx context.Context, clusterID uint) error {
+	const op = "cluster controller: delete hibernation"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	return c.hibernationMgr.DeleteByClusterID(ctx, clusterID)
+}
+
+// validateHibernationRequest validates the schedules and returns the idle seconds
+func validateHibernationRequest(r *HibernationRequest) (uint, error) {
+	for _, schedule := range []string{r.HibernateSchedule, r.WakeSchedule} {
+		if schedule == "" {
+			continue
+		}
+		if _, err := cron.ParseStandard(schedule); err != nil {
+			return 0, perror.Wrapf(herrors.ErrParamInvalid, "invalid schedule %q: %v", schedule, err)
+		}
+	}
+	idleSeconds := uint(0)
+	if r.IdleTime != "" {
+		duration, err := time.ParseDuration(r.IdleTime)
+		if err != nil || duration <= 0 {
+			return 0, perror.Wrapf(herrors.ErrParamInvalid, "invalid idle time: %v", r.IdleTime)
+		}
+		idleSeconds = uint(duration.Seconds())
+	}
+	if r.HibernateSchedule == "" && idleSeconds == 0 {
+		return 0, perror.Wrap(herrors.ErrParamInvalid, "either hibernateSchedule or idleTime is required")
+	}
+	return idleSeconds, nil
+}
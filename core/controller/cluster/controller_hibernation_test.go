@@ -0,0 +1,179 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cd"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrydao "github.com/horizoncd/horizon/pkg/registry/dao"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/workload"
+	// register the abilities of the workloads to hibernate
+	_ "github.com/horizoncd/horizon/pkg/workload/deployment"
+	_ "github.com/horizoncd/horizon/pkg/workload/rollout"
+	_ "github.com/horizoncd/horizon/pkg/workload/statefulset"
+)
+
+func testHibernateCluster(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCD := cdmock.NewMockCD(mockCtl)
+	mockK8sUtil := cdmock.NewMockK8sUtil(mockCtl)
+	c := &controller{
+		cd:             mockCD,
+		k8sutil:        mockK8sUtil,
+		clusterMgr:     manager.ClusterMgr,
+		regionMgr:      manager.RegionMgr,
+		hibernationMgr: manager.HibernationMgr,
+		eventSvc:       eventservice.New(manager),
+	}
+
+	registryID, err := registrydao.NewDAO(db).Create(ctx, &registrymodels.Registry{
+		Server: "http://127.0.0.1",
+	})
+	assert.Nil(t, err)
+	region, err := manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name:        "TestHibernateCluster",
+		DisplayName: "TestHibernateCluster",
+		RegistryID:  registryID,
+	})
+	assert.Nil(t, err)
+	cluster := &clustermodels.Cluster{
+		Name:            "test-hibernate-cluster",
+		EnvironmentName: "test",
+		RegionName:      region.Name,
+	}
+	assert.Nil(t, db.Create(cluster).Error)
+
+	resourceTree := []cd.ResourceNode{
+		{ResourceNode: v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+			Group: "apps", Kind: "Deployment", Namespace: "test", Name: cluster.Name,
+		}}},
+		{ResourceNode: v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+			Group: "apps", Kind: "ReplicaSet", Namespace: "test", Name: cluster.Name + "-abc",
+		}, ParentRefs: []v1alpha1.ResourceRef{{Group: "apps", Kind: "Deployment", Name: cluster.Name}}}},
+		{ResourceNode: v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+			Kind: "Service", Namespace: "test", Name: cluster.Name,
+		}}},
+	}
+	mockCD.EXPECT().GetResourceTree(gomock.Any(), gomock.Any()).Return(resourceTree, nil).Times(2)
+	mockK8sUtil.EXPECT().ExecuteAction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, params *cd.ExecuteActionParams) error {
+			assert.Equal(t, workload.ActionHibernate, params.Action)
+			assert.Equal(t, "deployments", params.GVR.Resource)
+			assert.Equal(t, cluster.Name, params.ResourceName)
+			assert.True(t, params.SkipEvent)
+			return nil
+		}).Times(1)
+
+	// waking a running cluster is not allowed
+	err = c.WakeCluster(ctx, cluster.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	assert.Nil(t, c.HibernateCluster(ctx, cluster.ID))
+	hibernated, err := manager.ClusterMgr.GetByID(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, common.ClusterStatusHibernated, hibernated.Status)
+
+	// hibernating a hibernated cluster changes nothing
+	assert.Nil(t, c.HibernateCluster(ctx, cluster.ID))
+
+	mockK8sUtil.EXPECT().ExecuteAction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, params *cd.ExecuteActionParams) error {
+			assert.Equal(t, workload.ActionWake, params.Action)
+			return nil
+		}).Times(1)
+	assert.Nil(t, c.WakeCluster(ctx, cluster.ID))
+	woken, err := manager.ClusterMgr.GetByID(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, common.ClusterStatusEmpty, woken.Status)
+
+	// a failed hibernation wakes the workloads already scaled to zero
+	resourceTree = append(resourceTree, cd.ResourceNode{ResourceNode: v1alpha1.ResourceNode{
+		ResourceRef: v1alpha1.ResourceRef{
+			Group: "apps", Kind: "StatefulSet", Namespace: "test", Name: cluster.Name,
+		}}})
+	mockCD.EXPECT().GetResourceTree(gomock.Any(), gomock.Any()).Return(resourceTree, nil).Times(2)
+	var actions []string
+	mockK8sUtil.EXPECT().ExecuteAction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, params *cd.ExecuteActionParams) error {
+			actions = append(actions, params.Action+" "+params.GVR.Resource)
+			if params.GVR.Resource == "statefulsets" {
+				return herrors.NewErrUpdateFailed(herrors.ResourceInK8S, "failed")
+			}
+			return nil
+		}).Times(5)
+	assert.NotNil(t, c.HibernateCluster(ctx, cluster.ID))
+	assert.Equal(t, []string{
+		workload.ActionHibernate + " deployments",
+		workload.ActionHibernate + " statefulsets",
+		workload.ActionWake + " deployments",
+	}, actions)
+	woken, err = manager.ClusterMgr.GetByID(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, common.ClusterStatusEmpty, woken.Status)
+
+	// waking continues past failures
+	woken.Status = common.ClusterStatusHibernated
+	_, err = manager.ClusterMgr.UpdateByID(ctx, cluster.ID, woken)
+	assert.Nil(t, err)
+	actions = nil
+	err = c.WakeCluster(ctx, cluster.ID)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{
+		workload.ActionWake + " deployments",
+		workload.ActionWake + " statefulsets",
+	}, actions)
+	woken.Status = common.ClusterStatusEmpty
+	_, err = manager.ClusterMgr.UpdateByID(ctx, cluster.ID, woken)
+	assert.Nil(t, err)
+
+	// schedules
+	_, err = c.UpdateHibernation(ctx, cluster.ID, &HibernationRequest{HibernateSchedule: "invalid"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.UpdateHibernation(ctx, cluster.ID, &HibernationRequest{WakeSchedule: "0 9 * * 1-5"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	resp, err := c.UpdateHibernation(ctx, cluster.ID, &HibernationRequest{
+		HibernateSchedule: "0 20 * * *",
+		WakeSchedule:      "0 9 * * 1-5",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "0 20 * * *", resp.HibernateSchedule)
+	resp, err = c.UpdateHibernation(ctx, cluster.ID, &HibernationRequest{IdleTime: "2h"})
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.HibernateSchedule)
+	assert.Equal(t, "2h0m0s", resp.IdleTime)
+
+	resp, err = c.GetHibernation(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "2h0m0s", resp.IdleTime)
+
+	assert.Nil(t, c.DeleteHibernation(ctx, cluster.ID))
+	_, err = c.GetHibernation(ctx, cluster.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
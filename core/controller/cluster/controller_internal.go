@@ -33,7 +33,8 @@ import (
 func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 	r *InternalDeployRequest) (_ *InternalDeployResponse, err error) {
 	const op = "cluster controller: internal deploy"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get pr, and do some validate
 	pr, err := c.prMgr.PipelineRun.GetByID(ctx, r.PipelinerunID)
@@ -97,6 +98,16 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
+	// 4.1 evaluate policies on the manifests to deploy
+	if err := c.evaluatePolicies(ctx, application, cluster, tr, pr, commit); err != nil {
+		return nil, err
+	}
+
+	// 4.2 check the manifests to deploy do not use apis removed in the region
+	if err := c.checkDeprecatedAPIs(ctx, application, cluster, tr, pr, commit); err != nil {
+		return nil, err
+	}
+
 	// 5. merge branch from gitops to master  and update status
 	masterRevision, err := c.clusterGitRepo.MergeBranch(ctx, application.Name, cluster.Name,
 		gitrepo.GitOpsBranch, c.clusterGitRepo.DefaultBranch(), &pr.ID)
@@ -129,7 +140,8 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 	}
 
 	// 7. reset cluster status
-	if cluster.Status == common.ClusterStatusFreed {
+	if cluster.Status == common.ClusterStatusFreed ||
+		cluster.Status == common.ClusterStatusHibernated {
 		cluster.Status = common.ClusterStatusEmpty
 		cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
 		if err != nil {
@@ -140,6 +152,7 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 	// 8. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
 	}); err != nil {
@@ -18,25 +18,36 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi"
+	deprecatedapiservice "github.com/horizoncd/horizon/pkg/deprecatedapi/service"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	policyservice "github.com/horizoncd/horizon/pkg/policy/service"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
+// _checkRunMessageMaxLength is the max length of the message of check runs
+const _checkRunMessageMaxLength = 256
+
 func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 	r *InternalDeployRequestV2) (_ *InternalDeployResponseV2, err error) {
 	const op = "cluster controller: internal deploy v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// auth jwt token
 	claims, user, err := c.retrieveClaimsAndUser(ctx)
@@ -127,6 +138,16 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
+	// 4.1 evaluate policies on the manifests to deploy
+	if err := c.evaluatePolicies(ctx, application, cluster, tr, pr, configCommit.Gitops); err != nil {
+		return nil, err
+	}
+
+	// 4.2 check the manifests to deploy do not use apis removed in the region
+	if err := c.checkDeprecatedAPIs(ctx, application, cluster, tr, pr, configCommit.Gitops); err != nil {
+		return nil, err
+	}
+
 	// 5. merge branch from gitops to master if diff is not empty and update status
 	diff, err := c.clusterGitRepo.CompareConfig(ctx, application.Name, cluster.Name,
 		&configCommit.Master, &configCommit.Gitops)
@@ -167,7 +188,8 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 	}
 
 	// 7. reset cluster status
-	if cluster.Status == common.ClusterStatusFreed {
+	if cluster.Status == common.ClusterStatusFreed ||
+		cluster.Status == common.ClusterStatusHibernated {
 		cluster.Status = common.ClusterStatusEmpty
 		cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
 		if err != nil {
@@ -178,6 +200,7 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 	// 8. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
 	}); err != nil {
@@ -223,6 +246,89 @@ func (c *controller) retrieveClaimsAndUser(ctx context.Context) (*tokenservice.C
 	return &claims, user, nil
 }
 
+// evaluatePolicies reports the policies evaluated on the cluster at the commit as a check run of the pipelinerun,
+// and fails the pipelinerun if any policy in enforce mode is violated
+func (c *controller) evaluatePolicies(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, tr *trmodels.TemplateRelease, pr *prmodels.Pipelinerun, commit string) error {
+	report, err := c.policySvc.Evaluate(ctx, application, cluster, tr, commit)
+	if err != nil || report == nil {
+		return err
+	}
+
+	status := prmodels.CheckStatusSuccess
+	if len(report.Violations) > 0 {
+		status = prmodels.CheckStatusFailure
+	}
+	message := report.Summary()
+	if len(message) > _checkRunMessageMaxLength {
+		message = message[:_checkRunMessageMaxLength-3] + "..."
+	}
+	if _, err := c.prMgr.Check.CreateCheckRun(ctx, &prmodels.CheckRun{
+		Name:          policyservice.CheckRunName,
+		Status:        status,
+		Message:       message,
+		PipelineRunID: pr.ID,
+	}); err != nil {
+		return err
+	}
+	if len(report.Violations) == 0 {
+		return nil
+	}
+	// details of the violations are too long for the check run
+	c.prSvc.CreateSystemMessageAsync(ctx, pr.ID, report.Details())
+
+	if !report.Enforced() {
+		return nil
+	}
+	if err := c.prMgr.PipelineRun.UpdateStatusByID(ctx, pr.ID, prmodels.StatusFailed); err != nil {
+		return err
+	}
+	return perror.Wrapf(herrors.ErrPolicyViolated, "cluster %s violates policies: %s", cluster.Name, report.Summary())
+}
+
+// checkDeprecatedAPIs reports the deprecated apis used by the cluster at the commit as a check run of the pipelinerun,
+// and fails the pipelinerun if any api is removed in the region. Deploys are not blocked if the check fails.
+func (c *controller) checkDeprecatedAPIs(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, tr *trmodels.TemplateRelease, pr *prmodels.Pipelinerun, commit string) error {
+	findings, err := c.deprecatedAPISvc.CheckCluster(ctx, application, cluster, tr, commit)
+	if err != nil {
+		log.Warningf(ctx, "failed to check deprecated apis of cluster %s: %v", cluster.Name, err)
+		return nil
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	removed := deprecatedapi.Removed(findings)
+	status, message := prmodels.CheckStatusSuccess,
+		fmt.Sprintf("%d objects use apis deprecated in region %s", len(findings), cluster.RegionName)
+	if len(removed) > 0 {
+		status, message = prmodels.CheckStatusFailure,
+			fmt.Sprintf("%d objects use apis removed in region %s", len(removed), cluster.RegionName)
+	}
+	if _, err := c.prMgr.Check.CreateCheckRun(ctx, &prmodels.CheckRun{
+		Name:          deprecatedapiservice.CheckRunName,
+		Status:        status,
+		Message:       message,
+		PipelineRunID: pr.ID,
+	}); err != nil {
+		return err
+	}
+	lines := []string{message}
+	for _, finding := range findings {
+		lines = append(lines, fmt.Sprintf("- [%s] %s", finding.Status, finding))
+	}
+	c.prSvc.CreateSystemMessageAsync(ctx, pr.ID, strings.Join(lines, "\n"))
+
+	if len(removed) == 0 {
+		return nil
+	}
+	if err := c.prMgr.PipelineRun.UpdateStatusByID(ctx, pr.ID, prmodels.StatusFailed); err != nil {
+		return err
+	}
+	return perror.Wrapf(herrors.ErrAPIRemoved, "cluster %s: %s", cluster.Name, message)
+}
+
 func (c *controller) InternalGetClusterStatus(ctx context.Context,
 	clusterID uint) (_ *GetClusterStatusResponse, err error) {
 	// auth jwt token
@@ -30,6 +30,7 @@ import (
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
@@ -39,11 +40,13 @@ import (
 	tokensvc "github.com/horizoncd/horizon/pkg/token/service"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/horizoncd/horizon/pkg/workload"
 )
 
 func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *PipelinerunIDResponse, err error) {
 	const op = "cluster controller: restart "
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
@@ -93,6 +96,7 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 	// 3. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    commit,
 	}); err != nil {
@@ -117,7 +121,8 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	r *DeployRequest) (_ *PipelinerunIDResponse, err error) {
 	const op = "cluster controller: deploy"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get models and do some validation
 	currentUser, err := common.UserFromContext(ctx)
@@ -136,6 +141,12 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if err := c.quotaSvc.Check(ctx, cluster, map[string]interface{}{
+		resource.SectionApplication: clusterFiles.ApplicationJSONBlob,
+		resource.SectionPipeline:    clusterFiles.PipelineJSONBlob,
+	}); err != nil {
+		return nil, err
+	}
 	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
 	if err != nil {
 		return nil, err
@@ -202,7 +213,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if clusterFiles.PipelineJSONBlob != nil {
 		pipelineJSONBlob = clusterFiles.PipelineJSONBlob
 	}
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.RegionName)
 	if err != nil {
 		return nil, err
 	}
@@ -284,7 +295,8 @@ func getDeployImage(imageURL, deployTag string) (string, error) {
 func (c *controller) Rollback(ctx context.Context,
 	clusterID uint, r *RollbackRequest) (_ *PipelinerunIDResponse, err error) {
 	const op = "cluster controller: rollback"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get pipelinerun to rollback, and do some validation
 	pipelinerun, err := c.prMgr.PipelineRun.GetByID(ctx, r.PipelinerunID)
@@ -319,6 +331,20 @@ func (c *controller) Rollback(ctx context.Context,
 		return nil, err
 	}
 
+	// 2.1 check resource quotas with the config to roll back to
+	rollbackFiles, err := c.clusterGitRepo.GetClusterByCommit(ctx, application.Name, cluster.Name,
+		cluster.Template, pipelinerun.ConfigCommit)
+	if err != nil {
+		return nil, err
+	}
+	quotaConfig := map[string]interface{}{
+		resource.SectionApplication: rollbackFiles.ApplicationJSONBlob,
+		resource.SectionPipeline:    rollbackFiles.PipelineJSONBlob,
+	}
+	if err := c.quotaSvc.Check(ctx, cluster, quotaConfig); err != nil {
+		return nil, err
+	}
+
 	// 3. create record
 	prCreated, err := c.prMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
@@ -359,6 +385,24 @@ func (c *controller) Rollback(ctx context.Context,
 		return nil, err
 	}
 
+	// 4.1 evaluate policies and check deprecated apis on the manifests of the revision to roll back to
+	// as deploys do, which are rendered by the template release of the revision
+	templateFromFile, err := c.clusterGitRepo.GetClusterTemplate(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx,
+		templateFromFile.Name, templateFromFile.Release)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.evaluatePolicies(ctx, application, cluster, tr, prCreated, newConfigCommit); err != nil {
+		return nil, err
+	}
+	if err := c.checkDeprecatedAPIs(ctx, application, cluster, tr, prCreated, newConfigCommit); err != nil {
+		return nil, err
+	}
+
 	// 5. merge branch & update config commit and status
 	masterRevision, err := c.clusterGitRepo.MergeBranch(ctx, application.Name, cluster.Name,
 		gitrepo.GitOpsBranch, c.clusterGitRepo.DefaultBranch(), &prCreated.ID)
@@ -376,6 +420,7 @@ func (c *controller) Rollback(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	c.recordUsage(ctx, cluster, quotaConfig)
 
 	// 7. create cluster in cd system
 	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
@@ -399,7 +444,8 @@ func (c *controller) Rollback(ctx context.Context,
 	}
 
 	// 8. reset cluster status
-	if cluster.Status == common.ClusterStatusFreed {
+	if cluster.Status == common.ClusterStatusFreed ||
+		cluster.Status == common.ClusterStatusHibernated {
 		cluster.Status = common.ClusterStatusEmpty
 		cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
 		if err != nil {
@@ -410,6 +456,7 @@ func (c *controller) Rollback(ctx context.Context,
 	// 9. deploy cluster in cd and update status
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
 	}); err != nil {
@@ -469,14 +516,36 @@ func (c *controller) ExecuteAction(ctx context.Context, clusterID uint,
 		return err
 	}
 
-	return c.k8sutil.ExecuteAction(ctx, &cd.ExecuteActionParams{
+	if err := c.k8sutil.ExecuteAction(ctx, &cd.ExecuteActionParams{
 		RegionEntity: regionEntity,
 		Namespace:    envValue.Namespace,
 		Action:       action,
 		GVR:          gvr,
 		ResourceName: cluster.Name,
 		ClusterID:    clusterID,
-	})
+	}); err != nil {
+		return err
+	}
+
+	// record promotions on the pipelinerun being released
+	if message, ok := promotionMessages[action]; ok {
+		pr, err := c.prMgr.PipelineRun.GetLatestSuccessByClusterID(ctx, clusterID)
+		if err != nil {
+			log.Warningf(ctx, "failed to get latest pipelinerun of cluster %d: %v", clusterID, err)
+			return nil
+		}
+		if pr != nil {
+			c.prSvc.CreateSystemMessageAsync(ctx, pr.ID, message)
+		}
+	}
+	return nil
+}
+
+var promotionMessages = map[string]string{
+	workload.ActionPromote:           common.MessagePipelinerunPromoted,
+	workload.ActionPromoteFull:       common.MessagePipelinerunPromotedFully,
+	workload.ActionAbort:             common.MessagePipelinerunAborted,
+	workload.ActionScaleDownPrevious: common.MessagePipelinerunScaledDown,
 }
 
 // onlineCommand the location of online.sh in pod is /home/appops/.probe/online-once.sh
@@ -502,7 +571,8 @@ bash "$OFFLINE_SHELL"
 // Deprecated
 func (c *controller) Online(ctx context.Context, clusterID uint, r *ExecRequest) (_ ExecResponse, err error) {
 	const op = "cluster controller: online"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	r.Commands = onlineCommands
 	return c.Exec(ctx, clusterID, r)
@@ -511,7 +581,8 @@ func (c *controller) Online(ctx context.Context, clusterID uint, r *ExecRequest)
 // Deprecated
 func (c *controller) Offline(ctx context.Context, clusterID uint, r *ExecRequest) (_ ExecResponse, err error) {
 	const op = "cluster controller: offline"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	r.Commands = offlineCommands
 	return c.Exec(ctx, clusterID, r)
@@ -519,7 +590,8 @@ func (c *controller) Offline(ctx context.Context, clusterID uint, r *ExecRequest
 
 func (c *controller) Exec(ctx context.Context, clusterID uint, r *ExecRequest) (_ ExecResponse, err error) {
 	const op = "cluster controller: exec"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
@@ -630,7 +702,7 @@ func (c *controller) GetGrafanaDashBoard(ctx context.Context, clusterID uint) (*
 	}
 
 	return &GetGrafanaDashboardsResponse{
-		Host: c.grafanaConfig.Host,
+		Host: c.grafanaService.Config().Host,
 		Params: map[string]string{
 			"kiosk":           "iframe",
 			"theme":           "light",
@@ -646,7 +718,8 @@ func (c *controller) GetGrafanaDashBoard(ctx context.Context, clusterID uint) (*
 // Deprecated: Upgrade v1 to v2
 func (c *controller) Upgrade(ctx context.Context, clusterID uint) error {
 	const op = "cluster controller: upgrade to v2"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. validate infos
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
@@ -696,10 +769,21 @@ func (c *controller) Upgrade(ctx context.Context, clusterID uint) error {
 	// TODO(zhuxu): remove strong dependencies on db updates, just print an err log when updates fail
 	cluster.Template = targetRelease.TemplateName
 	cluster.TemplateRelease = targetRelease.Name
-	_, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
+	cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
 	if err != nil {
 		return err
 	}
+
+	// 6. cache the resources requested by the upgraded config
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		log.Warningf(ctx, "failed to get upgraded config of cluster %s, err: %v", cluster.Name, err)
+		return nil
+	}
+	c.recordUsage(ctx, cluster, map[string]interface{}{
+		resource.SectionApplication: files.ApplicationJSONBlob,
+		resource.SectionPipeline:    files.PipelineJSONBlob,
+	})
 	return nil
 }
 
@@ -16,7 +16,9 @@ package cluster
 
 import (
 	"context"
+	"io"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -33,6 +35,7 @@ import (
 
 	"github.com/argoproj/gitops-engine/pkg/health"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -84,7 +87,8 @@ func (c *controller) GetClusterPipelinerunStatus(ctx context.Context,
 // Deprecated
 func (c *controller) GetClusterStatus(ctx context.Context, clusterID uint) (_ *GetClusterStatusResponse, err error) {
 	const op = "cluster controller: get cluster status"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	resp := &GetClusterStatusResponse{}
 
@@ -272,7 +276,7 @@ func (c *controller) getLatestPipelinerunByClusterID(ctx context.Context,
 
 func (c *controller) getLatestPipelineRunObject(ctx context.Context, cluster *clustermodels.Cluster,
 	pipelinerun *prmodels.Pipelinerun) (*v1beta1.PipelineRun, error) {
-	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName)
+	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName, cluster.RegionName)
 	if err != nil {
 		return nil, err
 	}
@@ -371,6 +375,85 @@ func (c *controller) GetContainerLog(ctx context.Context, clusterID uint, podNam
 	return c.k8sutil.GetContainerLog(ctx, &param)
 }
 
+func (c *controller) GetClusterLogs(ctx context.Context, clusterID uint,
+	r *GetClusterLogsRequest) (<-chan string, error) {
+	const op = "cluster controller: get cluster logs"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	params, err := c.getClusterLogsParams(ctx, clusterID, r)
+	if err != nil {
+		return nil, err
+	}
+	return c.k8sutil.GetClusterLogs(ctx, params)
+}
+
+func (c *controller) ArchiveClusterLogs(ctx context.Context, clusterID uint,
+	r *GetClusterLogsRequest, w io.Writer) error {
+	const op = "cluster controller: archive cluster logs"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	params, err := c.getClusterLogsParams(ctx, clusterID, r)
+	if err != nil {
+		return err
+	}
+	return c.k8sutil.ArchiveClusterLogs(ctx, params, w)
+}
+
+func (c *controller) getClusterLogsParams(ctx context.Context, clusterID uint,
+	r *GetClusterLogsRequest) (*cd.GetClusterLogsParams, error) {
+	params := &cd.GetClusterLogsParams{
+		Revision:  r.Revision,
+		Container: r.Container,
+		Previous:  r.Previous,
+		Follow:    r.Follow,
+	}
+	if r.TailLines > 0 {
+		params.TailLines = &r.TailLines
+	}
+	if r.SinceTime != nil {
+		sinceTime := metav1.NewTime(*r.SinceTime)
+		params.SinceTime = &sinceTime
+	}
+	if r.Filter != "" {
+		filter, err := regexp.Compile(r.Filter)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid filter %s: %v", r.Filter, err)
+		}
+		params.Filter = filter
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return nil, err
+	}
+
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+
+	params.RegionEntity = regionEntity
+	params.Namespace = envValue.Namespace
+	params.Cluster = cluster.Name
+	return params, nil
+}
+
 func (c *controller) GetPodEvents(ctx context.Context, clusterID uint, podName string) (interface{}, error) {
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
@@ -490,6 +573,7 @@ func (c *controller) GetResourceTree(ctx context.Context, clusterID uint) (resp
 		Environment:  cluster.EnvironmentName,
 		Cluster:      cluster.Name,
 		RegionEntity: regionEntity,
+		WithUsage:    true,
 	})
 	if err != nil {
 		return
@@ -499,8 +583,12 @@ func (c *controller) GetResourceTree(ctx context.Context, clusterID uint) (resp
 	resp.Nodes = make(map[string]*ResourceNode, len(resourceTree))
 	for i := range resourceTree {
 		n := ResourceNode{
-			ResourceNode: resourceTree[i].ResourceNode,
-			PodDetail:    resourceTree[i].PodDetail,
+			ResourceNode:  resourceTree[i].ResourceNode,
+			PodDetail:     resourceTree[i].PodDetail,
+			Runs:          resourceTree[i].Runs,
+			NodeRollouts:  resourceTree[i].NodeRollouts,
+			Usage:         resourceTree[i].Usage,
+			RevisionUsage: resourceTree[i].RevisionUsage,
 		}
 		resp.Nodes[n.UID] = &n
 	}
@@ -535,6 +623,8 @@ func (c *controller) GetStep(ctx context.Context, clusterID uint) (resp *GetStep
 			ManualPaused: steps.ManualPaused,
 			AutoPromote:  steps.AutoPromote,
 			Extra:        steps.Extra,
+			BlueGreen:    steps.BlueGreen,
+			Traffic:      steps.Traffic,
 		}
 	} else {
 		resp = &GetStepResponse{
@@ -28,7 +28,16 @@ import (
 	appservice "github.com/horizoncd/horizon/pkg/application/service"
 	badgemodels "github.com/horizoncd/horizon/pkg/badge/models"
 	clustercd "github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	deprecatedapimodels "github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+	deprecatedapiservice "github.com/horizoncd/horizon/pkg/deprecatedapi/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	gittriggermodels "github.com/horizoncd/horizon/pkg/gittrigger/models"
+	hibernationmodels "github.com/horizoncd/horizon/pkg/hibernation/models"
+	policymodels "github.com/horizoncd/horizon/pkg/policy/models"
+	policyservice "github.com/horizoncd/horizon/pkg/policy/service"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
 
 	v1 "k8s.io/api/core/v1"
@@ -81,6 +90,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/global"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	tmodel "github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	gitlabschema "github.com/horizoncd/horizon/pkg/templaterelease/schema/gitlab"
@@ -453,6 +463,10 @@ var (
 
 const secondsInOneDay = 24 * 3600
 
+func emptyTemplateResources() *templateresource.Config {
+	return &templateresource.Config{}
+}
+
 // nolint
 func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&appmodels.Application{}, &models.Cluster{}, &groupmodels.Group{},
@@ -460,7 +474,9 @@ func TestMain(m *testing.M) {
 		&registrymodels.Registry{}, eventmodels.Event{}, &templatemodels.Template{},
 		&regionmodels.Region{}, &envregionmodels.EnvironmentRegion{}, &eventmodels.Event{},
 		&prmodels.Pipelinerun{}, &schematagmodel.ClusterTemplateSchemaTag{}, &tmodel.Tag{},
-		&envmodels.Environment{}, &tokenmodels.Token{}, &badgemodels.Badge{}); err != nil {
+		&envmodels.Environment{}, &tokenmodels.Token{}, &badgemodels.Badge{},
+		&gittriggermodels.GitTrigger{}, &hibernationmodels.Hibernation{}, &quotamodels.Quota{},
+		&policymodels.Policy{}, &deprecatedapimodels.DeprecatedAPI{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
@@ -512,6 +528,7 @@ func TestAll(t *testing.T) {
 	t.Run("TestListUserClustersByNameFuzzily", testListUserClustersByNameFuzzily)
 	t.Run("TestListClusterWithExpiry", testListClusterWithExpiry)
 	t.Run("TestGetClusterStatusV2", testGetClusterStatusV2)
+	t.Run("TestHibernateCluster", testHibernateCluster)
 }
 
 // nolint
@@ -640,6 +657,9 @@ func test(t *testing.T) {
 	c = &controller{
 		clusterMgr:           manager.ClusterMgr,
 		clusterGitRepo:       clusterGitRepo,
+		quotaSvc:             quotaservice.NewService(manager, clusterGitRepo, emptyTemplateResources),
+		policySvc:            policyservice.NewService(manager, clusterGitRepo, nil),
+		deprecatedAPISvc:     deprecatedapiservice.NewService(manager, clusterGitRepo, nil),
 		clusterSvc:           cluterservice.NewService(appSvc, clusterGitRepo, manager),
 		commitGetter:         commitGetter,
 		cd:                   cd,
@@ -670,41 +690,41 @@ func test(t *testing.T) {
 	}
 
 	commitGetter.EXPECT().GetHTTPLink(gomock.Any()).Return("https://cloudnative.com:22222/demo/springboot-demo", nil).AnyTimes()
-	applicationGitRepo.EXPECT().GetApplication(ctx, gomock.Any(), gomock.Any()).
+	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(&appgitrepo.GetResponse{
 			Manifest:     nil,
 			BuildConf:    pipelineJSONBlob,
 			TemplateConf: applicationJSONBlob,
 		}, nil).AnyTimes()
-	clusterGitRepo.EXPECT().CreateCluster(ctx, gomock.Any()).Return(nil).Times(2)
-	clusterGitRepo.EXPECT().UpdateCluster(ctx, gomock.Any()).Return(nil).Times(1)
-	clusterGitRepo.EXPECT().GetCluster(ctx, "app",
+	clusterGitRepo.EXPECT().CreateCluster(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	clusterGitRepo.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), "app",
 		"app-cluster", templateName).Return(&gitrepo.ClusterFiles{
 		PipelineJSONBlob:    pipelineJSONBlob,
 		ApplicationJSONBlob: applicationJSONBlob,
 	}, nil).AnyTimes()
-	clusterGitRepo.EXPECT().GetCluster(ctx, "app",
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), "app",
 		"app-cluster-mergepatch", "javaapp").Return(&gitrepo.ClusterFiles{
 		PipelineJSONBlob:    pipelineJSONBlob,
 		ApplicationJSONBlob: applicationJSONBlob,
 	}, nil).AnyTimes()
-	clusterGitRepo.EXPECT().GetConfigCommit(ctx, gomock.Any(), gomock.Any()).Return(&gitrepo.ClusterCommit{
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.ClusterCommit{
 		Master: "master-commit",
 		Gitops: "gitops-commit",
 	}, nil).AnyTimes()
-	clusterGitRepo.EXPECT().GetEnvValue(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.EnvValue{
+	clusterGitRepo.EXPECT().GetEnvValue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.EnvValue{
 		Namespace: "test-1",
 	}, nil).AnyTimes()
-	clusterGitRepo.EXPECT().GetRepoInfo(ctx, gomock.Any(), gomock.Any()).Return(&gitrepo.RepoInfo{
+	clusterGitRepo.EXPECT().GetRepoInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.RepoInfo{
 		GitRepoURL: "ssh://xxxx",
 		ValueFiles: []string{},
 	}).AnyTimes()
 	imageName := "image"
-	clusterGitRepo.EXPECT().UpdatePipelineOutput(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("image-commit", nil).AnyTimes()
+	clusterGitRepo.EXPECT().UpdatePipelineOutput(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("image-commit", nil).AnyTimes()
 	clusterGitRepo.EXPECT().DefaultBranch().Return("master").AnyTimes()
-	cd.EXPECT().CreateCluster(ctx, gomock.Any()).Return(nil).AnyTimes()
+	cd.EXPECT().CreateCluster(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	clusterGitRepo.EXPECT().UpdateTags(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	clusterGitRepo.EXPECT().UpdateTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	createClusterRequest := &CreateClusterRequest{
 		Base: &Base{
@@ -857,15 +877,15 @@ func test(t *testing.T) {
 	t.Logf("%v", getByName)
 
 	tekton := tektonmock.NewMockInterface(mockCtl)
-	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
-	tekton.EXPECT().CreatePipelineRun(ctx, gomock.Any()).Return("abc", nil).Times(2)
-	tekton.EXPECT().GetPipelineRunByID(ctx, gomock.Any()).Return(pr, nil).AnyTimes()
+	tektonFty.EXPECT().GetTekton(gomock.Any(), gomock.Any()).Return(tekton, nil).AnyTimes()
+	tekton.EXPECT().CreatePipelineRun(gomock.Any(), gomock.Any()).Return("abc", nil).Times(2)
+	tekton.EXPECT().GetPipelineRunByID(gomock.Any(), gomock.Any()).Return(pr, nil).AnyTimes()
 	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
 
-	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
-	tektonCollector.EXPECT().GetPipelineRun(ctx, gomock.Any()).Return(pr, nil).AnyTimes()
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any(), gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	tektonCollector.EXPECT().GetPipelineRun(gomock.Any(), gomock.Any()).Return(pr, nil).AnyTimes()
 
-	commitGetter.EXPECT().GetCommit(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(&git.Commit{
+	commitGetter.EXPECT().GetCommit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&git.Commit{
 		ID:      "code-commit-id",
 		Message: "msg",
 	}, nil)
@@ -881,17 +901,17 @@ func test(t *testing.T) {
 	b, _ = json.Marshal(buildDeployResp)
 	t.Logf("%v", string(b))
 
-	clusterGitRepo.EXPECT().GetRestartTime(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+	clusterGitRepo.EXPECT().GetRestartTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return("", nil).AnyTimes()
-	clusterGitRepo.EXPECT().MergeBranch(ctx, gomock.Any(), gomock.Any(), gomock.Any(),
+	clusterGitRepo.EXPECT().MergeBranch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return("newest-commit", nil).AnyTimes()
-	clusterGitRepo.EXPECT().GetRepoInfo(ctx, gomock.Any(), gomock.Any()).Return(&gitrepo.RepoInfo{
+	clusterGitRepo.EXPECT().GetRepoInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.RepoInfo{
 		GitRepoURL: "ssh://xxxx.git",
 		ValueFiles: []string{"file1", "file2"},
 	}).AnyTimes()
 
-	cd.EXPECT().DeployCluster(ctx, gomock.Any()).Return(nil).AnyTimes()
-	cd.EXPECT().GetClusterStateV1(ctx, gomock.Any()).Return(nil, herrors.NewErrNotFound(herrors.PodsInK8S, "test"))
+	cd.EXPECT().DeployCluster(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	cd.EXPECT().GetClusterStateV1(gomock.Any(), gomock.Any()).Return(nil, herrors.NewErrNotFound(herrors.PodsInK8S, "test"))
 	internalDeployResp, err := c.InternalDeploy(ctx, resp.ID, &InternalDeployRequest{
 		PipelinerunID: buildDeployResp.PipelinerunID,
 	})
@@ -954,11 +974,11 @@ func test(t *testing.T) {
 	commitMsg := "code-commit-msg"
 	configDiff := "config-diff"
 	commitGetter.EXPECT().GetCommitHistoryLink(gomock.Any(), gomock.Any()).Return("https://cloudnative.com:22222/demo/springboot-demo/-/commits/"+codeBranch, nil).AnyTimes()
-	commitGetter.EXPECT().GetCommit(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(&git.Commit{
+	commitGetter.EXPECT().GetCommit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&git.Commit{
 		ID:      commitID,
 		Message: commitMsg,
 	}, nil)
-	clusterGitRepo.EXPECT().CompareConfig(ctx, gomock.Any(), gomock.Any(),
+	clusterGitRepo.EXPECT().CompareConfig(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return(configDiff, nil).AnyTimes()
 
 	getdiffResp, err := c.GetDiff(ctx, resp.ID, codemodels.GitRefTypeBranch, codeBranch)
@@ -978,7 +998,7 @@ func test(t *testing.T) {
 	t.Logf("%s", string(b))
 
 	// test restart
-	clusterGitRepo.EXPECT().UpdateRestartTime(ctx, gomock.Any(), gomock.Any(),
+	clusterGitRepo.EXPECT().UpdateRestartTime(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).Return("update-image-commit", nil)
 
 	restartResp, err := c.Restart(ctx, resp.ID)
@@ -992,8 +1012,8 @@ func test(t *testing.T) {
 	assert.NotNil(t, pr.FinishedAt)
 
 	// test deploy
-	clusterGitRepo.EXPECT().GetPipelineOutput(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, herrors.ErrPipelineOutputEmpty).Times(1)
-	commitGetter.EXPECT().GetCommit(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(&git.Commit{
+	clusterGitRepo.EXPECT().GetPipelineOutput(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, herrors.ErrPipelineOutputEmpty).Times(1)
+	commitGetter.EXPECT().GetCommit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&git.Commit{
 		ID:      commitID,
 		Message: commitMsg,
 	}, nil).AnyTimes()
@@ -1004,7 +1024,7 @@ func test(t *testing.T) {
 	assert.Equal(t, herrors.ErrShouldBuildDeployFirst, perror.Cause(err))
 	assert.Nil(t, deployResp)
 
-	clusterGitRepo.EXPECT().GetPipelineOutput(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil,
+	clusterGitRepo.EXPECT().GetPipelineOutput(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil,
 		nil).Times(1)
 
 	deployResp, err = c.Deploy(ctx, resp.ID, &DeployRequest{
@@ -1018,7 +1038,7 @@ func test(t *testing.T) {
 		Image *string
 	}
 
-	clusterGitRepo.EXPECT().GetPipelineOutput(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+	clusterGitRepo.EXPECT().GetPipelineOutput(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(&PipelineOutput{Image: &imageName}, nil).AnyTimes()
 
 	deployResp, err = c.Deploy(ctx, resp.ID, &DeployRequest{
@@ -1036,7 +1056,7 @@ func test(t *testing.T) {
 	assert.Equal(t, string(prmodels.StatusRunning), pr.Status)
 
 	// test next
-	k8sutil.EXPECT().ExecuteAction(ctx, gomock.Any()).Return(nil)
+	k8sutil.EXPECT().ExecuteAction(gomock.Any(), gomock.Any()).Return(nil)
 	err = c.ExecuteAction(ctx, resp.ID, "next", schema.GroupVersionResource{})
 	assert.Nil(t, err)
 
@@ -1051,7 +1071,7 @@ func test(t *testing.T) {
 		},
 	}
 
-	k8sutil.EXPECT().Exec(ctx, gomock.Any()).Return(execResp, nil).Times(3)
+	k8sutil.EXPECT().Exec(gomock.Any(), gomock.Any()).Return(execResp, nil).Times(3)
 
 	execRequest := &ExecRequest{
 		PodList:  []string{"pod1", "pod2"},
@@ -1087,14 +1107,19 @@ func test(t *testing.T) {
 	clusterGitRepo.EXPECT().GetClusterValueFiles(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return([]gitrepo.ClusterValueFile{valueFile}, nil)
 	// test rollback
-	clusterGitRepo.EXPECT().Rollback(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+	clusterGitRepo.EXPECT().Rollback(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return("rollback-commit", nil).AnyTimes()
-	clusterGitRepo.EXPECT().GetClusterTemplate(ctx, application.Name, resp.Name).
+	clusterGitRepo.EXPECT().GetClusterByCommit(gomock.Any(), application.Name, resp.Name,
+		gomock.Any(), gomock.Any()).Return(&gitrepo.ClusterFiles{
+		PipelineJSONBlob:    pipelineJSONBlob,
+		ApplicationJSONBlob: applicationJSONBlob,
+	}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().GetClusterTemplate(gomock.Any(), application.Name, resp.Name).
 		Return(&gitrepo.ClusterTemplate{
 			Name:    resp.Template.Name,
 			Release: resp.Template.Release,
 		}, nil).AnyTimes()
-	clusterGitRepo.EXPECT().CheckAndSyncGitOpsBranch(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+	clusterGitRepo.EXPECT().CheckAndSyncGitOpsBranch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil).AnyTimes()
 	// update status to 'ok'
 	err = manager.PRMgr.PipelineRun.UpdateResultByID(ctx, buildDeployResp.PipelinerunID, &prmodels.Result{
@@ -1121,7 +1146,7 @@ func test(t *testing.T) {
 	assert.Equal(t, "test_value", tags[0].Value)
 	c.tagMgr = tagManager
 
-	k8sutil.EXPECT().DeletePods(ctx, gomock.Any()).Return(
+	k8sutil.EXPECT().DeletePods(gomock.Any(), gomock.Any()).Return(
 		map[string]clustercd.OperationResult{
 			"pod1": {Result: true},
 		}, nil)
@@ -1134,7 +1159,7 @@ func test(t *testing.T) {
 
 	podExist := "exist"
 	podNotExist := "notexist"
-	k8sutil.EXPECT().GetPod(ctx, gomock.Any()).DoAndReturn(
+	k8sutil.EXPECT().GetPod(gomock.Any(), gomock.Any()).DoAndReturn(
 		func(_ context.Context, param *clustercd.GetPodParams) (*v1.Pod, error) {
 			if param.Pod == podExist {
 				return &v1.Pod{}, nil
@@ -1204,7 +1229,7 @@ func test(t *testing.T) {
 		Name: "app-cluster-mergepatch",
 	}
 
-	clusterGitRepo.EXPECT().CreateCluster(ctx, gomock.Any()).DoAndReturn(
+	clusterGitRepo.EXPECT().CreateCluster(gomock.Any(), gomock.Any()).DoAndReturn(
 		func(_ context.Context, params *gitrepo.CreateClusterParams) error {
 			blob := map[string]interface{}{}
 			err := json.Unmarshal([]byte(mergedJSONStr), &blob)
@@ -1272,7 +1297,7 @@ func test(t *testing.T) {
 			},
 		},
 	}
-	clusterGitRepo.EXPECT().UpdateCluster(ctx, gomock.Any()).DoAndReturn(
+	clusterGitRepo.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).DoAndReturn(
 		func(_ context.Context, params *gitrepo.UpdateClusterParams) error {
 			blob := map[string]interface{}{}
 			err := json.Unmarshal([]byte(mergedJSONStr), &blob)
@@ -1412,6 +1437,9 @@ func testV2(t *testing.T) {
 	c = &controller{
 		clusterMgr:           manager.ClusterMgr,
 		clusterGitRepo:       clusterGitRepo,
+		quotaSvc:             quotaservice.NewService(manager, clusterGitRepo, emptyTemplateResources),
+		policySvc:            policyservice.NewService(manager, clusterGitRepo, nil),
+		deprecatedAPISvc:     deprecatedapiservice.NewService(manager, clusterGitRepo, nil),
 		applicationMgr:       appMgr,
 		templateMgr:          templateMgr,
 		templateReleaseMgr:   trMgr,
@@ -1423,6 +1451,8 @@ func testV2(t *testing.T) {
 		prMgr:                manager.PRMgr,
 		userManager:          manager.UserMgr,
 		badgeMgr:             manager.BadgeMgr,
+		gitTriggerMgr:        manager.GitTriggerMgr,
+		hibernationMgr:       manager.HibernationMgr,
 		autoFreeSvc:          parameter.AutoFreeSvc,
 		userSvc:              userservice.NewService(manager),
 		schemaTagManager:     manager.ClusterSchemaTagMgr,
@@ -1439,7 +1469,7 @@ func testV2(t *testing.T) {
 			BuildConf:    pipelineJSONBlob,
 			TemplateConf: applicationJSONBlob,
 		}, nil).Times(1)
-	clusterGitRepo.EXPECT().CreateCluster(ctx, gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().CreateCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 	createClusterName := "app-cluster2"
 	createReq := &CreateClusterRequestV2{
@@ -1477,7 +1507,7 @@ func testV2(t *testing.T) {
 	t.Logf("%+v", resp)
 
 	// then get cluster
-	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, createClusterName, templateName).Return(&gitrepo.ClusterFiles{
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), applicationName, createClusterName, templateName).Return(&gitrepo.ClusterFiles{
 		PipelineJSONBlob:    pipelineJSONBlob,
 		ApplicationJSONBlob: applicationJSONBlob,
 		Manifest:            nil,
@@ -1504,7 +1534,7 @@ func testV2(t *testing.T) {
 	t.Logf("%+v", getClusterResp)
 
 	// update v2
-	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, createClusterName, templateName).Return(&gitrepo.ClusterFiles{
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), applicationName, createClusterName, templateName).Return(&gitrepo.ClusterFiles{
 		PipelineJSONBlob:    pipelineJSONBlob,
 		ApplicationJSONBlob: applicationJSONBlob,
 		Manifest:            nil,
@@ -1532,13 +1562,13 @@ func testV2(t *testing.T) {
 	var manifest = make(map[string]interface{})
 	manifest["Version"] = common.MetaVersion2
 
-	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, createClusterName, templateName).Return(&gitrepo.ClusterFiles{
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), applicationName, createClusterName, templateName).Return(&gitrepo.ClusterFiles{
 		PipelineJSONBlob:    pipelineJSONBlob,
 		ApplicationJSONBlob: applicationJSONBlob,
 		Manifest:            manifest,
 	}, nil).Times(1)
-	clusterGitRepo.EXPECT().UpdateCluster(ctx, gomock.Any()).Return(nil).Times(1)
-	clusterGitRepo.EXPECT().UpdateTags(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().UpdateTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), templateName, "v1.0.0", gomock.Any()).
 		Return(&trschema.Schemas{
 			Application: &trschema.Schema{
@@ -1552,6 +1582,48 @@ func testV2(t *testing.T) {
 	t.Logf("%+v", err)
 	assert.Nil(t, err)
 
+	// switching the template release migrates the configs
+	_, err = trMgr.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName: templateName,
+		Name:         "v1.1.0",
+		ChartVersion: "v1.1.0-test",
+		ChartName:    templateName,
+	})
+	assert.Nil(t, err)
+	c.migrationGetter = &fakeMigrationGetter{migrations: []*migration.Migration{{
+		From:  "v1.0.0",
+		To:    "v1.1.0",
+		Rules: []*migration.Rule{{Op: migration.OpSet, Path: "pipeline.migrated", Value: true}},
+	}}}
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), applicationName, createClusterName, templateName).Return(&gitrepo.ClusterFiles{
+		PipelineJSONBlob:    pipelineJSONBlob,
+		ApplicationJSONBlob: applicationJSONBlob,
+		Manifest:            manifest,
+	}, nil).Times(1)
+	clusterGitRepo.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *gitrepo.UpdateClusterParams) error {
+			assert.Equal(t, true, params.PipelineJSONBlob["migrated"])
+			assertMapEqual(t, applicationJSONBlob, params.ApplicationJSONBlob)
+			assert.Equal(t, "v1.1.0", params.TemplateRelease.Name)
+			return nil
+		}).Times(1)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), templateName, "v1.1.0", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{
+				JSONSchema: applicationSchema,
+			},
+			Pipeline: &trschema.Schema{
+				JSONSchema: pipelineSchema,
+			},
+		}, nil).Times(1)
+	err = c.UpdateClusterV2(ctx, getClusterResp.ID, &UpdateClusterRequestV2{
+		TemplateInfo: &codemodels.TemplateInfo{
+			Name:    templateName,
+			Release: "v1.1.0",
+		},
+	}, true)
+	assert.Nil(t, err)
+
 	registry := registrymock.NewMockRegistry(mockCtl)
 	registryFty.EXPECT().GetRegistryByConfig(gomock.Any(), gomock.Any()).Return(registry, nil).Times(1)
 	registry.EXPECT().DeleteImage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
@@ -1637,6 +1709,9 @@ func testUpgrade(t *testing.T) {
 	c = &controller{
 		clusterMgr:            manager.ClusterMgr,
 		clusterGitRepo:        clusterGitRepo,
+		quotaSvc:              quotaservice.NewService(manager, clusterGitRepo, emptyTemplateResources),
+		policySvc:             policyservice.NewService(manager, clusterGitRepo, nil),
+		deprecatedAPISvc:      deprecatedapiservice.NewService(manager, clusterGitRepo, nil),
 		applicationMgr:        appMgr,
 		templateMgr:           manager.TemplateMgr,
 		templateReleaseMgr:    trMgr,
@@ -1649,6 +1724,8 @@ func testUpgrade(t *testing.T) {
 		userManager:           manager.UserMgr,
 		autoFreeSvc:           parameter.AutoFreeSvc,
 		badgeMgr:              manager.BadgeMgr,
+		gitTriggerMgr:         manager.GitTriggerMgr,
+		hibernationMgr:        manager.HibernationMgr,
 		userSvc:               userservice.NewService(manager),
 		schemaTagManager:      manager.ClusterSchemaTagMgr,
 		applicationGitRepo:    applicationGitRepo,
@@ -1660,7 +1737,7 @@ func testUpgrade(t *testing.T) {
 		memberManager:         manager.MemberMgr,
 	}
 
-	applicationGitRepo.EXPECT().GetApplication(ctx, gomock.Any(), gomock.Any()).
+	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(&appgitrepo.GetResponse{
 			Manifest:     nil,
 			BuildConf:    pipelineJSONBlob,
@@ -1675,8 +1752,8 @@ func testUpgrade(t *testing.T) {
 				JSONSchema: pipelineSchema,
 			},
 		}, nil).Times(1)
-	clusterGitRepo.EXPECT().CreateCluster(ctx, gomock.Any()).Return(nil).Times(1)
-	clusterGitRepo.EXPECT().GetEnvValue(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.EnvValue{
+	clusterGitRepo.EXPECT().CreateCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().GetEnvValue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.EnvValue{
 		Namespace: "test-1",
 	}, nil).AnyTimes()
 
@@ -1705,16 +1782,21 @@ func testUpgrade(t *testing.T) {
 	assert.Equal(t, resp.Application.ID, application.ID)
 	assert.Equal(t, resp.FullPath, "/"+group.Path+"/"+application.Name+"/"+createClusterName)
 
-	clusterGitRepo.EXPECT().GetClusterTemplate(ctx, application.Name, resp.Name).
+	clusterGitRepo.EXPECT().GetClusterTemplate(gomock.Any(), application.Name, resp.Name).
 		Return(&gitrepo.ClusterTemplate{
 			Name:    resp.Template.Name,
 			Release: resp.Template.Release,
 		}, nil).AnyTimes()
-	clusterGitRepo.EXPECT().UpgradeCluster(ctx, gomock.Any()).Return("", nil).Times(1)
+	clusterGitRepo.EXPECT().UpgradeCluster(gomock.Any(), gomock.Any()).Return("", nil).Times(1)
 	// clusterGitRepo.EXPECT().DefaultBranch().Return("master").AnyTimes()
-	// clusterGitRepo.EXPECT().CompareConfig(ctx, gomock.Any(), gomock.Any(),
+	// clusterGitRepo.EXPECT().CompareConfig(gomock.Any(), gomock.Any(), gomock.Any(),
 	// 	gomock.Any(), gomock.Any()).Return("", nil).Times(1)
-	clusterGitRepo.EXPECT().SyncGitOpsBranch(ctx, gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().SyncGitOpsBranch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), application.Name, resp.Name, gomock.Any()).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: applicationJSONBlob,
+		}, nil).Times(1)
 
 	err = c.Upgrade(ctx, resp.ID)
 	assert.Nil(t, err)
@@ -1731,6 +1813,14 @@ func testUpgrade(t *testing.T) {
 	time.Sleep(time.Second * 5)
 }
 
+type fakeMigrationGetter struct {
+	migrations []*migration.Migration
+}
+
+func (g *fakeMigrationGetter) GetMigrations(context.Context, string, string) ([]*migration.Migration, error) {
+	return g.migrations, nil
+}
+
 func assertMapEqual(t *testing.T, expected, got map[string]interface{}) {
 	expectedBuf, err := json.Marshal(expected)
 	if err != nil {
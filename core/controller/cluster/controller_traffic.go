@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/traffic"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) GetTraffic(ctx context.Context, clusterID uint) (*traffic.Traffic, error) {
+	const op = "cluster controller: get traffic"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	cluster, _, _, regionEntity, envValue, err := c.retrieveClusterCtx(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return c.k8sutil.GetTraffic(ctx, &cd.GetTrafficParams{
+		RegionEntity: regionEntity,
+		Namespace:    envValue.Namespace,
+		Cluster:      cluster.Name,
+	})
+}
+
+func (c *controller) ShiftTraffic(ctx context.Context, clusterID uint,
+	r *ShiftTrafficRequest) (*traffic.Traffic, error) {
+	const op = "cluster controller: shift traffic"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	cluster, _, _, regionEntity, envValue, err := c.retrieveClusterCtx(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	shift := &traffic.Shift{
+		Weights:     r.Weights,
+		HeaderRoute: r.HeaderRoute,
+	}
+	result, err := c.k8sutil.ShiftTraffic(ctx, &cd.ShiftTrafficParams{
+		RegionEntity: regionEntity,
+		Namespace:    envValue.Namespace,
+		Cluster:      cluster.Name,
+		Shift:        shift,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// record the shift on the pipelinerun being released
+	pr, err := c.prMgr.PipelineRun.GetLatestSuccessByClusterID(ctx, clusterID)
+	if err != nil {
+		log.Warningf(ctx, "failed to get latest pipelinerun of cluster %d: %v", clusterID, err)
+		return result, nil
+	}
+	if pr != nil {
+		c.prSvc.CreateSystemMessageAsync(ctx, pr.ID,
+			fmt.Sprintf(common.MessagePipelinerunTrafficShifted, describeShift(shift)))
+	}
+	return result, nil
+}
+
+// describeShift describes the shift in a line, such as "stable=95, canary=5, header x-canary=true to canary"
+func describeShift(shift *traffic.Shift) string {
+	parts := make([]string, 0, len(shift.Weights)+1)
+	for _, weight := range shift.Weights {
+		parts = append(parts, fmt.Sprintf("%s=%d", weight.Destination, weight.Weight))
+	}
+	if shift.HeaderRoute != nil {
+		if shift.HeaderRoute.Header == "" {
+			parts = append(parts, "header route removed")
+		} else {
+			parts = append(parts, fmt.Sprintf("header %s=%s to %s", shift.HeaderRoute.Header,
+				shift.HeaderRoute.Value, shift.HeaderRoute.Destination))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -15,7 +15,11 @@
 package cluster
 
 import (
+	"time"
+
 	"github.com/horizoncd/horizon/pkg/cd"
+	hibernationmodels "github.com/horizoncd/horizon/pkg/hibernation/models"
+	"github.com/horizoncd/horizon/pkg/traffic"
 )
 
 const ServerlessTemplateName = "serverless"
@@ -37,6 +41,13 @@ type ExecuteActionRequest struct {
 	Resource string `json:"resource"`
 }
 
+// ShiftTrafficRequest adjusts the traffic of the cluster, weights are kept if Weights is empty,
+// the header route is kept if HeaderRoute is nil and removed if its header is empty
+type ShiftTrafficRequest struct {
+	Weights     []traffic.Weight     `json:"weights"`
+	HeaderRoute *traffic.HeaderRoute `json:"headerRoute"`
+}
+
 type ExecRequest struct {
 	Commands []string `json:"commands"`
 	PodList  []string `json:"podList"`
@@ -88,3 +99,36 @@ func ofBatchResp(resp map[string]cd.OperationResult) BatchResponse {
 	}
 	return resultMap
 }
+
+type HibernationRequest struct {
+	// HibernateSchedule and WakeSchedule are standard cron expressions
+	HibernateSchedule string `json:"hibernateSchedule"`
+	WakeSchedule      string `json:"wakeSchedule"`
+	// IdleTime is a duration such as 2h, the cluster is hibernated
+	// after it is not deployed or updated for IdleTime
+	IdleTime string `json:"idleTime"`
+}
+
+type HibernationResponse struct {
+	ClusterID         uint      `json:"clusterID"`
+	HibernateSchedule string    `json:"hibernateSchedule"`
+	WakeSchedule      string    `json:"wakeSchedule"`
+	IdleTime          string    `json:"idleTime"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func ofHibernation(hibernation *hibernationmodels.Hibernation) *HibernationResponse {
+	idleTime := ""
+	if hibernation.IdleSeconds > 0 {
+		idleTime = time.Duration(hibernation.IdleSeconds * 1e9).String()
+	}
+	return &HibernationResponse{
+		ClusterID:         hibernation.ClusterID,
+		HibernateSchedule: hibernation.HibernateSchedule,
+		WakeSchedule:      hibernation.WakeSchedule,
+		IdleTime:          idleTime,
+		CreatedAt:         hibernation.CreatedAt,
+		UpdatedAt:         hibernation.UpdatedAt,
+	}
+}
@@ -15,8 +15,13 @@
 package cluster
 
 import (
+	"time"
+
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/horizoncd/horizon/pkg/grafana"
+	"github.com/horizoncd/horizon/pkg/podusage"
+	"github.com/horizoncd/horizon/pkg/traffic"
+	"github.com/horizoncd/horizon/pkg/workload"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -72,6 +77,14 @@ type GetClusterPodResponse struct {
 type ResourceNode struct {
 	v1alpha1.ResourceNode
 	PodDetail interface{} `json:"podDetail,omitempty"`
+	// Runs are the latest runs of jobs and cronjobs
+	Runs []workload.Run `json:"runs,omitempty"`
+	// NodeRollouts are the rollout progress per node of daemonsets
+	NodeRollouts []workload.NodeRollout `json:"nodeRollouts,omitempty"`
+	// Usage is the cpu and memory usage of pods vs. their requests and limits
+	Usage *podusage.Pod `json:"usage,omitempty"`
+	// RevisionUsage is the usage aggregated over the pods of revisions, such as replicasets
+	RevisionUsage *podusage.Summary `json:"revisionUsage,omitempty"`
 }
 
 type GetResourceTreeResponse struct {
@@ -85,4 +98,24 @@ type GetStepResponse struct {
 	ManualPaused bool    `json:"manualPaused"`
 	AutoPromote  bool    `json:"autoPromote"`
 	Extra        *string `json:"extra"`
+	// BlueGreen is the status of the blue-green release, nil for other strategies
+	BlueGreen *workload.BlueGreen `json:"blueGreen,omitempty"`
+	// Traffic is the traffic weights of the cluster, nil if it's not routed by a service mesh or gateway
+	Traffic *traffic.Traffic `json:"traffic,omitempty"`
+}
+
+// GetClusterLogsRequest selects the containers and lines of the cluster's logs
+type GetClusterLogsRequest struct {
+	// Revision selects pods of the revision, pods of all revisions are selected if it's empty
+	Revision string
+	// Container selects the container, all containers of pods are selected if it's empty
+	Container string
+	// TailLines limits the lines of each container, all lines are returned if it's 0
+	TailLines int64
+	SinceTime *time.Time
+	// Previous returns the logs of the previous terminated containers
+	Previous bool
+	Follow   bool
+	// Filter is a regular expression, only the matched lines are returned
+	Filter string
 }
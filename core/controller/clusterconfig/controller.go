@@ -0,0 +1,442 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterconfig
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/angular"
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _maxCommitsPageSize = 20
+
+// _mergePipelinerunPattern matches the title of merge commits made by pipelineruns
+var _mergePipelinerunPattern = regexp.MustCompile(`pipelineRunID = (\d+)`)
+
+type Controller interface {
+	// Compare diffs the application, pipeline, env and tag values of the cluster
+	// against the source cluster path by path
+	Compare(ctx context.Context, clusterID, sourceClusterID uint) (*CompareResponse, error)
+	// Sync copies the values of the paths from the source cluster to the cluster
+	// by a config update of the cluster
+	Sync(ctx context.Context, clusterID uint, r *SyncRequest) error
+	// ListCommits lists the commits of the master or gitops branch of the cluster's gitops repo
+	ListCommits(ctx context.Context, clusterID uint, branch string, query *q.Query) ([]*ConfigCommit, error)
+	// Restore sets the application and pipeline values of the commit by a config update of the cluster
+	Restore(ctx context.Context, clusterID uint, commit string) error
+}
+
+type controller struct {
+	clusterMgr     clustermanager.Manager
+	applicationMgr appmanager.Manager
+	tagMgr         tagmanager.Manager
+	clusterGitRepo gitrepo.ClusterGitRepo
+	pipelinerunMgr prmanager.PipelineRunManager
+	userMgr        usermanager.Manager
+	clusterCtl     clusterctl.Controller
+	authorizer     rbac.Authorizer
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param, clusterCtl clusterctl.Controller, authorizer rbac.Authorizer) Controller {
+	return &controller{
+		clusterMgr:     param.ClusterMgr,
+		applicationMgr: param.ApplicationMgr,
+		tagMgr:         param.TagMgr,
+		clusterGitRepo: param.ClusterGitRepo,
+		pipelinerunMgr: param.PRMgr.PipelineRun,
+		userMgr:        param.UserMgr,
+		clusterCtl:     clusterCtl,
+		authorizer:     authorizer,
+	}
+}
+
+func (c *controller) Compare(ctx context.Context, clusterID, sourceClusterID uint) (*CompareResponse, error) {
+	const op = "cluster config controller: compare"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	cluster, config, err := c.getConfig(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.authorizeSource(ctx, sourceClusterID); err != nil {
+		return nil, err
+	}
+	_, sourceConfig, err := c.getConfig(ctx, sourceClusterID)
+	if err != nil {
+		return nil, err
+	}
+	return &CompareResponse{
+		ClusterID:       cluster.ID,
+		SourceClusterID: sourceClusterID,
+		Differences:     jsonpath.Diff(config, sourceConfig),
+	}, nil
+}
+
+func (c *controller) Sync(ctx context.Context, clusterID uint, r *SyncRequest) error {
+	const op = "cluster config controller: sync"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if len(r.Paths) == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "paths cannot be empty")
+	}
+	if r.SourceClusterID == clusterID {
+		return perror.Wrap(herrors.ErrParamInvalid, "source cluster cannot be the cluster itself")
+	}
+	paths := make([][]string, 0, len(r.Paths))
+	sections := make(map[string]bool)
+	for _, path := range r.Paths {
+		keys, err := jsonpath.Parse(path)
+		if err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		switch keys[0] {
+		case SectionApplication, SectionPipeline, SectionTags:
+		case SectionEnv:
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"%s is generated by the environment and region of the cluster, it can not be synced", path)
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "unknown section of path %s", path)
+		}
+		sections[keys[0]] = true
+		paths = append(paths, keys)
+	}
+
+	cluster, config, err := c.getConfig(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if err := c.authorizeSource(ctx, r.SourceClusterID); err != nil {
+		return err
+	}
+	_, sourceConfig, err := c.getConfig(ctx, r.SourceClusterID)
+	if err != nil {
+		return err
+	}
+	for _, keys := range paths {
+		if value, ok := jsonpath.Get(sourceConfig, keys); ok {
+			if err := jsonpath.Set(config, keys, value); err != nil {
+				return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+			}
+		} else {
+			jsonpath.Delete(config, keys)
+		}
+	}
+
+	request := &clusterctl.UpdateClusterRequestV2{
+		Description:    cluster.Description,
+		BuildConfig:    section(config, SectionPipeline),
+		TemplateConfig: section(config, SectionApplication),
+	}
+	if sections[SectionTags] {
+		if request.Tags, err = toTags(section(config, SectionTags)); err != nil {
+			return err
+		}
+	}
+	return c.clusterCtl.UpdateClusterV2(ctx, clusterID, request, false)
+}
+
+func (c *controller) ListCommits(ctx context.Context, clusterID uint, branch string,
+	query *q.Query) ([]*ConfigCommit, error) {
+	const op = "cluster config controller: list commits"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if branch == "" {
+		branch = gitrepo.GitOpsBranch
+	}
+	if branch != gitrepo.GitOpsBranch && branch != c.clusterGitRepo.DefaultBranch() {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "branch must be %s or %s",
+			c.clusterGitRepo.DefaultBranch(), gitrepo.GitOpsBranch)
+	}
+	if query == nil {
+		query = &q.Query{}
+	}
+	if query.PageSize > _maxCommitsPageSize {
+		query.PageSize = _maxCommitsPageSize
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := c.clusterGitRepo.ListConfigCommits(ctx, application.Name, cluster.Name, branch,
+		query.Offset()/query.Limit()+1, query.Limit())
+	if err != nil {
+		return nil, err
+	}
+
+	configCommits := make([]*ConfigCommit, 0, len(commits))
+	for _, commit := range commits {
+		configCommits = append(configCommits, ofCommit(branch, commit))
+	}
+	if err := c.attribute(ctx, clusterID, configCommits); err != nil {
+		return nil, err
+	}
+	return configCommits, nil
+}
+
+func (c *controller) Restore(ctx context.Context, clusterID uint, commit string) error {
+	const op = "cluster config controller: restore"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if commit == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "commit cannot be empty")
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	files, err := c.clusterGitRepo.GetClusterByCommit(ctx, application.Name, cluster.Name,
+		cluster.Template, commit)
+	if err != nil {
+		return err
+	}
+	return c.clusterCtl.UpdateClusterV2(ctx, clusterID, &clusterctl.UpdateClusterRequestV2{
+		Description:    cluster.Description,
+		BuildConfig:    orEmpty(files.PipelineJSONBlob),
+		TemplateConfig: orEmpty(files.ApplicationJSONBlob),
+	}, false)
+}
+
+// attribute fills the pipelineruns and the horizon users of the commits.
+// Pipelineruns are linked by their config commits on the gitops branch,
+// and by the messages of merge commits on the master branch.
+func (c *controller) attribute(ctx context.Context, clusterID uint, commits []*ConfigCommit) error {
+	ids := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		ids = append(ids, commit.Commit)
+	}
+	_, pipelineruns, err := c.pipelinerunMgr.GetByClusterID(ctx, clusterID, false, q.Query{
+		Keywords: q.KeyWords{
+			common.PipelineQueryByConfigCommit: ids,
+		},
+		WithoutPagination: true,
+	})
+	if err != nil {
+		return err
+	}
+	pipelinerunByCommit := make(map[string]*prmodels.Pipelinerun, len(pipelineruns))
+	for _, pipelinerun := range pipelineruns {
+		// pipelineruns are sorted by created time desc, the earliest one makes the commit
+		pipelinerunByCommit[pipelinerun.ConfigCommit] = pipelinerun
+	}
+
+	creators := make(map[*ConfigCommit]uint)
+	for _, commit := range commits {
+		pipelinerun := pipelinerunByCommit[commit.Commit]
+		if commit.PipelinerunID != nil {
+			if pipelinerun, err = c.pipelinerunMgr.GetByID(ctx, *commit.PipelinerunID); err != nil {
+				return err
+			}
+		}
+		if pipelinerun == nil || pipelinerun.ClusterID != clusterID {
+			commit.PipelinerunID = nil
+			continue
+		}
+		commit.PipelinerunID = &pipelinerun.ID
+		if commit.Operator == "" {
+			creators[commit] = pipelinerun.CreatedBy
+		}
+	}
+
+	if len(creators) == 0 {
+		return nil
+	}
+	userIDs := make([]uint, 0, len(creators))
+	for _, userID := range creators {
+		userIDs = append(userIDs, userID)
+	}
+	users, err := c.userMgr.GetUserMapByIDs(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	for commit, userID := range creators {
+		if user, ok := users[userID]; ok {
+			commit.Operator = user.Name
+		}
+	}
+	return nil
+}
+
+// getConfig returns the config of the cluster, in which the sections are the first keys
+func (c *controller) getConfig(ctx context.Context,
+	clusterID uint) (*clustermodels.Cluster, map[string]interface{}, error) {
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, nil, err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env := map[string]interface{}{}
+	if envValue != nil {
+		env = map[string]interface{}{
+			"environment":   envValue.Environment,
+			"region":        envValue.Region,
+			"namespace":     envValue.Namespace,
+			"baseRegistry":  envValue.BaseRegistry,
+			"ingressDomain": envValue.IngressDomain,
+		}
+	}
+	tagValues := make(map[string]interface{}, len(tags))
+	for _, tag := range tags {
+		tagValues[tag.Key] = tag.Value
+	}
+	config := map[string]interface{}{
+		SectionApplication: orEmpty(files.ApplicationJSONBlob),
+		SectionPipeline:    orEmpty(files.PipelineJSONBlob),
+		SectionEnv:         env,
+		SectionTags:        tagValues,
+	}
+	return cluster, config, nil
+}
+
+// authorizeSource checks whether the current user is allowed to read the source cluster
+func (c *controller) authorizeSource(ctx context.Context, sourceClusterID uint) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	decision, reason, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            "get",
+		APIGroup:        common.GroupCore,
+		Resource:        common.ResourceCluster,
+		Name:            strconv.FormatUint(uint64(sourceClusterID), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return err
+	}
+	if decision != auth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden, "source cluster %d is not allowed to read: %s",
+			sourceClusterID, reason)
+	}
+	return nil
+}
+
+func ofCommit(branch string, commit *gitrepo.ConfigCommit) *ConfigCommit {
+	configCommit := &ConfigCommit{
+		Branch:       branch,
+		Commit:       commit.ID,
+		ParentCommit: commit.ParentID,
+		Title:        commit.Title,
+		AuthorName:   commit.AuthorName,
+		CommittedAt:  commit.CommittedAt,
+		Files:        make([]string, 0, len(commit.Changes)),
+	}
+	if msg, ok := angular.ParseMessage(commit.Message); ok {
+		configCommit.Operator = msg.Header.Subject.Operator
+		configCommit.Action = msg.Header.Subject.Action
+	}
+	if matches := _mergePipelinerunPattern.FindStringSubmatch(commit.Message); len(matches) == 2 {
+		if id, err := strconv.ParseUint(matches[1], 10, 0); err == nil {
+			pipelinerunID := uint(id)
+			configCommit.PipelinerunID = &pipelinerunID
+		}
+	}
+
+	from, to := map[string]interface{}{}, map[string]interface{}{}
+	for _, change := range commit.Changes {
+		configCommit.Files = append(configCommit.Files, change.NewPath)
+		if change.Old != nil {
+			from[valuesKey(change.OldPath)] = change.Old
+		}
+		if change.New != nil {
+			to[valuesKey(change.NewPath)] = change.New
+		}
+	}
+	configCommit.Differences = jsonpath.Diff(from, to)
+	return configCommit
+}
+
+// valuesKey returns the file name without extension as the first key of paths of the values in the file
+func valuesKey(file string) string {
+	return strings.TrimSuffix(file, path.Ext(file))
+}
+
+func section(config map[string]interface{}, name string) map[string]interface{} {
+	value, _ := config[name].(map[string]interface{})
+	return orEmpty(value)
+}
+
+func orEmpty(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+func toTags(values map[string]interface{}) (tagmodels.TagsBasic, error) {
+	tags := make(tagmodels.TagsBasic, 0, len(values))
+	for key, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "value of tag %s is not a string", key)
+		}
+		tags = append(tags, &tagmodels.TagBasic{Key: key, Value: str})
+	}
+	return tags, nil
+}
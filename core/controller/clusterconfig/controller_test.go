@@ -0,0 +1,271 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	applicationmockmanager "github.com/horizoncd/horizon/mock/pkg/application/manager"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	clustermockmanager "github.com/horizoncd/horizon/mock/pkg/cluster/manager"
+	pipelinemockmanager "github.com/horizoncd/horizon/mock/pkg/pipelinerun/manager"
+	tagmockmanager "github.com/horizoncd/horizon/mock/pkg/tag/manager"
+	usermockmanager "github.com/horizoncd/horizon/mock/pkg/user/manager"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/angular"
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+)
+
+// fakeAuthorizer allows to read the clusters in allowed only
+type fakeAuthorizer struct {
+	allowed map[string]bool
+}
+
+func (f *fakeAuthorizer) Authorize(ctx context.Context, attributes auth.Attributes) (auth.Decision, string, error) {
+	if f.allowed[attributes.GetName()] {
+		return auth.DecisionAllow, "", nil
+	}
+	return auth.DecisionDeny, "not a member", nil
+}
+
+// fakeClusterController records the last update request
+type fakeClusterController struct {
+	clusterctl.Controller
+
+	request *clusterctl.UpdateClusterRequestV2
+}
+
+func (f *fakeClusterController) UpdateClusterV2(ctx context.Context, clusterID uint,
+	r *clusterctl.UpdateClusterRequestV2, mergePatch bool) error {
+	f.request = r
+	return nil
+}
+
+func TestCompareAndSync(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterMgr := clustermockmanager.NewMockManager(mockCtl)
+	applicationMgr := applicationmockmanager.NewMockManager(mockCtl)
+	tagMgr := tagmockmanager.NewMockManager(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	clusterCtl := &fakeClusterController{}
+	c := &controller{
+		clusterMgr:     clusterMgr,
+		applicationMgr: applicationMgr,
+		tagMgr:         tagMgr,
+		clusterGitRepo: clusterGitRepo,
+		clusterCtl:     clusterCtl,
+		authorizer:     &fakeAuthorizer{allowed: map[string]bool{"2": true}},
+	}
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+
+	clusters := map[uint]*clustermodels.Cluster{
+		1: {Model: global.Model{ID: 1}, ApplicationID: 1, Name: "app-test", Template: "javaapp",
+			Description: "test"},
+		2: {Model: global.Model{ID: 2}, ApplicationID: 1, Name: "app-online", Template: "javaapp"},
+		3: {Model: global.Model{ID: 3}, ApplicationID: 1, Name: "app-other", Template: "javaapp"},
+	}
+	files := map[string]*gitrepo.ClusterFiles{
+		"app-test": {
+			ApplicationJSONBlob: map[string]interface{}{
+				"app": map[string]interface{}{"spec": map[string]interface{}{"replicas": 1, "cpu": 500}},
+			},
+			PipelineJSONBlob: map[string]interface{}{"buildxml": "a"},
+		},
+		"app-online": {
+			ApplicationJSONBlob: map[string]interface{}{
+				"app": map[string]interface{}{"spec": map[string]interface{}{"replicas": 3}},
+			},
+			PipelineJSONBlob: map[string]interface{}{"buildxml": "a"},
+		},
+	}
+	tags := map[uint][]*tagmodels.Tag{
+		1: {{Key: "owner", Value: "a"}},
+		2: {{Key: "owner", Value: "b"}, {Key: "tier", Value: "1"}},
+	}
+	clusterMgr.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id uint) (*clustermodels.Cluster, error) {
+			return clusters[id], nil
+		}).AnyTimes()
+	applicationMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(
+		&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), "app", gomock.Any(), "javaapp").DoAndReturn(
+		func(_ context.Context, _, cluster, _ string) (*gitrepo.ClusterFiles, error) {
+			return files[cluster], nil
+		}).AnyTimes()
+	clusterGitRepo.EXPECT().GetEnvValue(gomock.Any(), "app", gomock.Any(), "javaapp").DoAndReturn(
+		func(_ context.Context, _, cluster, _ string) (*gitrepo.EnvValue, error) {
+			if cluster == "app-test" {
+				return &gitrepo.EnvValue{Environment: "test", Namespace: "test-1"}, nil
+			}
+			return &gitrepo.EnvValue{Environment: "online", Namespace: "online-1"}, nil
+		}).AnyTimes()
+	tagMgr.EXPECT().ListByResourceTypeID(gomock.Any(), common.ResourceCluster, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, id uint) ([]*tagmodels.Tag, error) {
+			return tags[id], nil
+		}).AnyTimes()
+
+	resp, err := c.Compare(ctx, 1, 2)
+	assert.Nil(t, err)
+	paths := make(map[string]*jsonpath.Difference)
+	for _, diff := range resp.Differences {
+		paths[diff.Path] = diff
+	}
+	assert.Equal(t, 6, len(paths))
+	assert.Equal(t, jsonpath.DifferenceRemoved, paths["application.app.spec.cpu"].Type)
+	assert.Equal(t, 3, paths["application.app.spec.replicas"].To)
+	assert.Equal(t, "test", paths["env.environment"].From)
+	assert.Equal(t, "online-1", paths["env.namespace"].To)
+	assert.Equal(t, "b", paths["tags.owner"].To)
+	assert.Equal(t, jsonpath.DifferenceAdded, paths["tags.tier"].Type)
+
+	// the source cluster must be readable by the current user
+	_, err = c.Compare(ctx, 1, 3)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// env values can not be synced
+	err = c.Sync(ctx, 1, &SyncRequest{SourceClusterID: 2, Paths: []string{"env.namespace"}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	err = c.Sync(ctx, 1, &SyncRequest{SourceClusterID: 2, Paths: []string{
+		"application.app.spec.replicas", "application.app.spec.cpu", "tags.tier",
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, "test", clusterCtl.request.Description)
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{"spec": map[string]interface{}{"replicas": 3}},
+	}, clusterCtl.request.TemplateConfig)
+	assert.Equal(t, map[string]interface{}{"buildxml": "a"}, clusterCtl.request.BuildConfig)
+	assert.Equal(t, 2, len(clusterCtl.request.Tags))
+}
+
+func TestListCommitsAndRestore(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterMgr := clustermockmanager.NewMockManager(mockCtl)
+	applicationMgr := applicationmockmanager.NewMockManager(mockCtl)
+	pipelinerunMgr := pipelinemockmanager.NewMockPipelineRunManager(mockCtl)
+	userMgr := usermockmanager.NewMockManager(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	clusterCtl := &fakeClusterController{}
+	c := &controller{
+		clusterMgr:     clusterMgr,
+		applicationMgr: applicationMgr,
+		pipelinerunMgr: pipelinerunMgr,
+		userMgr:        userMgr,
+		clusterGitRepo: clusterGitRepo,
+		clusterCtl:     clusterCtl,
+	}
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+
+	clusterMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(&clustermodels.Cluster{
+		Model: global.Model{ID: 1}, ApplicationID: 1, Name: "app-test", Template: "javaapp", Description: "test",
+	}, nil).AnyTimes()
+	applicationMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(
+		&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().DefaultBranch().Return("master").AnyTimes()
+
+	_, err := c.ListCommits(ctx, 1, "feature", nil)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	updateMessage := angular.CommitMessage("cluster", angular.Subject{
+		Operator: "tony",
+		Action:   "update cluster",
+		Cluster:  angular.StringPtr("app-test"),
+	}, nil)
+	clusterGitRepo.EXPECT().ListConfigCommits(gomock.Any(), "app", "app-test", gitrepo.GitOpsBranch, 1, 20).Return(
+		[]*gitrepo.ConfigCommit{
+			{
+				ID: "c3", ParentID: "c2", Title: "update image", Message: "update image", AuthorName: "horizon",
+				Changes: []*gitrepo.ConfigChange{{
+					OldPath: "pipeline-output.yaml", NewPath: "pipeline-output.yaml",
+					Old: map[string]interface{}{"image": "v1"},
+					New: map[string]interface{}{"image": "v2"},
+				}},
+			},
+			{
+				ID: "c2", ParentID: "c1", Title: "change(cluster): tony update cluster app-test",
+				Message: updateMessage, AuthorName: "horizon",
+				Changes: []*gitrepo.ConfigChange{{
+					OldPath: "application.yaml", NewPath: "application.yaml",
+					Old: map[string]interface{}{"javaapp": map[string]interface{}{"replicas": 1}},
+					New: map[string]interface{}{"javaapp": map[string]interface{}{"replicas": 2}},
+				}},
+			},
+			{ID: "c1", Title: "edit sre values", Message: "edit sre values", AuthorName: "alice"},
+		}, nil)
+	pipelinerunMgr.EXPECT().GetByClusterID(gomock.Any(), uint(1), false, gomock.Any()).Return(1,
+		[]*prmodels.Pipelinerun{{ID: 5, ClusterID: 1, ConfigCommit: "c3", CreatedBy: 2}}, nil)
+	userMgr.EXPECT().GetUserMapByIDs(gomock.Any(), []uint{2}).Return(map[uint]*usermodels.User{
+		2: {Model: global.Model{ID: 2}, Name: "jerry"},
+	}, nil)
+
+	commits, err := c.ListCommits(ctx, 1, "", &q.Query{PageNumber: 1, PageSize: 50})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(commits))
+	assert.Equal(t, "jerry", commits[0].Operator)
+	assert.Equal(t, uint(5), *commits[0].PipelinerunID)
+	assert.Equal(t, []string{"pipeline-output.yaml"}, commits[0].Files)
+	assert.Equal(t, "pipeline-output.image", commits[0].Differences[0].Path)
+	assert.Equal(t, "tony", commits[1].Operator)
+	assert.Equal(t, "update cluster", commits[1].Action)
+	assert.Nil(t, commits[1].PipelinerunID)
+	assert.Equal(t, "application.javaapp.replicas", commits[1].Differences[0].Path)
+	assert.Equal(t, 2, commits[1].Differences[0].To)
+	assert.Equal(t, "", commits[2].Operator)
+	assert.Equal(t, 0, len(commits[2].Differences))
+
+	// merge commits on the master branch are linked to pipelineruns by their messages
+	clusterGitRepo.EXPECT().ListConfigCommits(gomock.Any(), "app", "app-test", "master", 1, 20).Return(
+		[]*gitrepo.ConfigCommit{{
+			ID: "m1", Title: "git merge gitops into master, pipelineRunID = 6",
+			Message: "git merge gitops into master, pipelineRunID = 6",
+		}}, nil)
+	pipelinerunMgr.EXPECT().GetByClusterID(gomock.Any(), uint(1), false, gomock.Any()).Return(0, nil, nil)
+	pipelinerunMgr.EXPECT().GetByID(gomock.Any(), uint(6)).Return(
+		&prmodels.Pipelinerun{ID: 6, ClusterID: 1, CreatedBy: 2}, nil)
+	userMgr.EXPECT().GetUserMapByIDs(gomock.Any(), []uint{2}).Return(map[uint]*usermodels.User{
+		2: {Model: global.Model{ID: 2}, Name: "jerry"},
+	}, nil)
+	commits, err = c.ListCommits(ctx, 1, "master", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "master", commits[0].Branch)
+	assert.Equal(t, uint(6), *commits[0].PipelinerunID)
+	assert.Equal(t, "jerry", commits[0].Operator)
+
+	clusterGitRepo.EXPECT().GetClusterByCommit(gomock.Any(), "app", "app-test", "javaapp", "c1").Return(
+		&gitrepo.ClusterFiles{
+			ApplicationJSONBlob: map[string]interface{}{"replicas": 1},
+		}, nil)
+	assert.Nil(t, c.Restore(ctx, 1, "c1"))
+	assert.Equal(t, "test", clusterCtl.request.Description)
+	assert.Equal(t, map[string]interface{}{"replicas": 1}, clusterCtl.request.TemplateConfig)
+	assert.Equal(t, map[string]interface{}{}, clusterCtl.request.BuildConfig)
+}
@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterconfig
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+)
+
+// sections of the config of clusters, they are the first keys of paths
+const (
+	SectionApplication = "application"
+	SectionPipeline    = "pipeline"
+	SectionEnv         = "env"
+	SectionTags        = "tags"
+)
+
+type CompareResponse struct {
+	ClusterID       uint `json:"clusterID"`
+	SourceClusterID uint `json:"sourceClusterID"`
+	// Differences are the changes to make the config of the cluster the same as the source cluster,
+	// from is the value of the cluster and to is the value of the source cluster
+	Differences []*jsonpath.Difference `json:"differences"`
+}
+
+type SyncRequest struct {
+	SourceClusterID uint `json:"sourceClusterID"`
+	// Paths are the json paths to copy from the source cluster, such as application.app.spec.replicas,
+	// paths missing in the source cluster are deleted from the cluster
+	Paths []string `json:"paths"`
+}
+
+type ConfigCommit struct {
+	Branch       string `json:"branch"`
+	Commit       string `json:"commit"`
+	ParentCommit string `json:"parentCommit,omitempty"`
+	Title        string `json:"title"`
+	// Action is the action of horizon making the commit, such as update cluster
+	Action     string `json:"action,omitempty"`
+	AuthorName string `json:"authorName"`
+	// Operator is the horizon user making the commit, it is empty for the commits made in the git repo directly
+	Operator      string     `json:"operator,omitempty"`
+	PipelinerunID *uint      `json:"pipelinerunID,omitempty"`
+	CommittedAt   *time.Time `json:"committedAt"`
+	Files         []string   `json:"files"`
+	// Differences are the changes of the values in the files, the first key of paths is the file name
+	// without extension, such as application.javaapp.app.spec.replicas
+	Differences []*jsonpath.Difference `json:"differences"`
+}
@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	coreconfig "github.com/horizoncd/horizon/core/config"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+type Controller interface {
+	// GetConfig returns the effective config with secrets redacted, only admin can get it
+	GetConfig(ctx context.Context) (*Config, error)
+}
+
+type controller struct {
+	reloader *coreconfig.Reloader
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(reloader *coreconfig.Reloader) Controller {
+	return &controller{reloader: reloader}
+}
+
+func (c *controller) GetConfig(ctx context.Context) (*Config, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "only admin can get the config")
+	}
+
+	redacted, err := c.reloader.Redacted()
+	if err != nil {
+		return nil, perror.WithMessage(err, "failed to redact config")
+	}
+	return &Config{
+		Config: redacted,
+		Status: c.reloader.Status(),
+	}, nil
+}
@@ -0,0 +1,332 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	costconfig "github.com/horizoncd/horizon/pkg/config/cost"
+	"github.com/horizoncd/horizon/pkg/cost/manager"
+	"github.com/horizoncd/horizon/pkg/cost/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	dateFormat = "2006-01-02"
+	// _maxDays is the max days of the costs selected at a time
+	_maxDays = 366
+)
+
+type Controller interface {
+	// GetGroupCosts returns the costs of the clusters of the group and its subgroups
+	GetGroupCosts(ctx context.Context, groupID uint, r *CostRequest) (*Costs, error)
+	GetApplicationCosts(ctx context.Context, applicationID uint, r *CostRequest) (*Costs, error)
+	GetClusterCosts(ctx context.Context, clusterID uint, r *CostRequest) (*Costs, error)
+	// GetTagCosts returns the costs of all clusters by the values of the tag, only admin can get them
+	GetTagCosts(ctx context.Context, tagKey string, r *CostRequest) (*TagCosts, error)
+
+	// GetBudget returns the monthly budget of a group, an application or a cluster
+	GetBudget(ctx context.Context, resourceType string, resourceID uint) (*Budget, error)
+	SetBudget(ctx context.Context, resourceType string, resourceID uint, r *SetBudgetRequest) (*Budget, error)
+}
+
+type controller struct {
+	costMgr          manager.Manager
+	groupMgr         groupmanager.Manager
+	applicationMgr   applicationmanager.Manager
+	clusterMgr       clustermanager.Manager
+	costConfigGetter func() *costconfig.Config
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param, costConfigGetter func() *costconfig.Config) Controller {
+	return &controller{
+		costMgr:          param.CostMgr,
+		groupMgr:         param.GroupMgr,
+		applicationMgr:   param.ApplicationMgr,
+		clusterMgr:       param.ClusterMgr,
+		costConfigGetter: costConfigGetter,
+	}
+}
+
+func (c *controller) GetGroupCosts(ctx context.Context, groupID uint, r *CostRequest) (*Costs, error) {
+	const op = "cost controller: get group costs"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	group, err := c.groupMgr.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	query, err := newQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	query.GroupTraversalIDs = group.TraversalIDs
+	return c.getCosts(ctx, query)
+}
+
+func (c *controller) GetApplicationCosts(ctx context.Context, applicationID uint,
+	r *CostRequest) (*Costs, error) {
+	const op = "cost controller: get application costs"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if _, err := c.applicationMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	query, err := newQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	query.ApplicationID = applicationID
+	return c.getCosts(ctx, query)
+}
+
+func (c *controller) GetClusterCosts(ctx context.Context, clusterID uint, r *CostRequest) (*Costs, error) {
+	const op = "cost controller: get cluster costs"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+	query, err := newQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	query.ClusterID = clusterID
+	return c.getCosts(ctx, query)
+}
+
+func (c *controller) GetTagCosts(ctx context.Context, tagKey string, r *CostRequest) (*TagCosts, error) {
+	const op = "cost controller: get tag costs"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "you have no privilege")
+	}
+	if tagKey == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "tag key is required")
+	}
+	query, err := newQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := c.costMgr.SumByTag(ctx, query, tagKey)
+	if err != nil {
+		return nil, err
+	}
+	result := &TagCosts{
+		Currency: c.costConfigGetter().Currency,
+		From:     query.From,
+		To:       query.To,
+		TagKey:   tagKey,
+		Costs:    make([]*TagCost, 0, len(costs)),
+	}
+	for _, cost := range costs {
+		result.Total += cost.Cost
+		result.Costs = append(result.Costs, &TagCost{TagValue: cost.TagValue, Usage: toUsage(cost)})
+	}
+	return result, nil
+}
+
+func (c *controller) getCosts(ctx context.Context, query *models.CostQuery) (*Costs, error) {
+	daily, err := c.costMgr.Sum(ctx, query, manager.SumByDate)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := c.costMgr.Sum(ctx, query, manager.SumByCluster)
+	if err != nil {
+		return nil, err
+	}
+	result := &Costs{
+		Currency: c.costConfigGetter().Currency,
+		From:     query.From,
+		To:       query.To,
+		Daily:    make([]*DailyCost, 0, len(daily)),
+		Clusters: make([]*ClusterCost, 0, len(clusters)),
+	}
+	for _, cost := range daily {
+		result.Total += cost.Cost
+		result.Daily = append(result.Daily, &DailyCost{Date: cost.Date, Usage: toUsage(cost)})
+	}
+	for _, cost := range clusters {
+		clusterCost := &ClusterCost{ClusterID: cost.ClusterID, Usage: toUsage(cost)}
+		if cluster, err := c.clusterMgr.GetByIDIncludeSoftDelete(ctx, cost.ClusterID); err == nil {
+			clusterCost.ClusterName = cluster.Name
+		}
+		result.Clusters = append(result.Clusters, clusterCost)
+	}
+	return result, nil
+}
+
+func (c *controller) GetBudget(ctx context.Context, resourceType string, resourceID uint) (*Budget, error) {
+	const op = "cost controller: get budget"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	query, err := c.budgetQuery(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := c.costMgr.GetBudget(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return c.toBudget(ctx, budget, query)
+}
+
+func (c *controller) SetBudget(ctx context.Context, resourceType string, resourceID uint,
+	r *SetBudgetRequest) (*Budget, error) {
+	const op = "cost controller: set budget"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.Amount <= 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "amount should be positive")
+	}
+	thresholds := make([]string, 0, len(r.Thresholds))
+	for _, threshold := range r.Thresholds {
+		if threshold == 0 {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "thresholds should be positive")
+		}
+		thresholds = append(thresholds, strconv.FormatUint(uint64(threshold), 10))
+	}
+	query, err := c.budgetQuery(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := c.costMgr.SaveBudget(ctx, &models.Budget{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Amount:       r.Amount,
+		Thresholds:   strings.Join(thresholds, ","),
+		CreatedBy:    currentUser.GetID(),
+		UpdatedBy:    currentUser.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.toBudget(ctx, budget, query)
+}
+
+// budgetQuery checks the resource of the budget exists and returns the query of its costs of the month
+func (c *controller) budgetQuery(ctx context.Context, resourceType string,
+	resourceID uint) (*models.CostQuery, error) {
+	now := time.Now()
+	query := &models.CostQuery{From: now.Format("2006-01") + "-01", To: now.Format(dateFormat)}
+	switch resourceType {
+	case common.ResourceGroup:
+		group, err := c.groupMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		query.GroupTraversalIDs = group.TraversalIDs
+	case common.ResourceApplication:
+		if _, err := c.applicationMgr.GetByID(ctx, resourceID); err != nil {
+			return nil, err
+		}
+		query.ApplicationID = resourceID
+	case common.ResourceCluster:
+		if _, err := c.clusterMgr.GetByID(ctx, resourceID); err != nil {
+			return nil, err
+		}
+		query.ClusterID = resourceID
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "budget of %s is not supported", resourceType)
+	}
+	return query, nil
+}
+
+func (c *controller) toBudget(ctx context.Context, budget *models.Budget,
+	query *models.CostQuery) (*Budget, error) {
+	thresholds, err := models.ParseThresholds(budget.Thresholds)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	costs, err := c.costMgr.Sum(ctx, query, manager.SumByCluster)
+	if err != nil {
+		return nil, err
+	}
+	result := &Budget{
+		ResourceType: budget.ResourceType,
+		ResourceID:   budget.ResourceID,
+		Amount:       budget.Amount,
+		Thresholds:   thresholds,
+		Currency:     c.costConfigGetter().Currency,
+		UpdatedAt:    budget.UpdatedAt,
+	}
+	for _, cost := range costs {
+		result.MonthCost += cost.Cost
+	}
+	return result, nil
+}
+
+// newQuery validates the dates of the request and fills the default ones
+func newQuery(r *CostRequest) (*models.CostQuery, error) {
+	now := time.Now()
+	query := &models.CostQuery{From: r.From, To: r.To}
+	if query.From == "" {
+		query.From = now.Format("2006-01") + "-01"
+	}
+	if query.To == "" {
+		query.To = now.Format(dateFormat)
+	}
+	from, err := time.Parse(dateFormat, query.From)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid from date: %s", query.From)
+	}
+	to, err := time.Parse(dateFormat, query.To)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid to date: %s", query.To)
+	}
+	if to.Before(from) {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "from date should not be after to date")
+	}
+	if to.Sub(from) >= _maxDays*24*time.Hour {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "dates should be within %d days", _maxDays)
+	}
+	return query, nil
+}
+
+func toUsage(cost *models.Cost) Usage {
+	return Usage{
+		CPUHours:    cost.CPUHours,
+		MemoryHours: cost.MemoryHours,
+		GPUHours:    cost.GPUHours,
+		Cost:        cost.Cost,
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmockmanager "github.com/horizoncd/horizon/mock/pkg/application/manager"
+	clustermockmanager "github.com/horizoncd/horizon/mock/pkg/cluster/manager"
+	costmockmanager "github.com/horizoncd/horizon/mock/pkg/cost/manager"
+	groupmockmanager "github.com/horizoncd/horizon/mock/pkg/group/manager"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	costconfig "github.com/horizoncd/horizon/pkg/config/cost"
+	"github.com/horizoncd/horizon/pkg/cost/manager"
+	"github.com/horizoncd/horizon/pkg/cost/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+func newTestController(t *testing.T) (*controller, *costmockmanager.MockManager,
+	*groupmockmanager.MockManager, *applicationmockmanager.MockManager, *clustermockmanager.MockManager) {
+	mockCtl := gomock.NewController(t)
+	costMgr := costmockmanager.NewMockManager(mockCtl)
+	groupMgr := groupmockmanager.NewMockManager(mockCtl)
+	applicationMgr := applicationmockmanager.NewMockManager(mockCtl)
+	clusterMgr := clustermockmanager.NewMockManager(mockCtl)
+	return &controller{
+		costMgr:        costMgr,
+		groupMgr:       groupMgr,
+		applicationMgr: applicationMgr,
+		clusterMgr:     clusterMgr,
+		costConfigGetter: func() *costconfig.Config {
+			return &costconfig.Config{Currency: "USD"}
+		},
+	}, costMgr, groupMgr, applicationMgr, clusterMgr
+}
+
+func TestGetCosts(t *testing.T) {
+	c, costMgr, groupMgr, _, clusterMgr := newTestController(t)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+
+	groupMgr.EXPECT().GetByID(gomock.Any(), uint(2)).Return(
+		&groupmodels.Group{Model: global.Model{ID: 2}, TraversalIDs: "1,2"}, nil).AnyTimes()
+	query := &models.CostQuery{From: "2026-10-01", To: "2026-10-02", GroupTraversalIDs: "1,2"}
+	costMgr.EXPECT().Sum(gomock.Any(), query, manager.SumByDate).Return([]*models.Cost{
+		{Date: "2026-10-01", CPUHours: 48, Cost: 2},
+		{Date: "2026-10-02", CPUHours: 24, Cost: 1.5},
+	}, nil)
+	costMgr.EXPECT().Sum(gomock.Any(), query, manager.SumByCluster).Return([]*models.Cost{
+		{ClusterID: 1, CPUHours: 48, Cost: 3},
+		{ClusterID: 2, CPUHours: 24, Cost: 0.5},
+	}, nil)
+	clusterMgr.EXPECT().GetByIDIncludeSoftDelete(gomock.Any(), uint(1)).Return(
+		&clustermodels.Cluster{Model: global.Model{ID: 1}, Name: "app-test"}, nil)
+	clusterMgr.EXPECT().GetByIDIncludeSoftDelete(gomock.Any(), uint(2)).Return(
+		nil, herrors.NewErrNotFound(herrors.ClusterInDB, "not found"))
+
+	costs, err := c.GetGroupCosts(ctx, 2, &CostRequest{From: "2026-10-01", To: "2026-10-02"})
+	assert.Nil(t, err)
+	assert.Equal(t, "USD", costs.Currency)
+	assert.Equal(t, 3.5, costs.Total)
+	assert.Equal(t, 2, len(costs.Daily))
+	assert.Equal(t, "app-test", costs.Clusters[0].ClusterName)
+	assert.Equal(t, "", costs.Clusters[1].ClusterName)
+
+	for _, r := range []*CostRequest{
+		{From: "2026-10-1"},
+		{From: "2026-10-02", To: "2026-10-01"},
+		{From: "2025-01-01", To: "2026-10-01"},
+	} {
+		_, err = c.GetGroupCosts(ctx, 2, r)
+		assert.True(t, perror.Cause(err) == herrors.ErrParamInvalid, r)
+	}
+
+	// costs by tag are for admin only
+	_, err = c.GetTagCosts(ctx, "team", &CostRequest{})
+	assert.True(t, perror.Cause(err) == herrors.ErrForbidden)
+	adminCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Admin: true})
+	costMgr.EXPECT().SumByTag(gomock.Any(), gomock.Any(), "team").Return([]*models.Cost{
+		{TagValue: "x", Cost: 1}, {TagValue: "y", Cost: 2},
+	}, nil)
+	tagCosts, err := c.GetTagCosts(adminCtx, "team", &CostRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, float64(3), tagCosts.Total)
+	assert.Equal(t, "y", tagCosts.Costs[1].TagValue)
+}
+
+func TestBudget(t *testing.T) {
+	c, costMgr, _, applicationMgr, clusterMgr := newTestController(t)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+
+	applicationMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(
+		&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"}, nil).AnyTimes()
+	costMgr.EXPECT().Sum(gomock.Any(), gomock.Any(), manager.SumByCluster).Return([]*models.Cost{
+		{ClusterID: 1, Cost: 30}, {ClusterID: 2, Cost: 20},
+	}, nil).AnyTimes()
+
+	_, err := c.SetBudget(ctx, common.ResourceApplication, 1, &SetBudgetRequest{Amount: 0})
+	assert.True(t, perror.Cause(err) == herrors.ErrParamInvalid)
+	_, err = c.SetBudget(ctx, common.ResourceApplication, 1,
+		&SetBudgetRequest{Amount: 100, Thresholds: []uint{0}})
+	assert.True(t, perror.Cause(err) == herrors.ErrParamInvalid)
+	_, err = c.SetBudget(ctx, common.ResourceTemplate, 1, &SetBudgetRequest{Amount: 100})
+	assert.True(t, perror.Cause(err) == herrors.ErrParamInvalid)
+
+	costMgr.EXPECT().SaveBudget(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, budget *models.Budget) (*models.Budget, error) {
+			assert.Equal(t, "100,80", budget.Thresholds)
+			assert.Equal(t, uint(1), budget.CreatedBy)
+			return budget, nil
+		})
+	budget, err := c.SetBudget(ctx, common.ResourceApplication, 1,
+		&SetBudgetRequest{Amount: 100, Thresholds: []uint{100, 80}})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{80, 100}, budget.Thresholds)
+	assert.Equal(t, float64(50), budget.MonthCost)
+
+	costMgr.EXPECT().GetBudget(gomock.Any(), common.ResourceApplication, uint(1)).Return(
+		&models.Budget{ResourceType: common.ResourceApplication, ResourceID: 1, Amount: 100}, nil)
+	budget, err = c.GetBudget(ctx, common.ResourceApplication, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(100), budget.Amount)
+	assert.Equal(t, []uint{}, budget.Thresholds)
+
+	clusterMgr.EXPECT().GetByID(gomock.Any(), uint(2)).Return(
+		&clustermodels.Cluster{Model: global.Model{ID: 2}, Name: "cluster"}, nil)
+	costMgr.EXPECT().GetBudget(gomock.Any(), common.ResourceCluster, uint(2)).Return(
+		&models.Budget{ResourceType: common.ResourceCluster, ResourceID: 2, Amount: 100}, nil)
+	budget, err = c.GetBudget(ctx, common.ResourceCluster, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), budget.ResourceID)
+}
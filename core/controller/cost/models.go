@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import "time"
+
+// CostRequest selects the costs between the dates inclusively, formatted as 2006-01-02,
+// they default to the first day of the current month and today
+type CostRequest struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+// Costs are the costs of a group, an application or a cluster by the date and by the cluster,
+// cpu hours are vCPU hours, memory hours are GiB hours and gpu hours are GPU hours
+type Costs struct {
+	Currency string         `json:"currency"`
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Total    float64        `json:"total"`
+	Daily    []*DailyCost   `json:"daily"`
+	Clusters []*ClusterCost `json:"clusters"`
+}
+
+type Usage struct {
+	CPUHours    float64 `json:"cpuHours"`
+	MemoryHours float64 `json:"memoryHours"`
+	GPUHours    float64 `json:"gpuHours"`
+	Cost        float64 `json:"cost"`
+}
+
+type DailyCost struct {
+	Date string `json:"date"`
+	Usage
+}
+
+type ClusterCost struct {
+	ClusterID uint `json:"clusterID"`
+	// ClusterName is empty if the cluster is deleted
+	ClusterName string `json:"clusterName"`
+	Usage
+}
+
+// TagCosts are the costs of all clusters by the values of the tag, clusters without the tag are excluded
+type TagCosts struct {
+	Currency string     `json:"currency"`
+	From     string     `json:"from"`
+	To       string     `json:"to"`
+	TagKey   string     `json:"tagKey"`
+	Total    float64    `json:"total"`
+	Costs    []*TagCost `json:"costs"`
+}
+
+type TagCost struct {
+	TagValue string `json:"tagValue"`
+	Usage
+}
+
+type SetBudgetRequest struct {
+	// Amount is the monthly budget in the currency
+	Amount float64 `json:"amount"`
+	// Thresholds are the percentages of the amount, an event is emitted once a month when the cost
+	// of the month exceeds each of them, such as [80, 100]
+	Thresholds []uint `json:"thresholds"`
+}
+
+type Budget struct {
+	ResourceType string  `json:"resourceType"`
+	ResourceID   uint    `json:"resourceID"`
+	Amount       float64 `json:"amount"`
+	Thresholds   []uint  `json:"thresholds"`
+	Currency     string  `json:"currency"`
+	// MonthCost is the cost of the current month
+	MonthCost float64   `json:"monthCost"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,173 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deprecatedapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/manager"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/service"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// Report returns the deprecated apis used in every region ordered by the region name, only admin can get it
+	Report(ctx context.Context) ([]*RegionReport, error)
+}
+
+type controller struct {
+	deprecatedAPIMgr   manager.Manager
+	deprecatedAPISvc   service.Service
+	regionMgr          regionmanager.Manager
+	clusterMgr         clustermanager.Manager
+	templateReleaseMgr trmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		deprecatedAPIMgr:   param.DeprecatedAPIMgr,
+		deprecatedAPISvc:   param.DeprecatedAPISvc,
+		regionMgr:          param.RegionMgr,
+		clusterMgr:         param.ClusterMgr,
+		templateReleaseMgr: param.TemplateReleaseMgr,
+	}
+}
+
+func (c *controller) Report(ctx context.Context) ([]*RegionReport, error) {
+	const op = "deprecated api controller: report"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "you have no privilege")
+	}
+
+	regions, err := c.regionMgr.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deprecatedAPIs, err := c.deprecatedAPIMgr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*RegionReport, 0, len(regions))
+	reportMap := make(map[string]*RegionReport, len(regions))
+	for _, region := range regions {
+		report := &RegionReport{
+			Region:          region.Name,
+			DisplayName:     region.DisplayName,
+			UpgradeBlockers: make(map[string]int),
+			Findings:        make([]*Finding, 0),
+		}
+		if apis, err := c.deprecatedAPISvc.RegionAPIs(ctx, region); err != nil {
+			log.Warningf(ctx, "failed to discover apis of region %s: %v", region.Name, err)
+		} else {
+			report.ServerVersion = apis.ServerVersion
+		}
+		reports = append(reports, report)
+		reportMap[region.Name] = report
+	}
+
+	resourceNames := make(map[string]string)
+	for _, deprecatedAPI := range deprecatedAPIs {
+		report, ok := reportMap[deprecatedAPI.Region]
+		if !ok {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d", deprecatedAPI.ResourceType, deprecatedAPI.ResourceID)
+		resourceName, ok := resourceNames[key]
+		if !ok {
+			resourceName, err = c.resourceName(ctx, deprecatedAPI)
+			if err != nil {
+				return nil, err
+			}
+			resourceNames[key] = resourceName
+		}
+		// the findings of the deleted resources are left until the next scan
+		if resourceName == "" {
+			continue
+		}
+
+		if deprecatedAPI.Status == deprecatedapi.StatusRemoved {
+			report.Removed++
+		} else {
+			report.Deprecated++
+			if deprecatedAPI.RemovedIn != "" {
+				report.UpgradeBlockers[deprecatedAPI.RemovedIn]++
+			}
+		}
+		report.Findings = append(report.Findings, &Finding{
+			ResourceType: deprecatedAPI.ResourceType,
+			ResourceID:   deprecatedAPI.ResourceID,
+			ResourceName: resourceName,
+			APIVersion:   deprecatedAPI.APIVersion,
+			Kind:         deprecatedAPI.Kind,
+			Name:         deprecatedAPI.Name,
+			Status:       deprecatedAPI.Status,
+			DeprecatedIn: deprecatedAPI.DeprecatedIn,
+			RemovedIn:    deprecatedAPI.RemovedIn,
+			Replacement:  deprecatedAPI.Replacement,
+		})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Region < reports[j].Region
+	})
+	return reports, nil
+}
+
+// resourceName returns the name of the cluster or the template release, or empty if it is deleted
+func (c *controller) resourceName(ctx context.Context, deprecatedAPI *models.DeprecatedAPI) (string, error) {
+	switch deprecatedAPI.ResourceType {
+	case common.ResourceCluster:
+		cluster, err := c.clusterMgr.GetByID(ctx, deprecatedAPI.ResourceID)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				return "", nil
+			}
+			return "", err
+		}
+		return cluster.Name, nil
+	case common.ResourceTemplateRelease:
+		tr, err := c.templateReleaseMgr.GetByID(ctx, deprecatedAPI.ResourceID)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				return "", nil
+			}
+			return "", err
+		}
+		return fmt.Sprintf("%s/%s", tr.TemplateName, tr.Name), nil
+	default:
+		return "", nil
+	}
+}
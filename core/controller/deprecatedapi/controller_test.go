@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deprecatedapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+)
+
+type fakeService struct{}
+
+func (s *fakeService) CheckCluster(context.Context, *appmodels.Application, *clustermodels.Cluster,
+	*trmodels.TemplateRelease, string) ([]*deprecatedapi.Finding, error) {
+	return nil, nil
+}
+
+func (s *fakeService) CheckTemplateRelease(context.Context, *trmodels.TemplateRelease) error {
+	return nil
+}
+
+func (s *fakeService) RegionAPIs(_ context.Context,
+	region *regionmodels.Region) (*deprecatedapi.RegionAPIs, error) {
+	if region.Name == "offline" {
+		return nil, errors.New("connection refused")
+	}
+	return deprecatedapi.NewRegionAPIs("v1.22.3")
+}
+
+func TestReport(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&regionmodels.Region{}, &clustermodels.Cluster{},
+		&trmodels.TemplateRelease{}, &models.DeprecatedAPI{}); err != nil {
+		panic(err)
+	}
+	manager := managerparam.InitManager(db)
+	c := NewController(&param.Param{Manager: manager, DeprecatedAPISvc: &fakeService{}})
+	// nolint
+	adminCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    1,
+		Admin: true,
+	})
+	// nolint
+	userCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "tony",
+		ID:   2,
+	})
+
+	_, err := c.Report(userCtx)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	assert.Nil(t, db.Save(&regionmodels.Region{Name: "hz", DisplayName: "HZ"}).Error)
+	assert.Nil(t, db.Save(&regionmodels.Region{Name: "offline", DisplayName: "Offline"}).Error)
+	cluster := &clustermodels.Cluster{Name: "app-online", RegionName: "hz"}
+	assert.Nil(t, db.Save(cluster).Error)
+	tr := &trmodels.TemplateRelease{TemplateName: "javaapp", Name: "v1.0.0"}
+	assert.Nil(t, db.Save(tr).Error)
+
+	assert.Nil(t, manager.DeprecatedAPIMgr.Replace(context.Background(), common.ResourceCluster, cluster.ID, "hz",
+		[]*models.DeprecatedAPI{
+			{APIVersion: "extensions/v1beta1", Kind: "Ingress", Name: "app-online",
+				Status: deprecatedapi.StatusRemoved, DeprecatedIn: "v1.14", RemovedIn: "v1.22"},
+			{APIVersion: "batch/v1beta1", Kind: "CronJob", Name: "app-online",
+				Status: deprecatedapi.StatusDeprecated, DeprecatedIn: "v1.21", RemovedIn: "v1.25"},
+		}))
+	assert.Nil(t, manager.DeprecatedAPIMgr.Replace(context.Background(), common.ResourceTemplateRelease, tr.ID,
+		"offline", []*models.DeprecatedAPI{
+			{APIVersion: "policy/v1beta1", Kind: "PodDisruptionBudget", Name: "javaapp",
+				Status: deprecatedapi.StatusDeprecated, DeprecatedIn: "v1.21", RemovedIn: "v1.25"},
+		}))
+	// the findings of deleted clusters are excluded
+	assert.Nil(t, manager.DeprecatedAPIMgr.Replace(context.Background(), common.ResourceCluster, 100, "hz",
+		[]*models.DeprecatedAPI{
+			{APIVersion: "batch/v1beta1", Kind: "CronJob", Name: "deleted",
+				Status: deprecatedapi.StatusDeprecated, DeprecatedIn: "v1.21", RemovedIn: "v1.25"},
+		}))
+
+	reports, err := c.Report(adminCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(reports))
+
+	assert.Equal(t, "hz", reports[0].Region)
+	assert.Equal(t, "v1.22.3", reports[0].ServerVersion)
+	assert.Equal(t, 1, reports[0].Removed)
+	assert.Equal(t, 1, reports[0].Deprecated)
+	assert.Equal(t, map[string]int{"v1.25": 1}, reports[0].UpgradeBlockers)
+	assert.Equal(t, 2, len(reports[0].Findings))
+	assert.Equal(t, "app-online", reports[0].Findings[0].ResourceName)
+
+	assert.Equal(t, "offline", reports[1].Region)
+	assert.Equal(t, "", reports[1].ServerVersion)
+	assert.Equal(t, 0, reports[1].Removed)
+	assert.Equal(t, 1, reports[1].Deprecated)
+	assert.Equal(t, "javaapp/v1.0.0", reports[1].Findings[0].ResourceName)
+}
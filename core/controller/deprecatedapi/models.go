@@ -0,0 +1,45 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deprecatedapi
+
+// RegionReport is the deprecated apis used by the clusters and the template releases in a region
+type RegionReport struct {
+	Region      string `json:"region"`
+	DisplayName string `json:"displayName"`
+	// ServerVersion is empty if the region failed to be discovered
+	ServerVersion string `json:"serverVersion"`
+	// Removed is the number of objects using apis removed in the server version, whose deploys are blocked
+	Removed    int `json:"removed"`
+	Deprecated int `json:"deprecated"`
+	// UpgradeBlockers are the numbers of objects using deprecated apis by the version removing them,
+	// which must be migrated before the region is upgraded to the version
+	UpgradeBlockers map[string]int `json:"upgradeBlockers"`
+	Findings        []*Finding     `json:"findings"`
+}
+
+type Finding struct {
+	// ResourceType is clusters or templatereleases
+	ResourceType string `json:"resourceType"`
+	ResourceID   uint   `json:"resourceID"`
+	// ResourceName is the name of the cluster, or the template name and the release name of the template release
+	ResourceName string `json:"resourceName"`
+	APIVersion   string `json:"apiVersion"`
+	Kind         string `json:"kind"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	DeprecatedIn string `json:"deprecatedIn"`
+	RemovedIn    string `json:"removedIn"`
+	Replacement  string `json:"replacement"`
+}
@@ -16,29 +16,163 @@ package event
 
 import (
 	"context"
+	"strconv"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
+const (
+	_defaultLimit = 50
+	_maxLimit     = 500
+)
+
 type Controller interface {
 	ListSupportEvents(ctx context.Context) map[string]string
+	// ListEvents lists events after the cursor, only the events of resources
+	// that the current user can get are returned
+	ListEvents(ctx context.Context, req *ListEventsRequest) (*ListEventsResponse, error)
 }
 
 type controller struct {
-	eventMgr eventmanager.Manager
+	eventMgr   eventmanager.Manager
+	memberMgr  membermanager.Manager
+	authorizer rbac.Authorizer
 }
 
-func NewController(param *param.Param) Controller {
+func NewController(param *param.Param, authorizer rbac.Authorizer) Controller {
 	return &controller{
-		eventMgr: param.EventMgr,
+		eventMgr:   param.EventMgr,
+		memberMgr:  param.MemberMgr,
+		authorizer: authorizer,
 	}
 }
 
 func (c *controller) ListSupportEvents(ctx context.Context) map[string]string {
 	const op = "event controller: list supported events"
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	return c.eventMgr.ListSupportEvents()
 }
+
+func (c *controller) ListEvents(ctx context.Context, req *ListEventsRequest) (*ListEventsResponse, error) {
+	const op = "event controller: list events"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ResourceID != 0 && req.ResourceType == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "resourceType is required when resourceID is specified")
+	}
+	supportedEvents := c.eventMgr.ListSupportEvents()
+	for _, eventType := range req.EventTypes {
+		if _, ok := supportedEvents[eventType]; !ok {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported event type: %s", eventType)
+		}
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = _defaultLimit
+	}
+	if limit > _maxLimit {
+		limit = _maxLimit
+	}
+
+	var cursor uint
+	if req.Cursor != nil {
+		cursor = *req.Cursor
+	} else if cursor, err = c.eventMgr.GetLatestEventID(ctx); err != nil {
+		return nil, err
+	}
+
+	keywords := q.KeyWords{
+		common.StartID: cursor,
+		common.Limit:   limit,
+	}
+	if req.ResourceType != "" {
+		keywords[common.ParamResourceType] = req.ResourceType
+	}
+	if req.ResourceID != 0 {
+		keywords[common.ParamResourceID] = req.ResourceID
+	}
+	if len(req.EventTypes) > 0 {
+		keywords[common.EventType] = req.EventTypes
+	}
+	events, err := c.eventMgr.ListEvents(ctx, &q.Query{Keywords: keywords})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListEventsResponse{
+		Events: make([]*Event, 0, len(events)),
+		Cursor: cursor,
+	}
+	// decisions are cached by resource, since events of a resource often come together
+	decisions := make(map[string]bool)
+	for _, event := range events {
+		resp.Cursor = event.ID
+		resourceID := strconv.FormatUint(uint64(event.ResourceID), 10)
+		key := event.ResourceType + "/" + resourceID
+		allowed, ok := decisions[key]
+		if !ok {
+			allowed = c.authorize(ctx, currentUser, event)
+			decisions[key] = allowed
+		}
+		if allowed {
+			resp.Events = append(resp.Events, ofEvent(event))
+		}
+	}
+	return resp, nil
+}
+
+// authorize returns whether the user can get the resource of the event, members and users are not checked
+// by the authorizer, so the events of members are authorized by the resources that they belong to,
+// and the events of users are only delivered to the users themselves
+func (c *controller) authorize(ctx context.Context, currentUser userauth.User, event *models.Event) bool {
+	resourceType, resourceID := event.ResourceType, event.ResourceID
+	switch resourceType {
+	case common.ResourceUser:
+		return currentUser.IsAdmin() || currentUser.GetID() == resourceID
+	case common.ResourceMember:
+		member, err := c.memberMgr.GetByIDIncludeSoftDelete(ctx, resourceID)
+		if err != nil {
+			log.Warningf(ctx, "failed to get member %d of event %d, err: %v", resourceID, event.ID, err)
+			return false
+		}
+		resourceType, resourceID = string(member.ResourceType), member.ResourceID
+	}
+
+	name := strconv.FormatUint(uint64(resourceID), 10)
+	decision, reason, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            "get",
+		APIGroup:        common.GroupCore,
+		Resource:        resourceType,
+		Name:            name,
+		ResourceRequest: true,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to authorize event %d of %s/%s, err: %v", event.ID, resourceType, name, err)
+		return false
+	}
+	if decision != auth.DecisionAllow {
+		log.Debugf(ctx, "event %d of %s/%s is filtered out, reason: %s", event.ID, resourceType, name, reason)
+		return false
+	}
+	return true
+}
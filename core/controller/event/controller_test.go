@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+// fakeAuthorizer allows getting application 1 only
+type fakeAuthorizer struct{}
+
+func (a *fakeAuthorizer) Authorize(_ context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	if attr.GetResource() == common.ResourceApplication && attr.GetName() == "1" {
+		return auth.DecisionAllow, "", nil
+	}
+	return auth.DecisionDeny, "", nil
+}
+
+func TestListEvents(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.Event{}, &membermodels.Member{}); err != nil {
+		panic(err)
+	}
+	manager := managerparam.InitManager(db)
+	c := NewController(&param.Param{Manager: manager}, &fakeAuthorizer{})
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "tony", ID: 2})
+
+	visibleMember := &membermodels.Member{ResourceType: membermodels.TypeApplication, ResourceID: 1}
+	assert.Nil(t, db.Save(visibleMember).Error)
+	invisibleMember := &membermodels.Member{ResourceType: membermodels.TypeApplication, ResourceID: 2}
+	assert.Nil(t, db.Save(invisibleMember).Error)
+
+	newEvent := func(resourceType string, resourceID uint, eventType string) *models.Event {
+		return &models.Event{EventSummary: models.EventSummary{
+			ResourceType: resourceType, ResourceID: resourceID, EventType: eventType}}
+	}
+	old, err := manager.EventMgr.CreateEvent(ctx, newEvent(common.ResourceApplication, 1,
+		models.ApplicationCreated))
+	assert.Nil(t, err)
+
+	// only new events are listed without cursor
+	resp, err := c.ListEvents(ctx, &ListEventsRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(resp.Events))
+	assert.Equal(t, old[0].ID, resp.Cursor)
+
+	_, err = manager.EventMgr.CreateEvent(ctx,
+		newEvent(common.ResourceApplication, 1, models.ApplicationUpdated),
+		newEvent(common.ResourceApplication, 2, models.ApplicationUpdated),
+		newEvent(common.ResourceMember, visibleMember.ID, models.MemberCreated),
+		newEvent(common.ResourceMember, invisibleMember.ID, models.MemberCreated),
+		newEvent(common.ResourceUser, 2, "users_updated"),
+		newEvent(common.ResourceUser, 3, "users_updated"),
+	)
+	assert.Nil(t, err)
+
+	resp, err = c.ListEvents(ctx, &ListEventsRequest{Cursor: &resp.Cursor})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(resp.Events))
+	assert.Equal(t, common.ResourceApplication, resp.Events[0].ResourceType)
+	assert.Equal(t, uint(1), resp.Events[0].ResourceID)
+	assert.Equal(t, visibleMember.ID, resp.Events[1].ResourceID)
+	assert.Equal(t, common.ResourceUser, resp.Events[2].ResourceType)
+	assert.Equal(t, uint(2), resp.Events[2].ResourceID)
+
+	// the whole history is listed from cursor 0
+	cursor := uint(0)
+	resp, err = c.ListEvents(ctx, &ListEventsRequest{Cursor: &cursor})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(resp.Events))
+}
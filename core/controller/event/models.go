@@ -0,0 +1,63 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/event/models"
+)
+
+// ListEventsRequest lists events after the cursor
+type ListEventsRequest struct {
+	// Cursor is the id of the last event received, events after it are listed,
+	// nil means the latest event so that only new events are listed
+	Cursor       *uint
+	ResourceType string
+	ResourceID   uint
+	EventTypes   []string
+	Limit        int
+}
+
+type Event struct {
+	ID           uint      `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	EventType    string    `json:"eventType"`
+	Extra        *string   `json:"extra,omitempty"`
+	ReqID        string    `json:"reqID"`
+	CreatedAt    time.Time `json:"createdAt"`
+	CreatedBy    uint      `json:"createdBy"`
+}
+
+type ListEventsResponse struct {
+	Events []*Event `json:"events"`
+	// Cursor is the position to continue listing from,
+	// it moves past the events filtered out as well
+	Cursor uint `json:"cursor"`
+}
+
+func ofEvent(event *models.Event) *Event {
+	return &Event{
+		ID:           event.ID,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		EventType:    event.EventType,
+		Extra:        event.Extra,
+		ReqID:        event.ReqID,
+		CreatedAt:    event.CreatedAt,
+		CreatedBy:    event.CreatedBy,
+	}
+}
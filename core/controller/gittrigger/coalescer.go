@@ -0,0 +1,64 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittrigger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/gittrigger/hook"
+)
+
+type pendingBuild struct {
+	triggerID uint
+	operator  uint
+	event     *hook.Event
+}
+
+// coalescer delays the builds of a cluster for an interval, the builds added
+// in the interval are coalesced and only the latest one is run
+type coalescer struct {
+	sync.Mutex
+	interval func() time.Duration
+	run      func(clusterID uint, build *pendingBuild)
+	pending  map[uint]*pendingBuild
+}
+
+func newCoalescer(interval func() time.Duration,
+	run func(clusterID uint, build *pendingBuild)) *coalescer {
+	return &coalescer{
+		interval: interval,
+		run:      run,
+		pending:  make(map[uint]*pendingBuild),
+	}
+}
+
+func (c *coalescer) add(clusterID uint, build *pendingBuild) {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.pending[clusterID]; ok {
+		c.pending[clusterID] = build
+		return
+	}
+	c.pending[clusterID] = build
+	time.AfterFunc(c.interval(), func() {
+		c.Lock()
+		latest := c.pending[clusterID]
+		delete(c.pending, clusterID)
+		c.Unlock()
+		c.run(clusterID, latest)
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittrigger
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/gittrigger/hook"
+)
+
+func TestCoalescer(t *testing.T) {
+	var (
+		lock sync.Mutex
+		runs = make(map[uint][]string)
+		wg   sync.WaitGroup
+	)
+	wg.Add(2)
+	c := newCoalescer(func() time.Duration {
+		return 50 * time.Millisecond
+	}, func(clusterID uint, build *pendingBuild) {
+		lock.Lock()
+		defer lock.Unlock()
+		runs[clusterID] = append(runs[clusterID], build.event.Commit)
+		wg.Done()
+	})
+
+	c.add(1, &pendingBuild{event: &hook.Event{Commit: "a"}})
+	c.add(1, &pendingBuild{event: &hook.Event{Commit: "b"}})
+	c.add(2, &pendingBuild{event: &hook.Event{Commit: "c"}})
+	c.add(1, &pendingBuild{event: &hook.Event{Commit: "d"}})
+	wg.Wait()
+
+	assert.Equal(t, []string{"d"}, runs[1])
+	assert.Equal(t, []string{"c"}, runs[2])
+}
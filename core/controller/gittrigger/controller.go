@@ -0,0 +1,354 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittrigger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	previewctl "github.com/horizoncd/horizon/core/controller/preview"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	"github.com/horizoncd/horizon/pkg/config/gittrigger"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/gittrigger/hook"
+	"github.com/horizoncd/horizon/pkg/gittrigger/manager"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	CreateGitTrigger(ctx context.Context, clusterID uint, r *CreateGitTriggerRequest) (*GitTrigger, error)
+	UpdateGitTrigger(ctx context.Context, clusterID, id uint, r *UpdateGitTriggerRequest) (*GitTrigger, error)
+	ListGitTriggers(ctx context.Context, clusterID uint) ([]*GitTrigger, error)
+	DeleteGitTrigger(ctx context.Context, clusterID, id uint) error
+	// Receive verifies the webhook of a git provider, and schedules builddeploys
+	// for the clusters whose triggers match the event, as well as preview clusters
+	// for merge requests
+	Receive(ctx context.Context, provider string, header http.Header, body []byte) (*ReceiveResponse, error)
+}
+
+type controller struct {
+	configGetter  func() *gittrigger.Config
+	gitTriggerMgr manager.Manager
+	clusterMgr    clustermanager.Manager
+	userMgr       usermanager.Manager
+	clusterCtl    clusterctl.Controller
+	previewCtl    previewctl.Controller
+	authorizer    rbac.Authorizer
+	coalescer     *coalescer
+}
+
+var _ Controller = (*controller)(nil)
+
+// NewController creates a git trigger controller, configGetter returns the effective config
+func NewController(configGetter func() *gittrigger.Config, param *param.Param,
+	clusterCtl clusterctl.Controller, previewCtl previewctl.Controller, authorizer rbac.Authorizer) Controller {
+	c := &controller{
+		configGetter:  configGetter,
+		gitTriggerMgr: param.GitTriggerMgr,
+		clusterMgr:    param.ClusterMgr,
+		userMgr:       param.UserMgr,
+		clusterCtl:    clusterCtl,
+		previewCtl:    previewCtl,
+		authorizer:    authorizer,
+	}
+	c.coalescer = newCoalescer(func() time.Duration {
+		return configGetter().DebounceInterval
+	}, c.buildDeploy)
+	return c
+}
+
+func (c *controller) CreateGitTrigger(ctx context.Context, clusterID uint,
+	r *CreateGitTriggerRequest) (*GitTrigger, error) {
+	const op = "git trigger controller: create git trigger"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(r.Event, r.Pattern); err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.GitURL == "" {
+		return nil, herrors.ErrBuildDeployNotSupported
+	}
+
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	trigger, err := c.gitTriggerMgr.Create(ctx, &models.GitTrigger{
+		ClusterID: clusterID,
+		Event:     r.Event,
+		Pattern:   r.Pattern,
+		Enabled:   enabled,
+		CreatedBy: currentUser.GetID(),
+		UpdatedBy: currentUser.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofGitTrigger(trigger), nil
+}
+
+func (c *controller) UpdateGitTrigger(ctx context.Context, clusterID, id uint,
+	r *UpdateGitTriggerRequest) (*GitTrigger, error) {
+	const op = "git trigger controller: update git trigger"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trigger, err := c.getGitTrigger(ctx, clusterID, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Event != nil {
+		trigger.Event = *r.Event
+	}
+	if r.Pattern != nil {
+		trigger.Pattern = *r.Pattern
+	}
+	if r.Enabled != nil {
+		trigger.Enabled = *r.Enabled
+	}
+	if err := validate(trigger.Event, trigger.Pattern); err != nil {
+		return nil, err
+	}
+	// builddeploys are triggered as the last one who updates the trigger
+	trigger.UpdatedBy = currentUser.GetID()
+	trigger, err = c.gitTriggerMgr.Update(ctx, trigger)
+	if err != nil {
+		return nil, err
+	}
+	return ofGitTrigger(trigger), nil
+}
+
+func (c *controller) ListGitTriggers(ctx context.Context, clusterID uint) ([]*GitTrigger, error) {
+	const op = "git trigger controller: list git triggers"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	triggers, err := c.gitTriggerMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*GitTrigger, 0, len(triggers))
+	for _, trigger := range triggers {
+		result = append(result, ofGitTrigger(trigger))
+	}
+	return result, nil
+}
+
+func (c *controller) DeleteGitTrigger(ctx context.Context, clusterID, id uint) error {
+	const op = "git trigger controller: delete git trigger"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if _, err := c.getGitTrigger(ctx, clusterID, id); err != nil {
+		return err
+	}
+	return c.gitTriggerMgr.Delete(ctx, id)
+}
+
+func (c *controller) getGitTrigger(ctx context.Context, clusterID, id uint) (*models.GitTrigger, error) {
+	trigger, err := c.gitTriggerMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if trigger.ClusterID != clusterID {
+		return nil, herrors.NewErrNotFound(herrors.GitTriggerInDB,
+			fmt.Sprintf("git trigger %d does not belong to cluster %d", id, clusterID))
+	}
+	return trigger, nil
+}
+
+func (c *controller) Receive(ctx context.Context, provider string,
+	header http.Header, body []byte) (*ReceiveResponse, error) {
+	const op = "git trigger controller: receive"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	config := c.configGetter()
+	var secret string
+	switch provider {
+	case hook.ProviderGitlab:
+		secret = config.GitlabSecretToken
+	case hook.ProviderGithub:
+		secret = config.GithubSecret
+	}
+	event, err := hook.Parse(provider, secret, header, body)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ReceiveResponse{ClusterIDs: []uint{}, PreviewRuleIDs: []uint{}}
+	if event == nil {
+		return resp, nil
+	}
+
+	if resp.PreviewRuleIDs, err = c.previewCtl.HandleMergeRequest(ctx, event); err != nil {
+		return nil, err
+	}
+	// merged or closed merge requests only tear down preview clusters
+	if event.Closed {
+		return resp, nil
+	}
+
+	triggers, err := c.gitTriggerMgr.ListEnabledByGitURLs(ctx, hook.CandidateURLs(event.RepoURLs))
+	if err != nil {
+		return nil, err
+	}
+	scheduled := make(map[uint]bool)
+	for _, trigger := range triggers {
+		if scheduled[trigger.ClusterID] || !match(&trigger.GitTrigger, event) {
+			continue
+		}
+		scheduled[trigger.ClusterID] = true
+		resp.ClusterIDs = append(resp.ClusterIDs, trigger.ClusterID)
+		log.Infof(ctx, "git trigger %d of cluster %d matches %s event of %s",
+			trigger.ID, trigger.ClusterID, event.Type, event.Ref)
+		c.coalescer.add(trigger.ClusterID, &pendingBuild{
+			triggerID: trigger.ID,
+			operator:  trigger.UpdatedBy,
+			event:     event,
+		})
+	}
+	return resp, nil
+}
+
+// buildDeploy creates the builddeploy pipelinerun of the cluster as the operator of the trigger,
+// it's skipped if the operator is no longer allowed to builddeploy the cluster
+func (c *controller) buildDeploy(clusterID uint, build *pendingBuild) {
+	const op = "git trigger controller: build deploy"
+	// nolint
+	ctx := context.WithValue(context.Background(), requestid.HeaderXRequestID, uuid.NewV4().String())
+
+	user, err := c.userMgr.GetUserByID(ctx, build.operator)
+	if err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to get operator %d of git trigger %d, err: %v",
+			build.operator, build.triggerID, err)
+		return
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+	if err := c.authorize(ctx, clusterID); err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("git trigger %d of cluster %d is skipped, err: %v",
+			build.triggerID, clusterID, err)
+		return
+	}
+
+	event := build.event
+	request := &clusterctl.BuildDeployRequest{
+		Title: event.Title,
+		Description: fmt.Sprintf("triggered by %s event of %s at %s, operator: %s",
+			event.Type, event.Ref, event.Commit, event.Operator),
+		Git: &clusterctl.BuildDeployRequestGit{},
+	}
+	if event.Type == models.EventTag {
+		request.Git.Tag = event.Ref
+	} else {
+		request.Git.Branch = event.Ref
+	}
+	resp, err := c.clusterCtl.BuildDeploy(ctx, clusterID, request)
+	if err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to builddeploy cluster %d by git trigger %d, err: %v",
+			clusterID, build.triggerID, err)
+		return
+	}
+	log.WithFiled(ctx, "op", op).Infof("cluster %d is built and deployed by git trigger %d, pipelinerun: %d",
+		clusterID, build.triggerID, resp.PipelinerunID)
+}
+
+// authorize checks whether the current user is allowed to builddeploy the cluster
+// as if the builddeploy is requested by the api of the cluster
+func (c *controller) authorize(ctx context.Context, clusterID uint) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	decision, reason, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            "create",
+		APIGroup:        common.GroupCore,
+		Resource:        common.ResourceCluster,
+		SubResource:     "builddeploy",
+		Name:            strconv.FormatUint(uint64(clusterID), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return err
+	}
+	if decision != auth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden, "builddeploy of cluster %d is not allowed: %s",
+			clusterID, reason)
+	}
+	return nil
+}
+
+func validate(event, pattern string) error {
+	switch event {
+	case models.EventPush, models.EventTag, models.EventMergeRequest:
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "unsupported event: %s", event)
+	}
+	if pattern == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "pattern cannot be empty")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid pattern %s: %v", pattern, err)
+	}
+	return nil
+}
+
+func match(trigger *models.GitTrigger, event *hook.Event) bool {
+	if trigger.Event != event.Type {
+		return false
+	}
+	ref := event.Ref
+	if event.Type == models.EventMergeRequest {
+		ref = event.TargetBranch
+	}
+	matched, _ := path.Match(trigger.Pattern, ref)
+	return matched
+}
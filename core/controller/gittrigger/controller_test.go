@@ -0,0 +1,78 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittrigger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/config/gittrigger"
+	"github.com/horizoncd/horizon/pkg/gittrigger/hook"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// fakeAuthorizer allows the builddeploy of cluster 1 only
+type fakeAuthorizer struct{}
+
+func (a *fakeAuthorizer) Authorize(_ context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	if attr.GetResource() == common.ResourceCluster && attr.GetSubResource() == "builddeploy" &&
+		attr.GetName() == "1" {
+		return auth.DecisionAllow, "", nil
+	}
+	return auth.DecisionDeny, "not a member", nil
+}
+
+// fakeClusterCtl records the clusters built and deployed
+type fakeClusterCtl struct {
+	clusterctl.Controller
+
+	deploys []uint
+}
+
+func (f *fakeClusterCtl) BuildDeploy(_ context.Context, clusterID uint,
+	_ *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error) {
+	f.deploys = append(f.deploys, clusterID)
+	return &clusterctl.BuildDeployResponse{}, nil
+}
+
+func TestBuildDeploy(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&usermodels.User{}); err != nil {
+		panic(err)
+	}
+	user := &usermodels.User{Name: "tony"}
+	assert.Nil(t, db.Create(user).Error)
+
+	clusterCtl := &fakeClusterCtl{}
+	c := NewController(func() *gittrigger.Config {
+		return &gittrigger.Config{DebounceInterval: time.Second}
+	}, &param.Param{Manager: managerparam.InitManager(db)}, clusterCtl, nil, &fakeAuthorizer{}).(*controller)
+
+	event := &hook.Event{Type: models.EventPush, Ref: "main", Commit: "a"}
+	c.buildDeploy(1, &pendingBuild{triggerID: 1, operator: user.ID, event: event})
+	// the operator is no longer allowed to builddeploy cluster 2
+	c.buildDeploy(2, &pendingBuild{triggerID: 2, operator: user.ID, event: event})
+	assert.Equal(t, []uint{1}, clusterCtl.deploys)
+}
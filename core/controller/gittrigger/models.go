@@ -0,0 +1,67 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittrigger
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+)
+
+type GitTrigger struct {
+	ID        uint      `json:"id"`
+	ClusterID uint      `json:"clusterID"`
+	Event     string    `json:"event"`
+	Pattern   string    `json:"pattern"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	CreatedBy uint      `json:"createdBy"`
+}
+
+func ofGitTrigger(trigger *models.GitTrigger) *GitTrigger {
+	return &GitTrigger{
+		ID:        trigger.ID,
+		ClusterID: trigger.ClusterID,
+		Event:     trigger.Event,
+		Pattern:   trigger.Pattern,
+		Enabled:   trigger.Enabled,
+		CreatedAt: trigger.CreatedAt,
+		UpdatedAt: trigger.UpdatedAt,
+		CreatedBy: trigger.CreatedBy,
+	}
+}
+
+type CreateGitTriggerRequest struct {
+	// Event is one of push, tag and merge_request
+	Event string `json:"event"`
+	// Pattern is a glob pattern of the branch for push, the tag for tag,
+	// and the target branch for merge_request, such as main or v*
+	Pattern string `json:"pattern"`
+	Enabled *bool  `json:"enabled"`
+}
+
+type UpdateGitTriggerRequest struct {
+	Event   *string `json:"event"`
+	Pattern *string `json:"pattern"`
+	Enabled *bool   `json:"enabled"`
+}
+
+type ReceiveResponse struct {
+	// ClusterIDs are the clusters that builddeploys are scheduled for
+	ClusterIDs []uint `json:"clusterIDs"`
+	// PreviewRuleIDs are the preview rules matching the merge request
+	PreviewRuleIDs []uint `json:"previewRuleIDs"`
+}
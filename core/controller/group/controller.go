@@ -347,7 +347,8 @@ func (c *controller) CreateGroup(ctx context.Context, newGroup *NewGroup) (uint,
 func (c *controller) GetByFullPath(ctx context.Context,
 	resourcePath string, resourceType string) (*service.Child, error) {
 	const op = "get record by fullPath"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	errMsg := fmt.Sprintf("no resource matching the resourcePath: %s, resourceType: %s",
 		resourcePath, resourceType)
@@ -90,7 +90,8 @@ type controller struct {
 
 func (c *controller) GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*AuthorizeCodeResponse, error) {
 	const op = "oauth controller: GenAuthorizeCode"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. TODO: check if the scope is ok (now horizon app do not need provide scope)
 	// 2. gen authorization Code
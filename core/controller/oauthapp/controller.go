@@ -93,7 +93,8 @@ func (c *controller) ofClientSecret(ctx context.Context, secret *models.OauthCli
 }
 func (c *controller) CreateSecret(ctx context.Context, clientID string) (*SecretBasic, error) {
 	const op = "oauth app controller  CreateSecret"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	secret, err := c.oauthManager.CreateSecret(ctx, clientID)
 	if err != nil {
 		return nil, err
@@ -107,13 +108,15 @@ func (c *controller) CreateSecret(ctx context.Context, clientID string) (*Secret
 
 func (c *controller) DeleteSecret(ctx context.Context, ClientID string, clientSecretID uint) error {
 	const op = "oauth app controller  DeleteSecret"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return c.oauthManager.DeleteSecret(ctx, ClientID, clientSecretID)
 }
 
 func (c *controller) ListSecret(ctx context.Context, ClientID string) ([]SecretBasic, error) {
 	const op = "oauth app controller  ListSecret"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	secrets, err := c.oauthManager.ListSecret(ctx, ClientID)
 	if err != nil {
 		return nil, err
@@ -134,7 +137,8 @@ func (c *controller) ListSecret(ctx context.Context, ClientID string) ([]SecretB
 
 func (c *controller) Create(ctx context.Context, groupID uint, request CreateOauthAPPRequest) (*APPBasicInfo, error) {
 	const op = "oauth app controller  Create"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// TODO: check if have the permission to create
 	oauthApp, err := c.oauthManager.CreateOauthApp(ctx, &manager.CreateOAuthAppReq{
@@ -164,7 +168,8 @@ func (c *controller) Create(ctx context.Context, groupID uint, request CreateOau
 
 func (c *controller) Get(ctx context.Context, clientID string) (*APPBasicInfo, error) {
 	const op = "oauth app controller  Get"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	oauthApp, err := c.oauthManager.GetOAuthApp(ctx, clientID)
 	if err != nil {
@@ -185,7 +190,8 @@ func (c *controller) Get(ctx context.Context, clientID string) (*APPBasicInfo, e
 
 func (c *controller) List(ctx context.Context, groupID uint) ([]APPBasicInfo, error) {
 	const op = "oauth  app controller  List"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	apps, err := c.oauthManager.ListOauthApp(ctx, models.GroupOwnerType, groupID)
 	if err != nil {
@@ -209,7 +215,8 @@ func (c *controller) List(ctx context.Context, groupID uint) ([]APPBasicInfo, er
 
 func (c *controller) Update(ctx context.Context, info APPBasicInfo) (*APPBasicInfo, error) {
 	const op = "oauth  app controller  Update"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	app, err := c.oauthManager.UpdateOauthApp(ctx, info.ClientID, manager.UpdateOauthAppReq{
 		Name:        info.AppName,
@@ -33,6 +33,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
@@ -47,6 +48,7 @@ import (
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	tokensvc "github.com/horizoncd/horizon/pkg/token/service"
@@ -105,6 +107,7 @@ type controller struct {
 	eventSvc           eventservice.Service
 	cd                 cd.CD
 	clusterSvc         clusterservice.Service
+	quotaSvc           quotaservice.Service
 }
 
 var _ Controller = (*controller)(nil)
@@ -128,12 +131,14 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		eventSvc:           param.EventSvc,
 		cd:                 param.CD,
 		clusterSvc:         param.ClusterSvc,
+		quotaSvc:           param.QuotaSvc,
 	}
 }
 
 func (c *controller) GetPipelinerunLog(ctx context.Context, pipelinerunID uint) (_ *collector.Log, err error) {
 	const op = "pipelinerun controller: get pipelinerun log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
 	if err != nil {
@@ -150,12 +155,13 @@ func (c *controller) GetPipelinerunLog(ctx context.Context, pipelinerunID uint)
 		return nil, errors.E(op, fmt.Errorf("%v action has no log", pr.Action))
 	}
 
-	return c.getPipelinerunLog(ctx, pr, cluster.EnvironmentName)
+	return c.getPipelinerunLog(ctx, pr, cluster.EnvironmentName, cluster.RegionName)
 }
 
 func (c *controller) GetClusterLatestLog(ctx context.Context, clusterID uint) (_ *collector.Log, err error) {
 	const op = "pipelinerun controller: get cluster latest log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pr, err := c.prMgr.PipelineRun.GetLatestByClusterIDAndActions(ctx, clusterID,
 		prmodels.ActionBuildDeploy, prmodels.ActionDeploy)
@@ -170,15 +176,16 @@ func (c *controller) GetClusterLatestLog(ctx context.Context, clusterID uint) (_
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
-	return c.getPipelinerunLog(ctx, pr, cluster.EnvironmentName)
+	return c.getPipelinerunLog(ctx, pr, cluster.EnvironmentName, cluster.RegionName)
 }
 
 func (c *controller) getPipelinerunLog(ctx context.Context, pr *prmodels.Pipelinerun,
-	environment string) (_ *collector.Log, err error) {
+	environment, region string) (_ *collector.Log, err error) {
 	const op = "pipeline controller: get pipelinerun log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
-	tektonCollector, err := c.tektonFty.GetTektonCollector(environment)
+	tektonCollector, err := c.tektonFty.GetTektonCollector(environment, region)
 	if err != nil {
 		return nil, perror.WithMessagef(err, "failed to get tekton collector for %s", environment)
 	}
@@ -188,7 +195,8 @@ func (c *controller) getPipelinerunLog(ctx context.Context, pr *prmodels.Pipelin
 
 func (c *controller) GetDiff(ctx context.Context, pipelinerunID uint) (_ *GetDiffResponse, err error) {
 	const op = "pipelinerun controller: get pipelinerun diff"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get pipeline
 	pipelinerun, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
@@ -255,7 +263,8 @@ func (c *controller) GetDiff(ctx context.Context, pipelinerunID uint) (_ *GetDif
 
 func (c *controller) GetPipelinerun(ctx context.Context, pipelineID uint) (_ *prmodels.PipelineBasic, err error) {
 	const op = "pipelinerun controller: get pipelinerun basic"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pipelinerun, err := c.prMgr.PipelineRun.GetByID(ctx, pipelineID)
 	if err != nil {
@@ -272,7 +281,8 @@ func (c *controller) GetPipelinerun(ctx context.Context, pipelineID uint) (_ *pr
 func (c *controller) ListPipelineruns(ctx context.Context,
 	clusterID uint, canRollback bool, query q.Query) (_ int, _ []*prmodels.PipelineBasic, err error) {
 	const op = "pipelinerun controller: list pipelinerun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	totalCount, pipelineruns, err := c.prMgr.PipelineRun.GetByClusterID(ctx, clusterID, canRollback, query)
 	if err != nil {
@@ -294,7 +304,8 @@ func (c *controller) ListPipelineruns(ctx context.Context,
 
 func (c *controller) StopPipelinerun(ctx context.Context, pipelinerunID uint) (err error) {
 	const op = "pipelinerun controller: stop pipelinerun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pipelinerun, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
 	if err != nil {
@@ -309,7 +320,7 @@ func (c *controller) StopPipelinerun(ctx context.Context, pipelinerunID uint) (e
 		return errors.E(op, err)
 	}
 
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.RegionName)
 	if err != nil {
 		return errors.E(op, err)
 	}
@@ -324,7 +335,8 @@ func (c *controller) StopPipelinerun(ctx context.Context, pipelinerunID uint) (e
 
 func (c *controller) StopPipelinerunForCluster(ctx context.Context, clusterID uint) (err error) {
 	const op = "pipelinerun controller: stop pipelinerun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	if err != nil {
 		return errors.E(op, err)
@@ -342,7 +354,7 @@ func (c *controller) StopPipelinerunForCluster(ctx context.Context, clusterID ui
 		return errors.E(op, err)
 	}
 
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.RegionName)
 	if err != nil {
 		return errors.E(op, err)
 	}
@@ -357,7 +369,8 @@ func (c *controller) StopPipelinerunForCluster(ctx context.Context, clusterID ui
 
 func (c *controller) CreateCheck(ctx context.Context, check *prmodels.Check) (*prmodels.Check, error) {
 	const op = "pipelinerun controller: create check"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	return c.prMgr.Check.Create(ctx, check)
 }
@@ -365,7 +378,8 @@ func (c *controller) CreateCheck(ctx context.Context, check *prmodels.Check) (*p
 func (c *controller) UpdateCheckRunByID(ctx context.Context, checkRunID uint,
 	request *CreateOrUpdateCheckRunRequest) error {
 	const op = "pipelinerun controller: update check run"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	err := c.prMgr.Check.UpdateByID(ctx, checkRunID, &prmodels.CheckRun{
 		Name:      request.Name,
@@ -381,7 +395,8 @@ func (c *controller) UpdateCheckRunByID(ctx context.Context, checkRunID uint,
 
 func (c *controller) Execute(ctx context.Context, pipelinerunID uint) error {
 	const op = "pipelinerun controller: execute pipelinerun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
 	if err != nil {
@@ -404,7 +419,8 @@ func (c *controller) Execute(ctx context.Context, pipelinerunID uint) error {
 
 func (c *controller) Ready(ctx context.Context, pipelinerunID uint) error {
 	const op = "pipelinerun controller: ready pipelinerun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
 	if err != nil {
@@ -459,7 +475,7 @@ func (c *controller) executeDeploy(ctx context.Context, application *appmodels.A
 	}
 
 	// 2. create pipelinerun in k8s
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.RegionName)
 	if err != nil {
 		return err
 	}
@@ -478,6 +494,12 @@ func (c *controller) executeDeploy(ctx context.Context, application *appmodels.A
 	if err != nil {
 		return err
 	}
+	if err := c.quotaSvc.Check(ctx, cluster, map[string]interface{}{
+		resource.SectionApplication: clusterFiles.ApplicationJSONBlob,
+		resource.SectionPipeline:    clusterFiles.PipelineJSONBlob,
+	}); err != nil {
+		return err
+	}
 
 	prGit := tekton.PipelineRunGit{
 		URL:       cluster.GitURL,
@@ -556,6 +578,7 @@ func (c *controller) executeRestart(ctx context.Context, application *appmodels.
 	// 3. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    commit,
 	}); err != nil {
@@ -583,6 +606,19 @@ func (c *controller) executeRollback(ctx context.Context, application *appmodels
 	if err != nil {
 		return perror.Wrapf(err, "failed to get pipelinerun to rollback, pr = %d", *pr.RollbackFrom)
 	}
+	rollbackFiles, err := c.clusterGitRepo.GetClusterByCommit(ctx, application.Name, cluster.Name,
+		cluster.Template, prToRollback.ConfigCommit)
+	if err != nil {
+		return perror.Wrapf(err, "failed to get cluster config to rollback, commit = %s",
+			prToRollback.ConfigCommit)
+	}
+	quotaConfig := map[string]interface{}{
+		resource.SectionApplication: rollbackFiles.ApplicationJSONBlob,
+		resource.SectionPipeline:    rollbackFiles.PipelineJSONBlob,
+	}
+	if err := c.quotaSvc.Check(ctx, cluster, quotaConfig); err != nil {
+		return err
+	}
 
 	// 2. update pr status to running
 	if err := c.prMgr.PipelineRun.UpdateStatusByID(ctx, pr.ID, prmodels.StatusRunning); err != nil {
@@ -632,6 +668,9 @@ func (c *controller) executeRollback(ctx context.Context, application *appmodels
 	if err != nil {
 		return perror.Wrapf(err, "failed to sync db with git repo")
 	}
+	if err := c.quotaSvc.Record(ctx, cluster, quotaConfig); err != nil {
+		log.Warningf(ctx, "failed to record resource usage of cluster %s, err: %v", cluster.Name, err)
+	}
 
 	// 6. create cluster in cd system
 	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
@@ -666,6 +705,7 @@ func (c *controller) executeRollback(ctx context.Context, application *appmodels
 	// 8. deploy cluster in cd and update status
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
 		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
 	}); err != nil {
@@ -685,7 +725,8 @@ func (c *controller) executeRollback(ctx context.Context, application *appmodels
 
 func (c *controller) Cancel(ctx context.Context, pipelinerunID uint) error {
 	const op = "pipelinerun controller: cancel pipelinerun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
 	if err != nil {
 		return err
@@ -706,20 +747,23 @@ func (c *controller) Cancel(ctx context.Context, pipelinerunID uint) error {
 
 func (c *controller) ListCheckRuns(ctx context.Context, pipelinerunID uint) ([]*prmodels.CheckRun, error) {
 	const op = "pipelinerun controller: list check runs"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return c.prMgr.Check.ListCheckRuns(ctx, pipelinerunID)
 }
 
 func (c *controller) GetCheckRunByID(ctx context.Context, checkRunID uint) (*prmodels.CheckRun, error) {
 	const op = "pipelinerun controller: get check run by id"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return c.prMgr.Check.GetCheckRunByID(ctx, checkRunID)
 }
 
 func (c *controller) CreateCheckRun(ctx context.Context, pipelineRunID uint,
 	request *CreateOrUpdateCheckRunRequest) (*prmodels.CheckRun, error) {
 	const op = "pipelinerun controller: create check run"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	checkrun, err := c.prMgr.Check.CreateCheckRun(ctx, &prmodels.CheckRun{
 		Name:          request.Name,
@@ -742,7 +786,8 @@ func (c *controller) CreateCheckRun(ctx context.Context, pipelineRunID uint,
 func (c *controller) CreatePRMessage(ctx context.Context, pipelineRunID uint,
 	request *CreatePRMessageRequest) (*PRMessage, error) {
 	const op = "pipelinerun controller: create pr message"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -772,7 +817,8 @@ func (c *controller) CreatePRMessage(ctx context.Context, pipelineRunID uint,
 func (c *controller) ListPRMessages(ctx context.Context,
 	pipelineRunID uint, query *q.Query) (int, []*PRMessage, error) {
 	const op = "pipelinerun controller: list pr messages"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	count, messages, err := c.prMgr.Message.List(ctx, pipelineRunID, query)
 	if err != nil {
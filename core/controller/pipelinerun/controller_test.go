@@ -50,6 +50,7 @@ import (
 	clustermodel "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
 	"github.com/horizoncd/horizon/pkg/config/token"
 	envmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
 	"github.com/horizoncd/horizon/pkg/git"
@@ -60,6 +61,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/pr/models"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
@@ -105,13 +108,13 @@ func TestGetAndListPipelinerun(t *testing.T) {
 	// 1. test Get PipelineBasic
 	var pipelineID uint = 1932
 	var createUser uint = 32
-	mockPipelineManager.EXPECT().GetFirstCanRollbackPipelinerun(ctx, gomock.Any()).Return(nil, nil).AnyTimes()
-	mockPipelineManager.EXPECT().GetByID(ctx, pipelineID).Return(&models.Pipelinerun{
+	mockPipelineManager.EXPECT().GetFirstCanRollbackPipelinerun(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockPipelineManager.EXPECT().GetByID(gomock.Any(), pipelineID).Return(&models.Pipelinerun{
 		ID:        pipelineID,
 		CreatedBy: createUser,
 	}, nil).Times(1)
 	var UserName = "tom"
-	mockUserManager.EXPECT().GetUserByID(ctx, createUser).Return(&usermodel.User{
+	mockUserManager.EXPECT().GetUserByID(gomock.Any(), createUser).Return(&usermodel.User{
 		Name: UserName,
 	}, nil).Times(1)
 
@@ -141,9 +144,9 @@ func TestGetAndListPipelinerun(t *testing.T) {
 		CreatedBy: 0,
 	})
 
-	mockPipelineManager.EXPECT().GetByClusterID(ctx,
+	mockPipelineManager.EXPECT().GetByClusterID(gomock.Any(),
 		clusterID, gomock.Any(), gomock.Any()).Return(totalCount, pipelineruns, nil).Times(1)
-	mockUserManager.EXPECT().GetUserByID(ctx, gomock.Any()).Return(&usermodel.User{
+	mockUserManager.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Return(&usermodel.User{
 		Name: UserName,
 	}, nil).AnyTimes()
 
@@ -189,7 +192,7 @@ func TestGetDiff(t *testing.T) {
 	lastConfigCommit := "23232"
 	mockCommitGetter.EXPECT().GetCommitHistoryLink(gomock.Any(), gomock.Any()).
 		Return("https://cloudnative.com:22222/demo/springboot-demo/-/commits/"+gitCommit, nil).AnyTimes()
-	mockPipelineManager.EXPECT().GetByID(ctx, pipelineID).Return(&models.Pipelinerun{
+	mockPipelineManager.EXPECT().GetByID(gomock.Any(), pipelineID).Return(&models.Pipelinerun{
 		ID:               0,
 		ClusterID:        clusterID,
 		GitURL:           gitURL,
@@ -202,25 +205,25 @@ func TestGetDiff(t *testing.T) {
 
 	clusterName := "mycluster"
 	var applicationID uint = 1234988
-	mockClusterManager.EXPECT().GetByID(ctx, clusterID).Return(&clustermodel.Cluster{
+	mockClusterManager.EXPECT().GetByID(gomock.Any(), clusterID).Return(&clustermodel.Cluster{
 		ApplicationID: uint(applicationID),
 		Name:          clusterName,
 	}, nil).Times(1)
 
 	applicationName := "myapplication"
-	mockApplicationMananger.EXPECT().GetByID(ctx, applicationID).Return(&applicationmodel.Application{
+	mockApplicationMananger.EXPECT().GetByID(gomock.Any(), applicationID).Return(&applicationmodel.Application{
 		Name: applicationName,
 	}, nil).Times(1)
 
 	commitMsg := "hello world"
-	mockCommitGetter.EXPECT().GetCommit(ctx, gitURL, codemodels.GitRefTypeCommit, gitCommit).
+	mockCommitGetter.EXPECT().GetCommit(gomock.Any(), gitURL, codemodels.GitRefTypeCommit, gitCommit).
 		Return(&git.Commit{
 			ID:      gitCommit,
 			Message: commitMsg,
 		}, nil)
 
 	diff := "this is mydiff"
-	mockClusterGitRepo.EXPECT().CompareConfig(ctx, applicationName, clusterName,
+	mockClusterGitRepo.EXPECT().CompareConfig(gomock.Any(), applicationName, clusterName,
 		&lastConfigCommit, &configCommit).Return(diff, nil).Times(1)
 
 	resp, err := ctl.GetDiff(ctx, pipelineID)
@@ -271,8 +274,8 @@ func Test(t *testing.T) {
 	tektonFty := tektonftymock.NewMockFactory(mockCtl)
 	tekton := tektonmock.NewMockInterface(mockCtl)
 	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
-	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
-	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	tektonFty.EXPECT().GetTekton(gomock.Any(), gomock.Any()).Return(tekton, nil).AnyTimes()
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any(), gomock.Any()).Return(tektonCollector, nil).AnyTimes()
 
 	envMgr := manager.EnvMgr
 
@@ -308,7 +311,7 @@ func Test(t *testing.T) {
 	}
 
 	logBytes := []byte("this is a log")
-	tektonCollector.EXPECT().GetPipelineRunLog(ctx, gomock.Any()).
+	tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).
 		Return(&collector.Log{LogBytes: logBytes}, nil).Times(1)
 
 	l, err := c.GetPipelinerunLog(ctx, pipelinerun.ID)
@@ -332,7 +335,7 @@ func Test(t *testing.T) {
 
 	logCh := make(chan log.Log)
 	errCh := make(chan error)
-	tektonCollector.EXPECT().GetPipelineRunLog(ctx, gomock.Any()).
+	tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).
 		Return(&collector.Log{
 			LogChannel: logCh,
 			ErrChannel: errCh,
@@ -392,7 +395,7 @@ func Test(t *testing.T) {
 		CreatedBy: 1,
 	})
 	assert.Nil(t, err)
-	tekton.EXPECT().StopPipelineRun(ctx, gomock.Any()).Return(nil).AnyTimes()
+	tekton.EXPECT().StopPipelineRun(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	err = c.StopPipelinerun(ctx, pipelinerun.ID)
 	assert.Nil(t, err)
 
@@ -405,7 +408,8 @@ func TestExecutePipelineRun(t *testing.T) {
 	if err := db.AutoMigrate(&applicationmodel.Application{}, &clustermodel.Cluster{},
 		&regionmodels.Region{}, &membermodels.Member{}, &registrymodels.Registry{},
 		&prmodels.Pipelinerun{}, &groupmodels.Group{}, &prmodels.Check{},
-		&usermodel.User{}, &trmodels.TemplateRelease{}, &eventmodels.Event{}); err != nil {
+		&usermodel.User{}, &trmodels.TemplateRelease{}, &eventmodels.Event{},
+		&quotamodels.Quota{}); err != nil {
 		panic(err)
 	}
 	mgr := managerparam.InitManager(db)
@@ -420,7 +424,7 @@ func TestExecutePipelineRun(t *testing.T) {
 	mockTektonInterface := tektonmock.NewMockInterface(mockCtl)
 
 	mockFactory := tektonftymock.NewMockFactory(mockCtl)
-	mockFactory.EXPECT().GetTekton(gomock.Any()).Return(mockTektonInterface, nil).AnyTimes()
+	mockFactory.EXPECT().GetTekton(gomock.Any(), gomock.Any()).Return(mockTektonInterface, nil).AnyTimes()
 	tokenConfig := token.Config{
 		JwtSigningKey:         "hello",
 		CallbackTokenExpireIn: 24 * time.Hour,
@@ -447,6 +451,9 @@ func TestExecutePipelineRun(t *testing.T) {
 		cd:                 mockCD,
 		clusterSvc:         clusterSvc,
 		eventSvc:           eventSvc,
+		quotaSvc: quotaservice.NewService(mgr, mockClusterGitRepo, func() *templateresource.Config {
+			return &templateresource.Config{}
+		}),
 	}
 
 	_, err1 := mgr.EventMgr.CreateEvent(ctx, &eventmodels.Event{
@@ -515,6 +522,11 @@ func TestExecutePipelineRun(t *testing.T) {
 		Return(nil).AnyTimes()
 	mockClusterGitRepo.EXPECT().Rollback(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return("rollback_commit", nil).AnyTimes()
+	mockClusterGitRepo.EXPECT().GetClusterByCommit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(&clustergitrepo.ClusterFiles{
+		PipelineJSONBlob:    map[string]interface{}{},
+		ApplicationJSONBlob: map[string]interface{}{},
+	}, nil).AnyTimes()
 	mockClusterGitRepo.EXPECT().MergeBranch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return("rollback_master_commit", nil).AnyTimes()
 	mockClusterGitRepo.EXPECT().GetClusterTemplate(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -536,7 +548,7 @@ func TestExecutePipelineRun(t *testing.T) {
 		Return(&clustergitrepo.RepoInfo{}).AnyTimes()
 	mockClusterGitRepo.EXPECT().DefaultBranch().Return("master").AnyTimes()
 
-	mockTektonInterface.EXPECT().CreatePipelineRun(ctx, gomock.Any()).Return("hello", nil).AnyTimes()
+	mockTektonInterface.EXPECT().CreatePipelineRun(gomock.Any(), gomock.Any()).Return("hello", nil).AnyTimes()
 
 	mockCD.EXPECT().CreateCluster(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockCD.EXPECT().DeployCluster(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
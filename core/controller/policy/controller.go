@@ -0,0 +1,234 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/policy/engine"
+	"github.com/horizoncd/horizon/pkg/policy/manager"
+	"github.com/horizoncd/horizon/pkg/policy/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// Controller manages the policies evaluated before clusters are deployed, only admin can access them
+type Controller interface {
+	List(ctx context.Context) ([]*Policy, error)
+	Get(ctx context.Context, id uint) (*Policy, error)
+	Create(ctx context.Context, r *PolicyRequest) (*Policy, error)
+	Update(ctx context.Context, id uint, r *PolicyRequest) (*Policy, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type controller struct {
+	policyMgr manager.Manager
+	groupMgr  groupmanager.Manager
+	envMgr    envmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		policyMgr: param.PolicyMgr,
+		groupMgr:  param.GroupMgr,
+		envMgr:    param.EnvMgr,
+	}
+}
+
+func (c *controller) List(ctx context.Context) ([]*Policy, error) {
+	const op = "policy controller: list"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	policies, err := c.policyMgr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Policy, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, toPolicy(policy))
+	}
+	return result, nil
+}
+
+func (c *controller) Get(ctx context.Context, id uint) (*Policy, error) {
+	const op = "policy controller: get"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	policy, err := c.policyMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toPolicy(policy), nil
+}
+
+func (c *controller) Create(ctx context.Context, r *PolicyRequest) (*Policy, error) {
+	const op = "policy controller: create"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validate(ctx, r); err != nil {
+		return nil, err
+	}
+	policy := toModel(r)
+	policy.CreatedBy = currentUser.GetID()
+	policy.UpdatedBy = currentUser.GetID()
+	policy, err = c.policyMgr.Create(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return toPolicy(policy), nil
+}
+
+func (c *controller) Update(ctx context.Context, id uint, r *PolicyRequest) (*Policy, error) {
+	const op = "policy controller: update"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.policyMgr.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := c.validate(ctx, r); err != nil {
+		return nil, err
+	}
+	policy := toModel(r)
+	policy.UpdatedBy = currentUser.GetID()
+	policy, err = c.policyMgr.UpdateByID(ctx, id, policy)
+	if err != nil {
+		return nil, err
+	}
+	return toPolicy(policy), nil
+}
+
+func (c *controller) Delete(ctx context.Context, id uint) error {
+	const op = "policy controller: delete"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := c.policyMgr.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return c.policyMgr.DeleteByID(ctx, id)
+}
+
+func checkAdmin(ctx context.Context) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !currentUser.IsAdmin() {
+		return perror.Wrap(herrors.ErrForbidden, "you have no privilege")
+	}
+	return nil
+}
+
+// validate checks the expression compiles and the environments and groups bound exist
+func (c *controller) validate(ctx context.Context, r *PolicyRequest) error {
+	if r.Name == "" || r.Expression == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "name and expression should not be empty")
+	}
+	if r.Mode != models.ModeAudit && r.Mode != models.ModeEnforce {
+		return perror.Wrapf(herrors.ErrParamInvalid, "mode should be %s or %s",
+			models.ModeAudit, models.ModeEnforce)
+	}
+	if _, err := engine.Compile(r.Expression); err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid expression: %v", err)
+	}
+	for _, environment := range r.Environments {
+		if _, err := c.envMgr.GetByName(ctx, environment); err != nil {
+			return err
+		}
+	}
+	for _, groupID := range r.GroupIDs {
+		if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toModel(r *PolicyRequest) *models.Policy {
+	groupIDs := make([]string, 0, len(r.GroupIDs))
+	for _, groupID := range r.GroupIDs {
+		groupIDs = append(groupIDs, strconv.FormatUint(uint64(groupID), 10))
+	}
+	return &models.Policy{
+		Name:         r.Name,
+		Description:  r.Description,
+		Expression:   r.Expression,
+		Message:      r.Message,
+		Mode:         r.Mode,
+		Kinds:        strings.Join(r.Kinds, ","),
+		Environments: strings.Join(r.Environments, ","),
+		GroupIDs:     strings.Join(groupIDs, ","),
+	}
+}
+
+func toPolicy(policy *models.Policy) *Policy {
+	groupIDs := make([]uint, 0)
+	for _, groupID := range models.SplitList(policy.GroupIDs) {
+		if id, err := strconv.ParseUint(groupID, 10, 0); err == nil {
+			groupIDs = append(groupIDs, uint(id))
+		}
+	}
+	return &Policy{
+		ID: policy.ID,
+		PolicyRequest: PolicyRequest{
+			Name:         policy.Name,
+			Description:  policy.Description,
+			Expression:   policy.Expression,
+			Message:      policy.Message,
+			Mode:         policy.Mode,
+			Kinds:        models.SplitList(policy.Kinds),
+			Environments: models.SplitList(policy.Environments),
+			GroupIDs:     groupIDs,
+		},
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/policy/models"
+)
+
+func TestController(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&groupmodels.Group{}, &envmodels.Environment{}, &models.Policy{}); err != nil {
+		panic(err)
+	}
+	manager := managerparam.InitManager(db)
+	c := NewController(&param.Param{Manager: manager})
+	// nolint
+	adminCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    1,
+		Admin: true,
+	})
+	// nolint
+	userCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "tony",
+		ID:   2,
+	})
+
+	group := &groupmodels.Group{Name: "group", Path: "group", TraversalIDs: "1"}
+	assert.Nil(t, db.Save(group).Error)
+	assert.Nil(t, db.Save(&envmodels.Environment{Name: "online"}).Error)
+
+	request := &PolicyRequest{
+		Name:         "replicas",
+		Expression:   "object.spec.replicas >= 2",
+		Message:      "at least 2 replicas are required",
+		Mode:         models.ModeEnforce,
+		Kinds:        []string{"Deployment"},
+		Environments: []string{"online"},
+		GroupIDs:     []uint{group.ID},
+	}
+	_, err := c.List(userCtx)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = c.Create(userCtx, request)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// invalid requests
+	for _, r := range []*PolicyRequest{
+		{Name: "invalid", Expression: "object.spec.replicas >=", Mode: models.ModeAudit},
+		{Name: "invalid", Expression: "1 + 1", Mode: models.ModeAudit},
+		{Name: "invalid", Expression: "true", Mode: "warn"},
+		{Name: "", Expression: "true", Mode: models.ModeAudit},
+	} {
+		_, err = c.Create(adminCtx, r)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+	_, err = c.Create(adminCtx, &PolicyRequest{Name: "invalid", Expression: "true", Mode: models.ModeAudit,
+		Environments: []string{"test"}})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	_, err = c.Create(adminCtx, &PolicyRequest{Name: "invalid", Expression: "true", Mode: models.ModeAudit,
+		GroupIDs: []uint{100}})
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	policy, err := c.Create(adminCtx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, *request, policy.PolicyRequest)
+	_, err = c.Create(adminCtx, request)
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	request.Mode = models.ModeAudit
+	request.GroupIDs = nil
+	policy, err = c.Update(adminCtx, policy.ID, request)
+	assert.Nil(t, err)
+	assert.Equal(t, models.ModeAudit, policy.Mode)
+	assert.Equal(t, []uint{}, policy.GroupIDs)
+
+	policies, err := c.List(adminCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(policies))
+
+	assert.Nil(t, c.Delete(adminCtx, policy.ID))
+	_, err = c.Get(adminCtx, policy.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
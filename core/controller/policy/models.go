@@ -0,0 +1,40 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"time"
+)
+
+// PolicyRequest creates or updates a policy. Expression is a CEL expression on the variables object,
+// cluster and environment, which is true if the object rendered from the chart complies with the policy.
+// Kinds, environments and groups are the scopes of the policy, empty ones mean all.
+type PolicyRequest struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Expression   string   `json:"expression"`
+	Message      string   `json:"message"`
+	Mode         string   `json:"mode"`
+	Kinds        []string `json:"kinds"`
+	Environments []string `json:"environments"`
+	GroupIDs     []uint   `json:"groupIDs"`
+}
+
+type Policy struct {
+	ID uint `json:"id"`
+	PolicyRequest
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,549 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	"github.com/horizoncd/horizon/pkg/config/preview"
+	envregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/gittrigger/hook"
+	gittriggermodels "github.com/horizoncd/horizon/pkg/gittrigger/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/preview/manager"
+	"github.com/horizoncd/horizon/pkg/preview/models"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	placeholderApplication  = "{application}"
+	placeholderMergeRequest = "{mr}"
+	placeholderBranch       = "{branch}"
+
+	_maxClusterNameLength = 53
+)
+
+var _invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+type Controller interface {
+	CreateRule(ctx context.Context, applicationID uint, r *CreateRuleRequest) (*Rule, error)
+	UpdateRule(ctx context.Context, applicationID, id uint, r *UpdateRuleRequest) (*Rule, error)
+	ListRules(ctx context.Context, applicationID uint) ([]*Rule, error)
+	DeleteRule(ctx context.Context, applicationID, id uint) error
+	ListEnvironments(ctx context.Context, applicationID uint) ([]*Environment, error)
+	// HandleMergeRequest creates or redeploys the preview clusters of the merge request,
+	// and deletes them once the merge request is merged or closed.
+	// It returns the rules matching the event, the clusters are handled asynchronously
+	HandleMergeRequest(ctx context.Context, event *hook.Event) ([]uint, error)
+}
+
+type controller struct {
+	configGetter   func() *preview.Config
+	previewMgr     manager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+	envRegionMgr   envregionmanager.Manager
+	userMgr        usermanager.Manager
+	gitGetter      code.GitGetter
+	clusterCtl     clusterctl.Controller
+	authorizer     rbac.Authorizer
+	// locks serializes the events of the same merge request of a rule
+	locks sync.Map
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(configGetter func() *preview.Config, param *param.Param,
+	clusterCtl clusterctl.Controller, authorizer rbac.Authorizer) Controller {
+	return &controller{
+		configGetter:   configGetter,
+		previewMgr:     param.PreviewMgr,
+		applicationMgr: param.ApplicationMgr,
+		clusterMgr:     param.ClusterMgr,
+		envRegionMgr:   param.EnvRegionMgr,
+		userMgr:        param.UserMgr,
+		gitGetter:      param.GitGetter,
+		clusterCtl:     clusterCtl,
+		authorizer:     authorizer,
+	}
+}
+
+func (c *controller) CreateRule(ctx context.Context, applicationID uint,
+	r *CreateRuleRequest) (*Rule, error) {
+	const op = "preview controller: create rule"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	rule := &models.PreviewRule{
+		ApplicationID: applicationID,
+		BaseClusterID: r.BaseClusterID,
+		Environment:   r.Environment,
+		Region:        r.Region,
+		NamePattern:   r.NamePattern,
+		TargetBranch:  r.TargetBranch,
+		Enabled:       enabled,
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	}
+	if rule.TTLSeconds, err = parseTTL(r.TTL); err != nil {
+		return nil, err
+	}
+	if err := c.validate(ctx, rule); err != nil {
+		return nil, err
+	}
+	rule, err = c.previewMgr.CreateRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	return ofRule(rule), nil
+}
+
+func (c *controller) UpdateRule(ctx context.Context, applicationID, id uint,
+	r *UpdateRuleRequest) (*Rule, error) {
+	const op = "preview controller: update rule"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := c.getRule(ctx, applicationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.BaseClusterID != nil {
+		rule.BaseClusterID = *r.BaseClusterID
+	}
+	if r.Environment != nil {
+		rule.Environment = *r.Environment
+	}
+	if r.Region != nil {
+		rule.Region = *r.Region
+	}
+	if r.NamePattern != nil {
+		rule.NamePattern = *r.NamePattern
+	}
+	if r.TargetBranch != nil {
+		rule.TargetBranch = *r.TargetBranch
+	}
+	if r.TTL != nil {
+		if rule.TTLSeconds, err = parseTTL(*r.TTL); err != nil {
+			return nil, err
+		}
+	}
+	if r.Enabled != nil {
+		rule.Enabled = *r.Enabled
+	}
+	if err := c.validate(ctx, rule); err != nil {
+		return nil, err
+	}
+	// preview clusters are created as the last one who updates the rule
+	rule.UpdatedBy = currentUser.GetID()
+	rule, err = c.previewMgr.UpdateRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	return ofRule(rule), nil
+}
+
+func (c *controller) ListRules(ctx context.Context, applicationID uint) ([]*Rule, error) {
+	const op = "preview controller: list rules"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	rules, err := c.previewMgr.ListRulesByApplicationID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Rule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, ofRule(rule))
+	}
+	return result, nil
+}
+
+func (c *controller) DeleteRule(ctx context.Context, applicationID, id uint) error {
+	const op = "preview controller: delete rule"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if _, err := c.getRule(ctx, applicationID, id); err != nil {
+		return err
+	}
+	return c.previewMgr.DeleteRule(ctx, id)
+}
+
+func (c *controller) ListEnvironments(ctx context.Context, applicationID uint) ([]*Environment, error) {
+	const op = "preview controller: list environments"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	envs, err := c.previewMgr.ListEnvironmentsByApplicationID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Environment, 0, len(envs))
+	for _, env := range envs {
+		result = append(result, ofEnvironment(env))
+	}
+	return result, nil
+}
+
+func (c *controller) HandleMergeRequest(ctx context.Context, event *hook.Event) ([]uint, error) {
+	const op = "preview controller: handle merge request"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	ruleIDs := []uint{}
+	if event.Type != gittriggermodels.EventMergeRequest || event.MergeRequestID == 0 {
+		return ruleIDs, nil
+	}
+	rules, err := c.previewMgr.ListEnabledRulesByGitURLs(ctx, hook.CandidateURLs(event.RepoURLs))
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if !matchTargetBranch(rule.TargetBranch, event.TargetBranch) {
+			continue
+		}
+		ruleIDs = append(ruleIDs, rule.ID)
+		log.Infof(ctx, "preview rule %d of application %d matches merge request %d",
+			rule.ID, rule.ApplicationID, event.MergeRequestID)
+		go c.handle(rule, event)
+	}
+	return ruleIDs, nil
+}
+
+// handle creates, redeploys or deletes the preview cluster of the merge request
+// as the operator of the rule, it's skipped if the operator is no longer allowed to do so
+func (c *controller) handle(rule *models.PreviewRuleWithGitURL, event *hook.Event) {
+	const op = "preview controller: handle"
+	// nolint
+	ctx := context.WithValue(context.Background(), requestid.HeaderXRequestID, uuid.NewV4().String())
+	logger := log.WithFiled(ctx, "op", op)
+
+	key := fmt.Sprintf("%d/%d", rule.ID, event.MergeRequestID)
+	lock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+	if event.Closed {
+		// the merge request is torn down, deleted before unlocking
+		defer c.locks.Delete(key)
+	}
+
+	user, err := c.userMgr.GetUserByID(ctx, rule.UpdatedBy)
+	if err != nil {
+		logger.Errorf("failed to get operator %d of preview rule %d, err: %v", rule.UpdatedBy, rule.ID, err)
+		return
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	env, err := c.getEnvironment(ctx, rule.ID, event.MergeRequestID)
+	if err != nil {
+		logger.Errorf("failed to get preview environment of rule %d, err: %v", rule.ID, err)
+		return
+	}
+
+	if event.Closed {
+		if env == nil {
+			return
+		}
+		if err := c.authorize(ctx, "delete", common.ResourceCluster, "", env.ClusterID); err != nil {
+			logger.Errorf("deleting preview cluster %d is skipped, err: %v", env.ClusterID, err)
+			return
+		}
+		if err := c.clusterCtl.DeleteCluster(ctx, env.ClusterID, false); err != nil && !isNotFound(err) {
+			logger.Errorf("failed to delete preview cluster %d, err: %v", env.ClusterID, err)
+			return
+		}
+		if err := c.previewMgr.DeleteEnvironment(ctx, env.ID); err != nil {
+			logger.Errorf("failed to delete preview environment %d, err: %v", env.ID, err)
+			return
+		}
+		logger.Infof("preview cluster %d of merge request %d is deleted", env.ClusterID, event.MergeRequestID)
+		return
+	}
+
+	fullPath := ""
+	if env == nil {
+		if err := c.authorize(ctx, "create", common.ResourceApplication, common.ResourceCluster,
+			rule.ApplicationID); err != nil {
+			logger.Errorf("creating preview cluster by rule %d is skipped, err: %v", rule.ID, err)
+			return
+		}
+		if env, fullPath, err = c.createCluster(ctx, rule, event); err != nil {
+			logger.Errorf("failed to create preview cluster by rule %d, err: %v", rule.ID, err)
+			return
+		}
+	}
+	if err := c.authorize(ctx, "create", common.ResourceCluster, "builddeploy", env.ClusterID); err != nil {
+		logger.Errorf("deploying preview cluster %d is skipped, err: %v", env.ClusterID, err)
+		return
+	}
+	resp, err := c.clusterCtl.BuildDeploy(ctx, env.ClusterID, &clusterctl.BuildDeployRequest{
+		Title: event.Title,
+		Description: fmt.Sprintf("preview of merge request %d at %s, operator: %s",
+			event.MergeRequestID, event.Commit, event.Operator),
+		Git: &clusterctl.BuildDeployRequestGit{Branch: event.Ref},
+	})
+	if err != nil {
+		logger.Errorf("failed to builddeploy preview cluster %d, err: %v", env.ClusterID, err)
+	} else {
+		logger.Infof("preview cluster %d is built and deployed, pipelinerun: %d",
+			env.ClusterID, resp.PipelinerunID)
+	}
+	if fullPath != "" {
+		c.comment(ctx, rule, event, fullPath)
+	}
+}
+
+// authorize checks whether the current user is allowed to request the api of the resource,
+// since the permissions of the operator of a rule may have been revoked after the rule was saved
+func (c *controller) authorize(ctx context.Context, verb, resource, subResource string, id uint) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	decision, reason, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            verb,
+		APIGroup:        common.GroupCore,
+		Resource:        resource,
+		SubResource:     subResource,
+		Name:            strconv.FormatUint(uint64(id), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return err
+	}
+	if decision != auth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden, "%s of %s %d is not allowed: %s",
+			verb, path.Join(resource, subResource), id, reason)
+	}
+	return nil
+}
+
+// getEnvironment returns the preview environment of the merge request, nil is returned
+// if there is none or its cluster has been deleted
+func (c *controller) getEnvironment(ctx context.Context, ruleID uint,
+	mergeRequestID int) (*models.PreviewEnvironment, error) {
+	env, err := c.previewMgr.GetEnvironment(ctx, ruleID, mergeRequestID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, err := c.clusterMgr.GetByID(ctx, env.ClusterID); err != nil {
+		if !isNotFound(err) {
+			return nil, err
+		}
+		if err := c.previewMgr.DeleteEnvironment(ctx, env.ID); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return env, nil
+}
+
+// createCluster clones the base cluster of the rule to a cluster deploying the source branch,
+// and returns the full path of the cluster as well
+func (c *controller) createCluster(ctx context.Context, rule *models.PreviewRuleWithGitURL,
+	event *hook.Event) (*models.PreviewEnvironment, string, error) {
+	application, err := c.applicationMgr.GetByID(ctx, rule.ApplicationID)
+	if err != nil {
+		return nil, "", err
+	}
+	base, err := c.clusterCtl.GetClusterV2(ctx, rule.BaseClusterID)
+	if err != nil {
+		return nil, "", err
+	}
+	if base.Git == nil {
+		return nil, "", herrors.ErrBuildDeployNotSupported
+	}
+	expireTime := ""
+	if rule.TTLSeconds > 0 {
+		expireTime = time.Duration(rule.TTLSeconds * 1e9).String()
+	}
+	cluster, err := c.clusterCtl.CreateClusterV2(ctx, &clusterctl.CreateClusterParamsV2{
+		CreateClusterRequestV2: &clusterctl.CreateClusterRequestV2{
+			Name:           renderName(rule.NamePattern, application.Name, event),
+			Description:    fmt.Sprintf("preview of merge request %s", event.MergeRequestURL),
+			Priority:       base.Priority,
+			ExpireTime:     expireTime,
+			Git:            code.NewGit(base.Git.URL, base.Git.Subfolder, code.GitRefTypeBranch, event.Ref),
+			BuildConfig:    base.BuildConfig,
+			TemplateInfo:   base.TemplateInfo,
+			TemplateConfig: base.TemplateConfig,
+		},
+		ApplicationID: rule.ApplicationID,
+		Environment:   rule.Environment,
+		Region:        rule.Region,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	env, err := c.previewMgr.CreateEnvironment(ctx, &models.PreviewEnvironment{
+		RuleID:          rule.ID,
+		ApplicationID:   rule.ApplicationID,
+		ClusterID:       cluster.ID,
+		MergeRequestID:  event.MergeRequestID,
+		MergeRequestURL: event.MergeRequestURL,
+		SourceBranch:    event.Ref,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return env, cluster.FullPath, nil
+}
+
+// comment posts the link of the preview cluster to the merge request
+func (c *controller) comment(ctx context.Context, rule *models.PreviewRuleWithGitURL,
+	event *hook.Event, fullPath string) {
+	const op = "preview controller: comment"
+
+	body := fmt.Sprintf("Preview cluster %s is created for this merge request, "+
+		"it's deleted once the merge request is merged or closed.",
+		clusterLink(c.configGetter().HorizonURL, fullPath))
+	if err := c.gitGetter.CreateMergeRequestComment(ctx, rule.GitURL, event.MergeRequestID, body); err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to comment on merge request %d, err: %v",
+			event.MergeRequestID, err)
+	}
+}
+
+func (c *controller) getRule(ctx context.Context, applicationID, id uint) (*models.PreviewRule, error) {
+	rule, err := c.previewMgr.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.ApplicationID != applicationID {
+		return nil, herrors.NewErrNotFound(herrors.PreviewRuleInDB,
+			fmt.Sprintf("preview rule %d does not belong to application %d", id, applicationID))
+	}
+	return rule, nil
+}
+
+func (c *controller) validate(ctx context.Context, rule *models.PreviewRule) error {
+	if rule.NamePattern == "" || !strings.Contains(rule.NamePattern, placeholderMergeRequest) {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"namePattern must contain %s to distinguish merge requests", placeholderMergeRequest)
+	}
+	if rule.TargetBranch != "" {
+		if _, err := path.Match(rule.TargetBranch, ""); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid targetBranch %s: %v", rule.TargetBranch, err)
+		}
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, rule.BaseClusterID)
+	if err != nil {
+		return err
+	}
+	if cluster.ApplicationID != rule.ApplicationID {
+		return perror.Wrapf(herrors.ErrParamInvalid, "base cluster %d does not belong to application %d",
+			rule.BaseClusterID, rule.ApplicationID)
+	}
+	if cluster.GitURL == "" {
+		return herrors.ErrBuildDeployNotSupported
+	}
+	if _, err := c.envRegionMgr.GetByEnvironmentAndRegion(ctx, rule.Environment, rule.Region); err != nil {
+		if isNotFound(err) {
+			return perror.Wrapf(herrors.ErrParamInvalid, "region %s is not in environment %s",
+				rule.Region, rule.Environment)
+		}
+		return err
+	}
+	return nil
+}
+
+func parseTTL(ttl string) (uint, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(ttl)
+	if err != nil || duration < 0 {
+		return 0, perror.Wrapf(herrors.ErrParamInvalid, "invalid ttl: %v", ttl)
+	}
+	return uint(duration.Seconds()), nil
+}
+
+func matchTargetBranch(pattern, branch string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(pattern, branch)
+	return matched
+}
+
+// renderName replaces the placeholders in the pattern, and turns it into a valid cluster name
+func renderName(pattern, application string, event *hook.Event) string {
+	name := strings.NewReplacer(
+		placeholderApplication, application,
+		placeholderMergeRequest, strconv.Itoa(event.MergeRequestID),
+		placeholderBranch, event.Ref,
+	).Replace(pattern)
+	name = _invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > _maxClusterNameLength {
+		name = name[:_maxClusterNameLength]
+	}
+	return strings.Trim(name, "-")
+}
+
+func clusterLink(horizonURL, fullPath string) string {
+	if horizonURL == "" {
+		return fullPath
+	}
+	return fmt.Sprintf("[%s](%s%s)", fullPath, strings.TrimSuffix(horizonURL, "/"), fullPath)
+}
+
+func isNotFound(err error) bool {
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	return ok
+}
@@ -0,0 +1,246 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/lib/orm"
+	codemock "github.com/horizoncd/horizon/mock/pkg/cluster/code"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/preview"
+	"github.com/horizoncd/horizon/pkg/gittrigger/hook"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/preview/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestRenderName(t *testing.T) {
+	event := &hook.Event{Ref: "feature/Login_Page", MergeRequestID: 12}
+	assert.Equal(t, "demo-mr-12", renderName("{application}-mr-{mr}", "demo", event))
+	assert.Equal(t, "demo-feature-login-page-12",
+		renderName("{application}-{branch}-{mr}", "demo", event))
+
+	event.Ref = "a-very-long-branch-name-which-exceeds-the-limit-of-cluster-names"
+	name := renderName("{application}-{branch}-{mr}", "demo", event)
+	assert.True(t, len(name) <= _maxClusterNameLength)
+	assert.NotEqual(t, '-', name[len(name)-1])
+}
+
+func TestMatchTargetBranch(t *testing.T) {
+	assert.True(t, matchTargetBranch("", "main"))
+	assert.True(t, matchTargetBranch("release/*", "release/1.0"))
+	assert.False(t, matchTargetBranch("main", "develop"))
+}
+
+func TestClusterLink(t *testing.T) {
+	assert.Equal(t, "/group/demo/demo-mr-1", clusterLink("", "/group/demo/demo-mr-1"))
+	assert.Equal(t, "[/group/demo/demo-mr-1](https://horizon.io/group/demo/demo-mr-1)",
+		clusterLink("https://horizon.io/", "/group/demo/demo-mr-1"))
+}
+
+// fakeAuthorizer denies everything while revoked, and counts the denials
+type fakeAuthorizer struct {
+	mu      sync.Mutex
+	revoked bool
+	denied  int
+}
+
+func (a *fakeAuthorizer) Authorize(context.Context, auth.Attributes) (auth.Decision, string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.revoked {
+		a.denied++
+		return auth.DecisionDeny, "not a member", nil
+	}
+	return auth.DecisionAllow, "", nil
+}
+
+func (a *fakeAuthorizer) setRevoked(revoked bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.revoked = revoked
+}
+
+func (a *fakeAuthorizer) deniedCount() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.denied
+}
+
+// fakeClusterCtl records the clusters created, deployed and deleted by preview rules
+type fakeClusterCtl struct {
+	clusterctl.Controller
+
+	db      *gorm.DB
+	mu      sync.Mutex
+	deploys []string
+	deletes []uint
+}
+
+func (f *fakeClusterCtl) GetClusterV2(_ context.Context, clusterID uint) (*clusterctl.GetClusterResponseV2, error) {
+	return &clusterctl.GetClusterResponseV2{
+		ID:  clusterID,
+		Git: &code.Git{URL: "https://gitlab.com/demo/demo.git"},
+	}, nil
+}
+
+func (f *fakeClusterCtl) CreateClusterV2(_ context.Context,
+	params *clusterctl.CreateClusterParamsV2) (*clusterctl.CreateClusterResponseV2, error) {
+	cluster := &clustermodels.Cluster{
+		Name:          params.Name,
+		ApplicationID: params.ApplicationID,
+		GitURL:        params.Git.URL,
+	}
+	if err := f.db.Create(cluster).Error; err != nil {
+		return nil, err
+	}
+	return &clusterctl.CreateClusterResponseV2{ID: cluster.ID, FullPath: "/demo/" + cluster.Name}, nil
+}
+
+func (f *fakeClusterCtl) BuildDeploy(_ context.Context, clusterID uint,
+	r *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deploys = append(f.deploys, fmt.Sprintf("%d:%s", clusterID, r.Git.Branch))
+	return &clusterctl.BuildDeployResponse{}, nil
+}
+
+func (f *fakeClusterCtl) DeleteCluster(_ context.Context, clusterID uint, _ bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deletes = append(f.deletes, clusterID)
+	return f.db.Delete(&clustermodels.Cluster{}, clusterID).Error
+}
+
+func (f *fakeClusterCtl) calls() ([]string, []uint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.deploys...), append([]uint{}, f.deletes...)
+}
+
+func TestHandleMergeRequest(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.PreviewRule{}, &models.PreviewEnvironment{},
+		&clustermodels.Cluster{}, &appmodels.Application{}, &usermodels.User{}); err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+	user := &usermodels.User{Name: "tony"}
+	assert.Nil(t, db.Create(user).Error)
+	application := &appmodels.Application{Name: "demo"}
+	assert.Nil(t, db.Create(application).Error)
+	base := &clustermodels.Cluster{Name: "demo-test", ApplicationID: application.ID,
+		GitURL: "https://gitlab.com/demo/demo.git"}
+	assert.Nil(t, db.Create(base).Error)
+	rule := &models.PreviewRule{ApplicationID: application.ID, BaseClusterID: base.ID,
+		NamePattern: "{application}-mr-{mr}", TargetBranch: "main", Enabled: true, UpdatedBy: user.ID}
+	assert.Nil(t, db.Create(rule).Error)
+
+	mockCtl := gomock.NewController(t)
+	gitGetter := codemock.NewMockGitGetter(mockCtl)
+	gitGetter.EXPECT().CreateMergeRequestComment(gomock.Any(), base.GitURL, 12, gomock.Any()).Return(nil).Times(1)
+	clusterCtl := &fakeClusterCtl{db: db}
+	authorizer := &fakeAuthorizer{}
+	manager := managerparam.InitManager(db)
+	c := NewController(func() *preview.Config { return &preview.Config{} },
+		&param.Param{Manager: manager, GitGetter: gitGetter}, clusterCtl, authorizer).(*controller)
+
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Merge Request Hook")
+	header.Set("X-Gitlab-Token", "secret")
+	handle := func(action, oldrev, sourceURL string) []uint {
+		event, err := hook.Parse(hook.ProviderGitlab, "secret", header, []byte(fmt.Sprintf(`{
+			"project": {"git_http_url": "https://gitlab.com/demo/demo.git"},
+			"object_attributes": {
+				"iid": 12,
+				"action": %q,
+				"oldrev": %q,
+				"source_branch": "feature",
+				"target_branch": "main",
+				"source": {"git_http_url": %q}
+			}
+		}`, action, oldrev, sourceURL)))
+		assert.Nil(t, err)
+		if event == nil {
+			return nil
+		}
+		ruleIDs, err := c.HandleMergeRequest(ctx, event)
+		assert.Nil(t, err)
+		return ruleIDs
+	}
+	deployed := func(n int) func() bool {
+		return func() bool {
+			deploys, _ := clusterCtl.calls()
+			return len(deploys) == n
+		}
+	}
+
+	// open creates and deploys the preview cluster
+	assert.Equal(t, []uint{rule.ID}, handle("open", "", base.GitURL))
+	assert.Eventually(t, deployed(1), time.Second, 10*time.Millisecond)
+	env, err := manager.PreviewMgr.GetEnvironment(ctx, rule.ID, 12)
+	assert.Nil(t, err)
+	cluster, err := manager.ClusterMgr.GetByID(ctx, env.ClusterID)
+	assert.Nil(t, err)
+	assert.Equal(t, "demo-mr-12", cluster.Name)
+
+	// update with new commits redeploys the cluster, while editing the title changes nothing
+	assert.Nil(t, handle("update", "", base.GitURL))
+	assert.Equal(t, []uint{rule.ID}, handle("update", "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", base.GitURL))
+	assert.Eventually(t, deployed(2), time.Second, 10*time.Millisecond)
+	deploys, _ := clusterCtl.calls()
+	assert.Equal(t, fmt.Sprintf("%d:feature", env.ClusterID), deploys[1])
+
+	// merge requests from forks are ignored
+	assert.Nil(t, handle("open", "", "https://gitlab.com/fork/demo.git"))
+
+	// nothing is done once the permissions of the operator are revoked
+	authorizer.setRevoked(true)
+	assert.Equal(t, []uint{rule.ID}, handle("update", "e4f3a9c1b2d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9", base.GitURL))
+	assert.Equal(t, []uint{rule.ID}, handle("close", "", base.GitURL))
+	assert.Eventually(t, func() bool {
+		return authorizer.deniedCount() == 2
+	}, time.Second, 10*time.Millisecond)
+	deploys, deletes := clusterCtl.calls()
+	assert.Equal(t, 2, len(deploys))
+	assert.Equal(t, 0, len(deletes))
+	authorizer.setRevoked(false)
+
+	// close deletes the cluster, the environment and the lock of the merge request
+	assert.Equal(t, []uint{rule.ID}, handle("close", "", base.GitURL))
+	assert.Eventually(t, func() bool {
+		_, err := manager.PreviewMgr.GetEnvironment(ctx, rule.ID, 12)
+		_, locked := c.locks.Load(fmt.Sprintf("%d/%d", rule.ID, 12))
+		return isNotFound(err) && !locked
+	}, time.Second, 10*time.Millisecond)
+	deploys, deletes = clusterCtl.calls()
+	assert.Equal(t, 2, len(deploys))
+	assert.Equal(t, []uint{env.ClusterID}, deletes)
+}
@@ -0,0 +1,108 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/preview/models"
+)
+
+type Rule struct {
+	ID            uint      `json:"id"`
+	ApplicationID uint      `json:"applicationID"`
+	BaseClusterID uint      `json:"baseClusterID"`
+	Environment   string    `json:"environment"`
+	Region        string    `json:"region"`
+	NamePattern   string    `json:"namePattern"`
+	TargetBranch  string    `json:"targetBranch"`
+	TTL           string    `json:"ttl"`
+	Enabled       bool      `json:"enabled"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	CreatedBy     uint      `json:"createdBy"`
+}
+
+func ofRule(rule *models.PreviewRule) *Rule {
+	ttl := ""
+	if rule.TTLSeconds > 0 {
+		ttl = time.Duration(rule.TTLSeconds * 1e9).String()
+	}
+	return &Rule{
+		ID:            rule.ID,
+		ApplicationID: rule.ApplicationID,
+		BaseClusterID: rule.BaseClusterID,
+		Environment:   rule.Environment,
+		Region:        rule.Region,
+		NamePattern:   rule.NamePattern,
+		TargetBranch:  rule.TargetBranch,
+		TTL:           ttl,
+		Enabled:       rule.Enabled,
+		CreatedAt:     rule.CreatedAt,
+		UpdatedAt:     rule.UpdatedAt,
+		CreatedBy:     rule.CreatedBy,
+	}
+}
+
+type CreateRuleRequest struct {
+	// BaseClusterID is the cluster whose config is cloned to preview clusters
+	BaseClusterID uint   `json:"baseClusterID"`
+	Environment   string `json:"environment"`
+	Region        string `json:"region"`
+	// NamePattern is the name of preview clusters, {application}, {mr} and {branch}
+	// are replaced, such as {application}-mr-{mr}
+	NamePattern string `json:"namePattern"`
+	// TargetBranch is a glob pattern of the target branch of merge requests, such as main
+	TargetBranch string `json:"targetBranch"`
+	// TTL is the expire time of preview clusters, such as 72h, empty means never
+	TTL     string `json:"ttl"`
+	Enabled *bool  `json:"enabled"`
+}
+
+type UpdateRuleRequest struct {
+	BaseClusterID *uint   `json:"baseClusterID"`
+	Environment   *string `json:"environment"`
+	Region        *string `json:"region"`
+	NamePattern   *string `json:"namePattern"`
+	TargetBranch  *string `json:"targetBranch"`
+	TTL           *string `json:"ttl"`
+	Enabled       *bool   `json:"enabled"`
+}
+
+type Environment struct {
+	ID              uint      `json:"id"`
+	RuleID          uint      `json:"ruleID"`
+	ApplicationID   uint      `json:"applicationID"`
+	ClusterID       uint      `json:"clusterID"`
+	MergeRequestID  int       `json:"mergeRequestID"`
+	MergeRequestURL string    `json:"mergeRequestURL"`
+	SourceBranch    string    `json:"sourceBranch"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+func ofEnvironment(env *models.PreviewEnvironment) *Environment {
+	return &Environment{
+		ID:              env.ID,
+		RuleID:          env.RuleID,
+		ApplicationID:   env.ApplicationID,
+		ClusterID:       env.ClusterID,
+		MergeRequestID:  env.MergeRequestID,
+		MergeRequestURL: env.MergeRequestURL,
+		SourceBranch:    env.SourceBranch,
+		CreatedAt:       env.CreatedAt,
+		UpdatedAt:       env.UpdatedAt,
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	"github.com/horizoncd/horizon/pkg/quota/service"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// Controller manages the resource quotas of groups and applications, only admin can access them
+type Controller interface {
+	// List lists all quotas with the resources used under them
+	List(ctx context.Context) ([]*Quota, error)
+	// ListByResource lists the quotas of a group or an application in all environments
+	ListByResource(ctx context.Context, resourceType string, resourceID uint) ([]*Quota, error)
+	// Set creates or updates the quota of a group or an application in the environment
+	Set(ctx context.Context, resourceType string, resourceID uint, environment string,
+		r *SetQuotaRequest) (*Quota, error)
+	Delete(ctx context.Context, resourceType string, resourceID uint, environment string) error
+}
+
+type controller struct {
+	quotaMgr       manager.Manager
+	quotaSvc       service.Service
+	groupMgr       groupmanager.Manager
+	applicationMgr applicationmanager.Manager
+	envMgr         envmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		quotaMgr:       param.QuotaMgr,
+		quotaSvc:       param.QuotaSvc,
+		groupMgr:       param.GroupMgr,
+		applicationMgr: param.ApplicationMgr,
+		envMgr:         param.EnvMgr,
+	}
+}
+
+func (c *controller) List(ctx context.Context) ([]*Quota, error) {
+	const op = "quota controller: list"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	quotas, err := c.quotaMgr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.toQuotas(ctx, quotas)
+}
+
+func (c *controller) ListByResource(ctx context.Context, resourceType string,
+	resourceID uint) ([]*Quota, error) {
+	const op = "quota controller: list by resource"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	quotas, err := c.quotaMgr.ListByResource(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return c.toQuotas(ctx, quotas)
+}
+
+func (c *controller) Set(ctx context.Context, resourceType string, resourceID uint, environment string,
+	r *SetQuotaRequest) (*Quota, error) {
+	const op = "quota controller: set"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.CPU < 0 || r.Memory < 0 || r.Replicas < 0 || r.Clusters < 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "limits should not be negative")
+	}
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	if _, err := c.envMgr.GetByName(ctx, environment); err != nil {
+		return nil, err
+	}
+	quota, err := c.quotaMgr.Save(ctx, &models.Quota{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Environment:  environment,
+		CPU:          r.CPU,
+		Memory:       r.Memory,
+		Replicas:     r.Replicas,
+		Clusters:     r.Clusters,
+		CreatedBy:    currentUser.GetID(),
+		UpdatedBy:    currentUser.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.toQuota(ctx, quota)
+}
+
+func (c *controller) Delete(ctx context.Context, resourceType string, resourceID uint, environment string) error {
+	const op = "quota controller: delete"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := c.quotaMgr.Get(ctx, resourceType, resourceID, environment); err != nil {
+		return err
+	}
+	return c.quotaMgr.Delete(ctx, resourceType, resourceID, environment)
+}
+
+func checkAdmin(ctx context.Context) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !currentUser.IsAdmin() {
+		return perror.Wrap(herrors.ErrForbidden, "you have no privilege")
+	}
+	return nil
+}
+
+// checkResource checks the group or the application of the quota exists
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	switch resourceType {
+	case common.ResourceGroup:
+		_, err := c.groupMgr.GetByID(ctx, resourceID)
+		return err
+	case common.ResourceApplication:
+		_, err := c.applicationMgr.GetByID(ctx, resourceID)
+		return err
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "quota of %s is not supported", resourceType)
+	}
+}
+
+func (c *controller) toQuotas(ctx context.Context, quotas []*models.Quota) ([]*Quota, error) {
+	result := make([]*Quota, 0, len(quotas))
+	for _, quota := range quotas {
+		q, err := c.toQuota(ctx, quota)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, q)
+	}
+	return result, nil
+}
+
+func (c *controller) toQuota(ctx context.Context, quota *models.Quota) (*Quota, error) {
+	usage, err := c.quotaSvc.Usage(ctx, quota)
+	if err != nil {
+		return nil, err
+	}
+	return &Quota{
+		ResourceType: quota.ResourceType,
+		ResourceID:   quota.ResourceID,
+		Environment:  quota.Environment,
+		Limits: Limits{
+			CPU:      quota.CPU,
+			Memory:   quota.Memory,
+			Replicas: quota.Replicas,
+			Clusters: quota.Clusters,
+		},
+		Usage:     usage,
+		UpdatedAt: quota.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	"github.com/horizoncd/horizon/pkg/quota/service"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+)
+
+func TestController(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&regionmodels.Region{}, &templatemodels.Template{}, &envmodels.Environment{},
+		&models.Quota{}); err != nil {
+		panic(err)
+	}
+	manager := managerparam.InitManager(db)
+	c := NewController(&param.Param{
+		Manager: manager,
+		QuotaSvc: service.NewService(manager, nil, func() *templateresource.Config {
+			return &templateresource.Config{}
+		}),
+	})
+	// nolint
+	adminCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    1,
+		Admin: true,
+	})
+	// nolint
+	userCtx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "tony",
+		ID:   2,
+	})
+
+	group := &groupmodels.Group{Name: "group", Path: "group", TraversalIDs: "1"}
+	assert.Nil(t, db.Save(group).Error)
+	application := &appmodels.Application{Name: "app", GroupID: group.ID}
+	assert.Nil(t, db.Save(application).Error)
+	assert.Nil(t, db.Save(&clustermodels.Cluster{Name: "app-test", ApplicationID: application.ID,
+		EnvironmentName: "test"}).Error)
+	assert.Nil(t, db.Save(&envmodels.Environment{Name: "test"}).Error)
+
+	_, err := c.List(userCtx)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = c.Set(userCtx, common.ResourceGroup, group.ID, "test", &SetQuotaRequest{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	_, err = c.Set(adminCtx, common.ResourceGroup, group.ID, "test",
+		&SetQuotaRequest{Limits: Limits{CPU: -1}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Set(adminCtx, common.ResourceCluster, 1, "test", &SetQuotaRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Set(adminCtx, common.ResourceGroup, group.ID, "online", &SetQuotaRequest{})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	quota, err := c.Set(adminCtx, common.ResourceGroup, group.ID, "test",
+		&SetQuotaRequest{Limits: Limits{CPU: 4000, Clusters: 1}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4000), quota.Limits.CPU)
+	assert.Equal(t, int64(1), quota.Usage.Clusters)
+
+	quota, err = c.Set(adminCtx, common.ResourceGroup, group.ID, "test",
+		&SetQuotaRequest{Limits: Limits{CPU: 8000, Clusters: 2}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8000), quota.Limits.CPU)
+
+	_, err = c.Set(adminCtx, common.ResourceApplication, application.ID, "test",
+		&SetQuotaRequest{Limits: Limits{Replicas: 4}})
+	assert.Nil(t, err)
+
+	quotas, err := c.List(adminCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(quotas))
+	quotas, err = c.ListByResource(adminCtx, common.ResourceGroup, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(quotas))
+	assert.Equal(t, int64(2), quotas[0].Limits.Clusters)
+
+	assert.Nil(t, c.Delete(adminCtx, common.ResourceGroup, group.ID, "test"))
+	err = c.Delete(adminCtx, common.ResourceGroup, group.ID, "test")
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	quotas, err = c.List(adminCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(quotas))
+}
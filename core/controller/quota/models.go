@@ -0,0 +1,44 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+// Limits are the resources limited by a quota, cpu in millicores and memory in MiB over all replicas,
+// zero means unlimited
+type Limits struct {
+	CPU      int64 `json:"cpu"`
+	Memory   int64 `json:"memory"`
+	Replicas int64 `json:"replicas"`
+	Clusters int64 `json:"clusters"`
+}
+
+type SetQuotaRequest struct {
+	Limits
+}
+
+// Quota is the quota of a group or an application in an environment with the resources used under it
+type Quota struct {
+	ResourceType string        `json:"resourceType"`
+	ResourceID   uint          `json:"resourceID"`
+	Environment  string        `json:"environment"`
+	Limits       Limits        `json:"limits"`
+	Usage        *models.Usage `json:"usage"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+}
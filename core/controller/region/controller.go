@@ -16,10 +16,23 @@ package region
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/argocd"
+	tektonfactory "github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
+	tektonconf "github.com/horizoncd/horizon/pkg/config/tekton"
+	envregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
+	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	"github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 type Controller interface {
@@ -28,16 +41,27 @@ type Controller interface {
 	UpdateByID(ctx context.Context, id uint, request *UpdateRegionRequest) error
 	DeleteByID(ctx context.Context, id uint) error
 	GetByID(ctx context.Context, id uint) (*Region, error)
+	// ListCDMappings lists argo cd and tekton mapped to each environment of the region
+	ListCDMappings(ctx context.Context, id uint) ([]*CDMapping, error)
+	// UpdateCDMapping maps argo cd and tekton to the environment of the region,
+	// a nil config removes the mapping and falls back to the one of the environment
+	UpdateCDMapping(ctx context.Context, id uint, environment string, request *UpdateCDMappingRequest) error
 }
 
 func NewController(param *param.Param) Controller {
 	return &controller{
-		regionMgr: param.RegionMgr,
+		regionMgr:    param.RegionMgr,
+		envRegionMgr: param.EnvRegionMgr,
+		argoCDFty:    param.ArgoCDFty,
+		tektonFty:    param.TektonFty,
 	}
 }
 
 type controller struct {
-	regionMgr regionmanager.Manager
+	regionMgr    regionmanager.Manager
+	envRegionMgr envregionmanager.Manager
+	argoCDFty    argocd.Factory
+	tektonFty    tektonfactory.Factory
 }
 
 func (c controller) GetByID(ctx context.Context, id uint) (*Region, error) {
@@ -93,3 +117,168 @@ func (c controller) ListRegions(ctx context.Context) ([]*Region, error) {
 	}
 	return ofRegionEntities(entities), nil
 }
+
+func (c controller) ListCDMappings(ctx context.Context, id uint) ([]*CDMapping, error) {
+	region, err := c.regionMgr.GetRegionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	environmentRegions, err := c.envRegionMgr.ListAllEnvironmentRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make([]*CDMapping, 0)
+	for _, environmentRegion := range environmentRegions {
+		if environmentRegion.RegionName != region.Name {
+			continue
+		}
+		mapping, err := ofEnvironmentRegion(environmentRegion)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping.masked())
+	}
+	return mappings, nil
+}
+
+func (c controller) UpdateCDMapping(ctx context.Context, id uint, environment string,
+	request *UpdateCDMappingRequest) error {
+	region, err := c.regionMgr.GetRegionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	environmentRegion, err := c.envRegionMgr.GetByEnvironmentAndRegion(ctx, environment, region.Name)
+	if err != nil {
+		return err
+	}
+	current, err := ofEnvironmentRegion(environmentRegion)
+	if err != nil {
+		return err
+	}
+	// the credentials are masked when listed, keep the current ones if they are sent back unchanged
+	request.restoreMasked(current)
+
+	var argoCD, tekton []byte
+	if request.ArgoCD != nil {
+		if argoCD, err = json.Marshal(request.ArgoCD); err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+	}
+	if request.Tekton != nil {
+		if tekton, err = json.Marshal(request.Tekton); err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		if err := tektonfactory.Validate(request.Tekton); err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+	}
+
+	if err := c.envRegionMgr.UpdateCDConfigByID(ctx, environmentRegion.ID,
+		string(argoCD), string(tekton)); err != nil {
+		return err
+	}
+
+	// the mapping takes effect on this instance at once, and on others when they reload mappings from db
+	if request.ArgoCD != nil {
+		c.argoCDFty.SetArgoCD(environment, region.Name, request.ArgoCD)
+	} else {
+		c.argoCDFty.DeleteArgoCD(environment, region.Name)
+	}
+	if request.Tekton != nil {
+		return c.tektonFty.SetTekton(environment, region.Name, request.Tekton)
+	}
+	c.tektonFty.DeleteTekton(environment, region.Name)
+	return nil
+}
+
+// LoadCDMappings replaces argo cd and tekton set in factories with the ones mapped to environments of regions
+func LoadCDMappings(environmentRegions []*envregionmodels.EnvironmentRegion,
+	argoCDFty argocd.Factory, tektonFty tektonfactory.Factory) error {
+	argoCDMapper := make(argocdconf.Mapper)
+	tektonMapper := make(tektonconf.Mapper)
+	for _, environmentRegion := range environmentRegions {
+		mapping, err := ofEnvironmentRegion(environmentRegion)
+		if err != nil {
+			return err
+		}
+		if mapping.ArgoCD != nil {
+			argoCDMapper[argocdconf.Key(mapping.Environment, mapping.Region)] = mapping.ArgoCD
+		}
+		if mapping.Tekton != nil {
+			tektonMapper[tektonconf.Key(mapping.Environment, mapping.Region)] = mapping.Tekton
+		}
+	}
+	if err := tektonFty.ReloadOverrides(tektonMapper); err != nil {
+		return err
+	}
+	argoCDFty.ReloadOverrides(argoCDMapper)
+	return nil
+}
+
+// WatchCDMappings reloads argo cd and tekton mapped to environments of regions from db every interval,
+// so that the mappings updated on other instances take effect on this one
+func WatchCDMappings(ctx context.Context, interval time.Duration, envRegionMgr envregionmanager.Manager,
+	argoCDFty argocd.Factory, tektonFty tektonfactory.Factory) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var loaded string
+	for {
+		select {
+		case <-ticker.C:
+			environmentRegions, err := envRegionMgr.ListAllEnvironmentRegions(ctx)
+			if err != nil {
+				log.Errorf(ctx, "failed to list environment regions: %v", err)
+				continue
+			}
+			// factories are reloaded only if mappings change, as creating tekton clients is expensive
+			fingerprint := cdMappingsFingerprint(environmentRegions)
+			if fingerprint == loaded {
+				continue
+			}
+			if err := LoadCDMappings(environmentRegions, argoCDFty, tektonFty); err != nil {
+				log.Errorf(ctx, "failed to load cd mappings: %v", err)
+				continue
+			}
+			loaded = fingerprint
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func cdMappingsFingerprint(environmentRegions []*envregionmodels.EnvironmentRegion) string {
+	var b strings.Builder
+	for _, environmentRegion := range environmentRegions {
+		if environmentRegion.ArgoCD == "" && environmentRegion.Tekton == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s/%s:%s:%s\n", environmentRegion.EnvironmentName,
+			environmentRegion.RegionName, environmentRegion.ArgoCD, environmentRegion.Tekton))
+	}
+	return b.String()
+}
+
+func ofEnvironmentRegion(environmentRegion *envregionmodels.EnvironmentRegion) (*CDMapping, error) {
+	mapping := &CDMapping{
+		Environment: environmentRegion.EnvironmentName,
+		Region:      environmentRegion.RegionName,
+	}
+	if environmentRegion.ArgoCD != "" {
+		mapping.ArgoCD = &argocdconf.ArgoCD{}
+		if err := json.Unmarshal([]byte(environmentRegion.ArgoCD), mapping.ArgoCD); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to unmarshal argo cd config of %s/%s: %v",
+				environmentRegion.EnvironmentName, environmentRegion.RegionName, err)
+		}
+	}
+	if environmentRegion.Tekton != "" {
+		mapping.Tekton = &tektonconf.Tekton{}
+		if err := json.Unmarshal([]byte(environmentRegion.Tekton), mapping.Tekton); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to unmarshal tekton config of %s/%s: %v",
+				environmentRegion.EnvironmentName, environmentRegion.RegionName, err)
+		}
+	}
+	return mapping, nil
+}
@@ -19,6 +19,8 @@ import (
 
 	"github.com/horizoncd/horizon/core/controller/registry"
 	"github.com/horizoncd/horizon/core/controller/tag"
+	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
+	tektonconf "github.com/horizoncd/horizon/pkg/config/tekton"
 	"github.com/horizoncd/horizon/pkg/region/models"
 )
 
@@ -59,6 +61,74 @@ type UpdateRegionRequest struct {
 	Disabled      bool   `json:"disabled"`
 }
 
+// CDMapping is the argo cd and tekton mapped to an environment of a region
+type CDMapping struct {
+	Environment string             `json:"environment"`
+	Region      string             `json:"region"`
+	ArgoCD      *argocdconf.ArgoCD `json:"argoCD,omitempty"`
+	Tekton      *tektonconf.Tekton `json:"tekton,omitempty"`
+}
+
+type UpdateCDMappingRequest struct {
+	ArgoCD *argocdconf.ArgoCD `json:"argoCD"`
+	Tekton *tektonconf.Tekton `json:"tekton"`
+}
+
+// _masked replaces the credentials of argo cd and tekton when the mappings are listed
+const _masked = "******"
+
+// masked returns a copy of the mapping whose credentials are masked
+func (m *CDMapping) masked() *CDMapping {
+	ret := &CDMapping{
+		Environment: m.Environment,
+		Region:      m.Region,
+	}
+	if m.ArgoCD != nil {
+		argoCD := *m.ArgoCD
+		argoCD.Token = mask(argoCD.Token)
+		ret.ArgoCD = &argoCD
+	}
+	if m.Tekton != nil {
+		tekton := *m.Tekton
+		tekton.Kubeconfig = mask(tekton.Kubeconfig)
+		if tekton.LogStorage != nil {
+			logStorage := *tekton.LogStorage
+			logStorage.AccessKey = mask(logStorage.AccessKey)
+			logStorage.SecretKey = mask(logStorage.SecretKey)
+			tekton.LogStorage = &logStorage
+		}
+		ret.Tekton = &tekton
+	}
+	return ret
+}
+
+// restoreMasked replaces the masked credentials in the request with the ones of the current mapping
+func (r *UpdateCDMappingRequest) restoreMasked(current *CDMapping) {
+	if r.ArgoCD != nil && current.ArgoCD != nil && r.ArgoCD.Token == _masked {
+		r.ArgoCD.Token = current.ArgoCD.Token
+	}
+	if r.Tekton != nil && current.Tekton != nil {
+		if r.Tekton.Kubeconfig == _masked {
+			r.Tekton.Kubeconfig = current.Tekton.Kubeconfig
+		}
+		if r.Tekton.LogStorage != nil && current.Tekton.LogStorage != nil {
+			if r.Tekton.LogStorage.AccessKey == _masked {
+				r.Tekton.LogStorage.AccessKey = current.Tekton.LogStorage.AccessKey
+			}
+			if r.Tekton.LogStorage.SecretKey == _masked {
+				r.Tekton.LogStorage.SecretKey = current.Tekton.LogStorage.SecretKey
+			}
+		}
+	}
+}
+
+func mask(credential string) string {
+	if credential == "" {
+		return ""
+	}
+	return _masked
+}
+
 func ofRegionEntity(entity *models.RegionEntity) *Region {
 	var tags []tag.Tag
 	for _, t := range entity.Tags {
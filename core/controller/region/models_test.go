@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package region
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
+	tektonconf "github.com/horizoncd/horizon/pkg/config/tekton"
+)
+
+func TestMaskCDMapping(t *testing.T) {
+	mapping := &CDMapping{
+		Environment: "test",
+		Region:      "hz",
+		ArgoCD:      &argocdconf.ArgoCD{URL: "http://argocd", Token: "token"},
+		Tekton: &tektonconf.Tekton{
+			Server:     "http://tekton",
+			Kubeconfig: "kubeconfig",
+			LogStorage: &tektonconf.LogStorage{Type: "s3", AccessKey: "ak", SecretKey: ""},
+		},
+	}
+	masked := mapping.masked()
+	assert.Equal(t, _masked, masked.ArgoCD.Token)
+	assert.Equal(t, "http://argocd", masked.ArgoCD.URL)
+	assert.Equal(t, _masked, masked.Tekton.Kubeconfig)
+	assert.Equal(t, _masked, masked.Tekton.LogStorage.AccessKey)
+	assert.Equal(t, "", masked.Tekton.LogStorage.SecretKey)
+	// the mapping itself is not masked
+	assert.Equal(t, "token", mapping.ArgoCD.Token)
+	assert.Equal(t, "ak", mapping.Tekton.LogStorage.AccessKey)
+
+	// the masked credentials sent back keep the current ones
+	request := &UpdateCDMappingRequest{
+		ArgoCD: &argocdconf.ArgoCD{URL: "http://argocd2", Token: _masked},
+		Tekton: &tektonconf.Tekton{
+			Kubeconfig: "kubeconfig2",
+			LogStorage: &tektonconf.LogStorage{Type: "s3", AccessKey: _masked},
+		},
+	}
+	request.restoreMasked(mapping)
+	assert.Equal(t, "token", request.ArgoCD.Token)
+	assert.Equal(t, "http://argocd2", request.ArgoCD.URL)
+	assert.Equal(t, "kubeconfig2", request.Tekton.Kubeconfig)
+	assert.Equal(t, "ak", request.Tekton.LogStorage.AccessKey)
+}
@@ -0,0 +1,165 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"context"
+
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/core/controller/clusterconfig"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	rightsizingmanager "github.com/horizoncd/horizon/pkg/rightsizing/manager"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// GetRecommendation returns the resources recommended for the cluster by its usage history
+	GetRecommendation(ctx context.Context, clusterID uint) (*Recommendation, error)
+	// ApplyRecommendation sets the recommended resources by a config update of the cluster
+	ApplyRecommendation(ctx context.Context, clusterID uint) error
+}
+
+type controller struct {
+	clusterMgr              clustermanager.Manager
+	applicationMgr          appmanager.Manager
+	clusterGitRepo          gitrepo.ClusterGitRepo
+	recommendationMgr       rightsizingmanager.Manager
+	clusterCtl              clusterctl.Controller
+	templateResourcesGetter func() *templateresource.Config
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param, clusterCtl clusterctl.Controller,
+	templateResourcesGetter func() *templateresource.Config) Controller {
+	return &controller{
+		clusterMgr:              param.ClusterMgr,
+		applicationMgr:          param.ApplicationMgr,
+		clusterGitRepo:          param.ClusterGitRepo,
+		recommendationMgr:       param.RecommendationMgr,
+		clusterCtl:              clusterCtl,
+		templateResourcesGetter: templateResourcesGetter,
+	}
+}
+
+func (c *controller) GetRecommendation(ctx context.Context, clusterID uint) (*Recommendation, error) {
+	const op = "rightsizing controller: get recommendation"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	recommendation, err := c.recommendationMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return &Recommendation{
+		ClusterID:   recommendation.ClusterID,
+		CPUUsage:    recommendation.CPUUsage,
+		MemoryUsage: recommendation.MemoryUsage,
+		Current: &resource.Resources{
+			Tier:     recommendation.CurrentTier,
+			CPU:      recommendation.CurrentCPU,
+			Memory:   recommendation.CurrentMemory,
+			Replicas: recommendation.Replicas,
+		},
+		Recommended: &resource.Resources{
+			Tier:     recommendation.RecommendedTier,
+			CPU:      recommendation.RecommendedCPU,
+			Memory:   recommendation.RecommendedMemory,
+			Replicas: recommendation.Replicas,
+		},
+		Savings: &Savings{
+			CPU:    (recommendation.CurrentCPU - recommendation.RecommendedCPU) * recommendation.Replicas,
+			Memory: (recommendation.CurrentMemory - recommendation.RecommendedMemory) * recommendation.Replicas,
+		},
+		UpdatedAt: recommendation.UpdatedAt,
+	}, nil
+}
+
+func (c *controller) ApplyRecommendation(ctx context.Context, clusterID uint) error {
+	const op = "rightsizing controller: apply recommendation"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	recommendation, err := c.recommendationMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return err
+	}
+	t := c.templateResourcesGetter().Get(cluster.Template)
+	if t == nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "resources of template %s are not configured",
+			cluster.Template)
+	}
+
+	config := map[string]interface{}{
+		clusterconfig.SectionApplication: orEmpty(files.ApplicationJSONBlob),
+		clusterconfig.SectionPipeline:    orEmpty(files.PipelineJSONBlob),
+	}
+	current, err := resource.Get(t, config)
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	if current.Tier != recommendation.CurrentTier || current.CPU != recommendation.CurrentCPU ||
+		current.Memory != recommendation.CurrentMemory {
+		return perror.Wrap(herrors.ErrParamInvalid,
+			"resources of the cluster have been changed since the recommendation was made")
+	}
+	if err := resource.Set(t, config, &resource.Resources{
+		Tier:   recommendation.RecommendedTier,
+		CPU:    recommendation.RecommendedCPU,
+		Memory: recommendation.RecommendedMemory,
+	}); err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	if err := c.clusterCtl.UpdateClusterV2(ctx, clusterID, &clusterctl.UpdateClusterRequestV2{
+		Description:    cluster.Description,
+		BuildConfig:    section(config, clusterconfig.SectionPipeline),
+		TemplateConfig: section(config, clusterconfig.SectionApplication),
+	}, false); err != nil {
+		return err
+	}
+	return c.recommendationMgr.DeleteByClusterID(ctx, clusterID)
+}
+
+func section(config map[string]interface{}, name string) map[string]interface{} {
+	value, _ := config[name].(map[string]interface{})
+	return orEmpty(value)
+}
+
+func orEmpty(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
@@ -0,0 +1,130 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmockmanager "github.com/horizoncd/horizon/mock/pkg/application/manager"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	clustermockmanager "github.com/horizoncd/horizon/mock/pkg/cluster/manager"
+	rightsizingmockmanager "github.com/horizoncd/horizon/mock/pkg/rightsizing/manager"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	rightsizingmodels "github.com/horizoncd/horizon/pkg/rightsizing/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// fakeClusterController records the last update request
+type fakeClusterController struct {
+	clusterctl.Controller
+
+	request *clusterctl.UpdateClusterRequestV2
+}
+
+func (f *fakeClusterController) UpdateClusterV2(ctx context.Context, clusterID uint,
+	r *clusterctl.UpdateClusterRequestV2, mergePatch bool) error {
+	f.request = r
+	return nil
+}
+
+func TestRecommendation(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterMgr := clustermockmanager.NewMockManager(mockCtl)
+	applicationMgr := applicationmockmanager.NewMockManager(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	recommendationMgr := rightsizingmockmanager.NewMockManager(mockCtl)
+	clusterCtl := &fakeClusterController{}
+	templateResources := &templateresource.Config{Templates: []*templateresource.Template{{
+		Name:         "javaapp",
+		ReplicasPath: "application.app.spec.replicas",
+		TierPath:     "application.app.spec.resource",
+		Tiers: []*templateresource.Tier{
+			{Name: "x-small", CPU: 500, Memory: 1024},
+			{Name: "small", CPU: 1000, Memory: 2048},
+		},
+	}}}
+	c := &controller{
+		clusterMgr:        clusterMgr,
+		applicationMgr:    applicationMgr,
+		clusterGitRepo:    clusterGitRepo,
+		clusterCtl:        clusterCtl,
+		recommendationMgr: recommendationMgr,
+		templateResourcesGetter: func() *templateresource.Config {
+			return templateResources
+		},
+	}
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+
+	clusterMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(&clustermodels.Cluster{
+		Model: global.Model{ID: 1}, ApplicationID: 1, Name: "app-test", Template: "javaapp", Description: "test",
+	}, nil).AnyTimes()
+	applicationMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(
+		&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"}, nil).AnyTimes()
+	resourceTier := "small"
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), "app", "app-test", "javaapp").DoAndReturn(
+		func(context.Context, string, string, string) (*gitrepo.ClusterFiles, error) {
+			return &gitrepo.ClusterFiles{
+				ApplicationJSONBlob: map[string]interface{}{
+					"app": map[string]interface{}{
+						"spec": map[string]interface{}{"replicas": 2, "resource": resourceTier},
+					},
+				},
+				PipelineJSONBlob: map[string]interface{}{"buildxml": "a"},
+			}, nil
+		}).AnyTimes()
+	recommendationMgr.EXPECT().GetByClusterID(gomock.Any(), uint(1)).Return(&rightsizingmodels.Recommendation{
+		ClusterID:         1,
+		CPUUsage:          200,
+		MemoryUsage:       600,
+		CurrentTier:       "small",
+		CurrentCPU:        1000,
+		CurrentMemory:     2048,
+		RecommendedTier:   "x-small",
+		RecommendedCPU:    500,
+		RecommendedMemory: 1024,
+		Replicas:          2,
+	}, nil).AnyTimes()
+	recommendationMgr.EXPECT().DeleteByClusterID(gomock.Any(), uint(1)).Return(nil).Times(1)
+
+	resp, err := c.GetRecommendation(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "x-small", resp.Recommended.Tier)
+	assert.Equal(t, &Savings{CPU: 1000, Memory: 2048}, resp.Savings)
+
+	err = c.ApplyRecommendation(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "test", clusterCtl.request.Description)
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{"spec": map[string]interface{}{"replicas": 2, "resource": "x-small"}},
+	}, clusterCtl.request.TemplateConfig)
+	assert.Equal(t, map[string]interface{}{"buildxml": "a"}, clusterCtl.request.BuildConfig)
+
+	// the recommendation is stale once the resources of the cluster are changed
+	resourceTier = "x-small"
+	err = c.ApplyRecommendation(ctx, 1)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -0,0 +1,40 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
+)
+
+// Recommendation is the resources recommended for a replica of the cluster by its usage history,
+// cpu in millicores and memory in MiB
+type Recommendation struct {
+	ClusterID uint `json:"clusterID"`
+	// CPUUsage and MemoryUsage are the percentiles of the usage of pods over the window
+	CPUUsage    int64               `json:"cpuUsage"`
+	MemoryUsage int64               `json:"memoryUsage"`
+	Current     *resource.Resources `json:"current"`
+	Recommended *resource.Resources `json:"recommended"`
+	// Savings are the requests saved over all replicas, they are negative if more requests are recommended
+	Savings   *Savings  `json:"savings"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Savings struct {
+	CPU    int64 `json:"cpu"`
+	Memory int64 `json:"memory"`
+}
@@ -56,7 +56,8 @@ func NewController(param *param.Param) Controller {
 
 func (c *controller) List(ctx context.Context, resourceType string, resourceID uint) (_ *ListResponse, err error) {
 	const op = "cluster tag controller: list"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	tags, err := c.tagMgr.ListByResourceTypeID(ctx, resourceType, resourceID)
 	if err != nil {
@@ -68,7 +69,8 @@ func (c *controller) List(ctx context.Context, resourceType string, resourceID u
 
 func (c *controller) Update(ctx context.Context, resourceType string, resourceID uint, r *UpdateRequest) (err error) {
 	const op = "cluster tag controller: update"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	tags := r.toTags(resourceType, resourceID)
 	if err := tagmanager.ValidateUpsert(tags); err != nil {
@@ -99,7 +101,8 @@ func (c *controller) Update(ctx context.Context, resourceType string, resourceID
 func (c *controller) ListSubResourceTags(ctx context.Context, resourceType string,
 	resourceID uint) (*ListResponse, error) {
 	const op = "cluster tag controller: list sub resource tags"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	var results []*models.Tag
 	if resourceType == common.ResourceApplication {
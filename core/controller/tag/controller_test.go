@@ -67,7 +67,7 @@ func TestMain(m *testing.M) {
 func Test(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
-	clusterGitRepo.EXPECT().UpdateTags(ctx, gomock.Any(), gomock.Any(),
+	clusterGitRepo.EXPECT().UpdateTags(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	appMgr := manager.ApplicationMgr
@@ -42,12 +42,15 @@ import (
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	"github.com/horizoncd/horizon/pkg/template/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/lint"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/permission"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/horizoncd/horizon/pkg/workload/generic"
 )
 
 type Controller interface {
@@ -93,6 +96,7 @@ type controller struct {
 	memberMgr            membermanager.Manager
 	memberSvc            memberservice.Service
 	templateSchemaGetter schema.Getter
+	workloadRegistry     *generic.Registry
 }
 
 var _ Controller = (*controller)(nil)
@@ -108,6 +112,7 @@ func NewController(param *param.Param, repo templaterepo.TemplateRepo) Controlle
 		memberMgr:            param.MemberMgr,
 		memberSvc:            param.MemberService,
 		groupMgr:             param.GroupMgr,
+		workloadRegistry:     param.WorkloadRegistry,
 	}
 }
 
@@ -200,7 +205,8 @@ func (c *controller) ListV2(ctx context.Context, query *q.Query, withFullPath bo
 // ListTemplate TODO: remove this, keep it for api callers
 func (c *controller) ListTemplate(ctx context.Context) (Templates, error) {
 	const op = "template controller: listTemplate"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	var (
 		tpls Templates
@@ -352,7 +358,8 @@ func (c *controller) addFullPath(ctx context.Context, tpls Templates) (Templates
 
 func (c *controller) ListTemplateRelease(ctx context.Context, templateName string) (_ Releases, err error) {
 	const op = "template controller: listTemplateRelease"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	template, err := c.templateMgr.GetByName(ctx, templateName)
 	if err != nil {
@@ -389,7 +396,8 @@ func (c *controller) ListTemplateRelease(ctx context.Context, templateName strin
 func (c *controller) GetTemplateSchema(ctx context.Context, releaseID uint,
 	param map[string]string) (_ *Schemas, err error) {
 	const op = "template controller: getTemplateSchema"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
 	if err != nil {
@@ -407,7 +415,8 @@ func (c *controller) GetTemplateSchema(ctx context.Context, releaseID uint,
 // ListTemplateByGroupID lists all template available
 func (c *controller) ListTemplateByGroupID(ctx context.Context, groupID uint, withoutCI bool) (Templates, error) {
 	const op = "template controller: listTemplateByGroupID"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	if !c.groupMgr.GroupExist(ctx, groupID) {
 		reason := fmt.Sprintf("group not found: %d", groupID)
@@ -495,7 +504,8 @@ func (c *controller) listTemplateByGroupIDRecursively(ctx context.Context,
 // ListTemplateReleaseByTemplateID lists all releases of the specified template
 func (c *controller) ListTemplateReleaseByTemplateID(ctx context.Context, templateID uint) (Releases, error) {
 	const op = "template controller: listTemplateReleaseByTemplateID"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	user, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -540,7 +550,8 @@ func (c *controller) ListTemplateReleaseByTemplateID(ctx context.Context, templa
 func (c *controller) CreateTemplate(ctx context.Context,
 	groupID uint, request CreateTemplateRequest) (*Template, error) {
 	const op = "template controller: createTemplate"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	user, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -600,7 +611,8 @@ func (c *controller) CreateTemplate(ctx context.Context,
 func (c *controller) CreateRelease(ctx context.Context,
 	templateID uint, request CreateReleaseRequest) (*Release, error) {
 	const op = "template controller: createTemplateRelease"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	template, err := c.templateMgr.GetByID(ctx, templateID)
 	if err != nil {
@@ -618,7 +630,7 @@ func (c *controller) CreateRelease(ctx context.Context,
 			return nil, err
 		}
 		chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-		err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+		err = c.syncReleaseToRepo(ctx, tag.ArchiveData, template.ChartName, chartVersion)
 		if err != nil {
 			return nil, err
 		}
@@ -642,7 +654,8 @@ func (c *controller) CreateRelease(ctx context.Context,
 // GetTemplate gets template by templateID
 func (c *controller) GetTemplate(ctx context.Context, templateID uint) (*Template, error) {
 	const op = "template controller: getTemplate"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	template, err := c.templateMgr.GetByID(ctx, templateID)
 	if err != nil {
@@ -672,7 +685,8 @@ func (c *controller) GetTemplate(ctx context.Context, templateID uint) (*Templat
 
 func (c *controller) GetRelease(ctx context.Context, releaseID uint) (*Release, error) {
 	const op = "template controller: getRelease"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
 	if err != nil {
@@ -701,7 +715,8 @@ func (c *controller) GetRelease(ctx context.Context, releaseID uint) (*Release,
 
 func (c *controller) DeleteTemplate(ctx context.Context, templateID uint) error {
 	const op = "template controller: deleteTemplate"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	releases, err := c.templateReleaseMgr.ListByTemplateID(ctx, templateID)
 	if err != nil {
@@ -735,7 +750,8 @@ func (c *controller) DeleteTemplate(ctx context.Context, templateID uint) error
 
 func (c *controller) DeleteRelease(ctx context.Context, releaseID uint) error {
 	const op = "template controller: deleteRelease"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	ctx = context.WithValue(ctx, hctx.TemplateOnlyRefCount, true)
 	_, count, err := c.templateReleaseMgr.GetRefOfApplication(ctx, releaseID)
@@ -760,7 +776,8 @@ func (c *controller) DeleteRelease(ctx context.Context, releaseID uint) error {
 // UpdateTemplate deletes a template by ID
 func (c *controller) UpdateTemplate(ctx context.Context, templateID uint, request UpdateTemplateRequest) error {
 	const op = "template controller: updateTemplate"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	template, err := c.templateMgr.GetByID(ctx, templateID)
 	if err != nil {
@@ -790,7 +807,8 @@ func (c *controller) UpdateTemplate(ctx context.Context, templateID uint, reques
 // UpdateRelease deletes a template release by ID
 func (c *controller) UpdateRelease(ctx context.Context, releaseID uint, request UpdateReleaseRequest) error {
 	const op = "template controller: updateRelease"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	trUpdate, err := request.toReleaseModel(ctx)
 	if err != nil {
@@ -802,7 +820,8 @@ func (c *controller) UpdateRelease(ctx context.Context, releaseID uint, request
 
 func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) error {
 	const op = "template controller: syncReleaseToRepo"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
 	if err != nil {
@@ -819,7 +838,7 @@ func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) erro
 		return err
 	}
 	chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-	err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+	err = c.syncReleaseToRepo(ctx, tag.ArchiveData, template.ChartName, chartVersion)
 	if err != nil {
 		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, err.Error())
 	} else {
@@ -887,7 +906,12 @@ func (c *controller) checkStatusForRelease(ctx context.Context,
 	return release, nil
 }
 
-func (c *controller) syncReleaseToRepo(chartBytes []byte, name, tag string) error {
+func (c *controller) syncReleaseToRepo(ctx context.Context, chartBytes []byte, name, tag string) error {
+	// charts failing the lint are not published
+	if report := lint.Lint(chartBytes); !report.Passed {
+		return perror.WithStack(&lint.Error{Report: report})
+	}
+
 	chart, err := loader.LoadArchive(bytes.NewReader(chartBytes))
 	if err != nil {
 		return perror.Wrap(herrors.ErrLoadChartArchive, fmt.Sprintf("failed to load archive: %v", err))
@@ -895,7 +919,16 @@ func (c *controller) syncReleaseToRepo(chartBytes []byte, name, tag string) erro
 	chart.Metadata.Version = tag
 	chart.Metadata.Name = name
 
-	return c.templateRepo.UploadChart(chart)
+	if err := c.templateRepo.UploadChart(chart); err != nil {
+		return err
+	}
+	// workloads defined in the chart are supported once the chart is published
+	if c.workloadRegistry != nil {
+		if err := c.workloadRegistry.RegisterChart(ctx, chart); err != nil {
+			log.Warningf(ctx, "failed to register workloads of chart %s: %v", name, err)
+		}
+	}
+	return nil
 }
 
 func (c *controller) checkHasOnlyOwnerPermissionForTemplate(ctx context.Context,
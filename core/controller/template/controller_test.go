@@ -351,7 +351,7 @@ func TestGetSchema(t *testing.T) {
 		ChartName:    charName,
 	}
 	templateReleaseMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(release, nil)
-	templateSchemaGetter.EXPECT().GetTemplateSchema(ctx,
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(),
 		templateName, templateTag, nil).Return(schemas, nil)
 
 	ctl := &controller{
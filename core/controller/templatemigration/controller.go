@@ -0,0 +1,163 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"context"
+
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// PreviewMigration applies the migrations shipped in the chart of the template release
+	// to the application and pipeline values of the cluster, the values of the cluster are not changed
+	PreviewMigration(ctx context.Context, clusterID uint, templateRelease string) (*MigrationResponse, error)
+	// Migrate switches the cluster to the template release with the migrated values by a config update
+	Migrate(ctx context.Context, clusterID uint, r *MigrateRequest) error
+}
+
+type controller struct {
+	clusterMgr      clustermanager.Manager
+	applicationMgr  appmanager.Manager
+	clusterGitRepo  gitrepo.ClusterGitRepo
+	migrationGetter migration.Getter
+	clusterCtl      clusterctl.Controller
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param, clusterCtl clusterctl.Controller) Controller {
+	return &controller{
+		clusterMgr:      param.ClusterMgr,
+		applicationMgr:  param.ApplicationMgr,
+		clusterGitRepo:  param.ClusterGitRepo,
+		migrationGetter: param.MigrationGetter,
+		clusterCtl:      clusterCtl,
+	}
+}
+
+func (c *controller) PreviewMigration(ctx context.Context, clusterID uint,
+	templateRelease string) (*MigrationResponse, error) {
+	const op = "template migration controller: preview migration"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	_, resp, err := c.migrate(ctx, clusterID, templateRelease)
+	return resp, err
+}
+
+func (c *controller) Migrate(ctx context.Context, clusterID uint, r *MigrateRequest) error {
+	const op = "template migration controller: migrate"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	cluster, resp, err := c.migrate(ctx, clusterID, r.TemplateRelease)
+	if err != nil {
+		return err
+	}
+	return c.clusterCtl.UpdateClusterV2(ctx, clusterID, &clusterctl.UpdateClusterRequestV2{
+		Description: cluster.Description,
+		TemplateInfo: &codemodels.TemplateInfo{
+			Name:    cluster.Template,
+			Release: resp.ToRelease,
+		},
+		BuildConfig:    resp.Pipeline,
+		TemplateConfig: resp.Application,
+	}, false)
+}
+
+// migrate applies the chain of migrations from the current template release of the cluster
+// to the template release on the application and pipeline values of the cluster
+func (c *controller) migrate(ctx context.Context, clusterID uint,
+	templateRelease string) (*clustermodels.Cluster, *MigrationResponse, error) {
+	if templateRelease == "" {
+		return nil, nil, perror.Wrap(herrors.ErrParamInvalid, "templateRelease cannot be empty")
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cluster.TemplateRelease == templateRelease {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"cluster is already of template release %s", templateRelease)
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, nil, err
+	}
+	migrations, err := c.migrationGetter.GetMigrations(ctx, cluster.Template, templateRelease)
+	if err != nil {
+		return nil, nil, err
+	}
+	chain, err := migration.Chain(migrations, cluster.TemplateRelease, templateRelease)
+	if err != nil {
+		return nil, nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	values := map[string]interface{}{
+		migration.SectionApplication: orEmpty(files.ApplicationJSONBlob),
+		migration.SectionPipeline:    orEmpty(files.PipelineJSONBlob),
+	}
+	migrated, err := migration.Apply(values, chain)
+	if err != nil {
+		return nil, nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	resp := &MigrationResponse{
+		ClusterID:   cluster.ID,
+		Template:    cluster.Template,
+		FromRelease: cluster.TemplateRelease,
+		ToRelease:   templateRelease,
+		Migrations:  make([]*MigrationStep, 0, len(chain)),
+		Application: section(migrated, migration.SectionApplication),
+		Pipeline:    section(migrated, migration.SectionPipeline),
+		Differences: jsonpath.Diff(values, migrated),
+	}
+	for _, m := range chain {
+		resp.Migrations = append(resp.Migrations, &MigrationStep{
+			From:        m.From,
+			To:          m.To,
+			Description: m.Description,
+		})
+	}
+	return cluster, resp, nil
+}
+
+func section(values map[string]interface{}, name string) map[string]interface{} {
+	value, _ := values[name].(map[string]interface{})
+	return orEmpty(value)
+}
+
+func orEmpty(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
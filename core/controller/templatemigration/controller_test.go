@@ -0,0 +1,123 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmockmanager "github.com/horizoncd/horizon/mock/pkg/application/manager"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	clustermockmanager "github.com/horizoncd/horizon/mock/pkg/cluster/manager"
+	migrationmock "github.com/horizoncd/horizon/mock/pkg/templaterelease/migration"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+)
+
+// fakeClusterController records the last update request
+type fakeClusterController struct {
+	clusterctl.Controller
+
+	request *clusterctl.UpdateClusterRequestV2
+}
+
+func (f *fakeClusterController) UpdateClusterV2(ctx context.Context, clusterID uint,
+	r *clusterctl.UpdateClusterRequestV2, mergePatch bool) error {
+	f.request = r
+	return nil
+}
+
+func TestPreviewMigrationAndMigrate(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterMgr := clustermockmanager.NewMockManager(mockCtl)
+	applicationMgr := applicationmockmanager.NewMockManager(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	migrationGetter := migrationmock.NewMockGetter(mockCtl)
+	clusterCtl := &fakeClusterController{}
+	c := &controller{
+		clusterMgr:      clusterMgr,
+		applicationMgr:  applicationMgr,
+		clusterGitRepo:  clusterGitRepo,
+		migrationGetter: migrationGetter,
+		clusterCtl:      clusterCtl,
+	}
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{ID: 1, Name: "tony"})
+
+	clusterMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(&clustermodels.Cluster{
+		Model: global.Model{ID: 1}, ApplicationID: 1, Name: "app-test", Template: "javaapp",
+		TemplateRelease: "v1.0.0", Description: "test",
+	}, nil).AnyTimes()
+	applicationMgr.EXPECT().GetByID(gomock.Any(), uint(1)).Return(
+		&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), "app", "app-test", "javaapp").Return(
+		&gitrepo.ClusterFiles{
+			ApplicationJSONBlob: map[string]interface{}{
+				"app": map[string]interface{}{"spec": map[string]interface{}{"replicas": 1, "cpu": 500}},
+			},
+			PipelineJSONBlob: map[string]interface{}{"buildxml": "a"},
+		}, nil).AnyTimes()
+	migrationGetter.EXPECT().GetMigrations(gomock.Any(), "javaapp", "v1.2.0").Return([]*migration.Migration{
+		{From: "v1.0.0", To: "v1.1.0", Description: "resources are moved out of spec", Rules: []*migration.Rule{
+			{Op: migration.OpRename, From: "application.app.spec.cpu", Path: "application.app.resource.cpu"},
+		}},
+		{From: "v1.1.0", To: "v1.2.0", Rules: []*migration.Rule{
+			{Op: migration.OpDefault, Path: "pipeline.language", Value: "java"},
+		}},
+	}, nil).AnyTimes()
+
+	_, err := c.PreviewMigration(ctx, 1, "v1.0.0")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	resp, err := c.PreviewMigration(ctx, 1, "v1.2.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", resp.FromRelease)
+	assert.Equal(t, 2, len(resp.Migrations))
+	assert.Equal(t, "resources are moved out of spec", resp.Migrations[0].Description)
+	assert.Equal(t, map[string]interface{}{
+		"spec":     map[string]interface{}{"replicas": 1},
+		"resource": map[string]interface{}{"cpu": 500},
+	}, resp.Application["app"])
+	assert.Equal(t, "java", resp.Pipeline["language"])
+	paths := make(map[string]string)
+	for _, diff := range resp.Differences {
+		paths[diff.Path] = diff.Type
+	}
+	assert.Equal(t, map[string]string{
+		"application.app.spec.cpu": jsonpath.DifferenceRemoved,
+		"application.app.resource": jsonpath.DifferenceAdded,
+		"pipeline.language":        jsonpath.DifferenceAdded,
+	}, paths)
+	assert.Nil(t, clusterCtl.request)
+
+	err = c.Migrate(ctx, 1, &MigrateRequest{TemplateRelease: "v1.2.0"})
+	assert.Nil(t, err)
+	assert.Equal(t, "javaapp", clusterCtl.request.TemplateInfo.Name)
+	assert.Equal(t, "v1.2.0", clusterCtl.request.TemplateInfo.Release)
+	assert.Equal(t, resp.Application, clusterCtl.request.TemplateConfig)
+	assert.Equal(t, resp.Pipeline, clusterCtl.request.BuildConfig)
+	assert.Equal(t, "test", clusterCtl.request.Description)
+}
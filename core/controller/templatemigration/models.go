@@ -0,0 +1,43 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+)
+
+type MigrateRequest struct {
+	TemplateRelease string `json:"templateRelease"`
+}
+
+type MigrationResponse struct {
+	ClusterID   uint   `json:"clusterID"`
+	Template    string `json:"template"`
+	FromRelease string `json:"fromRelease"`
+	ToRelease   string `json:"toRelease"`
+	// Migrations are the migrations applied in order, it's empty if no migration is needed
+	Migrations []*MigrationStep `json:"migrations"`
+	// Application and Pipeline are the migrated values
+	Application map[string]interface{} `json:"application"`
+	Pipeline    map[string]interface{} `json:"pipeline"`
+	// Differences are the changes made by the migrations, the first key of paths is the section
+	Differences []*jsonpath.Difference `json:"differences"`
+}
+
+type MigrationStep struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Description string `json:"description,omitempty"`
+}
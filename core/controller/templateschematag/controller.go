@@ -44,7 +44,8 @@ func NewController(param *param.Param) Controller {
 
 func (c *controller) List(ctx context.Context, clusterID uint) (_ *ListResponse, err error) {
 	const op = "cluster template scheme tag controller: list"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	tags, err := c.clusterSchemaTagMgr.ListByClusterID(ctx, clusterID)
 	if err != nil {
@@ -56,7 +57,8 @@ func (c *controller) List(ctx context.Context, clusterID uint) (_ *ListResponse,
 
 func (c *controller) Update(ctx context.Context, clusterID uint, r *UpdateRequest) (err error) {
 	const op = "cluster template scheme tag controller: update"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
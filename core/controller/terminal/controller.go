@@ -19,24 +19,38 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/go-redis/redis/v8"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
 
+	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/blob"
+	"github.com/horizoncd/horizon/lib/q"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	terminalconfig "github.com/horizoncd/horizon/pkg/config/terminal"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	envregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	recordingmanager "github.com/horizoncd/horizon/pkg/terminalrecording/manager"
+	recordingmodels "github.com/horizoncd/horizon/pkg/terminalrecording/models"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/recorder"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 
 	"gopkg.in/igm/sockjs-go.v3/sockjs"
@@ -48,8 +62,30 @@ type Controller interface {
 	// CreateShell returns sessionID and sockJSHandler according to clusterID,podName,containerName
 	CreateShell(ctx context.Context, clusterID uint, podName, containerName string) (sessionID string,
 		sockJSHandler http.Handler, err error)
+	// CreateDebugShell injects an ephemeral debug container with an approved image into the pod,
+	// and returns sessionID and sockJSHandler of the shell in the debug container
+	CreateDebugShell(ctx context.Context, clusterID uint, podName, targetContainerName, image string) (
+		sessionID string, sockJSHandler http.Handler, err error)
+	// DownloadFile writes the file or directory in the container to w as a tar archive
+	DownloadFile(ctx context.Context, clusterID uint, podName, containerName, path string, w io.Writer) error
+	// UploadFile writes the file into the directory of the container
+	UploadFile(ctx context.Context, clusterID uint, podName, containerName, dir string, file *File) error
+	// ListRecordings lists the terminal recordings of the cluster
+	ListRecordings(ctx context.Context, clusterID uint, query *q.Query) ([]*Recording, int64, error)
+	// ListAllRecordings lists the terminal recordings of all clusters, only admin is allowed
+	ListAllRecordings(ctx context.Context, query *q.Query) ([]*Recording, int64, error)
+	// GetRecordingContent returns the asciicast content of the recording
+	GetRecordingContent(ctx context.Context, clusterID, recordingID uint) ([]byte, error)
 }
 
+const (
+	// _sessionOwnerKeyPrefix prefixes the redis keys of the owners of session ids
+	_sessionOwnerKeyPrefix = "horizon:terminal:owner:"
+	// _sessionOwnerTTL expires the owners of session ids which are never connected to or not closed,
+	// it's longer than the sessions are expected to last
+	_sessionOwnerTTL = 24 * time.Hour
+)
+
 type controller struct {
 	kubeClientFty      kubeclient.Factory
 	clusterMgr         clustermanager.Manager
@@ -59,12 +95,27 @@ type controller struct {
 	envRegionMgr       envregionmanager.Manager
 	regionMgr          regionmanager.Manager
 	clusterGitRepo     gitrepo.ClusterGitRepo
+	recordingMgr       recordingmanager.Manager
+	eventSvc           eventservice.Service
+	configGetter       func() *terminalconfig.Config
+	// store stores the recordings, recording is disabled if it's nil
+	store blob.Store
+	// redis stores the users who request the session ids from GetTerminalID, so that the instance
+	// serving the connection of a session finds its owner, the entries are deleted when the sessions
+	// are closed and expire after _sessionOwnerTTL
+	redis redis.Cmdable
 }
 
 var _ Controller = (*controller)(nil)
 
-func NewController(param *param.Param) Controller {
+func NewController(param *param.Param, configGetter func() *terminalconfig.Config,
+	store blob.Store, redis redis.Cmdable) Controller {
 	return &controller{
+		redis:              redis,
+		recordingMgr:       param.TerminalRecordingMgr,
+		eventSvc:           param.EventSvc,
+		configGetter:       configGetter,
+		store:              store,
 		kubeClientFty:      kubeclient.Fty,
 		clusterMgr:         param.ClusterMgr,
 		applicationMgr:     param.ApplicationMgr,
@@ -79,7 +130,8 @@ func NewController(param *param.Param) Controller {
 func (c *controller) GetTerminalID(ctx context.Context, clusterID uint, podName,
 	containerName string) (*SessionIDResp, error) {
 	const op = "terminal: get terminal id"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	sessionID := &SessionIDResp{}
 	randomID, err := genRandomID()
 	if err != nil {
@@ -87,110 +139,105 @@ func (c *controller) GetTerminalID(ctx context.Context, clusterID uint, podName,
 	}
 
 	sessionID.ID = genSessionID(clusterID, podName, containerName, randomID)
+	if user, err := common.UserFromContext(ctx); err == nil {
+		if err := c.redis.Set(ctx, _sessionOwnerKeyPrefix+sessionID.ID, user.GetID(),
+			_sessionOwnerTTL).Err(); err != nil {
+			return nil, perror.Wrapf(herrors.ErrWriteFailed, "failed to save owner of session %s: %v",
+				sessionID.ID, err)
+		}
+	}
 	return sessionID, nil
 }
 
 func (c *controller) GetSockJSHandler(ctx context.Context, sessionID string) (http.Handler, error) {
 	const op = "terminal: get sockjs handler"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	clusterID, podName, containerName, randomID, err := parseSessionID(sessionID)
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
 
-	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	ref, kubeConfig, kubeClient, err := c.getContainerRef(ctx, clusterID, podName, containerName, randomID)
 	if err != nil {
 		return nil, err
 	}
 
-	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
-	if err != nil {
-		return nil, err
+	userID, err := c.redis.Get(ctx, _sessionOwnerKeyPrefix+sessionID).Uint64()
+	if err != nil && err != redis.Nil {
+		log.Warningf(ctx, "failed to get owner of session %s, err: %v", sessionID, err)
 	}
+	terminalSessions.Set(ref.String(), c.newSession(ctx, ref, uint(userID)))
 
-	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
-	if err != nil {
-		return nil, err
-	}
+	go WaitForTerminal(kubeClient.Basic, kubeConfig, ref)
 
-	kubeConfig, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server, regionEntity.Certificate)
-	if err != nil {
-		return nil, err
-	}
+	handler := sockjs.NewHandler("/apis/front/v1", sockjs.DefaultOptions, handleTerminalSession)
+	return handler, nil
+}
 
-	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
+	containerName string) (string, http.Handler, error) {
+	const op = "terminal controller: create shell"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	// Generate a random number as the session id
+	randomID, err := genRandomID()
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
-	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
+
+	ref, kubeConfig, kubeClient, err := c.getContainerRef(ctx, clusterID, podName, containerName, randomID)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
-	ref := ContainerRef{
-		Environment: cluster.EnvironmentName,
-		Cluster:     cluster.Name,
-		ClusterID:   cluster.ID,
-		Namespace:   envValue.Namespace,
-		Pod:         podName,
-		Container:   containerName,
-		RandomID:    randomID,
+	var userID uint
+	if user, err := common.UserFromContext(ctx); err == nil {
+		userID = user.GetID()
 	}
+	terminalSessions.Set(ref.String(), c.newSession(ctx, ref, userID))
 
-	terminalSessions.Set(ref.String(), Session{
-		id:       ref.String(),
-		bound:    make(chan error),
-		sizeChan: make(chan remotecommand.TerminalSize),
-	})
+	handler := sockjs.NewHandler("/apis/core/v1", sockjs.DefaultOptions, handleShellSession(ctx, ref.String()))
 
 	go WaitForTerminal(kubeClient.Basic, kubeConfig, ref)
-
-	handler := sockjs.NewHandler("/apis/front/v1", sockjs.DefaultOptions, handleTerminalSession)
-	return handler, nil
+	return randomID, handler, nil
 }
 
-func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
-	containerName string) (string, http.Handler, error) {
-	const op = "terminal controller: create shell"
-	defer wlog.Start(ctx, op).StopPrint()
-
+// getContainerRef returns the reference of the container of the cluster and the kube client of its region
+func (c *controller) getContainerRef(ctx context.Context, clusterID uint, podName, containerName,
+	randomID string) (ContainerRef, *rest.Config, *kube.Client, error) {
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
-		return "", nil, err
+		return ContainerRef{}, nil, nil, err
 	}
 
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
-		return "", nil, err
+		return ContainerRef{}, nil, nil, err
 	}
 
 	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
 	if err != nil {
-		return "", nil, err
+		return ContainerRef{}, nil, nil, err
 	}
 
 	kubeConfig, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server, regionEntity.Certificate)
 	if err != nil {
-		return "", nil, err
+		return ContainerRef{}, nil, nil, err
 	}
 
 	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
 	if err != nil {
-		return "", nil, err
+		return ContainerRef{}, nil, nil, err
 	}
 	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
 	if err != nil {
-		return "", nil, err
+		return ContainerRef{}, nil, nil, err
 	}
 
-	// Generate a random number as the session id
-	randomID, err := genRandomID()
-	if err != nil {
-		return "", nil, err
-	}
-
-	ref := ContainerRef{
+	return ContainerRef{
 		Environment: cluster.EnvironmentName,
 		Cluster:     cluster.Name,
 		ClusterID:   cluster.ID,
@@ -198,18 +245,154 @@ func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
 		Pod:         podName,
 		Container:   containerName,
 		RandomID:    randomID,
+	}, kubeConfig, kubeClient, nil
+}
+
+// newSession creates a session of the container with the terminal policy of its environment,
+// the session is recorded if recording is enabled
+func (c *controller) newSession(ctx context.Context, ref ContainerRef, userID uint) Session {
+	config := c.configGetter()
+	session := Session{
+		id:          ref.String(),
+		bound:       make(chan error),
+		sizeChan:    make(chan remotecommand.TerminalSize),
+		readOnly:    config.IsReadOnly(ref.Environment),
+		idleTimeout: config.IdleTimeout,
+		maxDuration: config.MaxSessionDuration,
+		lastActive:  new(int64),
+		onClose: func(string) {
+			c.deleteSessionOwner(ref)
+		},
+	}
+	if c.store == nil {
+		return session
+	}
+
+	rec, err := recorder.New(fmt.Sprintf("%s/%s/%s", ref.Cluster, ref.Pod, ref.Container),
+		map[string]string{"TERM": "xterm"})
+	if err != nil {
+		log.Warningf(ctx, "failed to create recorder for session %s, err: %v", ref.String(), err)
+		return session
+	}
+	session.recorder = rec
+	mode := recordingmodels.ModeShell
+	if session.readOnly {
+		mode = recordingmodels.ModeAttach
+	}
+	session.onClose = func(reason string) {
+		c.deleteSessionOwner(ref)
+		c.saveRecording(ref, userID, mode, rec, reason)
 	}
+	return session
+}
 
-	terminalSessions.Set(ref.String(), Session{
-		id:       ref.String(),
-		bound:    make(chan error),
-		sizeChan: make(chan remotecommand.TerminalSize),
-	})
+// deleteSessionOwner deletes the owner of the closed session,
+// the request may have been finished, so a background context is used
+func (c *controller) deleteSessionOwner(ref ContainerRef) {
+	ctx := context.Background()
+	if err := c.redis.Del(ctx, _sessionOwnerKeyPrefix+ref.String()).Err(); err != nil {
+		log.Warningf(ctx, "failed to delete owner of session %s, err: %v", ref.String(), err)
+	}
+}
 
-	handler := sockjs.NewHandler("/apis/core/v1", sockjs.DefaultOptions, handleShellSession(ctx, ref.String()))
+// saveRecording uploads the recording to the blob store and indexes it
+func (c *controller) saveRecording(ref ContainerRef, userID uint, mode string,
+	rec *recorder.Recorder, reason string) {
+	// the request may have been finished, so a background context is used
+	ctx := context.Background()
+	content, size, cleanup, err := rec.Close()
+	if err != nil {
+		log.Errorf(ctx, "failed to close recorder of session %s, err: %v", ref.String(), err)
+		return
+	}
+	defer cleanup()
+
+	startedAt := rec.StartedAt()
+	key := fmt.Sprintf("terminal-recordings/%d/%s/%s-%s-%s.cast", ref.ClusterID,
+		startedAt.Format("2006-01-02"), ref.Pod, ref.Container, ref.RandomID)
+	if err := c.store.Put(ctx, key, content); err != nil {
+		log.Errorf(ctx, "failed to upload recording of session %s, err: %v", ref.String(), err)
+		return
+	}
 
-	go WaitForTerminal(kubeClient.Basic, kubeConfig, ref)
-	return randomID, handler, nil
+	if _, err := c.recordingMgr.Create(ctx, &recordingmodels.TerminalRecording{
+		SessionID:   ref.String(),
+		UserID:      userID,
+		ClusterID:   ref.ClusterID,
+		Environment: ref.Environment,
+		Pod:         ref.Pod,
+		Container:   ref.Container,
+		Mode:        mode,
+		ObjectKey:   key,
+		Size:        size,
+		StartedAt:   startedAt,
+		EndedAt:     time.Now(),
+		CloseReason: reason,
+	}); err != nil {
+		log.Errorf(ctx, "failed to index recording of session %s, err: %v", ref.String(), err)
+	}
+}
+
+func (c *controller) ListRecordings(ctx context.Context, clusterID uint,
+	query *q.Query) ([]*Recording, int64, error) {
+	const op = "terminal controller: list recordings"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if query == nil {
+		query = &q.Query{}
+	}
+	if query.Keywords == nil {
+		query.Keywords = q.KeyWords{}
+	}
+	query.Keywords[common.TerminalRecordingQueryByCluster] = clusterID
+	return c.listRecordings(ctx, query)
+}
+
+func (c *controller) ListAllRecordings(ctx context.Context, query *q.Query) ([]*Recording, int64, error) {
+	const op = "terminal controller: list all recordings"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !user.IsAdmin() {
+		return nil, 0, perror.Wrap(herrors.ErrForbidden, "only admin is allowed to list all terminal recordings")
+	}
+	return c.listRecordings(ctx, query)
+}
+
+func (c *controller) listRecordings(ctx context.Context, query *q.Query) ([]*Recording, int64, error) {
+	recordings, total, err := c.recordingMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp := make([]*Recording, 0, len(recordings))
+	for _, recording := range recordings {
+		resp = append(resp, ofRecording(recording))
+	}
+	return resp, total, nil
+}
+
+func (c *controller) GetRecordingContent(ctx context.Context, clusterID, recordingID uint) ([]byte, error) {
+	const op = "terminal controller: get recording content"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if c.store == nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "terminal recording is disabled")
+	}
+	recording, err := c.recordingMgr.Get(ctx, recordingID)
+	if err != nil {
+		return nil, err
+	}
+	if recording.ClusterID != clusterID {
+		return nil, herrors.NewErrNotFound(herrors.TerminalRecordingInDB,
+			fmt.Sprintf("recording %d not found in cluster %d", recordingID, clusterID))
+	}
+	return c.store.Get(ctx, recording.ObjectKey)
 }
 
 func genRandomID() (string, error) {
@@ -0,0 +1,169 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+
+	"gopkg.in/igm/sockjs-go.v3/sockjs"
+)
+
+const (
+	_debugContainerPrefix       = "debugger-"
+	_debugContainerPollInterval = time.Second
+	_debugContainerTimeout      = 2 * time.Minute
+)
+
+func (c *controller) CreateDebugShell(ctx context.Context, clusterID uint, podName,
+	targetContainerName, image string) (string, http.Handler, error) {
+	const op = "terminal controller: create debug shell"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	config := c.configGetter()
+	if image == "" {
+		image = config.DefaultDebugImage()
+	}
+	if !config.IsDebugImageAllowed(image) {
+		return "", nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"image %s is not approved for debug containers", image)
+	}
+
+	randomID, err := genRandomID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ref, kubeConfig, kubeClient, err := c.getContainerRef(ctx, clusterID, podName, targetContainerName, randomID)
+	if err != nil {
+		return "", nil, err
+	}
+	if config.IsReadOnly(ref.Environment) {
+		return "", nil, perror.Wrapf(herrors.ErrForbidden,
+			"debug containers are not allowed in read-only environment %s", ref.Environment)
+	}
+
+	ref.Container = _debugContainerPrefix + randomID
+	if err := addDebugContainer(ctx, kubeClient.Basic, ref, targetContainerName, image); err != nil {
+		return "", nil, err
+	}
+
+	extra, err := json.Marshal(map[string]string{
+		"podName":             podName,
+		"targetContainerName": targetContainerName,
+		"containerName":       ref.Container,
+		"image":               image,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal debug container: %v", err.Error())
+	}
+	extraStr := string(extra)
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, ref.ClusterID,
+		eventmodels.ClusterPodDebugged, &extraStr)
+
+	var userID uint
+	if user, err := common.UserFromContext(ctx); err == nil {
+		userID = user.GetID()
+	}
+	terminalSessions.Set(ref.String(), c.newSession(ctx, ref, userID))
+
+	handler := sockjs.NewHandler("/apis/core/v2", sockjs.DefaultOptions, handleShellSession(ctx, ref.String()))
+
+	go WaitForTerminal(kubeClient.Basic, kubeConfig, ref)
+	return randomID, handler, nil
+}
+
+// addDebugContainer injects an ephemeral container targeting the container into the pod,
+// and waits until it's running
+func addDebugContainer(ctx context.Context, client kubernetes.Interface, ref ContainerRef,
+	targetContainerName, image string) error {
+	return addEphemeralContainer(ctx, client, ref, v1.EphemeralContainer{
+		EphemeralContainerCommon: v1.EphemeralContainerCommon{
+			Name:                     ref.Container,
+			Image:                    image,
+			ImagePullPolicy:          v1.PullIfNotPresent,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: v1.TerminationMessageReadFile,
+		},
+		TargetContainerName: targetContainerName,
+	})
+}
+
+// addEphemeralContainer patches the ephemeral container into the ephemeralcontainers subresource of the pod,
+// the same as kubectl debug, and waits until it's running
+func addEphemeralContainer(ctx context.Context, client kubernetes.Interface, ref ContainerRef,
+	container v1.EphemeralContainer) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ephemeralContainers": []v1.EphemeralContainer{container},
+		},
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	pods := client.CoreV1().Pods(ref.Namespace)
+	if _, err := pods.Patch(ctx, ref.Pod, types.StrategicMergePatchType, patch,
+		metav1.PatchOptions{}, "ephemeralcontainers"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return herrors.NewErrNotFound(herrors.PodsInK8S, err.Error())
+		}
+		return herrors.NewErrUpdateFailed(herrors.PodsInK8S, err.Error())
+	}
+
+	err = wait.PollImmediate(_debugContainerPollInterval, _debugContainerTimeout, func() (bool, error) {
+		pod, err := pods.Get(ctx, ref.Pod, metav1.GetOptions{})
+		if err != nil {
+			return false, herrors.NewErrGetFailed(herrors.PodsInK8S, err.Error())
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != ref.Container {
+				continue
+			}
+			if status.State.Running != nil {
+				return true, nil
+			}
+			if terminated := status.State.Terminated; terminated != nil {
+				return false, perror.Wrapf(herrors.ErrKubeExecFailed, "debug container %s terminated: %s %s",
+					ref.Container, terminated.Reason, terminated.Message)
+			}
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return perror.Wrap(herrors.ErrKubeExecFailed,
+			fmt.Sprintf("debug container %s is not running in %v", ref.Container, _debugContainerTimeout))
+	}
+	return err
+}
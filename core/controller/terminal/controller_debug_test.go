@@ -0,0 +1,149 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	terminalconfig "github.com/horizoncd/horizon/pkg/config/terminal"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+func TestAddDebugContainer(t *testing.T) {
+	ref := ContainerRef{
+		Namespace: "ns",
+		Pod:       "pod",
+		Container: "debugger-abc",
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns"},
+		Status: v1.PodStatus{
+			EphemeralContainerStatuses: []v1.ContainerStatus{{
+				Name:  "debugger-abc",
+				State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			}},
+		},
+	}
+	client := fake.NewSimpleClientset(pod)
+
+	var patched *v1.Pod
+	client.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(k8stesting.PatchAction)
+		if patchAction.GetSubresource() != "ephemeralcontainers" ||
+			patchAction.GetPatchType() != types.StrategicMergePatchType {
+			return false, nil, nil
+		}
+		patched = &v1.Pod{}
+		if err := json.Unmarshal(patchAction.GetPatch(), patched); err != nil {
+			return true, nil, err
+		}
+		return true, pod, nil
+	})
+
+	err := addDebugContainer(context.Background(), client, ref, "app", "busybox")
+	assert.Nil(t, err)
+	assert.NotNil(t, patched)
+	assert.Equal(t, 1, len(patched.Spec.EphemeralContainers))
+	assert.Equal(t, "debugger-abc", patched.Spec.EphemeralContainers[0].Name)
+	assert.Equal(t, "busybox", patched.Spec.EphemeralContainers[0].Image)
+	assert.Equal(t, "app", patched.Spec.EphemeralContainers[0].TargetContainerName)
+
+	ref.Pod = "not-exists"
+	err = addDebugContainer(context.Background(), fake.NewSimpleClientset(), ref, "app", "busybox")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestGetFileContainerRef(t *testing.T) {
+	c := &controller{
+		configGetter: func() *terminalconfig.Config {
+			return &terminalconfig.Config{DebugImages: []string{"busybox"}}
+		},
+	}
+	ref := ContainerRef{
+		Namespace: "ns",
+		Pod:       "pod",
+		Container: "app",
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns"},
+		Spec: v1.PodSpec{
+			EphemeralContainers: []v1.EphemeralContainer{{
+				EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "file-abc"},
+				TargetContainerName:      "app",
+			}},
+		},
+		Status: v1.PodStatus{
+			EphemeralContainerStatuses: []v1.ContainerStatus{{
+				Name:  "file-abc",
+				State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			}},
+		},
+	}
+
+	// the running file container is reused
+	fileRef, err := c.ensureFileContainer(context.Background(), fake.NewSimpleClientset(pod), pod, ref)
+	assert.Nil(t, err)
+	assert.Equal(t, "file-abc", fileRef.Container)
+	fileRef, rootfs, err := c.getFileContainerRef(context.Background(), fake.NewSimpleClientset(pod), nil, ref)
+	assert.Nil(t, err)
+	assert.Equal(t, "file-abc", fileRef.Container)
+	assert.Equal(t, _targetRootfs, rootfs)
+
+	// the container must be running to look up its process if the process namespace is shared by the pod
+	shared := pod.DeepCopy()
+	shareProcessNamespace := true
+	shared.Spec.ShareProcessNamespace = &shareProcessNamespace
+	_, _, err = c.getFileContainerRef(context.Background(), fake.NewSimpleClientset(shared), nil, ref)
+	assert.True(t, perror.Cause(err) == herrors.ErrParamInvalid)
+
+	// no debug image is approved to add a file container
+	c.configGetter = func() *terminalconfig.Config {
+		return &terminalconfig.Config{}
+	}
+	ref.Container = "sidecar"
+	_, err = c.ensureFileContainer(context.Background(), fake.NewSimpleClientset(pod), pod, ref)
+	assert.True(t, perror.Cause(err) == herrors.ErrParamInvalid)
+}
+
+func TestContainerIDOf(t *testing.T) {
+	pod := &v1.Pod{
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name:        "app",
+				ContainerID: "containerd://abc",
+				State:       v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			}, {
+				Name:        "sidecar",
+				ContainerID: "containerd://def",
+				State:       v1.ContainerState{Waiting: &v1.ContainerStateWaiting{}},
+			}},
+		},
+	}
+	assert.Equal(t, "abc", containerIDOf(pod, "app"))
+	assert.Equal(t, "", containerIDOf(pod, "sidecar"))
+	assert.Equal(t, "", containerIDOf(pod, "not-exists"))
+}
@@ -0,0 +1,306 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_fileContainerPrefix = "file-"
+	_targetRootfs        = "/proc/1/root"
+)
+
+func (c *controller) DownloadFile(ctx context.Context, clusterID uint, podName, containerName,
+	filePath string, w io.Writer) error {
+	const op = "terminal controller: download file"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if !path.IsAbs(filePath) || path.Clean(filePath) == "/" {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid file path: %s", filePath)
+	}
+	filePath = path.Clean(filePath)
+
+	ref, kubeConfig, kubeClient, err := c.getContainerRef(ctx, clusterID, podName, containerName, "")
+	if err != nil {
+		return err
+	}
+	if c.configGetter().IsReadOnly(ref.Environment) {
+		return perror.Wrapf(herrors.ErrForbidden,
+			"downloading files is not allowed in read-only environment %s", ref.Environment)
+	}
+
+	fileRef, rootfs, err := c.getFileContainerRef(ctx, kubeClient.Basic, kubeConfig, ref)
+	if err != nil {
+		return err
+	}
+
+	// the same as kubectl cp, the file is archived by tar in the container
+	cmd := []string{"tar", "cf", "-", "-C", rootfs + path.Dir(filePath), path.Base(filePath)}
+	if err := execProcess(kubeClient.Basic, kubeConfig, fileRef, cmd, nil, w); err != nil {
+		return err
+	}
+
+	c.recordFileEvent(ctx, ref, eventmodels.ClusterFileDownloaded, filePath)
+	return nil
+}
+
+func (c *controller) UploadFile(ctx context.Context, clusterID uint, podName, containerName,
+	dir string, file *File) error {
+	const op = "terminal controller: upload file"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	if !path.IsAbs(dir) {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid directory: %s", dir)
+	}
+	if file.Name == "" || file.Name == "." || file.Name == ".." || strings.Contains(file.Name, "/") {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid file name: %s", file.Name)
+	}
+	config := c.configGetter()
+	if config.MaxUploadSize > 0 && file.Size > config.MaxUploadSize {
+		return perror.Wrapf(herrors.ErrParamInvalid, "file size %d exceeds the limit %d",
+			file.Size, config.MaxUploadSize)
+	}
+
+	ref, kubeConfig, kubeClient, err := c.getContainerRef(ctx, clusterID, podName, containerName, "")
+	if err != nil {
+		return err
+	}
+	if config.IsReadOnly(ref.Environment) {
+		return perror.Wrapf(herrors.ErrForbidden,
+			"uploading files is not allowed in read-only environment %s", ref.Environment)
+	}
+
+	fileRef, rootfs, err := c.getFileContainerRef(ctx, kubeClient.Basic, kubeConfig, ref)
+	if err != nil {
+		return err
+	}
+
+	// the file is streamed as a tar archive and extracted in the container, the same as kubectl cp
+	reader, writer := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(writer)
+		err := tw.WriteHeader(&tar.Header{
+			Name:    file.Name,
+			Mode:    0644,
+			Size:    file.Size,
+			ModTime: time.Now(),
+		})
+		if err == nil {
+			_, err = io.CopyN(tw, file.Content, file.Size)
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		_ = writer.CloseWithError(err)
+	}()
+	cmd := []string{"tar", "xmf", "-", "-C", rootfs + path.Clean(dir)}
+	err = execProcess(kubeClient.Basic, kubeConfig, fileRef, cmd, reader, ioutil.Discard)
+	_ = reader.Close()
+	if err != nil {
+		return err
+	}
+
+	c.recordFileEvent(ctx, ref, eventmodels.ClusterFileUploaded, path.Join(dir, file.Name))
+	return nil
+}
+
+// getFileContainerRef returns the container to run tar in and the root of the container's filesystem in it.
+// Files are copied in an ephemeral container targeting the container, since images like distroless have no tar,
+// the filesystem of the container is reached by /proc/<pid>/root of a process of the container,
+// which is /proc/1/root unless the process namespace is shared by the whole pod.
+func (c *controller) getFileContainerRef(ctx context.Context, client kubernetes.Interface, cfg *rest.Config,
+	ref ContainerRef) (ContainerRef, string, error) {
+	pod, err := client.CoreV1().Pods(ref.Namespace).Get(ctx, ref.Pod, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ContainerRef{}, "", herrors.NewErrNotFound(herrors.PodsInK8S, err.Error())
+		}
+		return ContainerRef{}, "", herrors.NewErrGetFailed(herrors.PodsInK8S, err.Error())
+	}
+
+	fileRef, err := c.ensureFileContainer(ctx, client, pod, ref)
+	if err != nil {
+		return ContainerRef{}, "", err
+	}
+	if pod.Spec.ShareProcessNamespace == nil || !*pod.Spec.ShareProcessNamespace {
+		return fileRef, _targetRootfs, nil
+	}
+
+	// pid 1 is the pause container if the process namespace is shared by the whole pod,
+	// so the process of the container is looked up by its container id
+	containerID := containerIDOf(pod, ref.Container)
+	if containerID == "" {
+		return ContainerRef{}, "", perror.Wrapf(herrors.ErrParamInvalid,
+			"container %s is not running", ref.Container)
+	}
+	pid, err := findContainerPid(client, cfg, fileRef, containerID)
+	if err != nil {
+		return ContainerRef{}, "", err
+	}
+	return fileRef, path.Join("/proc", pid, "root"), nil
+}
+
+// ensureFileContainer returns the ephemeral container to copy files of the container in, it's added if absent.
+// The ephemeral container is reused by later copies as ephemeral containers can't be removed from the pod.
+func (c *controller) ensureFileContainer(ctx context.Context, client kubernetes.Interface, pod *v1.Pod,
+	ref ContainerRef) (ContainerRef, error) {
+	fileRef := ref
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.State.Running != nil && strings.HasPrefix(status.Name, _fileContainerPrefix) &&
+			fileContainerTarget(pod, status.Name) == ref.Container {
+			fileRef.Container = status.Name
+			return fileRef, nil
+		}
+	}
+
+	image := c.configGetter().DefaultDebugImage()
+	if image == "" {
+		return ContainerRef{}, perror.Wrap(herrors.ErrParamInvalid,
+			"no debug image is approved for copying files")
+	}
+	randomID, err := genRandomID()
+	if err != nil {
+		return ContainerRef{}, err
+	}
+	fileRef.Container = _fileContainerPrefix + randomID
+	if err := addEphemeralContainer(ctx, client, fileRef, v1.EphemeralContainer{
+		EphemeralContainerCommon: v1.EphemeralContainerCommon{
+			Name:                     fileRef.Container,
+			Image:                    image,
+			ImagePullPolicy:          v1.PullIfNotPresent,
+			Command:                  []string{"tail", "-f", "/dev/null"},
+			TerminationMessagePolicy: v1.TerminationMessageReadFile,
+		},
+		TargetContainerName: ref.Container,
+	}); err != nil {
+		return ContainerRef{}, err
+	}
+	return fileRef, nil
+}
+
+// containerIDOf returns the id of the running container without the runtime prefix like containerd://
+func containerIDOf(pod *v1.Pod, name string) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == name && status.State.Running != nil {
+			id := status.ContainerID
+			if i := strings.Index(id, "://"); i >= 0 {
+				id = id[i+len("://"):]
+			}
+			return id
+		}
+	}
+	return ""
+}
+
+// findContainerPid finds a process of the container in the file container,
+// the cgroup paths of the processes contain the ids of their containers
+func findContainerPid(client kubernetes.Interface, cfg *rest.Config, fileRef ContainerRef,
+	containerID string) (string, error) {
+	stdout := &bytes.Buffer{}
+	cmd := []string{"sh", "-c", `grep -l "$0" /proc/[0-9]*/cgroup 2>/dev/null | head -n 1`, containerID}
+	if err := execProcess(client, cfg, fileRef, cmd, nil, stdout); err != nil {
+		return "", err
+	}
+	// the output is like /proc/<pid>/cgroup
+	parts := strings.Split(strings.TrimSpace(stdout.String()), "/")
+	if len(parts) != 4 {
+		return "", perror.Wrapf(herrors.ErrKubeExecFailed,
+			"process of container %s is not found", containerID)
+	}
+	return parts[2], nil
+}
+
+func fileContainerTarget(pod *v1.Pod, name string) string {
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name == name {
+			return container.TargetContainerName
+		}
+	}
+	return ""
+}
+
+func (c *controller) recordFileEvent(ctx context.Context, ref ContainerRef, eventType, filePath string) {
+	extra, err := json.Marshal(map[string]string{
+		"podName":       ref.Pod,
+		"containerName": ref.Container,
+		"path":          filePath,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal file: %v", err.Error())
+	}
+	extraStr := string(extra)
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, ref.ClusterID, eventType, &extraStr)
+}
+
+// execProcess executes the command in the container without tty,
+// the output of stderr is returned as the error if the command fails
+func execProcess(k8sClient kubernetes.Interface, cfg *rest.Config, ref ContainerRef,
+	cmd []string, stdin io.Reader, stdout io.Writer) error {
+	req := k8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(ref.Pod).
+		Namespace(ref.Namespace).
+		SubResource("exec")
+
+	req.VersionedParams(&v1.PodExecOptions{
+		Container: ref.Container,
+		Command:   cmd,
+		Stdin:     stdin != nil,
+		Stdout:    true,
+		Stderr:    true,
+		TTY:       false,
+	}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(cfg, "POST", req.URL())
+	if err != nil {
+		return perror.Wrap(herrors.ErrKubeExecFailed, err.Error())
+	}
+
+	stderr := &bytes.Buffer{}
+	if err := exec.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	}); err != nil {
+		return perror.Wrapf(herrors.ErrKubeExecFailed, "%s: %s", err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
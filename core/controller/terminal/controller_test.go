@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+
+	terminalconfig "github.com/horizoncd/horizon/pkg/config/terminal"
+)
+
+// fakeRedis keeps the keys in memory
+type fakeRedis struct {
+	redis.Cmdable
+
+	keys map[string]string
+}
+
+func (r *fakeRedis) Set(ctx context.Context, key string, value interface{}, _ time.Duration) *redis.StatusCmd {
+	r.keys[key] = fmt.Sprint(value)
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (r *fakeRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	value, ok := r.keys[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (r *fakeRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	var n int64
+	for _, key := range keys {
+		if _, ok := r.keys[key]; ok {
+			delete(r.keys, key)
+			n++
+		}
+	}
+	return redis.NewIntResult(n, nil)
+}
+
+func TestSessionOwnerDeletedOnClose(t *testing.T) {
+	ctx := context.Background()
+	c := &controller{
+		configGetter: func() *terminalconfig.Config {
+			return &terminalconfig.Config{}
+		},
+		redis: &fakeRedis{keys: map[string]string{}},
+	}
+	ref := ContainerRef{ClusterID: 1, Pod: "pod", Container: "container", RandomID: "abc"}
+	key := _sessionOwnerKeyPrefix + ref.String()
+	assert.Nil(t, c.redis.Set(ctx, key, uint(1), _sessionOwnerTTL).Err())
+
+	session := c.newSession(ctx, ref, 1)
+	owner, err := c.redis.Get(ctx, key).Uint64()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), owner)
+
+	session.onClose(ReasonProcessExited)
+	_, err = c.redis.Get(ctx, key).Result()
+	assert.Equal(t, redis.Nil, err)
+}
@@ -14,6 +14,50 @@
 
 package terminal
 
+import (
+	"io"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+)
+
 type SessionIDResp struct {
 	ID string `json:"id"`
 }
+
+type Recording struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"userID"`
+	ClusterID   uint      `json:"clusterID"`
+	Environment string    `json:"environment"`
+	Pod         string    `json:"podName"`
+	Container   string    `json:"containerName"`
+	Mode        string    `json:"mode"`
+	Size        int64     `json:"size"`
+	StartedAt   time.Time `json:"startedAt"`
+	EndedAt     time.Time `json:"endedAt"`
+	CloseReason string    `json:"closeReason"`
+}
+
+func ofRecording(recording *models.TerminalRecording) *Recording {
+	return &Recording{
+		ID:          recording.ID,
+		UserID:      recording.UserID,
+		ClusterID:   recording.ClusterID,
+		Environment: recording.Environment,
+		Pod:         recording.Pod,
+		Container:   recording.Container,
+		Mode:        recording.Mode,
+		Size:        recording.Size,
+		StartedAt:   recording.StartedAt,
+		EndedAt:     recording.EndedAt,
+		CloseReason: recording.CloseReason,
+	}
+}
+
+// File is a file uploaded to containers
+type File struct {
+	Name    string
+	Size    int64
+	Content io.Reader
+}
@@ -27,7 +27,10 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/horizoncd/horizon/pkg/terminalrecording/recorder"
 	utillog "github.com/horizoncd/horizon/pkg/util/log"
 	"gopkg.in/igm/sockjs-go.v3/sockjs"
 	v1 "k8s.io/api/core/v1"
@@ -39,6 +42,14 @@ import (
 
 const EndOfTransmission = "\u0004"
 
+const (
+	ReasonProcessExited  = "Process exited"
+	ReasonIdleTimeout    = "Session closed for idle timeout"
+	ReasonMaxDuration    = "Session closed for exceeding the max duration"
+	_minIdleCheckPeriod  = time.Second
+	_readOnlyInputNotice = "The terminal is read-only, input is ignored"
+)
+
 // PtyHandler is what remotecommand expects from a pty
 type PtyHandler interface {
 	io.Reader
@@ -53,6 +64,17 @@ type Session struct {
 	sockJSSession sockjs.Session
 	sizeChan      chan remotecommand.TerminalSize
 	doneChan      chan struct{}
+
+	// readOnly sessions attach to the main process of the container without stdin
+	readOnly    bool
+	idleTimeout time.Duration
+	maxDuration time.Duration
+	// lastActive is the unix nano time of the last input or output
+	lastActive *int64
+	// recorder records the session, it's nil if recording is disabled
+	recorder *recorder.Recorder
+	// onClose is called with the reason after the session is closed
+	onClose func(reason string)
 }
 
 // Message is the messaging protocol between ShellController and TerminalSession.
@@ -96,8 +118,15 @@ func (t Session) Read(p []byte) (int, error) {
 
 	switch msg.Op {
 	case "stdin":
+		t.touch()
+		if t.recorder != nil {
+			t.recorder.Input([]byte(msg.Data))
+		}
 		return copy(p, msg.Data), nil
 	case "resize":
+		if t.recorder != nil {
+			t.recorder.Resize(msg.Cols, msg.Rows)
+		}
 		t.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
 		return 0, nil
 	default:
@@ -108,6 +137,10 @@ func (t Session) Read(p []byte) (int, error) {
 // Write handles process->pty stdout
 // Called from remotecommand whenever there is any output
 func (t Session) Write(p []byte) (int, error) {
+	t.touch()
+	if t.recorder != nil {
+		t.recorder.Output(p)
+	}
 	msg, err := json.Marshal(Message{
 		Op:   "stdout",
 		Data: string(p),
@@ -136,6 +169,83 @@ func (t Session) Toast(p string) error {
 	return t.sockJSSession.Send(string(msg))
 }
 
+// touch marks the session active
+func (t Session) touch() {
+	if t.lastActive != nil {
+		atomic.StoreInt64(t.lastActive, time.Now().UnixNano())
+	}
+}
+
+// drainInput consumes the messages of read-only sessions, since stdin is not attached,
+// input is ignored and the user is noticed once
+func (t Session) drainInput() {
+	noticed := false
+	for {
+		m, err := t.sockJSSession.Recv()
+		if err != nil {
+			return
+		}
+		var msg Message
+		if err := json.Unmarshal([]byte(m), &msg); err != nil {
+			continue
+		}
+		if msg.Op == "stdin" && !noticed {
+			noticed = true
+			_ = t.Toast(_readOnlyInputNotice)
+		}
+	}
+}
+
+// watch closes the session when it's idle or lasts too long,
+// the returned stop function returns the reason if the session is closed by watch
+func (t Session) watch() (stop func() string) {
+	done := make(chan struct{})
+	var reason atomic.Value
+	closeSession := func(r string) {
+		reason.Store(r)
+		_ = t.Toast(r)
+		terminalSessions.Close(t.id, 2, r)
+	}
+	go func() {
+		var maxDuration <-chan time.Time
+		if t.maxDuration > 0 {
+			timer := time.NewTimer(t.maxDuration)
+			defer timer.Stop()
+			maxDuration = timer.C
+		}
+		var idleCheck <-chan time.Time
+		if t.idleTimeout > 0 && t.lastActive != nil {
+			period := t.idleTimeout / 10
+			if period < _minIdleCheckPeriod {
+				period = _minIdleCheckPeriod
+			}
+			ticker := time.NewTicker(period)
+			defer ticker.Stop()
+			idleCheck = ticker.C
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case <-maxDuration:
+				closeSession(ReasonMaxDuration)
+				return
+			case <-idleCheck:
+				lastActive := time.Unix(0, atomic.LoadInt64(t.lastActive))
+				if time.Since(lastActive) >= t.idleTimeout {
+					closeSession(ReasonIdleTimeout)
+					return
+				}
+			}
+		}
+	}()
+	return func() string {
+		close(done)
+		r, _ := reason.Load().(string)
+		return r
+	}
+}
+
 // SessionMap stores a map of all TerminalSession objects and a lock to avoid concurrent conflict
 type SessionMap struct {
 	Sessions map[string]Session
@@ -296,28 +406,74 @@ func startProcess(k8sClient kubernetes.Interface, cfg *rest.Config,
 	return nil
 }
 
+// attachProcess attaches to the main process of the container specified in request without stdin
+func attachProcess(k8sClient kubernetes.Interface, cfg *rest.Config,
+	ref ContainerRef, ptyHandler PtyHandler) error {
+	req := k8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(ref.Pod).
+		Namespace(ref.Namespace).
+		SubResource("attach")
+
+	req.VersionedParams(&v1.PodAttachOptions{
+		Container: ref.Container,
+		Stdin:     false,
+		Stdout:    true,
+		Stderr:    true,
+		TTY:       false,
+	}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(cfg, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	return exec.Stream(remotecommand.StreamOptions{
+		Stdout: ptyHandler,
+		Stderr: ptyHandler,
+	})
+}
+
 // WaitForTerminal is called from apihandler.handleAttach as a goroutine
 // Waits for the SockJS connection to be opened by the client the session to be bound in handleTerminalSession
 func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, ref ContainerRef) {
 	<-terminalSessions.Get(ref.String()).bound
 	close(terminalSessions.Get(ref.String()).bound)
 
-	var err error
-	validShells := []string{"bash", "sh"}
+	session := terminalSessions.Get(ref.String())
+	session.touch()
+	stopWatch := session.watch()
 
-	for _, testShell := range validShells {
-		cmd := []string{testShell}
-		if err = startProcess(k8sClient, cfg, ref, cmd, terminalSessions.Get(ref.String())); err == nil {
-			break
+	var err error
+	if session.readOnly {
+		go session.drainInput()
+		err = attachProcess(k8sClient, cfg, ref, session)
+	} else {
+		validShells := []string{"bash", "sh"}
+
+		for _, testShell := range validShells {
+			cmd := []string{testShell}
+			if err = startProcess(k8sClient, cfg, ref, cmd, session); err == nil {
+				break
+			}
 		}
 	}
 
+	reason := stopWatch()
 	if err != nil {
 		terminalSessions.Close(ref.String(), 2, err.Error())
-		return
+		if reason == "" {
+			reason = err.Error()
+		}
+	} else {
+		terminalSessions.Close(ref.String(), 1, ReasonProcessExited)
+		if reason == "" {
+			reason = ReasonProcessExited
+		}
+	}
+	if session.onClose != nil {
+		session.onClose(reason)
 	}
-
-	terminalSessions.Close(ref.String(), 1, "Process exited")
 }
 
 type ContainerRef struct {
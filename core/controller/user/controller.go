@@ -54,7 +54,8 @@ var _ Controller = (*controller)(nil)
 
 func (c *controller) List(ctx context.Context, query *q.Query) (int64, []*User, error) {
 	const op = "user controller: list user"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	total, users, err := c.userMgr.List(ctx, query)
 	if err != nil {
@@ -68,7 +68,8 @@ func NewController(param *param.Param) Controller {
 func (c *controller) CreateWebhook(ctx context.Context, resourceType string,
 	resourceID uint, w *CreateWebhookRequest) (*Webhook, error) {
 	const op = "webhook controller: create"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. validate request
 	if err := c.validateCreateRequest(resourceType, w); err != nil {
@@ -92,7 +93,8 @@ func (c *controller) CreateWebhook(ctx context.Context, resourceType string,
 
 func (c *controller) GetWebhook(ctx context.Context, id uint) (*Webhook, error) {
 	const op = "wehook controller: get"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	w, err := c.webhookMgr.GetWebhook(ctx, id)
 	if err != nil {
@@ -104,7 +106,8 @@ func (c *controller) GetWebhook(ctx context.Context, id uint) (*Webhook, error)
 func (c *controller) UpdateWebhook(ctx context.Context, id uint,
 	w *UpdateWebhookRequest) (*Webhook, error) {
 	const op = "wehook controller: update"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. validate request
 	if err := c.validateUpdateRequest(w); err != nil {
@@ -129,7 +132,8 @@ func (c *controller) UpdateWebhook(ctx context.Context, id uint,
 
 func (c *controller) DeleteWebhook(ctx context.Context, id uint) error {
 	const op = "wehook controller: delete"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	return c.webhookMgr.DeleteWebhook(ctx, id)
 }
@@ -137,7 +141,8 @@ func (c *controller) DeleteWebhook(ctx context.Context, id uint) error {
 func (c *controller) ListWebhooks(ctx context.Context, resourceType string,
 	resourceID uint, query *q.Query) ([]*Webhook, int64, error) {
 	const op = "wehook controller: list"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	resource := map[string][]uint{
 		resourceType: {resourceID},
@@ -157,7 +162,8 @@ func (c *controller) ListWebhooks(ctx context.Context, resourceType string,
 func (c *controller) ListWebhookLogs(ctx context.Context, wID uint,
 	query *q.Query) ([]*LogSummary, int64, error) {
 	const op = "wehook controller: list log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	resources := map[string][]uint{}
 
@@ -229,7 +235,8 @@ func (c *controller) ListWebhookLogs(ctx context.Context, wID uint,
 
 func (c *controller) GetWebhookLog(ctx context.Context, id uint) (*Log, error) {
 	const op = "wehook controller: get log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	wl, err := c.webhookMgr.GetWebhookLog(ctx, id)
 	if err != nil {
@@ -249,7 +256,8 @@ func (c *controller) GetWebhookLog(ctx context.Context, id uint) (*Log, error) {
 
 func (c *controller) ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error) {
 	const op = "wehook controller: resend"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	return c.webhookMgr.ResendWebhook(ctx, id)
 }
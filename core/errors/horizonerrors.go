@@ -34,6 +34,20 @@ var (
 	ClusterStateInArgo        = sourceType{name: "ClusterStateInArgo"}
 	TagInDB                   = sourceType{name: "TagInDB"}
 	BadgeInDB                 = sourceType{name: "BadgeInDB"}
+	GitTriggerInDB            = sourceType{name: "GitTriggerInDB"}
+	TerminalRecordingInDB     = sourceType{name: "TerminalRecordingInDB"}
+	HibernationInDB           = sourceType{name: "HibernationInDB"}
+	PreviewRuleInDB           = sourceType{name: "PreviewRuleInDB"}
+	PreviewEnvironmentInDB    = sourceType{name: "PreviewEnvironmentInDB"}
+	BatchOperationInDB        = sourceType{name: "BatchOperationInDB"}
+	BatchOperationItemInDB    = sourceType{name: "BatchOperationItemInDB"}
+	RecommendationInDB        = sourceType{name: "RecommendationInDB"}
+	ClusterCostInDB           = sourceType{name: "ClusterCostInDB"}
+	CostBudgetInDB            = sourceType{name: "CostBudgetInDB"}
+	ResourceQuotaInDB         = sourceType{name: "ResourceQuotaInDB"}
+	ClusterResourceUsageInDB  = sourceType{name: "ClusterResourceUsageInDB"}
+	PolicyInDB                = sourceType{name: "PolicyInDB"}
+	DeprecatedAPIInDB         = sourceType{name: "DeprecatedAPIInDB"}
 	ApplicationInArgo         = sourceType{name: "ApplicationInArgo"}
 	ApplicationResourceInArgo = sourceType{name: "ApplicationResourceInArgo"}
 	ApplicationInDB           = sourceType{name: "ApplicationInDB"}
@@ -225,6 +239,10 @@ var (
 	ErrShouldBuildDeployFirst          = errors.New("clusters with build config should build and deploy first")
 	ErrBuildDeployNotSupported         = errors.New("builddeploy is not supported for this cluster")
 	ErrFreedClusterNotSupportedRestart = errors.New("freed cluster is not supported to restart")
+	ErrClusterNotHibernatable          = errors.New("cluster in current status can not be hibernated")
+	ErrQuotaExceeded                   = errors.New("resource quota exceeded")
+	ErrPolicyViolated                  = errors.New("policy violated")
+	ErrAPIRemoved                      = errors.New("api removed")
 
 	// pipelinerun
 
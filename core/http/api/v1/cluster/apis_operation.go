@@ -198,6 +198,11 @@ func (a *API) InternalDeploy(c *gin.Context) {
 				return
 			}
 		}
+		if perror.Cause(err) == herrors.ErrPolicyViolated || perror.Cause(err) == herrors.ErrAPIRemoved {
+			log.WithFiled(c, "op", op).Warningf("%+v", err)
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchoperation
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/batchoperation"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/request"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _paramBatchOperationID = "batchOperationID"
+
+type API struct {
+	batchOperationCtl batchoperation.Controller
+}
+
+func NewAPI(batchOperationCtl batchoperation.Controller) *API {
+	return &API{batchOperationCtl: batchOperationCtl}
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "batch operation: create"
+	var req batchoperation.CreateBatchOperationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	operation, err := a.batchOperationCtl.Create(c, &req)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, operation)
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "batch operation: get"
+	id, err := parseID(c)
+	if err != nil {
+		return
+	}
+	operation, err := a.batchOperationCtl.Get(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, operation)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "batch operation: list"
+	pageNumber, pageSize, err := request.GetPageParam(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	keywords := q.KeyWords{}
+	if status := c.Query(common.BatchOperationQueryByStatus); status != "" {
+		keywords[common.BatchOperationQueryByStatus] = status
+	}
+	operations, total, err := a.batchOperationCtl.List(c, &q.Query{
+		Keywords:   keywords,
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Total: total,
+		Items: operations,
+	})
+}
+
+func (a *API) Cancel(c *gin.Context) {
+	const op = "batch operation: cancel"
+	id, err := parseID(c)
+	if err != nil {
+		return
+	}
+	if err := a.batchOperationCtl.Cancel(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseID(c *gin.Context) (uint, error) {
+	idStr := c.Param(_paramBatchOperationID)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid batch operation id: %s, err: %s", idStr, err.Error())
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(errMsg))
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -257,6 +257,10 @@ func (a *API) Create(c *gin.Context) {
 			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
 			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
 			return
+		} else if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
 		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
@@ -303,6 +307,11 @@ func (a *API) Update(c *gin.Context) {
 			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
 			return
 		}
+		if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
@@ -17,7 +17,9 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -66,6 +68,11 @@ func (a *API) BuildDeploy(c *gin.Context) {
 			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
 			return
 		}
+		if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -280,6 +287,11 @@ func (a *API) InternalDeploy(c *gin.Context) {
 			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
 			return
 		}
+		if perror.Cause(err) == herrors.ErrPolicyViolated || perror.Cause(err) == herrors.ErrAPIRemoved {
+			log.WithFiled(c, "op", op).Warningf("%+v", err)
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -390,6 +402,60 @@ func (a *API) ExecuteAction(c *gin.Context) {
 	response.Success(c)
 }
 
+func (a *API) GetTraffic(c *gin.Context) {
+	const op = "cluster: get traffic"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("invalid cluster id"))
+		return
+	}
+
+	resp, err := a.clusterCtl.GetTraffic(c, uint(clusterID))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ShiftTraffic(c *gin.Context) {
+	const op = "cluster: shift traffic"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("invalid cluster id"))
+		return
+	}
+
+	var request cluster.ShiftTrafficRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	resp, err := a.clusterCtl.ShiftTraffic(c, uint(clusterID), &request)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if e := perror.Cause(err); e == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
 func (a *API) Deploy(c *gin.Context) {
 	op := "cluster: deploy"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -420,6 +486,11 @@ func (a *API) Deploy(c *gin.Context) {
 			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
 			return
 		}
+		if perror.Cause(err) == herrors.ErrQuotaExceeded {
+			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
@@ -469,6 +540,112 @@ func (a *API) GetContainerLog(c *gin.Context) {
 	}
 }
 
+// GetClusterLogs streams the logs of the cluster's pods, or downloads them as a zip archive
+func (a *API) GetClusterLogs(c *gin.Context) {
+	const op = "cluster: get cluster logs"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	request := &cluster.GetClusterLogsRequest{
+		Revision:  c.Query(common.ClusterQueryRevision),
+		Container: c.Query(common.ClusterQueryContainerName),
+		Filter:    c.Query(common.ClusterQueryLogFilter),
+	}
+	if tailLinesStr := c.Query(common.ClusterQueryTailLines); tailLinesStr != "" {
+		tailLines, err := strconv.ParseUint(tailLinesStr, 10, 0)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		}
+		request.TailLines = int64(tailLines)
+	}
+	if sinceTimeStr := c.Query(common.ClusterQuerySinceTime); sinceTimeStr != "" {
+		sinceTime, err := time.Parse(time.RFC3339, sinceTimeStr)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		}
+		request.SinceTime = &sinceTime
+	}
+	var download bool
+	for query, value := range map[string]*bool{
+		common.ClusterQueryPrevious: &request.Previous,
+		common.ClusterQueryFollow:   &request.Follow,
+		common.ClusterQueryDownload: &download,
+	} {
+		if str, ok := c.GetQuery(query); ok {
+			if *value, err = strconv.ParseBool(str); err != nil {
+				response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+				return
+			}
+		}
+	}
+	if request.TailLines == 0 && request.SinceTime == nil && !download {
+		request.TailLines = defaultTailLines
+	}
+
+	// streams are stopped once the client goes away
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	abortWithError := func(err error) {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+	}
+
+	if download {
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition",
+			fmt.Sprintf("attachment; filename=\"cluster-%d-logs.zip\"", clusterID))
+		if err := a.clusterCtl.ArchiveClusterLogs(ctx, uint(clusterID), request, c.Writer); err != nil {
+			if c.Writer.Written() {
+				log.WithFiled(c, "op", op).Errorf("%+v", err)
+				return
+			}
+			c.Writer.Header().Del("Content-Disposition")
+			abortWithError(err)
+		}
+		return
+	}
+
+	logC, err := a.clusterCtl.GetClusterLogs(ctx, uint(clusterID), request)
+	if err != nil {
+		abortWithError(err)
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case l, ok := <-logC:
+			if !ok {
+				return
+			}
+			if _, err := c.Writer.Write([]byte(l)); err != nil {
+				return
+			}
+			if request.Follow {
+				c.Writer.Flush()
+			}
+		}
+	}
+}
+
 func (a *API) Exec(c *gin.Context) {
 	op := "cluster: exec"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -576,3 +753,103 @@ func (a *API) DeleteClusterPods(c *gin.Context) {
 	}
 	response.SuccessWithData(c, resp)
 }
+
+func (a *API) Hibernate(c *gin.Context) {
+	const op = "cluster: hibernate"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	if err := a.clusterCtl.HibernateCluster(c, uint(clusterID)); err != nil {
+		abortWithHibernationError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) Wake(c *gin.Context) {
+	const op = "cluster: wake"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	if err := a.clusterCtl.WakeCluster(c, uint(clusterID)); err != nil {
+		abortWithHibernationError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) GetHibernation(c *gin.Context) {
+	const op = "cluster: get hibernation"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	resp, err := a.clusterCtl.GetHibernation(c, uint(clusterID))
+	if err != nil {
+		abortWithHibernationError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) UpdateHibernation(c *gin.Context) {
+	const op = "cluster: update hibernation"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request cluster.HibernationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+
+	resp, err := a.clusterCtl.UpdateHibernation(c, uint(clusterID), &request)
+	if err != nil {
+		abortWithHibernationError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) DeleteHibernation(c *gin.Context) {
+	const op = "cluster: delete hibernation"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	if err := a.clusterCtl.DeleteHibernation(c, uint(clusterID)); err != nil {
+		abortWithHibernationError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func abortWithHibernationError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrClusterNotHibernatable:
+		response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -91,10 +91,22 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/action", common.ParamClusterID),
 			HandlerFunc: api.ExecuteAction,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/traffic", common.ParamClusterID),
+			HandlerFunc: api.GetTraffic,
+		}, {
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/clusters/:%v/traffic", common.ParamClusterID),
+			HandlerFunc: api.ShiftTraffic,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/containerlog", common.ParamClusterID),
 			HandlerFunc: api.GetContainerLog,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/logs", common.ParamClusterID),
+			HandlerFunc: api.GetClusterLogs,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/exec", common.ParamClusterID),
@@ -115,6 +127,26 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/free", common.ParamClusterID),
 			HandlerFunc: api.Free,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/hibernate", common.ParamClusterID),
+			HandlerFunc: api.Hibernate,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/wake", common.ParamClusterID),
+			HandlerFunc: api.Wake,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/hibernation", common.ParamClusterID),
+			HandlerFunc: api.GetHibernation,
+		}, {
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/clusters/:%v/hibernation", common.ParamClusterID),
+			HandlerFunc: api.UpdateHibernation,
+		}, {
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/clusters/:%v/hibernation", common.ParamClusterID),
+			HandlerFunc: api.DeleteHibernation,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/events", common.ParamClusterID),
@@ -0,0 +1,145 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/clusterconfig"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/request"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_querySourceClusterID = "sourceClusterID"
+	_queryBranch          = "branch"
+	_paramCommit          = "commit"
+)
+
+type API struct {
+	clusterConfigCtl clusterconfig.Controller
+}
+
+func NewAPI(clusterConfigCtl clusterconfig.Controller) *API {
+	return &API{clusterConfigCtl: clusterConfigCtl}
+}
+
+func (a *API) Compare(c *gin.Context) {
+	const op = "cluster config: compare"
+	clusterID, err := parseUint(c, common.ParamClusterID, c.Param(common.ParamClusterID))
+	if err != nil {
+		return
+	}
+	sourceClusterID, err := parseUint(c, _querySourceClusterID, c.Query(_querySourceClusterID))
+	if err != nil {
+		return
+	}
+	resp, err := a.clusterConfigCtl.Compare(c, clusterID, sourceClusterID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Sync(c *gin.Context) {
+	const op = "cluster config: sync"
+	clusterID, err := parseUint(c, common.ParamClusterID, c.Param(common.ParamClusterID))
+	if err != nil {
+		return
+	}
+	var request clusterconfig.SyncRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	if err := a.clusterConfigCtl.Sync(c, clusterID, &request); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) ListCommits(c *gin.Context) {
+	const op = "cluster config: list commits"
+	clusterID, err := parseUint(c, common.ParamClusterID, c.Param(common.ParamClusterID))
+	if err != nil {
+		return
+	}
+	pageNumber, pageSize, err := request.GetPageParam(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	commits, err := a.clusterConfigCtl.ListCommits(c, clusterID, c.Query(_queryBranch), &q.Query{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, commits)
+}
+
+func (a *API) Restore(c *gin.Context) {
+	const op = "cluster config: restore"
+	clusterID, err := parseUint(c, common.ParamClusterID, c.Param(common.ParamClusterID))
+	if err != nil {
+		return
+	}
+	if err := a.clusterConfigCtl.Restore(c, clusterID, c.Param(_paramCommit)); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseUint(c *gin.Context, name, valueStr string) (uint, error) {
+	value, err := strconv.ParseUint(valueStr, 10, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid %s: %s, err: %s", name, valueStr, err.Error())
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(errMsg))
+		return 0, err
+	}
+	return uint(value), nil
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrParamInvalid) {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrForbidden) {
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
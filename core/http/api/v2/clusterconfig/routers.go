@@ -0,0 +1,54 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterconfig
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+// RegisterRoute registers routes
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	apiV2Group := engine.Group("/apis/core/v2")
+	apiV2Routes := route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/configdiff", common.ParamClusterID),
+			HandlerFunc: a.Compare,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/configsync", common.ParamClusterID),
+			HandlerFunc: a.Sync,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/configcommits", common.ParamClusterID),
+			HandlerFunc: a.ListCommits,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/configcommits/:%v/restore", common.ParamClusterID, _paramCommit),
+			HandlerFunc: a.Restore,
+		},
+	}
+
+	route.RegisterRoutes(apiV2Group, apiV2Routes)
+}
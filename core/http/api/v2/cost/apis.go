@@ -0,0 +1,259 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/cost"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_queryTagKey = "tagKey"
+	// _queryFormat is csv to export the costs, which are by the date, or by the cluster if _queryBy is cluster
+	_queryFormat = "format"
+	_queryBy     = "by"
+
+	_formatCSV = "csv"
+	_byCluster = "cluster"
+)
+
+type API struct {
+	costCtl cost.Controller
+}
+
+func NewAPI(costCtl cost.Controller) *API {
+	return &API{costCtl: costCtl}
+}
+
+func (a *API) GetGroupCosts(c *gin.Context) {
+	const op = "cost: get group costs"
+	groupID, err := parseUint(c, common.ParamGroupID, c.Param(common.ParamGroupID))
+	if err != nil {
+		return
+	}
+	request, err := bindCostRequest(c)
+	if err != nil {
+		return
+	}
+	resp, err := a.costCtl.GetGroupCosts(c, groupID, request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	writeCosts(c, op, fmt.Sprintf("group-%d", groupID), resp)
+}
+
+func (a *API) GetApplicationCosts(c *gin.Context) {
+	const op = "cost: get application costs"
+	applicationID, err := parseUint(c, common.ParamApplicationID, c.Param(common.ParamApplicationID))
+	if err != nil {
+		return
+	}
+	request, err := bindCostRequest(c)
+	if err != nil {
+		return
+	}
+	resp, err := a.costCtl.GetApplicationCosts(c, applicationID, request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	writeCosts(c, op, fmt.Sprintf("application-%d", applicationID), resp)
+}
+
+func (a *API) GetClusterCosts(c *gin.Context) {
+	const op = "cost: get cluster costs"
+	clusterID, err := parseUint(c, common.ParamClusterID, c.Param(common.ParamClusterID))
+	if err != nil {
+		return
+	}
+	request, err := bindCostRequest(c)
+	if err != nil {
+		return
+	}
+	resp, err := a.costCtl.GetClusterCosts(c, clusterID, request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	writeCosts(c, op, fmt.Sprintf("cluster-%d", clusterID), resp)
+}
+
+func (a *API) GetTagCosts(c *gin.Context) {
+	const op = "cost: get tag costs"
+	request, err := bindCostRequest(c)
+	if err != nil {
+		return
+	}
+	resp, err := a.costCtl.GetTagCosts(c, c.Query(_queryTagKey), request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	if c.Query(_queryFormat) != _formatCSV {
+		response.SuccessWithData(c, resp)
+		return
+	}
+	records := [][]string{{"tagValue", "cpuHours", "memoryHours", "gpuHours", "cost"}}
+	for _, tagCost := range resp.Costs {
+		records = append(records, append([]string{tagCost.TagValue}, usageRecord(&tagCost.Usage)...))
+	}
+	writeCSV(c, op, fmt.Sprintf("tag-%s-%s-%s.csv", resp.TagKey, resp.From, resp.To), records)
+}
+
+func (a *API) GetGroupBudget(c *gin.Context) {
+	a.getBudget(c, common.ResourceGroup, common.ParamGroupID)
+}
+
+func (a *API) SetGroupBudget(c *gin.Context) {
+	a.setBudget(c, common.ResourceGroup, common.ParamGroupID)
+}
+
+func (a *API) GetApplicationBudget(c *gin.Context) {
+	a.getBudget(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) SetApplicationBudget(c *gin.Context) {
+	a.setBudget(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) GetClusterBudget(c *gin.Context) {
+	a.getBudget(c, common.ResourceCluster, common.ParamClusterID)
+}
+
+func (a *API) SetClusterBudget(c *gin.Context) {
+	a.setBudget(c, common.ResourceCluster, common.ParamClusterID)
+}
+
+func (a *API) getBudget(c *gin.Context, resourceType, param string) {
+	const op = "cost: get budget"
+	resourceID, err := parseUint(c, param, c.Param(param))
+	if err != nil {
+		return
+	}
+	resp, err := a.costCtl.GetBudget(c, resourceType, resourceID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) setBudget(c *gin.Context, resourceType, param string) {
+	const op = "cost: set budget"
+	resourceID, err := parseUint(c, param, c.Param(param))
+	if err != nil {
+		return
+	}
+	var request cost.SetBudgetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	resp, err := a.costCtl.SetBudget(c, resourceType, resourceID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func bindCostRequest(c *gin.Context) (*cost.CostRequest, error) {
+	var request cost.CostRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid query, err: %s", err.Error()))
+		return nil, err
+	}
+	return &request, nil
+}
+
+// writeCosts responds the costs in json, or in csv by the date or the cluster if it's required
+func writeCosts(c *gin.Context, op, name string, costs *cost.Costs) {
+	if c.Query(_queryFormat) != _formatCSV {
+		response.SuccessWithData(c, costs)
+		return
+	}
+	var records [][]string
+	if c.Query(_queryBy) == _byCluster {
+		records = [][]string{{"clusterID", "clusterName", "cpuHours", "memoryHours", "gpuHours", "cost"}}
+		for _, clusterCost := range costs.Clusters {
+			records = append(records, append([]string{strconv.FormatUint(uint64(clusterCost.ClusterID), 10),
+				clusterCost.ClusterName}, usageRecord(&clusterCost.Usage)...))
+		}
+	} else {
+		records = [][]string{{"date", "cpuHours", "memoryHours", "gpuHours", "cost"}}
+		for _, dailyCost := range costs.Daily {
+			records = append(records, append([]string{dailyCost.Date}, usageRecord(&dailyCost.Usage)...))
+		}
+	}
+	writeCSV(c, op, fmt.Sprintf("%s-%s-%s.csv", name, costs.From, costs.To), records)
+}
+
+func usageRecord(usage *cost.Usage) []string {
+	format := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', 4, 64)
+	}
+	return []string{format(usage.CPUHours), format(usage.MemoryHours), format(usage.GPUHours), format(usage.Cost)}
+}
+
+func writeCSV(c *gin.Context, op, filename string, records [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.WriteAll(records); err != nil {
+		log.WithFiled(c, "op", op).Errorf("failed to write csv, err: %v", err)
+	}
+}
+
+func parseUint(c *gin.Context, name, valueStr string) (uint, error) {
+	value, err := strconv.ParseUint(valueStr, 10, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid %s: %s, err: %s", name, valueStr, err.Error())
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(errMsg))
+		return 0, err
+	}
+	return uint(value), nil
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrParamInvalid) {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrForbidden) {
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
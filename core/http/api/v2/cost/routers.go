@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+// RegisterRoute registers routes
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	apiV2Group := engine.Group("/apis/core/v2")
+	apiV2Routes := route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/groups/:%v/costs", common.ParamGroupID),
+			HandlerFunc: a.GetGroupCosts,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/costs", common.ParamApplicationID),
+			HandlerFunc: a.GetApplicationCosts,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/costs", common.ParamClusterID),
+			HandlerFunc: a.GetClusterCosts,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/costs",
+			HandlerFunc: a.GetTagCosts,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/groups/:%v/costbudget", common.ParamGroupID),
+			HandlerFunc: a.GetGroupBudget,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/groups/:%v/costbudget", common.ParamGroupID),
+			HandlerFunc: a.SetGroupBudget,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/costbudget", common.ParamApplicationID),
+			HandlerFunc: a.GetApplicationBudget,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/applications/:%v/costbudget", common.ParamApplicationID),
+			HandlerFunc: a.SetApplicationBudget,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/costbudget", common.ParamClusterID),
+			HandlerFunc: a.GetClusterBudget,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/clusters/:%v/costbudget", common.ParamClusterID),
+			HandlerFunc: a.SetClusterBudget,
+		},
+	}
+
+	route.RegisterRoutes(apiV2Group, apiV2Routes)
+}
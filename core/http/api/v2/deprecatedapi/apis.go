@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deprecatedapi
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/controller/deprecatedapi"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	deprecatedAPICtl deprecatedapi.Controller
+}
+
+func NewAPI(deprecatedAPICtl deprecatedapi.Controller) *API {
+	return &API{deprecatedAPICtl: deprecatedAPICtl}
+}
+
+func (a *API) Report(c *gin.Context) {
+	const op = "deprecated api: report"
+	resp, err := a.deprecatedAPICtl.Report(c)
+	if err != nil {
+		if errors.Is(perror.Cause(err), herrors.ErrForbidden) {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
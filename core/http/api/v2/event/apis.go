@@ -15,10 +15,37 @@
 package event
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/event"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
 
-	"github.com/gin-gonic/gin"
+const (
+	_cursorQuery = "cursor"
+	_limitQuery  = "limit"
+	_waitQuery   = "wait"
+
+	_headerLastEventID = "Last-Event-ID"
+
+	// _pollInterval is the interval to check new events
+	_pollInterval = 2 * time.Second
+	// _heartbeatInterval is the interval to send comments to keep the stream alive
+	_heartbeatInterval = 15 * time.Second
+	// _maxWait is the max seconds that long polling waits for new events
+	_maxWait = 60
 )
 
 type API struct {
@@ -36,3 +63,162 @@ func NewAPI(controller event.Controller) *API {
 func (a *API) ListSupportEvents(c *gin.Context) {
 	response.SuccessWithData(c, a.eventCtl.ListSupportEvents(c))
 }
+
+// ListEvents lists events after the cursor, if there are no events,
+// it waits for new events at most wait seconds
+func (a *API) ListEvents(c *gin.Context) {
+	const op = "event: list events"
+	req, err := parseListEventsRequest(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	wait := 0
+	if waitStr := c.Query(_waitQuery); waitStr != "" {
+		wait, err = strconv.Atoi(waitStr)
+		if err != nil || wait < 0 {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid wait: %s", waitStr))
+			return
+		}
+		if wait > _maxWait {
+			wait = _maxWait
+		}
+	}
+
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+	for {
+		resp, err := a.eventCtl.ListEvents(c, req)
+		if err != nil {
+			abortWithError(c, op, err)
+			return
+		}
+		if len(resp.Events) > 0 || !time.Now().Add(_pollInterval).Before(deadline) {
+			response.SuccessWithData(c, resp)
+			return
+		}
+		req.Cursor = &resp.Cursor
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-time.After(_pollInterval):
+		}
+	}
+}
+
+// StreamEvents streams events after the cursor as server-sent events,
+// the stream can be resumed by the Last-Event-ID header
+func (a *API) StreamEvents(c *gin.Context) {
+	const op = "event: stream events"
+	req, err := parseListEventsRequest(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if lastEventID := c.GetHeader(_headerLastEventID); lastEventID != "" {
+		cursor, err := strconv.ParseUint(lastEventID, 10, 0)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid %s: %s", _headerLastEventID, lastEventID))
+			return
+		}
+		id := uint(cursor)
+		req.Cursor = &id
+	}
+
+	// list once before streaming, so that invalid requests are responded as usual
+	resp, err := a.eventCtl.ListEvents(c, req)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	poll := time.NewTicker(_pollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(_heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		for _, e := range resp.Events {
+			data, err := json.Marshal(e)
+			if err != nil {
+				log.WithFiled(c, "op", op).Errorf("failed to marshal event %d: %v", e.ID, err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.EventType, data)
+		}
+		req.Cursor = &resp.Cursor
+		c.Writer.Flush()
+
+	W:
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(c.Writer, ": heartbeat\n\n")
+				c.Writer.Flush()
+			case <-poll.C:
+				break W
+			}
+		}
+
+		resp, err = a.eventCtl.ListEvents(c, req)
+		if err != nil {
+			log.WithFiled(c, "op", op).Errorf("%+v", err)
+			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", strings.ReplaceAll(err.Error(), "\n", " "))
+			c.Writer.Flush()
+			return
+		}
+	}
+}
+
+func parseListEventsRequest(c *gin.Context) (*event.ListEventsRequest, error) {
+	req := &event.ListEventsRequest{
+		ResourceType: c.Query(common.ParamResourceType),
+	}
+	if cursorStr := c.Query(_cursorQuery); cursorStr != "" {
+		cursor, err := strconv.ParseUint(cursorStr, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", cursorStr)
+		}
+		id := uint(cursor)
+		req.Cursor = &id
+	}
+	if resourceIDStr := c.Query(common.ParamResourceID); resourceIDStr != "" {
+		resourceID, err := strconv.ParseUint(resourceIDStr, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resourceID: %s", resourceIDStr)
+		}
+		req.ResourceID = uint(resourceID)
+	}
+	if limitStr := c.Query(_limitQuery); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit: %s", limitStr)
+		}
+		req.Limit = limit
+	}
+	// event types can be repeated or joined by comma
+	for _, eventTypes := range c.QueryArray(common.EventType) {
+		for _, eventType := range strings.Split(eventTypes, ",") {
+			if eventType != "" {
+				req.EventTypes = append(req.EventTypes, eventType)
+			}
+		}
+	}
+	return req, nil
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
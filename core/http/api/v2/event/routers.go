@@ -30,6 +30,14 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     "/supportevents",
 			Method:      http.MethodGet,
 			HandlerFunc: a.ListSupportEvents,
+		}, {
+			Pattern:     "/events",
+			Method:      http.MethodGet,
+			HandlerFunc: a.ListEvents,
+		}, {
+			Pattern:     "/events/stream",
+			Method:      http.MethodGet,
+			HandlerFunc: a.StreamEvents,
 		},
 	}
 
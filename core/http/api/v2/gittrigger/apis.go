@@ -0,0 +1,166 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittrigger
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/gittrigger"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_paramTriggerID = "triggerID"
+	_paramProvider  = "provider"
+)
+
+type API struct {
+	gitTriggerCtl gittrigger.Controller
+}
+
+func NewAPI(gitTriggerCtl gittrigger.Controller) *API {
+	return &API{gitTriggerCtl: gitTriggerCtl}
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "git trigger: create"
+	clusterID, err := parseUintParam(c, common.ParamClusterID)
+	if err != nil {
+		return
+	}
+	var request gittrigger.CreateGitTriggerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	trigger, err := a.gitTriggerCtl.CreateGitTrigger(c, clusterID, &request)
+	if err != nil {
+		if errors.Is(perror.Cause(err), herrors.ErrBuildDeployNotSupported) {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, trigger)
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "git trigger: update"
+	clusterID, err := parseUintParam(c, common.ParamClusterID)
+	if err != nil {
+		return
+	}
+	triggerID, err := parseUintParam(c, _paramTriggerID)
+	if err != nil {
+		return
+	}
+	var request gittrigger.UpdateGitTriggerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	trigger, err := a.gitTriggerCtl.UpdateGitTrigger(c, clusterID, triggerID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, trigger)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "git trigger: list"
+	clusterID, err := parseUintParam(c, common.ParamClusterID)
+	if err != nil {
+		return
+	}
+	triggers, err := a.gitTriggerCtl.ListGitTriggers(c, clusterID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, triggers)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "git trigger: delete"
+	clusterID, err := parseUintParam(c, common.ParamClusterID)
+	if err != nil {
+		return
+	}
+	triggerID, err := parseUintParam(c, _paramTriggerID)
+	if err != nil {
+		return
+	}
+	if err := a.gitTriggerCtl.DeleteGitTrigger(c, clusterID, triggerID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Receive receives webhooks from git providers
+func (a *API) Receive(c *gin.Context) {
+	const op = "git trigger: receive"
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("failed to read body, err: %s", err.Error()))
+		return
+	}
+	resp, err := a.gitTriggerCtl.Receive(c, c.Param(_paramProvider), c.Request.Header, body)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func parseUintParam(c *gin.Context, name string) (uint, error) {
+	valueStr := c.Param(name)
+	value, err := strconv.ParseUint(valueStr, 10, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid %s: %s, err: %s", name, valueStr, err.Error())
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(errMsg))
+		return 0, err
+	}
+	return uint(value), nil
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -181,6 +181,10 @@ func (a *API) Execute(c *gin.Context) {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(e.Error()))
 				return
 			}
+			if perror.Cause(err) == herrors.ErrQuotaExceeded {
+				response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+				return
+			}
 			response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 			return
 		}
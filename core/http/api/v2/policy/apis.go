@@ -0,0 +1,143 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/controller/policy"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _paramPolicyID = "policyID"
+
+type API struct {
+	policyCtl policy.Controller
+}
+
+func NewAPI(policyCtl policy.Controller) *API {
+	return &API{policyCtl: policyCtl}
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "policy: list"
+	resp, err := a.policyCtl.List(c)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "policy: get"
+	policyID, err := parsePolicyID(c)
+	if err != nil {
+		return
+	}
+	resp, err := a.policyCtl.Get(c, policyID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "policy: create"
+	var request policy.PolicyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	resp, err := a.policyCtl.Create(c, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "policy: update"
+	policyID, err := parsePolicyID(c)
+	if err != nil {
+		return
+	}
+	var request policy.PolicyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	resp, err := a.policyCtl.Update(c, policyID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "policy: delete"
+	policyID, err := parsePolicyID(c)
+	if err != nil {
+		return
+	}
+	if err := a.policyCtl.Delete(c, policyID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parsePolicyID(c *gin.Context) (uint, error) {
+	policyIDStr := c.Param(_paramPolicyID)
+	policyID, err := strconv.ParseUint(policyIDStr, 10, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid %s: %s, err: %s", _paramPolicyID, policyIDStr, err.Error())
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(errMsg))
+		return 0, err
+	}
+	return uint(policyID), nil
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrParamInvalid) {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrNameConflict) {
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrForbidden) {
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/preview"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _paramRuleID = "ruleID"
+
+type API struct {
+	previewCtl preview.Controller
+}
+
+func NewAPI(previewCtl preview.Controller) *API {
+	return &API{previewCtl: previewCtl}
+}
+
+func (a *API) CreateRule(c *gin.Context) {
+	const op = "preview: create rule"
+	applicationID, err := parseUintParam(c, common.ParamApplicationID)
+	if err != nil {
+		return
+	}
+	var request preview.CreateRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	rule, err := a.previewCtl.CreateRule(c, applicationID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, rule)
+}
+
+func (a *API) UpdateRule(c *gin.Context) {
+	const op = "preview: update rule"
+	applicationID, err := parseUintParam(c, common.ParamApplicationID)
+	if err != nil {
+		return
+	}
+	ruleID, err := parseUintParam(c, _paramRuleID)
+	if err != nil {
+		return
+	}
+	var request preview.UpdateRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	rule, err := a.previewCtl.UpdateRule(c, applicationID, ruleID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, rule)
+}
+
+func (a *API) ListRules(c *gin.Context) {
+	const op = "preview: list rules"
+	applicationID, err := parseUintParam(c, common.ParamApplicationID)
+	if err != nil {
+		return
+	}
+	rules, err := a.previewCtl.ListRules(c, applicationID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, rules)
+}
+
+func (a *API) DeleteRule(c *gin.Context) {
+	const op = "preview: delete rule"
+	applicationID, err := parseUintParam(c, common.ParamApplicationID)
+	if err != nil {
+		return
+	}
+	ruleID, err := parseUintParam(c, _paramRuleID)
+	if err != nil {
+		return
+	}
+	if err := a.previewCtl.DeleteRule(c, applicationID, ruleID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) ListEnvironments(c *gin.Context) {
+	const op = "preview: list environments"
+	applicationID, err := parseUintParam(c, common.ParamApplicationID)
+	if err != nil {
+		return
+	}
+	envs, err := a.previewCtl.ListEnvironments(c, applicationID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, envs)
+}
+
+func parseUintParam(c *gin.Context, name string) (uint, error) {
+	valueStr := c.Param(name)
+	value, err := strconv.ParseUint(valueStr, 10, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid %s: %s, err: %s", name, valueStr, err.Error())
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(errMsg))
+		return 0, err
+	}
+	return uint(value), nil
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrBuildDeployNotSupported) ||
+		errors.Is(perror.Cause(err), herrors.ErrParamInvalid) {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
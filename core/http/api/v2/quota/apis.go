@@ -0,0 +1,148 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/quota"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _paramEnvironment = "environment"
+
+type API struct {
+	quotaCtl quota.Controller
+}
+
+func NewAPI(quotaCtl quota.Controller) *API {
+	return &API{quotaCtl: quotaCtl}
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "quota: list"
+	resp, err := a.quotaCtl.List(c)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListGroupQuotas(c *gin.Context) {
+	a.listByResource(c, common.ResourceGroup, common.ParamGroupID)
+}
+
+func (a *API) SetGroupQuota(c *gin.Context) {
+	a.set(c, common.ResourceGroup, common.ParamGroupID)
+}
+
+func (a *API) DeleteGroupQuota(c *gin.Context) {
+	a.delete(c, common.ResourceGroup, common.ParamGroupID)
+}
+
+func (a *API) ListApplicationQuotas(c *gin.Context) {
+	a.listByResource(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) SetApplicationQuota(c *gin.Context) {
+	a.set(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) DeleteApplicationQuota(c *gin.Context) {
+	a.delete(c, common.ResourceApplication, common.ParamApplicationID)
+}
+
+func (a *API) listByResource(c *gin.Context, resourceType, param string) {
+	const op = "quota: list by resource"
+	resourceID, err := parseUint(c, param, c.Param(param))
+	if err != nil {
+		return
+	}
+	resp, err := a.quotaCtl.ListByResource(c, resourceType, resourceID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) set(c *gin.Context, resourceType, param string) {
+	const op = "quota: set"
+	resourceID, err := parseUint(c, param, c.Param(param))
+	if err != nil {
+		return
+	}
+	var request quota.SetQuotaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+	resp, err := a.quotaCtl.Set(c, resourceType, resourceID, c.Param(_paramEnvironment), &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) delete(c *gin.Context, resourceType, param string) {
+	const op = "quota: delete"
+	resourceID, err := parseUint(c, param, c.Param(param))
+	if err != nil {
+		return
+	}
+	if err := a.quotaCtl.Delete(c, resourceType, resourceID, c.Param(_paramEnvironment)); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseUint(c *gin.Context, name, valueStr string) (uint, error) {
+	value, err := strconv.ParseUint(valueStr, 10, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid %s: %s, err: %s", name, valueStr, err.Error())
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(errMsg))
+		return 0, err
+	}
+	return uint(value), nil
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrParamInvalid) {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	if errors.Is(perror.Cause(err), herrors.ErrForbidden) {
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
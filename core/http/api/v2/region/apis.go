@@ -31,7 +31,8 @@ import (
 
 const (
 	// param
-	_regionIDParam = "id"
+	_regionIDParam    = "id"
+	_environmentParam = "environment"
 )
 
 type API struct {
@@ -171,3 +172,63 @@ func (a *API) ListRegionTags(c *gin.Context) {
 	}
 	response.SuccessWithData(c, resp)
 }
+
+func (a *API) ListCDMappings(c *gin.Context) {
+	regionIDStr := c.Param(_regionIDParam)
+	regionID, err := strconv.ParseUint(regionIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid regionID: %s, err: %s",
+			regionIDStr, err.Error())))
+		return
+	}
+
+	mappings, err := a.regionCtl.ListCDMappings(c, uint(regionID))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+
+	response.SuccessWithData(c, mappings)
+}
+
+func (a *API) UpdateCDMapping(c *gin.Context) {
+	regionIDStr := c.Param(_regionIDParam)
+	regionID, err := strconv.ParseUint(regionIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid regionID: %s, err: %s",
+			regionIDStr, err.Error())))
+		return
+	}
+	environment := c.Param(_environmentParam)
+
+	var request *region.UpdateCDMappingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+	if request == nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("request body is required"))
+		return
+	}
+
+	err = a.regionCtl.UpdateCDMapping(c, uint(regionID), environment, request)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+
+	response.Success(c)
+}
@@ -41,6 +41,14 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/:%v/tags", _regionIDParam),
 			HandlerFunc: api.ListRegionTags,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/:%v/cdmappings", _regionIDParam),
+			HandlerFunc: api.ListCDMappings,
+		}, {
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/:%v/cdmappings/:%v", _regionIDParam, _environmentParam),
+			HandlerFunc: api.UpdateCDMapping,
 		}, {
 			Method:      http.MethodPut,
 			Pattern:     fmt.Sprintf("/:%v", _regionIDParam),
@@ -307,6 +307,10 @@ func (a *API) CreateTemplate(c *gin.Context) {
 	if err != nil {
 		defer func() { _ = a.templateCtl.DeleteTemplate(c, template.ID) }()
 
+		if response.AbortIfDataError(c, rpcerror.ParamError, err) {
+			return
+		}
+
 		if perror.Cause(err) == herrors.ErrParamInvalid {
 			log.WithFiled(c, "op", op).Infof("could not parse gitlab url: %s", err)
 			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("failed parsing gitlab URL: %s", err)))
@@ -457,6 +461,9 @@ func (a *API) CreateRelease(c *gin.Context) {
 
 	var release *templatectl.Release
 	if release, err = a.templateCtl.CreateRelease(c, uint(templateID), createRequest); err != nil {
+		if response.AbortIfDataError(c, rpcerror.ParamError, err) {
+			return
+		}
 		if perror.Cause(err) == herrors.ErrParamInvalid {
 			log.WithFiled(c, "op", op).Infof("could not parse gitlab url: %s", err)
 			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("failed parsing gitlab URL: %s", err)))
@@ -626,6 +633,9 @@ func (a *API) SyncReleaseToRepo(c *gin.Context) {
 	}
 
 	if err = a.templateCtl.SyncReleaseToRepo(c, uint(releaseID)); err != nil {
+		if response.AbortIfDataError(c, rpcerror.ParamError, err) {
+			return
+		}
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			log.WithFiled(c, "op", op).Infof("release with ID %d not found", releaseID)
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(fmt.Sprintf("not found: %s", err)))
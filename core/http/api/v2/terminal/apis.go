@@ -16,12 +16,18 @@ package terminal
 
 import (
 	"fmt"
+	"net/http"
+	"path"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/terminal"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/request"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -29,6 +35,11 @@ import (
 
 const (
 	_clusterIDParam     = "clusterID"
+	_recordingIDParam   = "recordingID"
+	_imageQuery         = "image"
+	_pathQuery          = "path"
+	_dirQuery           = "dir"
+	_fileForm           = "file"
 	_podNameQuery       = "podName"
 	_containerNameQuery = "containerName"
 )
@@ -78,3 +89,227 @@ func (a *API) CreateShell(c *gin.Context) {
 	c.Request.URL.Path = fmt.Sprintf("/apis/core/v2/0/%s/websocket", sessionID)
 	sockJS.ServeHTTP(c.Writer, c.Request)
 }
+
+func (a *API) ListRecordings(c *gin.Context) {
+	const op = "terminal: list recordings"
+	clusterIDStr := c.Param(_clusterIDParam)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", clusterIDStr, err.Error())))
+		return
+	}
+	query, err := parseRecordingQuery(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	recordings, total, err := a.terminalCtl.ListRecordings(c, uint(clusterID), query)
+	if err != nil {
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Total: total,
+		Items: recordings,
+	})
+}
+
+func (a *API) ListAllRecordings(c *gin.Context) {
+	const op = "terminal: list all recordings"
+	query, err := parseRecordingQuery(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	recordings, total, err := a.terminalCtl.ListAllRecordings(c, query)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Total: total,
+		Items: recordings,
+	})
+}
+
+// GetRecordingContent returns the asciicast content of the recording for playback
+func (a *API) GetRecordingContent(c *gin.Context) {
+	const op = "terminal: get recording content"
+	clusterIDStr := c.Param(_clusterIDParam)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", clusterIDStr, err.Error())))
+		return
+	}
+	recordingIDStr := c.Param(_recordingIDParam)
+	recordingID, err := strconv.ParseUint(recordingIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid recording id: %s, "+
+			"err: %s", recordingIDStr, err.Error())))
+		return
+	}
+
+	content, err := a.terminalCtl.GetRecordingContent(c, uint(clusterID), uint(recordingID))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	c.Data(http.StatusOK, "application/x-asciicast", content)
+}
+
+func parseRecordingQuery(c *gin.Context) (*q.Query, error) {
+	pageNumber, pageSize, err := request.GetPageParam(c)
+	if err != nil {
+		return nil, err
+	}
+	keywords := q.KeyWords{}
+	if userIDStr := c.Query(common.TerminalRecordingQueryByUser); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id: %s", userIDStr)
+		}
+		keywords[common.TerminalRecordingQueryByUser] = uint(userID)
+	}
+	if podName := c.Query(common.TerminalRecordingQueryByPod); podName != "" {
+		keywords[common.TerminalRecordingQueryByPod] = podName
+	}
+	for _, key := range []string{common.TerminalRecordingQueryStartedAfter,
+		common.TerminalRecordingQueryStartedBefore} {
+		if value := c.Query(key); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %s, it should be in RFC3339 format", key, value)
+			}
+			keywords[key] = t
+		}
+	}
+	return &q.Query{
+		Keywords:   keywords,
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// CreateDebugShell injects an ephemeral debug container into the pod and returns a shell in it
+func (a *API) CreateDebugShell(c *gin.Context) {
+	const op = "terminal: create debug shell"
+	clusterID, err := strconv.ParseUint(c.Param(_clusterIDParam), 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", c.Param(_clusterIDParam), err.Error())))
+		return
+	}
+	podName := c.Query(_podNameQuery)
+	containerName := c.Query(_containerNameQuery)
+	image := c.Query(_imageQuery)
+
+	sessionID, sockJS, err := a.terminalCtl.CreateDebugShell(c, uint(clusterID), podName, containerName, image)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+
+	// the same as CreateShell, modify the URL to adapt to the sock JS protocol
+	c.Request.URL.Path = fmt.Sprintf("/apis/core/v2/0/%s/websocket", sessionID)
+	sockJS.ServeHTTP(c.Writer, c.Request)
+}
+
+// DownloadFile streams the file or directory in the container as a tar archive
+func (a *API) DownloadFile(c *gin.Context) {
+	const op = "terminal: download file"
+	clusterID, err := strconv.ParseUint(c.Param(_clusterIDParam), 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", c.Param(_clusterIDParam), err.Error())))
+		return
+	}
+	podName := c.Query(_podNameQuery)
+	containerName := c.Query(_containerNameQuery)
+	filePath := c.Query(_pathQuery)
+
+	c.Header("Content-Type", "application/x-tar")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(filePath)+".tar"))
+	err = a.terminalCtl.DownloadFile(c, uint(clusterID), podName, containerName, filePath, c.Writer)
+	if err != nil {
+		if c.Writer.Written() {
+			// the archive is partially sent, the error can only be logged
+			log.WithFiled(c, "op", op).Errorf("%+v", err)
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		abortWithError(c, op, err)
+	}
+}
+
+// UploadFile uploads the file in the multipart form to the directory of the container
+func (a *API) UploadFile(c *gin.Context) {
+	const op = "terminal: upload file"
+	clusterID, err := strconv.ParseUint(c.Param(_clusterIDParam), 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", c.Param(_clusterIDParam), err.Error())))
+		return
+	}
+	podName := c.Query(_podNameQuery)
+	containerName := c.Query(_containerNameQuery)
+	dir := c.Query(_dirQuery)
+
+	fileHeader, err := c.FormFile(_fileForm)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid file: %s", err.Error())))
+		return
+	}
+	content, err := fileHeader.Open()
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	defer func() { _ = content.Close() }()
+
+	err = a.terminalCtl.UploadFile(c, uint(clusterID), podName, containerName, dir, &terminal.File{
+		Name:    fileHeader.Filename,
+		Size:    fileHeader.Size,
+		Content: content,
+	})
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
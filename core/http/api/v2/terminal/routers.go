@@ -32,6 +32,37 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/clusters/:%v/shell", _clusterIDParam),
 			HandlerFunc: api.CreateShell,
 		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/debugshell", _clusterIDParam),
+			HandlerFunc: api.CreateDebugShell,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/files", _clusterIDParam),
+			HandlerFunc: api.DownloadFile,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/files", _clusterIDParam),
+			HandlerFunc: api.UploadFile,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalrecordings", _clusterIDParam),
+			HandlerFunc: api.ListRecordings,
+		},
+		{
+			Method: http.MethodGet,
+			Pattern: fmt.Sprintf("/clusters/:%v/terminalrecordings/:%v/cast",
+				_clusterIDParam, _recordingIDParam),
+			HandlerFunc: api.GetRecordingContent,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/terminalrecordings",
+			HandlerFunc: api.ListAllRecordings,
+		},
 	}
 	route.RegisterRoutes(coreGroup, coreRoutes)
 }
@@ -31,7 +31,10 @@ import (
 	_ "github.com/horizoncd/horizon/pkg/templaterepo/chartmuseumbase"
 
 	// for k8s workload
+	_ "github.com/horizoncd/horizon/pkg/workload/cronjob"
+	_ "github.com/horizoncd/horizon/pkg/workload/daemonset"
 	_ "github.com/horizoncd/horizon/pkg/workload/deployment"
+	_ "github.com/horizoncd/horizon/pkg/workload/job"
 	_ "github.com/horizoncd/horizon/pkg/workload/kservice"
 	_ "github.com/horizoncd/horizon/pkg/workload/pod"
 	_ "github.com/horizoncd/horizon/pkg/workload/rollout"
@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	middleware "github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/util/trace"
+)
+
+const _serverName = "horizon"
+
+// Middleware starts a span for each request, and stores it in context so that
+// spans started by controllers, DAOs and clients are its children
+func Middleware(skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(),
+			propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		attrs := semconv.HTTPServerAttributesFromHTTPRequest(_serverName, route, c.Request)
+		if rid, ok := c.Value(requestid.HeaderXRequestID).(string); ok && rid != "" {
+			attrs = append(attrs, trace.AttributeRequestID.String(rid))
+		}
+		ctx, span := trace.Tracer().Start(ctx, fmt.Sprintf("%s %s", c.Request.Method, route),
+			oteltrace.WithSpanKind(oteltrace.SpanKindServer), oteltrace.WithAttributes(attrs...))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(trace.SpanKey(), span)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}, skippers...)
+}
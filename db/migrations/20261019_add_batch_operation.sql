@@ -0,0 +1,50 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- batch operations execute an action on many clusters
+CREATE TABLE `tb_batch_operation`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `action`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'restart, deploy, rollback or free',
+    `selector`        text COMMENT 'json of the selector that clusters are selected by',
+    `concurrency`     int(11) unsigned    NOT NULL DEFAULT '1' COMMENT 'count of clusters operated at the same time',
+    `stop_on_failure` tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'stop the remaining clusters once an action fails',
+    `status`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'pending, running, succeeded, failed or cancelled',
+    `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator, actions are executed as the creator',
+    PRIMARY KEY (`id`),
+    KEY `idx_created_by` (`created_by`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_batch_operation_item`
+(
+    `id`                 bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `batch_operation_id` bigint(20) unsigned NOT NULL COMMENT 'batch operation id',
+    `cluster_id`         bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `cluster_name`       varchar(64)         NOT NULL DEFAULT '' COMMENT 'cluster name',
+    `status`             varchar(64)         NOT NULL DEFAULT '' COMMENT 'pending, running, succeeded, failed or cancelled',
+    `pipelinerun_id`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'pipelinerun created by the action',
+    `message`            varchar(2048)       NOT NULL DEFAULT '' COMMENT 'error message of the action',
+    `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`         bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_batch_operation_id` (`batch_operation_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
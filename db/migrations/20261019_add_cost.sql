@@ -0,0 +1,71 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+CREATE TABLE `tb_cluster_cost`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`       bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `application_id`   bigint(20) unsigned NOT NULL COMMENT 'application id of the cluster',
+    `region_name`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'region of the cluster',
+    `environment_name` varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of the cluster',
+    `date`             varchar(16)         NOT NULL COMMENT 'date of the cost, formatted as 2006-01-02',
+    `cpu_hours`        double              NOT NULL DEFAULT '0' COMMENT 'requested vCPU hours',
+    `memory_hours`     double              NOT NULL DEFAULT '0' COMMENT 'requested memory GiB hours',
+    `gpu_hours`        double              NOT NULL DEFAULT '0' COMMENT 'requested GPU hours',
+    `cost`             double              NOT NULL DEFAULT '0' COMMENT 'cost by the prices of the region',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id_date_deleted_ts` (`cluster_id`, `date`, `deleted_ts`),
+    KEY `idx_date` (`date`),
+    KEY `idx_application_id` (`application_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_cost_budget`
+(
+    `id`                 bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type`      varchar(64)         NOT NULL COMMENT 'groups, applications or clusters',
+    `resource_id`        bigint(20) unsigned NOT NULL COMMENT 'id of the group, the application or the cluster',
+    `amount`             double              NOT NULL DEFAULT '0' COMMENT 'monthly budget',
+    `thresholds`         varchar(256)        NOT NULL DEFAULT '' COMMENT 'comma separated percentages of the amount to notify',
+    `notified_month`     varchar(16)         NOT NULL DEFAULT '' COMMENT 'month of the last notification, formatted as 2006-01',
+    `notified_threshold` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'highest threshold notified in the month',
+    `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`         bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`         bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`         bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_resource_deleted_ts` (`resource_type`, `resource_id`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_cost_accounting`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `region_name`  varchar(128)        NOT NULL COMMENT 'region accounted',
+    `accounted_at` datetime            NOT NULL COMMENT 'time the cost of the region is accumulated to',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`   bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_region_name_deleted_ts` (`region_name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,36 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE `tb_deprecated_api`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL COMMENT 'clusters or templatereleases',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the cluster or the template release',
+    `region`        varchar(128)        NOT NULL COMMENT 'name of the region',
+    `api_version`   varchar(128)        NOT NULL COMMENT 'api version of the object',
+    `kind`          varchar(128)        NOT NULL COMMENT 'kind of the object',
+    `name`          varchar(256)        NOT NULL DEFAULT '' COMMENT 'name of the object',
+    `status`        varchar(32)         NOT NULL COMMENT 'removed or deprecated in the region',
+    `deprecated_in` varchar(32)         NOT NULL DEFAULT '' COMMENT 'kubernetes version deprecating the api',
+    `removed_in`    varchar(32)         NOT NULL DEFAULT '' COMMENT 'kubernetes version removing the api',
+    `replacement`   varchar(128)        NOT NULL DEFAULT '' COMMENT 'api version to migrate to',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`    bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_resource_region` (`resource_type`, `resource_id`, `region`),
+    KEY `idx_region` (`region`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,32 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+CREATE TABLE `tb_git_trigger`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id` bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `event`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'push, tag or merge_request',
+    `pattern`    varchar(256)        NOT NULL DEFAULT '' COMMENT 'glob pattern of the branch or tag',
+    `enabled`    tinyint(1)          NOT NULL DEFAULT '1',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts` bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater, builddeploys are triggered as the updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,35 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE `tb_policy`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`         varchar(128)        NOT NULL COMMENT 'name of the policy',
+    `description`  varchar(256)        NOT NULL DEFAULT '' COMMENT 'description of the policy',
+    `expression`   text                NOT NULL COMMENT 'cel expression which is true if an object complies with the policy',
+    `message`      varchar(512)        NOT NULL DEFAULT '' COMMENT 'message of the violations',
+    `mode`         varchar(32)         NOT NULL COMMENT 'audit or enforce',
+    `kinds`        varchar(512)        NOT NULL DEFAULT '' COMMENT 'comma separated kinds of objects, empty means all',
+    `environments` varchar(512)        NOT NULL DEFAULT '' COMMENT 'comma separated environments bound, empty means all',
+    `group_ids`    varchar(512)        NOT NULL DEFAULT '' COMMENT 'comma separated ids of groups bound, empty means all',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`   bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`   bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`   bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name_deleted_ts` (`name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
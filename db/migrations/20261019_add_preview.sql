@@ -0,0 +1,56 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+CREATE TABLE `tb_preview_rule`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `application_id`  bigint(20) unsigned NOT NULL COMMENT 'application id',
+    `base_cluster_id` bigint(20) unsigned NOT NULL COMMENT 'cluster to clone config from',
+    `environment`     varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of preview clusters',
+    `region`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'region of preview clusters',
+    `name_pattern`    varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of preview clusters, {application}, {mr} and {branch} are replaced',
+    `target_branch`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'glob pattern of the target branch of merge requests',
+    `ttl_seconds`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'expire seconds of preview clusters, 0 means never',
+    `enabled`         tinyint(1)          NOT NULL DEFAULT '1',
+    `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater, preview clusters are created as the updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_application_id` (`application_id`),
+    KEY `idx_base_cluster_id` (`base_cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_preview_environment`
+(
+    `id`                bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `rule_id`           bigint(20) unsigned NOT NULL COMMENT 'preview rule id',
+    `application_id`    bigint(20) unsigned NOT NULL COMMENT 'application id',
+    `cluster_id`        bigint(20) unsigned NOT NULL COMMENT 'preview cluster id',
+    `merge_request_id`  bigint(20)          NOT NULL DEFAULT '0' COMMENT 'iid of merge request or number of pull request',
+    `merge_request_url` varchar(1024)       NOT NULL DEFAULT '' COMMENT 'web url of merge request',
+    `source_branch`     varchar(256)        NOT NULL DEFAULT '' COMMENT 'source branch of merge request',
+    `created_at`        datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`        datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`        bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_rule_merge_request_deleted_ts` (`rule_id`, `merge_request_id`, `deleted_ts`),
+    KEY `idx_application_id` (`application_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,51 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE `tb_resource_quota`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL COMMENT 'groups or applications',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the group or the application',
+    `environment`   varchar(128)        NOT NULL COMMENT 'environment of the quota',
+    `cpu`           bigint(20)          NOT NULL DEFAULT '0' COMMENT 'cpu limit in millicores, 0 means unlimited',
+    `memory`        bigint(20)          NOT NULL DEFAULT '0' COMMENT 'memory limit in MiB, 0 means unlimited',
+    `replicas`      bigint(20)          NOT NULL DEFAULT '0' COMMENT 'replicas limit, 0 means unlimited',
+    `clusters`      bigint(20)          NOT NULL DEFAULT '0' COMMENT 'clusters limit, 0 means unlimited',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`    bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_resource_environment_deleted_ts` (`resource_type`, `resource_id`, `environment`, `deleted_ts`),
+    KEY `idx_environment` (`environment`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_cluster_resource_usage`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id` bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `cpu`        bigint(20)          NOT NULL DEFAULT '0' COMMENT 'cpu requested in millicores over all replicas',
+    `memory`     bigint(20)          NOT NULL DEFAULT '0' COMMENT 'memory requested in MiB over all replicas',
+    `replicas`   bigint(20)          NOT NULL DEFAULT '0' COMMENT 'replicas requested',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts` bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id_deleted_ts` (`cluster_id`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
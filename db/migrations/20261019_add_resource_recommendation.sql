@@ -0,0 +1,36 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+CREATE TABLE `tb_resource_recommendation`
+(
+    `id`                 bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`         bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `cpu_usage`          bigint(20)          NOT NULL DEFAULT '0' COMMENT 'percentile of cpu usage of pods in millicores',
+    `memory_usage`       bigint(20)          NOT NULL DEFAULT '0' COMMENT 'percentile of memory usage of pods in MiB',
+    `current_tier`       varchar(64)         NOT NULL DEFAULT '' COMMENT 'current resource tier, empty if the template has no tiers',
+    `current_cpu`        bigint(20)          NOT NULL DEFAULT '0' COMMENT 'current cpu requests in millicores',
+    `current_memory`     bigint(20)          NOT NULL DEFAULT '0' COMMENT 'current memory requests in MiB',
+    `recommended_tier`   varchar(64)         NOT NULL DEFAULT '' COMMENT 'recommended resource tier, empty if the template has no tiers',
+    `recommended_cpu`    bigint(20)          NOT NULL DEFAULT '0' COMMENT 'recommended cpu requests in millicores',
+    `recommended_memory` bigint(20)          NOT NULL DEFAULT '0' COMMENT 'recommended memory requests in MiB',
+    `replicas`           bigint(20)          NOT NULL DEFAULT '1' COMMENT 'replicas of the cluster',
+    `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`         bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id_deleted_ts` (`cluster_id`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,37 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+CREATE TABLE `tb_terminal_recording`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `session_id`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'terminal session id',
+    `user_id`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'user who opened the session',
+    `cluster_id`   bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `environment`  varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of the cluster',
+    `pod`          varchar(256)        NOT NULL DEFAULT '' COMMENT 'pod name',
+    `container`    varchar(256)        NOT NULL DEFAULT '' COMMENT 'container name',
+    `mode`         varchar(32)         NOT NULL DEFAULT '' COMMENT 'shell or attach',
+    `object_key`   varchar(1024)       NOT NULL DEFAULT '' COMMENT 'key of the asciicast recording in the blob store',
+    `size`         bigint(20)          NOT NULL DEFAULT '0' COMMENT 'size of the recording in bytes',
+    `started_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `ended_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `close_reason` varchar(1024)       NOT NULL DEFAULT '' COMMENT 'why the session is closed',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_started_at` (`cluster_id`, `started_at`),
+    KEY `idx_user_started_at` (`user_id`, `started_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -10,9 +10,10 @@ require (
 	github.com/aws/aws-sdk-go v1.38.49
 	github.com/coreos/go-oidc/v3 v3.2.0
 	github.com/gin-gonic/gin v1.7.7
-	github.com/go-redis/redis/v8 v8.3.3
+	github.com/go-redis/redis/v8 v8.11.5
 	github.com/golang-jwt/jwt/v4 v4.4.3
 	github.com/golang/mock v1.6.0
+	github.com/google/cel-go v0.6.0
 	github.com/google/go-containerregistry v0.1.3
 	github.com/google/go-github/v41 v41.0.0
 	github.com/google/uuid v1.2.0
@@ -25,6 +26,7 @@ require (
 	github.com/mozillazg/go-pinyin v0.18.0
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.11.0
+	github.com/prometheus/common v0.26.0
 	github.com/rbcervilla/redisstore/v8 v8.1.0
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/santhosh-tekuri/jsonschema/v5 v5.0.0
@@ -35,6 +37,11 @@ require (
 	github.com/tektoncd/pipeline v0.17.1-0.20201027063619-b7badedd0f65
 	github.com/tektoncd/triggers v0.8.2-0.20201007153255-cb1879311818
 	github.com/xanzy/go-gitlab v0.50.4
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.0.1
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.0.1
+	go.opentelemetry.io/otel/sdk v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
 	golang.org/x/net v0.0.0-20220107192237-5cfca573fb4d
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
@@ -47,7 +54,7 @@ require (
 	gorm.io/gorm v1.21.15
 	gorm.io/plugin/prometheus v0.0.0-20210820101226-2a49866f83ee
 	gorm.io/plugin/soft_delete v1.0.3
-	helm.sh/helm/v3 v3.1.1
+	helm.sh/helm/v3 v3.4.2
 	k8s.io/api v0.23.5
 	k8s.io/apimachinery v0.23.5
 	k8s.io/cli-runtime v0.23.5
@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/horizoncd/horizon/lib/s3"
+)
+
+const (
+	TypeLocal = "local"
+	TypeS3    = "s3"
+)
+
+// Store stores blobs by keys, keys are slash separated paths such as a/b/c
+type Store interface {
+	Put(ctx context.Context, key string, content io.ReadSeeker) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+type localStore struct {
+	dir string
+}
+
+// NewLocalStore returns a store which saves blobs as files under dir
+func NewLocalStore(dir string) Store {
+	return &localStore{dir: dir}
+}
+
+func (s *localStore) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid key: %s", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *localStore) Put(ctx context.Context, key string, content io.ReadSeeker) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	_, err = io.Copy(file, content)
+	return err
+}
+
+func (s *localStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+type s3Store struct {
+	driver s3.Interface
+}
+
+// NewS3Store returns a store which saves blobs as objects of s3
+func NewS3Store(driver s3.Interface) Store {
+	return &s3Store{driver: driver}
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, content io.ReadSeeker) error {
+	return s.driver.PutObject(ctx, key, content, nil)
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.driver.GetObject(ctx, key)
+}
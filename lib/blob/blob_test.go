@@ -0,0 +1,42 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blob")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ctx := context.Background()
+	store := NewLocalStore(dir)
+	assert.Nil(t, store.Put(ctx, "a/b/c.txt", bytes.NewReader([]byte("hello"))))
+	content, err := store.Get(ctx, "a/b/c.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	_, err = store.Get(ctx, "a/not-exist.txt")
+	assert.NotNil(t, err)
+	assert.NotNil(t, store.Put(ctx, "../escape.txt", bytes.NewReader(nil)))
+}
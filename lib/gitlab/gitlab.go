@@ -24,6 +24,7 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 
 	"github.com/xanzy/go-gitlab"
@@ -72,6 +73,16 @@ type Interface interface {
 	// See https://docs.gitlab.com/ee/api/commits.html#get-a-single-commit for more information.
 	GetCommit(ctx context.Context, pid interface{}, commit string) (_ *gitlab.Commit, err error)
 
+	// ListCommits list the commits of the specified ref, sorted by committed date in descending order.
+	// The pid can be the project's ID or relative path such as fist/second.
+	// See https://docs.gitlab.com/ee/api/commits.html#list-repository-commits for more information.
+	ListCommits(ctx context.Context, pid interface{}, ref string, page, perPage int) ([]*gitlab.Commit, error)
+
+	// GetCommitDiff get the diffs of a specified commit against its first parent.
+	// The pid can be the project's ID or relative path such as fist/second.
+	// See https://docs.gitlab.com/ee/api/commits.html#get-the-diff-of-a-commit for more information.
+	GetCommitDiff(ctx context.Context, pid interface{}, commit string) ([]*gitlab.Diff, error)
+
 	// GetBranch get branch of the specified project.
 	// The pid can be the project's ID or relative path such as fist/second.
 	// See https://docs.gitlab.com/ee/api/branches.html#get-single-repository-branch for more information.
@@ -124,6 +135,11 @@ type Interface interface {
 	// See https://docs.gitlab.com/ee/api/merge_requests.html#update-mr for more information.
 	CloseMR(ctx context.Context, pid interface{}, mrID int) (mr *gitlab.MergeRequest, err error)
 
+	// CreateMRNote comment on a merge request for specified project.
+	// The pid can be the project's ID or relative path such as fist/second.
+	// See https://docs.gitlab.com/ee/api/notes.html#create-new-merge-request-note for more information.
+	CreateMRNote(ctx context.Context, pid interface{}, mrID int, body string) error
+
 	// WriteFiles write including create, delete, update multiple files within a specified project.
 	// The pid can be the project's ID or relative path such as fist/second.
 	// See https://docs.gitlab.com/ee/api/commits.html#create-a-commit-with-multiple-files-and-actions
@@ -196,9 +212,9 @@ func New(token, httpURL string) (Interface, error) {
 	client, err := gitlab.NewClient(token,
 		gitlab.WithBaseURL(httpURL),
 		gitlab.WithHTTPClient(&http.Client{
-			Transport: &http.Transport{
+			Transport: trace.NewTransport("gitlab", &http.Transport{
 				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
+			}),
 		}))
 	if err != nil {
 		return nil, herrors.NewErrCreateFailed(herrors.GitlabResource, err.Error())
@@ -211,7 +227,8 @@ func New(token, httpURL string) (Interface, error) {
 
 func (h *helper) GetGroup(ctx context.Context, gid interface{}) (_ *gitlab.Group, err error) {
 	const op = "gitlab: get group"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	group, rsp, err := h.client.Groups.GetGroup(gid, nil, gitlab.WithContext(ctx))
 	if err != nil {
@@ -224,7 +241,8 @@ func (h *helper) GetGroup(ctx context.Context, gid interface{}) (_ *gitlab.Group
 func (h *helper) ListGroupProjects(ctx context.Context, gid interface{},
 	page, perPage int) (_ []*gitlab.Project, err error) {
 	const op = "gitlab: list group projects"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	if page < 1 {
 		return nil, perror.Wrap(herrors.ErrParamInvalid, "page cannot be less 1")
@@ -249,7 +267,8 @@ func (h *helper) ListGroupProjects(ctx context.Context, gid interface{},
 func (h *helper) CreateGroup(ctx context.Context, name, path string,
 	parentID *int, visibility string) (_ *gitlab.Group, err error) {
 	const op = "gitlab: create group"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	visibilityValue := gitlab.VisibilityValue(visibility)
 
@@ -269,7 +288,8 @@ func (h *helper) CreateGroup(ctx context.Context, name, path string,
 
 func (h *helper) DeleteGroup(ctx context.Context, gid interface{}) (err error) {
 	const op = "gitlab: delete group"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	rsp, err := h.client.Groups.DeleteGroup(gid, gitlab.WithContext(ctx))
 
@@ -278,7 +298,8 @@ func (h *helper) DeleteGroup(ctx context.Context, gid interface{}) (err error) {
 
 func (h *helper) GetProject(ctx context.Context, pid interface{}) (_ *gitlab.Project, err error) {
 	const op = "gitlab: get project"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	project, rsp, err := h.client.Projects.GetProject(pid, nil, gitlab.WithContext(ctx))
 	if err != nil {
@@ -290,7 +311,8 @@ func (h *helper) GetProject(ctx context.Context, pid interface{}) (_ *gitlab.Pro
 func (h *helper) CreateProject(ctx context.Context, name string,
 	groupID int, visibility string) (_ *gitlab.Project, err error) {
 	const op = "gitlab: create project"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	visibilityValue := gitlab.VisibilityValue(visibility)
 
@@ -311,7 +333,8 @@ func (h *helper) CreateProject(ctx context.Context, name string,
 
 func (h *helper) DeleteProject(ctx context.Context, pid interface{}) (err error) {
 	const op = "gitlab: delete project"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	rsp, err := h.client.Projects.DeleteProject(pid, gitlab.WithContext(ctx))
 
@@ -320,7 +343,8 @@ func (h *helper) DeleteProject(ctx context.Context, pid interface{}) (err error)
 
 func (h *helper) GetBranch(ctx context.Context, pid interface{}, branch string) (_ *gitlab.Branch, err error) {
 	const op = "gitlab: get branch"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	b, rsp, err := h.client.Branches.GetBranch(pid, branch, gitlab.WithContext(ctx))
 	if err != nil {
@@ -333,7 +357,8 @@ func (h *helper) GetBranch(ctx context.Context, pid interface{}, branch string)
 func (h *helper) ListBranch(ctx context.Context, pid interface{},
 	listBranchOptions *gitlab.ListBranchesOptions) (_ []*gitlab.Branch, err error) {
 	const op = "gitlab: list branch"
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	branches, rsp, err := h.client.Branches.ListBranches(pid, listBranchOptions, nil)
 	if err != nil {
@@ -345,7 +370,8 @@ func (h *helper) ListBranch(ctx context.Context, pid interface{},
 func (h *helper) ListTag(ctx context.Context, pid interface{},
 	listTagsOptions *gitlab.ListTagsOptions) (_ []*gitlab.Tag, err error) {
 	const op = "gitlab: list tag"
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	tags, rsp, err := h.client.Tags.ListTags(pid, listTagsOptions, nil)
 	if err != nil {
@@ -356,7 +382,8 @@ func (h *helper) ListTag(ctx context.Context, pid interface{},
 
 func (h *helper) GetCommit(ctx context.Context, pid interface{}, commit string) (_ *gitlab.Commit, err error) {
 	const op = "gitlab: get commit"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	c, rsp, err := h.client.Commits.GetCommit(pid, commit, gitlab.WithContext(ctx))
 	if err != nil {
@@ -366,9 +393,43 @@ func (h *helper) GetCommit(ctx context.Context, pid interface{}, commit string)
 	return c, nil
 }
 
+func (h *helper) ListCommits(ctx context.Context, pid interface{}, ref string,
+	page, perPage int) (_ []*gitlab.Commit, err error) {
+	const op = "gitlab: list commits"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	commits, rsp, err := h.client.Commits.ListCommits(pid, &gitlab.ListCommitsOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		},
+		RefName: &ref,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, parseError(rsp, err)
+	}
+
+	return commits, nil
+}
+
+func (h *helper) GetCommitDiff(ctx context.Context, pid interface{}, commit string) (_ []*gitlab.Diff, err error) {
+	const op = "gitlab: get commit diff"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	diffs, rsp, err := h.client.Commits.GetCommitDiff(pid, commit, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, parseError(rsp, err)
+	}
+
+	return diffs, nil
+}
+
 func (h *helper) GetTag(ctx context.Context, pid interface{}, tag string) (_ *gitlab.Tag, err error) {
 	const op = "gitlab: get tag"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	c, rsp, err := h.client.Tags.GetTag(pid, tag, gitlab.WithContext(ctx))
 	if err != nil {
@@ -381,7 +442,8 @@ func (h *helper) GetTag(ctx context.Context, pid interface{}, tag string) (_ *gi
 func (h *helper) CreateBranch(ctx context.Context, pid interface{},
 	branch, fromRef string) (_ *gitlab.Branch, err error) {
 	const op = "gitlab: create branch"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	b, rsp, err := h.client.Branches.CreateBranch(pid, &gitlab.CreateBranchOptions{
 		Branch: &branch,
@@ -397,7 +459,8 @@ func (h *helper) CreateBranch(ctx context.Context, pid interface{},
 
 func (h *helper) DeleteBranch(ctx context.Context, pid interface{}, branch string) (err error) {
 	const op = "gitlab: delete branch"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	rsp, err := h.client.Branches.DeleteBranch(pid, branch, gitlab.WithContext(ctx))
 
@@ -407,7 +470,8 @@ func (h *helper) DeleteBranch(ctx context.Context, pid interface{}, branch strin
 func (h *helper) CreateMR(ctx context.Context, pid interface{},
 	source, target, title string) (_ *gitlab.MergeRequest, err error) {
 	const op = "gitlab: create mr"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	mr, rsp, err := h.client.MergeRequests.CreateMergeRequest(pid, &gitlab.CreateMergeRequestOptions{
 		Title:        &title,
@@ -440,7 +504,8 @@ func (h *helper) ListMRs(ctx context.Context, pid interface{},
 
 func (h *helper) CloseMR(ctx context.Context, pid interface{}, mrID int) (mr *gitlab.MergeRequest, err error) {
 	const op = "gitlab: close mr"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	closeEvent := "close"
 	mr, resp, err := h.client.MergeRequests.UpdateMergeRequest(pid, mrID, &gitlab.UpdateMergeRequestOptions{
@@ -458,10 +523,25 @@ func (h *helper) CloseMR(ctx context.Context, pid interface{}, mrID int) (mr *gi
 	return nil, err2
 }
 
+func (h *helper) CreateMRNote(ctx context.Context, pid interface{}, mrID int, body string) (err error) {
+	const op = "gitlab: create mr note"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	_, resp, err := h.client.Notes.CreateMergeRequestNote(pid, mrID, &gitlab.CreateMergeRequestNoteOptions{
+		Body: &body,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return parseError(resp, err)
+	}
+	return nil
+}
+
 func (h *helper) AcceptMR(ctx context.Context, pid interface{}, mrID int,
 	mergeCommitMsg *string, shouldRemoveSourceBranch *bool) (mr *gitlab.MergeRequest, err error) {
 	const op = "gitlab: accept mr"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	retryFunc := func(i int) (*gitlab.MergeRequest, error) {
 		mr, rsp, err := h.client.MergeRequests.AcceptMergeRequest(pid, mrID, &gitlab.AcceptMergeRequestOptions{
@@ -489,7 +569,8 @@ func (h *helper) AcceptMR(ctx context.Context, pid interface{}, mrID int,
 func (h *helper) WriteFiles(ctx context.Context, pid interface{}, branch, commitMsg string,
 	startBranch *string, actions []CommitAction) (_ *gitlab.Commit, err error) {
 	const op = "gitlab: write files"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	commit, rsp, err := h.client.Commits.CreateCommit(pid, &gitlab.CreateCommitOptions{
 		Branch:        &branch,
@@ -519,7 +600,8 @@ func (h *helper) WriteFiles(ctx context.Context, pid interface{}, branch, commit
 
 func (h *helper) GetFile(ctx context.Context, pid interface{}, ref, filepath string) (_ []byte, err error) {
 	const op = "gitlab: get file"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	content, rsp, err := h.client.RepositoryFiles.GetRawFile(pid, filepath, &gitlab.GetRawFileOptions{
 		Ref: &ref,
@@ -534,7 +616,8 @@ func (h *helper) GetFile(ctx context.Context, pid interface{}, ref, filepath str
 
 func (h *helper) TransferProject(ctx context.Context, pid interface{}, gid interface{}) (err error) {
 	const op = "gitlab: transfer project"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	if _, rsp, err := h.client.Projects.TransferProject(pid, &gitlab.TransferProjectOptions{
 		Namespace: gid,
@@ -547,7 +630,8 @@ func (h *helper) TransferProject(ctx context.Context, pid interface{}, gid inter
 
 func (h *helper) EditNameAndPathForProject(ctx context.Context, pid interface{}, newName, newPath *string) (err error) {
 	const op = "gitlab: edit name and path for project"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	if _, rsp, err := h.client.Projects.EditProject(pid, &gitlab.EditProjectOptions{
 		Name: newName,
@@ -562,7 +646,8 @@ func (h *helper) EditNameAndPathForProject(ctx context.Context, pid interface{},
 func (h *helper) Compare(ctx context.Context, pid interface{}, from, to string,
 	straight *bool) (_ *gitlab.Compare, err error) {
 	const op = "gitlab: compare branchs"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	compare, rsp, err := h.client.Repositories.Compare(pid, &gitlab.CompareOptions{
 		From:     &from,
@@ -577,7 +662,8 @@ func (h *helper) Compare(ctx context.Context, pid interface{}, from, to string,
 }
 func (h *helper) GetRepositoryArchive(ctx context.Context, pid interface{}, sha string) ([]byte, error) {
 	const op = "gitlab: get repository archive"
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	format := "tar.gz"
 	archive, resp, err := h.client.Repositories.Archive(pid, &gitlab.ArchiveOptions{
@@ -63,6 +63,10 @@ func NewMySQLDB(db *MySQL) (*gorm.DB, error) {
 			SingularTable: true,
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
+	RegisterTraceCallbacks(orm)
 
 	if db.PrometheusEnabled {
 		if err := orm.Use(prometheus.New(prometheus.Config{
@@ -94,8 +98,12 @@ func NewSqliteDB(file string) (*gorm.DB, error) {
 			},
 		),
 	})
+	if err != nil {
+		return nil, err
+	}
+	RegisterTraceCallbacks(orm)
 
-	return orm, err
+	return orm, nil
 }
 
 func FormatSortExp(query *q.Query) string {
@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/util/trace"
+)
+
+const (
+	_traceSpanKey      = "horizon:trace_span"
+	_traceCallbackName = "horizon:trace"
+)
+
+// RegisterTraceCallbacks starts a span for each statement executed by db
+func RegisterTraceCallbacks(db *gorm.DB) {
+	_ = db.Callback().Create().Before("gorm:create").Register(_traceCallbackName+":before_create",
+		startSpan("gorm: create"))
+	_ = db.Callback().Create().After("gorm:create").Register(_traceCallbackName+":after_create", endSpan)
+
+	_ = db.Callback().Query().Before("gorm:query").Register(_traceCallbackName+":before_query",
+		startSpan("gorm: query"))
+	_ = db.Callback().Query().After("gorm:query").Register(_traceCallbackName+":after_query", endSpan)
+
+	_ = db.Callback().Update().Before("gorm:update").Register(_traceCallbackName+":before_update",
+		startSpan("gorm: update"))
+	_ = db.Callback().Update().After("gorm:update").Register(_traceCallbackName+":after_update", endSpan)
+
+	_ = db.Callback().Delete().Before("gorm:delete").Register(_traceCallbackName+":before_delete",
+		startSpan("gorm: delete"))
+	_ = db.Callback().Delete().After("gorm:delete").Register(_traceCallbackName+":after_delete", endSpan)
+
+	_ = db.Callback().Row().Before("gorm:row").Register(_traceCallbackName+":before_row",
+		startSpan("gorm: row"))
+	_ = db.Callback().Row().After("gorm:row").Register(_traceCallbackName+":after_row", endSpan)
+
+	_ = db.Callback().Raw().Before("gorm:raw").Register(_traceCallbackName+":before_raw",
+		startSpan("gorm: raw"))
+	_ = db.Callback().Raw().After("gorm:raw").Register(_traceCallbackName+":after_raw", endSpan)
+}
+
+func startSpan(name string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		_, span := trace.Start(db.Statement.Context, name,
+			semconv.DBSystemKey.String(db.Dialector.Name()))
+		db.InstanceSet(_traceSpanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(_traceSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(oteltrace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(semconv.DBStatementKey.String(db.Statement.SQL.String()))
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBSQLTableKey.String(db.Statement.Table))
+	}
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	trace.End(span, err)
+}
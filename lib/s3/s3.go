@@ -25,10 +25,13 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	oteltrace "go.opentelemetry.io/otel/trace"
 
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 )
 
 type Interface interface {
@@ -73,6 +76,9 @@ func (params *Params) check() error {
 	return nil
 }
 
+// spanKey is the key of the span of operation stored in request context
+type spanKey struct{}
+
 type Driver struct {
 	Params
 	S3 *awss3.S3
@@ -110,6 +116,16 @@ func NewDriver(params Params) (Interface, error) {
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
+	// start a span for each operation, it ends when the operation completes after retries
+	sess.Handlers.Validate.PushFront(func(r *request.Request) {
+		ctx, span := trace.Start(r.Context(), "s3: "+r.Operation.Name)
+		r.SetContext(context.WithValue(ctx, spanKey{}, span))
+	})
+	sess.Handlers.Complete.PushBack(func(r *request.Request) {
+		if span, ok := r.Context().Value(spanKey{}).(oteltrace.Span); ok {
+			trace.End(span, r.Error)
+		}
+	})
 
 	d.S3 = awss3.New(sess)
 
@@ -126,6 +126,20 @@ func (mr *MockInterfaceMockRecorder) CreateMR(ctx, pid, source, target, title in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMR", reflect.TypeOf((*MockInterface)(nil).CreateMR), ctx, pid, source, target, title)
 }
 
+// CreateMRNote mocks base method.
+func (m *MockInterface) CreateMRNote(ctx context.Context, pid interface{}, mrID int, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMRNote", ctx, pid, mrID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMRNote indicates an expected call of CreateMRNote.
+func (mr *MockInterfaceMockRecorder) CreateMRNote(ctx, pid, mrID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMRNote", reflect.TypeOf((*MockInterface)(nil).CreateMRNote), ctx, pid, mrID, body)
+}
+
 // CreateProject mocks base method.
 func (m *MockInterface) CreateProject(ctx context.Context, name string, groupID int, visibility string) (*gitlab0.Project, error) {
 	m.ctrl.T.Helper()
@@ -227,6 +241,21 @@ func (mr *MockInterfaceMockRecorder) GetCommit(ctx, pid, commit interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommit", reflect.TypeOf((*MockInterface)(nil).GetCommit), ctx, pid, commit)
 }
 
+// GetCommitDiff mocks base method.
+func (m *MockInterface) GetCommitDiff(ctx context.Context, pid interface{}, commit string) ([]*gitlab0.Diff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommitDiff", ctx, pid, commit)
+	ret0, _ := ret[0].([]*gitlab0.Diff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommitDiff indicates an expected call of GetCommitDiff.
+func (mr *MockInterfaceMockRecorder) GetCommitDiff(ctx, pid, commit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitDiff", reflect.TypeOf((*MockInterface)(nil).GetCommitDiff), ctx, pid, commit)
+}
+
 // GetCreatedGroup mocks base method.
 func (m *MockInterface) GetCreatedGroup(ctx context.Context, parentID int, parentPath, name, visibility string) (*gitlab0.Group, error) {
 	m.ctrl.T.Helper()
@@ -346,6 +375,21 @@ func (mr *MockInterfaceMockRecorder) ListBranch(ctx, pid, listBranchOptions inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBranch", reflect.TypeOf((*MockInterface)(nil).ListBranch), ctx, pid, listBranchOptions)
 }
 
+// ListCommits mocks base method.
+func (m *MockInterface) ListCommits(ctx context.Context, pid interface{}, ref string, page, perPage int) ([]*gitlab0.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCommits", ctx, pid, ref, page, perPage)
+	ret0, _ := ret[0].([]*gitlab0.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCommits indicates an expected call of ListCommits.
+func (mr *MockInterfaceMockRecorder) ListCommits(ctx, pid, ref, page, perPage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommits", reflect.TypeOf((*MockInterface)(nil).ListCommits), ctx, pid, ref, page, perPage)
+}
+
 // ListGroupProjects mocks base method.
 func (m *MockInterface) ListGroupProjects(ctx context.Context, gid interface{}, page, perPage int) ([]*gitlab0.Project, error) {
 	m.ctrl.T.Helper()
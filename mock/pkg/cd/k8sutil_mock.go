@@ -6,10 +6,12 @@ package mock_cd
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
 	cd "github.com/horizoncd/horizon/pkg/cd"
+	traffic "github.com/horizoncd/horizon/pkg/traffic"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -36,6 +38,20 @@ func (m *MockK8sUtil) EXPECT() *MockK8sUtilMockRecorder {
 	return m.recorder
 }
 
+// ArchiveClusterLogs mocks base method.
+func (m *MockK8sUtil) ArchiveClusterLogs(ctx context.Context, params *cd.GetClusterLogsParams, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveClusterLogs", ctx, params, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveClusterLogs indicates an expected call of ArchiveClusterLogs.
+func (mr *MockK8sUtilMockRecorder) ArchiveClusterLogs(ctx, params, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveClusterLogs", reflect.TypeOf((*MockK8sUtil)(nil).ArchiveClusterLogs), ctx, params, w)
+}
+
 // DeletePods mocks base method.
 func (m *MockK8sUtil) DeletePods(ctx context.Context, params *cd.DeletePodsParams) (map[string]cd.OperationResult, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +96,21 @@ func (mr *MockK8sUtilMockRecorder) ExecuteAction(ctx, params interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteAction", reflect.TypeOf((*MockK8sUtil)(nil).ExecuteAction), ctx, params)
 }
 
+// GetClusterLogs mocks base method.
+func (m *MockK8sUtil) GetClusterLogs(ctx context.Context, params *cd.GetClusterLogsParams) (<-chan string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterLogs", ctx, params)
+	ret0, _ := ret[0].(<-chan string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterLogs indicates an expected call of GetClusterLogs.
+func (mr *MockK8sUtilMockRecorder) GetClusterLogs(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterLogs", reflect.TypeOf((*MockK8sUtil)(nil).GetClusterLogs), ctx, params)
+}
+
 // GetContainerLog mocks base method.
 func (m *MockK8sUtil) GetContainerLog(ctx context.Context, params *cd.GetContainerLogParams) (<-chan string, error) {
 	m.ctrl.T.Helper()
@@ -124,3 +155,33 @@ func (mr *MockK8sUtilMockRecorder) GetPodContainers(ctx, params interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodContainers", reflect.TypeOf((*MockK8sUtil)(nil).GetPodContainers), ctx, params)
 }
+
+// GetTraffic mocks base method.
+func (m *MockK8sUtil) GetTraffic(ctx context.Context, params *cd.GetTrafficParams) (*traffic.Traffic, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTraffic", ctx, params)
+	ret0, _ := ret[0].(*traffic.Traffic)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTraffic indicates an expected call of GetTraffic.
+func (mr *MockK8sUtilMockRecorder) GetTraffic(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTraffic", reflect.TypeOf((*MockK8sUtil)(nil).GetTraffic), ctx, params)
+}
+
+// ShiftTraffic mocks base method.
+func (m *MockK8sUtil) ShiftTraffic(ctx context.Context, params *cd.ShiftTrafficParams) (*traffic.Traffic, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShiftTraffic", ctx, params)
+	ret0, _ := ret[0].(*traffic.Traffic)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShiftTraffic indicates an expected call of ShiftTraffic.
+func (mr *MockK8sUtilMockRecorder) ShiftTraffic(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShiftTraffic", reflect.TypeOf((*MockK8sUtil)(nil).ShiftTraffic), ctx, params)
+}
@@ -123,6 +123,21 @@ func (mr *MockClusterGitRepoMockRecorder) GetCluster(ctx, application, cluster,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).GetCluster), ctx, application, cluster, templateName)
 }
 
+// GetClusterByCommit mocks base method.
+func (m *MockClusterGitRepo) GetClusterByCommit(ctx context.Context, application, cluster, templateName, commit string) (*gitrepo.ClusterFiles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterByCommit", ctx, application, cluster, templateName, commit)
+	ret0, _ := ret[0].(*gitrepo.ClusterFiles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterByCommit indicates an expected call of GetClusterByCommit.
+func (mr *MockClusterGitRepoMockRecorder) GetClusterByCommit(ctx, application, cluster, templateName, commit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterByCommit", reflect.TypeOf((*MockClusterGitRepo)(nil).GetClusterByCommit), ctx, application, cluster, templateName, commit)
+}
+
 // GetClusterTemplate mocks base method.
 func (m *MockClusterGitRepo) GetClusterTemplate(ctx context.Context, application, cluster string) (*gitrepo.ClusterTemplate, error) {
 	m.ctrl.T.Helper()
@@ -168,6 +183,21 @@ func (mr *MockClusterGitRepoMockRecorder) GetConfigCommit(ctx, application, clus
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigCommit", reflect.TypeOf((*MockClusterGitRepo)(nil).GetConfigCommit), ctx, application, cluster)
 }
 
+// GetDeployValues mocks base method.
+func (m *MockClusterGitRepo) GetDeployValues(ctx context.Context, application, cluster, commit string) ([]map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeployValues", ctx, application, cluster, commit)
+	ret0, _ := ret[0].([]map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeployValues indicates an expected call of GetDeployValues.
+func (mr *MockClusterGitRepoMockRecorder) GetDeployValues(ctx, application, cluster, commit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeployValues", reflect.TypeOf((*MockClusterGitRepo)(nil).GetDeployValues), ctx, application, cluster, commit)
+}
+
 // GetEnvValue mocks base method.
 func (m *MockClusterGitRepo) GetEnvValue(ctx context.Context, application, cluster, templateName string) (*gitrepo.EnvValue, error) {
 	m.ctrl.T.Helper()
@@ -256,6 +286,21 @@ func (mr *MockClusterGitRepoMockRecorder) HardDeleteCluster(ctx, application, cl
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).HardDeleteCluster), ctx, application, cluster)
 }
 
+// ListConfigCommits mocks base method.
+func (m *MockClusterGitRepo) ListConfigCommits(ctx context.Context, application, cluster, branch string, page, perPage int) ([]*gitrepo.ConfigCommit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConfigCommits", ctx, application, cluster, branch, page, perPage)
+	ret0, _ := ret[0].([]*gitrepo.ConfigCommit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConfigCommits indicates an expected call of ListConfigCommits.
+func (mr *MockClusterGitRepoMockRecorder) ListConfigCommits(ctx, application, cluster, branch, page, perPage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConfigCommits", reflect.TypeOf((*MockClusterGitRepo)(nil).ListConfigCommits), ctx, application, cluster, branch, page, perPage)
+}
+
 // MergeBranch mocks base method.
 func (m *MockClusterGitRepo) MergeBranch(ctx context.Context, application, cluster, sourceBranch, targetBranch string, pipelineRunID *uint) (string, error) {
 	m.ctrl.T.Helper()
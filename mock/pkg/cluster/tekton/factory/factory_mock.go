@@ -10,57 +10,112 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	tekton "github.com/horizoncd/horizon/pkg/cluster/tekton"
 	collector "github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	tekton0 "github.com/horizoncd/horizon/pkg/config/tekton"
 )
 
-// MockFactory is a mock of Factory interface
+// MockFactory is a mock of Factory interface.
 type MockFactory struct {
 	ctrl     *gomock.Controller
 	recorder *MockFactoryMockRecorder
 }
 
-// MockFactoryMockRecorder is the mock recorder for MockFactory
+// MockFactoryMockRecorder is the mock recorder for MockFactory.
 type MockFactoryMockRecorder struct {
 	mock *MockFactory
 }
 
-// NewMockFactory creates a new mock instance
+// NewMockFactory creates a new mock instance.
 func NewMockFactory(ctrl *gomock.Controller) *MockFactory {
 	mock := &MockFactory{ctrl: ctrl}
 	mock.recorder = &MockFactoryMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockFactory) EXPECT() *MockFactoryMockRecorder {
 	return m.recorder
 }
 
-// GetTekton mocks base method
-func (m *MockFactory) GetTekton(environment string) (tekton.Interface, error) {
+// DeleteTekton mocks base method.
+func (m *MockFactory) DeleteTekton(environment, region string) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTekton", environment)
+	m.ctrl.Call(m, "DeleteTekton", environment, region)
+}
+
+// DeleteTekton indicates an expected call of DeleteTekton.
+func (mr *MockFactoryMockRecorder) DeleteTekton(environment, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTekton", reflect.TypeOf((*MockFactory)(nil).DeleteTekton), environment, region)
+}
+
+// GetTekton mocks base method.
+func (m *MockFactory) GetTekton(environment, region string) (tekton.Interface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTekton", environment, region)
 	ret0, _ := ret[0].(tekton.Interface)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTekton indicates an expected call of GetTekton
-func (mr *MockFactoryMockRecorder) GetTekton(environment interface{}) *gomock.Call {
+// GetTekton indicates an expected call of GetTekton.
+func (mr *MockFactoryMockRecorder) GetTekton(environment, region interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTekton", reflect.TypeOf((*MockFactory)(nil).GetTekton), environment)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTekton", reflect.TypeOf((*MockFactory)(nil).GetTekton), environment, region)
 }
 
-// GetTektonCollector mocks base method
-func (m *MockFactory) GetTektonCollector(environment string) (collector.Interface, error) {
+// GetTektonCollector mocks base method.
+func (m *MockFactory) GetTektonCollector(environment, region string) (collector.Interface, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTektonCollector", environment)
+	ret := m.ctrl.Call(m, "GetTektonCollector", environment, region)
 	ret0, _ := ret[0].(collector.Interface)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTektonCollector indicates an expected call of GetTektonCollector
-func (mr *MockFactoryMockRecorder) GetTektonCollector(environment interface{}) *gomock.Call {
+// GetTektonCollector indicates an expected call of GetTektonCollector.
+func (mr *MockFactoryMockRecorder) GetTektonCollector(environment, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTektonCollector", reflect.TypeOf((*MockFactory)(nil).GetTektonCollector), environment, region)
+}
+
+// Reload mocks base method.
+func (m *MockFactory) Reload(tektonMapper tekton0.Mapper) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reload", tektonMapper)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reload indicates an expected call of Reload.
+func (mr *MockFactoryMockRecorder) Reload(tektonMapper interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reload", reflect.TypeOf((*MockFactory)(nil).Reload), tektonMapper)
+}
+
+// ReloadOverrides mocks base method.
+func (m *MockFactory) ReloadOverrides(tektonMapper tekton0.Mapper) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadOverrides", tektonMapper)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadOverrides indicates an expected call of ReloadOverrides.
+func (mr *MockFactoryMockRecorder) ReloadOverrides(tektonMapper interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadOverrides", reflect.TypeOf((*MockFactory)(nil).ReloadOverrides), tektonMapper)
+}
+
+// SetTekton mocks base method.
+func (m *MockFactory) SetTekton(environment, region string, tektonConfig *tekton0.Tekton) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTekton", environment, region, tektonConfig)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTekton indicates an expected call of SetTekton.
+func (mr *MockFactoryMockRecorder) SetTekton(environment, region, tektonConfig interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTektonCollector", reflect.TypeOf((*MockFactory)(nil).GetTektonCollector), environment)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTekton", reflect.TypeOf((*MockFactory)(nil).SetTekton), environment, region, tektonConfig)
 }
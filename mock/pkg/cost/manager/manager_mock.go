@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/cost/manager/manager.go
+
+// Package mock_manager is a generated GoMock package.
+package mock_manager
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/cost/models"
+)
+
+// MockManager is a mock of Manager interface.
+type MockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerMockRecorder
+}
+
+// MockManagerMockRecorder is the mock recorder for MockManager.
+type MockManagerMockRecorder struct {
+	mock *MockManager
+}
+
+// NewMockManager creates a new mock instance.
+func NewMockManager(ctrl *gomock.Controller) *MockManager {
+	mock := &MockManager{ctrl: ctrl}
+	mock.recorder = &MockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManager) EXPECT() *MockManagerMockRecorder {
+	return m.recorder
+}
+
+// Accumulate mocks base method.
+func (m *MockManager) Accumulate(ctx context.Context, regionName string, accountedAt time.Time, costs []*models.ClusterCost) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accumulate", ctx, regionName, accountedAt, costs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Accumulate indicates an expected call of Accumulate.
+func (mr *MockManagerMockRecorder) Accumulate(ctx, regionName, accountedAt, costs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accumulate", reflect.TypeOf((*MockManager)(nil).Accumulate), ctx, regionName, accountedAt, costs)
+}
+
+// GetBudget mocks base method.
+func (m *MockManager) GetBudget(ctx context.Context, resourceType string, resourceID uint) (*models.Budget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBudget", ctx, resourceType, resourceID)
+	ret0, _ := ret[0].(*models.Budget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBudget indicates an expected call of GetBudget.
+func (mr *MockManagerMockRecorder) GetBudget(ctx, resourceType, resourceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBudget", reflect.TypeOf((*MockManager)(nil).GetBudget), ctx, resourceType, resourceID)
+}
+
+// ListAccountedAt mocks base method.
+func (m *MockManager) ListAccountedAt(ctx context.Context) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountedAt", ctx)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountedAt indicates an expected call of ListAccountedAt.
+func (mr *MockManagerMockRecorder) ListAccountedAt(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountedAt", reflect.TypeOf((*MockManager)(nil).ListAccountedAt), ctx)
+}
+
+// ListBudgets mocks base method.
+func (m *MockManager) ListBudgets(ctx context.Context) ([]*models.Budget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBudgets", ctx)
+	ret0, _ := ret[0].([]*models.Budget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBudgets indicates an expected call of ListBudgets.
+func (mr *MockManagerMockRecorder) ListBudgets(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBudgets", reflect.TypeOf((*MockManager)(nil).ListBudgets), ctx)
+}
+
+// SaveBudget mocks base method.
+func (m *MockManager) SaveBudget(ctx context.Context, budget *models.Budget) (*models.Budget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBudget", ctx, budget)
+	ret0, _ := ret[0].(*models.Budget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBudget indicates an expected call of SaveBudget.
+func (mr *MockManagerMockRecorder) SaveBudget(ctx, budget interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBudget", reflect.TypeOf((*MockManager)(nil).SaveBudget), ctx, budget)
+}
+
+// Sum mocks base method.
+func (m *MockManager) Sum(ctx context.Context, query *models.CostQuery, by string) ([]*models.Cost, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sum", ctx, query, by)
+	ret0, _ := ret[0].([]*models.Cost)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sum indicates an expected call of Sum.
+func (mr *MockManagerMockRecorder) Sum(ctx, query, by interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sum", reflect.TypeOf((*MockManager)(nil).Sum), ctx, query, by)
+}
+
+// SumByTag mocks base method.
+func (m *MockManager) SumByTag(ctx context.Context, query *models.CostQuery, tagKey string) ([]*models.Cost, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumByTag", ctx, query, tagKey)
+	ret0, _ := ret[0].([]*models.Cost)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumByTag indicates an expected call of SumByTag.
+func (mr *MockManagerMockRecorder) SumByTag(ctx, query, tagKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumByTag", reflect.TypeOf((*MockManager)(nil).SumByTag), ctx, query, tagKey)
+}
+
+// UpdateBudgetNotified mocks base method.
+func (m *MockManager) UpdateBudgetNotified(ctx context.Context, id uint, month string, threshold uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBudgetNotified", ctx, id, month, threshold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBudgetNotified indicates an expected call of UpdateBudgetNotified.
+func (mr *MockManagerMockRecorder) UpdateBudgetNotified(ctx, id, month, threshold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBudgetNotified", reflect.TypeOf((*MockManager)(nil).UpdateBudgetNotified), ctx, id, month, threshold)
+}
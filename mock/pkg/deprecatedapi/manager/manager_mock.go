@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: manager.go
+
+// Package mock_manager is a generated GoMock package.
+package mock_manager
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+)
+
+// MockManager is a mock of Manager interface.
+type MockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerMockRecorder
+}
+
+// MockManagerMockRecorder is the mock recorder for MockManager.
+type MockManagerMockRecorder struct {
+	mock *MockManager
+}
+
+// NewMockManager creates a new mock instance.
+func NewMockManager(ctrl *gomock.Controller) *MockManager {
+	mock := &MockManager{ctrl: ctrl}
+	mock.recorder = &MockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManager) EXPECT() *MockManagerMockRecorder {
+	return m.recorder
+}
+
+// DeleteByResource mocks base method.
+func (m *MockManager) DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByResource", ctx, resourceType, resourceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByResource indicates an expected call of DeleteByResource.
+func (mr *MockManagerMockRecorder) DeleteByResource(ctx, resourceType, resourceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByResource", reflect.TypeOf((*MockManager)(nil).DeleteByResource), ctx, resourceType, resourceID)
+}
+
+// List mocks base method.
+func (m *MockManager) List(ctx context.Context) ([]*models.DeprecatedAPI, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]*models.DeprecatedAPI)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockManagerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockManager)(nil).List), ctx)
+}
+
+// Replace mocks base method.
+func (m *MockManager) Replace(ctx context.Context, resourceType string, resourceID uint, region string, deprecatedAPIs []*models.DeprecatedAPI) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", ctx, resourceType, resourceID, region, deprecatedAPIs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockManagerMockRecorder) Replace(ctx, resourceType, resourceID, region, deprecatedAPIs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockManager)(nil).Replace), ctx, resourceType, resourceID, region, deprecatedAPIs)
+}
@@ -35,6 +35,20 @@ func (m *MockHelper) EXPECT() *MockHelperMockRecorder {
 	return m.recorder
 }
 
+// CreateMergeRequestComment mocks base method.
+func (m *MockHelper) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, comment string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMergeRequestComment", ctx, gitURL, mrID, comment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMergeRequestComment indicates an expected call of CreateMergeRequestComment.
+func (mr *MockHelperMockRecorder) CreateMergeRequestComment(ctx, gitURL, mrID, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMergeRequestComment", reflect.TypeOf((*MockHelper)(nil).CreateMergeRequestComment), ctx, gitURL, mrID, comment)
+}
+
 // GetCommit mocks base method.
 func (m *MockHelper) GetCommit(ctx context.Context, gitURL, refType, ref string) (*git.Commit, error) {
 	m.ctrl.T.Helper()
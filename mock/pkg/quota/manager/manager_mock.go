@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/quota/manager/manager.go
+
+// Package mock_manager is a generated GoMock package.
+package mock_manager
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+// MockManager is a mock of Manager interface.
+type MockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerMockRecorder
+}
+
+// MockManagerMockRecorder is the mock recorder for MockManager.
+type MockManagerMockRecorder struct {
+	mock *MockManager
+}
+
+// NewMockManager creates a new mock instance.
+func NewMockManager(ctrl *gomock.Controller) *MockManager {
+	mock := &MockManager{ctrl: ctrl}
+	mock.recorder = &MockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManager) EXPECT() *MockManagerMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockManager) Delete(ctx context.Context, resourceType string, resourceID uint, environment string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, resourceType, resourceID, environment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockManagerMockRecorder) Delete(ctx, resourceType, resourceID, environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockManager)(nil).Delete), ctx, resourceType, resourceID, environment)
+}
+
+// Get mocks base method.
+func (m *MockManager) Get(ctx context.Context, resourceType string, resourceID uint, environment string) (*models.Quota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, resourceType, resourceID, environment)
+	ret0, _ := ret[0].(*models.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockManagerMockRecorder) Get(ctx, resourceType, resourceID, environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockManager)(nil).Get), ctx, resourceType, resourceID, environment)
+}
+
+// List mocks base method.
+func (m *MockManager) List(ctx context.Context) ([]*models.Quota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]*models.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockManagerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockManager)(nil).List), ctx)
+}
+
+// ListApplicable mocks base method.
+func (m *MockManager) ListApplicable(ctx context.Context, environment string, applicationID uint, groupIDs []uint) ([]*models.Quota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApplicable", ctx, environment, applicationID, groupIDs)
+	ret0, _ := ret[0].([]*models.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApplicable indicates an expected call of ListApplicable.
+func (mr *MockManagerMockRecorder) ListApplicable(ctx, environment, applicationID, groupIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplicable", reflect.TypeOf((*MockManager)(nil).ListApplicable), ctx, environment, applicationID, groupIDs)
+}
+
+// ListByResource mocks base method.
+func (m *MockManager) ListByResource(ctx context.Context, resourceType string, resourceID uint) ([]*models.Quota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByResource", ctx, resourceType, resourceID)
+	ret0, _ := ret[0].([]*models.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByResource indicates an expected call of ListByResource.
+func (mr *MockManagerMockRecorder) ListByResource(ctx, resourceType, resourceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByResource", reflect.TypeOf((*MockManager)(nil).ListByResource), ctx, resourceType, resourceID)
+}
+
+// ListClusterUsages mocks base method.
+func (m *MockManager) ListClusterUsages(ctx context.Context, clusterIDs []uint) (map[uint]*models.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterUsages", ctx, clusterIDs)
+	ret0, _ := ret[0].(map[uint]*models.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterUsages indicates an expected call of ListClusterUsages.
+func (mr *MockManagerMockRecorder) ListClusterUsages(ctx, clusterIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterUsages", reflect.TypeOf((*MockManager)(nil).ListClusterUsages), ctx, clusterIDs)
+}
+
+// Save mocks base method.
+func (m *MockManager) Save(ctx context.Context, quota *models.Quota) (*models.Quota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, quota)
+	ret0, _ := ret[0].(*models.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockManagerMockRecorder) Save(ctx, quota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockManager)(nil).Save), ctx, quota)
+}
+
+// SaveClusterUsage mocks base method.
+func (m *MockManager) SaveClusterUsage(ctx context.Context, clusterID uint, usage *models.Usage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveClusterUsage", ctx, clusterID, usage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveClusterUsage indicates an expected call of SaveClusterUsage.
+func (mr *MockManagerMockRecorder) SaveClusterUsage(ctx, clusterID, usage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveClusterUsage", reflect.TypeOf((*MockManager)(nil).SaveClusterUsage), ctx, clusterID, usage)
+}
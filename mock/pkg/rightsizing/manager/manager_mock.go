@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: manager.go
+
+// Package mock_manager is a generated GoMock package.
+package mock_manager
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/rightsizing/models"
+)
+
+// MockManager is a mock of Manager interface.
+type MockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerMockRecorder
+}
+
+// MockManagerMockRecorder is the mock recorder for MockManager.
+type MockManagerMockRecorder struct {
+	mock *MockManager
+}
+
+// NewMockManager creates a new mock instance.
+func NewMockManager(ctrl *gomock.Controller) *MockManager {
+	mock := &MockManager{ctrl: ctrl}
+	mock.recorder = &MockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManager) EXPECT() *MockManagerMockRecorder {
+	return m.recorder
+}
+
+// DeleteByClusterID mocks base method.
+func (m *MockManager) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByClusterID", ctx, clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByClusterID indicates an expected call of DeleteByClusterID.
+func (mr *MockManagerMockRecorder) DeleteByClusterID(ctx, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByClusterID", reflect.TypeOf((*MockManager)(nil).DeleteByClusterID), ctx, clusterID)
+}
+
+// GetByClusterID mocks base method.
+func (m *MockManager) GetByClusterID(ctx context.Context, clusterID uint) (*models.Recommendation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByClusterID", ctx, clusterID)
+	ret0, _ := ret[0].(*models.Recommendation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByClusterID indicates an expected call of GetByClusterID.
+func (mr *MockManagerMockRecorder) GetByClusterID(ctx, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByClusterID", reflect.TypeOf((*MockManager)(nil).GetByClusterID), ctx, clusterID)
+}
+
+// Save mocks base method.
+func (m *MockManager) Save(ctx context.Context, recommendation *models.Recommendation) (*models.Recommendation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, recommendation)
+	ret0, _ := ret[0].(*models.Recommendation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockManagerMockRecorder) Save(ctx, recommendation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockManager)(nil).Save), ctx, recommendation)
+}
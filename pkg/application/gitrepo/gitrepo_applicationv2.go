@@ -102,7 +102,8 @@ func NewApplicationGitlabRepo(ctx context.Context, gitlabLib gitlablib.Interface
 func (g appGitopsRepo) CreateOrUpdateApplication(ctx context.Context,
 	application string, req CreateOrUpdateRequest) error {
 	const op = "gitlab repo: create or update application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -223,7 +224,8 @@ func (g appGitopsRepo) CreateOrUpdateApplication(ctx context.Context,
 
 func (g appGitopsRepo) GetApplication(ctx context.Context, application, environment string) (*GetResponse, error) {
 	const op = "gitlab repo: get application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get data from gitlab
 	gid := fmt.Sprintf("%v/%v", g.applicationsGroup.FullPath, application)
@@ -292,7 +294,8 @@ func (g appGitopsRepo) GetApplication(ctx context.Context, application, environm
 
 func (g appGitopsRepo) HardDeleteApplication(ctx context.Context, application string) error {
 	const op = "gitlab repo: hard delete application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	gid := fmt.Sprintf("%v/%v", g.applicationsGroup.FullPath, application)
 	return g.gitlabLib.DeleteGroup(ctx, gid)
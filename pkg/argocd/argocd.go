@@ -33,6 +33,7 @@ import (
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
@@ -181,11 +182,11 @@ const (
 var (
 	_client = &retryablehttp.Client{
 		HTTPClient: &http.Client{
-			Transport: &http.Transport{
+			Transport: trace.NewTransport("argocd", &http.Transport{
 				TLSClientConfig: &tls.Config{
 					InsecureSkipVerify: true,
 				},
-			},
+			}),
 			Timeout: _timeout,
 		},
 		RetryMax:     _retry,
@@ -235,7 +236,8 @@ func (h *helper) AssembleArgoApplication(name, namespace, gitRepoURL, server str
 
 func (h *helper) CreateApplication(ctx context.Context, manifest []byte) (err error) {
 	const op = "argo: create application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	url := h.URL + "/api/v1/applications?validate=false&upsert=false"
 	resp, err := h.sendHTTPRequest(ctx, http.MethodPost, url, bytes.NewReader(manifest))
@@ -254,7 +256,8 @@ func (h *helper) CreateApplication(ctx context.Context, manifest []byte) (err er
 
 func (h *helper) DeployApplication(ctx context.Context, application string, revision string) (err error) {
 	const op = "argo: deploy application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	url := fmt.Sprintf("%v/api/v1/applications/%v/sync", h.URL, application)
 	req := DeployApplicationRequest{
@@ -282,7 +285,8 @@ func (h *helper) DeployApplication(ctx context.Context, application string, revi
 
 func (h *helper) DeleteApplication(ctx context.Context, application string) (err error) {
 	const op = "argo: delete application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	url := fmt.Sprintf("%v/api/v1/applications/%v?cascade=true", h.URL, application)
 	resp, err := h.sendHTTPRequest(ctx, http.MethodDelete, url, nil)
@@ -303,7 +307,8 @@ func (h *helper) DeleteApplication(ctx context.Context, application string) (err
 
 func (h *helper) WaitApplication(ctx context.Context, cluster string, uid string, status int) (err error) {
 	const op = "argo: wait application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	waitError := fmt.Errorf("continue to wait")
 
@@ -353,7 +358,8 @@ func (h *helper) WaitApplication(ctx context.Context, cluster string, uid string
 func (h *helper) GetApplication(ctx context.Context,
 	application string) (applicationCRD *v1alpha1.Application, err error) {
 	const op = "argo: get application"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	url := fmt.Sprintf("%v/api/v1/applications/%v", h.URL, application)
 	return h.getOrRefreshApplication(ctx, url)
@@ -362,7 +368,8 @@ func (h *helper) GetApplication(ctx context.Context,
 func (h *helper) RefreshApplication(ctx context.Context,
 	application string) (applicationCRD *v1alpha1.Application, err error) {
 	const op = "argo: refresh application "
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	url := fmt.Sprintf("%v/api/v1/applications/%v?refresh=normal", h.URL, application)
 	return h.getOrRefreshApplication(ctx, url)
@@ -398,7 +405,8 @@ func (h *helper) getOrRefreshApplication(ctx context.Context,
 func (h *helper) GetApplicationTree(ctx context.Context, application string) (
 	tree *v1alpha1.ApplicationTree, err error) {
 	const op = "argo: get application tree"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	url := fmt.Sprintf("%v/api/v1/applications/%v/resource-tree", h.URL, application)
 	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, url, nil)
@@ -430,7 +438,8 @@ func (h *helper) GetApplicationTree(ctx context.Context, application string) (
 func (h *helper) GetApplicationResource(ctx context.Context, application string,
 	gvk ResourceParams, resource interface{}) (err error) {
 	const op = "argo: get application resource"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	url := fmt.Sprintf("%v/api/v1/applications/%v/resource?namespace=%v&resourceName=%v&group=%v&version=%v&kind=%v",
 		h.URL, application, gvk.Namespace, gvk.ResourceName, gvk.Group, gvk.Version, gvk.Kind)
@@ -477,7 +486,8 @@ func (h *helper) GetApplicationResource(ctx context.Context, application string,
 func (h *helper) ListResourceEvents(ctx context.Context, application string, param EventParam) (
 	eventList *corev1.EventList, err error) {
 	const op = "argo: list resource events"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	url := fmt.Sprintf("%v/api/v1/applications/%v/events?resourceUID=%v&resourceNamespace=%v&resourceName=%v",
 		h.URL, application, param.ResourceUID, param.ResourceNamespace, param.ResourceName)
@@ -505,7 +515,8 @@ func (h *helper) ListResourceEvents(ctx context.Context, application string, par
 
 func (h *helper) ResumeRollout(ctx context.Context, application string) (err error) {
 	const op = "argo: resume rollout"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	app, err := h.GetApplication(ctx, application)
 	if err != nil {
@@ -533,7 +544,8 @@ func (h *helper) ResumeRollout(ctx context.Context, application string) (err err
 func (h *helper) GetContainerLog(ctx context.Context, application string,
 	param ContainerLogParams) (lc <-chan ContainerLog, ec <-chan error, err error) {
 	const op = "argo: get container log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	format := "%v/api/v1/applications/%v/pods/%v/logs?container=%v&follow=false&namespace=%v&tailLines=%v"
 	url := fmt.Sprintf(format, h.URL, application, param.PodName, param.ContainerName, param.Namespace, param.TailLines)
@@ -24,32 +24,79 @@ import (
 const _default = "default"
 
 type Factory interface {
-	GetArgoCD(environment string) (ArgoCD, error)
+	// GetArgoCD returns the argo cd mapped to the environment in the region,
+	// falls back to the one mapped to the environment, and then the default one
+	GetArgoCD(environment, region string) (ArgoCD, error)
+	// SetArgoCD maps an argo cd to the environment in the region, region can be empty
+	SetArgoCD(environment, region string, argoCDConf *argocd.ArgoCD)
+	// DeleteArgoCD removes the argo cd mapped to the environment in the region
+	DeleteArgoCD(environment, region string)
+	// Reload replaces the argo cds loaded from argoCDMapper, the ones set by SetArgoCD are kept
+	Reload(argoCDMapper argocd.Mapper)
+	// ReloadOverrides replaces all the argo cds set by SetArgoCD with the ones in argoCDMapper
+	ReloadOverrides(argoCDMapper argocd.Mapper)
 }
 
 type factory struct {
-	cache *sync.Map
+	sync.RWMutex
+	// mapped are argo cds loaded from argoCDMapper
+	mapped map[string]ArgoCD
+	// overrides are argo cds set at runtime, they take precedence over the mapped ones
+	overrides map[string]ArgoCD
 }
 
 func NewFactory(argoCDMapper argocd.Mapper) Factory {
-	cache := &sync.Map{}
-	for env, argoCDConf := range argoCDMapper {
-		argoCD := NewArgoCD(argoCDConf.URL, argoCDConf.Token, argoCDConf.Namespace)
-		cache.Store(env, argoCD)
+	f := &factory{
+		overrides: make(map[string]ArgoCD),
 	}
-	return &factory{
-		cache: cache,
+	f.Reload(argoCDMapper)
+	return f
+}
+
+func (f *factory) Reload(argoCDMapper argocd.Mapper) {
+	mapped := make(map[string]ArgoCD, len(argoCDMapper))
+	// key of argoCDMapper is environment or environment/region
+	for key, argoCDConf := range argoCDMapper {
+		mapped[key] = NewArgoCD(argoCDConf.URL, argoCDConf.Token, argoCDConf.Namespace)
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.mapped = mapped
+}
+
+func (f *factory) ReloadOverrides(argoCDMapper argocd.Mapper) {
+	overrides := make(map[string]ArgoCD, len(argoCDMapper))
+	for key, argoCDConf := range argoCDMapper {
+		overrides[key] = NewArgoCD(argoCDConf.URL, argoCDConf.Token, argoCDConf.Namespace)
 	}
+	f.Lock()
+	defer f.Unlock()
+	f.overrides = overrides
 }
 
-func (f *factory) GetArgoCD(environment string) (ArgoCD, error) {
-	var ret interface{}
-	var ok bool
-	if ret, ok = f.cache.Load(environment); !ok {
-		// check and use default cd
-		if ret, ok = f.cache.Load(_default); !ok {
-			return nil, herrors.NewErrNotFound(herrors.ArgoCD, "default argo cd not found")
+func (f *factory) GetArgoCD(environment, region string) (ArgoCD, error) {
+	f.RLock()
+	defer f.RUnlock()
+	for _, key := range []string{argocd.Key(environment, region), environment, _default} {
+		if ret, ok := f.overrides[key]; ok {
+			return ret, nil
+		}
+		if ret, ok := f.mapped[key]; ok {
+			return ret, nil
 		}
 	}
-	return ret.(ArgoCD), nil
+	return nil, herrors.NewErrNotFound(herrors.ArgoCD, "default argo cd not found")
+}
+
+func (f *factory) SetArgoCD(environment, region string, argoCDConf *argocd.ArgoCD) {
+	argoCD := NewArgoCD(argoCDConf.URL, argoCDConf.Token, argoCDConf.Namespace)
+	f.Lock()
+	defer f.Unlock()
+	f.overrides[argocd.Key(environment, region)] = argoCD
+}
+
+func (f *factory) DeleteArgoCD(environment, region string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.overrides, argocd.Key(environment, region))
 }
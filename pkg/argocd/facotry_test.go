@@ -38,17 +38,47 @@ func Test(t *testing.T) {
 	factory := NewFactory(argoCDMapper)
 	assert.NotNil(t, factory)
 
-	argoCD, err := factory.GetArgoCD("test")
+	argoCD, err := factory.GetArgoCD("test", "")
 	assert.Nil(t, err)
 	assert.NotNil(t, argoCD)
 	assert.Equal(t, argoCD, NewArgoCD(argoCDTest.URL, argoCDTest.Token, argoCDTest.Namespace))
 
-	argoCD, err = factory.GetArgoCD("reg")
+	argoCD, err = factory.GetArgoCD("reg", "hz")
 	assert.Nil(t, err)
 	assert.NotNil(t, argoCD)
 	assert.Equal(t, argoCD, NewArgoCD(argoCDReg.URL, argoCDReg.Token, argoCDReg.Namespace))
 
-	argoCD, err = factory.GetArgoCD("not-exists")
+	argoCD, err = factory.GetArgoCD("not-exists", "")
 	assert.Nil(t, argoCD)
 	assert.NotNil(t, err)
+
+	// region specific argo cd takes precedence over the environment one
+	argoCDRegHz := &argocd.ArgoCD{
+		URL:       "http://reg-hz.argo.com",
+		Token:     "token2",
+		Namespace: "argocd",
+	}
+	factory.SetArgoCD("reg", "hz", argoCDRegHz)
+	argoCD, err = factory.GetArgoCD("reg", "hz")
+	assert.Nil(t, err)
+	assert.Equal(t, argoCD, NewArgoCD(argoCDRegHz.URL, argoCDRegHz.Token, argoCDRegHz.Namespace))
+
+	argoCD, err = factory.GetArgoCD("reg", "js")
+	assert.Nil(t, err)
+	assert.Equal(t, argoCD, NewArgoCD(argoCDReg.URL, argoCDReg.Token, argoCDReg.Namespace))
+
+	factory.DeleteArgoCD("reg", "hz")
+	argoCD, err = factory.GetArgoCD("reg", "hz")
+	assert.Nil(t, err)
+	assert.Equal(t, argoCD, NewArgoCD(argoCDReg.URL, argoCDReg.Token, argoCDReg.Namespace))
+
+	// overrides are replaced as a whole when reloaded from db
+	factory.SetArgoCD("reg", "js", argoCDRegHz)
+	factory.ReloadOverrides(argocd.Mapper{"reg/hz": argoCDRegHz})
+	argoCD, err = factory.GetArgoCD("reg", "hz")
+	assert.Nil(t, err)
+	assert.Equal(t, argoCD, NewArgoCD(argoCDRegHz.URL, argoCDRegHz.Token, argoCDRegHz.Namespace))
+	argoCD, err = factory.GetArgoCD("reg", "js")
+	assert.Nil(t, err)
+	assert.Equal(t, argoCD, NewArgoCD(argoCDReg.URL, argoCDReg.Token, argoCDReg.Namespace))
 }
@@ -100,7 +100,8 @@ func (argoServer *ArgoServer) GetRepository(w http.ResponseWriter, r *http.Reque
 
 	var err error
 	ctx := log.WithContext(context.Background(), "GetRepository")
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	vars := mux.Vars(r)
 	repository := vars["repository"]
@@ -148,7 +149,8 @@ func (argoServer *ArgoServer) CreateApplication(w http.ResponseWriter, r *http.R
 
 	var err error
 	ctx := log.WithContext(context.Background(), "CreateApplication")
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -177,7 +179,8 @@ func (argoServer *ArgoServer) DeployApplication(w http.ResponseWriter, r *http.R
 	const op = "argo mock server: deploy application"
 
 	ctx := log.WithContext(context.Background(), "DeployApplication")
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	vars := mux.Vars(r)
 	application := vars["application"]
@@ -196,7 +199,8 @@ func (argoServer *ArgoServer) GetApplication(w http.ResponseWriter, r *http.Requ
 
 	var err error
 	ctx := log.WithContext(context.Background(), "GetApplication")
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	vars := mux.Vars(r)
 	application := vars["application"]
@@ -227,7 +231,8 @@ func (argoServer *ArgoServer) DeleteApplication(w http.ResponseWriter, r *http.R
 	const op = "argo mock server: delete application"
 
 	ctx := log.WithContext(context.Background(), "DeleteApplication")
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	vars := mux.Vars(r)
 	application := vars["application"]
@@ -435,7 +440,8 @@ func (argoServer *ArgoServer) ResumeRollout(w http.ResponseWriter, r *http.Reque
 	const op = "argo mock server: resume rollout"
 
 	ctx := log.WithContext(context.Background(), "ResumeRollout")
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	vars := mux.Vars(r)
 	application := vars["application"]
@@ -453,7 +459,8 @@ func (argoServer *ArgoServer) GetContainerLog(w http.ResponseWriter, r *http.Req
 	const op = "argo mock server: get container log"
 
 	ctx := log.WithContext(context.Background(), "GetContainerLog")
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	vars := mux.Vars(r)
 	application := vars["application"]
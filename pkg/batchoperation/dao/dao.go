@@ -0,0 +1,150 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/batchoperation/models"
+)
+
+type DAO interface {
+	// Create creates the batch operation with its items in a transaction
+	Create(ctx context.Context, operation *models.BatchOperation,
+		items []*models.BatchOperationItem) (*models.BatchOperation, error)
+	Get(ctx context.Context, id uint) (*models.BatchOperation, error)
+	List(ctx context.Context, query *q.Query) ([]*models.BatchOperation, int64, error)
+	// UpdateStatus updates the status of the batch operation if it's in one of the fromStatuses,
+	// and returns whether it's updated
+	UpdateStatus(ctx context.Context, id uint, status string, fromStatuses ...string) (bool, error)
+	ListItems(ctx context.Context, operationID uint) ([]*models.BatchOperationItem, error)
+	UpdateItem(ctx context.Context, item *models.BatchOperationItem) error
+	// CancelPendingItems marks pending items of the batch operation as cancelled
+	CancelPendingItems(ctx context.Context, operationID uint, message string) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, operation *models.BatchOperation,
+	items []*models.BatchOperationItem) (*models.BatchOperation, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(operation).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			item.BatchOperationID = operation.ID
+		}
+		if len(items) > 0 {
+			return tx.Create(&items).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.BatchOperationInDB, err.Error())
+	}
+	return operation, nil
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.BatchOperation, error) {
+	var operation models.BatchOperation
+	if err := d.db.WithContext(ctx).Where("id = ?", id).First(&operation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.BatchOperationInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.BatchOperationInDB, err.Error())
+	}
+	return &operation, nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.BatchOperation, int64, error) {
+	var (
+		operations []*models.BatchOperation
+		total      int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.BatchOperation{})
+	if query != nil {
+		for k, v := range query.Keywords {
+			switch k {
+			case common.BatchOperationQueryByUser:
+				statement = statement.Where("created_by = ?", v)
+			case common.BatchOperationQueryByStatus:
+				statement = statement.Where("status = ?", v)
+			}
+		}
+	} else {
+		query = &q.Query{}
+	}
+	if err := statement.Count(&total).Error; err != nil {
+		return nil, 0, herrors.NewErrListFailed(herrors.BatchOperationInDB, err.Error())
+	}
+	if err := statement.Order("id desc").Offset(query.Offset()).Limit(query.Limit()).
+		Find(&operations).Error; err != nil {
+		return nil, 0, herrors.NewErrListFailed(herrors.BatchOperationInDB, err.Error())
+	}
+	return operations, total, nil
+}
+
+func (d *dao) UpdateStatus(ctx context.Context, id uint, status string, fromStatuses ...string) (bool, error) {
+	statement := d.db.WithContext(ctx).Model(&models.BatchOperation{}).Where("id = ?", id)
+	if len(fromStatuses) > 0 {
+		statement = statement.Where("status in ?", fromStatuses)
+	}
+	result := statement.Update("status", status)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.BatchOperationInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *dao) ListItems(ctx context.Context, operationID uint) ([]*models.BatchOperationItem, error) {
+	var items []*models.BatchOperationItem
+	if err := d.db.WithContext(ctx).Where("batch_operation_id = ?", operationID).
+		Order("id").Find(&items).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.BatchOperationItemInDB, err.Error())
+	}
+	return items, nil
+}
+
+func (d *dao) UpdateItem(ctx context.Context, item *models.BatchOperationItem) error {
+	if err := d.db.WithContext(ctx).Model(item).Where("id = ?", item.ID).
+		Select("status", "pipelinerun_id", "message").Updates(item).Error; err != nil {
+		return herrors.NewErrUpdateFailed(herrors.BatchOperationItemInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) CancelPendingItems(ctx context.Context, operationID uint, message string) error {
+	if err := d.db.WithContext(ctx).Model(&models.BatchOperationItem{}).
+		Where("batch_operation_id = ? and status = ?", operationID, models.StatusPending).
+		Updates(map[string]interface{}{
+			"status":  models.StatusCancelled,
+			"message": message,
+		}).Error; err != nil {
+		return herrors.NewErrUpdateFailed(herrors.BatchOperationItemInDB, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/batchoperation/dao"
+	"github.com/horizoncd/horizon/pkg/batchoperation/models"
+)
+
+type Manager interface {
+	// Create creates the batch operation with its items in a transaction
+	Create(ctx context.Context, operation *models.BatchOperation,
+		items []*models.BatchOperationItem) (*models.BatchOperation, error)
+	Get(ctx context.Context, id uint) (*models.BatchOperation, error)
+	List(ctx context.Context, query *q.Query) ([]*models.BatchOperation, int64, error)
+	// UpdateStatus updates the status of the batch operation if it's in one of the fromStatuses,
+	// and returns whether it's updated
+	UpdateStatus(ctx context.Context, id uint, status string, fromStatuses ...string) (bool, error)
+	ListItems(ctx context.Context, operationID uint) ([]*models.BatchOperationItem, error)
+	UpdateItem(ctx context.Context, item *models.BatchOperationItem) error
+	// CancelPendingItems marks pending items of the batch operation as cancelled
+	CancelPendingItems(ctx context.Context, operationID uint, message string) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, operation *models.BatchOperation,
+	items []*models.BatchOperationItem) (*models.BatchOperation, error) {
+	return m.dao.Create(ctx, operation, items)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.BatchOperation, error) {
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) ([]*models.BatchOperation, int64, error) {
+	return m.dao.List(ctx, query)
+}
+
+func (m *manager) UpdateStatus(ctx context.Context, id uint, status string, fromStatuses ...string) (bool, error) {
+	return m.dao.UpdateStatus(ctx, id, status, fromStatuses...)
+}
+
+func (m *manager) ListItems(ctx context.Context, operationID uint) ([]*models.BatchOperationItem, error) {
+	return m.dao.ListItems(ctx, operationID)
+}
+
+func (m *manager) UpdateItem(ctx context.Context, item *models.BatchOperationItem) error {
+	return m.dao.UpdateItem(ctx, item)
+}
+
+func (m *manager) CancelPendingItems(ctx context.Context, operationID uint, message string) error {
+	return m.dao.CancelPendingItems(ctx, operationID, message)
+}
@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	ActionRestart  = "restart"
+	ActionDeploy   = "deploy"
+	ActionRollback = "rollback"
+	ActionFree     = "free"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// BatchOperation executes an action on the selected clusters
+type BatchOperation struct {
+	global.Model
+
+	Action string
+	// Selector is the json of the selector that the clusters are selected by
+	Selector    string
+	Concurrency uint
+	// StopOnFailure stops the remaining clusters once an action fails
+	StopOnFailure bool
+	Status        string
+	CreatedBy     uint
+}
+
+// BatchOperationItem is the action on one cluster of a batch operation
+type BatchOperationItem struct {
+	global.Model
+
+	BatchOperationID uint
+	ClusterID        uint
+	ClusterName      string
+	Status           string
+	PipelinerunID    uint
+	Message          string
+}
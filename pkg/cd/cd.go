@@ -32,9 +32,11 @@ import (
 	"github.com/horizoncd/horizon/pkg/argocd"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
-	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/podusage"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/traffic"
 	"github.com/horizoncd/horizon/pkg/util/kube"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
@@ -45,6 +47,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes"
 )
@@ -105,21 +108,30 @@ type cd struct {
 }
 
 func NewCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
-	argoCDMapper argocdconf.Mapper, targetRevision string) CD {
+	argoCDFactory argocd.Factory, targetRevision string) CD {
 	return &cd{
 		kubeClientFactory: kubeclient.Fty,
 		informerFactories: informerFactories,
-		factory:           argocd.NewFactory(argoCDMapper),
+		factory:           argoCDFactory,
 		clusterGitRepo:    clusterGitRepo,
 		targetRevision:    targetRevision,
 	}
 }
 
+// regionNameOf returns the name of region entity, or empty if it's not provided
+func regionNameOf(regionEntity *regionmodels.RegionEntity) string {
+	if regionEntity == nil || regionEntity.Region == nil {
+		return ""
+	}
+	return regionEntity.Name
+}
+
 func (c *cd) CreateCluster(ctx context.Context, params *CreateClusterParams) (err error) {
 	const op = "cd: create cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
-	argo, err := c.factory.GetArgoCD(params.Environment)
+	argo, err := c.factory.GetArgoCD(params.Environment, regionNameOf(params.RegionEntity))
 	if err != nil {
 		return err
 	}
@@ -149,9 +161,10 @@ func (c *cd) CreateCluster(ctx context.Context, params *CreateClusterParams) (er
 
 func (c *cd) DeployCluster(ctx context.Context, params *DeployClusterParams) (err error) {
 	const op = "cd: deploy cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
-	argo, err := c.factory.GetArgoCD(params.Environment)
+	argo, err := c.factory.GetArgoCD(params.Environment, params.Region)
 	if err != nil {
 		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
 	}
@@ -161,9 +174,10 @@ func (c *cd) DeployCluster(ctx context.Context, params *DeployClusterParams) (er
 
 func (c *cd) DeleteCluster(ctx context.Context, params *DeleteClusterParams) (err error) {
 	const op = "cd: delete cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
-	argo, err := c.factory.GetArgoCD(params.Environment)
+	argo, err := c.factory.GetArgoCD(params.Environment, params.Region)
 	if err != nil {
 		return err
 	}
@@ -189,9 +203,10 @@ func (c *cd) DeleteCluster(ctx context.Context, params *DeleteClusterParams) (er
 func (c *cd) GetResourceTree(ctx context.Context,
 	params *GetResourceTreeParams) ([]ResourceNode, error) {
 	const op = "cd: get resource tree"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
-	argo, err := c.factory.GetArgoCD(params.Environment)
+	argo, err := c.factory.GetArgoCD(params.Environment, regionNameOf(params.RegionEntity))
 	if err != nil {
 		return nil, err
 	}
@@ -208,6 +223,7 @@ func (c *cd) GetResourceTree(ctx context.Context,
 		return nil, err
 	}
 	gt := getter.New(pd)
+	pods := make([]corev1.Pod, 0)
 	for _, node := range resourceTreeInArgo.Nodes {
 		n := ResourceNode{ResourceNode: node}
 		if n.Kind == "Pod" {
@@ -230,23 +246,108 @@ func (c *cd) GetResourceTree(ctx context.Context,
 			}
 			t := Compact(podDetail)
 			n.PodDetail = &t
+			pods = append(pods, podDetail)
+		} else {
+			c.fillRunsAndNodeRollouts(ctx, params.RegionEntity.ID, &n)
 		}
 		resourceTree = append(resourceTree, n)
 	}
+	if params.WithUsage {
+		c.fillPodUsage(ctx, params.RegionEntity, resourceTree, pods)
+	}
 
 	return resourceTree, nil
 }
 
+// fillPodUsage fills the usage of pods, and the usage aggregated per revision into the parents of pods,
+// usage is queried from the metrics api of the region, or the prometheus of the region if it fails
+func (c *cd) fillPodUsage(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	resourceTree []ResourceNode, pods []corev1.Pod) {
+	if len(pods) == 0 {
+		return
+	}
+	sources := make([]podusage.Source, 0, 2)
+	_ = c.informerFactories.GetDynamicClientSet(regionEntity.ID, func(clientset dynamic.Interface) error {
+		sources = append(sources, podusage.NewMetricsAPI(clientset))
+		return nil
+	})
+	if regionEntity.PrometheusURL != "" {
+		prometheus, err := podusage.NewPrometheus(regionEntity.PrometheusURL)
+		if err != nil {
+			log.Warningf(ctx, "invalid prometheus url of region %s: %v", regionEntity.Name, err)
+		} else {
+			sources = append(sources, prometheus)
+		}
+	}
+	usages := podusage.Get(ctx, pods, sources...)
+
+	indexes := make(map[string]int, len(resourceTree))
+	for i := range resourceTree {
+		indexes[resourceTree[i].UID] = i
+	}
+	revisions := make(map[string][]*podusage.Pod)
+	for i := range resourceTree {
+		node := &resourceTree[i]
+		if node.Kind != "Pod" {
+			continue
+		}
+		usage, ok := usages[node.Name]
+		if !ok {
+			continue
+		}
+		node.Usage = usage
+		for _, parent := range node.ParentRefs {
+			revisions[parent.UID] = append(revisions[parent.UID], usage)
+		}
+	}
+	for uid, usages := range revisions {
+		if i, ok := indexes[uid]; ok {
+			resourceTree[i].RevisionUsage = podusage.Summarize(usages...)
+		}
+	}
+}
+
+// fillRunsAndNodeRollouts fills the runs of batch workloads and the rollouts per node of daemon workloads
+func (c *cd) fillRunsAndNodeRollouts(ctx context.Context, regionID uint, n *ResourceNode) {
+	ability, err := workload.GetAbility(schema.GroupKind{Group: n.Group, Kind: n.Kind})
+	if err != nil {
+		return
+	}
+	runsLister, isRunsLister := ability.(workload.RunsLister)
+	rolloutGetter, isRolloutGetter := ability.(workload.NodeRolloutGetter)
+	if !isRunsLister && !isRolloutGetter {
+		return
+	}
+	err = c.informerFactories.GetDynamicFactory(regionID,
+		func(factory dynamicinformer.DynamicSharedInformerFactory) error {
+			if isRunsLister {
+				if n.Runs, err = runsLister.ListRuns(&n.ResourceNode, factory); err != nil {
+					return err
+				}
+			}
+			if isRolloutGetter {
+				if n.NodeRollouts, err = rolloutGetter.GetNodeRollouts(&n.ResourceNode, factory); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		log.Warningf(ctx, "failed to get runs or node rollouts of %s(%s): %v", n.Name, n.Kind, err)
+	}
+}
+
 func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
 	const op = "cd: get step"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
 	if err != nil {
 		return nil, err
 	}
 
-	argo, err := c.factory.GetArgoCD(params.Environment)
+	argo, err := c.factory.GetArgoCD(params.Environment, regionNameOf(params.RegionEntity))
 	if err != nil {
 		return nil, err
 	}
@@ -259,6 +360,7 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 
 	ifContinue := true
 	step := (*workload.Step)(nil)
+	namespace := ""
 	c.traverseResourceTree(resourceTreeInArgo, func(node *ResourceTreeNode) bool {
 		if !ifContinue {
 			return ifContinue
@@ -274,6 +376,7 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 				return true
 			}
 
+			namespace = node.Namespace
 			ifContinue = false
 			return false
 		})
@@ -291,6 +394,15 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 		}, nil
 	}
 
+	// traffic weights exist only if the cluster is routed by a service mesh or gateway
+	trafficWeights, err := traffic.Get(ctx, kubeClient.Dynamic, namespace, params.Cluster)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			log.Warningf(ctx, "failed to get traffic of cluster %s: %v", params.Cluster, err)
+		}
+		trafficWeights = nil
+	}
+
 	return &Step{
 		Index:        step.Index,
 		Total:        step.Total,
@@ -298,6 +410,8 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 		ManualPaused: step.ManualPaused,
 		AutoPromote:  step.AutoPromote,
 		Extra:        step.Extra,
+		BlueGreen:    step.BlueGreen,
+		Traffic:      trafficWeights,
 	}, nil
 }
 
@@ -305,9 +419,10 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 func (c *cd) GetClusterState(ctx context.Context,
 	params *GetClusterStateV2Params) (*ClusterStateV2, error) {
 	const op = "cd: get cluster status"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
-	argo, err := c.factory.GetArgoCD(params.Environment)
+	argo, err := c.factory.GetArgoCD(params.Environment, regionNameOf(params.RegionEntity))
 	if err != nil {
 		return nil, err
 	}
@@ -394,9 +509,10 @@ func (c *cd) GetClusterState(ctx context.Context,
 func (c *cd) GetClusterStateV1(ctx context.Context,
 	params *GetClusterStateParams) (clusterState *ClusterState, err error) {
 	const op = "cd: get cluster status"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
-	argo, err := c.factory.GetArgoCD(params.Environment)
+	argo, err := c.factory.GetArgoCD(params.Environment, regionNameOf(params.RegionEntity))
 	if err != nil {
 		return nil, err
 	}
@@ -564,7 +680,8 @@ func (c *cd) GetClusterStateV1(ctx context.Context,
 func (c *cd) GetPodEvents(ctx context.Context,
 	params *GetPodEventsParams) (events []Event, err error) {
 	const op = "cd: get cluster pod events"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
 	if err != nil {
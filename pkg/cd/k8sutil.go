@@ -30,12 +30,14 @@ import (
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/traffic"
 	"github.com/horizoncd/horizon/pkg/util/kube"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"github.com/horizoncd/horizon/pkg/workload"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -51,6 +53,14 @@ type K8sUtil interface {
 	GetPodContainers(ctx context.Context, params *GetPodParams) ([]ContainerDetail, error)
 	GetPod(ctx context.Context, params *GetPodParams) (*corev1.Pod, error)
 	GetContainerLog(ctx context.Context, params *GetContainerLogParams) (<-chan string, error)
+	// GetClusterLogs streams the logs of the cluster's containers concurrently, lines are prefixed with pod names
+	GetClusterLogs(ctx context.Context, params *GetClusterLogsParams) (<-chan string, error)
+	// ArchiveClusterLogs writes the logs of the cluster's containers to w as a zip archive
+	ArchiveClusterLogs(ctx context.Context, params *GetClusterLogsParams, w io.Writer) error
+	// GetTraffic gets the traffic weights of the cluster from its VirtualService or HTTPRoute
+	GetTraffic(ctx context.Context, params *GetTrafficParams) (*traffic.Traffic, error)
+	// ShiftTraffic updates the traffic weights and the header route of the cluster
+	ShiftTraffic(ctx context.Context, params *ShiftTrafficParams) (*traffic.Traffic, error)
 }
 
 type util struct {
@@ -157,9 +167,26 @@ func (e *util) ExecuteAction(ctx context.Context,
 				fmt.Sprintf("failed to get %s(%s)", params.ResourceName, params.GVR.String()))
 		}
 
-		workload.LoopAbilities(func(workload workload.Workload) bool {
-			if workload.MatchGK(un.GroupVersionKind().GroupKind()) {
-				un, err = workload.Action(params.Action, un)
+		var (
+			spawnedGVR schema.GroupVersionResource
+			spawned    *unstructured.Unstructured
+			handled    bool
+		)
+		workload.LoopAbilities(func(ability workload.Workload) bool {
+			if ability.MatchGK(un.GroupVersionKind().GroupKind()) {
+				if updater, ok := ability.(workload.DependentsUpdater); ok {
+					handled, err = updater.UpdateDependents(ctx, params.Action, un, clientset)
+					if err != nil || handled {
+						return false
+					}
+				}
+				if spawner, ok := ability.(workload.Spawner); ok {
+					spawnedGVR, spawned, err = spawner.Spawn(params.Action, un)
+					if err != nil || spawned != nil {
+						return false
+					}
+				}
+				un, err = ability.Action(params.Action, un)
 				return false
 			}
 			return true
@@ -169,14 +196,32 @@ func (e *util) ExecuteAction(ctx context.Context,
 				params.Action, params.ResourceName, params.GVR.String())
 		}
 
-		un, err = clientset.Resource(params.GVR).Namespace(params.Namespace).
-			Update(ctx, un, metav1.UpdateOptions{})
-		log.Debugf(ctx, "update %s(%s) with %s: %v", params.ResourceName,
-			params.GVR.String(), params.Action, un)
-		if err != nil {
-			return herrors.NewErrUpdateFailed(herrors.ResourceInK8S,
-				fmt.Sprintf("failed to update gvr(%s), ns(%s), name(%s)",
-					params.GVR.String(), params.Namespace, un.GetName()))
+		if handled {
+			log.Debugf(ctx, "update dependents of %s(%s) with %s", params.ResourceName,
+				params.GVR.String(), params.Action)
+		} else if spawned != nil {
+			spawned, err = clientset.Resource(spawnedGVR).Namespace(params.Namespace).
+				Create(ctx, spawned, metav1.CreateOptions{})
+			if err != nil {
+				return herrors.NewErrCreateFailed(herrors.ResourceInK8S,
+					fmt.Sprintf("failed to create gvr(%s), ns(%s) for %s: %v",
+						spawnedGVR.String(), params.Namespace, params.ResourceName, err))
+			}
+			log.Debugf(ctx, "create %s(%s) by %s of %s", spawned.GetName(),
+				spawnedGVR.String(), params.Action, params.ResourceName)
+		} else {
+			un, err = clientset.Resource(params.GVR).Namespace(params.Namespace).
+				Update(ctx, un, metav1.UpdateOptions{})
+			log.Debugf(ctx, "update %s(%s) with %s: %v", params.ResourceName,
+				params.GVR.String(), params.Action, un)
+			if err != nil {
+				return herrors.NewErrUpdateFailed(herrors.ResourceInK8S,
+					fmt.Sprintf("failed to update gvr(%s), ns(%s), name(%s)",
+						params.GVR.String(), params.Namespace, un.GetName()))
+			}
+		}
+		if params.SkipEvent {
+			return nil
 		}
 		bts, err := json.Marshal(map[string]interface{}{
 			"action":       params.Action,
@@ -229,7 +274,8 @@ func (e *util) GetPod(ctx context.Context,
 
 func (e *util) Exec(ctx context.Context, params *ExecParams) (resp map[string]ExecResp, err error) {
 	const op = "cd: shell exec"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	config, err := e.informerFactories.GetRestConfig(params.RegionEntity.ID)
 	if err != nil {
@@ -323,3 +369,19 @@ func (c *cd) traverseResourceTree(resourceTree *applicationV1alpha1.ApplicationT
 		}
 	}
 }
+
+func (e *util) GetTraffic(ctx context.Context, params *GetTrafficParams) (result *traffic.Traffic, err error) {
+	err = e.informerFactories.GetDynamicClientSet(params.RegionEntity.ID, func(clientset dynamic.Interface) error {
+		result, err = traffic.Get(ctx, clientset, params.Namespace, params.Cluster)
+		return err
+	})
+	return result, err
+}
+
+func (e *util) ShiftTraffic(ctx context.Context, params *ShiftTrafficParams) (result *traffic.Traffic, err error) {
+	err = e.informerFactories.GetDynamicClientSet(params.RegionEntity.ID, func(clientset dynamic.Interface) error {
+		result, err = traffic.Apply(ctx, clientset, params.Namespace, params.Cluster, params.Shift)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,288 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/workload"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	gvrPod = schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	}
+	gvrReplicaSet = schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "replicasets",
+	}
+)
+
+// LogTarget is a container whose logs are aggregated
+type LogTarget struct {
+	Pod       string
+	Container string
+	// Prefix is prepended to the lines of the container
+	Prefix string
+}
+
+func (e *util) GetClusterLogs(ctx context.Context, params *GetClusterLogsParams) (<-chan string, error) {
+	targets, err := e.listLogTargets(params)
+	if err != nil {
+		return nil, err
+	}
+
+	logC := make(chan string)
+	err = e.informerFactories.GetClientSet(params.RegionEntity.ID, func(clientset kubernetes.Interface) error {
+		var wg sync.WaitGroup
+		for _, target := range targets {
+			wg.Add(1)
+			go func(target LogTarget) {
+				defer wg.Done()
+				send := func(line string) bool {
+					select {
+					case logC <- fmt.Sprintf("[%s] %s", target.Prefix, line):
+						return true
+					case <-ctx.Done():
+						return false
+					}
+				}
+				if err := readContainerLog(ctx, clientset, params, target, send); err != nil {
+					send(fmt.Sprintf("%v\n", err))
+				}
+			}(target)
+		}
+		go func() {
+			wg.Wait()
+			close(logC)
+		}()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logC, nil
+}
+
+func (e *util) ArchiveClusterLogs(ctx context.Context, params *GetClusterLogsParams, w io.Writer) error {
+	targets, err := e.listLogTargets(params)
+	if err != nil {
+		return err
+	}
+
+	// logs in archive are not followed
+	archiveParams := *params
+	archiveParams.Follow = false
+
+	// the clientset is taken out of the informers, so that their lock is not held while streaming
+	var clientset kubernetes.Interface
+	err = e.informerFactories.GetClientSet(params.RegionEntity.ID, func(c kubernetes.Interface) error {
+		clientset = c
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, target := range targets {
+		file, err := zw.Create(fmt.Sprintf("%s/%s.log", target.Pod, target.Container))
+		if err != nil {
+			return err
+		}
+		var writeErr error
+		err = readContainerLog(ctx, clientset, &archiveParams, target, func(line string) bool {
+			_, writeErr = io.WriteString(file, line)
+			return writeErr == nil
+		})
+		if writeErr != nil {
+			return writeErr
+		}
+		if err != nil {
+			// the failure is kept in the archive, so that logs of other containers are still available
+			if _, err := io.WriteString(file, err.Error()); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+// listLogTargets lists the containers of the cluster's pods from the informers
+func (e *util) listLogTargets(params *GetClusterLogsParams) ([]LogTarget, error) {
+	var (
+		pods        []corev1.Pod
+		replicaSets []appsv1.ReplicaSet
+	)
+	selector := labels.SelectorFromSet(labels.Set{common.ClusterClusterLabelKey: params.Cluster})
+	err := e.informerFactories.GetDynamicFactory(params.RegionEntity.ID,
+		func(factory dynamicinformer.DynamicSharedInformerFactory) error {
+			objs, err := factory.ForResource(gvrPod).Lister().ByNamespace(params.Namespace).List(selector)
+			if err != nil {
+				return herrors.NewErrGetFailed(herrors.PodsInK8S, err.Error())
+			}
+			pods = workload.ObjIntoPod(objs...)
+			if params.Revision == "" {
+				return nil
+			}
+
+			objs, err = factory.ForResource(gvrReplicaSet).Lister().ByNamespace(params.Namespace).List(selector)
+			if err != nil {
+				return herrors.NewErrGetFailed(herrors.ReplicasSetInK8S, err.Error())
+			}
+			for _, obj := range objs {
+				var rs appsv1.ReplicaSet
+				if err := workload.ObjUnmarshal(obj, &rs); err != nil {
+					continue
+				}
+				replicaSets = append(replicaSets, rs)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := selectLogTargets(pods, replicaSets, params.Revision, params.Container)
+	if len(targets) == 0 {
+		return nil, herrors.NewErrNotFound(herrors.PodsInK8S,
+			fmt.Sprintf("no container of cluster %s matches", params.Cluster))
+	}
+	return targets, nil
+}
+
+// selectLogTargets selects containers of pods by revision and container name,
+// pods are selected regardless of revision if revision is empty, and so is container
+func selectLogTargets(pods []corev1.Pod, replicaSets []appsv1.ReplicaSet,
+	revision, container string) []LogTarget {
+	revisions := make(map[string]string, len(replicaSets))
+	for i := range replicaSets {
+		revisions[replicaSets[i].Name] = getRevision(&replicaSets[i])
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	targets := make([]LogTarget, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Name == "" {
+			continue
+		}
+		if revision != "" {
+			owner := metav1.GetControllerOf(pod)
+			if owner == nil || owner.Kind != "ReplicaSet" || revisions[owner.Name] != revision {
+				continue
+			}
+		}
+
+		podTargets := make([]LogTarget, 0, len(pod.Spec.Containers))
+		for _, c := range pod.Spec.Containers {
+			if container != "" && c.Name != container {
+				continue
+			}
+			podTargets = append(podTargets, LogTarget{
+				Pod:       pod.Name,
+				Container: c.Name,
+				Prefix:    pod.Name,
+			})
+		}
+		// containers are distinguished only if several containers of the pod are selected
+		if len(podTargets) > 1 {
+			for i := range podTargets {
+				podTargets[i].Prefix = fmt.Sprintf("%s/%s", pod.Name, podTargets[i].Container)
+			}
+		}
+		targets = append(targets, podTargets...)
+	}
+	return targets
+}
+
+// readContainerLog reads the logs of the container, lines are emitted until emit returns false
+func readContainerLog(ctx context.Context, clientset kubernetes.Interface, params *GetClusterLogsParams,
+	target LogTarget, emit func(line string) bool) error {
+	stream, err := clientset.CoreV1().Pods(params.Namespace).GetLogs(target.Pod, &corev1.PodLogOptions{
+		Container:  target.Container,
+		Follow:     params.Follow,
+		Previous:   params.Previous,
+		SinceTime:  params.SinceTime,
+		TailLines:  params.TailLines,
+		Timestamps: true,
+	}).Stream(ctx)
+	if err != nil {
+		return herrors.NewErrGetFailed(herrors.PodLogsInK8S, err.Error())
+	}
+	defer stream.Close()
+	return scanLogLines(stream, params.Filter, emit)
+}
+
+// scanLogLines formats the timestamped lines, and emits the lines matching filter,
+// all lines are emitted if filter is nil
+func scanLogLines(reader io.Reader, filter *regexp.Regexp, emit func(line string) bool) error {
+	bufReader := bufio.NewReader(reader)
+	for {
+		line, err := bufReader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimRight(line, "\r\n")
+			timestamp, content := line, ""
+			if i := strings.IndexByte(line, ' '); i >= 0 {
+				timestamp, content = line[:i], line[i+1:]
+			}
+			if t, parseErr := time.Parse(time.RFC3339Nano, timestamp); parseErr == nil {
+				timestamp = t.Format(time.RFC3339)
+			} else {
+				timestamp, content = "", line
+			}
+			for _, l := range strings.Split(content, "\r") {
+				if filter != nil && !filter.MatchString(l) {
+					continue
+				}
+				formatted := l + "\n"
+				if timestamp != "" {
+					formatted = fmt.Sprintf("[%s] %s\n", timestamp, l)
+				}
+				if !emit(formatted) {
+					return nil
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
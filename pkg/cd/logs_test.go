@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelectLogTargets(t *testing.T) {
+	isController := true
+	newPod := func(name, rs string, containers ...string) corev1.Pod {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				OwnerReferences: []metav1.OwnerReference{{
+					Kind:       "ReplicaSet",
+					Name:       rs,
+					Controller: &isController,
+				}},
+			},
+		}
+		for _, c := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: c})
+		}
+		return pod
+	}
+	pods := []corev1.Pod{
+		newPod("pod-b", "rs-2", "app", "sidecar"),
+		newPod("pod-a", "rs-1", "app", "sidecar"),
+	}
+	replicaSets := []appsv1.ReplicaSet{
+		{ObjectMeta: metav1.ObjectMeta{Name: "rs-1", Annotations: map[string]string{_deploymentRevision: "1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "rs-2", Annotations: map[string]string{_deploymentRevision: "2"}}},
+	}
+
+	targets := selectLogTargets(pods, replicaSets, "", "")
+	assert.Equal(t, []LogTarget{
+		{Pod: "pod-a", Container: "app", Prefix: "pod-a/app"},
+		{Pod: "pod-a", Container: "sidecar", Prefix: "pod-a/sidecar"},
+		{Pod: "pod-b", Container: "app", Prefix: "pod-b/app"},
+		{Pod: "pod-b", Container: "sidecar", Prefix: "pod-b/sidecar"},
+	}, targets)
+
+	targets = selectLogTargets(pods, replicaSets, "2", "app")
+	assert.Equal(t, []LogTarget{
+		{Pod: "pod-b", Container: "app", Prefix: "pod-b"},
+	}, targets)
+
+	targets = selectLogTargets(pods, replicaSets, "3", "")
+	assert.Equal(t, 0, len(targets))
+}
+
+func TestScanLogLines(t *testing.T) {
+	logs := "2023-01-01T00:00:00.123456789Z GET /health 200\n" +
+		"2023-01-01T00:00:01.123456789Z POST /api 500\n" +
+		"not timestamped error"
+
+	var lines []string
+	err := scanLogLines(strings.NewReader(logs), nil, func(line string) bool {
+		lines = append(lines, line)
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"[2023-01-01T00:00:00Z] GET /health 200\n",
+		"[2023-01-01T00:00:01Z] POST /api 500\n",
+		"not timestamped error\n",
+	}, lines)
+
+	lines = nil
+	err = scanLogLines(strings.NewReader(logs), regexp.MustCompile("500|error"), func(line string) bool {
+		lines = append(lines, line)
+		return len(lines) < 1
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"[2023-01-01T00:00:01Z] POST /api 500\n"}, lines)
+}
@@ -15,13 +15,18 @@
 package cd
 
 import (
+	"regexp"
+
 	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	"github.com/horizoncd/horizon/pkg/podusage"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/traffic"
+	"github.com/horizoncd/horizon/pkg/workload"
 )
 
 type GetStepParams struct {
@@ -40,6 +45,8 @@ type GetResourceTreeParams struct {
 	Environment  string
 	Cluster      string
 	RegionEntity *regionmodels.RegionEntity
+	// WithUsage fills the usage of pods queried from the metrics of the region, only for display
+	WithUsage bool
 }
 
 type GetClusterStateV2Params struct {
@@ -60,6 +67,7 @@ type CreateClusterParams struct {
 
 type DeployClusterParams struct {
 	Environment string
+	Region      string
 	Cluster     string
 	Revision    string
 }
@@ -87,6 +95,7 @@ type DeletePodsParams struct {
 
 type DeleteClusterParams struct {
 	Environment string
+	Region      string
 	Cluster     string
 }
 
@@ -97,6 +106,21 @@ type ExecuteActionParams struct {
 	GVR          schema.GroupVersionResource
 	ResourceName string
 	ClusterID    uint
+	// SkipEvent skips the event of the action, the caller records its own
+	SkipEvent bool
+}
+
+type GetTrafficParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Namespace    string
+	Cluster      string
+}
+
+type ShiftTrafficParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Namespace    string
+	Cluster      string
+	Shift        *traffic.Shift
 }
 
 type GetContainerLogParams struct {
@@ -109,6 +133,24 @@ type GetContainerLogParams struct {
 	TailLines    int64
 }
 
+type GetClusterLogsParams struct {
+	RegionEntity *regionmodels.RegionEntity
+	Namespace    string
+	Cluster      string
+	// Revision selects the pods of the revision, pods of all revisions are selected if it's empty
+	Revision string
+	// Container selects the container, all containers of pods are selected if it's empty
+	Container string
+	// TailLines limits the lines of each container, all lines are returned if it's nil
+	TailLines *int64
+	SinceTime *metav1.Time
+	// Previous returns the logs of the previous terminated containers
+	Previous bool
+	Follow   bool
+	// Filter keeps the lines matching it, all lines are kept if it's nil
+	Filter *regexp.Regexp
+}
+
 type ExecParams struct {
 	Commands     []string
 	Environment  string
@@ -203,6 +245,10 @@ type Step struct {
 	ManualPaused bool    `json:"manualPaused"`
 	AutoPromote  bool    `json:"autoPromote"`
 	Extra        *string `json:"extra"`
+	// BlueGreen is the status of the blue-green release, nil for other strategies
+	BlueGreen *workload.BlueGreen `json:"blueGreen,omitempty"`
+	// Traffic is the traffic weights of the cluster, nil if it's not routed by a service mesh or gateway
+	Traffic *traffic.Traffic `json:"traffic,omitempty"`
 }
 
 // ClusterVersion version information
@@ -330,6 +376,14 @@ type LifeCycleItem struct {
 type ResourceNode struct {
 	applicationV1alpha1.ResourceNode
 	PodDetail *CompactPod
+	// Runs of batch workloads, such as jobs and cronjobs
+	Runs []workload.Run
+	// NodeRollouts of workloads running a pod per node, such as daemonsets
+	NodeRollouts []workload.NodeRollout
+	// Usage of the pod, only for pods
+	Usage *podusage.Pod
+	// RevisionUsage is the usage aggregated over the pods of the node, such as a replicaset
+	RevisionUsage *podusage.Summary
 }
 
 type ResourceTreeNode struct {
@@ -34,6 +34,8 @@ type GitGetter interface {
 	GetHTTPLink(gitURL string) (string, error)
 	GetCommitHistoryLink(gitURL string, commit string) (string, error)
 	GetTagArchive(ctx context.Context, gitURL, tagName string) (*git.Tag, error)
+	// CreateMergeRequestComment comments on the merge request or pull request mrID of the repository
+	CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, comment string) error
 }
 
 var _ GitGetter = (*gitGetter)(nil)
@@ -137,6 +139,14 @@ func (g *gitGetter) GetTagArchive(ctx context.Context, gitURL, tagName string) (
 	return helper.GetTagArchive(ctx, gitURL, tagName)
 }
 
+func (g *gitGetter) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, comment string) error {
+	helper, err := g.getGitHelper(gitURL)
+	if err != nil {
+		return err
+	}
+	return helper.CreateMergeRequestComment(ctx, gitURL, mrID, comment)
+}
+
 func (g *gitGetter) getGitHelper(gitURL string) (git.Helper, error) {
 	host, err := extractHostFromURL(gitURL)
 	if err != nil {
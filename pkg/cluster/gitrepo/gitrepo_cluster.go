@@ -22,6 +22,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -84,6 +85,27 @@ type ClusterFiles struct {
 	Manifest            map[string]interface{}
 }
 
+// ConfigCommit is a commit of the gitops repo of a cluster
+type ConfigCommit struct {
+	ID string
+	// ParentID is the first parent of the commit, it is empty for the initial commit
+	ParentID    string
+	Title       string
+	Message     string
+	AuthorName  string
+	CommittedAt *time.Time
+	Changes     []*ConfigChange
+}
+
+// ConfigChange is a file changed by a commit, Old and New are the yaml contents
+// of the file before and after the commit, they are nil if the file does not exist
+type ConfigChange struct {
+	OldPath string
+	NewPath string
+	Old     map[string]interface{}
+	New     map[string]interface{}
+}
+
 type ClusterValueFile struct {
 	FileName string
 	Content  map[interface{}]interface{}
@@ -119,6 +141,12 @@ type ReadFileParam struct {
 //go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/cluster/gitrepo/gitrepo_cluster_mock.go -package=mock_gitrepo
 type ClusterGitRepo interface {
 	GetCluster(ctx context.Context, application, cluster, templateName string) (*ClusterFiles, error)
+	// GetClusterByCommit gets the application and pipeline values of the cluster at the commit
+	GetClusterByCommit(ctx context.Context, application, cluster, templateName,
+		commit string) (*ClusterFiles, error)
+	// ListConfigCommits lists the commits of the branch with the files changed by them
+	ListConfigCommits(ctx context.Context, application, cluster, branch string,
+		page, perPage int) ([]*ConfigCommit, error)
 	GetClusterValueFiles(ctx context.Context,
 		application, cluster string) ([]ClusterValueFile, error)
 	// GetClusterTemplate parses cluster's template name and release from GitopsFileChart
@@ -143,6 +171,9 @@ type ClusterGitRepo interface {
 	UpdateRestartTime(ctx context.Context, application, cluster, template string) (string, error)
 	GetConfigCommit(ctx context.Context, application, cluster string) (*ClusterCommit, error)
 	GetRepoInfo(ctx context.Context, application, cluster string) *RepoInfo
+	// GetDeployValues gets the values of the value files in the repo info at the commit,
+	// they are ordered as the value files that the later ones take precedence
+	GetDeployValues(ctx context.Context, application, cluster, commit string) ([]map[string]interface{}, error)
 	GetEnvValue(ctx context.Context, application, cluster, templateName string) (*EnvValue, error)
 	// Rollback rolls gitOps branch back to a specific commit if there are diffs
 	Rollback(ctx context.Context, application, cluster, commit string) (string, error)
@@ -195,8 +226,23 @@ func NewClusterGitlabRepo(ctx context.Context, rootGroup *gitlab.Group,
 func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	application, cluster, templateName string) (_ *ClusterFiles, err error) {
 	const op = "cluster git repo: get cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	return g.getCluster(ctx, application, cluster, templateName, GitOpsBranch)
+}
+
+func (g *clusterGitopsRepo) GetClusterByCommit(ctx context.Context,
+	application, cluster, templateName, commit string) (_ *ClusterFiles, err error) {
+	const op = "cluster git repo: get cluster by commit"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	return g.getCluster(ctx, application, cluster, templateName, commit)
+}
 
+func (g *clusterGitopsRepo) getCluster(ctx context.Context,
+	application, cluster, templateName, ref string) (_ *ClusterFiles, err error) {
 	// 1. get template and pipeline from gitlab
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
 	var applicationBytes, pipelineBytes, manifestBytes []byte
@@ -206,7 +252,7 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		pipelineBytes, err1 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFilePipeline)
+		pipelineBytes, err1 = g.gitlabLib.GetFile(ctx, pid, ref, common.GitopsFilePipeline)
 		if err1 != nil {
 			return
 		}
@@ -217,7 +263,7 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	}()
 	go func() {
 		defer wg.Done()
-		applicationBytes, err2 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileApplication)
+		applicationBytes, err2 = g.gitlabLib.GetFile(ctx, pid, ref, common.GitopsFileApplication)
 		if err2 != nil {
 			return
 		}
@@ -228,7 +274,7 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	}()
 	go func() {
 		defer wg.Done()
-		manifestBytes, err3 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileManifest)
+		manifestBytes, err3 = g.gitlabLib.GetFile(ctx, pid, ref, common.GitopsFileManifest)
 		if err3 != nil {
 			return
 		}
@@ -325,10 +371,99 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	}, nil
 }
 
+func (g *clusterGitopsRepo) ListConfigCommits(ctx context.Context, application, cluster, branch string,
+	page, perPage int) (_ []*ConfigCommit, err error) {
+	const op = "cluster git repo: list config commits"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	commits, err := g.gitlabLib.ListCommits(ctx, pid, branch, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	configCommits := make([]*ConfigCommit, len(commits))
+	errs := make([]error, len(commits))
+	var wg sync.WaitGroup
+	for i := range commits {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			configCommits[i], errs[i] = g.getConfigCommit(ctx, pid, commits[i])
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return configCommits, nil
+}
+
+func (g *clusterGitopsRepo) getConfigCommit(ctx context.Context, pid string,
+	commit *gitlab.Commit) (*ConfigCommit, error) {
+	configCommit := &ConfigCommit{
+		ID:          commit.ID,
+		Title:       commit.Title,
+		Message:     commit.Message,
+		AuthorName:  commit.AuthorName,
+		CommittedAt: commit.CommittedDate,
+	}
+	if len(commit.ParentIDs) > 0 {
+		configCommit.ParentID = commit.ParentIDs[0]
+	}
+
+	diffs, err := g.gitlabLib.GetCommitDiff(ctx, pid, commit.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, diff := range diffs {
+		change := &ConfigChange{
+			OldPath: diff.OldPath,
+			NewPath: diff.NewPath,
+		}
+		if !diff.NewFile && configCommit.ParentID != "" {
+			if change.Old, err = g.readValues(ctx, pid, configCommit.ParentID, diff.OldPath); err != nil {
+				return nil, err
+			}
+		}
+		if !diff.DeletedFile {
+			if change.New, err = g.readValues(ctx, pid, commit.ID, diff.NewPath); err != nil {
+				return nil, err
+			}
+		}
+		configCommit.Changes = append(configCommit.Changes, change)
+	}
+	return configCommit, nil
+}
+
+// readValues reads the yaml file at the ref, the values are nil if the file is not a yaml object
+func (g *clusterGitopsRepo) readValues(ctx context.Context, pid, ref,
+	fileName string) (map[string]interface{}, error) {
+	content, err := g.gitlabLib.GetFile(ctx, pid, ref, fileName)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := kyaml.Unmarshal(content, &values); err != nil {
+		log.Warningf(ctx, "file %s of %s is not a yaml object: %v", fileName, ref, err)
+		return nil, nil
+	}
+	return values, nil
+}
+
 func (g *clusterGitopsRepo) GetClusterValueFiles(ctx context.Context,
 	application, cluster string) (_ []ClusterValueFile, err error) {
 	const op = "cluster git repo: get cluster value files"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get  value file from git
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
@@ -400,7 +535,8 @@ func (g *clusterGitopsRepo) GetClusterValueFiles(ctx context.Context,
 func (g *clusterGitopsRepo) GetClusterTemplate(ctx context.Context, application,
 	cluster string) (*ClusterTemplate, error) {
 	const op = "cluster git repo: get cluster template"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get Chart file from git
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
@@ -428,7 +564,8 @@ func (g *clusterGitopsRepo) GetClusterTemplate(ctx context.Context, application,
 }
 func (g *clusterGitopsRepo) CreateCluster(ctx context.Context, params *CreateClusterParams) (err error) {
 	const op = "cluster git repo: create cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -566,7 +703,8 @@ func (g *clusterGitopsRepo) CreateCluster(ctx context.Context, params *CreateClu
 
 func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClusterParams) error {
 	const op = "cluster git repo: update cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -682,7 +820,8 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 func (g *clusterGitopsRepo) DeleteCluster(ctx context.Context,
 	application, cluster string, clusterID uint) (err error) {
 	const op = "cluster git repo: delete cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. create application group if necessary
 	_, err = g.gitlabLib.GetGroup(ctx, fmt.Sprintf("%v/%v", g.recyclingClustersGroup.FullPath, application))
@@ -715,7 +854,8 @@ func (g *clusterGitopsRepo) DeleteCluster(ctx context.Context,
 func (g *clusterGitopsRepo) HardDeleteCluster(ctx context.Context, application,
 	cluster string) (err error) {
 	const op = "cluster git repo: hard delete cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
 	return g.gitlabLib.DeleteProject(ctx, pid)
@@ -724,7 +864,8 @@ func (g *clusterGitopsRepo) HardDeleteCluster(ctx context.Context, application,
 func (g *clusterGitopsRepo) CompareConfig(ctx context.Context, application,
 	cluster string, from, to *string) (_ string, err error) {
 	const op = "cluster git repo: compare config"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
 
@@ -803,7 +944,8 @@ func (g *clusterGitopsRepo) MergeBranch(ctx context.Context, application, cluste
 func (g *clusterGitopsRepo) GetManifest(ctx context.Context, application,
 	cluster string, commit *string) (*pkgcommon.Manifest, error) {
 	const op = "cluster git repo: get manifest"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
 	var content []byte
@@ -886,7 +1028,8 @@ func (g *clusterGitopsRepo) getPipelineOutput(ctx context.Context,
 func (g *clusterGitopsRepo) UpdatePipelineOutput(ctx context.Context, application, cluster, template string,
 	pipelineOutput interface{}) (commitID string, err error) {
 	const op = "cluster git repo: update pipeline output"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pipelineOutPutInternalFormat, err := func() (map[string]interface{}, error) {
 		bytes, err := json.Marshal(pipelineOutput)
@@ -982,7 +1125,8 @@ func (g *clusterGitopsRepo) GetRestartTime(ctx context.Context, application, clu
 func (g *clusterGitopsRepo) UpdateRestartTime(ctx context.Context,
 	application, cluster, template string) (_ string, err error) {
 	const op = "cluster git repo: update restartTime"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -1024,7 +1168,8 @@ func (g *clusterGitopsRepo) UpdateRestartTime(ctx context.Context,
 func (g *clusterGitopsRepo) GetConfigCommit(ctx context.Context,
 	application, cluster string) (_ *ClusterCommit, err error) {
 	const op = "cluster git repo: get config commit"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
 
@@ -1063,10 +1208,32 @@ func (g *clusterGitopsRepo) GetRepoInfo(ctx context.Context, application, cluste
 	}
 }
 
+func (g *clusterGitopsRepo) GetDeployValues(ctx context.Context, application, cluster,
+	commit string) (_ []map[string]interface{}, err error) {
+	const op = "cluster git repo: get deploy values"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	valueFiles := g.GetRepoInfo(ctx, application, cluster).ValueFiles
+	values := make([]map[string]interface{}, 0, len(valueFiles))
+	for _, valueFile := range valueFiles {
+		value, err := g.readValues(ctx, pid, commit, valueFile)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
 func (g *clusterGitopsRepo) GetEnvValue(ctx context.Context,
 	application, cluster, templateName string) (_ *EnvValue, err error) {
 	const op = "cluster git repo: get config commit"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
 
@@ -1100,7 +1267,8 @@ func (g *clusterGitopsRepo) CheckAndSyncGitOpsBranch(ctx context.Context, applic
 
 func (g *clusterGitopsRepo) Rollback(ctx context.Context, application, cluster, commit string) (_ string, err error) {
 	const op = "cluster git repo: rollback"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -1169,7 +1337,8 @@ func (g *clusterGitopsRepo) Rollback(ctx context.Context, application, cluster,
 func (g *clusterGitopsRepo) UpdateTags(ctx context.Context, application, cluster, templateName string,
 	tags []*tagmodels.Tag) (err error) {
 	const op = "cluster git repo: update tags"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -1230,7 +1399,8 @@ func (g *clusterGitopsRepo) DefaultBranch() string {
 func (g *clusterGitopsRepo) UpgradeCluster(ctx context.Context,
 	param *UpgradeValuesParam) (string, error) {
 	const op = "cluster git repo: upgrade cluster"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
 		return "", err
@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/horizoncd/horizon/core/common"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/render"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
+)
+
+const _globalValues = "global"
+
+// Renderer renders the objects deployed by clusters
+type Renderer interface {
+	// Render renders the objects of the cluster from the chart of the template release with the values
+	// at the commit, which are keyed by the name of the chart as its dependency in the gitops repo.
+	// The capabilities are the ones of the region of the cluster, the default ones are used if it's nil.
+	Render(ctx context.Context, application *appmodels.Application, cluster *clustermodels.Cluster,
+		tr *trmodels.TemplateRelease, commit string,
+		capabilities *chartutil.Capabilities) ([]*unstructured.Unstructured, error)
+}
+
+type renderer struct {
+	clusterGitRepo gitrepo.ClusterGitRepo
+	templateRepo   templaterepo.TemplateRepo
+}
+
+func NewRenderer(clusterGitRepo gitrepo.ClusterGitRepo, templateRepo templaterepo.TemplateRepo) Renderer {
+	return &renderer{
+		clusterGitRepo: clusterGitRepo,
+		templateRepo:   templateRepo,
+	}
+}
+
+func (r *renderer) Render(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, tr *trmodels.TemplateRelease, commit string,
+	capabilities *chartutil.Capabilities) ([]*unstructured.Unstructured, error) {
+	valuesList, err := r.clusterGitRepo.GetDeployValues(ctx, application.Name, cluster.Name, commit)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{})
+	for _, values := range valuesList {
+		if merged, err = mergemap.Merge(merged, values); err != nil {
+			return nil, err
+		}
+	}
+	chartValues, _ := merged[tr.ChartName].(map[string]interface{})
+	if chartValues == nil {
+		chartValues = make(map[string]interface{})
+	}
+	if global, ok := merged[_globalValues]; ok {
+		chartValues[_globalValues] = global
+	}
+	namespace := ""
+	if env, ok := chartValues[common.GitopsEnvValueNamespace].(map[string]interface{}); ok {
+		namespace, _ = env["namespace"].(string)
+	}
+
+	chrt, err := r.templateRepo.GetChart(tr.ChartName, tr.ChartVersion, tr.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := render.Render(chrt, chartValues, &render.Release{
+		Name:         cluster.Name,
+		Namespace:    namespace,
+		Capabilities: capabilities,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return render.Objects(manifests)
+}
@@ -93,7 +93,8 @@ func NewHarborRegistry(config *registry.Config) (registry.Registry, error) {
 
 func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName string) (err error) {
 	const op = "registry: delete repository"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	link := path.Join("/api/repositories", h.path, appName, clusterName)
 
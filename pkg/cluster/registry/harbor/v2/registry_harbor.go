@@ -94,7 +94,8 @@ func NewHarborRegistry(config *registry.Config) (registry.Registry, error) {
 
 func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName string) (err error) {
 	const op = "registry: delete repository"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	link := path.Join("/api/v2.0/projects", h.path, "repositories",
 		url.PathEscape(path.Join(appName, clusterName)))
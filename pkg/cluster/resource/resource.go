@@ -0,0 +1,139 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+)
+
+// sections of the config of clusters, they are the first keys of paths
+const (
+	SectionApplication = "application"
+	SectionPipeline    = "pipeline"
+)
+
+// Resources are the requests of a replica of a cluster, cpu in millicores and memory in MiB.
+// Tier is empty if the template has no tiers.
+type Resources struct {
+	Tier     string `json:"tier,omitempty"`
+	CPU      int64  `json:"cpu"`
+	Memory   int64  `json:"memory"`
+	Replicas int64  `json:"replicas"`
+}
+
+// Get reads the resources from the config of a cluster, in which the sections,
+// such as application and pipeline, are the first keys
+func Get(t *templateresource.Template, config map[string]interface{}) (*Resources, error) {
+	resources := &Resources{Replicas: 1}
+	if t.ReplicasPath != "" {
+		replicas, ok, err := getInt(config, t.ReplicasPath)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			resources.Replicas = replicas
+		}
+	}
+
+	if t.TierPath != "" {
+		value, ok, err := get(config, t.TierPath)
+		if err != nil || !ok {
+			return nil, notFound(err, t.TierPath)
+		}
+		name, _ := value.(string)
+		tier := t.Tier(name)
+		if tier == nil {
+			return nil, fmt.Errorf("unknown tier %v of template %s", value, t.Name)
+		}
+		resources.Tier, resources.CPU, resources.Memory = tier.Name, tier.CPU, tier.Memory
+		return resources, nil
+	}
+
+	var ok bool
+	var err error
+	if resources.CPU, ok, err = getInt(config, t.CPUPath); err != nil || !ok {
+		return nil, notFound(err, t.CPUPath)
+	}
+	if resources.Memory, ok, err = getInt(config, t.MemoryPath); err != nil || !ok {
+		return nil, notFound(err, t.MemoryPath)
+	}
+	return resources, nil
+}
+
+// Set writes the tier, or the cpu and memory if the template has no tiers, to the config of a cluster.
+// Replicas are not written.
+func Set(t *templateresource.Template, config map[string]interface{}, resources *Resources) error {
+	if t.TierPath != "" {
+		if t.Tier(resources.Tier) == nil {
+			return fmt.Errorf("unknown tier %s of template %s", resources.Tier, t.Name)
+		}
+		return set(config, t.TierPath, resources.Tier)
+	}
+	if err := set(config, t.CPUPath, resources.CPU); err != nil {
+		return err
+	}
+	return set(config, t.MemoryPath, resources.Memory)
+}
+
+func get(config map[string]interface{}, path string) (interface{}, bool, error) {
+	keys, err := jsonpath.Parse(path)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := jsonpath.Get(config, keys)
+	return value, ok, nil
+}
+
+func getInt(config map[string]interface{}, path string) (int64, bool, error) {
+	value, ok, err := get(config, path)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	switch v := value.(type) {
+	case float64:
+		return int64(v), true, nil
+	case int:
+		return int64(v), true, nil
+	case int64:
+		return v, true, nil
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil, err
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil, err
+	}
+	return 0, false, fmt.Errorf("%s is not a number: %v", path, value)
+}
+
+func set(config map[string]interface{}, path string, value interface{}) error {
+	keys, err := jsonpath.Parse(path)
+	if err != nil {
+		return err
+	}
+	return jsonpath.Set(config, keys, value)
+}
+
+func notFound(err error, path string) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%s is not found", path)
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+)
+
+func TestTiers(t *testing.T) {
+	template := &templateresource.Template{
+		Name:         "javaapp",
+		ReplicasPath: "application.app.spec.replicas",
+		TierPath:     "application.app.spec.resource",
+		Tiers: []*templateresource.Tier{
+			{Name: "x-small", CPU: 500, Memory: 1024},
+			{Name: "small", CPU: 1000, Memory: 2048},
+		},
+	}
+	config := map[string]interface{}{
+		"application": map[string]interface{}{
+			"app": map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": float64(3), "resource": "small"},
+			},
+		},
+	}
+	resources, err := Get(template, config)
+	assert.Nil(t, err)
+	assert.Equal(t, &Resources{Tier: "small", CPU: 1000, Memory: 2048, Replicas: 3}, resources)
+
+	assert.Nil(t, Set(template, config, &Resources{Tier: "x-small"}))
+	resources, err = Get(template, config)
+	assert.Nil(t, err)
+	assert.Equal(t, "x-small", resources.Tier)
+	assert.Equal(t, int64(500), resources.CPU)
+
+	assert.NotNil(t, Set(template, config, &Resources{Tier: "huge"}))
+	spec := config["application"].(map[string]interface{})["app"].(map[string]interface{})["spec"]
+	delete(spec.(map[string]interface{}), "resource")
+	_, err = Get(template, config)
+	assert.NotNil(t, err)
+}
+
+func TestRequests(t *testing.T) {
+	template := &templateresource.Template{
+		Name:       "rawapp",
+		CPUPath:    "application.app.resource.cpu",
+		MemoryPath: "application.app.resource.memory",
+	}
+	config := map[string]interface{}{
+		"application": map[string]interface{}{
+			"app": map[string]interface{}{
+				"resource": map[string]interface{}{"cpu": 2000, "memory": "4096"},
+			},
+		},
+	}
+	resources, err := Get(template, config)
+	assert.Nil(t, err)
+	assert.Equal(t, &Resources{CPU: 2000, Memory: 4096, Replicas: 1}, resources)
+
+	assert.Nil(t, Set(template, config, &Resources{CPU: 300, Memory: 512}))
+	assert.Equal(t, map[string]interface{}{"cpu": int64(300), "memory": int64(512)},
+		config["application"].(map[string]interface{})["app"].(map[string]interface{})["resource"])
+}
@@ -19,6 +19,7 @@ import (
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 	tektonclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"k8s.io/client-go/dynamic"
 	k8s "k8s.io/client-go/kubernetes"
@@ -44,6 +45,7 @@ func InitClient(kubeconfig string) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	config.Wrap(trace.WrapTransport("tekton"))
 
 	tekton, err := tektonClient(config)
 	if err != nil {
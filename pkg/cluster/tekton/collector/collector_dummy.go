@@ -41,7 +41,8 @@ func NewDummyCollector(tekton tekton.Interface) Interface {
 func (c *DummyCollector) Collect(ctx context.Context, pr *v1beta1.PipelineRun,
 	horizonMetaData *global.HorizonMetaData) (*CollectResult, error) {
 	const op = "DummyCollector: collect"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	metadata := resolveObjMetadata(pr, horizonMetaData)
 	collectResult := &CollectResult{
@@ -56,7 +57,8 @@ func (c *DummyCollector) Collect(ctx context.Context, pr *v1beta1.PipelineRun,
 
 func (c *DummyCollector) GetPipelineRunLog(ctx context.Context, pr *prmodels.Pipelinerun) (*Log, error) {
 	const op = "DummyCollector: getPipelineRunLog"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// get logs from k8s directly
 	logCh, errCh, err := c.tekton.GetPipelineRunLogByID(ctx, pr.CIEventID)
@@ -78,7 +80,8 @@ func (c *DummyCollector) GetPipelineRunObject(ctx context.Context,
 func (c *DummyCollector) GetPipelineRun(ctx context.Context,
 	pr *prmodels.Pipelinerun) (*v1beta1.PipelineRun, error) {
 	const op = "DummyCollector: getPipelineRun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// get pipelineRun from k8s directly
 	tektonPipelineRun, err := c.tekton.GetPipelineRunByID(ctx, pr.CIEventID)
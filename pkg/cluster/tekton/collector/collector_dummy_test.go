@@ -39,8 +39,8 @@ func TestDummyCollector(t *testing.T) {
 
 	ctl := gomock.NewController(t)
 	tek := tektonmock.NewMockInterface(ctl)
-	tek.EXPECT().GetPipelineRunLogByID(ctx, gomock.Any()).Return(getPipelineRunLog(pr))
-	tek.EXPECT().GetPipelineRunByID(ctx, gomock.Any()).Return(pr, nil)
+	tek.EXPECT().GetPipelineRunLogByID(gomock.Any(), gomock.Any()).Return(getPipelineRunLog(pr))
+	tek.EXPECT().GetPipelineRunByID(gomock.Any(), gomock.Any()).Return(pr, nil)
 
 	c := NewDummyCollector(tek)
 
@@ -132,7 +132,8 @@ type CollectResult struct {
 func (c *S3Collector) Collect(ctx context.Context, pr *v1beta1.PipelineRun, horizonMetaData *global.HorizonMetaData) (
 	*CollectResult, error) {
 	const op = "s3Collector: collect"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// collect pipelineRun log into s3
 	metadata := resolveObjMetadata(pr, horizonMetaData)
@@ -186,7 +187,8 @@ func (c *S3Collector) Collect(ctx context.Context, pr *v1beta1.PipelineRun, hori
 
 func (c *S3Collector) GetPipelineRunLog(ctx context.Context, pr *prmodels.Pipelinerun) (*Log, error) {
 	const op = "s3Collector: getPipelineRunLog"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// if pr.PrObject is not empty, get logs from s3
 	if pr.PrObject != "" {
@@ -212,7 +214,8 @@ func (c *S3Collector) GetPipelineRunLog(ctx context.Context, pr *prmodels.Pipeli
 
 func (c *S3Collector) getPipelineRunLog(ctx context.Context, logObject string) (_ []byte, err error) {
 	const op = "s3Collector: getPipelineRunLog from s3"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	b, err := c.s3.GetObject(ctx, logObject)
 	if err != nil {
@@ -228,7 +231,8 @@ func (c *S3Collector) getPipelineRunLog(ctx context.Context, logObject string) (
 
 func (c *S3Collector) GetPipelineRunObject(ctx context.Context, object string) (_ *Object, err error) {
 	const op = "s3Collector: getPipelineRunObject"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	b, err := c.s3.GetObject(ctx, object)
 	if err != nil {
@@ -249,7 +253,8 @@ func (c *S3Collector) GetPipelineRunObject(ctx context.Context, object string) (
 func (c *S3Collector) GetPipelineRun(ctx context.Context,
 	pr *prmodels.Pipelinerun) (*v1beta1.PipelineRun, error) {
 	const op = "s3Collector: getPipelineRun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// if pr.PrObject is not empty, get pipelineRun object from s3
 	if pr.PrObject != "" {
@@ -279,7 +284,8 @@ type CollectObjectResult struct {
 func (c *S3Collector) collectObject(ctx context.Context, metadata *ObjectMeta,
 	pr *v1beta1.PipelineRun) (_ *CollectObjectResult, err error) {
 	const op = "s3Collector: collectObject"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	object := &Object{
 		Metadata:    metadata,
 		PipelineRun: pr,
@@ -312,7 +318,8 @@ type CollectLogResult struct {
 func (c *S3Collector) collectLog(ctx context.Context,
 	pr *v1beta1.PipelineRun, metadata *ObjectMeta) (_ *CollectLogResult, err error) {
 	const op = "s3Collector: collectLog"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	logC, errC, err := c.tekton.GetPipelineRunLog(ctx, pr)
 	if err != nil {
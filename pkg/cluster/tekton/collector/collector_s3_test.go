@@ -75,8 +75,8 @@ func TestS3Collector_Collect(t *testing.T) {
 
 	ctl := gomock.NewController(t)
 	tek := tektonmock.NewMockInterface(ctl)
-	tek.EXPECT().GetPipelineRunLog(ctx, pr).Return(getPipelineRunLog(pr))
-	tek.EXPECT().DeletePipelineRun(ctx, pr).Return(nil)
+	tek.EXPECT().GetPipelineRunLog(gomock.Any(), pr).Return(getPipelineRunLog(pr))
+	tek.EXPECT().DeletePipelineRun(gomock.Any(), pr).Return(nil)
 
 	backend := s3mem.New()
 	_ = backend.CreateBucket("bucket")
@@ -31,12 +31,26 @@ const (
 )
 
 type Factory interface {
-	GetTekton(environment string) (tekton.Interface, error)
-	GetTektonCollector(environment string) (collector.Interface, error)
+	// GetTekton returns the tekton mapped to the environment in the region,
+	// falls back to the one mapped to the environment, and then the default one
+	GetTekton(environment, region string) (tekton.Interface, error)
+	GetTektonCollector(environment, region string) (collector.Interface, error)
+	// SetTekton maps a tekton to the environment in the region, region can be empty
+	SetTekton(environment, region string, tektonConfig *tektonconfig.Tekton) error
+	// DeleteTekton removes the tekton mapped to the environment in the region
+	DeleteTekton(environment, region string)
+	// Reload replaces the tektons loaded from tektonMapper, the ones set by SetTekton are kept
+	Reload(tektonMapper tektonconfig.Mapper) error
+	// ReloadOverrides replaces all the tektons set by SetTekton with the ones in tektonMapper
+	ReloadOverrides(tektonMapper tektonconfig.Mapper) error
 }
 
 type factory struct {
-	cache *sync.Map
+	sync.RWMutex
+	// mapped are tektons loaded from tektonMapper
+	mapped map[string]*tektonCache
+	// overrides are tektons set at runtime, they take precedence over the mapped ones
+	overrides map[string]*tektonCache
 }
 
 type tektonCache struct {
@@ -47,66 +61,128 @@ type tektonCache struct {
 func NewFactory(tektonMapper tektonconfig.Mapper) (Factory, error) {
 	const op = "new tekton factory"
 
-	cache := &sync.Map{}
-	for env, tektonConfig := range tektonMapper {
-		t, err := tekton.NewTekton(tektonConfig)
+	f := &factory{
+		overrides: make(map[string]*tektonCache),
+	}
+	if err := f.Reload(tektonMapper); err != nil {
+		return nil, errors.E(op, err)
+	}
+	return f, nil
+}
+
+func (f *factory) Reload(tektonMapper tektonconfig.Mapper) error {
+	mapped := make(map[string]*tektonCache, len(tektonMapper))
+	// key of tektonMapper is environment or environment/region
+	for key, tektonConfig := range tektonMapper {
+		c, err := newTektonCache(tektonConfig)
 		if err != nil {
-			return nil, errors.E(op, err)
+			return err
 		}
-		var c collector.Interface
-		if tektonConfig.LogStorage.Type == _s3Storage {
-			s3Driver, err := s3.NewDriver(s3.Params{
-				AccessKey:        tektonConfig.LogStorage.AccessKey,
-				SecretKey:        tektonConfig.LogStorage.SecretKey,
-				Region:           tektonConfig.LogStorage.Region,
-				Endpoint:         tektonConfig.LogStorage.Endpoint,
-				Bucket:           tektonConfig.LogStorage.Bucket,
-				DisableSSL:       tektonConfig.LogStorage.DisableSSL,
-				SkipVerify:       tektonConfig.LogStorage.SkipVerify,
-				S3ForcePathStyle: tektonConfig.LogStorage.S3ForcePathStyle,
-				ContentType:      "text/plain",
-			})
-			if err != nil {
-				return nil, errors.E(op, err)
-			}
-			c = collector.NewS3Collector(s3Driver, t)
-		} else {
-			c = collector.NewDummyCollector(t)
+		mapped[key] = c
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.mapped = mapped
+	return nil
+}
+
+func (f *factory) ReloadOverrides(tektonMapper tektonconfig.Mapper) error {
+	overrides := make(map[string]*tektonCache, len(tektonMapper))
+	for key, tektonConfig := range tektonMapper {
+		c, err := newTektonCache(tektonConfig)
+		if err != nil {
+			return err
 		}
-		cache.Store(env, &tektonCache{
-			tekton:          t,
-			tektonCollector: c,
+		overrides[key] = c
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.overrides = overrides
+	return nil
+}
+
+// Validate checks whether a tekton can be created by the config
+func Validate(tektonConfig *tektonconfig.Tekton) error {
+	_, err := newTektonCache(tektonConfig)
+	return err
+}
+
+func newTektonCache(tektonConfig *tektonconfig.Tekton) (*tektonCache, error) {
+	t, err := tekton.NewTekton(tektonConfig)
+	if err != nil {
+		return nil, err
+	}
+	var c collector.Interface
+	if tektonConfig.LogStorage != nil && tektonConfig.LogStorage.Type == _s3Storage {
+		s3Driver, err := s3.NewDriver(s3.Params{
+			AccessKey:        tektonConfig.LogStorage.AccessKey,
+			SecretKey:        tektonConfig.LogStorage.SecretKey,
+			Region:           tektonConfig.LogStorage.Region,
+			Endpoint:         tektonConfig.LogStorage.Endpoint,
+			Bucket:           tektonConfig.LogStorage.Bucket,
+			DisableSSL:       tektonConfig.LogStorage.DisableSSL,
+			SkipVerify:       tektonConfig.LogStorage.SkipVerify,
+			S3ForcePathStyle: tektonConfig.LogStorage.S3ForcePathStyle,
+			ContentType:      "text/plain",
 		})
+		if err != nil {
+			return nil, err
+		}
+		c = collector.NewS3Collector(s3Driver, t)
+	} else {
+		c = collector.NewDummyCollector(t)
 	}
-	return &factory{
-		cache: cache,
+	return &tektonCache{
+		tekton:          t,
+		tektonCollector: c,
 	}, nil
 }
 
-func (f factory) GetTekton(environment string) (tekton.Interface, error) {
-	cache, err := f.GetFromCache(environment)
+func (f *factory) GetTekton(environment, region string) (tekton.Interface, error) {
+	cache, err := f.GetFromCache(environment, region)
 	if err != nil {
 		return nil, err
 	}
 	return cache.tekton, nil
 }
 
-func (f factory) GetTektonCollector(environment string) (collector.Interface, error) {
-	cache, err := f.GetFromCache(environment)
+func (f *factory) GetTektonCollector(environment, region string) (collector.Interface, error) {
+	cache, err := f.GetFromCache(environment, region)
 	if err != nil {
 		return nil, err
 	}
 	return cache.tektonCollector, nil
 }
 
-func (f factory) GetFromCache(environment string) (*tektonCache, error) {
-	var ret interface{}
-	var ok bool
-	if ret, ok = f.cache.Load(environment); !ok {
-		// check and use default tekton
-		if ret, ok = f.cache.Load(_default); !ok {
-			return nil, herrors.NewErrNotFound(herrors.Tekton, "default tekton not found")
+func (f *factory) SetTekton(environment, region string, tektonConfig *tektonconfig.Tekton) error {
+	const op = "set tekton"
+
+	c, err := newTektonCache(tektonConfig)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.overrides[tektonconfig.Key(environment, region)] = c
+	return nil
+}
+
+func (f *factory) DeleteTekton(environment, region string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.overrides, tektonconfig.Key(environment, region))
+}
+
+func (f *factory) GetFromCache(environment, region string) (*tektonCache, error) {
+	f.RLock()
+	defer f.RUnlock()
+	for _, key := range []string{tektonconfig.Key(environment, region), environment, _default} {
+		if ret, ok := f.overrides[key]; ok {
+			return ret, nil
+		}
+		if ret, ok := f.mapped[key]; ok {
+			return ret, nil
 		}
 	}
-	return ret.(*tektonCache), nil
+	return nil, herrors.NewErrNotFound(herrors.Tekton, "default tekton not found")
 }
@@ -29,6 +29,7 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"github.com/tektoncd/cli/pkg/options"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -44,7 +45,8 @@ func (t *Tekton) GetPipelineRunByID(ctx context.Context, ciEventID string) (_ *v
 
 func (t *Tekton) CreatePipelineRun(ctx context.Context, pr *PipelineRun) (eventID string, err error) {
 	const op = "tekton: create pipelineRun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	bodyBytes, err := json.Marshal(pr)
 	if err != nil {
@@ -86,7 +88,8 @@ type patchStringValue struct {
 
 func (t *Tekton) StopPipelineRun(ctx context.Context, ciEventID string) (err error) {
 	const op = "tekton: stop pipelineRun"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pr, err := t.GetPipelineRunByID(ctx, ciEventID)
 	if err != nil {
@@ -189,6 +192,6 @@ func (t *Tekton) sendHTTPRequest(ctx context.Context, method string,
 	}
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("X-Request-Id", "")
-	client := &http.Client{}
+	client := &http.Client{Transport: trace.NewTransport("tekton", nil)}
 	return client.Do(req)
 }
@@ -37,7 +37,8 @@ type RunningTask struct {
 
 func GetPipelineRunStatus(ctx context.Context, pr *v1beta1.PipelineRun) *PipelineRunStatus {
 	const op = "tekton util: get pipelineRun status"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	if pr == nil {
 		return nil
@@ -61,7 +62,8 @@ func GetPipelineRunStatus(ctx context.Context, pr *v1beta1.PipelineRun) *Pipelin
 
 func GetPipelineRunningTask(ctx context.Context, pr *v1beta1.PipelineRun) *RunningTask {
 	const op = "tekton util: get pipeline runningTask"
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	var currentTaskName, currentTaskStatus string
 
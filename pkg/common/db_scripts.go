@@ -176,8 +176,10 @@ const (
 		"is_default = 1 and deleted_ts = 0"
 	EnvironmentRegionsGetDefault = "select * from tb_environment_region where " +
 		"is_default = 1 and deleted_ts = 0"
-	EnvironmentRegionSetDefaultByID   = "update tb_environment_region set is_default = 1 where id = ?"
-	EnvironmentRegionUnsetDefaultByID = "update tb_environment_region set is_default = 0 where id = ?"
+	EnvironmentRegionSetDefaultByID     = "update tb_environment_region set is_default = 1 where id = ?"
+	EnvironmentRegionUnsetDefaultByID   = "update tb_environment_region set is_default = 0 where id = ?"
+	EnvironmentRegionUpdateCDConfigByID = "update tb_environment_region set argocd = ?, tekton = ? " +
+		"where id = ? and deleted_ts = 0"
 )
 
 /* sql about region */
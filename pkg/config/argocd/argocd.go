@@ -14,11 +14,22 @@
 
 package argocd
 
+// Mapper maps environment or environment/region to ArgoCD,
+// environments can also be joined by comma in config file
 type Mapper map[string]*ArgoCD
 
 type ArgoCD struct {
-	URL       string `yaml:"url"`
-	Token     string `yaml:"token"`
-	HelmRepo  string `yaml:"helmRepo"`
-	Namespace string `yaml:"namespace"`
+	URL       string `json:"url" yaml:"url"`
+	Token     string `json:"token" yaml:"token"`
+	HelmRepo  string `json:"helmRepo" yaml:"helmRepo"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+}
+
+// Key returns the mapper key of the environment in the region,
+// returns environment itself if region is empty
+func Key(environment, region string) string {
+	if region == "" {
+		return environment
+	}
+	return environment + "/" + region
 }
@@ -0,0 +1,39 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import "time"
+
+type Config struct {
+	JobInterval time.Duration `yaml:"jobInterval"`
+	Currency    string        `yaml:"currency"`
+	// Prices are the prices of resources by region name, resources in regions without prices cost nothing
+	Prices map[string]*Price `yaml:"prices"`
+}
+
+// Price is the price of a vCPU-hour, a GiB-hour of memory and a GPU-hour
+type Price struct {
+	CPU    float64 `yaml:"cpu"`
+	Memory float64 `yaml:"memory"`
+	GPU    float64 `yaml:"gpu"`
+}
+
+// Price returns the price of the region, or zero price if the region has no price
+func (c *Config) Price(region string) *Price {
+	if price, ok := c.Prices[region]; ok && price != nil {
+		return price
+	}
+	return &Price{}
+}
@@ -0,0 +1,29 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittrigger
+
+import "time"
+
+type Config struct {
+	// GitlabSecretToken is the secret token configured in gitlab webhooks,
+	// gitlab events are rejected if it's empty
+	GitlabSecretToken string `yaml:"gitlabSecretToken"`
+	// GithubSecret is the secret used to sign github webhook payloads,
+	// github events are rejected if it's empty
+	GithubSecret string `yaml:"githubSecret"`
+	// DebounceInterval is the period to wait for more events of a cluster,
+	// only the latest event in the period triggers a builddeploy
+	DebounceInterval time.Duration `yaml:"debounceInterval"`
+}
@@ -0,0 +1,35 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hibernation
+
+import "time"
+
+type Config struct {
+	// SupportedEnvs are the environments whose clusters are hibernated and woken by the job,
+	// clusters can still be hibernated on demand in other environments
+	SupportedEnvs []string `yaml:"supportedEnvs"`
+	// AccountID is the user who hibernates and wakes clusters
+	AccountID   uint          `yaml:"accountID"`
+	JobInterval time.Duration `yaml:"jobInterval"`
+}
+
+func (c *Config) IsSupported(env string) bool {
+	for _, supported := range c.SupportedEnvs {
+		if supported == env {
+			return true
+		}
+	}
+	return false
+}
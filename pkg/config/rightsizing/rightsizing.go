@@ -0,0 +1,46 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import "time"
+
+type Config struct {
+	// SupportedEnvs are the environments whose clusters are recommended resources
+	SupportedEnvs []string      `yaml:"supportedEnvs"`
+	JobInterval   time.Duration `yaml:"jobInterval"`
+	// Window is the duration of the usage history
+	Window time.Duration `yaml:"window"`
+	// CPUPercentile and MemoryPercentile are the quantiles of the usage of pods over the window
+	CPUPercentile    float64 `yaml:"cpuPercentile"`
+	MemoryPercentile float64 `yaml:"memoryPercentile"`
+	// Headroom is multiplied by the usage as the target requests, such as 1.2 for 20% headroom
+	Headroom float64 `yaml:"headroom"`
+	// Tolerance is the ratio of changes ignored, requests are not recommended
+	// if they are within the tolerance of the current requests
+	Tolerance float64 `yaml:"tolerance"`
+	// CPUStep in millicores and MemoryStep in MiB round up the recommended requests
+	// of templates without tiers
+	CPUStep    int64 `yaml:"cpuStep"`
+	MemoryStep int64 `yaml:"memoryStep"`
+}
+
+func (c *Config) IsSupported(env string) bool {
+	for _, supported := range c.SupportedEnvs {
+		if supported == env {
+			return true
+		}
+	}
+	return false
+}
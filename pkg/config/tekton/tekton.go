@@ -14,23 +14,34 @@
 
 package tekton
 
+// Mapper maps environment or environment/region to Tekton,
+// environments can also be joined by comma in config file
 type Mapper map[string]*Tekton
 
 type Tekton struct {
-	Server     string      `yaml:"server"`
-	Namespace  string      `yaml:"namespace"`
-	Kubeconfig string      `yaml:"kubeconfig"`
-	LogStorage *LogStorage `yaml:"logStorage"`
+	Server     string      `json:"server" yaml:"server"`
+	Namespace  string      `json:"namespace" yaml:"namespace"`
+	Kubeconfig string      `json:"kubeconfig" yaml:"kubeconfig"`
+	LogStorage *LogStorage `json:"logStorage" yaml:"logStorage"`
 }
 
 type LogStorage struct {
-	Type             string `yaml:"type"`
-	AccessKey        string `yaml:"accessKey"`
-	SecretKey        string `yaml:"secretKey"`
-	Region           string `yaml:"region"`
-	Endpoint         string `yaml:"endpoint"`
-	Bucket           string `yaml:"bucket"`
-	DisableSSL       bool   `yaml:"disableSSL"`
-	SkipVerify       bool   `yaml:"skipVerify"`
-	S3ForcePathStyle bool   `yaml:"s3ForcePathStyle"`
+	Type             string `json:"type" yaml:"type"`
+	AccessKey        string `json:"accessKey" yaml:"accessKey"`
+	SecretKey        string `json:"secretKey" yaml:"secretKey"`
+	Region           string `json:"region" yaml:"region"`
+	Endpoint         string `json:"endpoint" yaml:"endpoint"`
+	Bucket           string `json:"bucket" yaml:"bucket"`
+	DisableSSL       bool   `json:"disableSSL" yaml:"disableSSL"`
+	SkipVerify       bool   `json:"skipVerify" yaml:"skipVerify"`
+	S3ForcePathStyle bool   `json:"s3ForcePathStyle" yaml:"s3ForcePathStyle"`
+}
+
+// Key returns the mapper key of the environment in the region,
+// returns environment itself if region is empty
+func Key(environment, region string) string {
+	if region == "" {
+		return environment
+	}
+	return environment + "/" + region
 }
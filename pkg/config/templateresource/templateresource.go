@@ -0,0 +1,64 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templateresource
+
+// Config describes where the resources of clusters are in the values of templates,
+// paths are json paths in the config of clusters, such as application.app.spec.resource
+type Config struct {
+	Templates []*Template `yaml:"templates"`
+}
+
+// Template describes the resources of the clusters of a template,
+// either TierPath and Tiers or CPUPath and MemoryPath is set
+type Template struct {
+	Name string `yaml:"name"`
+	// ReplicasPath is the path of replicas, clusters have 1 replica if it's empty or unset
+	ReplicasPath string `yaml:"replicasPath"`
+	// TierPath is the path of the name of the tier
+	TierPath string `yaml:"tierPath"`
+	// Tiers are ordered from small to large
+	Tiers []*Tier `yaml:"tiers"`
+	// CPUPath is the path of cpu requests in millicores
+	CPUPath string `yaml:"cpuPath"`
+	// MemoryPath is the path of memory requests in MiB
+	MemoryPath string `yaml:"memoryPath"`
+}
+
+// Tier is a resource tier of the template, cpu in millicores and memory in MiB
+type Tier struct {
+	Name   string `yaml:"name"`
+	CPU    int64  `yaml:"cpu"`
+	Memory int64  `yaml:"memory"`
+}
+
+// Get returns the template by name, or nil if the resources of the template are not configured
+func (c *Config) Get(template string) *Template {
+	for _, t := range c.Templates {
+		if t.Name == template {
+			return t
+		}
+	}
+	return nil
+}
+
+// Tier returns the tier by name, or nil if the tier does not exist
+func (t *Template) Tier(name string) *Tier {
+	for _, tier := range t.Tiers {
+		if tier.Name == name {
+			return tier
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import "time"
+
+type Config struct {
+	// ReadOnlyEnvironments are the environments whose terminals are attach-only,
+	// users can only watch the output of the main process of containers
+	ReadOnlyEnvironments []string `yaml:"readOnlyEnvironments"`
+	// IdleTimeout closes the sessions without input or output for the duration, 0 means no limit
+	IdleTimeout time.Duration `yaml:"idleTimeout"`
+	// MaxSessionDuration closes the sessions lasting for the duration, 0 means no limit
+	MaxSessionDuration time.Duration `yaml:"maxSessionDuration"`
+	Recording          Recording     `yaml:"recording"`
+	// DebugImages are the platform-approved images of ephemeral debug containers, the first one is the default
+	DebugImages []string `yaml:"debugImages"`
+	// MaxUploadSize is the max size in bytes of files uploaded to containers, 0 means no limit
+	MaxUploadSize int64 `yaml:"maxUploadSize"`
+}
+
+// Recording configures where the asciicast recordings of sessions are stored
+type Recording struct {
+	// Storage is local or s3, sessions are not recorded if it's empty
+	Storage string `yaml:"storage"`
+	// LocalDir is the directory to store recordings for local storage
+	LocalDir string `yaml:"localDir"`
+	S3       S3     `yaml:"s3"`
+}
+
+type S3 struct {
+	AccessKey        string `yaml:"accessKey"`
+	SecretKey        string `yaml:"secretKey"`
+	Region           string `yaml:"region"`
+	Endpoint         string `yaml:"endpoint"`
+	Bucket           string `yaml:"bucket"`
+	DisableSSL       bool   `yaml:"disableSSL"`
+	SkipVerify       bool   `yaml:"skipVerify"`
+	S3ForcePathStyle bool   `yaml:"s3ForcePathStyle"`
+}
+
+// IsReadOnly returns whether terminals of the environment are attach-only
+func (c *Config) IsReadOnly(environment string) bool {
+	for _, env := range c.ReadOnlyEnvironments {
+		if env == environment {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDebugImageAllowed returns whether the image is approved for ephemeral debug containers
+func (c *Config) IsDebugImageAllowed(image string) bool {
+	for _, approved := range c.DebugImages {
+		if approved == image {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultDebugImage returns the default image of ephemeral debug containers, empty if no image is approved
+func (c *Config) DefaultDebugImage() string {
+	if len(c.DebugImages) == 0 {
+		return ""
+	}
+	return c.DebugImages[0]
+}
@@ -0,0 +1,27 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+type Config struct {
+	// Exporter is the exporter of spans, supports otlp and stdout, tracing is disabled if it's empty
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the address of the otlp http receiver, such as localhost:4318
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	// ServiceName defaults to horizon
+	ServiceName string `yaml:"serviceName"`
+	// SampleRatio is the ratio of traces to be sampled, defaults to 1
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
@@ -0,0 +1,86 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+// Config defines workload abilities of custom resources declaratively, the abilities of kinds
+// supported by go code are not overridden
+type Config struct {
+	Definitions []*Definition `yaml:"definitions" json:"definitions"`
+}
+
+// Definition defines the workload ability of a kind. Values are read from the object by jsonpath templates,
+// such as {.status.readyReplicas}, and written by json paths, such as spec.paused.
+type Definition struct {
+	Group    string `yaml:"group" json:"group"`
+	Version  string `yaml:"version" json:"version"`
+	Kind     string `yaml:"kind" json:"kind"`
+	Resource string `yaml:"resource" json:"resource"`
+
+	// PodSelector is the jsonpath template of the labels selecting the pods, such as {.spec.selector.matchLabels}
+	PodSelector string `yaml:"podSelector" json:"podSelector"`
+	// Healthy are the conditions which are all true when the workload is healthy,
+	// the workload is always healthy without conditions
+	Healthy []*Condition `yaml:"healthy" json:"healthy,omitempty"`
+	// Steps enables greyscale releases of the workload
+	Steps *Steps `yaml:"steps" json:"steps,omitempty"`
+	// Hibernatable means the replicas are at spec.replicas, so the workload can be hibernated and woken
+	Hibernatable bool `yaml:"hibernatable" json:"hibernatable,omitempty"`
+	// Actions are the operations to execute by action name, such as restart and pause
+	Actions map[string][]*Operation `yaml:"actions" json:"actions,omitempty"`
+}
+
+// operators of conditions
+const (
+	OperatorEqual          = "Equal"
+	OperatorNotEqual       = "NotEqual"
+	OperatorExists         = "Exists"
+	OperatorNotExists      = "NotExists"
+	OperatorGreaterOrEqual = "GreaterOrEqual"
+	OperatorLessOrEqual    = "LessOrEqual"
+)
+
+type Condition struct {
+	// Path is the jsonpath template of the value to check
+	Path string `yaml:"path" json:"path"`
+	// Operator defaults to Equal
+	Operator string `yaml:"operator" json:"operator,omitempty"`
+	// Value is the value to compare with, or ValuePath is the jsonpath template of it
+	Value     string `yaml:"value" json:"value,omitempty"`
+	ValuePath string `yaml:"valuePath" json:"valuePath,omitempty"`
+}
+
+// Steps are the jsonpath templates of the steps of greyscale releases
+type Steps struct {
+	// Replicas is the list of replicas released by each step
+	Replicas string `yaml:"replicas" json:"replicas"`
+	// Index is the count of steps finished
+	Index       string `yaml:"index" json:"index"`
+	Paused      string `yaml:"paused" json:"paused,omitempty"`
+	AutoPromote string `yaml:"autoPromote" json:"autoPromote,omitempty"`
+}
+
+// ops of operations
+const (
+	OpSet    = "set"
+	OpDelete = "delete"
+)
+
+type Operation struct {
+	Op   string `yaml:"op" json:"op"`
+	Path string `yaml:"path" json:"path"`
+	// Value is the value to set, string values are rendered as go templates with sprig functions,
+	// such as {{ now | date "2006-01-02T15:04:05Z07:00" }}
+	Value interface{} `yaml:"value" json:"value,omitempty"`
+}
@@ -0,0 +1,222 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cost/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// columns to sum the costs by
+const (
+	SumByDate    = "date"
+	SumByCluster = "cluster_id"
+)
+
+type DAO interface {
+	// Accumulate adds the costs of the region to the costs of the clusters in the dates,
+	// and records the time accounted to in the same transaction
+	Accumulate(ctx context.Context, regionName string, accountedAt time.Time, costs []*models.ClusterCost) error
+	// ListAccountedAt returns the time each region is accounted to
+	ListAccountedAt(ctx context.Context) (map[string]time.Time, error)
+	// Sum sums the costs by the date or the cluster
+	Sum(ctx context.Context, query *models.CostQuery, by string) ([]*models.Cost, error)
+	// SumByTag sums the costs by the values of the tag of clusters, clusters without the tag are skipped
+	SumByTag(ctx context.Context, query *models.CostQuery, tagKey string) ([]*models.Cost, error)
+
+	SaveBudget(ctx context.Context, budget *models.Budget) (*models.Budget, error)
+	GetBudget(ctx context.Context, resourceType string, resourceID uint) (*models.Budget, error)
+	ListBudgets(ctx context.Context) ([]*models.Budget, error)
+	UpdateBudgetNotified(ctx context.Context, id uint, month string, threshold uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Accumulate(ctx context.Context, regionName string, accountedAt time.Time,
+	costs []*models.ClusterCost) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, cost := range costs {
+			var existing models.ClusterCost
+			err := tx.Where("cluster_id = ? and date = ?", cost.ClusterID, cost.Date).First(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := tx.Create(cost).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&existing).Updates(map[string]interface{}{
+				"application_id":   cost.ApplicationID,
+				"region_name":      cost.RegionName,
+				"environment_name": cost.EnvironmentName,
+				"cpu_hours":        gorm.Expr("cpu_hours + ?", cost.CPUHours),
+				"memory_hours":     gorm.Expr("memory_hours + ?", cost.MemoryHours),
+				"gpu_hours":        gorm.Expr("gpu_hours + ?", cost.GPUHours),
+				"cost":             gorm.Expr("cost + ?", cost.Cost),
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		var accounting models.Accounting
+		err := tx.Where("region_name = ?", regionName).First(&accounting).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&models.Accounting{RegionName: regionName, AccountedAt: accountedAt}).Error
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&accounting).Update("accounted_at", accountedAt).Error
+	})
+	if err != nil {
+		return herrors.NewErrUpdateFailed(herrors.ClusterCostInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListAccountedAt(ctx context.Context) (map[string]time.Time, error) {
+	var accountings []*models.Accounting
+	if err := d.db.WithContext(ctx).Find(&accountings).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterCostInDB, err.Error())
+	}
+	result := make(map[string]time.Time, len(accountings))
+	for _, accounting := range accountings {
+		result[accounting.RegionName] = accounting.AccountedAt
+	}
+	return result, nil
+}
+
+func (d *dao) Sum(ctx context.Context, query *models.CostQuery, by string) ([]*models.Cost, error) {
+	if by != SumByDate && by != SumByCluster {
+		return nil, herrors.NewErrListFailed(herrors.ClusterCostInDB, fmt.Sprintf("unknown column %s", by))
+	}
+	var costs []*models.Cost
+	if err := d.where(ctx, query).
+		Select(fmt.Sprintf("cc.%s, %s", by, _sums)).
+		Group("cc." + by).Order("cc." + by).Scan(&costs).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterCostInDB, err.Error())
+	}
+	return costs, nil
+}
+
+func (d *dao) SumByTag(ctx context.Context, query *models.CostQuery, tagKey string) ([]*models.Cost, error) {
+	var costs []*models.Cost
+	if err := d.where(ctx, query).
+		Joins("join tb_tag t on t.resource_id = cc.cluster_id and t.resource_type = ? and t.tag_key = ?",
+			common.ResourceCluster, tagKey).
+		Select("t.tag_value, " + _sums).
+		Group("t.tag_value").Order("t.tag_value").Scan(&costs).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterCostInDB, err.Error())
+	}
+	return costs, nil
+}
+
+const _sums = "sum(cc.cpu_hours) as cpu_hours, sum(cc.memory_hours) as memory_hours, " +
+	"sum(cc.gpu_hours) as gpu_hours, sum(cc.cost) as cost"
+
+func (d *dao) where(ctx context.Context, query *models.CostQuery) *gorm.DB {
+	statement := d.db.WithContext(ctx).Table("tb_cluster_cost cc").
+		Where("cc.deleted_ts = 0 and cc.date >= ? and cc.date <= ?", query.From, query.To)
+	if query.ClusterID != 0 {
+		statement = statement.Where("cc.cluster_id = ?", query.ClusterID)
+	}
+	if query.ApplicationID != 0 {
+		statement = statement.Where("cc.application_id = ?", query.ApplicationID)
+	}
+	if query.GroupTraversalIDs != "" {
+		statement = statement.
+			Joins("join tb_application a on a.id = cc.application_id").
+			Joins("join tb_group g on g.id = a.group_id").
+			Where("g.traversal_ids = ? or g.traversal_ids like ?",
+				query.GroupTraversalIDs, query.GroupTraversalIDs+",%")
+	}
+	return statement
+}
+
+func (d *dao) SaveBudget(ctx context.Context, budget *models.Budget) (*models.Budget, error) {
+	existing, err := d.GetBudget(ctx, budget.ResourceType, budget.ResourceID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		if err := d.db.WithContext(ctx).Create(budget).Error; err != nil {
+			return nil, herrors.NewErrInsertFailed(herrors.CostBudgetInDB, err.Error())
+		}
+		return budget, nil
+	}
+	// thresholds are notified again in the month once the budget is changed
+	where := d.db.WithContext(ctx).Model(existing).Where("id = ?", existing.ID)
+	if err := where.Updates(map[string]interface{}{
+		"amount":             budget.Amount,
+		"thresholds":         budget.Thresholds,
+		"notified_month":     "",
+		"notified_threshold": 0,
+		"updated_by":         budget.UpdatedBy,
+	}).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.CostBudgetInDB, err.Error())
+	}
+	if err := where.First(existing).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.CostBudgetInDB, err.Error())
+	}
+	return existing, nil
+}
+
+func (d *dao) GetBudget(ctx context.Context, resourceType string, resourceID uint) (*models.Budget, error) {
+	var budget models.Budget
+	if err := d.db.WithContext(ctx).Where("resource_type = ? and resource_id = ?", resourceType, resourceID).
+		First(&budget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.CostBudgetInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.CostBudgetInDB, err.Error())
+	}
+	return &budget, nil
+}
+
+func (d *dao) ListBudgets(ctx context.Context) ([]*models.Budget, error) {
+	var budgets []*models.Budget
+	if err := d.db.WithContext(ctx).Order("id").Find(&budgets).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.CostBudgetInDB, err.Error())
+	}
+	return budgets, nil
+}
+
+func (d *dao) UpdateBudgetNotified(ctx context.Context, id uint, month string, threshold uint) error {
+	if err := d.db.WithContext(ctx).Model(&models.Budget{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"notified_month":     month,
+			"notified_threshold": threshold,
+		}).Error; err != nil {
+		return herrors.NewErrUpdateFailed(herrors.CostBudgetInDB, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/cost/dao"
+	"github.com/horizoncd/horizon/pkg/cost/models"
+)
+
+// columns to sum the costs by
+const (
+	SumByDate    = dao.SumByDate
+	SumByCluster = dao.SumByCluster
+)
+
+type Manager interface {
+	// Accumulate adds the costs of the region to the costs of the clusters in the dates,
+	// and records the time accounted to in the same transaction
+	Accumulate(ctx context.Context, regionName string, accountedAt time.Time, costs []*models.ClusterCost) error
+	// ListAccountedAt returns the time each region is accounted to
+	ListAccountedAt(ctx context.Context) (map[string]time.Time, error)
+	// Sum sums the costs by the date or the cluster
+	Sum(ctx context.Context, query *models.CostQuery, by string) ([]*models.Cost, error)
+	// SumByTag sums the costs by the values of the tag of clusters, clusters without the tag are skipped
+	SumByTag(ctx context.Context, query *models.CostQuery, tagKey string) ([]*models.Cost, error)
+
+	// SaveBudget creates the budget of the resource, or updates it if it exists
+	SaveBudget(ctx context.Context, budget *models.Budget) (*models.Budget, error)
+	GetBudget(ctx context.Context, resourceType string, resourceID uint) (*models.Budget, error)
+	ListBudgets(ctx context.Context) ([]*models.Budget, error)
+	UpdateBudgetNotified(ctx context.Context, id uint, month string, threshold uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Accumulate(ctx context.Context, regionName string, accountedAt time.Time,
+	costs []*models.ClusterCost) error {
+	return m.dao.Accumulate(ctx, regionName, accountedAt, costs)
+}
+
+func (m *manager) ListAccountedAt(ctx context.Context) (map[string]time.Time, error) {
+	return m.dao.ListAccountedAt(ctx)
+}
+
+func (m *manager) Sum(ctx context.Context, query *models.CostQuery, by string) ([]*models.Cost, error) {
+	return m.dao.Sum(ctx, query, by)
+}
+
+func (m *manager) SumByTag(ctx context.Context, query *models.CostQuery,
+	tagKey string) ([]*models.Cost, error) {
+	return m.dao.SumByTag(ctx, query, tagKey)
+}
+
+func (m *manager) SaveBudget(ctx context.Context, budget *models.Budget) (*models.Budget, error) {
+	return m.dao.SaveBudget(ctx, budget)
+}
+
+func (m *manager) GetBudget(ctx context.Context, resourceType string,
+	resourceID uint) (*models.Budget, error) {
+	return m.dao.GetBudget(ctx, resourceType, resourceID)
+}
+
+func (m *manager) ListBudgets(ctx context.Context) ([]*models.Budget, error) {
+	return m.dao.ListBudgets(ctx)
+}
+
+func (m *manager) UpdateBudgetNotified(ctx context.Context, id uint, month string, threshold uint) error {
+	return m.dao.UpdateBudgetNotified(ctx, id, month, threshold)
+}
@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cost/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+)
+
+func TestCosts(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.ClusterCost{}, &models.Budget{}, &models.Accounting{},
+		&appmodels.Application{},
+		&groupmodels.Group{}, &tagmodels.Tag{}))
+	mgr := New(db)
+	ctx := context.Background()
+
+	// group 1 has subgroup 2, application 1 is in group 1 and application 2 is in group 2
+	assert.Nil(t, db.Create([]*groupmodels.Group{
+		{Model: global.Model{ID: 1}, Name: "a", Path: "a", TraversalIDs: "1"},
+		{Model: global.Model{ID: 2}, Name: "b", Path: "b", ParentID: 1, TraversalIDs: "1,2"},
+		{Model: global.Model{ID: 3}, Name: "c", Path: "c", TraversalIDs: "3"},
+	}).Error)
+	assert.Nil(t, db.Create([]*appmodels.Application{
+		{Model: global.Model{ID: 1}, Name: "app-1", GroupID: 1},
+		{Model: global.Model{ID: 2}, Name: "app-2", GroupID: 2},
+	}).Error)
+	assert.Nil(t, db.Create([]*tagmodels.Tag{
+		{ResourceType: common.ResourceCluster, ResourceID: 1, Key: "team", Value: "x"},
+		{ResourceType: common.ResourceCluster, ResourceID: 2, Key: "team", Value: "y"},
+	}).Error)
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, mgr.Accumulate(ctx, "hz", now, []*models.ClusterCost{
+			{ClusterID: 1, ApplicationID: 1, Date: "2026-10-01", CPUHours: 2, MemoryHours: 4, Cost: 1},
+			{ClusterID: 2, ApplicationID: 2, Date: "2026-10-01", CPUHours: 1, MemoryHours: 2, Cost: 0.5},
+		}))
+	}
+	assert.Nil(t, mgr.Accumulate(ctx, "hz", now.Add(24*time.Hour), []*models.ClusterCost{
+		{ClusterID: 2, ApplicationID: 2, Date: "2026-10-02", CPUHours: 1, MemoryHours: 2, Cost: 0.5},
+	}))
+	assert.Nil(t, mgr.Accumulate(ctx, "sh", now, nil))
+	accountedAt, err := mgr.ListAccountedAt(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(accountedAt))
+	assert.True(t, now.Add(24*time.Hour).Equal(accountedAt["hz"]))
+	assert.True(t, now.Equal(accountedAt["sh"]))
+
+	query := &models.CostQuery{From: "2026-10-01", To: "2026-10-31", GroupTraversalIDs: "1"}
+	costs, err := mgr.Sum(ctx, query, SumByDate)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(costs))
+	assert.Equal(t, "2026-10-01", costs[0].Date)
+	assert.Equal(t, float64(6), costs[0].CPUHours)
+	assert.Equal(t, float64(3), costs[0].Cost)
+	assert.Equal(t, float64(0.5), costs[1].Cost)
+
+	costs, err = mgr.Sum(ctx, &models.CostQuery{From: "2026-10-01", To: "2026-10-01",
+		GroupTraversalIDs: "1,2"}, SumByCluster)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(costs))
+	assert.Equal(t, uint(2), costs[0].ClusterID)
+	assert.Equal(t, float64(1), costs[0].Cost)
+
+	costs, err = mgr.Sum(ctx, &models.CostQuery{From: "2026-10-01", To: "2026-10-31",
+		GroupTraversalIDs: "3"}, SumByDate)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(costs))
+
+	costs, err = mgr.SumByTag(ctx, &models.CostQuery{From: "2026-10-01", To: "2026-10-31"}, "team")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(costs))
+	assert.Equal(t, "x", costs[0].TagValue)
+	assert.Equal(t, float64(2), costs[0].Cost)
+	assert.Equal(t, float64(1.5), costs[1].Cost)
+
+	_, err = mgr.GetBudget(ctx, common.ResourceGroup, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	budget, err := mgr.SaveBudget(ctx, &models.Budget{ResourceType: common.ResourceGroup, ResourceID: 1,
+		Amount: 100, Thresholds: "80,100"})
+	assert.Nil(t, err)
+	assert.Nil(t, mgr.UpdateBudgetNotified(ctx, budget.ID, "2026-10", 80))
+	budget, err = mgr.SaveBudget(ctx, &models.Budget{ResourceType: common.ResourceGroup, ResourceID: 1,
+		Amount: 200, Thresholds: "100"})
+	assert.Nil(t, err)
+	assert.Equal(t, float64(200), budget.Amount)
+	assert.Equal(t, uint(0), budget.NotifiedThreshold)
+	budgets, err := mgr.ListBudgets(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(budgets))
+}
@@ -0,0 +1,117 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// ClusterCost is the cost of a cluster in a day by its requested resources over time,
+// the application, region and environment are the ones when the cost is accumulated
+type ClusterCost struct {
+	global.Model
+
+	ClusterID       uint
+	ApplicationID   uint
+	RegionName      string
+	EnvironmentName string
+	// Date is formatted as 2006-01-02
+	Date        string
+	CPUHours    float64
+	MemoryHours float64
+	GPUHours    float64
+	Cost        float64
+}
+
+// Cost is the cost summed by the date, the cluster or the tag value
+type Cost struct {
+	Date        string
+	ClusterID   uint
+	TagValue    string
+	CPUHours    float64
+	MemoryHours float64
+	GPUHours    float64
+	Cost        float64
+}
+
+// Accounting is the checkpoint of the cost accumulated in a region, the cost of the time
+// elapsed since AccountedAt is accumulated in the next run of the job on any instance
+type Accounting struct {
+	global.Model
+
+	RegionName  string
+	AccountedAt time.Time
+}
+
+func (Accounting) TableName() string {
+	return "tb_cost_accounting"
+}
+
+// Budget is the monthly budget of a group, including its subgroups, an application or a cluster
+type Budget struct {
+	global.Model
+
+	ResourceType string
+	ResourceID   uint
+	Amount       float64
+	// Thresholds are the comma separated percentages of the amount, an event is emitted
+	// once a month when the cost of the month exceeds each of them, such as 80,100
+	Thresholds string
+	// NotifiedMonth and NotifiedThreshold are the month formatted as 2006-01
+	// and the highest threshold notified in it
+	NotifiedMonth     string
+	NotifiedThreshold uint
+	CreatedBy         uint
+	UpdatedBy         uint
+}
+
+func (Budget) TableName() string {
+	return "tb_cost_budget"
+}
+
+// ParseThresholds parses the comma separated percentages into ascending order
+func ParseThresholds(thresholds string) ([]uint, error) {
+	result := make([]uint, 0)
+	for _, s := range strings.Split(thresholds, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		threshold, err := strconv.ParseUint(s, 10, 32)
+		if err != nil || threshold == 0 {
+			return nil, fmt.Errorf("invalid threshold %q, it should be a positive integer", s)
+		}
+		result = append(result, uint(threshold))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result, nil
+}
+
+// CostQuery selects the costs of clusters between the dates inclusively, formatted as 2006-01-02,
+// the costs of all clusters are selected without the cluster, the application and the group
+type CostQuery struct {
+	From          string
+	To            string
+	ClusterID     uint
+	ApplicationID uint
+	// GroupTraversalIDs selects the clusters of the applications in the group and its subgroups
+	GroupTraversalIDs string
+}
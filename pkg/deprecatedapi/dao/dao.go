@@ -0,0 +1,78 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+)
+
+type DAO interface {
+	Replace(ctx context.Context, resourceType string, resourceID uint, region string,
+		deprecatedAPIs []*models.DeprecatedAPI) error
+	DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error
+	List(ctx context.Context) ([]*models.DeprecatedAPI, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Replace(ctx context.Context, resourceType string, resourceID uint, region string,
+	deprecatedAPIs []*models.DeprecatedAPI) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// findings are replaced as a whole, so they are deleted permanently
+		if err := tx.Unscoped().Where("resource_type = ? and resource_id = ? and region = ?",
+			resourceType, resourceID, region).Delete(&models.DeprecatedAPI{}).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.DeprecatedAPIInDB, err.Error())
+		}
+		if len(deprecatedAPIs) == 0 {
+			return nil
+		}
+		for _, deprecatedAPI := range deprecatedAPIs {
+			deprecatedAPI.ResourceType, deprecatedAPI.ResourceID, deprecatedAPI.Region =
+				resourceType, resourceID, region
+		}
+		if err := tx.Create(deprecatedAPIs).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.DeprecatedAPIInDB, err.Error())
+		}
+		return nil
+	})
+}
+
+func (d *dao) DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error {
+	if err := d.db.WithContext(ctx).Unscoped().Where("resource_type = ? and resource_id = ?",
+		resourceType, resourceID).Delete(&models.DeprecatedAPI{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.DeprecatedAPIInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) List(ctx context.Context) ([]*models.DeprecatedAPI, error) {
+	var deprecatedAPIs []*models.DeprecatedAPI
+	if err := d.db.WithContext(ctx).Order("region, resource_type, resource_id, id").
+		Find(&deprecatedAPIs).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.DeprecatedAPIInDB, err.Error())
+	}
+	return deprecatedAPIs, nil
+}
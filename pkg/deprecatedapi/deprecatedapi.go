@@ -0,0 +1,234 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deprecatedapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+)
+
+// statuses of the apis used by objects in a region
+const (
+	// StatusRemoved means the api is not served by the region, objects of it can not be deployed
+	StatusRemoved = "removed"
+	// StatusDeprecated means the api is served by the region but will be removed in a later version,
+	// objects of it block the region from upgrading to the version
+	StatusDeprecated = "deprecated"
+)
+
+// Deprecation is a deprecated kubernetes api, the versions are the kubernetes versions
+// in which it is deprecated and removed
+type Deprecation struct {
+	APIVersion   string
+	Kind         string
+	DeprecatedIn string
+	RemovedIn    string
+	// Replacement is the api to migrate to, empty if there is no replacement
+	Replacement string
+}
+
+// Deprecations are the well-known deprecated apis of kubernetes,
+// see https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var Deprecations = []*Deprecation{
+	{"extensions/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.9", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "1.11", "1.16", "policy/v1beta1"},
+	{"apps/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "Ingress", "1.14", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.16", "1.22", "apiextensions.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.16", "1.22",
+		"admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.16", "1.22",
+		"admissionregistration.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.14", "1.22", "scheduling.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.14", "1.22", "coordination.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "1.21", "1.25", "batch/v1"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.21", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.21", "1.25", ""},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.21", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.19", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.22", "1.25", "autoscaling/v2"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.23", "1.26", "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "1.23", "1.26",
+		"flowcontrol.apiserver.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.24", "1.27", "storage.k8s.io/v1"},
+}
+
+// Lookup returns the deprecation of the api, or nil if it's not deprecated
+func Lookup(apiVersion, kind string) *Deprecation {
+	for _, d := range Deprecations {
+		if d.APIVersion == apiVersion && d.Kind == kind {
+			return d
+		}
+	}
+	return nil
+}
+
+// RegionAPIs are the server version and the apis served by a region
+type RegionAPIs struct {
+	ServerVersion string
+	// Served are the kinds served by the group versions, it's nil if they are not discovered
+	Served map[string]map[string]bool
+
+	version *version.Version
+	// undiscovered are the group versions failed to be discovered
+	undiscovered map[string]bool
+}
+
+// Discover discovers the server version and the served apis of a region. The apis are left
+// undiscovered if the discovery fails, then apis are checked by the server version only.
+func Discover(client discovery.DiscoveryInterface) (*RegionAPIs, error) {
+	info, err := client.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	apis, err := NewRegionAPIs(info.GitVersion)
+	if err != nil {
+		return nil, err
+	}
+	_, resourceLists, err := client.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return apis, nil
+	}
+	apis.Served = make(map[string]map[string]bool)
+	apis.undiscovered = make(map[string]bool)
+	if failed, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+		for gv := range failed.Groups {
+			apis.undiscovered[gv.String()] = true
+		}
+	}
+	for _, resourceList := range resourceLists {
+		kinds := make(map[string]bool)
+		for _, resource := range resourceList.APIResources {
+			kinds[resource.Kind] = true
+		}
+		apis.Served[resourceList.GroupVersion] = kinds
+	}
+	return apis, nil
+}
+
+// NewRegionAPIs returns the apis of a region of the server version without served apis discovered
+func NewRegionAPIs(serverVersion string) (*RegionAPIs, error) {
+	v, err := version.ParseGeneric(serverVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server version %s: %v", serverVersion, err)
+	}
+	return &RegionAPIs{ServerVersion: serverVersion, version: v}, nil
+}
+
+// Capabilities returns the capabilities which charts are rendered with for the region,
+// the default api versions are used if the served apis are not discovered
+func (r *RegionAPIs) Capabilities() *chartutil.Capabilities {
+	capabilities := &chartutil.Capabilities{
+		KubeVersion: chartutil.KubeVersion{
+			Version: r.ServerVersion,
+			Major:   strconv.FormatUint(uint64(r.version.Major()), 10),
+			Minor:   strconv.FormatUint(uint64(r.version.Minor()), 10),
+		},
+		APIVersions: chartutil.DefaultVersionSet,
+		HelmVersion: chartutil.DefaultCapabilities.HelmVersion,
+	}
+	if r.Served == nil {
+		return capabilities
+	}
+	apiVersions := make(chartutil.VersionSet, 0, len(r.Served)+len(r.undiscovered))
+	for groupVersion, kinds := range r.Served {
+		apiVersions = append(apiVersions, groupVersion)
+		for kind := range kinds {
+			apiVersions = append(apiVersions, groupVersion+"/"+kind)
+		}
+	}
+	for groupVersion := range r.undiscovered {
+		apiVersions = append(apiVersions, groupVersion)
+	}
+	sort.Strings(apiVersions)
+	capabilities.APIVersions = apiVersions
+	return capabilities
+}
+
+// Serves returns whether the api is served by the region
+func (r *RegionAPIs) Serves(d *Deprecation) bool {
+	if r.Served != nil && !r.undiscovered[d.APIVersion] {
+		return r.Served[d.APIVersion][d.Kind]
+	}
+	return !r.version.AtLeast(version.MustParseGeneric(d.RemovedIn))
+}
+
+// Finding is an object using a deprecated api in a region
+type Finding struct {
+	*Deprecation
+	Name   string
+	Status string
+}
+
+// String describes the finding and the api to migrate to
+func (f *Finding) String() string {
+	description := fmt.Sprintf("%s/%s uses %s which is deprecated in %s and removed in %s",
+		f.Kind, f.Name, f.APIVersion, f.DeprecatedIn, f.RemovedIn)
+	if f.Replacement == "" {
+		return description
+	}
+	return fmt.Sprintf("%s, migrate to %s", description, f.Replacement)
+}
+
+// Check returns the findings of the objects using deprecated apis in the region
+func Check(objects []*unstructured.Unstructured, apis *RegionAPIs) []*Finding {
+	findings := make([]*Finding, 0)
+	for _, object := range objects {
+		d := Lookup(object.GetAPIVersion(), object.GetKind())
+		if d == nil {
+			continue
+		}
+		status := StatusDeprecated
+		if !apis.Serves(d) {
+			status = StatusRemoved
+		}
+		findings = append(findings, &Finding{Deprecation: d, Name: object.GetName(), Status: status})
+	}
+	return findings
+}
+
+// Removed returns the findings of removed apis, which block deploys
+func Removed(findings []*Finding) []*Finding {
+	removed := make([]*Finding, 0)
+	for _, finding := range findings {
+		if finding.Status == StatusRemoved {
+			removed = append(removed, finding)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deprecatedapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chartutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCheck(t *testing.T) {
+	object := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		o := &unstructured.Unstructured{}
+		o.SetAPIVersion(apiVersion)
+		o.SetKind(kind)
+		o.SetName(name)
+		return o
+	}
+	objects := []*unstructured.Unstructured{
+		object("apps/v1", "Deployment", "app"),
+		object("extensions/v1beta1", "Ingress", "app"),
+		object("policy/v1beta1", "PodDisruptionBudget", "app"),
+	}
+
+	client := &fake.FakeDiscovery{
+		Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+			{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}}},
+			{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "ingresses", Kind: "Ingress"}}},
+			{GroupVersion: "policy/v1beta1", APIResources: []metav1.APIResource{
+				{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}}},
+		}},
+		FakedServerVersion: &k8sversion.Info{GitVersion: "v1.22.3-eks"},
+	}
+	apis, err := Discover(client)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.22.3-eks", apis.ServerVersion)
+	capabilities := apis.Capabilities()
+	assert.Equal(t, "v1.22.3-eks", capabilities.KubeVersion.Version)
+	assert.Equal(t, "22", capabilities.KubeVersion.Minor)
+	assert.Equal(t, chartutil.VersionSet{"apps/v1", "apps/v1/Deployment", "networking.k8s.io/v1",
+		"networking.k8s.io/v1/Ingress", "policy/v1beta1", "policy/v1beta1/PodDisruptionBudget"},
+		capabilities.APIVersions)
+
+	findings := Check(objects, apis)
+	assert.Equal(t, 2, len(findings))
+	assert.Equal(t, "Ingress", findings[0].Kind)
+	assert.Equal(t, StatusRemoved, findings[0].Status)
+	assert.Equal(t, "networking.k8s.io/v1", findings[0].Replacement)
+	assert.Equal(t, "PodDisruptionBudget", findings[1].Kind)
+	assert.Equal(t, StatusDeprecated, findings[1].Status)
+	assert.Equal(t, []*Finding{findings[0]}, Removed(findings))
+	assert.Equal(t, "Ingress/app uses extensions/v1beta1 which is deprecated in 1.14 and removed in 1.22, "+
+		"migrate to networking.k8s.io/v1", findings[0].String())
+
+	// apis are checked by the server version if they are not discovered
+	apis, err = NewRegionAPIs("v1.19.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.19.0", apis.Capabilities().KubeVersion.Version)
+	assert.Equal(t, chartutil.DefaultVersionSet, apis.Capabilities().APIVersions)
+	findings = Check(objects, apis)
+	assert.Equal(t, 2, len(findings))
+	assert.Equal(t, 0, len(Removed(findings)))
+	apis, err = NewRegionAPIs("v1.25.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(Removed(Check(objects, apis))))
+
+	_, err = NewRegionAPIs("unknown")
+	assert.NotNil(t, err)
+}
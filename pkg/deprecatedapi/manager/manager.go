@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/dao"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+)
+
+type Manager interface {
+	// Replace replaces the deprecated apis used by the cluster or the template release in the region
+	Replace(ctx context.Context, resourceType string, resourceID uint, region string,
+		deprecatedAPIs []*models.DeprecatedAPI) error
+	// DeleteByResource deletes the deprecated apis used by the cluster or the template release in all regions
+	DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error
+	// List lists the deprecated apis ordered by region
+	List(ctx context.Context) ([]*models.DeprecatedAPI, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Replace(ctx context.Context, resourceType string, resourceID uint, region string,
+	deprecatedAPIs []*models.DeprecatedAPI) error {
+	return m.dao.Replace(ctx, resourceType, resourceID, region, deprecatedAPIs)
+}
+
+func (m *manager) DeleteByResource(ctx context.Context, resourceType string, resourceID uint) error {
+	return m.dao.DeleteByResource(ctx, resourceType, resourceID)
+}
+
+func (m *manager) List(ctx context.Context) ([]*models.DeprecatedAPI, error) {
+	return m.dao.List(ctx)
+}
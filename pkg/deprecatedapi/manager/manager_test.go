@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+)
+
+func TestDeprecatedAPIs(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.DeprecatedAPI{}))
+	mgr := New(db)
+	ctx := context.Background()
+
+	ingress := func(status string) *models.DeprecatedAPI {
+		return &models.DeprecatedAPI{APIVersion: "extensions/v1beta1", Kind: "Ingress",
+			Name: "app", Status: status, DeprecatedIn: "1.14", RemovedIn: "1.22"}
+	}
+	assert.Nil(t, mgr.Replace(ctx, common.ResourceTemplateRelease, 1, "hz",
+		[]*models.DeprecatedAPI{ingress("deprecated")}))
+	assert.Nil(t, mgr.Replace(ctx, common.ResourceTemplateRelease, 1, "sh",
+		[]*models.DeprecatedAPI{ingress("removed")}))
+	assert.Nil(t, mgr.Replace(ctx, common.ResourceCluster, 1, "hz",
+		[]*models.DeprecatedAPI{ingress("deprecated")}))
+
+	deprecatedAPIs, err := mgr.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(deprecatedAPIs))
+	assert.Equal(t, common.ResourceCluster, deprecatedAPIs[0].ResourceType)
+	assert.Equal(t, "sh", deprecatedAPIs[2].Region)
+
+	// the ones in other regions are kept
+	assert.Nil(t, mgr.Replace(ctx, common.ResourceTemplateRelease, 1, "hz", nil))
+	deprecatedAPIs, err = mgr.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deprecatedAPIs))
+
+	assert.Nil(t, mgr.DeleteByResource(ctx, common.ResourceTemplateRelease, 1))
+	deprecatedAPIs, err = mgr.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(deprecatedAPIs))
+	assert.Equal(t, uint(1), deprecatedAPIs[0].ResourceID)
+}
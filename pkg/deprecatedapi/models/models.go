@@ -0,0 +1,42 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// DeprecatedAPI is an object of a cluster or a template release using a deprecated api in a region,
+// template releases are rendered with their default values for every region
+type DeprecatedAPI struct {
+	global.Model
+
+	// ResourceType is clusters or templatereleases
+	ResourceType string
+	ResourceID   uint
+	Region       string
+	APIVersion   string
+	Kind         string
+	Name         string
+	// Status is removed or deprecated in the region
+	Status       string
+	DeprecatedIn string
+	RemovedIn    string
+	Replacement  string
+}
+
+func (DeprecatedAPI) TableName() string {
+	return "tb_deprecated_api"
+}
@@ -0,0 +1,196 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
+	"github.com/horizoncd/horizon/pkg/cluster/manifest"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi"
+	deprecatedapimanager "github.com/horizoncd/horizon/pkg/deprecatedapi/manager"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/render"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	// CheckRunName is the name of the check run reporting the deprecated apis used by a pipelinerun
+	CheckRunName = "deprecated-api"
+
+	// _discoveryTTL is the duration the discovered apis of regions are cached
+	_discoveryTTL = 10 * time.Minute
+	// _defaultNamespace is the namespace template releases are rendered in
+	_defaultNamespace = "default"
+)
+
+type Service interface {
+	// CheckCluster checks the objects rendered from the chart of the cluster with the values at the commit
+	// and the capabilities of its region against the apis served by the region, and saves the findings
+	// for the fleet report
+	CheckCluster(ctx context.Context, application *appmodels.Application, cluster *clustermodels.Cluster,
+		tr *trmodels.TemplateRelease, commit string) ([]*deprecatedapi.Finding, error)
+	// CheckTemplateRelease checks the objects rendered from the chart of the template release with its
+	// default values and the capabilities of every region against the apis served by the region, and saves
+	// the findings for the fleet report. Regions failed to be discovered are skipped.
+	CheckTemplateRelease(ctx context.Context, tr *trmodels.TemplateRelease) error
+	// RegionAPIs returns the server version and the apis served by the region
+	RegionAPIs(ctx context.Context, region *regionmodels.Region) (*deprecatedapi.RegionAPIs, error)
+}
+
+type discovered struct {
+	apis         *deprecatedapi.RegionAPIs
+	discoveredAt time.Time
+}
+
+type service struct {
+	deprecatedAPIMgr deprecatedapimanager.Manager
+	regionMgr        regionmanager.Manager
+	renderer         manifest.Renderer
+	templateRepo     templaterepo.TemplateRepo
+	discover         func(region *regionmodels.Region) (*deprecatedapi.RegionAPIs, error)
+
+	lock  sync.Mutex
+	cache map[string]*discovered
+}
+
+var _ Service = (*service)(nil)
+
+func NewService(manager *managerparam.Manager, clusterGitRepo gitrepo.ClusterGitRepo,
+	templateRepo templaterepo.TemplateRepo) Service {
+	return &service{
+		deprecatedAPIMgr: manager.DeprecatedAPIMgr,
+		regionMgr:        manager.RegionMgr,
+		renderer:         manifest.NewRenderer(clusterGitRepo, templateRepo),
+		templateRepo:     templateRepo,
+		discover:         discover,
+		cache:            make(map[string]*discovered),
+	}
+}
+
+func discover(region *regionmodels.Region) (*deprecatedapi.RegionAPIs, error) {
+	_, client, err := kubeclient.Fty.GetByK8SServer(region.Server, region.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	return deprecatedapi.Discover(client.Basic.Discovery())
+}
+
+func (s *service) CheckCluster(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, tr *trmodels.TemplateRelease, commit string) ([]*deprecatedapi.Finding, error) {
+	region, err := s.regionMgr.GetRegionByName(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+	apis, err := s.RegionAPIs(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.renderer.Render(ctx, application, cluster, tr, commit, apis.Capabilities())
+	if err != nil {
+		return nil, err
+	}
+	findings := deprecatedapi.Check(objects, apis)
+	// findings in the previous region are dropped when the cluster is moved
+	if err := s.deprecatedAPIMgr.DeleteByResource(ctx, common.ResourceCluster, cluster.ID); err != nil {
+		return nil, err
+	}
+	if err := s.deprecatedAPIMgr.Replace(ctx, common.ResourceCluster, cluster.ID, region.Name,
+		toModels(findings)); err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
+
+func (s *service) CheckTemplateRelease(ctx context.Context, tr *trmodels.TemplateRelease) error {
+	chrt, err := s.templateRepo.GetChart(tr.ChartName, tr.ChartVersion, tr.LastSyncAt)
+	if err != nil {
+		return err
+	}
+	regions, err := s.regionMgr.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, region := range regions {
+		apis, err := s.RegionAPIs(ctx, region)
+		if err != nil {
+			log.Warningf(ctx, "failed to discover apis of region %s: %v", region.Name, err)
+			continue
+		}
+		// charts may render different apis by the capabilities of regions
+		manifests, err := render.Render(chrt, nil, &render.Release{
+			Name:         tr.ChartName,
+			Namespace:    _defaultNamespace,
+			Capabilities: apis.Capabilities(),
+		})
+		if err != nil {
+			return err
+		}
+		objects, err := render.Objects(manifests)
+		if err != nil {
+			return err
+		}
+		if err := s.deprecatedAPIMgr.Replace(ctx, common.ResourceTemplateRelease, tr.ID, region.Name,
+			toModels(deprecatedapi.Check(objects, apis))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) RegionAPIs(ctx context.Context, region *regionmodels.Region) (*deprecatedapi.RegionAPIs, error) {
+	s.lock.Lock()
+	cached, ok := s.cache[region.Name]
+	s.lock.Unlock()
+	if ok && time.Since(cached.discoveredAt) < _discoveryTTL {
+		return cached.apis, nil
+	}
+
+	apis, err := s.discover(region)
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	s.cache[region.Name] = &discovered{apis: apis, discoveredAt: time.Now()}
+	s.lock.Unlock()
+	return apis, nil
+}
+
+func toModels(findings []*deprecatedapi.Finding) []*models.DeprecatedAPI {
+	deprecatedAPIs := make([]*models.DeprecatedAPI, 0, len(findings))
+	for _, finding := range findings {
+		deprecatedAPIs = append(deprecatedAPIs, &models.DeprecatedAPI{
+			APIVersion:   finding.APIVersion,
+			Kind:         finding.Kind,
+			Name:         finding.Name,
+			Status:       finding.Status,
+			DeprecatedIn: finding.DeprecatedIn,
+			RemovedIn:    finding.RemovedIn,
+			Replacement:  finding.Replacement,
+		})
+	}
+	return deprecatedAPIs
+}
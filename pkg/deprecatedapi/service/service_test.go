@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&regionmodels.Region{}, &models.DeprecatedAPI{}); err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+	manager := managerparam.InitManager(db)
+
+	assert.Nil(t, db.Save(&regionmodels.Region{Name: "old"}).Error)
+	assert.Nil(t, db.Save(&regionmodels.Region{Name: "new"}).Error)
+	assert.Nil(t, db.Save(&regionmodels.Region{Name: "unreachable"}).Error)
+	application := &appmodels.Application{Name: "app"}
+	cluster := &clustermodels.Cluster{Name: "app-test", RegionName: "new"}
+	cluster.ID = 1
+	tr := &trmodels.TemplateRelease{ChartName: "javaapp", ChartVersion: "v1.0.0"}
+	tr.ID = 1
+
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	clusterGitRepo.EXPECT().GetDeployValues(gomock.Any(), application.Name, cluster.Name, "commit").
+		Return([]map[string]interface{}{{"javaapp": map[string]interface{}{"hpa": true}}}, nil).AnyTimes()
+	templateRepo := repomock.NewMockTemplateRepo(mockCtl)
+	templateRepo.EXPECT().GetChart("javaapp", "v1.0.0", gomock.Any()).Return(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "javaapp", Version: "v1.0.0", APIVersion: chart.APIVersionV2},
+		Values:   map[string]interface{}{"hpa": false},
+		Templates: []*chart.File{
+			{Name: "templates/ingress.yaml", Data: []byte(`{{- if .Capabilities.APIVersions.Has "networking.k8s.io/v1/Ingress" }}
+apiVersion: networking.k8s.io/v1
+{{- else }}
+apiVersion: extensions/v1beta1
+{{- end }}
+kind: Ingress
+metadata:
+  name: {{ .Release.Name }}
+`)},
+			{Name: "templates/hpa.yaml", Data: []byte(`{{- if .Values.hpa }}
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ .Release.Name }}
+{{- end }}
+`)},
+		},
+	}, nil).AnyTimes()
+
+	s := NewService(manager, clusterGitRepo, templateRepo).(*service)
+	discoveries := 0
+	s.discover = func(region *regionmodels.Region) (*deprecatedapi.RegionAPIs, error) {
+		discoveries++
+		switch region.Name {
+		case "old":
+			return deprecatedapi.NewRegionAPIs("v1.20.0")
+		case "new":
+			apis, err := deprecatedapi.NewRegionAPIs("v1.23.0")
+			if err != nil {
+				return nil, err
+			}
+			apis.Served = map[string]map[string]bool{
+				"networking.k8s.io/v1": {"Ingress": true},
+				"autoscaling/v2beta2":  {"HorizontalPodAutoscaler": true},
+			}
+			return apis, nil
+		}
+		return nil, assert.AnError
+	}
+
+	// the ingress is rendered by the apis served by the region
+	findings, err := s.CheckCluster(ctx, application, cluster, tr, "commit")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(findings))
+	assert.Equal(t, "HorizontalPodAutoscaler", findings[0].Kind)
+	assert.Equal(t, deprecatedapi.StatusDeprecated, findings[0].Status)
+
+	// template releases are rendered by the capabilities of each region
+	assert.Nil(t, s.CheckTemplateRelease(ctx, tr))
+	deprecatedAPIs, err := manager.DeprecatedAPIMgr.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deprecatedAPIs))
+	assert.Equal(t, common.ResourceCluster, deprecatedAPIs[0].ResourceType)
+	assert.Equal(t, "old", deprecatedAPIs[1].Region)
+	assert.Equal(t, common.ResourceTemplateRelease, deprecatedAPIs[1].ResourceType)
+	assert.Equal(t, "Ingress", deprecatedAPIs[1].Kind)
+	assert.Equal(t, deprecatedapi.StatusDeprecated, deprecatedAPIs[1].Status)
+
+	// discovered apis are cached, and failures are not
+	assert.Equal(t, 3, discoveries)
+}
@@ -14,21 +14,33 @@
 
 package service
 
+import "sync"
+
 type AutoFreeSVC struct {
+	sync.RWMutex
 	envsWithAutoFree map[string]struct{}
 }
 
 func New(envsWithAutoFreeArr []string) *AutoFreeSVC {
+	s := &AutoFreeSVC{}
+	s.SetSupportedEnvs(envsWithAutoFreeArr)
+	return s
+}
+
+// SetSupportedEnvs replaces the environments supporting auto-free
+func (s *AutoFreeSVC) SetSupportedEnvs(envsWithAutoFreeArr []string) {
 	m := make(map[string]struct{})
 	for _, env := range envsWithAutoFreeArr {
 		m[env] = struct{}{}
 	}
-	return &AutoFreeSVC{
-		envsWithAutoFree: m,
-	}
+	s.Lock()
+	defer s.Unlock()
+	s.envsWithAutoFree = m
 }
 
 func (s *AutoFreeSVC) WhetherSupported(env string) bool {
+	s.RLock()
+	defer s.RUnlock()
 	_, ok := s.envsWithAutoFree[env]
 	return ok
 }
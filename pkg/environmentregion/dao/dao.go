@@ -44,6 +44,8 @@ type DAO interface {
 	GetDefaultRegions(ctx context.Context) ([]*models.EnvironmentRegion, error)
 	// SetEnvironmentRegionToDefaultByID set region to default by id
 	SetEnvironmentRegionToDefaultByID(ctx context.Context, id uint) error
+	// UpdateCDConfigByID update argo cd and tekton config of an environmentRegion by id
+	UpdateCDConfigByID(ctx context.Context, id uint, argoCD, tekton string) error
 	// DeleteByID delete an environmentRegion by id
 	DeleteByID(ctx context.Context, id uint) error
 }
@@ -212,3 +214,11 @@ func (d *dao) DeleteByID(ctx context.Context, id uint) error {
 
 	return nil
 }
+
+func (d *dao) UpdateCDConfigByID(ctx context.Context, id uint, argoCD, tekton string) error {
+	result := d.db.WithContext(ctx).Exec(common.EnvironmentRegionUpdateCDConfigByID, argoCD, tekton, id)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.EnvironmentRegionInDB, result.Error.Error())
+	}
+	return nil
+}
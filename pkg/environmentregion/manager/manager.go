@@ -44,6 +44,8 @@ type Manager interface {
 	SetEnvironmentRegionToDefaultByID(ctx context.Context, id uint) error
 	// ListAllEnvironmentRegions list all environmentRegions
 	ListAllEnvironmentRegions(ctx context.Context) ([]*models.EnvironmentRegion, error)
+	// UpdateCDConfigByID update the json encoded argo cd and tekton config of an environmentRegion
+	UpdateCDConfigByID(ctx context.Context, id uint, argoCD, tekton string) error
 	DeleteByID(ctx context.Context, id uint) error
 }
 
@@ -96,3 +98,7 @@ func (m *manager) SetEnvironmentRegionToDefaultByID(ctx context.Context, id uint
 func (m *manager) ListAllEnvironmentRegions(ctx context.Context) ([]*models.EnvironmentRegion, error) {
 	return m.envRegionDAO.ListAllEnvironmentRegions(ctx)
 }
+
+func (m *manager) UpdateCDConfigByID(ctx context.Context, id uint, argoCD, tekton string) error {
+	return m.envRegionDAO.UpdateCDConfigByID(ctx, id, argoCD, tekton)
+}
@@ -118,6 +118,14 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, devHzErNew.IsDefault, false)
 
+	// test UpdateCDConfigByID
+	err = mgr.UpdateCDConfigByID(ctx, devHzErNew.ID, `{"url":"http://hz.argo.com"}`, "")
+	assert.Nil(t, err)
+	devHzErNew, err = mgr.GetEnvironmentRegionByID(ctx, devHzEr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"url":"http://hz.argo.com"}`, devHzErNew.ArgoCD)
+	assert.Equal(t, "", devHzErNew.Tekton)
+
 	// test deleteByID
 	err = mgr.DeleteByID(ctx, devHzErNew.ID)
 	assert.Nil(t, err)
@@ -26,4 +26,9 @@ type EnvironmentRegion struct {
 	IsDefault       bool
 	CreatedBy       uint
 	UpdatedBy       uint
+
+	// ArgoCD is the json encoded argo cd config mapped to the environment in the region
+	ArgoCD string `gorm:"column:argocd"`
+	// Tekton is the json encoded tekton config mapped to the environment in the region
+	Tekton string
 }
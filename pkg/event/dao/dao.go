@@ -37,6 +37,8 @@ type DAO interface {
 		eventIndex *models.EventCursor) (*models.EventCursor, error)
 	GetCursor(ctx context.Context) (*models.EventCursor, error)
 	GetEvent(ctx context.Context, id uint) (*models.Event, error)
+	// GetLatestEventID returns the id of the latest event, or 0 if there are no events
+	GetLatestEventID(ctx context.Context) (uint, error)
 	DeleteEvents(ctx context.Context, id ...uint) (int64, error)
 }
 
@@ -77,6 +79,13 @@ func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.Event, error)
 			statement = statement.Where("id <= ?", v)
 		case common.ReqID:
 			statement = statement.Where("req_id = ?", v)
+		case common.ParamResourceType:
+			statement = statement.Where("resource_type = ?", v)
+		case common.ParamResourceID:
+			statement = statement.Where("resource_id = ?", v)
+		case common.EventType:
+			// v is a slice of event types
+			statement = statement.Where("event_type in ?", v)
 		}
 	}
 
@@ -98,6 +107,15 @@ func (d *dao) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
 	return event, nil
 }
 
+func (d *dao) GetLatestEventID(ctx context.Context) (uint, error) {
+	var id uint
+	if result := d.db.WithContext(ctx).Model(&models.Event{}).
+		Select("COALESCE(MAX(id), 0)").Scan(&id); result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.EventInDB, result.Error.Error())
+	}
+	return id, nil
+}
+
 func (d *dao) CreateOrUpdateCursor(ctx context.Context,
 	eventCursor *models.EventCursor) (*models.EventCursor, error) {
 	if result := d.db.Clauses(clause.OnConflict{
@@ -37,6 +37,8 @@ type Manager interface {
 		eventIndex *models.EventCursor) (*models.EventCursor, error)
 	GetCursor(ctx context.Context) (*models.EventCursor, error)
 	GetEvent(ctx context.Context, id uint) (*models.Event, error)
+	// GetLatestEventID returns the id of the latest event, or 0 if there are no events
+	GetLatestEventID(ctx context.Context) (uint, error)
 	ListSupportEvents() map[string]string
 	DeleteEvents(ctx context.Context, id ...uint) (int64, error)
 }
@@ -54,7 +56,8 @@ func New(db *gorm.DB) Manager {
 func (m *manager) CreateEvent(ctx context.Context,
 	events ...*models.Event) ([]*models.Event, error) {
 	const op = "event manager: create event"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	for _, event := range events {
 		if event.ReqID == "" {
@@ -76,19 +79,22 @@ func (m *manager) CreateEvent(ctx context.Context,
 func (m *manager) CreateOrUpdateCursor(ctx context.Context,
 	eventCursor *models.EventCursor) (*models.EventCursor, error) {
 	const op = "event manager: create or update cursor"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.CreateOrUpdateCursor(ctx, eventCursor)
 }
 
 func (m *manager) GetCursor(ctx context.Context) (*models.EventCursor, error) {
 	const op = "event manager: get cursor"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.GetCursor(ctx)
 }
 
 func (m *manager) ListEvents(ctx context.Context, query *q.Query) ([]*models.Event, error) {
 	const op = "event manager: list events"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	if query == nil {
 		query = &q.Query{}
 	}
@@ -100,7 +106,8 @@ func (m *manager) ListEvents(ctx context.Context, query *q.Query) ([]*models.Eve
 
 func (m *manager) ListEventsByRange(ctx context.Context, start, end uint) ([]*models.Event, error) {
 	const op = "event manager: list events by range"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.List(ctx, &q.Query{
 		Keywords: q.KeyWords{
 			common.StartID: start,
@@ -111,13 +118,22 @@ func (m *manager) ListEventsByRange(ctx context.Context, start, end uint) ([]*mo
 
 func (m *manager) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
 	const op = "event manager: get event"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.GetEvent(ctx, id)
 }
 
+func (m *manager) GetLatestEventID(ctx context.Context) (uint, error) {
+	const op = "event manager: get latest event id"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+	return m.dao.GetLatestEventID(ctx)
+}
+
 func (m *manager) DeleteEvents(ctx context.Context, id ...uint) (int64, error) {
 	const op = "event manager: delete event"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.DeleteEvents(ctx, id...)
 }
 
@@ -126,6 +142,7 @@ var supportedEvents = map[string]string{
 	models.ApplicationDeleted:     "Application has been deleted",
 	models.ApplicationTransfered:  "Application has been transferred to another group",
 	models.ApplicationUpdated:     "Application has been updated",
+	models.ApplicationOverBudget:  "Cost of application has exceeded a threshold of its monthly budget",
 	models.ClusterCreated:         "New cluster has been created",
 	models.ClusterDeleted:         "Cluster has been deleted",
 	models.ClusterUpdated:         "Cluster has been updated",
@@ -137,12 +154,19 @@ var supportedEvents = map[string]string{
 	models.ClusterAction:          "Cluster has triggered an action",
 	models.ClusterPodsRescheduled: "Pods has been deleted to reschedule",
 	models.ClusterKubernetesEvent: "Kubernetes event associated with cluster has been triggered",
+	models.ClusterPodDebugged:     "Ephemeral debug container has been injected into a pod of cluster",
+	models.ClusterFileDownloaded:  "File has been downloaded from a container of cluster",
+	models.ClusterFileUploaded:    "File has been uploaded to a container of cluster",
+	models.ClusterHibernated:      "Cluster has been hibernated",
+	models.ClusterWoken:           "Cluster has been woken from hibernation",
+	models.ClusterOverBudget:      "Cost of cluster has exceeded a threshold of its monthly budget",
 	models.MemberCreated:          "New member has been created",
 	models.MemberUpdated:          "Member has been updated",
 	models.MemberDeleted:          "Member has been deleted",
 	models.PipelinerunCreated:     "New pipelinerun has been created",
 	models.PipelinerunCancelled:   "Pipelinerun has been cancelled",
 	models.PipelinerunExecuted:    "Pipelinerun has been executed",
+	models.GroupOverBudget:        "Cost of group has exceeded a threshold of its monthly budget",
 }
 
 func (m *manager) ListSupportEvents() map[string]string {
@@ -111,3 +111,42 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(events))
 }
+
+func TestListEventsWithFilter(t *testing.T) {
+	createCtx()
+	events, err := m.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   10,
+			EventType:    eventmodels.ClusterCreated,
+		},
+	}, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   10,
+			EventType:    eventmodels.ClusterDeployed,
+		},
+	}, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceApplication,
+			ResourceID:   10,
+			EventType:    eventmodels.ApplicationCreated,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(events))
+
+	listed, err := m.ListEvents(ctx, &q.Query{Keywords: q.KeyWords{
+		common.StartID:           events[0].ID - 1,
+		common.ParamResourceType: common.ResourceCluster,
+		common.ParamResourceID:   uint(10),
+		common.EventType:         []string{eventmodels.ClusterDeployed, eventmodels.ApplicationCreated},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(listed))
+	assert.Equal(t, events[1].ID, listed[0].ID)
+
+	latestID, err := m.GetLatestEventID(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, events[2].ID, latestID)
+}
@@ -27,6 +27,7 @@ const (
 	ApplicationDeleted     string = "applications_deleted"
 	ApplicationUpdated     string = "applications_updated"
 	ApplicationTransfered  string = "applications_transferred"
+	ApplicationOverBudget  string = "applications_over_budget"
 	ClusterCreated         string = "clusters_created"
 	ClusterDeleted         string = "clusters_deleted"
 	ClusterBuildDeployed   string = "clusters_builddeployed"
@@ -38,12 +39,19 @@ const (
 	ClusterFreed           string = "clusters_freed"
 	ClusterKubernetesEvent string = "clusters_kubernetes_event"
 	ClusterAction                 = "clusters_action"
+	ClusterPodDebugged     string = "clusters_pod_debugged"
+	ClusterFileDownloaded  string = "clusters_file_downloaded"
+	ClusterFileUploaded    string = "clusters_file_uploaded"
+	ClusterHibernated      string = "clusters_hibernated"
+	ClusterWoken           string = "clusters_woken"
+	ClusterOverBudget      string = "clusters_over_budget"
 	MemberCreated          string = "members_created"
 	MemberUpdated          string = "members_updated"
 	MemberDeleted          string = "members_deleted"
 	PipelinerunCreated     string = "pipelineruns_created"
 	PipelinerunCancelled   string = "pipelineruns_cancelled"
 	PipelinerunExecuted    string = "pipelineruns_executed"
+	GroupOverBudget        string = "groups_over_budget"
 	// TODO: add group events
 )
 
@@ -17,8 +17,11 @@ package eventhandler
 import (
 	"context"
 	"runtime/debug"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	corecommon "github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
@@ -28,11 +31,14 @@ import (
 	"github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 )
 
 type Service interface {
 	RegisterEventHandler(name string, eh EventHandler) error
+	// SetConfig replaces the tunables, it takes effect in the next loop
+	SetConfig(config eventhandlerconfig.Config)
 	StopAndWait()
 	Start()
 }
@@ -43,7 +49,7 @@ type cursor struct {
 }
 
 type eventHandlerService struct {
-	config        eventhandlerconfig.Config
+	config        atomic.Value
 	ctx           context.Context
 	eventHandlers map[string]EventHandler
 	cursor        *cursor
@@ -55,8 +61,7 @@ type eventHandlerService struct {
 }
 
 func NewService(ctx context.Context, manager *managerparam.Manager, config eventhandlerconfig.Config) Service {
-	return &eventHandlerService{
-		config:        config,
+	e := &eventHandlerService{
 		ctx:           ctx,
 		eventHandlers: map[string]EventHandler{},
 		resume:        true,
@@ -64,6 +69,16 @@ func NewService(ctx context.Context, manager *managerparam.Manager, config event
 		eventMgr:   manager.EventMgr,
 		webhookMgr: manager.WebhookMgr,
 	}
+	e.SetConfig(config)
+	return e
+}
+
+func (e *eventHandlerService) SetConfig(config eventhandlerconfig.Config) {
+	e.config.Store(config)
+}
+
+func (e *eventHandlerService) getConfig() eventhandlerconfig.Config {
+	return e.config.Load().(eventhandlerconfig.Config)
 }
 
 // EventHandler processes new events by registered handlers
@@ -117,11 +132,21 @@ func (e *eventHandlerService) Start() {
 		}
 
 		// 2. process event
-		batchEventsCount := e.config.BatchEventsCount
-		cursorSaveInterval := time.NewTicker(time.Second * time.Duration(e.config.CursorSaveInterval))
-		idleWaitInterval := time.Second * time.Duration(e.config.IdleWaitInterval)
+		config := e.getConfig()
+		batchEventsCount := config.BatchEventsCount
+		cursorSaveInterval := time.NewTicker(time.Second * time.Duration(config.CursorSaveInterval))
+		idleWaitInterval := time.Second * time.Duration(config.IdleWaitInterval)
 	L:
 		for {
+			// apply config reloaded at runtime
+			if newConfig := e.getConfig(); newConfig != config {
+				if newConfig.CursorSaveInterval != config.CursorSaveInterval {
+					cursorSaveInterval.Reset(time.Second * time.Duration(newConfig.CursorSaveInterval))
+				}
+				config = newConfig
+				batchEventsCount = config.BatchEventsCount
+				idleWaitInterval = time.Second * time.Duration(config.IdleWaitInterval)
+			}
 			select {
 			case <-e.quit:
 				log.Infof(e.ctx, "save cursor(%d) and stop event handlers", e.cursor.Position)
@@ -172,7 +197,11 @@ func (e *eventHandlerService) Start() {
 					}
 				}
 				for name, eh := range e.eventHandlers {
-					if err := eh.Process(e.ctx, events, e.resume); err != nil {
+					ctx, span := trace.Start(e.ctx, "eventhandler: "+name,
+						attribute.Int("horizon.events", len(events)), attribute.Bool("horizon.resume", e.resume))
+					err := eh.Process(ctx, events, e.resume)
+					trace.End(span, err)
+					if err != nil {
 						log.Errorf(e.ctx, "Failed to process event by handler %s, error: %s",
 							name, err.Error())
 						continue
@@ -35,6 +35,7 @@ import (
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
 	"github.com/horizoncd/horizon/pkg/event/models"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -55,6 +56,7 @@ type MessageContent struct {
 	ID          uint                  `json:"id,omitempty"`
 	EventID     uint                  `json:"eventID,omitempty"`
 	WebhookID   uint                  `json:"webhookID,omitempty"`
+	Group       *GroupInfo            `json:"group,omitempty"`
 	Application *ApplicationInfo      `json:"application,omitempty"`
 	Cluster     *ClusterInfo          `json:"cluster,omitempty"`
 	Pipelinerun *PipelinerunInfo      `json:"pipelinerun,omitempty"`
@@ -69,6 +71,12 @@ type ResourceCommonInfo struct {
 	Name string `json:"name,omitempty"`
 }
 
+// GroupInfo contains basic info of group
+type GroupInfo struct {
+	ResourceCommonInfo
+	Path string `json:"path,omitempty"`
+}
+
 // ApplicationInfo contains basic info of application
 type ApplicationInfo struct {
 	ResourceCommonInfo
@@ -132,6 +140,7 @@ func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator
 type messageDependency struct {
 	webhook     *webhookmodels.Webhook
 	event       *models.Event
+	group       *groupmodels.Group
 	application *applicationmodels.Application
 	cluster     *clustermodels.Cluster
 	pipelinerun *prmodels.Pipelinerun
@@ -146,6 +155,20 @@ func (w *WebhookLogGenerator) listSystemResources() map[string][]uint {
 	}
 }
 
+// listAssociatedResourcesOfGroup get group by id and list itself and all the parent groups
+func (w *WebhookLogGenerator) listAssociatedResourcesOfGroup(ctx context.Context,
+	id uint) (*groupmodels.Group, map[string][]uint) {
+	resources := w.listSystemResources()
+	group, err := w.groupMgr.GetByID(ctx, id)
+	if err != nil {
+		log.Warningf(ctx, "group %d is not exist", id)
+		return nil, resources
+	}
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+	resources[common.ResourceGroup] = append(resources[common.ResourceGroup], groupIDs...)
+	return group, resources
+}
+
 // listAssociatedResourcesOfApp get application by id and list all the parent resources
 func (w *WebhookLogGenerator) listAssociatedResourcesOfApp(ctx context.Context,
 	id uint) (*applicationmodels.Application, map[string][]uint) {
@@ -241,6 +264,8 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 	)
 
 	switch e.ResourceType {
+	case common.ResourceGroup:
+		dep.group, resources = w.listAssociatedResourcesOfGroup(ctx, e.ResourceID)
 	case common.ResourceApplication:
 		application, resources = w.listAssociatedResourcesOfApp(ctx, e.ResourceID)
 		dep.application = application
@@ -293,6 +318,17 @@ func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageD
 		message.User = usermodels.ToUser(user)
 	}
 
+	if dep.event.ResourceType == common.ResourceGroup &&
+		dep.group != nil {
+		message.Group = &GroupInfo{
+			ResourceCommonInfo: ResourceCommonInfo{
+				ID:   dep.group.ID,
+				Name: dep.group.Name,
+			},
+			Path: dep.group.Path,
+		}
+	}
+
 	if dep.event.ResourceType == common.ResourceApplication &&
 		dep.application != nil {
 		message.Application = &ApplicationInfo{
@@ -34,6 +34,8 @@ type Helper interface {
 	GetHTTPLink(gitURL string) (string, error)
 	GetCommitHistoryLink(gitURL string, commit string) (string, error)
 	GetTagArchive(ctx context.Context, gitURL, tagName string) (*Tag, error)
+	// CreateMergeRequestComment comments on the merge request or pull request mrID of the repository
+	CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, comment string) error
 }
 
 type Constructor func(ctx context.Context, config *git.Repo) (Helper, error)
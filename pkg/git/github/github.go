@@ -211,3 +211,19 @@ func (h Helper) GetCommitHistoryLink(gitURL string, commit string) (string, erro
 
 	return fmt.Sprintf("%s/commits/%s", httpLink, commit), nil
 }
+
+func (h Helper) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, comment string) error {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return err
+	}
+	paths := strings.Split(pid, "/")
+	// comments of pull requests are created by the issues api
+	_, _, err = h.client.Issues.CreateComment(ctx, paths[0], paths[1], mrID, &github.IssueComment{
+		Body: &comment,
+	})
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to comment on pull request: err = %v", err)
+	}
+	return nil
+}
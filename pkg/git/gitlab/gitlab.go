@@ -189,3 +189,11 @@ func (h Helper) GetCommitHistoryLink(gitURL string, commit string) (string, erro
 
 	return fmt.Sprintf("%s/-/commits/%s", httpLink, commit), nil
 }
+
+func (h Helper) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, comment string) error {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return err
+	}
+	return h.client.CreateMRNote(ctx, pid, mrID, comment)
+}
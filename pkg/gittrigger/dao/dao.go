@@ -0,0 +1,115 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error)
+	Update(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error)
+	Get(ctx context.Context, id uint) (*models.GitTrigger, error)
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.GitTrigger, error)
+	// ListEnabledByGitURLs lists enabled triggers of clusters whose git url is one of gitURLs
+	ListEnabledByGitURLs(ctx context.Context, gitURLs []string) ([]*models.GitTriggerWithCluster, error)
+	Delete(ctx context.Context, id uint) error
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error) {
+	if err := d.db.WithContext(ctx).Create(trigger).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return trigger, nil
+}
+
+func (d *dao) Update(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error) {
+	where := d.db.WithContext(ctx).Model(trigger).Where("id = ?", trigger.ID)
+	if err := where.Select("event", "pattern", "enabled", "updated_by").
+		Updates(trigger).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	if err := where.First(trigger).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return trigger, nil
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.GitTrigger, error) {
+	var trigger models.GitTrigger
+	if err := d.db.WithContext(ctx).First(&trigger, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.GitTriggerInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return &trigger, nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.GitTrigger, error) {
+	var triggers []*models.GitTrigger
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Order("id").Find(&triggers).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return triggers, nil
+}
+
+func (d *dao) ListEnabledByGitURLs(ctx context.Context,
+	gitURLs []string) ([]*models.GitTriggerWithCluster, error) {
+	var triggers []*models.GitTriggerWithCluster
+	if len(gitURLs) == 0 {
+		return triggers, nil
+	}
+	if err := d.db.WithContext(ctx).Table("tb_git_trigger t").
+		Select("t.*, c.git_url").
+		Joins("join tb_cluster c on c.id = t.cluster_id").
+		Where("c.git_url in ? and c.deleted_ts = 0", gitURLs).
+		Where("t.enabled = ? and t.deleted_ts = 0", true).
+		Order("t.id").Scan(&triggers).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return triggers, nil
+}
+
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Delete(&models.GitTrigger{}, id).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Delete(&models.GitTrigger{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return nil
+}
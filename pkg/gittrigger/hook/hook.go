@@ -0,0 +1,339 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+)
+
+const (
+	ProviderGitlab = "gitlab"
+	ProviderGithub = "github"
+
+	_headerGitlabToken     = "X-Gitlab-Token"
+	_headerGitlabEvent     = "X-Gitlab-Event"
+	_headerGithubSignature = "X-Hub-Signature-256"
+	_headerGithubEvent     = "X-GitHub-Event"
+
+	_refHeadsPrefix = "refs/heads/"
+	_refTagsPrefix  = "refs/tags/"
+	// _zeroCommit is the commit of deleted branches and tags
+	_zeroCommit = "0000000000000000000000000000000000000000"
+)
+
+// Event is a git event parsed from the webhook of a git provider
+type Event struct {
+	// Type is one of models.EventPush, models.EventTag and models.EventMergeRequest
+	Type string
+	// RepoURLs are the urls of the repository, such as the http url and ssh url
+	RepoURLs []string
+	// Ref is the branch for push, the tag for tag and the source branch for merge_request
+	Ref string
+	// TargetBranch is the target branch of merge_request
+	TargetBranch string
+	Commit       string
+	Title        string
+	Operator     string
+	// MergeRequestID is the iid of the gitlab merge request or the number of the github pull request
+	MergeRequestID  int
+	MergeRequestURL string
+	// Closed is true if the merge_request is merged or closed
+	Closed bool
+}
+
+// Parse verifies the webhook request of the provider and parses the event in it,
+// nil is returned if the event is irrelevant, such as deleting a branch
+func Parse(provider, secret string, header http.Header, body []byte) (*Event, error) {
+	if secret == "" {
+		return nil, perror.Wrapf(herrors.ErrForbidden, "webhook of %s is not configured", provider)
+	}
+	switch provider {
+	case ProviderGitlab:
+		token := header.Get(_headerGitlabToken)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return nil, perror.Wrapf(herrors.ErrForbidden, "invalid %s", _headerGitlabToken)
+		}
+		return parseGitlab(header.Get(_headerGitlabEvent), body)
+	case ProviderGithub:
+		if !VerifyGithubSignature(secret, header.Get(_headerGithubSignature), body) {
+			return nil, perror.Wrapf(herrors.ErrForbidden, "invalid %s", _headerGithubSignature)
+		}
+		return parseGithub(header.Get(_headerGithubEvent), body)
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported git provider: %s", provider)
+	}
+}
+
+// VerifyGithubSignature checks the hmac sha256 signature of the payload
+func VerifyGithubSignature(secret, signature string, body []byte) bool {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+type gitlabProject struct {
+	GitHTTPURL string `json:"git_http_url"`
+	GitSSHURL  string `json:"git_ssh_url"`
+}
+
+type gitlabPushEvent struct {
+	Ref       string        `json:"ref"`
+	After     string        `json:"after"`
+	UserName  string        `json:"user_name"`
+	UserEmail string        `json:"user_email"`
+	Project   gitlabProject `json:"project"`
+	Commits   []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"commits"`
+}
+
+type gitlabMergeRequestEvent struct {
+	User struct {
+		Email string `json:"email"`
+	} `json:"user"`
+	Project          gitlabProject `json:"project"`
+	ObjectAttributes struct {
+		IID    int    `json:"iid"`
+		URL    string `json:"url"`
+		Title  string `json:"title"`
+		Action string `json:"action"`
+		// OldRev is set in update actions only if new commits are pushed
+		OldRev       string        `json:"oldrev"`
+		SourceBranch string        `json:"source_branch"`
+		TargetBranch string        `json:"target_branch"`
+		Source       gitlabProject `json:"source"`
+		LastCommit   struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+func parseGitlab(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "Push Hook", "Tag Push Hook":
+		var e gitlabPushEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid gitlab push event: %v", err)
+		}
+		if e.After == _zeroCommit {
+			return nil, nil
+		}
+		event := refEvent(e.Ref, e.After)
+		if event == nil {
+			return nil, nil
+		}
+		event.RepoURLs = []string{e.Project.GitHTTPURL, e.Project.GitSSHURL}
+		event.Operator = e.UserEmail
+		for _, commit := range e.Commits {
+			if commit.ID == e.After {
+				event.Title = commit.Title
+			}
+		}
+		return event, nil
+	case "Merge Request Hook":
+		var e gitlabMergeRequestEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid gitlab merge request event: %v", err)
+		}
+		attrs := e.ObjectAttributes
+		closed := false
+		switch attrs.Action {
+		case "open", "reopen":
+		case "update":
+			// updates of title, description, labels and so on are ignored
+			if attrs.OldRev == "" {
+				return nil, nil
+			}
+		case "close", "merge":
+			closed = true
+		default:
+			return nil, nil
+		}
+		// merge requests from forks are ignored, since the source branch is not in the repository
+		if attrs.Source.GitHTTPURL != e.Project.GitHTTPURL {
+			return nil, nil
+		}
+		return &Event{
+			Type:            models.EventMergeRequest,
+			RepoURLs:        []string{e.Project.GitHTTPURL, e.Project.GitSSHURL},
+			Ref:             attrs.SourceBranch,
+			TargetBranch:    attrs.TargetBranch,
+			Commit:          attrs.LastCommit.ID,
+			Title:           attrs.Title,
+			Operator:        e.User.Email,
+			MergeRequestID:  attrs.IID,
+			MergeRequestURL: attrs.URL,
+			Closed:          closed,
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+type githubRepository struct {
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+	HTMLURL  string `json:"html_url"`
+}
+
+type githubPushEvent struct {
+	Ref        string           `json:"ref"`
+	After      string           `json:"after"`
+	Deleted    bool             `json:"deleted"`
+	Repository githubRepository `json:"repository"`
+	Pusher     struct {
+		Email string `json:"email"`
+	} `json:"pusher"`
+	HeadCommit *struct {
+		Message string `json:"message"`
+	} `json:"head_commit"`
+}
+
+type githubPullRequestEvent struct {
+	Action      string           `json:"action"`
+	Number      int              `json:"number"`
+	Repository  githubRepository `json:"repository"`
+	PullRequest struct {
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Head    struct {
+			Ref  string           `json:"ref"`
+			SHA  string           `json:"sha"`
+			Repo githubRepository `json:"repo"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+}
+
+func parseGithub(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "push":
+		var e githubPushEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid github push event: %v", err)
+		}
+		if e.Deleted || e.After == _zeroCommit {
+			return nil, nil
+		}
+		event := refEvent(e.Ref, e.After)
+		if event == nil {
+			return nil, nil
+		}
+		event.RepoURLs = []string{e.Repository.CloneURL, e.Repository.SSHURL, e.Repository.HTMLURL}
+		event.Operator = e.Pusher.Email
+		if e.HeadCommit != nil {
+			event.Title = strings.SplitN(e.HeadCommit.Message, "\n", 2)[0]
+		}
+		return event, nil
+	case "pull_request":
+		var e githubPullRequestEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid github pull request event: %v", err)
+		}
+		closed := false
+		switch e.Action {
+		case "opened", "reopened", "synchronize":
+		case "closed":
+			closed = true
+		default:
+			return nil, nil
+		}
+		pr := e.PullRequest
+		// pull requests from forks are ignored, since the source branch is not in the repository
+		if pr.Head.Repo.CloneURL != e.Repository.CloneURL {
+			return nil, nil
+		}
+		return &Event{
+			Type:            models.EventMergeRequest,
+			RepoURLs:        []string{e.Repository.CloneURL, e.Repository.SSHURL, e.Repository.HTMLURL},
+			Ref:             pr.Head.Ref,
+			TargetBranch:    pr.Base.Ref,
+			Commit:          pr.Head.SHA,
+			Title:           pr.Title,
+			Operator:        pr.User.Login,
+			MergeRequestID:  e.Number,
+			MergeRequestURL: pr.HTMLURL,
+			Closed:          closed,
+		}, nil
+	default:
+		// including ping
+		return nil, nil
+	}
+}
+
+func refEvent(ref, commit string) *Event {
+	switch {
+	case strings.HasPrefix(ref, _refHeadsPrefix):
+		return &Event{
+			Type:   models.EventPush,
+			Ref:    strings.TrimPrefix(ref, _refHeadsPrefix),
+			Commit: commit,
+		}
+	case strings.HasPrefix(ref, _refTagsPrefix):
+		return &Event{
+			Type:   models.EventTag,
+			Ref:    strings.TrimPrefix(ref, _refTagsPrefix),
+			Commit: commit,
+		}
+	default:
+		return nil
+	}
+}
+
+// CandidateURLs returns the possible forms of the repository urls that clusters may use,
+// such as with or without the .git suffix
+func CandidateURLs(repoURLs []string) []string {
+	seen := make(map[string]bool)
+	candidates := make([]string, 0, 2*len(repoURLs))
+	add := func(u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			candidates = append(candidates, u)
+		}
+	}
+	for _, u := range repoURLs {
+		u = strings.TrimSuffix(strings.TrimSpace(u), "/")
+		if u == "" {
+			continue
+		}
+		trimmed := strings.TrimSuffix(u, ".git")
+		add(trimmed)
+		add(trimmed + ".git")
+	}
+	return candidates
+}
@@ -0,0 +1,156 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+)
+
+func TestParseGitlab(t *testing.T) {
+	body := []byte(`{
+		"ref": "refs/heads/main",
+		"after": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		"user_email": "jerry@example.com",
+		"project": {
+			"git_http_url": "https://gitlab.com/horizoncd/horizon.git",
+			"git_ssh_url": "git@gitlab.com:horizoncd/horizon.git"
+		},
+		"commits": [{"id": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", "title": "fix bug"}]
+	}`)
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Push Hook")
+
+	_, err := Parse(ProviderGitlab, "secret", header, body)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	header.Set("X-Gitlab-Token", "secret")
+	event, err := Parse(ProviderGitlab, "secret", header, body)
+	assert.Nil(t, err)
+	assert.Equal(t, models.EventPush, event.Type)
+	assert.Equal(t, "main", event.Ref)
+	assert.Equal(t, "fix bug", event.Title)
+	assert.Equal(t, "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", event.Commit)
+
+	// not configured
+	_, err = Parse(ProviderGitlab, "", header, body)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// deleting branch
+	event, err = Parse(ProviderGitlab, "secret", header,
+		[]byte(`{"ref": "refs/heads/main", "after": "0000000000000000000000000000000000000000"}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+}
+
+func TestParseGitlabMergeRequest(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Merge Request Hook")
+	header.Set("X-Gitlab-Token", "secret")
+	body := []byte(`{
+		"user": {"email": "jerry@example.com"},
+		"project": {"git_http_url": "https://gitlab.com/horizoncd/horizon.git"},
+		"object_attributes": {
+			"iid": 12,
+			"url": "https://gitlab.com/horizoncd/horizon/-/merge_requests/12",
+			"action": "merge",
+			"source_branch": "feature",
+			"target_branch": "main",
+			"source": {"git_http_url": "https://gitlab.com/horizoncd/horizon.git"}
+		}
+	}`)
+	event, err := Parse(ProviderGitlab, "secret", header, body)
+	assert.Nil(t, err)
+	assert.Equal(t, models.EventMergeRequest, event.Type)
+	assert.Equal(t, "feature", event.Ref)
+	assert.Equal(t, 12, event.MergeRequestID)
+	assert.Equal(t, "https://gitlab.com/horizoncd/horizon/-/merge_requests/12", event.MergeRequestURL)
+	assert.True(t, event.Closed)
+
+	// updates without new commits are ignored
+	update := []byte(`{
+		"project": {"git_http_url": "https://gitlab.com/horizoncd/horizon.git"},
+		"object_attributes": {
+			"iid": 12,
+			"action": "update",
+			"source_branch": "feature",
+			"source": {"git_http_url": "https://gitlab.com/horizoncd/horizon.git"}
+		}
+	}`)
+	event, err = Parse(ProviderGitlab, "secret", header, update)
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+
+	update = []byte(`{
+		"project": {"git_http_url": "https://gitlab.com/horizoncd/horizon.git"},
+		"object_attributes": {
+			"iid": 12,
+			"action": "update",
+			"oldrev": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+			"source_branch": "feature",
+			"source": {"git_http_url": "https://gitlab.com/horizoncd/horizon.git"}
+		}
+	}`)
+	event, err = Parse(ProviderGitlab, "secret", header, update)
+	assert.Nil(t, err)
+	assert.False(t, event.Closed)
+}
+
+func TestParseGithub(t *testing.T) {
+	body := []byte(`{
+		"ref": "refs/tags/v1.0.0",
+		"after": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		"repository": {"clone_url": "https://github.com/horizoncd/horizon.git"},
+		"head_commit": {"message": "release v1.0.0\n\ndetails"}
+	}`)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	header.Set("X-Hub-Signature-256", "sha256=invalid")
+
+	_, err := Parse(ProviderGithub, "secret", header, body)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	event, err := Parse(ProviderGithub, "secret", header, body)
+	assert.Nil(t, err)
+	assert.Equal(t, models.EventTag, event.Type)
+	assert.Equal(t, "v1.0.0", event.Ref)
+	assert.Equal(t, "release v1.0.0", event.Title)
+}
+
+func TestCandidateURLs(t *testing.T) {
+	assert.Equal(t, []string{
+		"https://github.com/horizoncd/horizon",
+		"https://github.com/horizoncd/horizon.git",
+		"git@github.com:horizoncd/horizon",
+		"git@github.com:horizoncd/horizon.git",
+	}, CandidateURLs([]string{
+		"https://github.com/horizoncd/horizon.git",
+		"git@github.com:horizoncd/horizon.git",
+		"https://github.com/horizoncd/horizon",
+		"",
+	}))
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+)
+
+func TestListEnabledByGitURLs(t *testing.T) {
+	db, _ := orm.NewSqliteDB("file::memory:?cache=shared")
+	if err := db.AutoMigrate(&models.GitTrigger{}, &clustermodels.Cluster{}); err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+	m := New(db)
+
+	clusters := []*clustermodels.Cluster{
+		{Name: "cluster1", GitURL: "https://github.com/horizoncd/horizon.git"},
+		{Name: "cluster2", GitURL: "git@github.com:horizoncd/horizon.git"},
+		{Name: "cluster3", GitURL: "https://github.com/horizoncd/other.git"},
+	}
+	for _, cluster := range clusters {
+		assert.Nil(t, db.Create(cluster).Error)
+	}
+	for _, cluster := range clusters {
+		_, err := m.Create(ctx, &models.GitTrigger{
+			ClusterID: cluster.ID,
+			Event:     models.EventPush,
+			Pattern:   "main",
+			Enabled:   true,
+		})
+		assert.Nil(t, err)
+	}
+	disabled, err := m.Create(ctx, &models.GitTrigger{
+		ClusterID: clusters[0].ID,
+		Event:     models.EventTag,
+		Pattern:   "v*",
+	})
+	assert.Nil(t, err)
+	disabled.Enabled = false
+	_, err = m.Update(ctx, disabled)
+	assert.Nil(t, err)
+
+	triggers, err := m.ListEnabledByGitURLs(ctx, []string{
+		"https://github.com/horizoncd/horizon.git",
+		"git@github.com:horizoncd/horizon.git",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(triggers))
+	assert.Equal(t, clusters[0].ID, triggers[0].ClusterID)
+	assert.Equal(t, clusters[0].GitURL, triggers[0].GitURL)
+	assert.Equal(t, clusters[1].ID, triggers[1].ClusterID)
+
+	assert.Nil(t, m.DeleteByClusterID(ctx, clusters[0].ID))
+	clusterTriggers, err := m.ListByClusterID(ctx, clusters[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(clusterTriggers))
+}
@@ -0,0 +1,50 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	// EventPush triggers on pushes to branches
+	EventPush = "push"
+	// EventTag triggers on pushes of tags
+	EventTag = "tag"
+	// EventMergeRequest triggers on merge requests opened or updated
+	EventMergeRequest = "merge_request"
+)
+
+// GitTrigger subscribes a cluster to events of its git repository,
+// a builddeploy is created when a matched event is received
+type GitTrigger struct {
+	global.Model
+
+	ClusterID uint
+	Event     string
+	// Pattern is a glob pattern of the branch for push,
+	// the tag for tag, and the target branch for merge_request
+	Pattern   string
+	Enabled   bool
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+// GitTriggerWithCluster is a trigger with the git url of its cluster
+type GitTriggerWithCluster struct {
+	GitTrigger
+
+	GitURL string
+}
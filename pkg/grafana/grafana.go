@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -47,9 +48,14 @@ const (
 type Service interface {
 	SyncDatasource(ctx context.Context)
 	ListDashboards(ctx context.Context) ([]*Dashboard, error)
+	// Config returns the effective grafana config
+	Config() grafana.Config
+	// SetConfig replaces the grafana config, the sync period takes effect after the next sync
+	SetConfig(config grafana.Config)
 }
 
 type service struct {
+	sync.RWMutex
 	config     grafana.Config
 	kubeClient kubernetes.Interface
 	regionMgr  regionmanager.Manager
@@ -81,11 +87,24 @@ type Dashboard struct {
 	Tags  []string `json:"tags"`
 }
 
+func (s *service) Config() grafana.Config {
+	s.RLock()
+	defer s.RUnlock()
+	return s.config
+}
+
+func (s *service) SetConfig(config grafana.Config) {
+	s.Lock()
+	defer s.Unlock()
+	s.config = config
+}
+
 func (s *service) SyncDatasource(ctx context.Context) {
-	log.Infof(ctx, "Starting syncing grafana datasource every %v", s.config.SyncDatasourceConfig.Period)
+	period := s.Config().SyncDatasourceConfig.Period
+	log.Infof(ctx, "Starting syncing grafana datasource every %v", period)
 	defer log.Infof(ctx, "Stopping syncing grafana datasource")
 
-	ticker := time.NewTicker(s.config.SyncDatasourceConfig.Period)
+	ticker := time.NewTicker(period)
 	defer ticker.Stop()
 
 	for {
@@ -95,11 +114,17 @@ func (s *service) SyncDatasource(ctx context.Context) {
 			return
 		case <-ticker.C:
 			s.sync(ctx)
+			if newPeriod := s.Config().SyncDatasourceConfig.Period; newPeriod != period && newPeriod > 0 {
+				log.Infof(ctx, "Syncing grafana datasource every %v", newPeriod)
+				period = newPeriod
+				ticker.Reset(period)
+			}
 		}
 	}
 }
 
 func (s *service) sync(ctx context.Context) {
+	config := s.Config()
 	log.Info(ctx, "Start to sync grafana datasource")
 
 	logErr := func(err error) {
@@ -112,7 +137,7 @@ func (s *service) sync(ctx context.Context) {
 		return
 	}
 
-	configMapOps := s.kubeClient.CoreV1().ConfigMaps(s.config.Namespace)
+	configMapOps := s.kubeClient.CoreV1().ConfigMaps(config.Namespace)
 	datasourceConfigMap, err := configMapOps.Get(ctx, _datasourceConfigMapName, metav1.GetOptions{})
 	if err != nil {
 		if statusError, ok := err.(*k8serrors.StatusError); !ok || statusError.ErrStatus.Code != http.StatusNotFound {
@@ -147,7 +172,7 @@ func (s *service) sync(ctx context.Context) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: _datasourceConfigMapName,
 			Labels: map[string]string{
-				s.config.SyncDatasourceConfig.LabelKey: s.config.SyncDatasourceConfig.LabelValue,
+				config.SyncDatasourceConfig.LabelKey: config.SyncDatasourceConfig.LabelValue,
 			},
 			Annotations: map[string]string{
 				_contentMD5AnnotationKey: curMD5Val,
@@ -186,12 +211,13 @@ func (s *service) sync(ctx context.Context) {
 }
 
 func (s *service) ListDashboards(ctx context.Context) ([]*Dashboard, error) {
-	configMapOps := s.kubeClient.CoreV1().ConfigMaps(s.config.Namespace)
+	config := s.Config()
+	configMapOps := s.kubeClient.CoreV1().ConfigMaps(config.Namespace)
 
 	dashboardConfigMapList, err := configMapOps.List(ctx,
 		metav1.ListOptions{
-			LabelSelector: fmt.Sprintf("%v=%v", s.config.Dashboards.LabelKey,
-				s.config.Dashboards.LabelValue),
+			LabelSelector: fmt.Sprintf("%v=%v", config.Dashboards.LabelKey,
+				config.Dashboards.LabelValue),
 		})
 	if err != nil {
 		if statusError, ok := err.(*k8serrors.StatusError); !ok || statusError.ErrStatus.Code != http.StatusNotFound {
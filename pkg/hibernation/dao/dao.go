@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/hibernation/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, hibernation *models.Hibernation) (*models.Hibernation, error)
+	Update(ctx context.Context, hibernation *models.Hibernation) (*models.Hibernation, error)
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.Hibernation, error)
+	// ListWithCluster lists hibernations of clusters which are not deleted
+	ListWithCluster(ctx context.Context) ([]*models.HibernationWithCluster, error)
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, hibernation *models.Hibernation) (*models.Hibernation, error) {
+	if err := d.db.WithContext(ctx).Create(hibernation).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.HibernationInDB, err.Error())
+	}
+	return hibernation, nil
+}
+
+func (d *dao) Update(ctx context.Context, hibernation *models.Hibernation) (*models.Hibernation, error) {
+	where := d.db.WithContext(ctx).Model(hibernation).Where("id = ?", hibernation.ID)
+	if err := where.Select("hibernate_schedule", "wake_schedule", "idle_seconds", "updated_by").
+		Updates(hibernation).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.HibernationInDB, err.Error())
+	}
+	if err := where.First(hibernation).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.HibernationInDB, err.Error())
+	}
+	return hibernation, nil
+}
+
+func (d *dao) GetByClusterID(ctx context.Context, clusterID uint) (*models.Hibernation, error) {
+	var hibernation models.Hibernation
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		First(&hibernation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.HibernationInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.HibernationInDB, err.Error())
+	}
+	return &hibernation, nil
+}
+
+func (d *dao) ListWithCluster(ctx context.Context) ([]*models.HibernationWithCluster, error) {
+	var hibernations []*models.HibernationWithCluster
+	if err := d.db.WithContext(ctx).Table("tb_hibernation h").
+		Select("h.*, c.name as cluster_name, c.environment_name, c.status, " +
+			"c.updated_at as cluster_updated_at").
+		Joins("join tb_cluster c on c.id = h.cluster_id").
+		Where("c.deleted_ts = 0 and h.deleted_ts = 0").
+		Order("h.id").Scan(&hibernations).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.HibernationInDB, err.Error())
+	}
+	return hibernations, nil
+}
+
+func (d *dao) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Delete(&models.Hibernation{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.HibernationInDB, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/hibernation/dao"
+	"github.com/horizoncd/horizon/pkg/hibernation/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, hibernation *models.Hibernation) (*models.Hibernation, error)
+	Update(ctx context.Context, hibernation *models.Hibernation) (*models.Hibernation, error)
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.Hibernation, error)
+	// ListWithCluster lists hibernations of clusters which are not deleted
+	ListWithCluster(ctx context.Context) ([]*models.HibernationWithCluster, error)
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, hibernation *models.Hibernation) (*models.Hibernation, error) {
+	return m.dao.Create(ctx, hibernation)
+}
+
+func (m *manager) Update(ctx context.Context, hibernation *models.Hibernation) (*models.Hibernation, error) {
+	return m.dao.Update(ctx, hibernation)
+}
+
+func (m *manager) GetByClusterID(ctx context.Context, clusterID uint) (*models.Hibernation, error) {
+	return m.dao.GetByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListWithCluster(ctx context.Context) ([]*models.HibernationWithCluster, error) {
+	return m.dao.ListWithCluster(ctx)
+}
+
+func (m *manager) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	return m.dao.DeleteByClusterID(ctx, clusterID)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// Hibernation configures when a cluster is hibernated and woken,
+// a hibernated cluster keeps its resources but is scaled to zero replicas
+type Hibernation struct {
+	global.Model
+
+	ClusterID uint
+	// HibernateSchedule is a standard cron expression to hibernate the cluster
+	HibernateSchedule string
+	// WakeSchedule is a standard cron expression to wake the cluster
+	WakeSchedule string
+	// IdleSeconds hibernates the cluster after it is not deployed or updated for a while,
+	// 0 means never
+	IdleSeconds uint
+	CreatedBy   uint
+	UpdatedBy   uint
+}
+
+// HibernationWithCluster is a hibernation with the fields of its cluster
+type HibernationWithCluster struct {
+	Hibernation
+
+	ClusterName      string
+	EnvironmentName  string
+	Status           string
+	ClusterUpdatedAt time.Time
+}
@@ -31,8 +31,11 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
-func Run(ctx context.Context, jobConfig *autofree.Config, userMgr usermanager.Manager,
+// Run frees expired clusters periodically, configGetter returns the latest config
+// so that the job settings can be reloaded at runtime
+func Run(ctx context.Context, configGetter func() *autofree.Config, userMgr usermanager.Manager,
 	clusterCtr clusterctl.Controller, prCtr prctl.Controller) {
+	jobConfig := configGetter()
 	// verify account
 	user, err := userMgr.GetUserByID(ctx, jobConfig.AccountID)
 	if err != nil {
@@ -50,11 +53,17 @@ func Run(ctx context.Context, jobConfig *autofree.Config, userMgr usermanager.Ma
 	// start job
 	log.Infof(ctx, "Starting releasing expired cluster automatically every %v", jobConfig.JobInterval)
 	defer log.Infof(ctx, "Stopping releasing expired cluster automatically")
-	ticker := time.NewTicker(jobConfig.JobInterval)
+	jobInterval := jobConfig.JobInterval
+	ticker := time.NewTicker(jobInterval)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
+			jobConfig = configGetter()
+			if jobConfig.JobInterval != jobInterval {
+				jobInterval = jobConfig.JobInterval
+				ticker.Reset(jobInterval)
+			}
 			rid := uuid.NewV4().String()
 			// nolint
 			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
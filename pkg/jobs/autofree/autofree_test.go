@@ -207,11 +207,13 @@ func TestAutoFreeExpiredCluster(t *testing.T) {
 		<-timer.C
 		cancelFunc()
 	}()
-	Run(ctx, &autofree.Config{
-		AccountID:     1,
-		JobInterval:   1 * time.Second,
-		BatchInterval: 0 * time.Second,
-		BatchSize:     20,
-		SupportedEnvs: []string{"dev"},
+	Run(ctx, func() *autofree.Config {
+		return &autofree.Config{
+			AccountID:     1,
+			JobInterval:   1 * time.Second,
+			BatchInterval: 0 * time.Second,
+			BatchSize:     20,
+			SupportedEnvs: []string{"dev"},
+		}
 	}, manager.UserMgr, clrCtl, prCtl)
 }
@@ -17,6 +17,7 @@ package clean
 import (
 	"context"
 	"encoding/json"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -39,9 +40,25 @@ type Cleaner struct {
 	mgr              *managerparam.Manager
 	eventCursor      uint
 	webhookLogCursor uint
+
+	// mu guards the fields below, which are used to reload config at runtime
+	mu        sync.Mutex
+	ctx       context.Context
+	pending   *clean.Config
+	crontab   *cron.Cron
+	entryID   cron.EntryID
+	scheduled string
 }
 
 func New(config clean.Config, mgr *managerparam.Manager) *Cleaner {
+	c := &Cleaner{
+		mgr: mgr,
+	}
+	c.apply(config)
+	return c
+}
+
+func (c *Cleaner) apply(config clean.Config) {
 	if config.Batch == 0 {
 		config.Batch = 160
 	}
@@ -53,12 +70,30 @@ func New(config clean.Config, mgr *managerparam.Manager) *Cleaner {
 	for _, rule := range config.WebhookLogCleanRules {
 		webhookCleanRules[rule.RelatedEventType] = append(webhookCleanRules[rule.RelatedEventType], rule)
 	}
-	return &Cleaner{
-		Config:       config,
-		eventRules:   eventCleanRules,
-		webhookRules: webhookCleanRules,
-		mgr:          mgr,
+	c.Config = config
+	c.eventRules = eventCleanRules
+	c.webhookRules = webhookCleanRules
+}
+
+// SetConfig replaces the config, the rules take effect in the next run,
+// and the job is rescheduled if TimeToRun changes
+func (c *Cleaner) SetConfig(config clean.Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.crontab != nil && config.TimeToRun != c.scheduled {
+		ctx := c.ctx
+		entryID, err := c.crontab.AddFunc(config.TimeToRun, func() {
+			c.run(ctx)
+		})
+		if err != nil {
+			return err
+		}
+		c.crontab.Remove(c.entryID)
+		c.entryID = entryID
+		c.scheduled = config.TimeToRun
 	}
+	c.pending = &config
+	return nil
 }
 
 func (c *Cleaner) Run(ctx context.Context) {
@@ -66,17 +101,32 @@ func (c *Cleaner) Run(ctx context.Context) {
 	if err != nil {
 		panic(err)
 	}
-	crontab := cron.New(cron.WithSeconds(), cron.WithLocation(loc))
-	_, err = crontab.AddFunc(c.TimeToRun, func() {
-		log.Info(ctx, "start to clean")
-		current := time.Now()
-		c.webhookLogClean(ctx, current)
-		c.eventClean(ctx, current)
+	c.mu.Lock()
+	c.ctx = ctx
+	c.crontab = cron.New(cron.WithSeconds(), cron.WithLocation(loc))
+	c.scheduled = c.TimeToRun
+	c.entryID, err = c.crontab.AddFunc(c.scheduled, func() {
+		c.run(ctx)
 	})
+	c.mu.Unlock()
 	if err != nil {
 		panic(err)
 	}
-	crontab.Run()
+	c.crontab.Run()
+}
+
+func (c *Cleaner) run(ctx context.Context) {
+	c.mu.Lock()
+	if c.pending != nil {
+		c.apply(*c.pending)
+		c.pending = nil
+	}
+	c.mu.Unlock()
+
+	log.Info(ctx, "start to clean")
+	current := time.Now()
+	c.webhookLogClean(ctx, current)
+	c.eventClean(ctx, current)
 }
 
 func (c *Cleaner) webhookLogClean(ctx context.Context, current time.Time) {
@@ -0,0 +1,341 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/informers"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	costconfig "github.com/horizoncd/horizon/pkg/config/cost"
+	"github.com/horizoncd/horizon/pkg/cost/manager"
+	"github.com/horizoncd/horizon/pkg/cost/models"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/generic"
+)
+
+const (
+	dateFormat  = "2006-01-02"
+	monthFormat = "2006-01"
+
+	resourceGPU corev1.ResourceName = "nvidia.com/gpu"
+	gib                             = 1024 * 1024 * 1024
+
+	// maxElapsed is the longest time accounted in a run, the requested resources
+	// are unknown while the job stops, such as no instance is running
+	maxElapsed = time.Hour
+)
+
+// podLister lists the pods of clusters in the region
+type podLister func(region *regionmodels.Region) ([]corev1.Pod, error)
+
+// Requests are the resources requested by the running pods of a cluster,
+// cpu in cores, memory in GiB and gpu in cards
+type Requests struct {
+	CPU    float64
+	Memory float64
+	GPU    float64
+}
+
+// BudgetExceeded is the extra of the event emitted when the cost exceeds a threshold of the budget
+type BudgetExceeded struct {
+	Month     string  `json:"month"`
+	Amount    float64 `json:"amount"`
+	Cost      float64 `json:"cost"`
+	Threshold uint    `json:"threshold"`
+	Currency  string  `json:"currency"`
+}
+
+// Run accumulates the cost of clusters by their requested resources every interval and notifies
+// the budgets exceeded, the getter returns the latest config so that it can be reloaded at runtime
+func Run(ctx context.Context, configGetter func() *costconfig.Config, manager *managerparam.Manager,
+	informerFactories *regioninformers.RegionInformers) {
+	// clusters are listed by a dummy user
+	// nolint
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{ID: 0})
+
+	selector := labels.NewSelector()
+	requirement, _ := labels.NewRequirement(common.ClusterClusterLabelKey, selection.Exists, nil)
+	selector = selector.Add(*requirement)
+	listPods := func(region *regionmodels.Region) ([]corev1.Pod, error) {
+		var pods []corev1.Pod
+		err := informerFactories.GetDynamicInformer(region.ID, generic.GVRPod,
+			func(informer informers.GenericInformer) error {
+				objs, err := informer.Lister().List(selector)
+				if err != nil {
+					return err
+				}
+				pods = workload.ObjIntoPod(objs...)
+				return nil
+			})
+		return pods, err
+	}
+
+	jobConfig := configGetter()
+	log.Infof(ctx, "Starting accumulating cost of clusters every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping accumulating cost of clusters")
+	jobInterval := jobConfig.JobInterval
+	ticker := time.NewTicker(jobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			jobConfig = configGetter()
+			if jobConfig.JobInterval != jobInterval {
+				jobInterval = jobConfig.JobInterval
+				ticker.Reset(jobInterval)
+			}
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "cost job starts to execute, rid: %v", rid)
+			process(ctx, jobConfig, manager, listPods, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func process(ctx context.Context, jobConfig *costconfig.Config, manager *managerparam.Manager,
+	listPods podLister, now time.Time) {
+	op := "job: cost"
+	if err := accumulate(ctx, jobConfig, manager, listPods, now); err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to accumulate cost of clusters, err: %+v", err)
+	}
+	if err := checkBudgets(ctx, jobConfig, manager, now); err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to check budgets, err: %+v", err)
+	}
+}
+
+// accumulate adds the cost of the requested resources in the time elapsed since each region
+// is accounted to the date of now, so that no time is lost when the job restarts on another instance
+func accumulate(ctx context.Context, jobConfig *costconfig.Config, manager *managerparam.Manager,
+	listPods podLister, now time.Time) error {
+	regions, err := manager.RegionMgr.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	accountedAt, err := manager.CostMgr.ListAccountedAt(ctx)
+	if err != nil {
+		return err
+	}
+	_, clusters, err := manager.ClusterMgr.List(ctx, &q.Query{WithoutPagination: true})
+	if err != nil {
+		return err
+	}
+	clusterMap := make(map[string]*clustermodels.Cluster, len(clusters))
+	for _, cluster := range clusters {
+		clusterMap[cluster.Name] = cluster.Cluster
+	}
+
+	for _, region := range regions {
+		if region.Disabled {
+			continue
+		}
+		elapsed, ok := elapsedSince(accountedAt, region.Name, now, jobConfig.JobInterval)
+		if !ok {
+			continue
+		}
+		pods, err := listPods(region)
+		if err != nil {
+			log.Warningf(ctx, "failed to list pods of region %v, err: %v", region.Name, err)
+			continue
+		}
+		// the resources are considered requested during the whole elapsed time
+		hours := elapsed.Hours()
+		price := jobConfig.Price(region.Name)
+		costs := make([]*models.ClusterCost, 0)
+		for name, requests := range sumRequests(pods) {
+			cluster, ok := clusterMap[name]
+			if !ok || cluster.RegionName != region.Name {
+				continue
+			}
+			costs = append(costs, &models.ClusterCost{
+				ClusterID:       cluster.ID,
+				ApplicationID:   cluster.ApplicationID,
+				RegionName:      cluster.RegionName,
+				EnvironmentName: cluster.EnvironmentName,
+				Date:            now.Format(dateFormat),
+				CPUHours:        requests.CPU * hours,
+				MemoryHours:     requests.Memory * hours,
+				GPUHours:        requests.GPU * hours,
+				Cost: (requests.CPU*price.CPU + requests.Memory*price.Memory +
+					requests.GPU*price.GPU) * hours,
+			})
+		}
+		sort.Slice(costs, func(i, j int) bool { return costs[i].ClusterID < costs[j].ClusterID })
+		if err := manager.CostMgr.Accumulate(ctx, region.Name, now, costs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// elapsedSince returns the time elapsed since the region is accounted, which is the job interval
+// for a region never accounted, and is capped by maxElapsed in case the job stops for long,
+// it returns false if the region has been accounted to a later time by another instance
+func elapsedSince(accountedAt map[string]time.Time, regionName string, now time.Time,
+	jobInterval time.Duration) (time.Duration, bool) {
+	last, ok := accountedAt[regionName]
+	if !ok {
+		return jobInterval, true
+	}
+	if !now.After(last) {
+		return 0, false
+	}
+	elapsed := now.Sub(last)
+	limit := maxElapsed
+	if jobInterval > limit {
+		limit = jobInterval
+	}
+	if elapsed > limit {
+		elapsed = limit
+	}
+	return elapsed, true
+}
+
+// sumRequests sums the requests of the containers of the running pods by the cluster name
+func sumRequests(pods []corev1.Pod) map[string]*Requests {
+	result := make(map[string]*Requests)
+	for _, pod := range pods {
+		name := pod.Labels[common.ClusterClusterLabelKey]
+		if name == "" || pod.Spec.NodeName == "" ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		requests, ok := result[name]
+		if !ok {
+			requests = &Requests{}
+			result[name] = requests
+		}
+		for _, container := range pod.Spec.Containers {
+			requests.CPU += float64(container.Resources.Requests.Cpu().MilliValue()) / 1000
+			requests.Memory += float64(container.Resources.Requests.Memory().Value()) / gib
+			if gpu, ok := container.Resources.Requests[resourceGPU]; ok {
+				requests.GPU += float64(gpu.Value())
+			}
+		}
+	}
+	return result
+}
+
+// checkBudgets emits an event for each budget whose cost of the month exceeds a new threshold
+func checkBudgets(ctx context.Context, jobConfig *costconfig.Config,
+	mgr *managerparam.Manager, now time.Time) error {
+	budgets, err := mgr.CostMgr.ListBudgets(ctx)
+	if err != nil {
+		return err
+	}
+	month := now.Format(monthFormat)
+	for _, budget := range budgets {
+		query := &models.CostQuery{From: month + "-01", To: now.Format(dateFormat)}
+		eventType := eventmodels.ApplicationOverBudget
+		switch budget.ResourceType {
+		case common.ResourceGroup:
+			group, err := mgr.GroupMgr.GetByID(ctx, budget.ResourceID)
+			if err != nil {
+				log.Warningf(ctx, "failed to get group %v of budget, err: %v", budget.ResourceID, err)
+				continue
+			}
+			query.GroupTraversalIDs = group.TraversalIDs
+			eventType = eventmodels.GroupOverBudget
+		case common.ResourceApplication:
+			query.ApplicationID = budget.ResourceID
+		case common.ResourceCluster:
+			query.ClusterID = budget.ResourceID
+			eventType = eventmodels.ClusterOverBudget
+		default:
+			continue
+		}
+		costs, err := mgr.CostMgr.Sum(ctx, query, manager.SumByCluster)
+		if err != nil {
+			return err
+		}
+		total := 0.0
+		for _, cost := range costs {
+			total += cost.Cost
+		}
+		threshold, ok := exceededThreshold(budget, month, total)
+		if !ok {
+			continue
+		}
+
+		extra, err := json.Marshal(&BudgetExceeded{
+			Month:     month,
+			Amount:    budget.Amount,
+			Cost:      total,
+			Threshold: threshold,
+			Currency:  jobConfig.Currency,
+		})
+		if err != nil {
+			return err
+		}
+		extraStr := string(extra)
+		if _, err := mgr.EventMgr.CreateEvent(ctx, &eventmodels.Event{
+			EventSummary: eventmodels.EventSummary{
+				ResourceType: budget.ResourceType,
+				ResourceID:   budget.ResourceID,
+				EventType:    eventType,
+				Extra:        &extraStr,
+			},
+		}); err != nil {
+			return err
+		}
+		if err := mgr.CostMgr.UpdateBudgetNotified(ctx, budget.ID, month, threshold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exceededThreshold returns the highest threshold exceeded by the cost which is not notified in the month
+func exceededThreshold(budget *models.Budget, month string, cost float64) (uint, bool) {
+	if budget.Amount <= 0 {
+		return 0, false
+	}
+	thresholds, err := models.ParseThresholds(budget.Thresholds)
+	if err != nil {
+		return 0, false
+	}
+	var notified uint
+	if budget.NotifiedMonth == month {
+		notified = budget.NotifiedThreshold
+	}
+	percentage := cost / budget.Amount * 100
+	var exceeded uint
+	for _, threshold := range thresholds {
+		if percentage >= float64(threshold) && threshold > notified {
+			exceeded = threshold
+		}
+	}
+	return exceeded, exceeded > 0
+}
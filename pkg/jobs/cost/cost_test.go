@@ -0,0 +1,127 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/cost/models"
+)
+
+func pod(cluster, node string, phase corev1.PodPhase, requests ...corev1.ResourceList) corev1.Pod {
+	p := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{common.ClusterClusterLabelKey: cluster}},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+	for _, r := range requests {
+		p.Spec.Containers = append(p.Spec.Containers,
+			corev1.Container{Resources: corev1.ResourceRequirements{Requests: r}})
+	}
+	return p
+}
+
+func TestSumRequests(t *testing.T) {
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("500m"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	gpuRequests := corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("2"),
+		resourceGPU:        resource.MustParse("1"),
+	}
+	result := sumRequests([]corev1.Pod{
+		pod("a", "node1", corev1.PodRunning, requests, requests),
+		pod("a", "node2", corev1.PodRunning, requests),
+		// unscheduled and completed pods request nothing
+		pod("a", "", corev1.PodPending, requests),
+		pod("a", "node1", corev1.PodSucceeded, requests),
+		pod("b", "node1", corev1.PodRunning, gpuRequests),
+		pod("", "node1", corev1.PodRunning, requests),
+	})
+	assert.Equal(t, map[string]*Requests{
+		"a": {CPU: 1.5, Memory: 3},
+		"b": {CPU: 2, GPU: 1},
+	}, result)
+}
+
+func TestExceededThreshold(t *testing.T) {
+	budget := &models.Budget{Amount: 100, Thresholds: "100,80"}
+
+	_, ok := exceededThreshold(budget, "2026-10", 79)
+	assert.False(t, ok)
+
+	threshold, ok := exceededThreshold(budget, "2026-10", 85)
+	assert.True(t, ok)
+	assert.Equal(t, uint(80), threshold)
+
+	// only the highest threshold exceeded is notified
+	threshold, ok = exceededThreshold(budget, "2026-10", 120)
+	assert.True(t, ok)
+	assert.Equal(t, uint(100), threshold)
+
+	budget.NotifiedMonth, budget.NotifiedThreshold = "2026-10", 80
+	_, ok = exceededThreshold(budget, "2026-10", 90)
+	assert.False(t, ok)
+	threshold, ok = exceededThreshold(budget, "2026-10", 100)
+	assert.True(t, ok)
+	assert.Equal(t, uint(100), threshold)
+
+	// thresholds notified in the last month are notified again
+	threshold, ok = exceededThreshold(budget, "2026-11", 90)
+	assert.True(t, ok)
+	assert.Equal(t, uint(80), threshold)
+
+	budget.Amount = 0
+	_, ok = exceededThreshold(budget, "2026-11", 90)
+	assert.False(t, ok)
+}
+
+func TestElapsedSince(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	accountedAt := map[string]time.Time{
+		"hz": now.Add(-90 * time.Second),
+		"sh": now.Add(-24 * time.Hour),
+		"bj": now.Add(time.Second),
+	}
+
+	// regions never accounted are accounted for an interval
+	elapsed, ok := elapsedSince(accountedAt, "gz", now, time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, elapsed)
+
+	// the time lost by restarts is accounted
+	elapsed, ok = elapsedSince(accountedAt, "hz", now, time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Second, elapsed)
+
+	elapsed, ok = elapsedSince(accountedAt, "sh", now, time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, maxElapsed, elapsed)
+	elapsed, ok = elapsedSince(accountedAt, "sh", now, 2*time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Hour, elapsed)
+
+	// regions accounted by another instance are skipped
+	_, ok = elapsedSince(accountedAt, "bj", now, time.Minute)
+	assert.False(t, ok)
+}
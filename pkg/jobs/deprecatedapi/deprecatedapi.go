@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deprecatedapi
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/deprecatedapi"
+	"github.com/horizoncd/horizon/pkg/deprecatedapi/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Run scans the manifests of all clusters and template releases for deprecated apis periodically,
+// the getter returns the latest config so that the job settings can be reloaded at runtime
+func Run(ctx context.Context, configGetter func() *deprecatedapi.Config, manager *managerparam.Manager,
+	clusterGitRepo gitrepo.ClusterGitRepo, svc service.Service) {
+	// clusters are listed by a dummy user
+	// nolint
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{ID: 0})
+
+	jobConfig := configGetter()
+	log.Infof(ctx, "Starting scanning deprecated apis every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping scanning deprecated apis")
+	jobInterval := jobConfig.JobInterval
+	ticker := time.NewTicker(jobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			jobConfig = configGetter()
+			if jobConfig.JobInterval != jobInterval {
+				jobInterval = jobConfig.JobInterval
+				ticker.Reset(jobInterval)
+			}
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "deprecated api job starts to execute, rid: %v", rid)
+			process(ctx, manager, clusterGitRepo, svc)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func process(ctx context.Context, manager *managerparam.Manager,
+	clusterGitRepo gitrepo.ClusterGitRepo, svc service.Service) {
+	op := "job: deprecated api"
+	templates, err := manager.TemplateMgr.ListTemplate(ctx)
+	if err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to list templates, err: %v", err.Error())
+	}
+	for _, t := range templates {
+		releases, err := manager.TemplateReleaseMgr.ListByTemplateID(ctx, t.ID)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to list releases of template %v, err: %v",
+				t.Name, err.Error())
+			continue
+		}
+		for _, tr := range releases {
+			if err := svc.CheckTemplateRelease(ctx, tr); err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to check release %v of template %v, err: %+v",
+					tr.Name, t.Name, err)
+			}
+		}
+	}
+
+	_, clusters, err := manager.ClusterMgr.List(ctx, &q.Query{WithoutPagination: true})
+	if err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to list clusters, err: %v", err.Error())
+		return
+	}
+	for _, cluster := range clusters {
+		if err := checkCluster(ctx, cluster.Cluster, manager, clusterGitRepo, svc); err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to check cluster %v, err: %+v", cluster.Name, err)
+		}
+	}
+}
+
+// checkCluster checks the manifests of the cluster at the latest config commit,
+// the findings of freed clusters are deleted as they are not deployed
+func checkCluster(ctx context.Context, cluster *clustermodels.Cluster, manager *managerparam.Manager,
+	clusterGitRepo gitrepo.ClusterGitRepo, svc service.Service) error {
+	if cluster.Status == common.ClusterStatusFreed {
+		return manager.DeprecatedAPIMgr.DeleteByResource(ctx, common.ResourceCluster, cluster.ID)
+	}
+	application, err := manager.ApplicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	tr, err := manager.TemplateReleaseMgr.GetByTemplateNameAndRelease(ctx,
+		cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return err
+	}
+	commit, err := clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return err
+	}
+	_, err = svc.CheckCluster(ctx, application, cluster, tr, commit.Master)
+	return err
+}
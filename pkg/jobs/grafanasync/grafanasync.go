@@ -17,14 +17,9 @@ package grafanasync
 import (
 	"context"
 
-	"github.com/horizoncd/horizon/core/config"
 	"github.com/horizoncd/horizon/pkg/grafana"
-	"github.com/horizoncd/horizon/pkg/param/managerparam"
-	"k8s.io/client-go/kubernetes"
 )
 
-func Run(ctx context.Context, coreConfig *config.Config,
-	manager *managerparam.Manager, client kubernetes.Interface) {
-	grafanaService := grafana.NewService(coreConfig.GrafanaConfig, manager, client)
+func Run(ctx context.Context, grafanaService grafana.Service) {
 	grafanaService.SyncDatasource(ctx)
 }
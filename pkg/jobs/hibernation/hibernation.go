@@ -0,0 +1,169 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hibernation
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/hibernation"
+	hibernationmanager "github.com/horizoncd/horizon/pkg/hibernation/manager"
+	"github.com/horizoncd/horizon/pkg/hibernation/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+// Run hibernates and wakes clusters on their schedules periodically, configGetter returns
+// the effective config, which is read on every tick
+func Run(ctx context.Context, configGetter func() *hibernation.Config, userMgr usermanager.Manager,
+	hibernationMgr hibernationmanager.Manager, clusterCtr clusterctl.Controller, prCtr prctl.Controller) {
+	jobConfig := configGetter()
+	// verify account
+	user, err := userMgr.GetUserByID(ctx, jobConfig.AccountID)
+	if err != nil {
+		log.Errorf(ctx, "failed to verify operator, err: %v", err.Error())
+		panic(err)
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	// start job
+	log.Infof(ctx, "Starting hibernating clusters automatically every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping hibernating clusters automatically")
+	jobInterval := jobConfig.JobInterval
+	ticker := time.NewTicker(jobInterval)
+	defer ticker.Stop()
+	lastTick := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			jobConfig = configGetter()
+			if jobConfig.JobInterval != jobInterval {
+				jobInterval = jobConfig.JobInterval
+				ticker.Reset(jobInterval)
+			}
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "hibernation job starts to execute, rid: %v", rid)
+			process(ctx, jobConfig, lastTick, now, hibernationMgr, clusterCtr, prCtr)
+			lastTick = now
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process executes the hibernations whose schedules are due in (from, to]
+func process(ctx context.Context, jobConfig *hibernation.Config, from, to time.Time,
+	hibernationMgr hibernationmanager.Manager, clusterCtr clusterctl.Controller, prCtr prctl.Controller) {
+	op := "job: cluster hibernation"
+	hibernations, err := hibernationMgr.ListWithCluster(ctx)
+	if err != nil {
+		log.WithFiled(ctx, "op", op).Errorf("failed to list hibernations, err: %v", err.Error())
+		return
+	}
+
+	for _, h := range hibernations {
+		if !jobConfig.IsSupported(h.EnvironmentName) {
+			continue
+		}
+
+		// the latest pipelinerun is only needed to check whether the cluster is idle
+		lastActiveAt := time.Time{}
+		if h.IdleSeconds > 0 && h.Status == common.ClusterStatusEmpty {
+			prTotal, pipelineruns, err := prCtr.ListPipelineruns(ctx, h.ClusterID, false, q.Query{
+				PageNumber: 1,
+				PageSize:   1,
+			})
+			if err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("%+v", err)
+				continue
+			}
+			// only clusters which have been deployed are hibernated when idle
+			if prTotal > 0 {
+				lastActiveAt = h.ClusterUpdatedAt
+				if pipelineruns[0].UpdatedAt.After(lastActiveAt) {
+					lastActiveAt = pipelineruns[0].UpdatedAt
+				}
+			}
+		}
+
+		switch nextAction(ctx, h, lastActiveAt, from, to) {
+		case workload.ActionHibernate:
+			if err := clusterCtr.HibernateCluster(ctx, h.ClusterID); err != nil {
+				log.WithFiled(ctx, "op", op).
+					Errorf("failed to hibernate cluster: %v, err: %v", h.ClusterName, err.Error())
+			} else {
+				log.WithFiled(ctx, "op", op).Infof("cluster %v hibernated", h.ClusterName)
+			}
+		case workload.ActionWake:
+			if err := clusterCtr.WakeCluster(ctx, h.ClusterID); err != nil {
+				log.WithFiled(ctx, "op", op).
+					Errorf("failed to wake cluster: %v, err: %v", h.ClusterName, err.Error())
+			} else {
+				log.WithFiled(ctx, "op", op).Infof("cluster %v woken", h.ClusterName)
+			}
+		}
+	}
+}
+
+// nextAction returns the action to execute for the hibernation, or an empty string if nothing is due.
+// lastActiveAt is zero if the idle time should not be checked
+func nextAction(ctx context.Context, h *models.HibernationWithCluster, lastActiveAt, from, to time.Time) string {
+	switch h.Status {
+	case common.ClusterStatusHibernated:
+		if due(ctx, h.WakeSchedule, from, to) {
+			return workload.ActionWake
+		}
+	case common.ClusterStatusEmpty:
+		if due(ctx, h.HibernateSchedule, from, to) {
+			return workload.ActionHibernate
+		}
+		if h.IdleSeconds > 0 && !lastActiveAt.IsZero() &&
+			lastActiveAt.Add(time.Duration(h.IdleSeconds)*time.Second).Before(to) {
+			return workload.ActionHibernate
+		}
+	}
+	return ""
+}
+
+// due returns whether the schedule is due in (from, to]
+func due(ctx context.Context, schedule string, from, to time.Time) bool {
+	if schedule == "" {
+		return false
+	}
+	s, err := cron.ParseStandard(schedule)
+	if err != nil {
+		log.Warningf(ctx, "invalid schedule %q: %v", schedule, err)
+		return false
+	}
+	return !s.Next(from).After(to)
+}
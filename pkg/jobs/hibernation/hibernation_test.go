@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hibernation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/hibernation/models"
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+func TestNextAction(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2026, 10, 19, 19, 59, 30, 0, time.Local)
+	to := from.Add(time.Minute)
+
+	h := &models.HibernationWithCluster{
+		Hibernation: models.Hibernation{
+			HibernateSchedule: "0 20 * * *",
+			WakeSchedule:      "0 9 * * 1-5",
+		},
+		Status: common.ClusterStatusEmpty,
+	}
+	assert.Equal(t, workload.ActionHibernate, nextAction(ctx, h, time.Time{}, from, to))
+	// the schedule is not due in the next window
+	assert.Equal(t, "", nextAction(ctx, h, time.Time{}, to, to.Add(time.Minute)))
+
+	h.Status = common.ClusterStatusHibernated
+	assert.Equal(t, "", nextAction(ctx, h, time.Time{}, from, to))
+	// 2026-10-20 is a Tuesday
+	wakeFrom := time.Date(2026, 10, 20, 8, 59, 30, 0, time.Local)
+	assert.Equal(t, workload.ActionWake, nextAction(ctx, h, time.Time{}, wakeFrom, wakeFrom.Add(time.Minute)))
+
+	// freed clusters are neither hibernated nor woken
+	h.Status = common.ClusterStatusFreed
+	assert.Equal(t, "", nextAction(ctx, h, time.Time{}, from, to))
+
+	idle := &models.HibernationWithCluster{
+		Hibernation: models.Hibernation{IdleSeconds: 3600},
+		Status:      common.ClusterStatusEmpty,
+	}
+	assert.Equal(t, "", nextAction(ctx, idle, time.Time{}, from, to))
+	assert.Equal(t, "", nextAction(ctx, idle, to.Add(-30*time.Minute), from, to))
+	assert.Equal(t, workload.ActionHibernate, nextAction(ctx, idle, to.Add(-2*time.Hour), from, to))
+}
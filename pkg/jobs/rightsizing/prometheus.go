@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	_prometheusTimeout = 30 * time.Second
+
+	// the usage of a pod is the sum of its containers, and the usage of a cluster is the max of its pods
+	_queryCPU = `max(quantile_over_time(%s, sum by (pod) (rate(container_cpu_usage_seconds_total{` +
+		`namespace="%s",pod=~"%s",container!="",container!="POD"}[5m]))[%s:5m]))`
+	_queryMemory = `max(quantile_over_time(%s, sum by (pod) (container_memory_working_set_bytes{` +
+		`namespace="%s",pod=~"%s",container!="",container!="POD"})[%s:5m]))`
+)
+
+// podPattern matches the pods of the cluster, which are named by the cluster and the hash of replicasets
+// with a random suffix, or by the cluster and an ordinal
+func podPattern(cluster string) string {
+	return regexp.QuoteMeta(cluster) + `(-[a-z0-9]{6,10}-[a-z0-9]{5}|-[0-9]+)`
+}
+
+// queryUsage queries the usage history of the pods of the cluster from prometheus,
+// it returns false if there is no history
+func queryUsage(ctx context.Context, prometheusURL, namespace, cluster string, window time.Duration,
+	cpuPercentile, memoryPercentile float64) (*Usage, bool, error) {
+	client, err := api.NewClient(api.Config{Address: prometheusURL})
+	if err != nil {
+		return nil, false, err
+	}
+	promAPI := prometheusv1.NewAPI(client)
+	query := func(format string, percentile float64) (float64, bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, _prometheusTimeout)
+		defer cancel()
+		value, _, err := promAPI.Query(ctx, fmt.Sprintf(format, strconv.FormatFloat(percentile, 'f', -1, 64),
+			namespace, podPattern(cluster), model.Duration(window).String()), time.Now())
+		if err != nil {
+			return 0, false, err
+		}
+		vector, ok := value.(model.Vector)
+		if !ok {
+			return 0, false, fmt.Errorf("unexpected result type of prometheus: %v", value.Type())
+		}
+		if len(vector) == 0 || math.IsNaN(float64(vector[0].Value)) {
+			return 0, false, nil
+		}
+		return float64(vector[0].Value), true, nil
+	}
+
+	cpu, ok, err := query(_queryCPU, cpuPercentile)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	memory, ok, err := query(_queryMemory, memoryPercentile)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &Usage{
+		CPU:    int64(math.Ceil(cpu * 1000)),
+		Memory: int64(math.Ceil(memory / (1 << 20))),
+	}, true, nil
+}
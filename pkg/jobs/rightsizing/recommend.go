@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"math"
+
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
+	"github.com/horizoncd/horizon/pkg/config/rightsizing"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+)
+
+// Usage is the percentiles of the usage of a pod over the window, cpu in millicores and memory in MiB
+type Usage struct {
+	CPU    int64
+	Memory int64
+}
+
+// recommend returns the resources recommended by the usage, and whether they should be recommended,
+// which is false if they are the same as or within the tolerance of the current resources
+func recommend(config *rightsizing.Config, t *templateresource.Template,
+	current *resource.Resources, usage *Usage) (*resource.Resources, bool) {
+	targetCPU := int64(math.Ceil(float64(usage.CPU) * config.Headroom))
+	targetMemory := int64(math.Ceil(float64(usage.Memory) * config.Headroom))
+	recommended := &resource.Resources{Replicas: current.Replicas}
+
+	if t.TierPath != "" {
+		if len(t.Tiers) == 0 {
+			return nil, false
+		}
+		// the smallest tier that fits, or the largest tier if none fits
+		tier := t.Tiers[len(t.Tiers)-1]
+		for _, candidate := range t.Tiers {
+			if candidate.CPU >= targetCPU && candidate.Memory >= targetMemory {
+				tier = candidate
+				break
+			}
+		}
+		recommended.Tier, recommended.CPU, recommended.Memory = tier.Name, tier.CPU, tier.Memory
+		return recommended, recommended.Tier != current.Tier
+	}
+
+	recommended.CPU = roundUp(targetCPU, config.CPUStep)
+	recommended.Memory = roundUp(targetMemory, config.MemoryStep)
+	return recommended, !within(recommended.CPU, current.CPU, config.Tolerance) ||
+		!within(recommended.Memory, current.Memory, config.Tolerance)
+}
+
+// roundUp rounds the value up to a multiple of the step, the result is at least one step
+func roundUp(value, step int64) int64 {
+	if step <= 0 {
+		return value
+	}
+	if value <= step {
+		return step
+	}
+	return (value + step - 1) / step * step
+}
+
+func within(value, current int64, tolerance float64) bool {
+	if current == 0 {
+		return value == 0
+	}
+	return math.Abs(float64(value-current))/float64(current) <= tolerance
+}
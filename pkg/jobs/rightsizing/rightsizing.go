@@ -0,0 +1,154 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterconfigctl "github.com/horizoncd/horizon/core/controller/clusterconfig"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
+	"github.com/horizoncd/horizon/pkg/config/rightsizing"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rightsizing/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Run recommends resources of clusters by the usage history periodically, the getters return
+// the latest config so that the job settings can be reloaded at runtime
+func Run(ctx context.Context, configGetter func() *rightsizing.Config,
+	templateResourcesGetter func() *templateresource.Config, manager *managerparam.Manager,
+	clusterGitRepo gitrepo.ClusterGitRepo) {
+	// clusters are listed by a dummy user
+	// nolint
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{ID: 0})
+
+	jobConfig := configGetter()
+	log.Infof(ctx, "Starting recommending resources of clusters every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping recommending resources of clusters")
+	jobInterval := jobConfig.JobInterval
+	ticker := time.NewTicker(jobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			jobConfig = configGetter()
+			if jobConfig.JobInterval != jobInterval {
+				jobInterval = jobConfig.JobInterval
+				ticker.Reset(jobInterval)
+			}
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "rightsizing job starts to execute, rid: %v", rid)
+			process(ctx, jobConfig, templateResourcesGetter(), manager, clusterGitRepo)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func process(ctx context.Context, jobConfig *rightsizing.Config, templateResources *templateresource.Config,
+	manager *managerparam.Manager, clusterGitRepo gitrepo.ClusterGitRepo) {
+	op := "job: rightsizing"
+	if len(jobConfig.SupportedEnvs) == 0 {
+		return
+	}
+	for _, t := range templateResources.Templates {
+		_, clusters, err := manager.ClusterMgr.List(ctx, &q.Query{
+			Keywords: q.KeyWords{
+				common.ClusterQueryEnvironment: jobConfig.SupportedEnvs,
+				common.ClusterQueryByTemplate:  t.Name,
+			},
+			WithoutPagination: true,
+		})
+		if err != nil {
+			log.WithFiled(ctx, "op", op).Errorf("failed to list clusters of template %v, err: %v",
+				t.Name, err.Error())
+			continue
+		}
+		for _, cluster := range clusters {
+			if err := recommendCluster(ctx, jobConfig, t, cluster.Cluster, manager, clusterGitRepo); err != nil {
+				log.WithFiled(ctx, "op", op).Errorf("failed to recommend resources of cluster %v, err: %+v",
+					cluster.Name, err)
+			}
+		}
+	}
+}
+
+// recommendCluster saves the recommendation of the cluster, or deletes it if the current resources fit
+func recommendCluster(ctx context.Context, jobConfig *rightsizing.Config, t *templateresource.Template,
+	cluster *clustermodels.Cluster, manager *managerparam.Manager, clusterGitRepo gitrepo.ClusterGitRepo) error {
+	region, err := manager.RegionMgr.GetRegionByName(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
+	if region.PrometheusURL == "" {
+		return nil
+	}
+	application, err := manager.ApplicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	files, err := clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return err
+	}
+	envValue, err := clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil || envValue == nil {
+		return err
+	}
+	current, err := resource.Get(t, map[string]interface{}{
+		clusterconfigctl.SectionApplication: files.ApplicationJSONBlob,
+		clusterconfigctl.SectionPipeline:    files.PipelineJSONBlob,
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	usage, ok, err := queryUsage(ctx, region.PrometheusURL, envValue.Namespace, cluster.Name,
+		jobConfig.Window, jobConfig.CPUPercentile, jobConfig.MemoryPercentile)
+	if err != nil || !ok {
+		return err
+	}
+	recommended, ok := recommend(jobConfig, t, current, usage)
+	if !ok {
+		return manager.RecommendationMgr.DeleteByClusterID(ctx, cluster.ID)
+	}
+	_, err = manager.RecommendationMgr.Save(ctx, &models.Recommendation{
+		ClusterID:         cluster.ID,
+		CPUUsage:          usage.CPU,
+		MemoryUsage:       usage.Memory,
+		CurrentTier:       current.Tier,
+		CurrentCPU:        current.CPU,
+		CurrentMemory:     current.Memory,
+		RecommendedTier:   recommended.Tier,
+		RecommendedCPU:    recommended.CPU,
+		RecommendedMemory: recommended.Memory,
+		Replicas:          current.Replicas,
+	})
+	return err
+}
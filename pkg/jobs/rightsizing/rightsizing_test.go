@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
+	"github.com/horizoncd/horizon/pkg/config/rightsizing"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+)
+
+var _config = &rightsizing.Config{
+	Headroom:   1.2,
+	Tolerance:  0.1,
+	CPUStep:    100,
+	MemoryStep: 128,
+}
+
+func TestRecommendTiers(t *testing.T) {
+	template := &templateresource.Template{
+		TierPath: "application.app.spec.resource",
+		Tiers: []*templateresource.Tier{
+			{Name: "x-small", CPU: 500, Memory: 1024},
+			{Name: "small", CPU: 1000, Memory: 2048},
+			{Name: "large", CPU: 4000, Memory: 8192},
+		},
+	}
+	current := &resource.Resources{Tier: "large", CPU: 4000, Memory: 8192, Replicas: 2}
+
+	recommended, ok := recommend(_config, template, current, &Usage{CPU: 300, Memory: 1000})
+	assert.True(t, ok)
+	assert.Equal(t, &resource.Resources{Tier: "small", CPU: 1000, Memory: 2048, Replicas: 2}, recommended)
+
+	_, ok = recommend(_config, template, current, &Usage{CPU: 3000, Memory: 1000})
+	assert.False(t, ok)
+
+	// the largest tier is recommended if none fits
+	recommended, ok = recommend(_config, template, &resource.Resources{Tier: "small"},
+		&Usage{CPU: 8000, Memory: 1000})
+	assert.True(t, ok)
+	assert.Equal(t, "large", recommended.Tier)
+}
+
+func TestRecommendRequests(t *testing.T) {
+	template := &templateresource.Template{
+		CPUPath:    "application.app.resource.cpu",
+		MemoryPath: "application.app.resource.memory",
+	}
+	current := &resource.Resources{CPU: 2000, Memory: 4096, Replicas: 1}
+
+	recommended, ok := recommend(_config, template, current, &Usage{CPU: 410, Memory: 1000})
+	assert.True(t, ok)
+	assert.Equal(t, &resource.Resources{CPU: 500, Memory: 1280, Replicas: 1}, recommended)
+
+	// within the tolerance
+	_, ok = recommend(_config, template, current, &Usage{CPU: 1600, Memory: 3400})
+	assert.False(t, ok)
+
+	recommended, ok = recommend(_config, template, current, &Usage{CPU: 10, Memory: 10})
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), recommended.CPU)
+	assert.Equal(t, int64(128), recommended.Memory)
+}
+
+func TestQueryUsage(t *testing.T) {
+	queries := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		query := r.Form.Get("query")
+		queries = append(queries, query)
+		value := `"1073741824"`
+		if strings.Contains(query, "container_cpu_usage_seconds_total") {
+			value = `"0.25"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[` +
+			`{"metric":{},"value":[1600000000,` + value + `]}]}}`))
+	}))
+	defer server.Close()
+
+	usage, ok, err := queryUsage(context.TODO(), server.URL, "test", "app-test", 7*24*time.Hour, 0.95, 0.99)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, &Usage{CPU: 250, Memory: 1024}, usage)
+	assert.Equal(t, 2, len(queries))
+	assert.Contains(t, queries[0], `quantile_over_time(0.95,`)
+	assert.Contains(t, queries[0], `pod=~"app-test(-[a-z0-9]{6,10}-[a-z0-9]{5}|-[0-9]+)"`)
+	assert.Contains(t, queries[1], `[1w:5m]`)
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer empty.Close()
+	_, ok, err = queryUsage(context.TODO(), empty.URL, "test", "app-test", time.Hour, 0.95, 0.99)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
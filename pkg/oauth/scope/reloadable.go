@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scope
+
+import (
+	"sync/atomic"
+
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
+// ReloadableService is a Service whose scopes can be replaced at runtime
+type ReloadableService struct {
+	service atomic.Value
+}
+
+var _ Service = (*ReloadableService)(nil)
+
+func NewReloadableService(service Service) *ReloadableService {
+	r := &ReloadableService{}
+	r.Reload(service)
+	return r
+}
+
+// Reload replaces the underlying service
+func (r *ReloadableService) Reload(service Service) {
+	r.service.Store(&service)
+}
+
+func (r *ReloadableService) get() Service {
+	return *r.service.Load().(*Service)
+}
+
+func (r *ReloadableService) GetRulesByScope(scopes []string) []types.Role {
+	return r.get().GetRulesByScope(scopes)
+}
+
+func (r *ReloadableService) GetAllScopeNames() []string {
+	return r.get().GetAllScopeNames()
+}
+
+func (r *ReloadableService) GetAllScopes() []types.Role {
+	return r.get().GetAllScopes()
+}
@@ -24,21 +24,31 @@ import (
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationregionmanager "github.com/horizoncd/horizon/pkg/applicationregion/manager"
 	badgemanager "github.com/horizoncd/horizon/pkg/badge/manager"
+	batchoperationmanager "github.com/horizoncd/horizon/pkg/batchoperation/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	costmanager "github.com/horizoncd/horizon/pkg/cost/manager"
+	deprecatedapimanager "github.com/horizoncd/horizon/pkg/deprecatedapi/manager"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	environmentregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventManager "github.com/horizoncd/horizon/pkg/event/manager"
+	gittriggermanager "github.com/horizoncd/horizon/pkg/gittrigger/manager"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	hibernationmanager "github.com/horizoncd/horizon/pkg/hibernation/manager"
 	idpmanager "github.com/horizoncd/horizon/pkg/idp/manager"
+	policymanager "github.com/horizoncd/horizon/pkg/policy/manager"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
+	previewmanager "github.com/horizoncd/horizon/pkg/preview/manager"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	registrymanager "github.com/horizoncd/horizon/pkg/registry/manager"
+	rightsizingmanager "github.com/horizoncd/horizon/pkg/rightsizing/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	templateschematagmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
 	trtmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
+	terminalrecordingmanager "github.com/horizoncd/horizon/pkg/terminalrecording/manager"
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	linkmanager "github.com/horizoncd/horizon/pkg/userlink/manager"
@@ -72,6 +82,16 @@ type Manager struct {
 	EventMgr             eventManager.Manager
 	TokenMgr             tokenmanager.Manager
 	BadgeMgr             badgemanager.Manager
+	GitTriggerMgr        gittriggermanager.Manager
+	TerminalRecordingMgr terminalrecordingmanager.Manager
+	HibernationMgr       hibernationmanager.Manager
+	PreviewMgr           previewmanager.Manager
+	BatchOperationMgr    batchoperationmanager.Manager
+	RecommendationMgr    rightsizingmanager.Manager
+	CostMgr              costmanager.Manager
+	QuotaMgr             quotamanager.Manager
+	PolicyMgr            policymanager.Manager
+	DeprecatedAPIMgr     deprecatedapimanager.Manager
 }
 
 func InitManager(db *gorm.DB) *Manager {
@@ -102,5 +122,15 @@ func InitManager(db *gorm.DB) *Manager {
 		EventMgr:             eventManager.New(db),
 		TokenMgr:             tokenmanager.New(db),
 		BadgeMgr:             badgemanager.New(db),
+		GitTriggerMgr:        gittriggermanager.New(db),
+		TerminalRecordingMgr: terminalrecordingmanager.New(db),
+		HibernationMgr:       hibernationmanager.New(db),
+		PreviewMgr:           previewmanager.New(db),
+		BatchOperationMgr:    batchoperationmanager.New(db),
+		RecommendationMgr:    rightsizingmanager.New(db),
+		CostMgr:              costmanager.New(db),
+		QuotaMgr:             quotamanager.New(db),
+		PolicyMgr:            policymanager.New(db),
+		DeprecatedAPIMgr:     deprecatedapimanager.New(db),
 	}
 }
@@ -17,11 +17,13 @@ package param
 import (
 	applicationgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
+	"github.com/horizoncd/horizon/pkg/argocd"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	deprecatedapiservice "github.com/horizoncd/horizon/pkg/deprecatedapi/service"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/grafana"
@@ -31,14 +33,18 @@ import (
 	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
 	"github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	policyservice "github.com/horizoncd/horizon/pkg/policy/service"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 
 	"github.com/horizoncd/horizon/core/controller/build"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
+	"github.com/horizoncd/horizon/pkg/workload/generic"
 )
 
 type Param struct {
@@ -47,18 +53,21 @@ type Param struct {
 
 	OauthManager oauthmanager.Manager
 	// service
-	AutoFreeSvc    *service.AutoFreeSVC
-	MemberService  memberservice.Service
-	ApplicationSvc applicationservice.Service
-	ClusterSvc     clusterservice.Service
-	GroupSvc       groupsvc.Service
-	EventSvc       eventservice.Service
-	UserSvc        userservice.Service
-	TokenSvc       tokenservice.Service
-	RoleService    role.Service
-	PRService      prservice.Service
-	ScopeService   scope.Service
-	GrafanaService grafana.Service
+	AutoFreeSvc      *service.AutoFreeSVC
+	MemberService    memberservice.Service
+	ApplicationSvc   applicationservice.Service
+	ClusterSvc       clusterservice.Service
+	GroupSvc         groupsvc.Service
+	EventSvc         eventservice.Service
+	UserSvc          userservice.Service
+	TokenSvc         tokenservice.Service
+	RoleService      role.Service
+	PRService        prservice.Service
+	ScopeService     scope.Service
+	GrafanaService   grafana.Service
+	QuotaSvc         quotaservice.Service
+	PolicySvc        policyservice.Service
+	DeprecatedAPISvc deprecatedapiservice.Service
 
 	// others
 	Hook                 hook.Hook
@@ -67,8 +76,11 @@ type Param struct {
 	CD                   cd.CD
 	K8sUtil              cd.K8sUtil
 	OutputGetter         output.Getter
+	MigrationGetter      migration.Getter
 	TektonFty            factory.Factory
+	ArgoCDFty            argocd.Factory
 	ClusterGitRepo       clustergitrepo.ClusterGitRepo
 	GitGetter            code.GitGetter
 	BuildSchema          *build.Schema
+	WorkloadRegistry     *generic.Registry
 }
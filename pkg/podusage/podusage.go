@@ -0,0 +1,164 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podusage
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/util/log"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	SourceMetricsAPI = "metrics.k8s.io"
+	SourcePrometheus = "prometheus"
+
+	reasonOOMKilled = "OOMKilled"
+)
+
+// Resource is the usage, request and limit of cpu in millicores or memory in bytes,
+// request and limit are zero if not set
+type Resource struct {
+	Usage   int64 `json:"usage"`
+	Request int64 `json:"request"`
+	Limit   int64 `json:"limit"`
+}
+
+func (r *Resource) add(o Resource) {
+	r.Usage += o.Usage
+	r.Request += o.Request
+	r.Limit += o.Limit
+}
+
+type Container struct {
+	Name     string   `json:"name"`
+	CPU      Resource `json:"cpu"`
+	Memory   Resource `json:"memory"`
+	Restarts int      `json:"restarts"`
+	// OOMKills is known from the last terminated state of the container, since kubelet keeps only the last one
+	OOMKills int `json:"oomKills"`
+}
+
+// Pod is the usage of a pod, Source is empty if the usage is not available from any source
+type Pod struct {
+	Source     string      `json:"source,omitempty"`
+	CPU        Resource    `json:"cpu"`
+	Memory     Resource    `json:"memory"`
+	Restarts   int         `json:"restarts"`
+	OOMKills   int         `json:"oomKills"`
+	Containers []Container `json:"containers"`
+}
+
+// Summary is the usage aggregated over pods, such as the pods of a revision
+type Summary struct {
+	Pods     int      `json:"pods"`
+	CPU      Resource `json:"cpu"`
+	Memory   Resource `json:"memory"`
+	Restarts int      `json:"restarts"`
+	OOMKills int      `json:"oomKills"`
+}
+
+// Sample is the cpu usage in millicores and the memory usage in bytes of a container
+type Sample struct {
+	CPU    int64
+	Memory int64
+}
+
+// Source queries samples of the containers of pods, keyed by pod name and container name
+type Source interface {
+	Name() string
+	Query(ctx context.Context, namespace string, pods []string) (map[string]map[string]Sample, error)
+}
+
+// Get returns the usage of pods keyed by pod name, samples are queried from sources in order
+// until one succeeds, requests, limits, restarts and OOM kills are read from the pods
+func Get(ctx context.Context, pods []corev1.Pod, sources ...Source) map[string]*Pod {
+	result := make(map[string]*Pod, len(pods))
+	if len(pods) == 0 {
+		return result
+	}
+
+	var (
+		samples map[string]map[string]Sample
+		source  string
+	)
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	for _, s := range sources {
+		var err error
+		samples, err = s.Query(ctx, pods[0].Namespace, names)
+		if err == nil {
+			source = s.Name()
+			break
+		}
+		log.Warningf(ctx, "failed to query pod usage from %s: %v", s.Name(), err)
+	}
+
+	for _, pod := range pods {
+		usage := &Pod{Source: source, Containers: make([]Container, 0, len(pod.Spec.Containers))}
+		statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.ContainerStatuses))
+		for _, status := range pod.Status.ContainerStatuses {
+			statuses[status.Name] = status
+		}
+		for _, c := range pod.Spec.Containers {
+			sample := samples[pod.Name][c.Name]
+			container := Container{
+				Name: c.Name,
+				CPU: Resource{
+					Usage:   sample.CPU,
+					Request: c.Resources.Requests.Cpu().MilliValue(),
+					Limit:   c.Resources.Limits.Cpu().MilliValue(),
+				},
+				Memory: Resource{
+					Usage:   sample.Memory,
+					Request: c.Resources.Requests.Memory().Value(),
+					Limit:   c.Resources.Limits.Memory().Value(),
+				},
+			}
+			if status, ok := statuses[c.Name]; ok {
+				container.Restarts = int(status.RestartCount)
+				if status.LastTerminationState.Terminated != nil &&
+					status.LastTerminationState.Terminated.Reason == reasonOOMKilled {
+					container.OOMKills++
+				}
+				if status.State.Terminated != nil && status.State.Terminated.Reason == reasonOOMKilled {
+					container.OOMKills++
+				}
+			}
+			usage.CPU.add(container.CPU)
+			usage.Memory.add(container.Memory)
+			usage.Restarts += container.Restarts
+			usage.OOMKills += container.OOMKills
+			usage.Containers = append(usage.Containers, container)
+		}
+		result[pod.Name] = usage
+	}
+	return result
+}
+
+// Summarize aggregates the usage of pods
+func Summarize(pods ...*Pod) *Summary {
+	summary := &Summary{}
+	for _, pod := range pods {
+		summary.Pods++
+		summary.CPU.add(pod.CPU)
+		summary.Memory.add(pod.Memory)
+		summary.Restarts += pod.Restarts
+		summary.OOMKills += pod.OOMKills
+	}
+	return summary
+}
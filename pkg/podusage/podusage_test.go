@@ -0,0 +1,128 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podusage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func pod(name string, restarts int32, lastReason string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("512Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		}}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "app",
+			RestartCount: restarts,
+			LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: lastReason},
+			},
+		}}},
+	}
+}
+
+type failedSource struct{}
+
+func (failedSource) Name() string {
+	return "failed"
+}
+
+func (failedSource) Query(context.Context, string, []string) (map[string]map[string]Sample, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestMetricsAPI(t *testing.T) {
+	podMetrics := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]interface{}{"name": "demo-1", "namespace": "test"},
+		"containers": []interface{}{map[string]interface{}{
+			"name":  "app",
+			"usage": map[string]interface{}{"cpu": "250000000n", "memory": "256Mi"},
+		}},
+	}}
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{GVRPodMetrics: "PodMetricsList"})
+	_, err := client.Resource(GVRPodMetrics).Namespace("test").Create(context.TODO(), podMetrics,
+		metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	usages := Get(context.TODO(), []corev1.Pod{pod("demo-1", 3, reasonOOMKilled), pod("demo-2", 0, "")},
+		failedSource{}, NewMetricsAPI(client))
+	assert.Equal(t, 2, len(usages))
+	usage := usages["demo-1"]
+	assert.Equal(t, SourceMetricsAPI, usage.Source)
+	assert.Equal(t, Resource{Usage: 250, Request: 500, Limit: 1000}, usage.CPU)
+	assert.Equal(t, Resource{Usage: 256 << 20, Request: 512 << 20, Limit: 1 << 30}, usage.Memory)
+	assert.Equal(t, 3, usage.Restarts)
+	assert.Equal(t, 1, usage.OOMKills)
+	assert.Equal(t, "app", usage.Containers[0].Name)
+	assert.Equal(t, int64(0), usages["demo-2"].CPU.Usage)
+
+	summary := Summarize(usages["demo-1"], usages["demo-2"])
+	assert.Equal(t, 2, summary.Pods)
+	assert.Equal(t, Resource{Usage: 250, Request: 1000, Limit: 2000}, summary.CPU)
+	assert.Equal(t, 3, summary.Restarts)
+	assert.Equal(t, 1, summary.OOMKills)
+}
+
+func TestPrometheus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		query := r.Form.Get("query")
+		value := `"268435456"`
+		if strings.Contains(query, "container_cpu_usage_seconds_total") {
+			value = `"0.1"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[` +
+			`{"metric":{"pod":"demo-1","container":"app"},"value":[1600000000,` + value + `]}]}}`))
+	}))
+	defer server.Close()
+
+	prometheus, err := NewPrometheus(server.URL)
+	assert.Nil(t, err)
+	usages := Get(context.TODO(), []corev1.Pod{pod("demo-1", 0, "")}, prometheus)
+	usage := usages["demo-1"]
+	assert.Equal(t, SourcePrometheus, usage.Source)
+	assert.Equal(t, int64(100), usage.CPU.Usage)
+	assert.Equal(t, int64(256<<20), usage.Memory.Usage)
+	assert.Equal(t, 0, usage.OOMKills)
+}
@@ -0,0 +1,165 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podusage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var GVRPodMetrics = schema.GroupVersionResource{
+	Group:    "metrics.k8s.io",
+	Version:  "v1beta1",
+	Resource: "pods",
+}
+
+const (
+	_metricsAPITimeout = 5 * time.Second
+	_prometheusTimeout = 5 * time.Second
+
+	_queryCPU = `sum by (pod, container) (rate(container_cpu_usage_seconds_total{` +
+		`namespace="%s",pod=~"%s",container!="",container!="POD"}[5m]))`
+	_queryMemory = `sum by (pod, container) (container_memory_working_set_bytes{` +
+		`namespace="%s",pod=~"%s",container!="",container!="POD"})`
+)
+
+// metricsAPI queries the metrics server of the region by metrics.k8s.io
+type metricsAPI struct {
+	client dynamic.Interface
+}
+
+func NewMetricsAPI(client dynamic.Interface) Source {
+	return &metricsAPI{client: client}
+}
+
+func (*metricsAPI) Name() string {
+	return SourceMetricsAPI
+}
+
+func (m *metricsAPI) Query(ctx context.Context, namespace string,
+	pods []string) (map[string]map[string]Sample, error) {
+	wanted := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		wanted[pod] = true
+	}
+	ctx, cancel := context.WithTimeout(ctx, _metricsAPITimeout)
+	defer cancel()
+	list, err := m.client.Resource(GVRPodMetrics).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	samples := make(map[string]map[string]Sample, len(pods))
+	for _, item := range list.Items {
+		if !wanted[item.GetName()] {
+			continue
+		}
+		containers, _, err := unstructured.NestedSlice(item.Object, "containers")
+		if err != nil {
+			return nil, err
+		}
+		samples[item.GetName()] = make(map[string]Sample, len(containers))
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(container, "name")
+			cpu, _, _ := unstructured.NestedString(container, "usage", "cpu")
+			memory, _, _ := unstructured.NestedString(container, "usage", "memory")
+			sample := Sample{}
+			if q, err := resource.ParseQuantity(cpu); err == nil {
+				sample.CPU = q.MilliValue()
+			}
+			if q, err := resource.ParseQuantity(memory); err == nil {
+				sample.Memory = q.Value()
+			}
+			samples[item.GetName()][name] = sample
+		}
+	}
+	return samples, nil
+}
+
+// prometheus queries the prometheus of the region by the metrics of cadvisor
+type prometheus struct {
+	api prometheusv1.API
+}
+
+func NewPrometheus(url string) (Source, error) {
+	client, err := api.NewClient(api.Config{Address: url})
+	if err != nil {
+		return nil, err
+	}
+	return &prometheus{api: prometheusv1.NewAPI(client)}, nil
+}
+
+func (*prometheus) Name() string {
+	return SourcePrometheus
+}
+
+func (p *prometheus) Query(ctx context.Context, namespace string,
+	pods []string) (map[string]map[string]Sample, error) {
+	quoted := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		quoted = append(quoted, regexp.QuoteMeta(pod))
+	}
+	podRegex := strings.Join(quoted, "|")
+
+	samples := make(map[string]map[string]Sample, len(pods))
+	set := func(query string, f func(sample *Sample, value float64)) error {
+		ctx, cancel := context.WithTimeout(ctx, _prometheusTimeout)
+		defer cancel()
+		value, _, err := p.api.Query(ctx, fmt.Sprintf(query, namespace, podRegex), time.Now())
+		if err != nil {
+			return err
+		}
+		vector, ok := value.(model.Vector)
+		if !ok {
+			return fmt.Errorf("unexpected result type of prometheus: %v", value.Type())
+		}
+		for _, s := range vector {
+			pod, container := string(s.Metric["pod"]), string(s.Metric["container"])
+			if samples[pod] == nil {
+				samples[pod] = make(map[string]Sample)
+			}
+			sample := samples[pod][container]
+			f(&sample, float64(s.Value))
+			samples[pod][container] = sample
+		}
+		return nil
+	}
+	if err := set(_queryCPU, func(sample *Sample, value float64) {
+		sample.CPU = int64(value * 1000)
+	}); err != nil {
+		return nil, err
+	}
+	if err := set(_queryMemory, func(sample *Sample, value float64) {
+		sample.Memory = int64(value)
+	}); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
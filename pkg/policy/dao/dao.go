@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/policy/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, policy *models.Policy) (*models.Policy, error)
+	GetByID(ctx context.Context, id uint) (*models.Policy, error)
+	List(ctx context.Context) ([]*models.Policy, error)
+	UpdateByID(ctx context.Context, id uint, policy *models.Policy) (*models.Policy, error)
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, policy *models.Policy) (*models.Policy, error) {
+	if err := d.checkNameConflict(ctx, 0, policy.Name); err != nil {
+		return nil, err
+	}
+	if err := d.db.WithContext(ctx).Create(policy).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.PolicyInDB, err.Error())
+	}
+	return policy, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.Policy, error) {
+	var policy models.Policy
+	if err := d.db.WithContext(ctx).Where("id = ?", id).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.PolicyInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.PolicyInDB, err.Error())
+	}
+	return &policy, nil
+}
+
+func (d *dao) List(ctx context.Context) ([]*models.Policy, error) {
+	var policies []*models.Policy
+	if err := d.db.WithContext(ctx).Order("id").Find(&policies).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.PolicyInDB, err.Error())
+	}
+	return policies, nil
+}
+
+func (d *dao) UpdateByID(ctx context.Context, id uint, policy *models.Policy) (*models.Policy, error) {
+	existing, err := d.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.checkNameConflict(ctx, id, policy.Name); err != nil {
+		return nil, err
+	}
+	where := d.db.WithContext(ctx).Model(existing).Where("id = ?", id)
+	if err := where.Updates(map[string]interface{}{
+		"name":         policy.Name,
+		"description":  policy.Description,
+		"expression":   policy.Expression,
+		"message":      policy.Message,
+		"mode":         policy.Mode,
+		"kinds":        policy.Kinds,
+		"environments": policy.Environments,
+		"group_ids":    policy.GroupIDs,
+		"updated_by":   policy.UpdatedBy,
+	}).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.PolicyInDB, err.Error())
+	}
+	if err := where.First(existing).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PolicyInDB, err.Error())
+	}
+	return existing, nil
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Policy{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.PolicyInDB, err.Error())
+	}
+	return nil
+}
+
+// checkNameConflict checks whether the name is used by another policy than the one with the id
+func (d *dao) checkNameConflict(ctx context.Context, id uint, name string) error {
+	var count int64
+	if err := d.db.WithContext(ctx).Model(&models.Policy{}).
+		Where("name = ? and id != ?", name, id).Count(&count).Error; err != nil {
+		return herrors.NewErrGetFailed(herrors.PolicyInDB, err.Error())
+	}
+	if count > 0 {
+		return perror.Wrapf(herrors.ErrNameConflict, "policy %s already exists", name)
+	}
+	return nil
+}
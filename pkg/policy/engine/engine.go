@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+)
+
+// variables of the expressions of policies
+const (
+	// VarObject is the object rendered from the chart of the cluster
+	VarObject = "object"
+	// VarCluster is the name of the cluster
+	VarCluster = "cluster"
+	// VarEnvironment is the environment of the cluster
+	VarEnvironment = "environment"
+)
+
+// Program is the compiled CEL expression of a policy
+type Program struct {
+	program cel.Program
+}
+
+// Compile compiles the CEL expression of a policy, which should be a boolean expression
+// that is true if the object complies with the policy
+func Compile(expression string) (*Program, error) {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar(VarObject, decls.NewMapType(decls.String, decls.Dyn)),
+		decls.NewVar(VarCluster, decls.String),
+		decls.NewVar(VarEnvironment, decls.String),
+	))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	// the result type of dynamic expressions is checked when they are evaluated
+	if t := ast.ResultType(); t.GetPrimitive() != decls.Bool.GetPrimitive() && t.GetDyn() == nil {
+		return nil, fmt.Errorf("expression should be boolean, but it's %v", t)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{program: program}, nil
+}
+
+// Evaluate returns whether the object of the cluster complies with the policy
+func (p *Program) Evaluate(object map[string]interface{}, cluster, environment string) (bool, error) {
+	out, _, err := p.program.Eval(map[string]interface{}{
+		VarObject:      object,
+		VarCluster:     cluster,
+		VarEnvironment: environment,
+	})
+	if err != nil {
+		return false, err
+	}
+	complied, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression should be boolean, but it's %v", out.Value())
+	}
+	return complied, nil
+}
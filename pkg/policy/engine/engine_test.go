@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine(t *testing.T) {
+	deployment := func(privileged bool, image string) map[string]interface{} {
+		return map[string]interface{}{
+			"kind": "Deployment",
+			"spec": map[string]interface{}{
+				"replicas": int64(2),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":            "app",
+								"image":           image,
+								"securityContext": map[string]interface{}{"privileged": privileged},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	for _, c := range []struct {
+		expression string
+		object     map[string]interface{}
+		complied   bool
+	}{
+		{
+			expression: `object.spec.template.spec.containers.all(c,
+				!has(c.securityContext) || !has(c.securityContext.privileged) || !c.securityContext.privileged)`,
+			object:   deployment(true, "harbor.example.com/app:v1"),
+			complied: false,
+		},
+		{
+			expression: `object.spec.template.spec.containers.all(c, c.image.startsWith("harbor.example.com/"))`,
+			object:     deployment(false, "harbor.example.com/app:v1"),
+			complied:   true,
+		},
+		{
+			expression: `environment != "online" || object.spec.replicas >= 2`,
+			object:     deployment(false, "nginx"),
+			complied:   true,
+		},
+		{
+			expression: `!has(object.spec.volumes)`,
+			object:     deployment(false, "nginx"),
+			complied:   true,
+		},
+	} {
+		program, err := Compile(c.expression)
+		assert.Nil(t, err)
+		complied, err := program.Evaluate(c.object, "app-online", "online")
+		assert.Nil(t, err)
+		assert.Equal(t, c.complied, complied, c.expression)
+	}
+
+	// not boolean
+	_, err := Compile(`cluster + "-suffix"`)
+	assert.NotNil(t, err)
+	// invalid
+	_, err = Compile(`object.spec.`)
+	assert.NotNil(t, err)
+
+	// dynamic result is checked when evaluated
+	program, err := Compile(`object.spec.replicas`)
+	assert.Nil(t, err)
+	_, err = program.Evaluate(deployment(false, "nginx"), "app-online", "online")
+	assert.NotNil(t, err)
+
+	// missing fields
+	program, err = Compile(`object.spec.template.spec.hostNetwork == false`)
+	assert.Nil(t, err)
+	_, err = program.Evaluate(deployment(false, "nginx"), "app-online", "online")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/policy/dao"
+	"github.com/horizoncd/horizon/pkg/policy/models"
+)
+
+type Manager interface {
+	// Create creates the policy, the name of which should be unique
+	Create(ctx context.Context, policy *models.Policy) (*models.Policy, error)
+	GetByID(ctx context.Context, id uint) (*models.Policy, error)
+	List(ctx context.Context) ([]*models.Policy, error)
+	UpdateByID(ctx context.Context, id uint, policy *models.Policy) (*models.Policy, error)
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, policy *models.Policy) (*models.Policy, error) {
+	return m.dao.Create(ctx, policy)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.Policy, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context) ([]*models.Policy, error) {
+	return m.dao.List(ctx)
+}
+
+func (m *manager) UpdateByID(ctx context.Context, id uint, policy *models.Policy) (*models.Policy, error) {
+	return m.dao.UpdateByID(ctx, id, policy)
+}
+
+func (m *manager) DeleteByID(ctx context.Context, id uint) error {
+	return m.dao.DeleteByID(ctx, id)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/policy/models"
+)
+
+func TestPolicies(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Policy{}))
+	mgr := New(db)
+	ctx := context.Background()
+
+	_, err := mgr.GetByID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	privileged, err := mgr.Create(ctx, &models.Policy{Name: "no-privileged", Expression: "true",
+		Mode: models.ModeEnforce, Environments: "online", GroupIDs: "1,2"})
+	assert.Nil(t, err)
+	hostPath, err := mgr.Create(ctx, &models.Policy{Name: "no-host-path", Expression: "true",
+		Mode: models.ModeAudit})
+	assert.Nil(t, err)
+	_, err = mgr.Create(ctx, &models.Policy{Name: "no-privileged", Expression: "true"})
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	policy, err := mgr.UpdateByID(ctx, privileged.ID, &models.Policy{Name: "no-privileged",
+		Expression: "false", Mode: models.ModeAudit, GroupIDs: "3", UpdatedBy: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, "false", policy.Expression)
+	assert.Equal(t, models.ModeAudit, policy.Mode)
+	assert.Equal(t, "", policy.Environments)
+	assert.Equal(t, "3", policy.GroupIDs)
+	assert.Equal(t, uint(2), policy.UpdatedBy)
+	_, err = mgr.UpdateByID(ctx, privileged.ID, &models.Policy{Name: "no-host-path"})
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	policies, err := mgr.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(policies))
+
+	assert.Nil(t, mgr.DeleteByID(ctx, hostPath.ID))
+	policies, err = mgr.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(policies))
+	_, err = mgr.Create(ctx, &models.Policy{Name: "no-host-path", Expression: "true"})
+	assert.Nil(t, err)
+}
+
+func TestBound(t *testing.T) {
+	policy := &models.Policy{Environments: "test, online", GroupIDs: "2", Kinds: "Deployment,StatefulSet"}
+	assert.True(t, policy.Bound("online", []uint{1, 2}))
+	assert.False(t, policy.Bound("online", []uint{1, 3}))
+	assert.False(t, policy.Bound("dev", []uint{2}))
+	assert.True(t, policy.Matches("StatefulSet"))
+	assert.False(t, policy.Matches("Service"))
+
+	policy = &models.Policy{}
+	assert.True(t, policy.Bound("dev", nil))
+	assert.True(t, policy.Matches("Service"))
+}
@@ -0,0 +1,108 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	// ModeAudit reports the violations of the policy without blocking the deployment
+	ModeAudit = "audit"
+	// ModeEnforce reports the violations of the policy and blocks the deployment
+	ModeEnforce = "enforce"
+)
+
+// Policy is evaluated on the objects rendered from the chart of a cluster before the cluster is deployed.
+// Expression is a CEL expression that is true if an object complies with the policy.
+type Policy struct {
+	global.Model
+
+	Name        string
+	Description string
+	Expression  string
+	// Message describes the violation of the policy
+	Message string
+	Mode    string
+	// Kinds are comma separated kinds of the objects evaluated, all objects are evaluated if it's empty
+	Kinds string
+	// Environments are comma separated environments bound, all environments are bound if it's empty
+	Environments string
+	// GroupIDs are comma separated ids of the groups bound with their subgroups,
+	// all groups are bound if it's empty
+	GroupIDs  string
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+func (Policy) TableName() string {
+	return "tb_policy"
+}
+
+// Bound returns whether the policy is bound to the environment and any of the groups
+func (p *Policy) Bound(environment string, groupIDs []uint) bool {
+	if environments := SplitList(p.Environments); len(environments) > 0 {
+		found := false
+		for _, e := range environments {
+			if e == environment {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	boundGroupIDs := SplitList(p.GroupIDs)
+	if len(boundGroupIDs) == 0 {
+		return true
+	}
+	for _, boundGroupID := range boundGroupIDs {
+		for _, groupID := range groupIDs {
+			if boundGroupID == strconv.FormatUint(uint64(groupID), 10) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Matches returns whether the object of the kind is evaluated by the policy
+func (p *Policy) Matches(kind string) bool {
+	kinds := SplitList(p.Kinds)
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitList splits the comma separated list, empty items are ignored
+func SplitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,188 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/manifest"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/policy/engine"
+	policymanager "github.com/horizoncd/horizon/pkg/policy/manager"
+	"github.com/horizoncd/horizon/pkg/policy/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+)
+
+// CheckRunName is the name of the check run reporting the policies evaluated on a pipelinerun
+const CheckRunName = "policy"
+
+type Service interface {
+	// Evaluate evaluates the policies bound to the environment and groups of the cluster on the objects
+	// rendered from its chart with the values at the gitops commit. It returns nil if no policy is bound.
+	// Objects which fail to be rendered or evaluated are reported as violations of the policies.
+	Evaluate(ctx context.Context, application *appmodels.Application, cluster *clustermodels.Cluster,
+		tr *trmodels.TemplateRelease, commit string) (*Report, error)
+}
+
+// Violation is an object of the cluster that violates a policy
+type Violation struct {
+	Policy  string `json:"policy"`
+	Mode    string `json:"mode"`
+	Kind    string `json:"kind,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report is the result of the policies evaluated on a cluster
+type Report struct {
+	Policies   []string     `json:"policies"`
+	Violations []*Violation `json:"violations"`
+}
+
+// Enforced returns whether any violation is of the policies in enforce mode
+func (r *Report) Enforced() bool {
+	for _, v := range r.Violations {
+		if v.Mode == models.ModeEnforce {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary summarizes the report in one line
+func (r *Report) Summary() string {
+	if len(r.Violations) == 0 {
+		return fmt.Sprintf("%d policies passed", len(r.Policies))
+	}
+	violated := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, v := range r.Violations {
+		if !seen[v.Policy] {
+			seen[v.Policy] = true
+			violated = append(violated, v.Policy)
+		}
+	}
+	return fmt.Sprintf("%d violations of policies: %s", len(r.Violations), strings.Join(violated, ", "))
+}
+
+// Details describes the violations in the report line by line
+func (r *Report) Details() string {
+	lines := []string{r.Summary()}
+	for _, v := range r.Violations {
+		object := ""
+		if v.Kind != "" {
+			object = fmt.Sprintf(" %s/%s", v.Kind, v.Name)
+		}
+		lines = append(lines, fmt.Sprintf("- [%s] %s%s: %s", v.Mode, v.Policy, object, v.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type service struct {
+	policyMgr policymanager.Manager
+	groupMgr  groupmanager.Manager
+	renderer  manifest.Renderer
+}
+
+var _ Service = (*service)(nil)
+
+func NewService(manager *managerparam.Manager, clusterGitRepo gitrepo.ClusterGitRepo,
+	templateRepo templaterepo.TemplateRepo) Service {
+	return &service{
+		policyMgr: manager.PolicyMgr,
+		groupMgr:  manager.GroupMgr,
+		renderer:  manifest.NewRenderer(clusterGitRepo, templateRepo),
+	}
+}
+
+func (s *service) Evaluate(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, tr *trmodels.TemplateRelease, commit string) (*Report, error) {
+	group, err := s.groupMgr.GetByID(ctx, application.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := s.policyMgr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+	bound := make([]*models.Policy, 0)
+	for _, policy := range policies {
+		if policy.Bound(cluster.EnvironmentName, groupIDs) {
+			bound = append(bound, policy)
+		}
+	}
+	if len(bound) == 0 {
+		return nil, nil
+	}
+
+	report := &Report{Policies: make([]string, 0, len(bound)), Violations: make([]*Violation, 0)}
+	for _, policy := range bound {
+		report.Policies = append(report.Policies, policy.Name)
+	}
+	violateAll := func(message string) *Report {
+		for _, policy := range bound {
+			report.Violations = append(report.Violations, &Violation{
+				Policy:  policy.Name,
+				Mode:    policy.Mode,
+				Message: message,
+			})
+		}
+		return report
+	}
+
+	objects, err := s.renderer.Render(ctx, application, cluster, tr, commit, nil)
+	if err != nil {
+		return violateAll(fmt.Sprintf("failed to render manifests: %v", err)), nil
+	}
+	for _, policy := range bound {
+		program, err := engine.Compile(policy.Expression)
+		if err != nil {
+			report.Violations = append(report.Violations, &Violation{
+				Policy:  policy.Name,
+				Mode:    policy.Mode,
+				Message: fmt.Sprintf("invalid expression: %v", err),
+			})
+			continue
+		}
+		for _, object := range objects {
+			if !policy.Matches(object.GetKind()) {
+				continue
+			}
+			message := policy.Message
+			complied, err := program.Evaluate(object.Object, cluster.Name, cluster.EnvironmentName)
+			if err != nil {
+				message = fmt.Sprintf("failed to evaluate: %v", err)
+			} else if complied {
+				continue
+			}
+			report.Violations = append(report.Violations, &Violation{
+				Policy:  policy.Name,
+				Mode:    policy.Mode,
+				Kind:    object.GetKind(),
+				Name:    object.GetName(),
+				Message: message,
+			})
+		}
+	}
+	return report, nil
+}
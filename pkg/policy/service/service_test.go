@@ -0,0 +1,117 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/policy/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+)
+
+func TestEvaluate(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&groupmodels.Group{}, &models.Policy{}); err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+	manager := managerparam.InitManager(db)
+
+	group := &groupmodels.Group{Name: "group", Path: "group", TraversalIDs: "1"}
+	assert.Nil(t, db.Save(group).Error)
+	application := &appmodels.Application{Name: "app", GroupID: group.ID}
+	cluster := &clustermodels.Cluster{Name: "app-test", EnvironmentName: "test"}
+	tr := &trmodels.TemplateRelease{ChartName: "javaapp", ChartVersion: "v1.0.0"}
+
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	clusterGitRepo.EXPECT().GetDeployValues(gomock.Any(), application.Name, cluster.Name, "commit").
+		Return([]map[string]interface{}{
+			{"javaapp": map[string]interface{}{"replicas": 1,
+				"env": map[string]interface{}{"namespace": "test-1"}}},
+			{"javaapp": map[string]interface{}{"replicas": 3}},
+		}, nil).AnyTimes()
+	templateRepo := repomock.NewMockTemplateRepo(mockCtl)
+	templateRepo.EXPECT().GetChart("javaapp", "v1.0.0", gomock.Any()).Return(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "javaapp", Version: "v1.0.0", APIVersion: chart.APIVersionV2},
+		Templates: []*chart.File{
+			{Name: "templates/deployment.yaml", Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+spec:
+  replicas: {{ .Values.replicas }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+`)},
+		},
+	}, nil).AnyTimes()
+
+	s := NewService(manager, clusterGitRepo, templateRepo)
+
+	// no policies bound
+	report, err := s.Evaluate(ctx, application, cluster, tr, "commit")
+	assert.Nil(t, err)
+	assert.Nil(t, report)
+	_, err = manager.PolicyMgr.Create(ctx, &models.Policy{Name: "online", Expression: "false",
+		Mode: models.ModeEnforce, Environments: "online"})
+	assert.Nil(t, err)
+	report, err = s.Evaluate(ctx, application, cluster, tr, "commit")
+	assert.Nil(t, err)
+	assert.Nil(t, report)
+
+	_, err = manager.PolicyMgr.Create(ctx, &models.Policy{Name: "replicas",
+		Expression: "object.spec.replicas <= 2", Message: "too many replicas",
+		Mode: models.ModeAudit, Kinds: "Deployment", GroupIDs: "1"})
+	assert.Nil(t, err)
+	_, err = manager.PolicyMgr.Create(ctx, &models.Policy{Name: "namespace",
+		Expression: `object.metadata.namespace == "test-1"`, Message: "wrong namespace",
+		Mode: models.ModeEnforce, Kinds: "Deployment"})
+	assert.Nil(t, err)
+	report, err = s.Evaluate(ctx, application, cluster, tr, "commit")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"replicas", "namespace"}, report.Policies)
+	assert.Equal(t, []*Violation{{Policy: "replicas", Mode: models.ModeAudit, Kind: "Deployment",
+		Name: "app-test", Message: "too many replicas"}}, report.Violations)
+	assert.False(t, report.Enforced())
+	assert.Equal(t, "1 violations of policies: replicas", report.Summary())
+
+	// objects which fail to be evaluated violate the policy
+	_, err = manager.PolicyMgr.Create(ctx, &models.Policy{Name: "image",
+		Expression: `object.spec.image == "nginx"`, Mode: models.ModeEnforce, Kinds: "Service"})
+	assert.Nil(t, err)
+	report, err = s.Evaluate(ctx, application, cluster, tr, "commit")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(report.Violations))
+	assert.Equal(t, "Service", report.Violations[1].Kind)
+	assert.True(t, report.Enforced())
+	t.Logf("%v", report.Details())
+}
@@ -206,6 +206,8 @@ func (d *pipelinerunDAO) GetByClusterID(ctx context.Context, clusterID uint,
 		switch k {
 		case corecommon.PipelineQueryByStatus:
 			sql = sql.Where("status in (?)", v)
+		case corecommon.PipelineQueryByConfigCommit:
+			sql = sql.Where("config_commit in (?)", v)
 		}
 	}
 
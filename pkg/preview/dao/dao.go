@@ -0,0 +1,152 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/preview/models"
+)
+
+type DAO interface {
+	CreateRule(ctx context.Context, rule *models.PreviewRule) (*models.PreviewRule, error)
+	UpdateRule(ctx context.Context, rule *models.PreviewRule) (*models.PreviewRule, error)
+	GetRule(ctx context.Context, id uint) (*models.PreviewRule, error)
+	ListRulesByApplicationID(ctx context.Context, applicationID uint) ([]*models.PreviewRule, error)
+	// ListEnabledRulesByGitURLs lists enabled rules whose base cluster's git url is one of gitURLs
+	ListEnabledRulesByGitURLs(ctx context.Context, gitURLs []string) ([]*models.PreviewRuleWithGitURL, error)
+	DeleteRule(ctx context.Context, id uint) error
+
+	CreateEnvironment(ctx context.Context,
+		environment *models.PreviewEnvironment) (*models.PreviewEnvironment, error)
+	GetEnvironment(ctx context.Context, ruleID uint, mergeRequestID int) (*models.PreviewEnvironment, error)
+	ListEnvironmentsByApplicationID(ctx context.Context,
+		applicationID uint) ([]*models.PreviewEnvironment, error)
+	DeleteEnvironment(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) CreateRule(ctx context.Context, rule *models.PreviewRule) (*models.PreviewRule, error) {
+	if err := d.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.PreviewRuleInDB, err.Error())
+	}
+	return rule, nil
+}
+
+func (d *dao) UpdateRule(ctx context.Context, rule *models.PreviewRule) (*models.PreviewRule, error) {
+	where := d.db.WithContext(ctx).Model(rule).Where("id = ?", rule.ID)
+	if err := where.Select("base_cluster_id", "environment", "region", "name_pattern",
+		"target_branch", "ttl_seconds", "enabled", "updated_by").
+		Updates(rule).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.PreviewRuleInDB, err.Error())
+	}
+	if err := where.First(rule).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PreviewRuleInDB, err.Error())
+	}
+	return rule, nil
+}
+
+func (d *dao) GetRule(ctx context.Context, id uint) (*models.PreviewRule, error) {
+	var rule models.PreviewRule
+	if err := d.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.PreviewRuleInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.PreviewRuleInDB, err.Error())
+	}
+	return &rule, nil
+}
+
+func (d *dao) ListRulesByApplicationID(ctx context.Context, applicationID uint) ([]*models.PreviewRule, error) {
+	var rules []*models.PreviewRule
+	if err := d.db.WithContext(ctx).Where("application_id = ?", applicationID).
+		Order("id").Find(&rules).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.PreviewRuleInDB, err.Error())
+	}
+	return rules, nil
+}
+
+func (d *dao) ListEnabledRulesByGitURLs(ctx context.Context,
+	gitURLs []string) ([]*models.PreviewRuleWithGitURL, error) {
+	var rules []*models.PreviewRuleWithGitURL
+	if len(gitURLs) == 0 {
+		return rules, nil
+	}
+	if err := d.db.WithContext(ctx).Table("tb_preview_rule r").
+		Select("r.*, c.git_url").
+		Joins("join tb_cluster c on c.id = r.base_cluster_id").
+		Where("c.git_url in ? and c.deleted_ts = 0", gitURLs).
+		Where("r.enabled = ? and r.deleted_ts = 0", true).
+		Order("r.id").Scan(&rules).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.PreviewRuleInDB, err.Error())
+	}
+	return rules, nil
+}
+
+func (d *dao) DeleteRule(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Delete(&models.PreviewRule{}, id).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.PreviewRuleInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) CreateEnvironment(ctx context.Context,
+	environment *models.PreviewEnvironment) (*models.PreviewEnvironment, error) {
+	if err := d.db.WithContext(ctx).Create(environment).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.PreviewEnvironmentInDB, err.Error())
+	}
+	return environment, nil
+}
+
+func (d *dao) GetEnvironment(ctx context.Context, ruleID uint,
+	mergeRequestID int) (*models.PreviewEnvironment, error) {
+	var environment models.PreviewEnvironment
+	if err := d.db.WithContext(ctx).Where("rule_id = ? and merge_request_id = ?", ruleID, mergeRequestID).
+		First(&environment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.PreviewEnvironmentInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.PreviewEnvironmentInDB, err.Error())
+	}
+	return &environment, nil
+}
+
+func (d *dao) ListEnvironmentsByApplicationID(ctx context.Context,
+	applicationID uint) ([]*models.PreviewEnvironment, error) {
+	var environments []*models.PreviewEnvironment
+	if err := d.db.WithContext(ctx).Where("application_id = ?", applicationID).
+		Order("id desc").Find(&environments).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.PreviewEnvironmentInDB, err.Error())
+	}
+	return environments, nil
+}
+
+func (d *dao) DeleteEnvironment(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Delete(&models.PreviewEnvironment{}, id).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.PreviewEnvironmentInDB, err.Error())
+	}
+	return nil
+}
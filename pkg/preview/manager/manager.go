@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/preview/dao"
+	"github.com/horizoncd/horizon/pkg/preview/models"
+)
+
+type Manager interface {
+	CreateRule(ctx context.Context, rule *models.PreviewRule) (*models.PreviewRule, error)
+	UpdateRule(ctx context.Context, rule *models.PreviewRule) (*models.PreviewRule, error)
+	GetRule(ctx context.Context, id uint) (*models.PreviewRule, error)
+	ListRulesByApplicationID(ctx context.Context, applicationID uint) ([]*models.PreviewRule, error)
+	// ListEnabledRulesByGitURLs lists enabled rules whose base cluster's git url is one of gitURLs
+	ListEnabledRulesByGitURLs(ctx context.Context, gitURLs []string) ([]*models.PreviewRuleWithGitURL, error)
+	DeleteRule(ctx context.Context, id uint) error
+
+	CreateEnvironment(ctx context.Context,
+		environment *models.PreviewEnvironment) (*models.PreviewEnvironment, error)
+	// GetEnvironment gets the preview environment created for the merge request by the rule
+	GetEnvironment(ctx context.Context, ruleID uint, mergeRequestID int) (*models.PreviewEnvironment, error)
+	ListEnvironmentsByApplicationID(ctx context.Context,
+		applicationID uint) ([]*models.PreviewEnvironment, error)
+	DeleteEnvironment(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) CreateRule(ctx context.Context, rule *models.PreviewRule) (*models.PreviewRule, error) {
+	return m.dao.CreateRule(ctx, rule)
+}
+
+func (m *manager) UpdateRule(ctx context.Context, rule *models.PreviewRule) (*models.PreviewRule, error) {
+	return m.dao.UpdateRule(ctx, rule)
+}
+
+func (m *manager) GetRule(ctx context.Context, id uint) (*models.PreviewRule, error) {
+	return m.dao.GetRule(ctx, id)
+}
+
+func (m *manager) ListRulesByApplicationID(ctx context.Context, applicationID uint) ([]*models.PreviewRule, error) {
+	return m.dao.ListRulesByApplicationID(ctx, applicationID)
+}
+
+func (m *manager) ListEnabledRulesByGitURLs(ctx context.Context,
+	gitURLs []string) ([]*models.PreviewRuleWithGitURL, error) {
+	return m.dao.ListEnabledRulesByGitURLs(ctx, gitURLs)
+}
+
+func (m *manager) DeleteRule(ctx context.Context, id uint) error {
+	return m.dao.DeleteRule(ctx, id)
+}
+
+func (m *manager) CreateEnvironment(ctx context.Context,
+	environment *models.PreviewEnvironment) (*models.PreviewEnvironment, error) {
+	return m.dao.CreateEnvironment(ctx, environment)
+}
+
+func (m *manager) GetEnvironment(ctx context.Context, ruleID uint,
+	mergeRequestID int) (*models.PreviewEnvironment, error) {
+	return m.dao.GetEnvironment(ctx, ruleID, mergeRequestID)
+}
+
+func (m *manager) ListEnvironmentsByApplicationID(ctx context.Context,
+	applicationID uint) ([]*models.PreviewEnvironment, error) {
+	return m.dao.ListEnvironmentsByApplicationID(ctx, applicationID)
+}
+
+func (m *manager) DeleteEnvironment(ctx context.Context, id uint) error {
+	return m.dao.DeleteEnvironment(ctx, id)
+}
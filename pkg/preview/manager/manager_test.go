@@ -0,0 +1,99 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/preview/models"
+)
+
+func TestPreview(t *testing.T) {
+	db, _ := orm.NewSqliteDB("file::memory:?cache=shared")
+	if err := db.AutoMigrate(&models.PreviewRule{}, &models.PreviewEnvironment{},
+		&clustermodels.Cluster{}); err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+	m := New(db)
+
+	clusters := []*clustermodels.Cluster{
+		{Name: "base1", ApplicationID: 1, GitURL: "https://github.com/horizoncd/horizon.git"},
+		{Name: "base2", ApplicationID: 2, GitURL: "https://github.com/horizoncd/other.git"},
+	}
+	for _, cluster := range clusters {
+		assert.Nil(t, db.Create(cluster).Error)
+	}
+	rules := make([]*models.PreviewRule, 0, len(clusters))
+	for _, cluster := range clusters {
+		rule, err := m.CreateRule(ctx, &models.PreviewRule{
+			ApplicationID: cluster.ApplicationID,
+			BaseClusterID: cluster.ID,
+			Environment:   "test",
+			Region:        "hz",
+			NamePattern:   "{application}-mr-{mr}",
+			Enabled:       true,
+		})
+		assert.Nil(t, err)
+		rules = append(rules, rule)
+	}
+
+	matched, err := m.ListEnabledRulesByGitURLs(ctx, []string{
+		"https://github.com/horizoncd/horizon.git",
+		"git@github.com:horizoncd/horizon.git",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, rules[0].ID, matched[0].ID)
+	assert.Equal(t, clusters[0].GitURL, matched[0].GitURL)
+
+	rules[0].Enabled = false
+	_, err = m.UpdateRule(ctx, rules[0])
+	assert.Nil(t, err)
+	matched, err = m.ListEnabledRulesByGitURLs(ctx, []string{"https://github.com/horizoncd/horizon.git"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(matched))
+
+	env, err := m.CreateEnvironment(ctx, &models.PreviewEnvironment{
+		RuleID:         rules[0].ID,
+		ApplicationID:  1,
+		ClusterID:      100,
+		MergeRequestID: 7,
+		SourceBranch:   "feature",
+	})
+	assert.Nil(t, err)
+	got, err := m.GetEnvironment(ctx, rules[0].ID, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, env.ClusterID, got.ClusterID)
+	envs, err := m.ListEnvironmentsByApplicationID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(envs))
+
+	assert.Nil(t, m.DeleteEnvironment(ctx, env.ID))
+	_, err = m.GetEnvironment(ctx, rules[0].ID, 7)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, m.DeleteRule(ctx, rules[1].ID))
+	_, err = m.GetRule(ctx, rules[1].ID)
+	assert.NotNil(t, err)
+	appRules, err := m.ListRulesByApplicationID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(appRules))
+}
@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// PreviewRule creates a preview cluster of the application for every merge request
+// of its repository, the cluster is cloned from the base cluster and deployed with
+// the source branch of the merge request
+type PreviewRule struct {
+	global.Model
+
+	ApplicationID uint
+	BaseClusterID uint
+	Environment   string
+	Region        string
+	// NamePattern is the name of preview clusters, in which {application}, {mr}
+	// and {branch} are replaced
+	NamePattern string
+	// TargetBranch is a glob pattern of the target branch of merge requests, empty matches all
+	TargetBranch string
+	// TTLSeconds is the expire seconds of preview clusters, 0 means never
+	TTLSeconds uint
+	Enabled    bool
+	CreatedBy  uint
+	UpdatedBy  uint
+}
+
+// PreviewRuleWithGitURL is a rule with the git url of its base cluster
+type PreviewRuleWithGitURL struct {
+	PreviewRule
+
+	GitURL string
+}
+
+// PreviewEnvironment is a preview cluster created for a merge request by a rule
+type PreviewEnvironment struct {
+	global.Model
+
+	RuleID          uint
+	ApplicationID   uint
+	ClusterID       uint
+	MergeRequestID  int
+	MergeRequestURL string
+	SourceBranch    string
+}
@@ -0,0 +1,182 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+type DAO interface {
+	Save(ctx context.Context, quota *models.Quota) (*models.Quota, error)
+	Get(ctx context.Context, resourceType string, resourceID uint, environment string) (*models.Quota, error)
+	List(ctx context.Context) ([]*models.Quota, error)
+	ListByResource(ctx context.Context, resourceType string, resourceID uint) ([]*models.Quota, error)
+	ListApplicable(ctx context.Context, environment string, applicationID uint,
+		groupIDs []uint) ([]*models.Quota, error)
+	Delete(ctx context.Context, resourceType string, resourceID uint, environment string) error
+	SaveClusterUsage(ctx context.Context, clusterID uint, usage *models.Usage) error
+	ListClusterUsages(ctx context.Context, clusterIDs []uint) (map[uint]*models.Usage, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Save(ctx context.Context, quota *models.Quota) (*models.Quota, error) {
+	existing, err := d.Get(ctx, quota.ResourceType, quota.ResourceID, quota.Environment)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		if err := d.db.WithContext(ctx).Create(quota).Error; err != nil {
+			return nil, herrors.NewErrInsertFailed(herrors.ResourceQuotaInDB, err.Error())
+		}
+		return quota, nil
+	}
+	where := d.db.WithContext(ctx).Model(existing).Where("id = ?", existing.ID)
+	if err := where.Updates(map[string]interface{}{
+		"cpu":        quota.CPU,
+		"memory":     quota.Memory,
+		"replicas":   quota.Replicas,
+		"clusters":   quota.Clusters,
+		"updated_by": quota.UpdatedBy,
+	}).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.ResourceQuotaInDB, err.Error())
+	}
+	if err := where.First(existing).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceQuotaInDB, err.Error())
+	}
+	return existing, nil
+}
+
+func (d *dao) Get(ctx context.Context, resourceType string, resourceID uint,
+	environment string) (*models.Quota, error) {
+	var quota models.Quota
+	if err := d.db.WithContext(ctx).
+		Where("resource_type = ? and resource_id = ? and environment = ?", resourceType, resourceID, environment).
+		First(&quota).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.ResourceQuotaInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ResourceQuotaInDB, err.Error())
+	}
+	return &quota, nil
+}
+
+func (d *dao) List(ctx context.Context) ([]*models.Quota, error) {
+	var quotas []*models.Quota
+	if err := d.db.WithContext(ctx).Order("resource_type, resource_id, environment").
+		Find(&quotas).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.ResourceQuotaInDB, err.Error())
+	}
+	return quotas, nil
+}
+
+func (d *dao) ListByResource(ctx context.Context, resourceType string,
+	resourceID uint) ([]*models.Quota, error) {
+	var quotas []*models.Quota
+	if err := d.db.WithContext(ctx).Where("resource_type = ? and resource_id = ?", resourceType, resourceID).
+		Order("environment").Find(&quotas).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.ResourceQuotaInDB, err.Error())
+	}
+	return quotas, nil
+}
+
+func (d *dao) ListApplicable(ctx context.Context, environment string, applicationID uint,
+	groupIDs []uint) ([]*models.Quota, error) {
+	var quotas []*models.Quota
+	statement := d.db.WithContext(ctx).Where("environment = ?", environment)
+	if len(groupIDs) > 0 {
+		statement = statement.Where(
+			"(resource_type = ? and resource_id = ?) or (resource_type = ? and resource_id in ?)",
+			common.ResourceApplication, applicationID, common.ResourceGroup, groupIDs)
+	} else {
+		statement = statement.Where("resource_type = ? and resource_id = ?",
+			common.ResourceApplication, applicationID)
+	}
+	if err := statement.Order("id").Find(&quotas).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.ResourceQuotaInDB, err.Error())
+	}
+	return quotas, nil
+}
+
+func (d *dao) Delete(ctx context.Context, resourceType string, resourceID uint, environment string) error {
+	if err := d.db.WithContext(ctx).
+		Where("resource_type = ? and resource_id = ? and environment = ?", resourceType, resourceID, environment).
+		Delete(&models.Quota{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.ResourceQuotaInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) SaveClusterUsage(ctx context.Context, clusterID uint, usage *models.Usage) error {
+	var existing models.ClusterUsage
+	err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := d.db.WithContext(ctx).Create(&models.ClusterUsage{
+			ClusterID: clusterID,
+			CPU:       usage.CPU,
+			Memory:    usage.Memory,
+			Replicas:  usage.Replicas,
+		}).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.ClusterResourceUsageInDB, err.Error())
+		}
+		return nil
+	}
+	if err != nil {
+		return herrors.NewErrGetFailed(herrors.ClusterResourceUsageInDB, err.Error())
+	}
+	if err := d.db.WithContext(ctx).Model(&existing).Updates(map[string]interface{}{
+		"cpu":      usage.CPU,
+		"memory":   usage.Memory,
+		"replicas": usage.Replicas,
+	}).Error; err != nil {
+		return herrors.NewErrUpdateFailed(herrors.ClusterResourceUsageInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListClusterUsages(ctx context.Context, clusterIDs []uint) (map[uint]*models.Usage, error) {
+	result := make(map[uint]*models.Usage)
+	if len(clusterIDs) == 0 {
+		return result, nil
+	}
+	var usages []*models.ClusterUsage
+	if err := d.db.WithContext(ctx).Where("cluster_id in ?", clusterIDs).
+		Find(&usages).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterResourceUsageInDB, err.Error())
+	}
+	for _, usage := range usages {
+		result[usage.ClusterID] = &models.Usage{
+			CPU:      usage.CPU,
+			Memory:   usage.Memory,
+			Replicas: usage.Replicas,
+			Clusters: 1,
+		}
+	}
+	return result, nil
+}
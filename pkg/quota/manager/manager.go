@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/quota/dao"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+type Manager interface {
+	// Save creates the quota of the resource in the environment, or updates it if it exists
+	Save(ctx context.Context, quota *models.Quota) (*models.Quota, error)
+	Get(ctx context.Context, resourceType string, resourceID uint, environment string) (*models.Quota, error)
+	List(ctx context.Context) ([]*models.Quota, error)
+	// ListByResource lists the quotas of the resource in all environments
+	ListByResource(ctx context.Context, resourceType string, resourceID uint) ([]*models.Quota, error)
+	// ListApplicable lists the quotas in the environment of the application and the groups
+	ListApplicable(ctx context.Context, environment string, applicationID uint,
+		groupIDs []uint) ([]*models.Quota, error)
+	Delete(ctx context.Context, resourceType string, resourceID uint, environment string) error
+	// SaveClusterUsage caches the resources requested by the config of the cluster
+	SaveClusterUsage(ctx context.Context, clusterID uint, usage *models.Usage) error
+	// ListClusterUsages returns the cached usages of the clusters by the cluster id, each counts a cluster
+	ListClusterUsages(ctx context.Context, clusterIDs []uint) (map[uint]*models.Usage, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Save(ctx context.Context, quota *models.Quota) (*models.Quota, error) {
+	return m.dao.Save(ctx, quota)
+}
+
+func (m *manager) Get(ctx context.Context, resourceType string, resourceID uint,
+	environment string) (*models.Quota, error) {
+	return m.dao.Get(ctx, resourceType, resourceID, environment)
+}
+
+func (m *manager) List(ctx context.Context) ([]*models.Quota, error) {
+	return m.dao.List(ctx)
+}
+
+func (m *manager) ListByResource(ctx context.Context, resourceType string,
+	resourceID uint) ([]*models.Quota, error) {
+	return m.dao.ListByResource(ctx, resourceType, resourceID)
+}
+
+func (m *manager) ListApplicable(ctx context.Context, environment string, applicationID uint,
+	groupIDs []uint) ([]*models.Quota, error) {
+	return m.dao.ListApplicable(ctx, environment, applicationID, groupIDs)
+}
+
+func (m *manager) Delete(ctx context.Context, resourceType string, resourceID uint, environment string) error {
+	return m.dao.Delete(ctx, resourceType, resourceID, environment)
+}
+
+func (m *manager) SaveClusterUsage(ctx context.Context, clusterID uint, usage *models.Usage) error {
+	return m.dao.SaveClusterUsage(ctx, clusterID, usage)
+}
+
+func (m *manager) ListClusterUsages(ctx context.Context, clusterIDs []uint) (map[uint]*models.Usage, error) {
+	return m.dao.ListClusterUsages(ctx, clusterIDs)
+}
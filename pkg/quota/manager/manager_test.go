@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+func TestQuotas(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Quota{}))
+	mgr := New(db)
+	ctx := context.Background()
+
+	_, err := mgr.Get(ctx, common.ResourceGroup, 1, "online")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	for _, quota := range []*models.Quota{
+		{ResourceType: common.ResourceGroup, ResourceID: 1, Environment: "online", CPU: 1000},
+		{ResourceType: common.ResourceGroup, ResourceID: 2, Environment: "online", Clusters: 2},
+		{ResourceType: common.ResourceGroup, ResourceID: 2, Environment: "test", Clusters: 5},
+		{ResourceType: common.ResourceApplication, ResourceID: 2, Environment: "online", Replicas: 4},
+		{ResourceType: common.ResourceApplication, ResourceID: 3, Environment: "online", Replicas: 4},
+	} {
+		_, err := mgr.Save(ctx, quota)
+		assert.Nil(t, err)
+	}
+	quota, err := mgr.Save(ctx, &models.Quota{ResourceType: common.ResourceGroup, ResourceID: 1,
+		Environment: "online", Memory: 2048, UpdatedBy: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), quota.CPU)
+	assert.Equal(t, int64(2048), quota.Memory)
+	assert.Equal(t, uint(2), quota.UpdatedBy)
+
+	quotas, err := mgr.ListApplicable(ctx, "online", 2, []uint{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(quotas))
+	quotas, err = mgr.ListApplicable(ctx, "online", 3, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(quotas))
+
+	quotas, err = mgr.ListByResource(ctx, common.ResourceGroup, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(quotas))
+	assert.Equal(t, "online", quotas[0].Environment)
+
+	assert.Nil(t, mgr.Delete(ctx, common.ResourceGroup, 2, "test"))
+	quotas, err = mgr.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(quotas))
+}
+
+func TestClusterUsages(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.ClusterUsage{}))
+	mgr := New(db)
+	ctx := context.Background()
+
+	usages, err := mgr.ListClusterUsages(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(usages))
+
+	assert.Nil(t, mgr.SaveClusterUsage(ctx, 1, &models.Usage{CPU: 1000, Memory: 1024, Replicas: 1}))
+	assert.Nil(t, mgr.SaveClusterUsage(ctx, 2, &models.Usage{CPU: 500, Memory: 512, Replicas: 1}))
+	assert.Nil(t, mgr.SaveClusterUsage(ctx, 1, &models.Usage{CPU: 2000, Memory: 2048, Replicas: 2}))
+
+	usages, err = mgr.ListClusterUsages(ctx, []uint{1, 3})
+	assert.Nil(t, err)
+	assert.Equal(t, map[uint]*models.Usage{
+		1: {CPU: 2000, Memory: 2048, Replicas: 2, Clusters: 1},
+	}, usages)
+}
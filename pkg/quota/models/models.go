@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// Quota limits the resources requested by the clusters of a group, including its subgroups,
+// or an application in an environment. CPU is in millicores and memory is in MiB over all replicas,
+// zero means unlimited.
+type Quota struct {
+	global.Model
+
+	ResourceType string
+	ResourceID   uint
+	Environment  string
+	CPU          int64
+	Memory       int64
+	Replicas     int64
+	Clusters     int64
+	CreatedBy    uint
+	UpdatedBy    uint
+}
+
+func (Quota) TableName() string {
+	return "tb_resource_quota"
+}
+
+// ClusterUsage caches the resources requested by the config of a cluster when the config is written,
+// so that the usages under quotas are summed without reading the configs of the clusters
+type ClusterUsage struct {
+	global.Model
+
+	ClusterID uint
+	CPU       int64
+	Memory    int64
+	Replicas  int64
+}
+
+func (ClusterUsage) TableName() string {
+	return "tb_cluster_resource_usage"
+}
+
+// Usage is the resources requested by clusters, cpu in millicores and memory in MiB over all replicas
+type Usage struct {
+	CPU      int64 `json:"cpu"`
+	Memory   int64 `json:"memory"`
+	Replicas int64 `json:"replicas"`
+	Clusters int64 `json:"clusters"`
+}
+
+func (u *Usage) Add(other *Usage) {
+	u.CPU += other.CPU
+	u.Memory += other.Memory
+	u.Replicas += other.Replicas
+	u.Clusters += other.Clusters
+}
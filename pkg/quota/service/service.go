@@ -0,0 +1,264 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/resource"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type Service interface {
+	// Check returns ErrQuotaExceeded with the remaining resources if the cluster with the config would exceed
+	// the quotas of its application and groups in its environment. The sections of the config, such as
+	// application and pipeline, are the first keys. Clusters are only checked by the resources more than
+	// they are counted, so that clusters over the quotas lowered can still be updated and deployed without
+	// requesting more, and freed clusters, which are not counted, are checked when they are deployed.
+	Check(ctx context.Context, cluster *clustermodels.Cluster, config map[string]interface{}) error
+	// Record caches the resources requested by the cluster with the config written to its git repo. The usages
+	// under quotas are summed from the cache, and the configs of the clusters not cached are read only once.
+	Record(ctx context.Context, cluster *clustermodels.Cluster, config map[string]interface{}) error
+	// Usage returns the resources requested by the clusters under the quota, computed from their configs
+	Usage(ctx context.Context, quota *models.Quota) (*models.Usage, error)
+}
+
+type service struct {
+	quotaMgr                quotamanager.Manager
+	groupMgr                groupmanager.Manager
+	applicationMgr          applicationmanager.Manager
+	clusterMgr              clustermanager.Manager
+	clusterGitRepo          gitrepo.ClusterGitRepo
+	templateResourcesGetter func() *templateresource.Config
+}
+
+var _ Service = (*service)(nil)
+
+func NewService(manager *managerparam.Manager, clusterGitRepo gitrepo.ClusterGitRepo,
+	templateResourcesGetter func() *templateresource.Config) Service {
+	return &service{
+		quotaMgr:                manager.QuotaMgr,
+		groupMgr:                manager.GroupMgr,
+		applicationMgr:          manager.ApplicationMgr,
+		clusterMgr:              manager.ClusterMgr,
+		clusterGitRepo:          clusterGitRepo,
+		templateResourcesGetter: templateResourcesGetter,
+	}
+}
+
+func (s *service) Check(ctx context.Context, cluster *clustermodels.Cluster,
+	config map[string]interface{}) error {
+	application, err := s.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	group, err := s.groupMgr.GetByID(ctx, application.GroupID)
+	if err != nil {
+		return err
+	}
+	quotas, err := s.quotaMgr.ListApplicable(ctx, cluster.EnvironmentName, application.ID,
+		groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs))
+	if err != nil || len(quotas) == 0 {
+		return err
+	}
+
+	requested := &models.Usage{Clusters: 1}
+	if t := s.templateResourcesGetter().Get(cluster.Template); t != nil {
+		resources, err := resource.Get(t, config)
+		if err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "failed to get resources of cluster: %v", err)
+		}
+		requested = toUsage(resources)
+	}
+
+	for _, quota := range quotas {
+		usages, err := s.listUsages(ctx, quota, hasResourceLimits(quota))
+		if err != nil {
+			return err
+		}
+		used, counted := &models.Usage{}, &models.Usage{}
+		for clusterID, usage := range usages {
+			if clusterID == cluster.ID {
+				counted = usage
+				continue
+			}
+			used.Add(usage)
+		}
+		exceeded := make([]string, 0)
+		check := func(name string, limit, used, requested, counted int64, unit string) {
+			if limit <= 0 || used+requested <= limit || requested <= counted {
+				return
+			}
+			remaining := limit - used
+			if remaining < 0 {
+				remaining = 0
+			}
+			exceeded = append(exceeded, fmt.Sprintf("%s requested %d%s, remaining %d%s",
+				name, requested, unit, remaining, unit))
+		}
+		check("cpu", quota.CPU, used.CPU, requested.CPU, counted.CPU, "m")
+		check("memory", quota.Memory, used.Memory, requested.Memory, counted.Memory, "Mi")
+		check("replicas", quota.Replicas, used.Replicas, requested.Replicas, counted.Replicas, "")
+		check("clusters", quota.Clusters, used.Clusters, requested.Clusters, counted.Clusters, "")
+		if len(exceeded) > 0 {
+			return perror.Wrapf(herrors.ErrQuotaExceeded, "quota of %s %d in environment %s is exceeded: %s",
+				quota.ResourceType, quota.ResourceID, quota.Environment, strings.Join(exceeded, ", "))
+		}
+	}
+	return nil
+}
+
+func (s *service) Record(ctx context.Context, cluster *clustermodels.Cluster,
+	config map[string]interface{}) error {
+	t := s.templateResourcesGetter().Get(cluster.Template)
+	if t == nil {
+		return nil
+	}
+	return s.quotaMgr.SaveClusterUsage(ctx, cluster.ID, usageOf(ctx, t, cluster, config))
+}
+
+func (s *service) Usage(ctx context.Context, quota *models.Quota) (*models.Usage, error) {
+	usages, err := s.listUsages(ctx, quota, true)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.Usage{}
+	for _, usage := range usages {
+		result.Add(usage)
+	}
+	return result, nil
+}
+
+// listUsages returns the usages of the clusters under the quota by the cluster id, freed clusters are not counted.
+// The configs of clusters are read only if the resources are required, otherwise only the clusters are counted.
+func (s *service) listUsages(ctx context.Context, quota *models.Quota,
+	withResources bool) (map[uint]*models.Usage, error) {
+	var applications []*appmodels.Application
+	switch quota.ResourceType {
+	case common.ResourceApplication:
+		application, err := s.applicationMgr.GetByID(ctx, quota.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		applications = []*appmodels.Application{application}
+	case common.ResourceGroup:
+		groups, err := s.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{quota.ResourceID})
+		if err != nil {
+			return nil, err
+		}
+		groupIDs := make([]uint, 0, len(groups))
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.ID)
+		}
+		if applications, err = s.applicationMgr.GetByGroupIDs(ctx, groupIDs); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "quota of %s is not supported", quota.ResourceType)
+	}
+
+	templateResources := s.templateResourcesGetter()
+	usages := make(map[uint]*models.Usage)
+	uncached := make(map[uint]*clustermodels.ClusterWithRegion)
+	applicationNames := make(map[uint]string, len(applications))
+	clusterIDs := make([]uint, 0)
+	for _, application := range applications {
+		applicationNames[application.ID] = application.Name
+		_, clusters, err := s.clusterMgr.ListByApplicationID(ctx, application.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters {
+			if cluster.EnvironmentName != quota.Environment || cluster.Status == common.ClusterStatusFreed {
+				continue
+			}
+			usages[cluster.ID] = &models.Usage{Clusters: 1}
+			if withResources && templateResources.Get(cluster.Template) != nil {
+				uncached[cluster.ID] = cluster
+				clusterIDs = append(clusterIDs, cluster.ID)
+			}
+		}
+	}
+	if len(clusterIDs) == 0 {
+		return usages, nil
+	}
+
+	cached, err := s.quotaMgr.ListClusterUsages(ctx, clusterIDs)
+	if err != nil {
+		return nil, err
+	}
+	for clusterID, usage := range cached {
+		if _, ok := uncached[clusterID]; ok {
+			usages[clusterID] = usage
+			delete(uncached, clusterID)
+		}
+	}
+	// the clusters written before the usages are cached are read from their git repos and cached
+	for _, cluster := range uncached {
+		files, err := s.clusterGitRepo.GetCluster(ctx, applicationNames[cluster.ApplicationID],
+			cluster.Name, cluster.Template)
+		if err != nil {
+			return nil, err
+		}
+		usage := usageOf(ctx, templateResources.Get(cluster.Template), cluster.Cluster, map[string]interface{}{
+			resource.SectionApplication: files.ApplicationJSONBlob,
+			resource.SectionPipeline:    files.PipelineJSONBlob,
+		})
+		if err := s.quotaMgr.SaveClusterUsage(ctx, cluster.ID, usage); err != nil {
+			log.Warningf(ctx, "failed to cache resources of cluster %s, err: %v", cluster.Name, err)
+		}
+		usages[cluster.ID] = usage
+	}
+	return usages, nil
+}
+
+// usageOf returns the resources requested by the cluster with the config, which are none if they can't be got
+func usageOf(ctx context.Context, t *templateresource.Template, cluster *clustermodels.Cluster,
+	config map[string]interface{}) *models.Usage {
+	resources, err := resource.Get(t, config)
+	if err != nil {
+		log.Warningf(ctx, "failed to get resources of cluster %s, err: %v", cluster.Name, err)
+		return &models.Usage{Clusters: 1}
+	}
+	return toUsage(resources)
+}
+
+func hasResourceLimits(quota *models.Quota) bool {
+	return quota.CPU > 0 || quota.Memory > 0 || quota.Replicas > 0
+}
+
+func toUsage(resources *resource.Resources) *models.Usage {
+	return &models.Usage{
+		CPU:      resources.CPU * resources.Replicas,
+		Memory:   resources.Memory * resources.Replicas,
+		Replicas: resources.Replicas,
+		Clusters: 1,
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/templateresource"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+)
+
+func TestService(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&regionmodels.Region{}, &templatemodels.Template{}, &models.Quota{}, &models.ClusterUsage{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	manager := managerparam.InitManager(db)
+
+	group := &groupmodels.Group{Name: "group", Path: "group", TraversalIDs: "1"}
+	assert.Nil(t, db.Save(group).Error)
+	application := &appmodels.Application{Name: "app", GroupID: group.ID}
+	assert.Nil(t, db.Save(application).Error)
+	running := &clustermodels.Cluster{Name: "running", ApplicationID: application.ID,
+		EnvironmentName: "test", Template: "javaapp"}
+	assert.Nil(t, db.Save(running).Error)
+	freed := &clustermodels.Cluster{Name: "freed", ApplicationID: application.ID,
+		EnvironmentName: "test", Template: "javaapp", Status: common.ClusterStatusFreed}
+	assert.Nil(t, db.Save(freed).Error)
+	online := &clustermodels.Cluster{Name: "online", ApplicationID: application.ID,
+		EnvironmentName: "online", Template: "javaapp"}
+	assert.Nil(t, db.Save(online).Error)
+
+	config := func(cpu, memory, replicas int64) map[string]interface{} {
+		return map[string]interface{}{
+			"application": map[string]interface{}{
+				"app": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"replicas": replicas,
+						"resource": map[string]interface{}{"cpu": cpu, "memory": memory},
+					},
+				},
+			},
+		}
+	}
+
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	// the config of the running cluster is read once and then cached
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), application.Name, running.Name, gomock.Any()).
+		Return(&gitrepo.ClusterFiles{
+			ApplicationJSONBlob: config(1000, 1024, 2)["application"].(map[string]interface{}),
+		}, nil).Times(1)
+
+	s := NewService(manager, clusterGitRepo, func() *templateresource.Config {
+		return &templateresource.Config{
+			Templates: []*templateresource.Template{{
+				Name:         "javaapp",
+				ReplicasPath: "application.app.spec.replicas",
+				CPUPath:      "application.app.spec.resource.cpu",
+				MemoryPath:   "application.app.spec.resource.memory",
+			}},
+		}
+	})
+
+	// no quotas
+	assert.Nil(t, s.Check(ctx, &clustermodels.Cluster{ApplicationID: application.ID,
+		EnvironmentName: "test", Template: "javaapp"}, config(100000, 100000, 100)))
+
+	groupQuota, err := manager.QuotaMgr.Save(ctx, &models.Quota{ResourceType: common.ResourceGroup,
+		ResourceID: group.ID, Environment: "test", CPU: 4000, Memory: 4096, Replicas: 4})
+	assert.Nil(t, err)
+	_, err = manager.QuotaMgr.Save(ctx, &models.Quota{ResourceType: common.ResourceApplication,
+		ResourceID: application.ID, Environment: "test", Clusters: 2})
+	assert.Nil(t, err)
+
+	usage, err := s.Usage(ctx, groupQuota)
+	assert.Nil(t, err)
+	assert.Equal(t, &models.Usage{CPU: 2000, Memory: 2048, Replicas: 2, Clusters: 1}, usage)
+
+	// a new cluster within the quotas
+	assert.Nil(t, s.Check(ctx, &clustermodels.Cluster{ApplicationID: application.ID,
+		EnvironmentName: "test", Template: "javaapp"}, config(1000, 1024, 2)))
+
+	// a new cluster exceeding the cpu of the group quota
+	err = s.Check(ctx, &clustermodels.Cluster{ApplicationID: application.ID,
+		EnvironmentName: "test", Template: "javaapp"}, config(1500, 1024, 2))
+	assert.Equal(t, herrors.ErrQuotaExceeded, perror.Cause(err))
+	assert.Contains(t, err.Error(), "cpu requested 3000m, remaining 2000m")
+
+	// the freed cluster is not counted, so it's checked when deployed
+	err = s.Check(ctx, freed, config(1500, 1024, 2))
+	assert.Equal(t, herrors.ErrQuotaExceeded, perror.Cause(err))
+
+	// the running cluster can be updated without requesting more
+	assert.Nil(t, s.Check(ctx, running, config(1000, 1024, 2)))
+	assert.Nil(t, s.Check(ctx, running, config(2000, 2048, 2)))
+	err = s.Check(ctx, running, config(2000, 2048, 3))
+	assert.Equal(t, herrors.ErrQuotaExceeded, perror.Cause(err))
+
+	// the usage is cached when the config is written
+	assert.Nil(t, s.Record(ctx, running, config(500, 512, 2)))
+	usage, err = s.Usage(ctx, groupQuota)
+	assert.Nil(t, err)
+	assert.Equal(t, &models.Usage{CPU: 1000, Memory: 1024, Replicas: 2, Clusters: 1}, usage)
+	assert.Nil(t, s.Record(ctx, running, config(1000, 1024, 2)))
+
+	// clusters in other environments are not limited
+	assert.Nil(t, s.Check(ctx, online, config(100000, 100000, 100)))
+
+	// the application quota limits the number of clusters
+	_, err = manager.QuotaMgr.Save(ctx, &models.Quota{ResourceType: common.ResourceApplication,
+		ResourceID: application.ID, Environment: "test", Clusters: 1})
+	assert.Nil(t, err)
+	err = s.Check(ctx, &clustermodels.Cluster{ApplicationID: application.ID,
+		EnvironmentName: "test", Template: "javaapp"}, config(100, 128, 1))
+	assert.Equal(t, herrors.ErrQuotaExceeded, perror.Cause(err))
+	assert.Contains(t, err.Error(), "clusters requested 1, remaining 0")
+}
@@ -0,0 +1,82 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/rightsizing/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, recommendation *models.Recommendation) (*models.Recommendation, error)
+	Update(ctx context.Context, recommendation *models.Recommendation) (*models.Recommendation, error)
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.Recommendation, error)
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context,
+	recommendation *models.Recommendation) (*models.Recommendation, error) {
+	if err := d.db.WithContext(ctx).Create(recommendation).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.RecommendationInDB, err.Error())
+	}
+	return recommendation, nil
+}
+
+func (d *dao) Update(ctx context.Context,
+	recommendation *models.Recommendation) (*models.Recommendation, error) {
+	where := d.db.WithContext(ctx).Model(recommendation).Where("id = ?", recommendation.ID)
+	if err := where.Select("cpu_usage", "memory_usage", "current_tier", "current_cpu", "current_memory",
+		"recommended_tier", "recommended_cpu", "recommended_memory", "replicas").
+		Updates(recommendation).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.RecommendationInDB, err.Error())
+	}
+	if err := where.First(recommendation).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.RecommendationInDB, err.Error())
+	}
+	return recommendation, nil
+}
+
+func (d *dao) GetByClusterID(ctx context.Context, clusterID uint) (*models.Recommendation, error) {
+	var recommendation models.Recommendation
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		First(&recommendation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.RecommendationInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.RecommendationInDB, err.Error())
+	}
+	return &recommendation, nil
+}
+
+func (d *dao) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Delete(&models.Recommendation{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.RecommendationInDB, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/rightsizing/dao"
+	"github.com/horizoncd/horizon/pkg/rightsizing/models"
+)
+
+type Manager interface {
+	// Save creates the recommendation of the cluster, or updates it if it exists
+	Save(ctx context.Context, recommendation *models.Recommendation) (*models.Recommendation, error)
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.Recommendation, error)
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Save(ctx context.Context,
+	recommendation *models.Recommendation) (*models.Recommendation, error) {
+	existing, err := m.dao.GetByClusterID(ctx, recommendation.ClusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		return m.dao.Create(ctx, recommendation)
+	}
+	recommendation.ID = existing.ID
+	return m.dao.Update(ctx, recommendation)
+}
+
+func (m *manager) GetByClusterID(ctx context.Context, clusterID uint) (*models.Recommendation, error) {
+	return m.dao.GetByClusterID(ctx, clusterID)
+}
+
+func (m *manager) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	return m.dao.DeleteByClusterID(ctx, clusterID)
+}
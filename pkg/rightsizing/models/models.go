@@ -0,0 +1,44 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// Recommendation is the requests recommended for a replica of a cluster by its usage history,
+// cpu in millicores and memory in MiB, tiers are empty if the template has no tiers
+type Recommendation struct {
+	global.Model
+
+	ClusterID uint
+	// CPUUsage and MemoryUsage are the percentiles of the usage of pods over the window
+	CPUUsage    int64
+	MemoryUsage int64
+
+	CurrentTier   string
+	CurrentCPU    int64
+	CurrentMemory int64
+
+	RecommendedTier   string
+	RecommendedCPU    int64
+	RecommendedMemory int64
+
+	Replicas int64
+}
+
+func (Recommendation) TableName() string {
+	return "tb_resource_recommendation"
+}
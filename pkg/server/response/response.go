@@ -19,6 +19,7 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -94,6 +95,40 @@ func AbortWithRPCError(c *gin.Context, rpcError rpcerror.RPCError) {
 	Abort(c, rpcError.HTTPCode, string(rpcError.ErrorCode), rpcError.ErrorMessage)
 }
 
+// AbortWithRPCErrorAndData aborts with the rpc error and the data describing the error
+func AbortWithRPCErrorAndData(c *gin.Context, rpcError rpcerror.RPCError, data interface{}) {
+	rid, err := requestid.FromContext(c)
+	if err != nil {
+		log.Errorf(c, "error to get requestID from context, err: %v", err)
+	}
+
+	c.JSON(rpcError.HTTPCode, &Response{
+		ErrorCode:    string(rpcError.ErrorCode),
+		ErrorMessage: rpcError.ErrorMessage,
+		Data:         data,
+		RequestID:    rid,
+	})
+	c.Abort()
+}
+
+// DataError is an error with the data describing it, such as the report of a failed lint
+type DataError interface {
+	error
+	Data() interface{}
+}
+
+// AbortIfDataError aborts with the rpc error and the data of err if err is caused by a DataError,
+// it returns whether the request is aborted
+func AbortIfDataError(c *gin.Context, rpcError rpcerror.RPCError, err error) bool {
+	dataErr, ok := perror.Cause(err).(DataError)
+	if !ok {
+		return false
+	}
+	log.Infof(c, "request failed with data: %v", dataErr)
+	AbortWithRPCErrorAndData(c, rpcError.WithErrMsg(dataErr.Error()), dataErr.Data())
+	return true
+}
+
 // AbortWithError TODO: remove this function after all error changed to rpcerror.RPCError
 func AbortWithError(c *gin.Context, err error) {
 	Abort(c, errors.Status(err), errors.Code(err), err.Error())
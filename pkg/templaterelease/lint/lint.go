@@ -0,0 +1,491 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	kyaml "sigs.k8s.io/yaml"
+
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
+	"github.com/horizoncd/horizon/pkg/templaterelease/render"
+	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/util/jsonschema"
+	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/generic"
+)
+
+// checks of the lint
+const (
+	CheckChart     = "chart"
+	CheckSchema    = "schema"
+	CheckOutput    = "output"
+	CheckRender    = "render"
+	CheckValues    = "values"
+	CheckWorkload  = "workload"
+	CheckMigration = "migration"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+const (
+	_pipelineSchemaPath      = "schema/pipeline.schema.json"
+	_applicationSchemaPath   = "schema/application.schema.json"
+	_pipelineUISchemaPath    = "schema/pipeline.ui.schema.json"
+	_applicationUISchemaPath = "schema/application.ui.schema.json"
+	_outputsPath             = "output/outputs.yaml"
+	_valuesSchemaPath        = "values.schema.json"
+	// example values to render the chart with
+	_exampleValuesPattern = "tests/*.values.yaml"
+)
+
+var _documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// Report is the result of linting a chart, the chart can not be published unless it is passed
+type Report struct {
+	Passed bool     `json:"passed"`
+	Issues []*Issue `json:"issues"`
+}
+
+type Issue struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Error is returned when a chart does not pass the lint
+type Error struct {
+	Report *Report
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Report.Issues))
+	for _, issue := range e.Report.Issues {
+		if issue.Severity == SeverityError {
+			messages = append(messages, issue.String())
+		}
+	}
+	return fmt.Sprintf("template release lint failed: %s", strings.Join(messages, "; "))
+}
+
+// Data returns the report as the data of the error responded
+func (e *Error) Data() interface{} {
+	return e.Report
+}
+
+func (i *Issue) String() string {
+	if i.File == "" {
+		return fmt.Sprintf("[%s] %s", i.Check, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", i.Check, i.File, i.Message)
+}
+
+type linter struct {
+	chart  *chart.Chart
+	issues []*Issue
+	// definitions are the kinds of workloads defined in the chart
+	definitions map[k8sschema.GroupKind]bool
+	// applicationSchema and valuesSchema validate the values to render the chart with
+	applicationSchema map[string]interface{}
+	valuesSchema      map[string]interface{}
+}
+
+// Lint lints the chart archive. It checks the chart, compiles the json schemas,
+// checks the output, the migrations and the workload definitions, validates the values
+// and renders the chart with the example values in tests/*.values.yaml.
+func Lint(archive []byte) *Report {
+	l := &linter{}
+	chrt, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		l.errorf(CheckChart, "", "failed to load chart archive: %v", err)
+		return l.report()
+	}
+	l.chart = chrt
+	if err := chrt.Validate(); err != nil {
+		l.errorf(CheckChart, "Chart.yaml", "%v", err)
+	}
+
+	l.lintSchemas()
+	l.lintValuesSchema()
+	l.lintOutput()
+	l.lintMigrations()
+	l.lintDefinitions()
+	l.lintRender()
+	return l.report()
+}
+
+func (l *linter) lintSchemas() {
+	for _, schemaPath := range []string{_applicationSchemaPath, _pipelineSchemaPath} {
+		uiSchemaPath := strings.TrimSuffix(schemaPath, ".schema.json") + ".ui.schema.json"
+		content := l.file(schemaPath)
+		if content == nil {
+			if schemaPath == _applicationSchemaPath {
+				l.errorf(CheckSchema, schemaPath, "file is missing")
+			}
+			if l.file(uiSchemaPath) != nil {
+				l.errorf(CheckSchema, uiSchemaPath, "json schema %s is missing", schemaPath)
+			}
+			continue
+		}
+
+		// schemas are templates rendered with params when they are fetched
+		rendered, err := renderSchema(content)
+		if err != nil {
+			l.errorf(CheckSchema, schemaPath, "failed to render: %v", err)
+			continue
+		}
+		var jsonSchema map[string]interface{}
+		if err := json.Unmarshal(rendered, &jsonSchema); err != nil {
+			l.errorf(CheckSchema, schemaPath, "invalid json: %v", err)
+			continue
+		}
+		if err := jsonschema.Compile(jsonSchema); err != nil {
+			l.errorf(CheckSchema, schemaPath, "invalid json schema: %v", err)
+			continue
+		}
+		if schemaPath == _applicationSchemaPath {
+			l.applicationSchema = jsonSchema
+		}
+
+		uiContent := l.file(uiSchemaPath)
+		if uiContent == nil {
+			continue
+		}
+		var uiSchema map[string]interface{}
+		if err := json.Unmarshal(uiContent, &uiSchema); err != nil {
+			l.errorf(CheckSchema, uiSchemaPath, "invalid json: %v", err)
+			continue
+		}
+		for _, message := range matchUISchema(uiSchema, jsonSchema, "") {
+			l.errorf(CheckSchema, uiSchemaPath, "%s", message)
+		}
+	}
+}
+
+// lintValuesSchema compiles values.schema.json of helm if the chart ships it,
+// and validates the default values against it
+func (l *linter) lintValuesSchema() {
+	if len(l.chart.Schema) == 0 {
+		return
+	}
+	var valuesSchema map[string]interface{}
+	if err := json.Unmarshal(l.chart.Schema, &valuesSchema); err != nil {
+		l.errorf(CheckSchema, _valuesSchemaPath, "invalid json: %v", err)
+		return
+	}
+	if err := jsonschema.Compile(valuesSchema); err != nil {
+		l.errorf(CheckSchema, _valuesSchemaPath, "invalid json schema: %v", err)
+		return
+	}
+	l.valuesSchema = valuesSchema
+	l.lintValues("values.yaml", map[string]interface{}{})
+}
+
+// lintValues validates the values of the application against the application schema,
+// and the values to render the chart with against values.schema.json
+func (l *linter) lintValues(file string, values map[string]interface{}) {
+	if application, ok := values[l.chart.Name()].(map[string]interface{}); ok && l.applicationSchema != nil {
+		if err := jsonschema.Validate(l.applicationSchema, application, false); err != nil {
+			l.errorf(CheckValues, file, "values of %s do not match %s: %v",
+				l.chart.Name(), _applicationSchemaPath, err)
+		}
+	}
+	if l.valuesSchema != nil {
+		// the values override the default values of the chart like helm
+		coalesced, err := chartutil.CoalesceValues(l.chart, values)
+		if err != nil {
+			l.errorf(CheckValues, file, "invalid values: %v", err)
+			return
+		}
+		if err := jsonschema.Validate(l.valuesSchema, coalesced.AsMap(), false); err != nil {
+			l.errorf(CheckValues, file, "values do not match %s: %v", _valuesSchemaPath, err)
+		}
+	}
+}
+
+func (l *linter) lintOutput() {
+	content := l.file(_outputsPath)
+	if content == nil {
+		l.errorf(CheckOutput, _outputsPath, "file is missing")
+		return
+	}
+	if _, err := template.New("").Funcs(sprig.HtmlFuncMap()).Parse(string(content)); err != nil {
+		l.errorf(CheckOutput, _outputsPath, "invalid template: %v", err)
+	}
+}
+
+// lintMigrations parses the migrations of values if the chart ships them
+func (l *linter) lintMigrations() {
+	content := l.file(migration.Path)
+	if content == nil {
+		return
+	}
+	if _, err := migration.Parse(content); err != nil {
+		l.errorf(CheckMigration, migration.Path, "%v", err)
+	}
+}
+
+// lintDefinitions parses the workload definitions if the chart ships them,
+// the workloads defined are supported once the chart is published
+func (l *linter) lintDefinitions() {
+	l.definitions = make(map[k8sschema.GroupKind]bool)
+	content := l.file(generic.Path)
+	if content == nil {
+		return
+	}
+	definitions, err := generic.Parse(content)
+	if err != nil {
+		l.errorf(CheckWorkload, generic.Path, "%v", err)
+		return
+	}
+	for _, definition := range definitions {
+		l.definitions[k8sschema.GroupKind{Group: definition.Group, Kind: definition.Kind}] = true
+	}
+}
+
+func (l *linter) lintRender() {
+	var examples []string
+	for _, file := range l.chart.Files {
+		if ok, _ := path.Match(_exampleValuesPattern, file.Name); ok {
+			examples = append(examples, file.Name)
+		}
+	}
+	if len(examples) == 0 {
+		l.warningf(CheckRender, "", "no example values matching %s, the chart is not rendered",
+			_exampleValuesPattern)
+		return
+	}
+	sort.Strings(examples)
+
+	for _, example := range examples {
+		var values map[string]interface{}
+		if err := kyaml.Unmarshal(l.file(example), &values); err != nil {
+			l.errorf(CheckRender, example, "invalid values: %v", err)
+			continue
+		}
+		l.lintValues(example, values)
+		manifests, err := render.Render(l.chart, values, &render.Release{Name: "lint", Namespace: "default"})
+		if err != nil {
+			l.errorf(CheckRender, example, "failed to render: %v", err)
+			continue
+		}
+		l.lintWorkloads(example, manifests)
+	}
+}
+
+// lintWorkloads checks whether horizon has abilities for the workloads in the manifests
+func (l *linter) lintWorkloads(example string, manifests map[string]string) {
+	names := make([]string, 0, len(manifests))
+	for name := range manifests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	supported := 0
+	for _, name := range names {
+		for _, document := range _documentSeparator.Split(manifests[name], -1) {
+			var object map[string]interface{}
+			if err := kyaml.Unmarshal([]byte(document), &object); err != nil {
+				l.errorf(CheckRender, name, "invalid yaml rendered with %s: %v", example, err)
+				continue
+			}
+			if len(object) == 0 {
+				continue
+			}
+			un := &unstructured.Unstructured{Object: object}
+			if !isWorkload(un) {
+				continue
+			}
+			gk := un.GroupVersionKind().GroupKind()
+			if _, err := workload.GetAbility(gk); err != nil && !l.definitions[gk] {
+				l.errorf(CheckWorkload, name, "workload %s %s rendered with %s is not supported by horizon",
+					gk.String(), un.GetName(), example)
+				continue
+			}
+			supported++
+		}
+	}
+	if supported == 0 {
+		l.errorf(CheckWorkload, example, "no workload supported by horizon is rendered")
+	}
+}
+
+func (l *linter) file(name string) []byte {
+	for _, file := range l.chart.Files {
+		if file.Name == name {
+			return file.Data
+		}
+	}
+	return nil
+}
+
+func (l *linter) errorf(check, file, format string, args ...interface{}) {
+	l.issues = append(l.issues, &Issue{
+		Check:    check,
+		Severity: SeverityError,
+		File:     file,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) warningf(check, file, format string, args ...interface{}) {
+	l.issues = append(l.issues, &Issue{
+		Check:    check,
+		Severity: SeverityWarning,
+		File:     file,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) report() *Report {
+	report := &Report{Passed: true, Issues: l.issues}
+	if report.Issues == nil {
+		report.Issues = []*Issue{}
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity == SeverityError {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+// isWorkload returns true for pods and the objects with pod templates
+func isWorkload(un *unstructured.Unstructured) bool {
+	if un.GetKind() == "Pod" {
+		return true
+	}
+	for _, fields := range [][]string{
+		{"spec", "template", "spec", "containers"},
+		{"spec", "jobTemplate", "spec", "template", "spec", "containers"},
+	} {
+		if _, found, _ := unstructured.NestedFieldNoCopy(un.Object, fields...); found {
+			return true
+		}
+	}
+	return false
+}
+
+func renderSchema(content []byte) ([]byte, error) {
+	tpl, err := template.New("").Funcs(sprig.TxtFuncMap()).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, map[string]string{
+		schema.ClusterIDKey:    "",
+		schema.ResourceTypeKey: "",
+	}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// matchUISchema returns the fields of the ui schema missing in the json schema
+func matchUISchema(uiSchema, jsonSchema map[string]interface{}, prefix string) []string {
+	properties := collectProperties(jsonSchema)
+	if len(properties) == 0 {
+		return nil
+	}
+	var messages []string
+	fields := make([]string, 0, len(uiSchema))
+	for field := range uiSchema {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if strings.HasPrefix(field, "ui:") {
+			continue
+		}
+		property, ok := properties[field].(map[string]interface{})
+		if !ok {
+			messages = append(messages, fmt.Sprintf("field %s%s is not defined in the json schema", prefix, field))
+			continue
+		}
+		if subUISchema, ok := uiSchema[field].(map[string]interface{}); ok {
+			if items, ok := property["items"].(map[string]interface{}); ok {
+				property = items
+				if subItems, ok := subUISchema["items"].(map[string]interface{}); ok {
+					subUISchema = subItems
+				}
+			}
+			if len(collectProperties(property)) > 0 {
+				messages = append(messages, matchUISchema(subUISchema, property, prefix+field+".")...)
+			}
+		}
+	}
+	if order, ok := uiSchema["ui:order"].([]interface{}); ok {
+		for _, field := range order {
+			name, _ := field.(string)
+			if name == "*" {
+				continue
+			}
+			if _, ok := properties[name]; !ok {
+				messages = append(messages,
+					fmt.Sprintf("field %s%s in ui:order is not defined in the json schema", prefix, name))
+			}
+		}
+	}
+	return messages
+}
+
+// collectProperties collects the properties of the schema, including the ones defined in
+// the subschemas of allOf, anyOf, oneOf and dependencies
+func collectProperties(jsonSchema map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	if ps, ok := jsonSchema["properties"].(map[string]interface{}); ok {
+		for name, property := range ps {
+			properties[name] = property
+		}
+	}
+	var subSchemas []interface{}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		if schemas, ok := jsonSchema[keyword].([]interface{}); ok {
+			subSchemas = append(subSchemas, schemas...)
+		}
+	}
+	if dependencies, ok := jsonSchema["dependencies"].(map[string]interface{}); ok {
+		for _, dependency := range dependencies {
+			subSchemas = append(subSchemas, dependency)
+		}
+	}
+	for _, subSchema := range subSchemas {
+		if subSchema, ok := subSchema.(map[string]interface{}); ok {
+			for name, property := range collectProperties(subSchema) {
+				if _, ok := properties[name]; !ok {
+					properties[name] = property
+				}
+			}
+		}
+	}
+	return properties
+}
@@ -0,0 +1,243 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/horizoncd/horizon/pkg/workload/deployment"
+)
+
+const (
+	_chartYAML = `apiVersion: v2
+name: demo
+version: 0.1.0
+`
+	_valuesYAML = `demo:
+  replicas: 1
+`
+	_helpersTpl = `{{- define "demo.name" -}}
+{{ .Release.Name }}-{{ .Chart.Name }}
+{{- end -}}
+`
+	_deploymentYAML = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "demo.name" . }}
+  labels:
+{{ toYaml .Values.labels | indent 4 }}
+spec:
+  replicas: {{ .Values.demo.replicas }}
+  template:
+    spec:
+      containers:
+        - name: app
+          image: {{ required "image is required" .Values.demo.image }}
+`
+	_daemonSetYAML = `{{- if .Values.daemon }}
+apiVersion: apps.kruise.io/v1alpha1
+kind: DaemonSet
+metadata:
+  name: daemon
+spec:
+  template:
+    spec:
+      containers:
+        - name: agent
+          image: agent
+{{- end }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "demo.name" . }}
+`
+	_schemaJSON = `{
+  "type": "object",
+  "properties": {
+    "app": {
+      "type": "object",
+      "properties": {
+        "replicas": {"type": "integer", "default": {{ if .clusterID }}2{{ else }}1{{ end }}}
+      }
+    }
+  }
+}`
+	_uiSchemaJSON = `{"ui:order": ["app"], "app": {"replicas": {"ui:widget": "updown"}}}`
+	_exampleYAML  = `demo:
+  image: demo:v1
+labels:
+  app: demo
+`
+)
+
+func chartFiles() map[string]string {
+	return map[string]string{
+		"Chart.yaml":                        _chartYAML,
+		"values.yaml":                       _valuesYAML,
+		"templates/_helpers.tpl":            _helpersTpl,
+		"templates/deployment.yaml":         _deploymentYAML,
+		"templates/daemonset.yaml":          _daemonSetYAML,
+		"schema/application.schema.json":    _schemaJSON,
+		"schema/application.ui.schema.json": _uiSchemaJSON,
+		"output/outputs.yaml":               "syncDomainName: {{ .Values.demo.image }}",
+		"tests/default.values.yaml":         _exampleYAML,
+	}
+}
+
+func archive(t *testing.T, files map[string]string) []byte {
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		assert.Nil(t, tw.WriteHeader(&tar.Header{
+			Name: "demo/" + name,
+			Mode: 0600,
+			Size: int64(len(content)),
+		}))
+		_, err := tw.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gw.Close())
+	return b.Bytes()
+}
+
+func TestLint(t *testing.T) {
+	report := Lint(archive(t, chartFiles()))
+	assert.True(t, report.Passed, "%+v", report.Issues)
+	assert.Equal(t, 0, len(report.Issues))
+
+	report = Lint([]byte("not an archive"))
+	assert.False(t, report.Passed)
+	assert.Equal(t, CheckChart, report.Issues[0].Check)
+
+	cases := []struct {
+		name  string
+		edit  func(files map[string]string)
+		check string
+		file  string
+	}{
+		{
+			name:  "broken json schema",
+			edit:  func(files map[string]string) { files["schema/application.schema.json"] = `{"type": "unknown"}` },
+			check: CheckSchema,
+			file:  "schema/application.schema.json",
+		},
+		{
+			name: "ui schema not matching",
+			edit: func(files map[string]string) {
+				files["schema/application.ui.schema.json"] = `{"app": {"replica": {"ui:widget": "updown"}}}`
+			},
+			check: CheckSchema,
+			file:  "schema/application.ui.schema.json",
+		},
+		{
+			name: "values not matching the application schema",
+			edit: func(files map[string]string) {
+				files["tests/default.values.yaml"] = "demo:\n  image: demo:v1\n  app:\n    replicas: two\n"
+			},
+			check: CheckValues,
+			file:  "tests/default.values.yaml",
+		},
+		{
+			name:  "default values not matching values.schema.json",
+			edit:  func(files map[string]string) { files["values.schema.json"] = `{"required": ["labels"]}` },
+			check: CheckValues,
+			file:  "values.yaml",
+		},
+		{
+			name:  "missing outputs",
+			edit:  func(files map[string]string) { delete(files, "output/outputs.yaml") },
+			check: CheckOutput,
+			file:  "output/outputs.yaml",
+		},
+		{
+			name: "invalid migrations",
+			edit: func(files map[string]string) {
+				files["migrations/migrations.yaml"] = "migrations:\n- from: v1.0.0\n  to: v1.1.0\n" +
+					"  rules:\n  - op: rename\n    path: app.cpu\n"
+			},
+			check: CheckMigration,
+			file:  "migrations/migrations.yaml",
+		},
+		{
+			name: "invalid workload definitions",
+			edit: func(files map[string]string) {
+				files["workloads/definitions.yaml"] = "definitions:\n- group: apps.kruise.io\n  version: v1alpha1\n" +
+					"  kind: DaemonSet\n  resource: daemonsets\n"
+			},
+			check: CheckWorkload,
+			file:  "workloads/definitions.yaml",
+		},
+		{
+			name:  "failing to render",
+			edit:  func(files map[string]string) { files["tests/default.values.yaml"] = "labels: {}" },
+			check: CheckRender,
+			file:  "tests/default.values.yaml",
+		},
+		{
+			name: "unsupported workload",
+			edit: func(files map[string]string) {
+				files["tests/daemon.values.yaml"] = _exampleYAML + "daemon: true\n"
+			},
+			check: CheckWorkload,
+			file:  "demo/templates/daemonset.yaml",
+		},
+		{
+			name:  "no workload",
+			edit:  func(files map[string]string) { delete(files, "templates/deployment.yaml") },
+			check: CheckWorkload,
+			file:  "tests/default.values.yaml",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			files := chartFiles()
+			c.edit(files)
+			report := Lint(archive(t, files))
+			assert.False(t, report.Passed)
+			assert.Equal(t, 1, len(report.Issues), "%+v", report.Issues)
+			assert.Equal(t, c.check, report.Issues[0].Check)
+			assert.Equal(t, c.file, report.Issues[0].File)
+			assert.Equal(t, SeverityError, report.Issues[0].Severity)
+		})
+	}
+
+	// charts without example values are not rendered
+	files := chartFiles()
+	delete(files, "tests/default.values.yaml")
+	report = Lint(archive(t, files))
+	assert.True(t, report.Passed)
+	assert.Equal(t, SeverityWarning, report.Issues[0].Severity)
+
+	// workloads defined in the chart are supported
+	files = chartFiles()
+	files["tests/daemon.values.yaml"] = _exampleYAML + "daemon: true\n"
+	files["workloads/definitions.yaml"] = `definitions:
+- group: apps.kruise.io
+  version: v1alpha1
+  kind: DaemonSet
+  resource: daemonsets
+  podSelector: '{.spec.selector.matchLabels}'
+`
+	report = Lint(archive(t, files))
+	assert.True(t, report.Passed, "%+v", report.Issues)
+}
@@ -68,7 +68,8 @@ func (m *manager) ListByTemplateID(ctx context.Context, id uint) ([]*models.Temp
 func (m *manager) GetByTemplateNameAndRelease(ctx context.Context,
 	templateName, release string) (_ *models.TemplateRelease, err error) {
 	const op = "template release manager: get by template name and release"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	tr, err := m.dao.GetByTemplateNameAndRelease(ctx, templateName, release)
 	if err != nil {
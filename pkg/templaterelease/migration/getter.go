@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// Getter gets the migrations shipped in charts of template releases
+type Getter interface {
+	// GetMigrations gets the migrations of the template release, it returns empty if the chart has no migrations
+	GetMigrations(ctx context.Context, templateName, releaseName string) ([]*Migration, error)
+}
+
+type getter struct {
+	templateRepo       templaterepo.TemplateRepo
+	templateReleaseMgr manager.Manager
+}
+
+func NewGetter(repo templaterepo.TemplateRepo, m *managerparam.Manager) Getter {
+	return &getter{
+		templateRepo:       repo,
+		templateReleaseMgr: m.TemplateReleaseMgr,
+	}
+}
+
+func (g *getter) GetMigrations(ctx context.Context, templateName, releaseName string) ([]*Migration, error) {
+	const op = "template migration getter: getMigrations"
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
+
+	tr, err := g.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, templateName, releaseName)
+	if err != nil {
+		return nil, err
+	}
+	chart, err := g.templateRepo.GetChart(tr.ChartName, tr.ChartVersion, tr.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range chart.Files {
+		if file.Name == Path {
+			migrations, err := Parse(file.Data)
+			if err != nil {
+				return nil, perror.Wrapf(err, "invalid migrations of template %s release %s",
+					templateName, releaseName)
+			}
+			return migrations, nil
+		}
+	}
+	return nil, nil
+}
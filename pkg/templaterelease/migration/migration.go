@@ -0,0 +1,314 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+	kyaml "sigs.k8s.io/yaml"
+
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+)
+
+// sections of the values to migrate, they are the first keys of paths in rules
+const (
+	SectionApplication = "application"
+	SectionPipeline    = "pipeline"
+)
+
+// operations of rules
+const (
+	// OpMove moves the value of from to path, rename is an alias of move
+	OpMove   = "move"
+	OpRename = "rename"
+	// OpCopy copies the value of from to path
+	OpCopy = "copy"
+	// OpDelete deletes path
+	OpDelete = "delete"
+	// OpDefault sets value to path if path does not exist
+	OpDefault = "default"
+	// OpSet sets value or the result of expr to path
+	OpSet = "set"
+)
+
+// Path is the path of the migrations file in charts
+const Path = "migrations/migrations.yaml"
+
+const (
+	_exprTimeout   = time.Second
+	_maxExprOutput = 64 * 1024
+)
+
+type File struct {
+	Migrations []*Migration `json:"migrations"`
+}
+
+// Migration migrates the values of release From to the values of release To
+type Migration struct {
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Description string  `json:"description,omitempty"`
+	Rules       []*Rule `json:"rules"`
+}
+
+// Rule is a step of migrations. Path and From are json paths such as application.app.spec.cpu.
+// Expr is a go template rendered with the values, in which sprig functions except env, expandenv,
+// repeat, until and untilStep are available, such as {{ mul .application.app.spec.cpu 1000 }}. The result is parsed as yaml,
+// and the rule is skipped if the result is empty.
+type Rule struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+	Expr  string      `json:"expr,omitempty"`
+}
+
+// Parse parses and validates the migrations file
+func Parse(content []byte) ([]*Migration, error) {
+	var file File
+	if err := kyaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, err
+	}
+	froms := make(map[string]bool, len(file.Migrations))
+	for i, migration := range file.Migrations {
+		if migration == nil || migration.From == "" || migration.To == "" {
+			return nil, fmt.Errorf("from and to of migration %d cannot be empty", i)
+		}
+		if migration.From == migration.To {
+			return nil, fmt.Errorf("migration %d migrates release %s to itself", i, migration.From)
+		}
+		if froms[migration.From] {
+			return nil, fmt.Errorf("more than one migration from release %s", migration.From)
+		}
+		froms[migration.From] = true
+		for j, rule := range migration.Rules {
+			if err := rule.validate(); err != nil {
+				return nil, fmt.Errorf("rule %d of migration from %s to %s is invalid: %v",
+					j, migration.From, migration.To, err)
+			}
+		}
+	}
+	return file.Migrations, nil
+}
+
+func (r *Rule) validate() error {
+	if r == nil {
+		return fmt.Errorf("rule cannot be empty")
+	}
+	if _, err := parsePath(r.Path); err != nil {
+		return err
+	}
+	switch r.Op {
+	case OpMove, OpRename, OpCopy:
+		if _, err := parsePath(r.From); err != nil {
+			return err
+		}
+	case OpDelete:
+	case OpDefault:
+		if r.Value == nil {
+			return fmt.Errorf("value of %s cannot be empty", r.Op)
+		}
+	case OpSet:
+		if (r.Value == nil) == (r.Expr == "") {
+			return fmt.Errorf("exactly one of value and expr of %s is required", r.Op)
+		}
+		if r.Expr != "" {
+			if _, err := template.New(r.Path).Funcs(funcMap()).Parse(r.Expr); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown op %s", r.Op)
+	}
+	return nil
+}
+
+// Chain returns the migrations from release from to release to. The chain starts with the migration
+// from release from, an error is returned if release to cannot be reached by the migrations.
+func Chain(migrations []*Migration, from, to string) ([]*Migration, error) {
+	byFrom := make(map[string]*Migration, len(migrations))
+	for _, migration := range migrations {
+		byFrom[migration.From] = migration
+	}
+	chain := make([]*Migration, 0)
+	visited := map[string]bool{from: true}
+	current := from
+	for current != to {
+		migration, ok := byFrom[current]
+		if !ok {
+			return nil, fmt.Errorf("no migration from release %s to release %s", current, to)
+		}
+		if visited[migration.To] {
+			return nil, fmt.Errorf("migrations from release %s loop at release %s", from, migration.To)
+		}
+		visited[migration.To] = true
+		chain = append(chain, migration)
+		current = migration.To
+	}
+	return chain, nil
+}
+
+// Apply applies the migrations in order to the values, in which the sections are the first keys.
+// The values are not modified, the migrated values are returned.
+func Apply(values map[string]interface{}, migrations []*Migration) (map[string]interface{}, error) {
+	migrated, _ := deepCopy(values).(map[string]interface{})
+	if migrated == nil {
+		migrated = make(map[string]interface{})
+	}
+	for _, migration := range migrations {
+		for i, rule := range migration.Rules {
+			if err := rule.apply(migrated); err != nil {
+				return nil, fmt.Errorf("failed to apply rule %d of migration from %s to %s: %v",
+					i, migration.From, migration.To, err)
+			}
+		}
+	}
+	return migrated, nil
+}
+
+func (r *Rule) apply(values map[string]interface{}) error {
+	keys, err := parsePath(r.Path)
+	if err != nil {
+		return err
+	}
+	switch r.Op {
+	case OpMove, OpRename, OpCopy:
+		fromKeys, err := parsePath(r.From)
+		if err != nil {
+			return err
+		}
+		value, ok := jsonpath.Get(values, fromKeys)
+		if !ok {
+			return nil
+		}
+		if r.Op != OpCopy {
+			jsonpath.Delete(values, fromKeys)
+		}
+		return jsonpath.Set(values, keys, deepCopy(value))
+	case OpDelete:
+		jsonpath.Delete(values, keys)
+		return nil
+	case OpDefault:
+		if _, ok := jsonpath.Get(values, keys); ok {
+			return nil
+		}
+		return jsonpath.Set(values, keys, deepCopy(r.Value))
+	case OpSet:
+		if r.Expr == "" {
+			return jsonpath.Set(values, keys, deepCopy(r.Value))
+		}
+		value, ok, err := evaluate(r.Expr, values)
+		if err != nil || !ok {
+			return err
+		}
+		return jsonpath.Set(values, keys, value)
+	default:
+		return fmt.Errorf("unknown op %s", r.Op)
+	}
+}
+
+// evaluate renders the expression with the values and parses the result as yaml,
+// the rendering is limited by _exprTimeout and _maxExprOutput
+func evaluate(expr string, values map[string]interface{}) (interface{}, bool, error) {
+	tpl, err := template.New("expr").Option("missingkey=zero").Funcs(funcMap()).Parse(expr)
+	if err != nil {
+		return nil, false, err
+	}
+	buf := &limitedBuffer{limit: _maxExprOutput}
+	done := make(chan error, 1)
+	// the values are copied since the rendering goes on in background after timeout
+	data := deepCopy(values)
+	go func() {
+		done <- tpl.Execute(buf, data)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, false, err
+		}
+	case <-time.After(_exprTimeout):
+		return nil, false, fmt.Errorf("expr is not rendered in %v", _exprTimeout)
+	}
+	result := strings.TrimSpace(strings.ReplaceAll(buf.String(), "<no value>", ""))
+	if result == "" {
+		return nil, false, nil
+	}
+	var value interface{}
+	if err := kyaml.Unmarshal([]byte(result), &value); err != nil {
+		return nil, false, fmt.Errorf("invalid result %s of expr: %v", result, err)
+	}
+	return value, true, nil
+}
+
+// funcMap returns the sprig functions without the ones accessing the environment
+// and the ones generating unbounded output
+func funcMap() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	for _, name := range []string{"env", "expandenv", "repeat", "until", "untilStep"} {
+		delete(funcs, name)
+	}
+	return funcs
+}
+
+// limitedBuffer is a buffer failing the writes exceeding the limit
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("output of expr exceeds %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
+
+func parsePath(path string) ([]string, error) {
+	keys, err := jsonpath.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if keys[0] != SectionApplication && keys[0] != SectionPipeline {
+		return nil, fmt.Errorf("path %s must start with %s or %s", path, SectionApplication, SectionPipeline)
+	}
+	if len(keys) == 1 {
+		return nil, fmt.Errorf("path %s cannot be a section", path)
+	}
+	return keys, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
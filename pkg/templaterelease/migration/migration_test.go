@@ -0,0 +1,231 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	templatereleasemock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+)
+
+const _migrations = `
+migrations:
+- from: v1.0.0
+  to: v1.1.0
+  description: resources are moved out of spec
+  rules:
+  - op: rename
+    from: application.app.spec.cpu
+    path: application.app.resource.cpu
+  - op: move
+    from: application.app.spec.memory
+    path: application.app.resource.memory
+  - op: default
+    path: application.app.spec.replicas
+    value: 1
+- from: v1.1.0
+  to: v1.2.0
+  rules:
+  - op: set
+    path: application.app.resource.memory
+    expr: '{{ if .application.app.resource.memory }}{{ mul .application.app.resource.memory 1024 }}{{ end }}'
+  - op: copy
+    from: application.app.spec.replicas
+    path: pipeline.replicas
+  - op: delete
+    path: pipeline.legacy
+  - op: set
+    path: pipeline.buildxml
+    value: <project/>
+- from: v1.2.0
+  to: v1.3.0
+  rules:
+  - op: set
+    path: application.app.resource.memoryUnit
+    value: Mi
+`
+
+func TestParse(t *testing.T) {
+	migrations, err := Parse([]byte(_migrations))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(migrations))
+	assert.Equal(t, 3, len(migrations[0].Rules))
+
+	invalids := map[string]string{
+		"unknown field": "migrations:\n- from: v1\n  to: v2\n  step: []\n",
+		"empty from":    "migrations:\n- to: v2\n",
+		"to itself":     "migrations:\n- from: v1\n  to: v1\n",
+		"duplicate from": "migrations:\n- from: v1\n  to: v2\n" +
+			"- from: v1\n  to: v3\n",
+		"unknown op":      "migrations:\n- from: v1\n  to: v2\n  rules:\n  - op: patch\n    path: application.a\n",
+		"unknown section": "migrations:\n- from: v1\n  to: v2\n  rules:\n  - op: delete\n    path: env.a\n",
+		"section path":    "migrations:\n- from: v1\n  to: v2\n  rules:\n  - op: delete\n    path: pipeline\n",
+		"move without from": "migrations:\n- from: v1\n  to: v2\n  rules:\n" +
+			"  - op: move\n    path: application.a\n",
+		"default without value": "migrations:\n- from: v1\n  to: v2\n  rules:\n" +
+			"  - op: default\n    path: application.a\n",
+		"set with both": "migrations:\n- from: v1\n  to: v2\n  rules:\n" +
+			"  - op: set\n    path: application.a\n    value: 1\n    expr: '2'\n",
+		"invalid expr": "migrations:\n- from: v1\n  to: v2\n  rules:\n" +
+			"  - op: set\n    path: application.a\n    expr: '{{ .a'\n",
+		"env in expr": "migrations:\n- from: v1\n  to: v2\n  rules:\n" +
+			"  - op: set\n    path: application.a\n    expr: '{{ env \"HOME\" }}'\n",
+		"repeat in expr": "migrations:\n- from: v1\n  to: v2\n  rules:\n" +
+			"  - op: set\n    path: application.a\n    expr: '{{ repeat 10 \"a\" }}'\n",
+		"until in expr": "migrations:\n- from: v1\n  to: v2\n  rules:\n" +
+			"  - op: set\n    path: application.a\n    expr: '{{ until 10 }}'\n",
+	}
+	for name, content := range invalids {
+		_, err := Parse([]byte(content))
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestChain(t *testing.T) {
+	migrations, err := Parse([]byte(_migrations))
+	assert.Nil(t, err)
+
+	chain, err := Chain(migrations, "v1.0.0", "v1.2.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(chain))
+	assert.Equal(t, "v1.1.0", chain[1].From)
+
+	chain, err = Chain(migrations, "v1.1.0", "v1.3.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(chain))
+
+	chain, err = Chain(migrations, "v1.3.0", "v1.3.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(chain))
+
+	// releases without migrations cannot be migrated
+	_, err = Chain(migrations, "v0.9.0", "v1.3.0")
+	assert.NotNil(t, err)
+
+	// downgrading cannot be migrated
+	_, err = Chain(migrations, "v1.3.0", "v1.0.0")
+	assert.NotNil(t, err)
+
+	// the chain cannot stop before release to
+	_, err = Chain(migrations, "v1.0.0", "v2.0.0")
+	assert.NotNil(t, err)
+
+	loop := append(migrations, &Migration{From: "v1.3.0", To: "v1.1.0"})
+	_, err = Chain(loop, "v1.1.0", "v2.0.0")
+	assert.NotNil(t, err)
+}
+
+func TestApply(t *testing.T) {
+	migrations, err := Parse([]byte(_migrations))
+	assert.Nil(t, err)
+	chain, err := Chain(migrations, "v1.0.0", "v1.2.0")
+	assert.Nil(t, err)
+
+	values := map[string]interface{}{
+		"application": map[string]interface{}{
+			"app": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"cpu":    500,
+					"memory": 2,
+				},
+			},
+		},
+		"pipeline": map[string]interface{}{
+			"legacy": true,
+		},
+	}
+	migrated, err := Apply(values, chain)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"application": map[string]interface{}{
+			"app": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": float64(1),
+				},
+				"resource": map[string]interface{}{
+					"cpu":    500,
+					"memory": float64(2048),
+				},
+			},
+		},
+		"pipeline": map[string]interface{}{
+			"replicas": float64(1),
+			"buildxml": "<project/>",
+		},
+	}, migrated)
+	// values are not modified
+	assert.Equal(t, 500, values["application"].(map[string]interface{})["app"].(map[string]interface{})["spec"].(map[string]interface{})["cpu"])
+
+	// empty result of expr skips the rule
+	migrated, err = Apply(map[string]interface{}{
+		"application": map[string]interface{}{"app": map[string]interface{}{"resource": map[string]interface{}{}}},
+	}, chain[1:])
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"resource": map[string]interface{}{}},
+		migrated["application"].(map[string]interface{})["app"])
+
+	// exprs with too large output fail
+	_, err = Apply(map[string]interface{}{"application": map[string]interface{}{"a": "aaaaaaaaaaaaaaaa"}},
+		[]*Migration{{From: "v1", To: "v2", Rules: []*Rule{{Op: OpSet, Path: "application.b",
+			Expr: "{{ range $i := list 1 2 3 4 5 6 7 8 9 10 }}{{ range $j := list 1 2 3 4 5 6 7 8 9 10 }}" +
+				"{{ range $k := list 1 2 3 4 5 6 7 8 9 10 }}{{ range $l := list 1 2 3 4 5 6 7 8 9 10 }}" +
+				"{{ $.application.a }}{{ end }}{{ end }}{{ end }}{{ end }}"}}}})
+	assert.NotNil(t, err)
+
+	// setting a path under a non-object fails
+	_, err = Apply(map[string]interface{}{"pipeline": map[string]interface{}{"replicas": "1"}},
+		[]*Migration{{From: "v1", To: "v2", Rules: []*Rule{{Op: OpSet, Path: "pipeline.replicas.count", Value: 1}}}})
+	assert.NotNil(t, err)
+}
+
+func TestGetMigrations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repoMock := repomock.NewMockTemplateRepo(mockCtrl)
+	templateReleaseMgr := templatereleasemock.NewMockManager(mockCtrl)
+	g := &getter{
+		templateRepo:       repoMock,
+		templateReleaseMgr: templateReleaseMgr,
+	}
+
+	tm := time.Now()
+	tr := &trmodels.TemplateRelease{ChartName: "javaapp", ChartVersion: "v1.2.0-abc", LastSyncAt: tm}
+	templateReleaseMgr.EXPECT().GetByTemplateNameAndRelease(gomock.Any(), "javaapp", "v1.2.0").
+		Return(tr, nil).Times(3)
+
+	repoMock.EXPECT().GetChart("javaapp", "v1.2.0-abc", tm).
+		Return(&chart.Chart{Files: []*chart.File{{Name: Path, Data: []byte(_migrations)}}}, nil)
+	migrations, err := g.GetMigrations(context.TODO(), "javaapp", "v1.2.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(migrations))
+
+	repoMock.EXPECT().GetChart("javaapp", "v1.2.0-abc", tm).Return(&chart.Chart{}, nil)
+	migrations, err = g.GetMigrations(context.TODO(), "javaapp", "v1.2.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(migrations))
+
+	repoMock.EXPECT().GetChart("javaapp", "v1.2.0-abc", tm).
+		Return(&chart.Chart{Files: []*chart.File{{Name: Path, Data: []byte("migrations: {}")}}}, nil)
+	_, err = g.GetMigrations(context.TODO(), "javaapp", "v1.2.0")
+	assert.NotNil(t, err)
+}
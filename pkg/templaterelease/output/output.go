@@ -59,7 +59,8 @@ func NewOutPutGetter(ctx context.Context, repo templaterepo.TemplateRepo, m *man
 func (g *getter) GetTemplateOutPut(ctx context.Context,
 	templateName, releaseName string) (string, error) {
 	const op = "template output getter: getTemplateOutPut"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	tr, err := g.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, templateName, releaseName)
 	if err != nil {
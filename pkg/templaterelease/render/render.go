@@ -0,0 +1,104 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+var _documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// Release is the release which the chart is rendered as
+type Release struct {
+	Name      string
+	Namespace string
+	// Revision is the revision of the release, 1 is used if it's 0
+	Revision int
+	// IsUpgrade is whether the release is upgraded, or it's installed
+	IsUpgrade bool
+	// Capabilities are the ones of the cluster to deploy the release,
+	// chartutil.DefaultCapabilities are used if it's nil
+	Capabilities *chartutil.Capabilities
+}
+
+// Render renders the templates of the chart and its dependencies with the values as the release by helm engine,
+// it returns the rendered manifests keyed by the paths of templates, partials and files except yaml are skipped
+func Render(chrt *chart.Chart, values map[string]interface{}, release *Release) (map[string]string, error) {
+	revision := release.Revision
+	if revision == 0 {
+		revision = 1
+	}
+	renderValues, err := chartutil.ToRenderValues(chrt, values, chartutil.ReleaseOptions{
+		Name:      release.Name,
+		Namespace: release.Namespace,
+		Revision:  revision,
+		IsInstall: !release.IsUpgrade,
+		IsUpgrade: release.IsUpgrade,
+	}, release.Capabilities)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(chrt, renderValues)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make(map[string]string, len(rendered))
+	for name, manifest := range rendered {
+		if ext := path.Ext(name); ext == ".yaml" || ext == ".yml" {
+			manifests[name] = manifest
+		}
+	}
+	return manifests, nil
+}
+
+// Objects parses the objects in the rendered manifests, ordered by the paths of templates
+func Objects(manifests map[string]string) ([]*unstructured.Unstructured, error) {
+	names := make([]string, 0, len(manifests))
+	for name := range manifests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var objects []*unstructured.Unstructured
+	for _, name := range names {
+		for _, document := range _documentSeparator.Split(manifests[name], -1) {
+			// numbers are decoded as int64 or float64 like unstructured objects
+			content, err := kyaml.YAMLToJSON([]byte(document))
+			if err != nil {
+				return nil, fmt.Errorf("invalid yaml in %s: %v", name, err)
+			}
+			var object map[string]interface{}
+			if err := utiljson.Unmarshal(content, &object); err != nil {
+				return nil, fmt.Errorf("invalid yaml in %s: %v", name, err)
+			}
+			if len(object) == 0 {
+				continue
+			}
+			objects = append(objects, &unstructured.Unstructured{Object: object})
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,190 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestRender(t *testing.T) {
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "javaapp", Version: "v1.0.0", APIVersion: chart.APIVersionV2},
+		Values:   map[string]interface{}{"replicas": 1, "image": "nginx"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{- define "name" -}}{{ .Release.Name }}{{- end -}}`)},
+			{Name: "templates/deployment.yaml", Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "name" . }}
+  namespace: {{ .Release.Namespace }}
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+        - name: app
+          image: {{ .Values.image }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "name" . }}
+`)},
+			{Name: "templates/NOTES.txt", Data: []byte(`notes`)},
+		},
+	}
+
+	manifests, err := Render(chrt, map[string]interface{}{"replicas": 2},
+		&Release{Name: "app-test", Namespace: "test"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(manifests))
+
+	objects, err := Objects(manifests)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(objects))
+	assert.Equal(t, "Deployment", objects[0].GetKind())
+	assert.Equal(t, "app-test", objects[0].GetName())
+	assert.Equal(t, "test", objects[0].GetNamespace())
+	assert.Equal(t, int64(2), objects[0].Object["spec"].(map[string]interface{})["replicas"])
+	assert.Equal(t, "Service", objects[1].GetKind())
+
+	_, err = Objects(map[string]string{"templates/invalid.yaml": "a: [b"})
+	assert.NotNil(t, err)
+}
+
+func TestRenderCapabilities(t *testing.T) {
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "javaapp", Version: "v1.0.0", APIVersion: chart.APIVersionV2},
+		Templates: []*chart.File{
+			{Name: "templates/pdb.yaml", Data: []byte(`{{- if .Capabilities.APIVersions.Has "policy/v1" }}
+apiVersion: policy/v1
+{{- else }}
+apiVersion: policy/v1beta1
+{{- end }}
+kind: PodDisruptionBudget
+metadata:
+  name: {{ .Release.Name }}-{{ .Capabilities.KubeVersion.Minor }}
+`)},
+		},
+	}
+
+	render := func(capabilities *chartutil.Capabilities) (string, string) {
+		manifests, err := Render(chrt, nil, &Release{Name: "app", Capabilities: capabilities})
+		assert.Nil(t, err)
+		objects, err := Objects(manifests)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(objects))
+		return objects[0].GetAPIVersion(), objects[0].GetName()
+	}
+
+	// the default capabilities of helm
+	apiVersion, name := render(nil)
+	assert.Equal(t, "policy/v1beta1", apiVersion)
+	assert.Equal(t, "app-"+chartutil.DefaultCapabilities.KubeVersion.Minor, name)
+
+	apiVersion, name = render(&chartutil.Capabilities{
+		KubeVersion: chartutil.KubeVersion{Version: "v1.25.3", Major: "1", Minor: "25"},
+		APIVersions: chartutil.VersionSet{"policy/v1"},
+	})
+	assert.Equal(t, "policy/v1", apiVersion)
+	assert.Equal(t, "app-25", name)
+}
+
+// TestRenderLikeHelm checks the behaviors which charts rely on are the same as helm install and upgrade
+func TestRenderLikeHelm(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sidecar", Version: "v1.0.0", APIVersion: chart.APIVersionV2},
+		Values:   map[string]interface{}{"image": "envoy", "port": 8080},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-{{ .Chart.Name }}
+data:
+  image: {{ .Values.image }}
+  port: {{ .Values.port | quote }}
+  env: {{ .Values.global.env }}
+  lookup: {{ lookup "v1" "Secret" "default" "app" | len | quote }}
+`)},
+		},
+	}
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "javaapp", Version: "v1.0.0", APIVersion: chart.APIVersionV2},
+		Values: map[string]interface{}{
+			"greeting": "hello {{ .Release.Name }}",
+			"global":   map[string]interface{}{"env": "dev"},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+data:
+  greeting: {{ tpl .Values.greeting . }}
+  revision: {{ .Release.Revision | quote }}
+  upgrade: {{ .Release.IsUpgrade | quote }}
+  service: {{ .Release.Service }}
+  required: {{ required "name is required" .Values.name }}
+`)},
+		},
+	}
+	chrt.AddDependency(sub)
+
+	values := map[string]interface{}{
+		"name":    "app",
+		"global":  map[string]interface{}{"env": "test"},
+		"sidecar": map[string]interface{}{"port": 9090},
+	}
+	manifests, err := Render(chrt, values, &Release{Name: "app", Namespace: "test", Revision: 3, IsUpgrade: true})
+	assert.Nil(t, err)
+	objects, err := Objects(manifests)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(objects))
+	// the objects are ordered by the paths of templates, the ones of the subchart are the first
+	assert.Equal(t, map[string]interface{}{
+		"greeting": "hello app",
+		"revision": "3",
+		"upgrade":  "true",
+		"service":  "Helm",
+		"required": "app",
+	}, objects[1].Object["data"])
+	// the values of the subchart are coalesced with its defaults and the globals of the parent chart
+	assert.Equal(t, "app-sidecar", objects[0].GetName())
+	assert.Equal(t, map[string]interface{}{
+		"image":  "envoy",
+		"port":   "9090",
+		"env":    "test",
+		"lookup": "0",
+	}, objects[0].Object["data"])
+	// the values are not modified
+	assert.Equal(t, map[string]interface{}{"port": 9090}, values["sidecar"])
+
+	// the release is installed at revision 1 by default
+	manifests, err = Render(chrt, values, &Release{Name: "app"})
+	assert.Nil(t, err)
+	objects, err = Objects(manifests)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", objects[1].Object["data"].(map[string]interface{})["revision"])
+	assert.Equal(t, "false", objects[1].Object["data"].(map[string]interface{})["upgrade"])
+
+	delete(values, "name")
+	_, err = Render(chrt, values, &Release{Name: "app"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "name is required")
+}
@@ -49,7 +49,8 @@ type getter struct {
 func (g *getter) GetTemplateSchema(ctx context.Context,
 	templateName, releaseName string, params map[string]string) (_ *schema.Schemas, err error) {
 	const op = "template schema getter: getTemplateSchema"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	t, err := g.templateMgr.GetByName(ctx, templateName)
 	tr, err := g.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, templateName, releaseName)
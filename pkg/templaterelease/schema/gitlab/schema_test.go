@@ -231,12 +231,12 @@ func TestNoTag(t *testing.T) {
 	templateReleaseMgr := trmock.NewMockManager(mockCtl)
 	templateMgr := tmock.NewMockManager(mockCtl)
 
-	templateMgr.EXPECT().GetByName(ctx, templateName).
+	templateMgr.EXPECT().GetByName(gomock.Any(), templateName).
 		Return(&tmodels.Template{
 			Name:       templateName,
 			Repository: templateGitlabProject,
 		}, nil)
-	templateReleaseMgr.EXPECT().GetByTemplateNameAndRelease(ctx, templateName,
+	templateReleaseMgr.EXPECT().GetByTemplateNameAndRelease(gomock.Any(), templateName,
 		releaseName).Return(&trmodels.TemplateRelease{
 		Model: global.Model{
 			ID: 1,
@@ -245,24 +245,24 @@ func TestNoTag(t *testing.T) {
 		ChartVersion: releaseName,
 	}, nil)
 
-	templateMgr.EXPECT().GetByName(ctx, templateName).
+	templateMgr.EXPECT().GetByName(gomock.Any(), templateName).
 		Return(&tmodels.Template{
 			Name:       templateName,
 			Repository: templateGitlabProject,
 		}, nil)
-	templateReleaseMgr.EXPECT().GetByTemplateNameAndRelease(ctx, templateName,
+	templateReleaseMgr.EXPECT().GetByTemplateNameAndRelease(gomock.Any(), templateName,
 		"release-not-exists").Return(nil, errors.E("", http.StatusNotFound))
 
 	jsonSchema := `{"type": "object"}`
 	var jsonSchemaMap map[string]interface{}
 	_ = json.Unmarshal([]byte(jsonSchema), &jsonSchemaMap)
-	gitlabLib.EXPECT().GetFile(ctx, templateGitlabProject, releaseName, _pipelineSchemaPath).Return(
+	gitlabLib.EXPECT().GetFile(gomock.Any(), templateGitlabProject, releaseName, _pipelineSchemaPath).Return(
 		[]byte(jsonSchema), nil)
-	gitlabLib.EXPECT().GetFile(ctx, templateGitlabProject, releaseName, _applicationSchemaPath).Return(
+	gitlabLib.EXPECT().GetFile(gomock.Any(), templateGitlabProject, releaseName, _applicationSchemaPath).Return(
 		[]byte(jsonSchema), nil)
-	gitlabLib.EXPECT().GetFile(ctx, templateGitlabProject, releaseName, _pipelineUISchemaPath).Return(
+	gitlabLib.EXPECT().GetFile(gomock.Any(), templateGitlabProject, releaseName, _pipelineUISchemaPath).Return(
 		[]byte(jsonSchema), nil)
-	gitlabLib.EXPECT().GetFile(ctx, templateGitlabProject, releaseName, _applicationUISchemaPath).Return(
+	gitlabLib.EXPECT().GetFile(gomock.Any(), templateGitlabProject, releaseName, _applicationUISchemaPath).Return(
 		[]byte(jsonSchema), nil)
 
 	g := &getter{
@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, recording *models.TerminalRecording) (*models.TerminalRecording, error)
+	Get(ctx context.Context, id uint) (*models.TerminalRecording, error)
+	// List lists recordings ordered by the start time desc
+	List(ctx context.Context, query *q.Query) ([]*models.TerminalRecording, int64, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context,
+	recording *models.TerminalRecording) (*models.TerminalRecording, error) {
+	if err := d.db.WithContext(ctx).Create(recording).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.TerminalRecordingInDB, err.Error())
+	}
+	return recording, nil
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.TerminalRecording, error) {
+	var recording models.TerminalRecording
+	if err := d.db.WithContext(ctx).First(&recording, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TerminalRecordingInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TerminalRecordingInDB, err.Error())
+	}
+	return &recording, nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.TerminalRecording, int64, error) {
+	var (
+		recordings []*models.TerminalRecording
+		total      int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.TerminalRecording{})
+	if query != nil {
+		for k, v := range query.Keywords {
+			switch k {
+			case common.TerminalRecordingQueryByUser:
+				statement = statement.Where("user_id = ?", v)
+			case common.TerminalRecordingQueryByCluster:
+				statement = statement.Where("cluster_id = ?", v)
+			case common.TerminalRecordingQueryByPod:
+				statement = statement.Where("pod = ?", v)
+			case common.TerminalRecordingQueryStartedAfter:
+				statement = statement.Where("started_at >= ?", v)
+			case common.TerminalRecordingQueryStartedBefore:
+				statement = statement.Where("started_at < ?", v)
+			}
+		}
+	} else {
+		query = &q.Query{}
+	}
+	if err := statement.Count(&total).Error; err != nil {
+		return nil, 0, herrors.NewErrListFailed(herrors.TerminalRecordingInDB, err.Error())
+	}
+	if err := statement.Order("started_at desc").Order("id desc").
+		Offset(query.Offset()).Limit(query.Limit()).
+		Find(&recordings).Error; err != nil {
+		return nil, 0, herrors.NewErrListFailed(herrors.TerminalRecordingInDB, err.Error())
+	}
+	return recordings, total, nil
+}
@@ -0,0 +1,53 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/dao"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, recording *models.TerminalRecording) (*models.TerminalRecording, error)
+	Get(ctx context.Context, id uint) (*models.TerminalRecording, error)
+	// List lists recordings ordered by the start time desc
+	List(ctx context.Context, query *q.Query) ([]*models.TerminalRecording, int64, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context,
+	recording *models.TerminalRecording) (*models.TerminalRecording, error) {
+	return m.dao.Create(ctx, recording)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.TerminalRecording, error) {
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) ([]*models.TerminalRecording, int64, error) {
+	return m.dao.List(ctx, query)
+}
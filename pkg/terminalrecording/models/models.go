@@ -0,0 +1,47 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+const (
+	// ModeShell is an interactive shell executed in the container
+	ModeShell = "shell"
+	// ModeAttach is a read-only attachment to the main process of the container
+	ModeAttach = "attach"
+)
+
+// TerminalRecording indexes the asciicast recording of a terminal session
+type TerminalRecording struct {
+	ID          uint
+	SessionID   string
+	UserID      uint
+	ClusterID   uint
+	Environment string
+	Pod         string
+	Container   string
+	Mode        string
+	// ObjectKey is the key of the recording in the blob store
+	ObjectKey string
+	// Size is the size of the recording in bytes
+	Size      int64
+	StartedAt time.Time
+	EndedAt   time.Time
+	// CloseReason tells why the session is closed, such as idle timeout
+	CloseReason string
+	CreatedAt   time.Time
+}
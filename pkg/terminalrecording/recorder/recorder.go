@@ -0,0 +1,158 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recorder records terminal sessions in asciicast v2 format,
+// see https://docs.asciinema.org/manual/asciicast/v2/
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultWidth  = 80
+	_defaultHeight = 24
+
+	eventOutput = "o"
+	eventInput  = "i"
+	eventResize = "r"
+)
+
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes the events of a session into a temporary file,
+// the file is returned by Close to be stored
+type Recorder struct {
+	sync.Mutex
+	file      *os.File
+	writer    *bufio.Writer
+	startedAt time.Time
+	closed    bool
+	err       error
+}
+
+// New creates a recorder and writes the header of the recording
+func New(title string, env map[string]string) (*Recorder, error) {
+	file, err := ioutil.TempFile("", "terminal-*.cast")
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		file:      file,
+		writer:    bufio.NewWriter(file),
+		startedAt: time.Now(),
+	}
+	content, err := json.Marshal(header{
+		Version:   2,
+		Width:     _defaultWidth,
+		Height:    _defaultHeight,
+		Timestamp: r.startedAt.Unix(),
+		Title:     title,
+		Env:       env,
+	})
+	if err != nil {
+		r.remove()
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(r.writer, "%s\n", content); err != nil {
+		r.remove()
+		return nil, err
+	}
+	return r, nil
+}
+
+// StartedAt returns the time when the recording starts
+func (r *Recorder) StartedAt() time.Time {
+	return r.startedAt
+}
+
+// Output records the output of the process
+func (r *Recorder) Output(data []byte) {
+	r.record(eventOutput, string(data))
+}
+
+// Input records the input of the user
+func (r *Recorder) Input(data []byte) {
+	r.record(eventInput, string(data))
+}
+
+// Resize records the resizing of the terminal
+func (r *Recorder) Resize(cols, rows uint16) {
+	r.record(eventResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+func (r *Recorder) record(eventType, data string) {
+	r.Lock()
+	defer r.Unlock()
+	if r.closed || r.err != nil {
+		return
+	}
+	elapsed := time.Since(r.startedAt).Seconds()
+	content, err := json.Marshal([]interface{}{elapsed, eventType, data})
+	if err != nil {
+		r.err = err
+		return
+	}
+	if _, err := fmt.Fprintf(r.writer, "%s\n", content); err != nil {
+		r.err = err
+	}
+}
+
+// Close stops recording and returns the recording with its size,
+// the caller should call the returned cleanup after storing the recording
+func (r *Recorder) Close() (recording io.ReadSeeker, size int64, cleanup func(), err error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.closed {
+		return nil, 0, nil, fmt.Errorf("recorder is closed")
+	}
+	r.closed = true
+	if r.err != nil {
+		r.remove()
+		return nil, 0, nil, r.err
+	}
+	if err := r.writer.Flush(); err != nil {
+		r.remove()
+		return nil, 0, nil, err
+	}
+	size, err = r.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		r.remove()
+		return nil, 0, nil, err
+	}
+	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+		r.remove()
+		return nil, 0, nil, err
+	}
+	return r.file, size, r.remove, nil
+}
+
+func (r *Recorder) remove() {
+	_ = r.file.Close()
+	_ = os.Remove(r.file.Name())
+}
@@ -0,0 +1,59 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	r, err := New("pod/container", map[string]string{"SHELL": "bash"})
+	assert.Nil(t, err)
+	r.Resize(120, 40)
+	r.Input([]byte("ls\r"))
+	r.Output([]byte("a.txt\r\n"))
+
+	recording, size, cleanup, err := r.Close()
+	assert.Nil(t, err)
+	defer cleanup()
+	content, err := ioutil.ReadAll(recording)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)), size)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Equal(t, 4, len(lines))
+	var h header
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &h))
+	assert.Equal(t, 2, h.Version)
+	assert.Equal(t, "pod/container", h.Title)
+
+	var event []interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, "r", event[1])
+	assert.Equal(t, "120x40", event[2])
+	assert.Nil(t, json.Unmarshal([]byte(lines[3]), &event))
+	assert.Equal(t, "o", event[1])
+	assert.Equal(t, "a.txt\r\n", event[2])
+
+	// events after closing are ignored
+	r.Output([]byte("ignored"))
+	_, _, _, err = r.Close()
+	assert.NotNil(t, err)
+}
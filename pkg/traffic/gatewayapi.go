@@ -0,0 +1,215 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traffic
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var GVRHTTPRoute = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1beta1",
+	Resource: "httproutes",
+}
+
+// HeaderRouteAnnotation records the header of the rule added to the HTTPRoute for the header route,
+// since rules of HTTPRoute have no names
+const HeaderRouteAnnotation = "cloudnative.music.netease.com/traffic-header-route"
+
+// gatewayAPI shifts traffic by the weights of backends of the first rule without header matches in the HTTPRoute
+type gatewayAPI struct{}
+
+func (*gatewayAPI) name() string {
+	return ProviderGatewayAPI
+}
+
+func (*gatewayAPI) gvrs() []schema.GroupVersionResource {
+	return withVersions(GVRHTTPRoute, "v1", "v1beta1")
+}
+
+func (g *gatewayAPI) read(_ context.Context, _ dynamic.Interface,
+	un *unstructured.Unstructured) (*Traffic, error) {
+	rules, _, err := unstructured.NestedSlice(un.Object, "spec", "rules")
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid rules of %s: %v", un.GetName(), err)
+	}
+	traffic := &Traffic{
+		Provider:     g.name(),
+		Resource:     un.GetName(),
+		Weights:      []Weight{},
+		Destinations: []string{},
+	}
+	header := un.GetAnnotations()[HeaderRouteAnnotation]
+	if index := defaultRule(rules); index >= 0 {
+		backends, _ := rules[index].(map[string]interface{})["backendRefs"].([]interface{})
+		for _, b := range backends {
+			backend, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// weight of a backend is 1 by default
+			weight, ok := toInt(backend["weight"])
+			if !ok {
+				weight = 1
+			}
+			name, _ := backend["name"].(string)
+			traffic.Weights = append(traffic.Weights, Weight{Destination: name, Weight: weight})
+			traffic.Destinations = append(traffic.Destinations, name)
+		}
+	}
+	if index := headerRule(rules, header); index >= 0 {
+		rule := rules[index].(map[string]interface{})
+		traffic.HeaderRoute = &HeaderRoute{
+			Header: header,
+			Value:  headerValue(rule, header),
+		}
+		if backends, ok := rule["backendRefs"].([]interface{}); ok && len(backends) > 0 {
+			if backend, ok := backends[0].(map[string]interface{}); ok {
+				traffic.HeaderRoute.Destination, _ = backend["name"].(string)
+			}
+		}
+	}
+	return traffic, nil
+}
+
+func (*gatewayAPI) write(un *unstructured.Unstructured, shift *Shift) error {
+	rules, _, err := unstructured.NestedSlice(un.Object, "spec", "rules")
+	if err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid rules of %s: %v", un.GetName(), err)
+	}
+	annotations := un.GetAnnotations()
+	header := annotations[HeaderRouteAnnotation]
+	index := defaultRule(rules)
+	if index < 0 {
+		return perror.Wrapf(herrors.ErrParamInvalid, "no rule without header matches in %s", un.GetName())
+	}
+	backends, _ := rules[index].(map[string]interface{})["backendRefs"].([]interface{})
+
+	if len(shift.Weights) > 0 {
+		weights := make(map[string]int, len(shift.Weights))
+		for _, weight := range shift.Weights {
+			weights[weight.Destination] = weight.Weight
+		}
+		for _, b := range backends {
+			if backend, ok := b.(map[string]interface{}); ok {
+				name, _ := backend["name"].(string)
+				backend["weight"] = int64(weights[name])
+			}
+		}
+	}
+
+	if shift.HeaderRoute != nil {
+		if i := headerRule(rules, header); i >= 0 {
+			rules = append(rules[:i], rules[i+1:]...)
+		}
+		delete(annotations, HeaderRouteAnnotation)
+		if shift.HeaderRoute.Header != "" {
+			var backendRef map[string]interface{}
+			for _, b := range backends {
+				if backend, ok := b.(map[string]interface{}); ok && backend["name"] == shift.HeaderRoute.Destination {
+					backendRef = runtime.DeepCopyJSON(backend)
+					delete(backendRef, "weight")
+				}
+			}
+			if backendRef == nil {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"destination %s of header route is not routed", shift.HeaderRoute.Destination)
+			}
+			rules = append([]interface{}{map[string]interface{}{
+				"matches": []interface{}{map[string]interface{}{
+					"headers": []interface{}{map[string]interface{}{
+						"type":  "Exact",
+						"name":  shift.HeaderRoute.Header,
+						"value": shift.HeaderRoute.Value,
+					}},
+				}},
+				"backendRefs": []interface{}{backendRef},
+			}}, rules...)
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[HeaderRouteAnnotation] = shift.HeaderRoute.Header
+		}
+		un.SetAnnotations(annotations)
+	}
+	return unstructured.SetNestedSlice(un.Object, rules, "spec", "rules")
+}
+
+// defaultRule returns the index of the first rule with backends and without header matches, -1 if not found
+func defaultRule(rules []interface{}) int {
+	for i, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if backends, ok := rule["backendRefs"].([]interface{}); !ok || len(backends) == 0 {
+			continue
+		}
+		if !hasHeaderMatch(rule, "") {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasHeaderMatch returns whether the rule matches the header, or any header if header is empty
+func hasHeaderMatch(rule map[string]interface{}, header string) bool {
+	matches, _ := rule["matches"].([]interface{})
+	for _, m := range matches {
+		match, _ := m.(map[string]interface{})
+		headers, _ := match["headers"].([]interface{})
+		for _, h := range headers {
+			if hm, ok := h.(map[string]interface{}); ok && (header == "" || hm["name"] == header) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headerRule returns the index of the rule of the header route, -1 if not found
+func headerRule(rules []interface{}, header string) int {
+	if header == "" {
+		return -1
+	}
+	for i, r := range rules {
+		if rule, ok := r.(map[string]interface{}); ok && hasHeaderMatch(rule, header) {
+			return i
+		}
+	}
+	return -1
+}
+
+func headerValue(rule map[string]interface{}, header string) string {
+	matches, _ := rule["matches"].([]interface{})
+	for _, m := range matches {
+		match, _ := m.(map[string]interface{})
+		headers, _ := match["headers"].([]interface{})
+		for _, h := range headers {
+			if hm, ok := h.(map[string]interface{}); ok && hm["name"] == header {
+				value, _ := hm["value"].(string)
+				return value
+			}
+		}
+	}
+	return ""
+}
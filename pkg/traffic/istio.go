@@ -0,0 +1,240 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traffic
+
+import (
+	"context"
+	"fmt"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	GVRVirtualService = schema.GroupVersionResource{
+		Group:    "networking.istio.io",
+		Version:  "v1beta1",
+		Resource: "virtualservices",
+	}
+	GVRDestinationRule = schema.GroupVersionResource{
+		Group:    "networking.istio.io",
+		Version:  "v1beta1",
+		Resource: "destinationrules",
+	}
+)
+
+// HeaderRouteName is the name of the http route of the VirtualService added for the header route
+const HeaderRouteName = "horizon-header-route"
+
+// istio shifts traffic by the weights of the http route without matches in the VirtualService,
+// subsets of the DestinationRule with the same name are destinations as well
+type istio struct{}
+
+func (*istio) name() string {
+	return ProviderIstio
+}
+
+func (*istio) gvrs() []schema.GroupVersionResource {
+	return withVersions(GVRVirtualService, "v1", "v1beta1", "v1alpha3")
+}
+
+func (i *istio) read(ctx context.Context, client dynamic.Interface,
+	un *unstructured.Unstructured) (*Traffic, error) {
+	https, _, err := unstructured.NestedSlice(un.Object, "spec", "http")
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid http routes of %s: %v", un.GetName(), err)
+	}
+	traffic := &Traffic{
+		Provider:     i.name(),
+		Resource:     un.GetName(),
+		Weights:      []Weight{},
+		Destinations: []string{},
+	}
+	if index := defaultHTTPRoute(https); index >= 0 {
+		routes, _ := https[index].(map[string]interface{})["route"].([]interface{})
+		for _, route := range routes {
+			r, ok := route.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			weight, ok := toInt(r["weight"])
+			if !ok && len(routes) == 1 {
+				weight = TotalWeight
+			}
+			destination := destinationOf(r)
+			traffic.Weights = append(traffic.Weights, Weight{Destination: destination, Weight: weight})
+			traffic.Destinations = append(traffic.Destinations, destination)
+		}
+	}
+	for _, h := range https {
+		if http, ok := h.(map[string]interface{}); ok && http["name"] == HeaderRouteName {
+			traffic.HeaderRoute = headerRouteOf(http)
+		}
+	}
+
+	// subsets of the destination rule can be routed to though they are not in the routes,
+	// it's of the same version as the virtual service
+	dr, err := client.Resource(GVRDestinationRule.GroupResource().WithVersion(un.GroupVersionKind().Version)).
+		Namespace(un.GetNamespace()).Get(ctx, un.GetName(), metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to get destinationrule %s/%s: %v", un.GetNamespace(), un.GetName(), err))
+	}
+	if err == nil {
+		subsets, _, _ := unstructured.NestedSlice(dr.Object, "spec", "subsets")
+		for _, s := range subsets {
+			subset, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok := subset["name"].(string); ok && !contains(traffic.Destinations, name) {
+				traffic.Destinations = append(traffic.Destinations, name)
+			}
+		}
+	}
+	return traffic, nil
+}
+
+func (*istio) write(un *unstructured.Unstructured, shift *Shift) error {
+	https, _, err := unstructured.NestedSlice(un.Object, "spec", "http")
+	if err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid http routes of %s: %v", un.GetName(), err)
+	}
+	index := defaultHTTPRoute(https)
+	if index < 0 {
+		return perror.Wrapf(herrors.ErrParamInvalid, "no http route without matches in %s", un.GetName())
+	}
+	http := https[index].(map[string]interface{})
+	routes, _ := http["route"].([]interface{})
+
+	if len(shift.Weights) > 0 {
+		weights := make(map[string]int, len(shift.Weights))
+		for _, weight := range shift.Weights {
+			weights[weight.Destination] = weight.Weight
+		}
+		for _, route := range routes {
+			r, ok := route.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			destination := destinationOf(r)
+			r["weight"] = int64(weights[destination])
+			delete(weights, destination)
+		}
+		// destinations left are subsets of the destination rule, routed by the host of the first route
+		for _, weight := range shift.Weights {
+			if _, ok := weights[weight.Destination]; !ok || len(routes) == 0 {
+				continue
+			}
+			host, _, _ := unstructured.NestedString(routes[0].(map[string]interface{}), "destination", "host")
+			routes = append(routes, map[string]interface{}{
+				"destination": map[string]interface{}{"host": host, "subset": weight.Destination},
+				"weight":      int64(weight.Weight),
+			})
+		}
+		http["route"] = routes
+	}
+
+	if shift.HeaderRoute != nil {
+		kept := make([]interface{}, 0, len(https))
+		for _, h := range https {
+			if r, ok := h.(map[string]interface{}); ok && r["name"] == HeaderRouteName {
+				continue
+			}
+			kept = append(kept, h)
+		}
+		https = kept
+		if shift.HeaderRoute.Header != "" {
+			var destination interface{}
+			for _, route := range routes {
+				if r, ok := route.(map[string]interface{}); ok && destinationOf(r) == shift.HeaderRoute.Destination {
+					destination = runtime.DeepCopyJSONValue(r["destination"])
+				}
+			}
+			if destination == nil {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"destination %s of header route is not routed", shift.HeaderRoute.Destination)
+			}
+			https = append([]interface{}{map[string]interface{}{
+				"name": HeaderRouteName,
+				"match": []interface{}{map[string]interface{}{
+					"headers": map[string]interface{}{
+						shift.HeaderRoute.Header: map[string]interface{}{"exact": shift.HeaderRoute.Value},
+					},
+				}},
+				"route": []interface{}{map[string]interface{}{"destination": destination}},
+			}}, https...)
+		}
+	}
+	return unstructured.SetNestedSlice(un.Object, https, "spec", "http")
+}
+
+// defaultHTTPRoute returns the index of the first http route without matches, -1 if not found
+func defaultHTTPRoute(https []interface{}) int {
+	for i, h := range https {
+		http, ok := h.(map[string]interface{})
+		if !ok || http["name"] == HeaderRouteName {
+			continue
+		}
+		if matches, ok := http["match"].([]interface{}); ok && len(matches) > 0 {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// destinationOf returns the subset of the destination, or the host if there is no subset
+func destinationOf(route map[string]interface{}) string {
+	if subset, _, _ := unstructured.NestedString(route, "destination", "subset"); subset != "" {
+		return subset
+	}
+	host, _, _ := unstructured.NestedString(route, "destination", "host")
+	return host
+}
+
+func headerRouteOf(http map[string]interface{}) *HeaderRoute {
+	headerRoute := &HeaderRoute{}
+	if matches, ok := http["match"].([]interface{}); ok && len(matches) > 0 {
+		if match, ok := matches[0].(map[string]interface{}); ok {
+			headers, _, _ := unstructured.NestedMap(match, "headers")
+			for header := range headers {
+				headerRoute.Header = header
+				headerRoute.Value, _, _ = unstructured.NestedString(headers, header, "exact")
+			}
+		}
+	}
+	if routes, ok := http["route"].([]interface{}); ok && len(routes) > 0 {
+		if r, ok := routes[0].(map[string]interface{}); ok {
+			headerRoute.Destination = destinationOf(r)
+		}
+	}
+	return headerRoute
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
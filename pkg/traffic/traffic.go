@@ -0,0 +1,220 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traffic
+
+import (
+	"context"
+	"fmt"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	ProviderIstio      = "istio"
+	ProviderGatewayAPI = "gatewayapi"
+
+	// TotalWeight is the sum of the weights to set
+	TotalWeight = 100
+)
+
+// GVRRollout is the resource of the argo rollout named after the cluster, whose release is shifted
+var GVRRollout = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "rollouts",
+}
+
+// Weight is the weight of traffic routed to the destination, which is the subset or the host of
+// an istio route destination, or the name of a backend of an HTTPRoute
+type Weight struct {
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+}
+
+// HeaderRoute routes the requests with the header to the destination, for testers to visit the canary
+type HeaderRoute struct {
+	Header      string `json:"header"`
+	Value       string `json:"value"`
+	Destination string `json:"destination"`
+}
+
+// Traffic is the traffic routing of a cluster
+type Traffic struct {
+	Provider string `json:"provider"`
+	// Resource is the name of the VirtualService or HTTPRoute, same as the cluster
+	Resource     string       `json:"resource"`
+	Weights      []Weight     `json:"weights"`
+	HeaderRoute  *HeaderRoute `json:"headerRoute,omitempty"`
+	Destinations []string     `json:"destinations"`
+}
+
+// Shift changes the traffic routing, weights are kept if Weights is empty,
+// the header route is kept if HeaderRoute is nil and removed if its header is empty
+type Shift struct {
+	Weights     []Weight     `json:"weights"`
+	HeaderRoute *HeaderRoute `json:"headerRoute"`
+}
+
+// provider reads and writes the traffic routing of a kind of route resource
+type provider interface {
+	name() string
+	// gvrs are the resources of all versions of the kind, the first served one is used
+	gvrs() []schema.GroupVersionResource
+	read(ctx context.Context, client dynamic.Interface, un *unstructured.Unstructured) (*Traffic, error)
+	write(un *unstructured.Unstructured, shift *Shift) error
+}
+
+var providers = []provider{&istio{}, &gatewayAPI{}}
+
+// Get returns the traffic routing of the route resource named name,
+// an ErrNotFound is returned if there is no route resource of any provider
+func Get(ctx context.Context, client dynamic.Interface, namespace, name string) (*Traffic, error) {
+	p, _, un, err := find(ctx, client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return p.read(ctx, client, un)
+}
+
+// Apply validates shift and updates the route resource named name. Traffic is only shifted while
+// the rollout named name is releasing, and not if the rollout routes the traffic by itself.
+func Apply(ctx context.Context, client dynamic.Interface, namespace, name string, shift *Shift) (*Traffic, error) {
+	if err := checkRollout(ctx, client, namespace, name); err != nil {
+		return nil, err
+	}
+	p, gvr, un, err := find(ctx, client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	current, err := p.read(ctx, client, un)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(current, shift); err != nil {
+		return nil, err
+	}
+	if err := p.write(un, shift); err != nil {
+		return nil, err
+	}
+	un, err = client.Resource(gvr).Namespace(namespace).Update(ctx, un, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to update %s %s/%s: %v", gvr.Resource, namespace, name, err))
+	}
+	return p.read(ctx, client, un)
+}
+
+// find finds the route resource named name, the versions of the resources not served by the region
+// are not found either, so the served version is found by trying them in order
+func find(ctx context.Context, client dynamic.Interface,
+	namespace, name string) (provider, schema.GroupVersionResource, *unstructured.Unstructured, error) {
+	for _, p := range providers {
+		for _, gvr := range p.gvrs() {
+			un, err := client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err == nil {
+				return p, gvr, un, nil
+			}
+			if !errors.IsNotFound(err) {
+				return nil, schema.GroupVersionResource{}, nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+					fmt.Sprintf("failed to get %s %s/%s: %v", gvr.Resource, namespace, name, err))
+			}
+		}
+	}
+	return nil, schema.GroupVersionResource{}, nil, herrors.NewErrNotFound(herrors.ResourceInK8S,
+		fmt.Sprintf("no traffic route of %s/%s", namespace, name))
+}
+
+// checkRollout checks that the rollout named name is releasing, and its canary strategy does not
+// route the traffic, otherwise the weights would be overwritten by the rollout
+func checkRollout(ctx context.Context, client dynamic.Interface, namespace, name string) error {
+	un, err := client.Resource(GVRRollout).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return perror.Wrapf(herrors.ErrParamInvalid, "no release of %s/%s is in progress", namespace, name)
+		}
+		return herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to get rollout %s/%s: %v", namespace, name, err))
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(un.Object,
+		"spec", "strategy", "canary", "trafficRouting"); found {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"traffic of %s/%s is routed by its rollout, promote the rollout instead", namespace, name)
+	}
+	stable, _, _ := unstructured.NestedString(un.Object, "status", "stableRS")
+	current, _, _ := unstructured.NestedString(un.Object, "status", "currentPodHash")
+	if current == "" || current == stable {
+		return perror.Wrapf(herrors.ErrParamInvalid, "no release of %s/%s is in progress", namespace, name)
+	}
+	return nil
+}
+
+// withVersions returns gvr of each version
+func withVersions(gvr schema.GroupVersionResource, versions ...string) []schema.GroupVersionResource {
+	gvrs := make([]schema.GroupVersionResource, 0, len(versions))
+	for _, version := range versions {
+		gvrs = append(gvrs, gvr.GroupResource().WithVersion(version))
+	}
+	return gvrs
+}
+
+func validate(current *Traffic, shift *Shift) error {
+	destinations := make(map[string]bool, len(current.Destinations))
+	for _, destination := range current.Destinations {
+		destinations[destination] = true
+	}
+	if len(shift.Weights) > 0 {
+		total := 0
+		for _, weight := range shift.Weights {
+			if !destinations[weight.Destination] {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"destination %s does not exist, available: %v", weight.Destination, current.Destinations)
+			}
+			if weight.Weight < 0 || weight.Weight > TotalWeight {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"weight of %s should be between 0 and %d", weight.Destination, TotalWeight)
+			}
+			total += weight.Weight
+		}
+		if total != TotalWeight {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"sum of weights should be %d, but got %d", TotalWeight, total)
+		}
+	}
+	if shift.HeaderRoute != nil && shift.HeaderRoute.Header != "" &&
+		!destinations[shift.HeaderRoute.Destination] {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"destination %s of header route does not exist, available: %v",
+			shift.HeaderRoute.Destination, current.Destinations)
+	}
+	return nil
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int64:
+		return int(n), true
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
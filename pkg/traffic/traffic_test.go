@@ -0,0 +1,223 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traffic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	kyaml "sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	_virtualService = `
+apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: demo
+  namespace: test
+spec:
+  hosts:
+  - demo
+  http:
+  - route:
+    - destination:
+        host: demo
+        subset: stable
+      weight: 100
+`
+	_destinationRule = `
+apiVersion: networking.istio.io/v1beta1
+kind: DestinationRule
+metadata:
+  name: demo
+  namespace: test
+spec:
+  host: demo
+  subsets:
+  - name: stable
+    labels:
+      version: stable
+  - name: canary
+    labels:
+      version: canary
+`
+	_httpRoute = `
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: demo
+  namespace: test
+spec:
+  parentRefs:
+  - name: gateway
+  rules:
+  - backendRefs:
+    - name: demo-stable
+      port: 80
+      weight: 100
+    - name: demo-canary
+      port: 80
+      weight: 0
+`
+)
+
+// rollout returns the rollout named demo, which is releasing if current differs from stable
+func rollout(trafficRouting bool, stable, current string) *unstructured.Unstructured {
+	canary := map[string]interface{}{}
+	if trafficRouting {
+		canary["trafficRouting"] = map[string]interface{}{
+			"istio": map[string]interface{}{
+				"virtualService": map[string]interface{}{"name": "demo"},
+			},
+		}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata": map[string]interface{}{
+			"name":      "demo",
+			"namespace": "test",
+		},
+		"spec": map[string]interface{}{
+			"strategy": map[string]interface{}{"canary": canary},
+		},
+		"status": map[string]interface{}{
+			"stableRS":       stable,
+			"currentPodHash": current,
+		},
+	}}
+}
+
+func object(t *testing.T, content string) *unstructured.Unstructured {
+	bts, err := kyaml.YAMLToJSON([]byte(content))
+	assert.Nil(t, err)
+	un := &unstructured.Unstructured{}
+	assert.Nil(t, un.UnmarshalJSON(bts))
+	return un
+}
+
+func TestIstio(t *testing.T) {
+	ctx := context.TODO()
+	client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(),
+		object(t, _virtualService), object(t, _destinationRule), rollout(false, "abc", "def"))
+
+	traffic, err := Get(ctx, client, "test", "demo")
+	assert.Nil(t, err)
+	assert.Equal(t, ProviderIstio, traffic.Provider)
+	assert.Equal(t, []Weight{{Destination: "stable", Weight: 100}}, traffic.Weights)
+	assert.Equal(t, []string{"stable", "canary"}, traffic.Destinations)
+	assert.Nil(t, traffic.HeaderRoute)
+
+	_, err = Apply(ctx, client, "test", "demo", &Shift{Weights: []Weight{{Destination: "stable", Weight: 90}}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = Apply(ctx, client, "test", "demo", &Shift{Weights: []Weight{{Destination: "other", Weight: 100}}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	traffic, err = Apply(ctx, client, "test", "demo", &Shift{
+		Weights: []Weight{{Destination: "stable", Weight: 95}, {Destination: "canary", Weight: 5}},
+		HeaderRoute: &HeaderRoute{
+			Header:      "x-canary",
+			Value:       "true",
+			Destination: "canary",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Weight{{Destination: "stable", Weight: 95}, {Destination: "canary", Weight: 5}},
+		traffic.Weights)
+	assert.Equal(t, &HeaderRoute{Header: "x-canary", Value: "true", Destination: "canary"}, traffic.HeaderRoute)
+
+	vs, err := client.Resource(GVRVirtualService).Namespace("test").Get(ctx, "demo", metav1.GetOptions{})
+	assert.Nil(t, err)
+	https, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	assert.Equal(t, 2, len(https))
+	assert.Equal(t, HeaderRouteName, https[0].(map[string]interface{})["name"])
+
+	// weights are kept when only the header route is removed
+	traffic, err = Apply(ctx, client, "test", "demo", &Shift{HeaderRoute: &HeaderRoute{}})
+	assert.Nil(t, err)
+	assert.Nil(t, traffic.HeaderRoute)
+	assert.Equal(t, 2, len(traffic.Weights))
+}
+
+func TestGatewayAPI(t *testing.T) {
+	ctx := context.TODO()
+	client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), object(t, _httpRoute),
+		rollout(false, "abc", "def"))
+
+	traffic, err := Get(ctx, client, "test", "demo")
+	assert.Nil(t, err)
+	assert.Equal(t, ProviderGatewayAPI, traffic.Provider)
+	assert.Equal(t, []Weight{{Destination: "demo-stable", Weight: 100}, {Destination: "demo-canary", Weight: 0}},
+		traffic.Weights)
+
+	traffic, err = Apply(ctx, client, "test", "demo", &Shift{
+		Weights: []Weight{{Destination: "demo-stable", Weight: 75}, {Destination: "demo-canary", Weight: 25}},
+		HeaderRoute: &HeaderRoute{
+			Header:      "x-canary",
+			Value:       "true",
+			Destination: "demo-canary",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Weight{{Destination: "demo-stable", Weight: 75}, {Destination: "demo-canary", Weight: 25}},
+		traffic.Weights)
+	assert.Equal(t, &HeaderRoute{Header: "x-canary", Value: "true", Destination: "demo-canary"},
+		traffic.HeaderRoute)
+
+	traffic, err = Apply(ctx, client, "test", "demo", &Shift{HeaderRoute: &HeaderRoute{}})
+	assert.Nil(t, err)
+	assert.Nil(t, traffic.HeaderRoute)
+	route, err := client.Resource(GVRHTTPRoute.GroupResource().WithVersion("v1")).Namespace("test").
+		Get(ctx, "demo", metav1.GetOptions{})
+	assert.Nil(t, err)
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	assert.Equal(t, 1, len(rules))
+	assert.Empty(t, route.GetAnnotations()[HeaderRouteAnnotation])
+}
+
+func TestRollout(t *testing.T) {
+	ctx := context.TODO()
+	shift := &Shift{Weights: []Weight{{Destination: "stable", Weight: 95}, {Destination: "canary", Weight: 5}}}
+	for name, objects := range map[string][]runtime.Object{
+		"no rollout":      {},
+		"not releasing":   {rollout(false, "abc", "abc")},
+		"traffic routing": {rollout(true, "abc", "def")},
+	} {
+		objects = append(objects, object(t, _virtualService), object(t, _destinationRule))
+		client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+		_, err := Apply(ctx, client, "test", "demo", shift)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), name)
+
+		// traffic can be read anyway
+		_, err = Get(ctx, client, "test", "demo")
+		assert.Nil(t, err, name)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	_, err := Get(context.TODO(), client, "test", "demo")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -49,6 +50,23 @@ func (m Message) String() string {
 	return fmt.Sprintf("%v\n\n%v", m.Header, buffer.String())
 }
 
+// ParseMessage parses a commit message constructed by CommitMessage,
+// ok is false if the message is not constructed by CommitMessage.
+func ParseMessage(message string) (_ *Message, ok bool) {
+	parts := strings.SplitN(message, "\n\n", 2)
+	if len(parts) != 2 {
+		return nil, false
+	}
+	var msg Message
+	if err := json.Unmarshal([]byte(strings.TrimSpace(parts[1])), &msg); err != nil {
+		return nil, false
+	}
+	if msg.Header.Kind != change {
+		return nil, false
+	}
+	return &msg, true
+}
+
 // Header ...
 type Header struct {
 	Kind    kind    `json:"kind,omitempty"`
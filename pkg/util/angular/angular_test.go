@@ -62,3 +62,27 @@ func TestCommitMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMessage(t *testing.T) {
+	message := CommitMessage("cluster", Subject{
+		Operator: "alice",
+		Action:   "update cluster",
+		Cluster:  StringPtr("cluster-test-1"),
+	}, &Body{Replica: func() *int { i := 1; return &i }()})
+	msg, ok := ParseMessage(message)
+	if !ok {
+		t.Fatalf("ParseMessage() failed to parse %v", message)
+	}
+	if msg.Header.Subject.Operator != "alice" || msg.Header.Subject.Action != "update cluster" {
+		t.Errorf("ParseMessage() = %+v, want operator alice and action update cluster", msg.Header.Subject)
+	}
+
+	for _, message := range []string{
+		"git merge gitops into master, pipelineRunID = 1",
+		"Initial commit\n\nnot a json",
+	} {
+		if _, ok := ParseMessage(message); ok {
+			t.Errorf("ParseMessage() parsed %v", message)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DifferenceAdded   = "added"
+	DifferenceRemoved = "removed"
+	DifferenceChanged = "changed"
+)
+
+// keys matching the pattern are formatted as .key, others are formatted as ["key"]
+var _plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Difference is the difference of a path between two objects, arrays are compared as a whole
+type Difference struct {
+	Path string `json:"path"`
+	// Type is added if the path only exists in to, removed if it only exists in from, or changed
+	Type string      `json:"type"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// Diff returns the differences from one object to another, sorted by paths
+func Diff(from, to map[string]interface{}) []*Difference {
+	differences := make([]*Difference, 0)
+	diff(nil, from, to, &differences)
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Path < differences[j].Path
+	})
+	return differences
+}
+
+func diff(prefix []string, from, to map[string]interface{}, differences *[]*Difference) {
+	for key, fromValue := range from {
+		path := append(append([]string{}, prefix...), key)
+		toValue, ok := to[key]
+		if !ok {
+			*differences = append(*differences, &Difference{
+				Path: Format(path), Type: DifferenceRemoved, From: fromValue,
+			})
+			continue
+		}
+		fromMap, fromIsMap := fromValue.(map[string]interface{})
+		toMap, toIsMap := toValue.(map[string]interface{})
+		if fromIsMap && toIsMap {
+			diff(path, fromMap, toMap, differences)
+			continue
+		}
+		if !reflect.DeepEqual(fromValue, toValue) {
+			*differences = append(*differences, &Difference{
+				Path: Format(path), Type: DifferenceChanged, From: fromValue, To: toValue,
+			})
+		}
+	}
+	for key, toValue := range to {
+		if _, ok := from[key]; !ok {
+			path := append(append([]string{}, prefix...), key)
+			*differences = append(*differences, &Difference{
+				Path: Format(path), Type: DifferenceAdded, To: toValue,
+			})
+		}
+	}
+}
+
+// Format formats the keys of a path, such as app.spec["a.b"]
+func Format(keys []string) string {
+	var builder strings.Builder
+	for i, key := range keys {
+		if _plainKey.MatchString(key) {
+			if i > 0 {
+				builder.WriteString(".")
+			}
+			builder.WriteString(key)
+		} else {
+			builder.WriteString("[")
+			builder.WriteString(strconv.Quote(key))
+			builder.WriteString("]")
+		}
+	}
+	return builder.String()
+}
+
+// Parse parses a path formatted by Format into its keys
+func Parse(path string) ([]string, error) {
+	keys := make([]string, 0)
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '[':
+			// scan the quoted key until the unescaped quote
+			end := i + 2
+			for end < len(path) && path[end] != '"' {
+				if path[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if i+1 >= len(path) || path[i+1] != '"' || end+1 >= len(path) || path[end+1] != ']' {
+				return nil, fmt.Errorf("invalid bracket at %d of path %s", i, path)
+			}
+			key, err := strconv.Unquote(path[i+1 : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid key at %d of path %s: %v", i, path, err)
+			}
+			keys = append(keys, key)
+			i = end + 2
+		case path[i] == '.' && i > 0:
+			i++
+			fallthrough
+		default:
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("empty key at %d of path %s", i, path)
+			}
+			keys = append(keys, path[i:end])
+			i = end
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	return keys, nil
+}
+
+// Get returns the value of the path in the object
+func Get(obj map[string]interface{}, keys []string) (interface{}, bool) {
+	var current interface{} = obj
+	for _, key := range keys {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Set sets the value of the path in the object, missing parents are created
+func Set(obj map[string]interface{}, keys []string, value interface{}) error {
+	current := obj
+	for i, key := range keys[:len(keys)-1] {
+		next, ok := current[key]
+		if !ok || next == nil {
+			child := make(map[string]interface{})
+			current[key] = child
+			current = child
+			continue
+		}
+		if current, ok = next.(map[string]interface{}); !ok {
+			return fmt.Errorf("%s is not an object", Format(keys[:i+1]))
+		}
+	}
+	current[keys[len(keys)-1]] = value
+	return nil
+}
+
+// Delete deletes the path from the object, it's a no-op if the path does not exist
+func Delete(obj map[string]interface{}, keys []string) {
+	current := obj
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, keys[len(keys)-1])
+}
@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAndParse(t *testing.T) {
+	for _, keys := range [][]string{
+		{"application", "app", "spec", "replicas"},
+		{"tags", "cloudnative.music.netease.com/team"},
+		{"env", `a"b\c`, "d"},
+		{"x.y"},
+	} {
+		path := Format(keys)
+		parsed, err := Parse(path)
+		assert.Nil(t, err, path)
+		assert.Equal(t, keys, parsed)
+	}
+	assert.Equal(t, `tags["a.b"].c`, Format([]string{"tags", "a.b", "c"}))
+
+	for _, path := range []string{"", "a..b", `a["b`, `a["b"`, "a.", `a[b]`} {
+		_, err := Parse(path)
+		assert.NotNil(t, err, path)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := map[string]interface{}{
+		"app": map[string]interface{}{
+			"replicas": float64(1),
+			"envs":     []interface{}{"a"},
+			"removed":  "x",
+		},
+		"same": "s",
+	}
+	to := map[string]interface{}{
+		"app": map[string]interface{}{
+			"replicas": float64(2),
+			"envs":     []interface{}{"a"},
+			"added":    map[string]interface{}{"k": "v"},
+		},
+		"same": "s",
+	}
+	assert.Equal(t, []*Difference{
+		{Path: "app.added", Type: DifferenceAdded, To: map[string]interface{}{"k": "v"}},
+		{Path: "app.removed", Type: DifferenceRemoved, From: "x"},
+		{Path: "app.replicas", Type: DifferenceChanged, From: float64(1), To: float64(2)},
+	}, Diff(from, to))
+
+	// applying the differences makes the objects equal
+	for _, difference := range Diff(from, to) {
+		keys, err := Parse(difference.Path)
+		assert.Nil(t, err)
+		if value, ok := Get(to, keys); ok {
+			assert.Nil(t, Set(from, keys, value))
+		} else {
+			Delete(from, keys)
+		}
+	}
+	assert.Equal(t, 0, len(Diff(from, to)))
+
+	assert.NotNil(t, Set(from, []string{"same", "child"}, 1))
+	assert.Nil(t, Set(from, []string{"new", "child"}, 1))
+	value, ok := Get(from, []string{"new", "child"})
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+}
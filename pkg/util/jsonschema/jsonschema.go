@@ -83,6 +83,31 @@ func Validate(schema, document interface{}, setUnevaluatedPropertiesToFalse bool
 	return nil
 }
 
+// Compile checks whether the schema is a valid json schema.
+// schema supports 2 types: string, map[string]interface{}
+func Compile(schema interface{}) error {
+	var schemaStr string
+	switch schema := schema.(type) {
+	case string:
+		schemaStr = schema
+	case map[string]interface{}:
+		schemaBytes, err := json.Marshal(schema)
+		if err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid,
+				fmt.Sprintf("json marshal error, schema: %v, error: %s", schema, err.Error()))
+		}
+		schemaStr = string(schemaBytes)
+	default:
+		return perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("unsported type: %T for schema", schema))
+	}
+
+	if _, err := v5jsonschema.CompileString("schema.json", schemaStr); err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return nil
+}
+
 // addUnevaluatedPropertiesField add "unevaluatedProperties": false to the jsonschema
 // which means no additional properties will be allowed.
 func addUnevaluatedPropertiesField(m map[string]interface{}) map[string]interface{} {
@@ -849,3 +849,12 @@ func TestDependency(t *testing.T) {
 	err = Validate(schema, document, true)
 	assert.NotNil(t, err)
 }
+
+func TestCompile(t *testing.T) {
+	assert.Nil(t, Compile(`{"type": "object", "properties": {"replicas": {"type": "integer"}}}`))
+	assert.Nil(t, Compile(map[string]interface{}{"type": "string"}))
+	assert.NotNil(t, Compile(`{"type": "object"`))
+	assert.NotNil(t, Compile(`{"type": "unknown"}`))
+	assert.NotNil(t, Compile(`{"properties": {"replicas": {"minimum": "one"}}}`))
+	assert.NotNil(t, Compile(1))
+}
@@ -71,7 +71,8 @@ func init() {
 func GetEvents(ctx context.Context, kubeClientset kubernetes.Interface,
 	namespace string) (_ map[string][]*v1.Event, err error) {
 	const op = "kube: get multi pod events from k8s "
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	eventsMapper := make(map[string][]*v1.Event)
 	events, err := kubeClientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
@@ -99,7 +100,8 @@ func GetEvents(ctx context.Context, kubeClientset kubernetes.Interface,
 func GetPodEvents(ctx context.Context, kubeClientset kubernetes.Interface, namespace, pod string) (_ []v1.Event,
 	err error) {
 	const op = "kube: get single pod events from k8s "
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	events, err := kubeClientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
 		Limit: DefaultEventsLimit,
@@ -118,7 +120,8 @@ func GetPodEvents(ctx context.Context, kubeClientset kubernetes.Interface, names
 func GetPods(ctx context.Context, kubeClientset kubernetes.Interface,
 	namespace, labelSelector string) (_ []v1.Pod, err error) {
 	const op = "kube: get pods from k8s "
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	pods, err := kubeClientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector,
@@ -250,7 +253,8 @@ type ContainerRef struct {
 func Exec(ctx context.Context, c ContainerRef,
 	command []string, executor exec.RemoteExecutor) (stdout string, stderr string, err error) {
 	const op = "kube: execute command in pod"
-	defer wlog.Start(ctx, op).StopPrint()
+	_, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	out := bytes.NewBuffer([]byte{})
 	errOut := bytes.NewBuffer([]byte{})
@@ -290,7 +294,8 @@ func Exec(ctx context.Context, c ContainerRef,
 func GetReplicaSets(ctx context.Context, kubeClientset kubernetes.Interface,
 	namespace, labelSelector string) (_ []appsv1.ReplicaSet, err error) {
 	const op = "get replicaSet list from k8s "
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	replicaSetList, err := kubeClientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector,
@@ -304,7 +309,8 @@ func GetReplicaSets(ctx context.Context, kubeClientset kubernetes.Interface,
 func GetDeploymentList(ctx context.Context, kubeClientset kubernetes.Interface,
 	namespace, labelSelector string) (_ []appsv1.Deployment, err error) {
 	const op = "get deployments from k8s "
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	deploymentList, err := kubeClientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector,
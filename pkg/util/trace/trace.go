@@ -0,0 +1,169 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	tracecfg "github.com/horizoncd/horizon/pkg/config/trace"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	_tracerName         = "github.com/horizoncd/horizon"
+	_defaultServiceName = "horizon"
+	_spanKey            = "span"
+)
+
+// AttributeRequestID is the attribute of span carrying the request id
+const AttributeRequestID = attribute.Key("horizon.request_id")
+
+// Init sets the global tracer provider by config, the returned func flushes and stops the exporter
+func Init(ctx context.Context, config tracecfg.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch config.Exporter {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter: %s", config.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = _defaultServiceName
+	}
+	sampleRatio := config.SampleRatio
+	if sampleRatio <= 0 {
+		sampleRatio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// SpanKey is the key of the span stored in gin context,
+// gin context doesn't look up the request context, so the span of request is stored by the key
+func SpanKey() string {
+	return _spanKey
+}
+
+// Tracer returns the tracer of horizon
+func Tracer() oteltrace.Tracer {
+	return otel.Tracer(_tracerName)
+}
+
+// Start starts a span as the child of the span in ctx, the request id in ctx is carried as an attribute
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, oteltrace.Span) {
+	ctx = withParent(ctx)
+	if rid, ok := ctx.Value(log.Key()).(string); ok && rid != "" {
+		attrs = append(attrs, AttributeRequestID.String(rid))
+	}
+	return Tracer().Start(ctx, name, oteltrace.WithAttributes(attrs...))
+}
+
+// End records err if it's not nil and ends the span
+func End(span oteltrace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func withParent(ctx context.Context) context.Context {
+	if oteltrace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	if span, ok := ctx.Value(_spanKey).(oteltrace.Span); ok {
+		return oteltrace.ContextWithSpan(ctx, span)
+	}
+	return ctx
+}
+
+// NewTransport returns a transport creating a span for each request sent to the component
+func NewTransport(component string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{component: component, base: base}
+}
+
+// WrapTransport returns a func wrapping transport by NewTransport, it's used as rest.Config.WrapTransport
+func WrapTransport(component string) func(http.RoundTripper) http.RoundTripper {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return NewTransport(component, base)
+	}
+}
+
+type transport struct {
+	component string
+	base      http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := Start(req.Context(), fmt.Sprintf("%s: %s %s", t.component, req.Method, req.URL.Path),
+		semconv.HTTPClientAttributesFromHTTPRequest(req)...)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+	return resp, nil
+}
@@ -0,0 +1,90 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	tracecfg "github.com/horizoncd/horizon/pkg/config/trace"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+func newRecorder() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+func TestStart(t *testing.T) {
+	recorder := newRecorder()
+
+	// the parent span is stored by SpanKey, as what gin context does
+	_, parent := Tracer().Start(context.Background(), "parent")
+	ctx := context.WithValue(context.Background(), SpanKey(), parent) // nolint
+	ctx = log.WithContext(ctx, "request-id")
+
+	_, span := Start(ctx, "child")
+	End(span, errors.New("failed"))
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Equal(t, 2, len(spans))
+	child := spans[0]
+	assert.Equal(t, "child", child.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, codes.Error, child.Status().Code)
+	assert.Contains(t, child.Attributes(), AttributeRequestID.String("request-id"))
+}
+
+func TestTransport(t *testing.T) {
+	recorder := newRecorder()
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport("test", nil)}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/path", nil)
+	assert.Nil(t, err)
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, "test: GET /path", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, traceparent, spans[0].SpanContext().TraceID().String())
+}
+
+func TestInitUnsupportedExporter(t *testing.T) {
+	_, err := Init(context.Background(), tracecfg.Config{Exporter: "unknown"})
+	assert.NotNil(t, err)
+}
@@ -16,25 +16,38 @@ package wlog
 
 import (
 	"context"
+	"fmt"
 	"runtime/debug"
 	"time"
 
+	oteltrace "go.opentelemetry.io/otel/trace"
+
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 )
 
 type Log struct {
 	ctx   context.Context
 	start time.Time
 	op    string
+	span  oteltrace.Span
 }
 
-func Start(ctx context.Context, op string) Log {
-	return Log{op: op, ctx: ctx, start: time.Now()}
+// Start starts a span named by op as well, it ends when StopPrint is called.
+// The returned context carries the span, pass it down so that the spans of callees nest under it.
+func Start(ctx context.Context, op string) (context.Context, Log) {
+	ctx, span := trace.Start(ctx, op)
+	return ctx, Log{op: op, ctx: ctx, start: time.Now(), span: span}
 }
 
 func (l Log) StopPrint() {
+	var panicErr error
 	if err := recover(); err != nil {
 		log.Error(l.ctx, string(debug.Stack()))
+		panicErr = fmt.Errorf("panic: %v", err)
+	}
+	if l.span != nil {
+		trace.End(l.span, panicErr)
 	}
 	duration := time.Since(l.start)
 
@@ -21,6 +21,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
@@ -29,17 +34,20 @@ func TestLogOK(t *testing.T) {
 	ctx := log.WithContext(context.Background(), "traceId")
 
 	const op = "app: create application"
-	defer Start(ctx, op).StopPrint()
+	ctx, l := Start(ctx, op)
+	defer l.StopPrint()
 	log.Info(ctx, "hello world")
 
-	Start(ctx, "test: stopPrint").StopPrint()
+	_, l2 := Start(ctx, "test: stopPrint")
+	l2.StopPrint()
 }
 
 func TestPanic(t *testing.T) {
 	ctx := log.WithContext(context.Background(), "traceId")
 
 	const op = "app: create application"
-	defer Start(ctx, op).StopPrint()
+	_, l := Start(ctx, op)
+	defer l.StopPrint()
 
 	doPanic()
 }
@@ -48,7 +56,8 @@ func TestPanic2(t *testing.T) {
 	ctx := log.WithContext(context.Background(), "traceId")
 
 	const op = "app: create application"
-	defer Start(ctx, op).StopPrint()
+	_, l := Start(ctx, op)
+	defer l.StopPrint()
 
 	doPanic()
 }
@@ -62,7 +71,8 @@ func TestLogError(t *testing.T) {
 	ctx := log.WithContext(context.Background(), "traceId")
 
 	const op = "app: create application"
-	defer Start(ctx, op).StopPrint()
+	ctx, l := Start(ctx, op)
+	defer l.StopPrint()
 
 	// err = errors.New("unknown error")
 	log.Info(ctx, "hello world")
@@ -80,8 +90,23 @@ func TestGetDuration(t *testing.T) {
 	ctx := log.WithContext(context.Background(), "traceId")
 
 	const op = "app: create application"
-	l := Start(ctx, op)
+	ctx, l := Start(ctx, op)
 	log.Info(ctx, "hello world")
 	t.Logf("duration: %v", l.GetDuration())
 	l.StopPrint()
 }
+
+func TestNestedSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, parent := Start(context.Background(), "controller")
+	_, child := Start(ctx, "dao")
+	child.StopPrint()
+	parent.StopPrint()
+
+	spans := recorder.Ended()
+	assert.Equal(t, 2, len(spans))
+	assert.Equal(t, "dao", spans[0].Name())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
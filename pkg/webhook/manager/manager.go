@@ -61,67 +61,77 @@ func New(db *gorm.DB) Manager {
 
 func (m *manager) CreateWebhook(ctx context.Context, w *models.Webhook) (*models.Webhook, error) {
 	const op = "webhook manager: create webhook"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.CreateWebhook(ctx, w)
 }
 
 func (m *manager) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
 	const op = "webhook manager: get webhook"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.GetWebhook(ctx, id)
 }
 
 func (m *manager) ListWebhookOfResources(ctx context.Context,
 	resources map[string][]uint, query *q.Query) ([]*models.Webhook, int64, error) {
 	const op = "webhook manager: list webhook of resources"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.ListWebhookOfResources(ctx, resources, query)
 }
 
 func (m *manager) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
 	const op = "webhook manager: list webhooks"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.ListWebhooks(ctx)
 }
 
 func (m *manager) UpdateWebhook(ctx context.Context, id uint,
 	w *models.Webhook) (*models.Webhook, error) {
 	const op = "webhook manager: update webhook"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	return m.dao.UpdateWebhook(ctx, id, w)
 }
 
 func (m *manager) DeleteWebhook(ctx context.Context, id uint) error {
 	const op = "webhook manager: delete webhook"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.DeleteWebhook(ctx, id)
 }
 
 func (m *manager) CreateWebhookLog(ctx context.Context,
 	wl *models.WebhookLog) (*models.WebhookLog, error) {
 	const op = "webhook manager: create webhook log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.CreateWebhookLog(ctx, wl)
 }
 
 func (m *manager) ListWebhookLogs(ctx context.Context, query *q.Query,
 	resources map[string][]uint) ([]*models.WebhookLogWithEventInfo, int64, error) {
 	const op = "webhook manager: list webhook logs"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.ListWebhookLogs(ctx, query, resources)
 }
 
 func (m *manager) ListWebhookLogsByMap(ctx context.Context,
 	webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error) {
 	const op = "webhook manager: list webhook logs by webhooks and events map"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.ListWebhookLogsByMap(ctx, webhookEventMap)
 }
 
 func (m *manager) CreateWebhookLogs(ctx context.Context, wls []*models.WebhookLog) ([]*models.WebhookLog, error) {
 	const op = "webhook manager: create webhook logs"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.CreateWebhookLogs(ctx, wls)
 }
 
@@ -132,32 +142,37 @@ func (m *manager) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 
 func (m *manager) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	const op = "webhook manager: update  webhook log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.UpdateWebhookLog(ctx, wl)
 }
 
 func (m *manager) GetWebhookLog(ctx context.Context, id uint) (*models.WebhookLog, error) {
 	const op = "webhook manager: get webhook log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.GetWebhookLog(ctx, id)
 }
 
 func (m *manager) GetWebhookLogByEventID(ctx context.Context, webhookID, eventID uint) (*models.WebhookLog, error) {
 	const op = "webhook manager: get webhook log by event id"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 	return m.dao.GetWebhookLogByEventID(ctx, webhookID, eventID)
 }
 
 func (m *manager) DeleteWebhookLogs(ctx context.Context, id ...uint) (int64, error) {
 	const op = "webhook manager: delete webhook log"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	return m.dao.DeleteWebhookLogs(ctx, id...)
 }
 
 func (m *manager) ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error) {
 	const op = "webhook manager: resend"
-	defer wlog.Start(ctx, op).StopPrint()
+	ctx, l := wlog.Start(ctx, op)
+	defer l.StopPrint()
 
 	// 1. get webhook log
 	wl, err := m.dao.GetWebhookLog(ctx, id)
@@ -28,6 +28,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"gopkg.in/yaml.v3"
 
 	webhookconfig "github.com/horizoncd/horizon/pkg/config/webhook"
@@ -36,24 +38,57 @@ import (
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/trace"
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 	"github.com/horizoncd/horizon/pkg/webhook/models"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
 )
 
 type worker struct {
+	// settings holds *workerSettings, which can be replaced at runtime
+	settings atomic.Value
+
+	ctx     context.Context
+	quit    chan bool
+	webhook atomic.Value
+
+	webhookManager webhookmanager.Manager
+	eventManager   eventmanager.Manager
+	userManager    usermanager.Manager
+}
+
+type workerSettings struct {
 	idleWaitInterval         uint
 	responseBodyTruncateSize uint
 
-	ctx            context.Context
 	insecureClient http.Client
 	secureClient   http.Client
-	quit           chan bool
-	webhook        atomic.Value
+}
 
-	webhookManager webhookmanager.Manager
-	eventManager   eventmanager.Manager
-	userManager    usermanager.Manager
+func newWorkerSettings(config webhookconfig.Config) *workerSettings {
+	return &workerSettings{
+		idleWaitInterval:         config.IdleWaitInterval,
+		responseBodyTruncateSize: config.ResponseBodyTruncateSize,
+		insecureClient: http.Client{
+			Timeout: time.Second * time.Duration(config.ClientTimeout),
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			},
+		},
+		secureClient: http.Client{
+			Timeout: time.Second * time.Duration(config.ClientTimeout),
+		},
+	}
+}
+
+func (w *worker) setSettings(settings *workerSettings) {
+	w.settings.Store(settings)
+}
+
+func (w *worker) getSettings() *workerSettings {
+	return w.settings.Load().(*workerSettings)
 }
 
 func (w *worker) setWebhook(webhook *models.Webhook) {
@@ -71,9 +106,12 @@ func (w *worker) getWebhook() (*models.Webhook, error) {
 type Service interface {
 	Start()
 	StopAndWait()
+	// SetConfig replaces the tunables of the service and its workers
+	SetConfig(config webhookconfig.Config)
 }
 
 type service struct {
+	sync.RWMutex
 	config  webhookconfig.Config
 	ctx     context.Context
 	quit    chan bool
@@ -95,6 +133,22 @@ func NewService(ctx context.Context, manager *managerparam.Manager, config webho
 	}
 }
 
+func (s *service) SetConfig(config webhookconfig.Config) {
+	s.Lock()
+	defer s.Unlock()
+	s.config = config
+	settings := newWorkerSettings(config)
+	for _, w := range s.workers {
+		w.setSettings(settings)
+	}
+}
+
+func (s *service) getConfig() webhookconfig.Config {
+	s.RLock()
+	defer s.RUnlock()
+	return s.config
+}
+
 func (s *service) stopWorkersAndWait() {
 	wg := sync.WaitGroup{}
 	wg.Add(len(s.workers))
@@ -115,6 +169,8 @@ func (s *service) reconcileWorkers() {
 		return
 	}
 	// 2. compare and reconcile workers
+	s.Lock()
+	defer s.Unlock()
 	reconciled := map[uint]bool{}
 	for _, webhook := range webhooks {
 		id := webhook.ID
@@ -131,8 +187,7 @@ func (s *service) reconcileWorkers() {
 		} else {
 			// 2.2 create workers
 			s.workers[id] = newWebhookWorker(s.webhookManager, s.eventManager,
-				s.userManager, webhook, s.config.IdleWaitInterval,
-				s.config.ClientTimeout, s.config.ResponseBodyTruncateSize)
+				s.userManager, webhook, newWorkerSettings(s.config))
 		}
 		reconciled[id] = true
 	}
@@ -157,7 +212,8 @@ func (s *service) Start() {
 			}
 		}()
 
-		t := time.NewTicker(time.Second * time.Duration(s.config.WorkerReconcileInterval))
+		reconcileInterval := s.getConfig().WorkerReconcileInterval
+		t := time.NewTicker(time.Second * time.Duration(reconcileInterval))
 		s.reconcileWorkers()
 	L:
 		for {
@@ -168,6 +224,11 @@ func (s *service) Start() {
 				break L
 			case <-t.C:
 				s.reconcileWorkers()
+				// apply reconcile interval reloaded at runtime
+				if interval := s.getConfig().WorkerReconcileInterval; interval != reconcileInterval {
+					reconcileInterval = interval
+					t.Reset(time.Second * time.Duration(reconcileInterval))
+				}
 			}
 		}
 	}()
@@ -181,28 +242,15 @@ func (s *service) StopAndWait() {
 
 func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	eventMgr eventmanager.Manager, userMgr usermanager.Manager,
-	webhook *models.Webhook, idleWaitInterval uint,
-	clientTimeout, responseBodyTruncateSize uint) *worker {
+	webhook *models.Webhook, settings *workerSettings) *worker {
 	ww := &worker{
-		idleWaitInterval:         idleWaitInterval,
-		responseBodyTruncateSize: responseBodyTruncateSize,
-		ctx:                      context.Background(),
-		quit:                     make(chan bool, 1),
-		insecureClient: http.Client{
-			Timeout: time.Second * time.Duration(clientTimeout),
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
-		},
-		secureClient: http.Client{
-			Timeout: time.Second * time.Duration(clientTimeout),
-		},
+		ctx:            context.Background(),
+		quit:           make(chan bool, 1),
 		webhookManager: webhookMgr,
 		eventManager:   eventMgr,
 		userManager:    userMgr,
 	}
+	ww.setSettings(settings)
 	ww.setWebhook(webhook)
 	go ww.start()
 	return ww
@@ -216,7 +264,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		log.Errorf(ctx, wl.ErrorMessage)
 		return wl
 	}
-	req, err := http.NewRequest(http.MethodPost, wl.URL,
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wl.URL,
 		bytes.NewBuffer(reqBody))
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to new request, error: %+v", err)
@@ -234,7 +282,8 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 	req.Header = headers
 
 	// 3. send request
-	cli := w.secureClient
+	settings := w.getSettings()
+	cli := &settings.secureClient
 	webhook, err := w.getWebhook()
 	if err != nil {
 		log.Error(ctx, err)
@@ -243,7 +292,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 	}
 
 	if !webhook.SSLVerifyEnabled {
-		cli = w.insecureClient
+		cli = &settings.insecureClient
 	}
 	resp, err := cli.Do(req)
 	if err != nil {
@@ -254,7 +303,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 
 	// 4. update response body
 	respBody, err := ioutil.ReadAll(
-		io.LimitReader(resp.Body, int64(w.responseBodyTruncateSize)),
+		io.LimitReader(resp.Body, int64(settings.responseBodyTruncateSize)),
 	)
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to read response body, error: %+v", err)
@@ -308,11 +357,11 @@ L:
 				continue
 			}
 			if len(wls) == 0 {
-				time.Sleep(time.Second * time.Duration(w.idleWaitInterval))
+				time.Sleep(time.Second * time.Duration(w.getSettings().idleWaitInterval))
 				continue
 			}
 			for _, wl := range wls {
-				saveResult := func() {
+				saveResult := func(ctx context.Context) {
 					if wl.ErrorMessage != "" {
 						wl.Status = webhookmodels.StatusFailed
 					} else {
@@ -324,8 +373,15 @@ L:
 					}
 				}
 
-				wl = w.sendWebhook(ctx, wl)
-				saveResult()
+				wlCtx, span := trace.Start(ctx, "webhook: send",
+					attribute.Int64("horizon.webhook_id", int64(webhook.ID)),
+					attribute.Int64("horizon.webhook_log_id", int64(wl.ID)))
+				wl = w.sendWebhook(wlCtx, wl)
+				saveResult(wlCtx)
+				if wl.ErrorMessage != "" {
+					span.SetStatus(codes.Error, wl.ErrorMessage)
+				}
+				span.End()
 			}
 		}
 	}
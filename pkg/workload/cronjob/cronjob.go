@@ -0,0 +1,237 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronjob
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/job"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+)
+
+var (
+	GVRCronJob = schema.GroupVersionResource{
+		Group:    "batch",
+		Version:  "v1",
+		Resource: "cronjobs",
+	}
+	GVRCronJobV1beta1 = schema.GroupVersionResource{
+		Group:    "batch",
+		Version:  "v1beta1",
+		Resource: "cronjobs",
+	}
+)
+
+const (
+	// InstantiateAnnotation marks the jobs triggered manually, same as kubectl create job --from
+	InstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+	instantiateManual     = "manual"
+)
+
+func init() {
+	workload.Register(ability, GVRCronJob, GVRCronJobV1beta1, job.GVRJob, job.GVRPod)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &cronJob{}
+
+type cronJob struct{}
+
+func (*cronJob) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "batch" && gk.Kind == "CronJob"
+}
+
+func (*cronJob) getCronJob(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) (*unstructured.Unstructured, error) {
+	gvr := GVRCronJob
+	if node.Version == GVRCronJobV1beta1.Version {
+		gvr = GVRCronJobV1beta1
+	}
+	obj, err := factory.ForResource(gvr).Lister().ByNamespace(node.Namespace).Get(node.Name)
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to get cronjob in k8s: cronjob = %s, ns = %v, err = %v",
+				node.Name, node.Namespace, err))
+	}
+	un, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to convert obj into unstructured: name = %s, ns = %v",
+				node.Name, node.Namespace))
+	}
+	return un, nil
+}
+
+// listJobs lists the jobs owned by the cronjob, the latest job comes first
+func (c *cronJob) listJobs(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]*unstructured.Unstructured, error) {
+	cj, err := c.getCronJob(node, factory)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := factory.ForResource(job.GVRJob).Lister().ByNamespace(node.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		un, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		for _, owner := range un.GetOwnerReferences() {
+			if owner.UID == cj.GetUID() {
+				jobs = append(jobs, un)
+				break
+			}
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		ti, tj := jobs[i].GetCreationTimestamp(), jobs[j].GetCreationTimestamp()
+		if ti.Equal(&tj) {
+			return jobs[i].GetName() > jobs[j].GetName()
+		}
+		return tj.Before(&ti)
+	})
+	return jobs, nil
+}
+
+// IsHealthy always returns true, since a failed run does not mean the cronjob is unhealthy,
+// statuses of the runs are shown by ListRuns
+func (*cronJob) IsHealthy(node *v1alpha1.ResourceNode,
+	client *kube.Client) (bool, error) {
+	return true, nil
+}
+
+func (c *cronJob) ListPods(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	jobs, err := c.listJobs(node, factory)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]corev1.Pod, 0)
+	for _, un := range jobs {
+		instance, err := job.FromUnstructured(un)
+		if err != nil {
+			return nil, err
+		}
+		jobPods, err := job.ListPodsOf(instance, factory)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, jobPods...)
+	}
+	return pods, nil
+}
+
+func (c *cronJob) ListRuns(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]workload.Run, error) {
+	jobs, err := c.listJobs(node, factory)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]workload.Run, 0, len(jobs))
+	for _, un := range jobs {
+		instance, err := job.FromUnstructured(un)
+		if err != nil {
+			return nil, err
+		}
+		pods, err := job.ListPodsOf(instance, factory)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, job.RunOf(un, instance, pods))
+	}
+	return runs, nil
+}
+
+func (*cronJob) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	switch actionName {
+	case workload.ActionSuspend:
+		return workload.Suspend(un, true)
+	case workload.ActionResume:
+		return workload.Suspend(un, false)
+	}
+	return un, nil
+}
+
+// Spawn creates a job from the job template of the cronjob when triggered
+func (*cronJob) Spawn(actionName string, un *unstructured.Unstructured) (schema.GroupVersionResource,
+	*unstructured.Unstructured, error) {
+	if actionName != workload.ActionTrigger {
+		return schema.GroupVersionResource{}, nil, nil
+	}
+	template, found, err := unstructured.NestedMap(un.Object, "spec", "jobTemplate")
+	if err != nil || !found {
+		return schema.GroupVersionResource{}, nil,
+			perror.Wrapf(herrors.ErrParamInvalid, "invalid job template of cronjob %s: %v", un.GetName(), err)
+	}
+
+	// the cronjob name is truncated to keep the unique suffix within the limit of 63 characters
+	suffix := "-manual-" + strconv.FormatInt(time.Now().Unix(), 10)
+	prefix := un.GetName()
+	if len(prefix)+len(suffix) > 63 {
+		prefix = prefix[:63-len(suffix)]
+	}
+	name := strings.Trim(prefix, "-") + suffix
+	spawned := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if spec, ok := template["spec"].(map[string]interface{}); ok {
+		spawned.Object["spec"] = spec
+	}
+	spawned.SetAPIVersion(job.GVRJob.GroupVersion().String())
+	spawned.SetKind("Job")
+	spawned.SetName(name)
+	spawned.SetNamespace(un.GetNamespace())
+
+	meta, _, _ := unstructured.NestedMap(template, "metadata")
+	spawnedLabels := make(map[string]string)
+	if ls, ok := meta["labels"].(map[string]interface{}); ok {
+		for k, v := range ls {
+			spawnedLabels[k] = fmt.Sprint(v)
+		}
+	}
+	spawned.SetLabels(spawnedLabels)
+	annotations := map[string]string{InstantiateAnnotation: instantiateManual}
+	if as, ok := meta["annotations"].(map[string]interface{}); ok {
+		for k, v := range as {
+			annotations[k] = fmt.Sprint(v)
+		}
+	}
+	spawned.SetAnnotations(annotations)
+
+	controller := true
+	spawned.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: un.GetAPIVersion(),
+		Kind:       un.GetKind(),
+		Name:       un.GetName(),
+		UID:        un.GetUID(),
+		Controller: &controller,
+	}})
+	return job.GVRJob, spawned, nil
+}
@@ -0,0 +1,177 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronjob
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/job"
+)
+
+func cronJobObj() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"metadata": map[string]interface{}{
+			"name":      "demo",
+			"namespace": "test",
+			"uid":       "cronjob-uid",
+		},
+		"spec": map[string]interface{}{
+			"schedule": "*/5 * * * *",
+			"jobTemplate": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "demo"},
+				},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"restartPolicy": "Never",
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func jobObj(name, created, ownerUID string, conditionType string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"namespace":         "test",
+			"creationTimestamp": created,
+			"ownerReferences": []interface{}{map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "CronJob",
+				"name":       "demo",
+				"uid":        ownerUID,
+			}},
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"controller-uid": name},
+			},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{
+				"type":   conditionType,
+				"status": "True",
+			}},
+		},
+	}}
+}
+
+func podObj(name, jobName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "test",
+			"labels":    map[string]interface{}{"controller-uid": jobName},
+		},
+	}}
+}
+
+func TestListRuns(t *testing.T) {
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			GVRCronJob: "CronJobList", job.GVRJob: "JobList", job.GVRPod: "PodList",
+		},
+		cronJobObj(),
+		jobObj("demo-1", "2022-01-01T00:00:00Z", "cronjob-uid", "Complete"),
+		jobObj("demo-2", "2022-01-01T00:05:00Z", "cronjob-uid", "Failed"),
+		jobObj("other-1", "2022-01-01T00:10:00Z", "other-uid", "Complete"),
+		podObj("demo-1-abc", "demo-1"), podObj("demo-2-abc", "demo-2"), podObj("other-1-abc", "other-1"))
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	factory.ForResource(GVRCronJob)
+	factory.ForResource(job.GVRJob)
+	factory.ForResource(job.GVRPod)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	gk := schema.GroupKind{Group: "batch", Kind: "CronJob"}
+	a, err := workload.GetAbility(gk)
+	assert.Nil(t, err)
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "batch", Version: "v1", Kind: "CronJob", Namespace: "test", Name: "demo",
+	}}
+
+	runs, err := a.(workload.RunsLister).ListRuns(node, factory)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(runs))
+	assert.Equal(t, "demo-2", runs[0].Name)
+	assert.Equal(t, workload.RunStatusFailed, runs[0].Status)
+	assert.Equal(t, []string{"demo-2-abc"}, runs[0].Pods)
+	assert.Equal(t, "demo-1", runs[1].Name)
+	assert.Equal(t, workload.RunStatusSucceeded, runs[1].Status)
+
+	pods, err := a.(workload.PodsLister).ListPods(node, factory)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pods))
+}
+
+func TestSpawn(t *testing.T) {
+	cj := cronJobObj()
+	gvr, spawned, err := ability.Spawn(workload.ActionSuspend, cj)
+	assert.Nil(t, err)
+	assert.Nil(t, spawned)
+	assert.Equal(t, schema.GroupVersionResource{}, gvr)
+
+	gvr, spawned, err = ability.Spawn(workload.ActionTrigger, cj)
+	assert.Nil(t, err)
+	assert.Equal(t, job.GVRJob, gvr)
+	assert.Equal(t, "Job", spawned.GetKind())
+	assert.Equal(t, "test", spawned.GetNamespace())
+	assert.Contains(t, spawned.GetName(), "demo-manual-")
+	assert.Equal(t, "demo", spawned.GetLabels()["app"])
+	assert.Equal(t, instantiateManual, spawned.GetAnnotations()[InstantiateAnnotation])
+	assert.Equal(t, 1, len(spawned.GetOwnerReferences()))
+	assert.Equal(t, cj.GetUID(), spawned.GetOwnerReferences()[0].UID)
+	policy, _, _ := unstructured.NestedString(spawned.Object, "spec", "template", "spec", "restartPolicy")
+	assert.Equal(t, "Never", policy)
+
+	// long names of cronjobs are truncated, the unique suffix is kept
+	long := cronJobObj()
+	long.SetName(strings.Repeat("a", 45) + "-" + strings.Repeat("b", 20))
+	_, spawned, err = ability.Spawn(workload.ActionTrigger, long)
+	assert.Nil(t, err)
+	assert.Equal(t, 63, len(spawned.GetName()))
+	assert.True(t, strings.HasPrefix(spawned.GetName(), strings.Repeat("a", 45)+"-manual-"))
+
+	un, err := ability.Action(workload.ActionSuspend, cj)
+	assert.Nil(t, err)
+	suspended, _, _ := unstructured.NestedBool(un.Object, "spec", "suspend")
+	assert.True(t, suspended)
+	un, err = ability.Action(workload.ActionResume, un)
+	assert.Nil(t, err)
+	suspended, _, _ = unstructured.NestedBool(un.Object, "spec", "suspend")
+	assert.False(t, suspended)
+}
@@ -0,0 +1,188 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemonset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+var (
+	GVRDaemonSet = schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "daemonsets",
+	}
+	GVRPod = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "pods",
+	}
+)
+
+const (
+	// TemplateGenerationAnnotation is the generation of the pod template set by the daemonset controller
+	TemplateGenerationAnnotation = "deprecated.daemonset.template.generation"
+	// PodTemplateGenerationLabel is the generation of the pod template the pod is created from
+	PodTemplateGenerationLabel = "pod-template-generation"
+)
+
+func init() {
+	workload.Register(ability, GVRDaemonSet, GVRPod)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &daemonsets{}
+
+type daemonsets struct{}
+
+func (*daemonsets) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "apps" && gk.Kind == "DaemonSet"
+}
+
+func (*daemonsets) getDaemonSet(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) (*v1.DaemonSet, error) {
+	obj, err := factory.ForResource(GVRDaemonSet).Lister().ByNamespace(node.Namespace).Get(node.Name)
+	if err != nil {
+		return nil,
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to get daemonsets in k8s: daemonsets = %s, ns = %v, err = %v",
+					node.Name, node.Namespace, err),
+			)
+	}
+	un, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil,
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to convert obj into unstructured: name = %s, ns = %v",
+					node.Name, node.Namespace),
+			)
+	}
+	instance := &v1.DaemonSet{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, instance)
+	if err != nil {
+		return nil,
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to convert unstructured into daemonsets: name = %s, ns = %v, err = %v",
+					node.Name, node.Namespace, err),
+			)
+	}
+	return instance, nil
+}
+
+func (*daemonsets) getDaemonSetByNode(node *v1alpha1.ResourceNode, client *kube.Client) (*v1.DaemonSet, error) {
+	instance, err := client.Basic.AppsV1().DaemonSets(node.Namespace).Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get daemonsets in k8s"),
+			"failed to get daemonsets in k8s: daemonsets = %s, ns = %v, err = %v", node.Name, node.Namespace, err)
+	}
+	return instance, nil
+}
+
+func (d *daemonsets) IsHealthy(node *v1alpha1.ResourceNode,
+	client *kube.Client) (bool, error) {
+	instance, err := d.getDaemonSetByNode(node, client)
+	if err != nil {
+		return true, err
+	}
+
+	if instance.Status.ObservedGeneration != instance.Generation {
+		return false, nil
+	}
+
+	desired := instance.Status.DesiredNumberScheduled
+	return instance.Status.UpdatedNumberScheduled == desired &&
+		instance.Status.NumberAvailable == desired, nil
+}
+
+func (d *daemonsets) ListPods(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	instance, err := d.getDaemonSet(node, factory)
+	if err != nil {
+		return nil, err
+	}
+	return listPods(instance, factory)
+}
+
+// GetNodeRollouts returns whether the pod on each node is updated and ready, sorted by node
+func (d *daemonsets) GetNodeRollouts(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]workload.NodeRollout, error) {
+	instance, err := d.getDaemonSet(node, factory)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := listPods(instance, factory)
+	if err != nil {
+		return nil, err
+	}
+
+	generation, ok := instance.Annotations[TemplateGenerationAnnotation]
+	if !ok {
+		generation = fmt.Sprint(instance.Generation)
+	}
+	rollouts := make([]workload.NodeRollout, 0, len(pods))
+	for _, pod := range pods {
+		rollout := workload.NodeRollout{
+			Node:    pod.Spec.NodeName,
+			Pod:     pod.Name,
+			Updated: pod.Labels[PodTemplateGenerationLabel] == generation,
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady {
+				rollout.Ready = condition.Status == corev1.ConditionTrue
+			}
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	sort.SliceStable(rollouts, func(i, j int) bool {
+		return rollouts[i].Node < rollouts[j].Node
+	})
+	return rollouts, nil
+}
+
+func (*daemonsets) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if actionName == workload.ActionRestart {
+		return workload.Restart(un)
+	}
+	return un, nil
+}
+
+func listPods(instance *v1.DaemonSet,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	selector := labels.SelectorFromSet(instance.Spec.Selector.MatchLabels)
+	objs, err := factory.ForResource(GVRPod).Lister().ByNamespace(instance.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return workload.ObjIntoPod(objs...), nil
+}
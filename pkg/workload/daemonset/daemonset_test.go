@@ -0,0 +1,89 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemonset
+
+import (
+	"testing"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+func TestGetNodeRollouts(t *testing.T) {
+	ds := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "DaemonSet",
+		"metadata": map[string]interface{}{
+			"name":        "demo",
+			"namespace":   "test",
+			"annotations": map[string]interface{}{TemplateGenerationAnnotation: "2"},
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "demo"},
+			},
+		},
+	}}
+	pod := func(name, node, generation, ready string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "test",
+				"labels": map[string]interface{}{
+					"app": "demo", PodTemplateGenerationLabel: generation,
+				},
+			},
+			"spec": map[string]interface{}{"nodeName": node},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{
+					"type": "Ready", "status": ready,
+				}},
+			},
+		}}
+	}
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{GVRDaemonSet: "DaemonSetList", GVRPod: "PodList"},
+		ds, pod("demo-b", "node-b", "1", "True"), pod("demo-a", "node-a", "2", "False"))
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	factory.ForResource(GVRDaemonSet)
+	factory.ForResource(GVRPod)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{Namespace: "test", Name: "demo"}}
+	rollouts, err := ability.GetNodeRollouts(node, factory)
+	assert.Nil(t, err)
+	assert.Equal(t, []workload.NodeRollout{
+		{Node: "node-a", Pod: "demo-a", Updated: true, Ready: false},
+		{Node: "node-b", Pod: "demo-b", Updated: false, Ready: true},
+	}, rollouts)
+
+	un, err := ability.Action(workload.ActionRestart, ds)
+	assert.Nil(t, err)
+	restartedAt, _, _ := unstructured.NestedString(un.Object,
+		"spec", "template", "metadata", "annotations", workload.RestartedAtAnnotation)
+	assert.NotEmpty(t, restartedAt)
+}
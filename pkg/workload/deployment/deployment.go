@@ -64,6 +64,10 @@ func (*deployment) MatchGK(gk schema.GroupKind) bool {
 	return gk.Group == "apps" && gk.Kind == "Deployment"
 }
 
+func (*deployment) HibernatableGVR() (schema.GroupVersionResource, bool) {
+	return GVRDeployment, true
+}
+
 func (*deployment) getDeploy(node *v1alpha1.ResourceNode,
 	factory dynamicinformer.DynamicSharedInformerFactory) (*v1.Deployment, error) {
 	obj, err := factory.ForResource(GVRDeployment).Lister().ByNamespace(node.Namespace).Get(node.Name)
@@ -138,5 +142,11 @@ func (d *deployment) ListPods(node *v1alpha1.ResourceNode,
 }
 
 func (*deployment) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	switch actionName {
+	case workload.ActionHibernate:
+		return workload.Hibernate(un)
+	case workload.ActionWake:
+		return workload.Wake(un)
+	}
 	return un, nil
 }
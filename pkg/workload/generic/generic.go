@@ -0,0 +1,443 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generic
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	workloadconfig "github.com/horizoncd/horizon/pkg/config/workload"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/jsonpath"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	kjsonpath "k8s.io/client-go/util/jsonpath"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+var GVRPod = schema.GroupVersionResource{
+	Group:    "",
+	Version:  "v1",
+	Resource: "pods",
+}
+
+// ability interprets a definition, it lists pods, checks health and executes actions
+type ability struct {
+	definition *workloadconfig.Definition
+	gvr        schema.GroupVersionResource
+	actions    map[string][]*operation
+}
+
+// greyscaleAbility is the ability of definitions with steps
+type greyscaleAbility struct {
+	*ability
+}
+
+type operation struct {
+	op    string
+	keys  []string
+	value interface{}
+	tpl   *template.Template
+}
+
+var (
+	_ workload.PodsLister         = (*ability)(nil)
+	_ workload.HealthStatusGetter = (*ability)(nil)
+	_ workload.GreyscaleReleaser  = (*greyscaleAbility)(nil)
+	_ workload.Hibernator         = (*ability)(nil)
+)
+
+// New validates the definition and returns its ability
+func New(definition *workloadconfig.Definition) (workload.Workload, error) {
+	if definition == nil {
+		return nil, fmt.Errorf("definition cannot be empty")
+	}
+	if definition.Version == "" || definition.Kind == "" || definition.Resource == "" {
+		return nil, fmt.Errorf("version, kind and resource of definition cannot be empty")
+	}
+	name := schema.GroupKind{Group: definition.Group, Kind: definition.Kind}.String()
+	if definition.PodSelector == "" {
+		return nil, fmt.Errorf("podSelector of %s cannot be empty", name)
+	}
+	templates := []string{definition.PodSelector}
+	for _, condition := range definition.Healthy {
+		if condition == nil || condition.Path == "" {
+			return nil, fmt.Errorf("path of healthy conditions of %s cannot be empty", name)
+		}
+		switch condition.Operator {
+		case "", workloadconfig.OperatorEqual, workloadconfig.OperatorNotEqual,
+			workloadconfig.OperatorGreaterOrEqual, workloadconfig.OperatorLessOrEqual:
+			if condition.ValuePath != "" {
+				templates = append(templates, condition.ValuePath)
+			}
+		case workloadconfig.OperatorExists, workloadconfig.OperatorNotExists:
+		default:
+			return nil, fmt.Errorf("unknown operator %s of %s", condition.Operator, name)
+		}
+		templates = append(templates, condition.Path)
+	}
+	if steps := definition.Steps; steps != nil {
+		if steps.Replicas == "" || steps.Index == "" {
+			return nil, fmt.Errorf("replicas and index of steps of %s cannot be empty", name)
+		}
+		templates = append(templates, steps.Replicas, steps.Index)
+		for _, tpl := range []string{steps.Paused, steps.AutoPromote} {
+			if tpl != "" {
+				templates = append(templates, tpl)
+			}
+		}
+	}
+	for _, tpl := range templates {
+		if err := kjsonpath.New(name).Parse(tpl); err != nil {
+			return nil, fmt.Errorf("invalid jsonpath %s of %s: %v", tpl, name, err)
+		}
+	}
+
+	a := &ability{
+		definition: definition,
+		gvr: schema.GroupVersionResource{
+			Group:    definition.Group,
+			Version:  definition.Version,
+			Resource: definition.Resource,
+		},
+		actions: make(map[string][]*operation, len(definition.Actions)),
+	}
+	for action, operations := range definition.Actions {
+		if definition.Hibernatable && (action == workload.ActionHibernate || action == workload.ActionWake) {
+			return nil, fmt.Errorf("action %s of %s is reserved by hibernatable workloads", action, name)
+		}
+		for i, o := range operations {
+			op, err := newOperation(o)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d of action %s of %s is invalid: %v", i, action, name, err)
+			}
+			a.actions[action] = append(a.actions[action], op)
+		}
+	}
+	if definition.Steps != nil {
+		return &greyscaleAbility{ability: a}, nil
+	}
+	return a, nil
+}
+
+func newOperation(o *workloadconfig.Operation) (*operation, error) {
+	if o == nil {
+		return nil, fmt.Errorf("operation cannot be empty")
+	}
+	keys, err := jsonpath.Parse(o.Path)
+	if err != nil {
+		return nil, err
+	}
+	op := &operation{op: o.Op, keys: keys, value: o.Value}
+	switch o.Op {
+	case workloadconfig.OpSet:
+		if o.Value == nil {
+			return nil, fmt.Errorf("value of %s cannot be empty", o.Op)
+		}
+		if value, ok := o.Value.(string); ok && strings.Contains(value, "{{") {
+			if op.tpl, err = template.New(o.Path).Funcs(funcMap()).Parse(value); err != nil {
+				return nil, err
+			}
+		}
+	case workloadconfig.OpDelete:
+	default:
+		return nil, fmt.Errorf("unknown op %s", o.Op)
+	}
+	return op, nil
+}
+
+func (a *ability) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == a.definition.Group && gk.Kind == a.definition.Kind
+}
+
+func (a *ability) HibernatableGVR() (schema.GroupVersionResource, bool) {
+	return a.gvr, a.definition.Hibernatable
+}
+
+func (a *ability) getByNode(node *v1alpha1.ResourceNode, client *kube.Client) (*unstructured.Unstructured, error) {
+	un, err := client.Dynamic.Resource(a.gvr).Namespace(node.Namespace).
+		Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to get %s in k8s", a.definition.Kind)),
+			"failed to get %s in k8s: name = %s, ns = %v, err = %v", a.definition.Kind, node.Name, node.Namespace, err)
+	}
+	return un, nil
+}
+
+func (a *ability) IsHealthy(node *v1alpha1.ResourceNode, client *kube.Client) (bool, error) {
+	un, err := a.getByNode(node, client)
+	if err != nil {
+		return true, err
+	}
+	for _, condition := range a.definition.Healthy {
+		ok, err := check(un.Object, condition)
+		if err != nil {
+			return true, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (a *ability) ListPods(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	obj, err := factory.ForResource(a.gvr).Lister().ByNamespace(node.Namespace).Get(node.Name)
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to get %s in k8s: name = %s, ns = %v, err = %v",
+				a.definition.Kind, node.Name, node.Namespace, err))
+	}
+	un, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to convert obj to unstructured")
+	}
+	value, _, err := find(un.Object, a.definition.PodSelector)
+	if err != nil {
+		return nil, err
+	}
+	matchLabels, _ := value.(map[string]interface{})
+	if len(matchLabels) == 0 {
+		// an empty selector selects all pods in the namespace
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "pod selector of %s %s is empty",
+			a.definition.Kind, node.Name)
+	}
+	set := make(labels.Set, len(matchLabels))
+	for k, v := range matchLabels {
+		set[k] = fmt.Sprint(v)
+	}
+	objs, err := factory.ForResource(GVRPod).Lister().ByNamespace(node.Namespace).List(labels.SelectorFromSet(set))
+	if err != nil {
+		return nil, err
+	}
+	return workload.ObjIntoPod(objs...), nil
+}
+
+func (a *ability) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if a.definition.Hibernatable {
+		switch actionName {
+		case workload.ActionHibernate:
+			return workload.Hibernate(un)
+		case workload.ActionWake:
+			return workload.Wake(un)
+		}
+	}
+	operations, ok := a.actions[actionName]
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
+	}
+	for _, op := range operations {
+		if op.op == workloadconfig.OpDelete {
+			jsonpath.Delete(un.Object, op.keys)
+			continue
+		}
+		value, err := op.render(un.Object)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to render value of %s: %v",
+				jsonpath.Format(op.keys), err)
+		}
+		if err := jsonpath.Set(un.Object, op.keys, value); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set %s: %v", jsonpath.Format(op.keys), err)
+		}
+	}
+	return un, nil
+}
+
+func (g *greyscaleAbility) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*workload.Step, error) {
+	un, err := g.getByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+	steps := g.definition.Steps
+
+	value, _, err := find(un.Object, steps.Replicas)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := value.([]interface{})
+	replicas := make([]int, 0, len(items))
+	for _, item := range items {
+		n, err := toInt(item)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid replicas of steps: %v", err)
+		}
+		replicas = append(replicas, n)
+	}
+	if len(replicas) == 0 {
+		total, found, _ := unstructured.NestedInt64(un.Object, "spec", "replicas")
+		if !found {
+			total = 1
+		}
+		return &workload.Step{Index: 0, Total: 1, Replicas: []int{int(total)}}, nil
+	}
+
+	index := 0
+	if value, found, err := find(un.Object, steps.Index); err != nil {
+		return nil, err
+	} else if found {
+		if index, err = toInt(value); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid index of steps: %v", err)
+		}
+	}
+	if index > len(replicas) {
+		index = len(replicas)
+	}
+	paused, err := findBool(un.Object, steps.Paused)
+	if err != nil {
+		return nil, err
+	}
+	autoPromote, err := findBool(un.Object, steps.AutoPromote)
+	if err != nil {
+		return nil, err
+	}
+	return &workload.Step{
+		Index:        index,
+		Total:        len(replicas),
+		Replicas:     replicas,
+		ManualPaused: paused,
+		AutoPromote:  autoPromote,
+	}, nil
+}
+
+// render returns the value of the operation, templates are rendered with the object and parsed as yaml
+func (o *operation) render(obj map[string]interface{}) (interface{}, error) {
+	if o.tpl == nil {
+		return o.value, nil
+	}
+	var buf bytes.Buffer
+	if err := o.tpl.Execute(&buf, obj); err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := kyaml.Unmarshal(buf.Bytes(), &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// check checks the condition over the object
+func check(obj map[string]interface{}, condition *workloadconfig.Condition) (bool, error) {
+	value, found, err := find(obj, condition.Path)
+	if err != nil {
+		return false, err
+	}
+	switch condition.Operator {
+	case workloadconfig.OperatorExists:
+		return found && value != nil, nil
+	case workloadconfig.OperatorNotExists:
+		return !found || value == nil, nil
+	}
+
+	expected := condition.Value
+	if condition.ValuePath != "" {
+		value, _, err := find(obj, condition.ValuePath)
+		if err != nil {
+			return false, err
+		}
+		expected = format(value)
+	}
+	actual := format(value)
+	switch condition.Operator {
+	case workloadconfig.OperatorNotEqual:
+		return actual != expected, nil
+	case workloadconfig.OperatorGreaterOrEqual, workloadconfig.OperatorLessOrEqual:
+		a, errA := strconv.ParseFloat(actual, 64)
+		e, errE := strconv.ParseFloat(expected, 64)
+		if errA != nil || errE != nil {
+			return false, nil
+		}
+		if condition.Operator == workloadconfig.OperatorGreaterOrEqual {
+			return a >= e, nil
+		}
+		return a <= e, nil
+	default:
+		return actual == expected, nil
+	}
+}
+
+// find returns the first value of the jsonpath template in the object
+func find(obj map[string]interface{}, tpl string) (interface{}, bool, error) {
+	j := kjsonpath.New("").AllowMissingKeys(true)
+	if err := j.Parse(tpl); err != nil {
+		return nil, false, err
+	}
+	results, err := j.FindResults(obj)
+	if err != nil {
+		return nil, false, perror.Wrapf(herrors.ErrParamInvalid, "failed to find %s: %v", tpl, err)
+	}
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() {
+				return value.Interface(), true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+func findBool(obj map[string]interface{}, tpl string) (bool, error) {
+	if tpl == "" {
+		return false, nil
+	}
+	value, _, err := find(obj, tpl)
+	if err != nil {
+		return false, err
+	}
+	return format(value) == "true", nil
+}
+
+func format(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func toInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int64:
+		return int(v), nil
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	default:
+		return strconv.Atoi(format(value))
+	}
+}
+
+// funcMap returns the sprig functions without the ones accessing the environment
+func funcMap() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+	return funcs
+}
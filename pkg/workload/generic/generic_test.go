@@ -0,0 +1,278 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	kyaml "sigs.k8s.io/yaml"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	workloadconfig "github.com/horizoncd/horizon/pkg/config/workload"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	tmodels "github.com/horizoncd/horizon/pkg/template/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+const _definitions = `
+definitions:
+- group: apps.kruise.io
+  version: v1alpha1
+  kind: CloneSet
+  resource: clonesets
+  podSelector: '{.spec.selector.matchLabels}'
+  healthy:
+  - path: '{.status.observedGeneration}'
+    valuePath: '{.metadata.generation}'
+  - path: '{.status.updatedReadyReplicas}'
+    operator: GreaterOrEqual
+    valuePath: '{.spec.replicas}'
+  steps:
+    replicas: '{.spec.updateStrategy.steps}'
+    index: '{.status.currentStepIndex}'
+    paused: '{.spec.updateStrategy.paused}'
+  hibernatable: true
+  actions:
+    pause:
+    - op: set
+      path: spec.updateStrategy.paused
+      value: true
+    restart:
+    - op: set
+      path: spec.template.metadata.annotations["kubectl.kubernetes.io/restartedAt"]
+      value: '{{ now | date "2006-01-02" }}'
+    - op: delete
+      path: spec.updateStrategy.paused
+`
+
+const _cloneSet = `
+apiVersion: apps.kruise.io/v1alpha1
+kind: CloneSet
+metadata:
+  name: demo
+  namespace: test
+  generation: 2
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: demo
+  updateStrategy:
+    paused: true
+    steps: [1, 2]
+status:
+  observedGeneration: 2
+  updatedReadyReplicas: 3
+  currentStepIndex: 1
+`
+
+func cloneSet(t *testing.T) *unstructured.Unstructured {
+	content, err := kyaml.YAMLToJSON([]byte(_cloneSet))
+	assert.Nil(t, err)
+	un := &unstructured.Unstructured{}
+	assert.Nil(t, un.UnmarshalJSON(content))
+	return un
+}
+
+func TestParse(t *testing.T) {
+	definitions, err := Parse([]byte(_definitions))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(definitions))
+
+	invalids := map[string]string{
+		"unknown field":       "definitions:\n- kind: A\n  replicas: 1\n",
+		"missing resource":    "definitions:\n- group: example.com\n  version: v1\n  kind: A\n  podSelector: '{.a}'\n",
+		"missing podSelector": "definitions:\n- group: example.com\n  version: v1\n  kind: A\n  resource: as\n",
+		"invalid jsonpath":    "definitions:\n- group: example.com\n  version: v1\n  kind: A\n  resource: as\n  podSelector: '{.a'\n",
+		"unknown operator": "definitions:\n- group: example.com\n  version: v1\n  kind: A\n  resource: as\n  podSelector: '{.a}'\n" +
+			"  healthy:\n  - path: '{.b}'\n    operator: Like\n",
+		"steps without index": "definitions:\n- group: example.com\n  version: v1\n  kind: A\n  resource: as\n  podSelector: '{.a}'\n" +
+			"  steps:\n    replicas: '{.b}'\n",
+		"unknown op": "definitions:\n- group: example.com\n  version: v1\n  kind: A\n  resource: as\n  podSelector: '{.a}'\n" +
+			"  actions:\n    pause:\n    - op: patch\n      path: spec.paused\n",
+		"reserved action": "definitions:\n- group: example.com\n  version: v1\n  kind: A\n  resource: as\n  podSelector: '{.a}'\n" +
+			"  hibernatable: true\n  actions:\n    wake:\n    - op: delete\n      path: spec.paused\n",
+		"core group": "definitions:\n- version: v1\n  kind: Secret\n  resource: secrets\n  podSelector: '{.a}'\n",
+		"apps group": "definitions:\n- group: apps\n  version: v1\n  kind: A\n  resource: as\n  podSelector: '{.a}'\n",
+		"k8s.io group": "definitions:\n- group: rbac.authorization.k8s.io\n  version: v1\n  kind: Role\n" +
+			"  resource: roles\n  podSelector: '{.a}'\n",
+	}
+	for name, content := range invalids {
+		_, err := Parse([]byte(content))
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestAbility(t *testing.T) {
+	definitions, err := Parse([]byte(_definitions))
+	assert.Nil(t, err)
+	ability, err := New(definitions[0])
+	assert.Nil(t, err)
+	assert.True(t, ability.MatchGK(schema.GroupKind{Group: "apps.kruise.io", Kind: "CloneSet"}))
+	assert.False(t, ability.MatchGK(schema.GroupKind{Group: "apps", Kind: "CloneSet"}))
+
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{Namespace: "test", Name: "demo"}}
+	un := cloneSet(t)
+	client := &kube.Client{Dynamic: fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), un)}
+
+	healthy, err := ability.(workload.HealthStatusGetter).IsHealthy(node, client)
+	assert.Nil(t, err)
+	assert.True(t, healthy)
+
+	steps, err := ability.(workload.GreyscaleReleaser).GetSteps(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, &workload.Step{Index: 1, Total: 2, Replicas: []int{1, 2}, ManualPaused: true}, steps)
+
+	// unhealthy until the new generation is observed
+	unhealthy := cloneSet(t)
+	unhealthy.SetGeneration(3)
+	client = &kube.Client{Dynamic: fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), unhealthy)}
+	healthy, err = ability.(workload.HealthStatusGetter).IsHealthy(node, client)
+	assert.Nil(t, err)
+	assert.False(t, healthy)
+
+	un, err = ability.Action("restart", cloneSet(t))
+	assert.Nil(t, err)
+	restartedAt, _, _ := unstructured.NestedString(un.Object,
+		"spec", "template", "metadata", "annotations", "kubectl.kubernetes.io/restartedAt")
+	assert.Equal(t, time.Now().Format("2006-01-02"), restartedAt)
+	_, found, _ := unstructured.NestedBool(un.Object, "spec", "updateStrategy", "paused")
+	assert.False(t, found)
+
+	gvr, ok := ability.(workload.Hibernator).HibernatableGVR()
+	assert.True(t, ok)
+	assert.Equal(t, schema.GroupVersionResource{Group: "apps.kruise.io", Version: "v1alpha1", Resource: "clonesets"}, gvr)
+
+	un, err = ability.Action(workload.ActionHibernate, cloneSet(t))
+	assert.Nil(t, err)
+	replicas, _, _ := unstructured.NestedInt64(un.Object, "spec", "replicas")
+	assert.Equal(t, int64(0), replicas)
+
+	_, err = ability.Action("promote", cloneSet(t))
+	assert.NotNil(t, err)
+}
+
+func TestListPods(t *testing.T) {
+	definitions, err := Parse([]byte(_definitions))
+	assert.Nil(t, err)
+	ability, err := New(definitions[0])
+	assert.Nil(t, err)
+
+	pod := func(name, app string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "test",
+				"labels":    map[string]interface{}{"app": app},
+			},
+		}}
+	}
+	gvr := schema.GroupVersionResource{Group: "apps.kruise.io", Version: "v1alpha1", Resource: "clonesets"}
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CloneSetList", GVRPod: "PodList"},
+		cloneSet(t), pod("demo-1", "demo"), pod("other-1", "other"))
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	factory.ForResource(gvr)
+	factory.ForResource(GVRPod)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{Namespace: "test", Name: "demo"}}
+	pods, err := ability.(workload.PodsLister).ListPods(node, factory)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pods))
+	assert.Equal(t, "demo-1", pods[0].Name)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(nil)
+	gk := schema.GroupKind{Group: "example.com", Kind: "Worker"}
+	_, err := workload.GetAbility(gk)
+	assert.NotNil(t, err)
+
+	definition := &workloadconfig.Definition{
+		Group:       "example.com",
+		Version:     "v1",
+		Kind:        "Worker",
+		Resource:    "workers",
+		PodSelector: "{.spec.selector.matchLabels}",
+	}
+	assert.Nil(t, registry.Register(context.TODO(), definition))
+	ability, err := workload.GetAbility(gk)
+	assert.Nil(t, err)
+
+	// kinds with abilities are skipped
+	assert.Nil(t, registry.Register(context.TODO(), &workloadconfig.Definition{
+		Group: "example.com", Version: "v2", Kind: "Worker", Resource: "workers", PodSelector: "{.spec.labels}",
+	}))
+	again, err := workload.GetAbility(gk)
+	assert.Nil(t, err)
+	assert.Equal(t, ability, again)
+
+	assert.NotNil(t, registry.Register(context.TODO(), &workloadconfig.Definition{Kind: "Invalid"}))
+}
+
+func TestRegisterTemplateReleases(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&tmodels.Template{}, &trmodels.TemplateRelease{}))
+	manager := managerparam.InitManager(db)
+	ctx := context.TODO()
+	template, err := manager.TemplateMgr.Create(ctx, &tmodels.Template{Name: "worker", ChartName: "worker"})
+	assert.Nil(t, err)
+	_, err = manager.TemplateReleaseMgr.Create(ctx, &trmodels.TemplateRelease{
+		Template:     template.ID,
+		TemplateName: template.Name,
+		Name:         "v1.0.0",
+		ChartName:    "worker",
+		ChartVersion: "v1.0.0",
+	})
+	assert.Nil(t, err)
+
+	definitions := "definitions:\n- group: example.com\n  version: v1\n  kind: Runner\n" +
+		"  resource: runners\n  podSelector: '{.spec.selector.matchLabels}'\n  hibernatable: true\n"
+	mockCtl := gomock.NewController(t)
+	repo := repomock.NewMockTemplateRepo(mockCtl)
+	// charts are loaded once
+	repo.EXPECT().GetChart("worker", "v1.0.0", gomock.Any()).Return(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "worker"},
+		Files:    []*chart.File{{Name: Path, Data: []byte(definitions)}},
+	}, nil).Times(1)
+
+	registry := NewRegistry(nil)
+	registry.RegisterTemplateReleases(ctx, manager, repo)
+	registry.RegisterTemplateReleases(ctx, manager, repo)
+	ability, err := workload.GetAbility(schema.GroupKind{Group: "example.com", Kind: "Runner"})
+	assert.Nil(t, err)
+	_, ok := ability.(workload.Hibernator).HibernatableGVR()
+	assert.True(t, ok)
+}
@@ -0,0 +1,198 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kyaml "sigs.k8s.io/yaml"
+
+	workloadconfig "github.com/horizoncd/horizon/pkg/config/workload"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+// Path is the path of the workload definitions file in charts, its format is the same as the workload config
+const Path = "workloads/definitions.yaml"
+
+// Parse parses and validates the workload definitions file. Kinds of kubernetes groups cannot be defined
+// in charts, since actions of the abilities are executed on the objects, such as secrets.
+func Parse(content []byte) ([]*workloadconfig.Definition, error) {
+	var config workloadconfig.Config
+	if err := kyaml.UnmarshalStrict(content, &config); err != nil {
+		return nil, err
+	}
+	for _, definition := range config.Definitions {
+		if _, err := New(definition); err != nil {
+			return nil, err
+		}
+		if isKubernetesGroup(definition.Group) {
+			return nil, fmt.Errorf("kind %s of kubernetes group %q cannot be defined",
+				definition.Kind, definition.Group)
+		}
+	}
+	return config.Definitions, nil
+}
+
+func isKubernetesGroup(group string) bool {
+	switch group {
+	case "", "apps", "batch", "autoscaling", "policy", "extensions":
+		return true
+	}
+	return group == "k8s.io" || strings.HasSuffix(group, ".k8s.io")
+}
+
+// Registry registers the abilities of definitions at runtime, and watches their resources in regions
+type Registry struct {
+	informers *regioninformers.RegionInformers
+
+	// mu makes checking and registering abilities atomic
+	mu sync.Mutex
+	// loaded are the charts whose definitions are loaded by RegisterTemplateReleases
+	loaded map[string]bool
+}
+
+func NewRegistry(informers *regioninformers.RegionInformers) *Registry {
+	return &Registry{informers: informers, loaded: make(map[string]bool)}
+}
+
+// Register registers the abilities of the definitions. Definitions of kinds which already have abilities
+// are skipped, so abilities in go code and the earlier definitions win.
+func (r *Registry) Register(ctx context.Context, definitions ...*workloadconfig.Definition) error {
+	abilities := make([]workload.Workload, 0, len(definitions))
+	for _, definition := range definitions {
+		ability, err := New(definition)
+		if err != nil {
+			return err
+		}
+		abilities = append(abilities, ability)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resources := make([]regioninformers.Resource, 0, len(definitions))
+	for i, ability := range abilities {
+		definition := definitions[i]
+		gk := schema.GroupKind{Group: definition.Group, Kind: definition.Kind}
+		if _, err := workload.GetAbility(gk); err == nil {
+			log.Debugf(ctx, "workload ability of %s exists, the definition is skipped", gk.String())
+			continue
+		}
+		gvr := schema.GroupVersionResource{
+			Group:    definition.Group,
+			Version:  definition.Version,
+			Resource: definition.Resource,
+		}
+		workload.Register(ability, gvr, GVRPod)
+		resources = append(resources, regioninformers.Resource{GVR: gvr})
+		log.Infof(ctx, "workload ability of %s is registered", gk.String())
+	}
+	if len(resources) > 0 && r.informers != nil {
+		r.informers.Register(resources...)
+	}
+	return nil
+}
+
+// RegisterChart registers the workload definitions shipped in the chart
+func (r *Registry) RegisterChart(ctx context.Context, chrt *chart.Chart) error {
+	for _, file := range chrt.Files {
+		if file.Name != Path {
+			continue
+		}
+		definitions, err := Parse(file.Data)
+		if err != nil {
+			return fmt.Errorf("invalid %s of chart %s: %v", Path, chrt.Name(), err)
+		}
+		return r.Register(ctx, definitions...)
+	}
+	return nil
+}
+
+// WatchTemplateReleases registers the workload definitions shipped in the charts of template releases
+// periodically, so the charts published on any instance are supported by all instances
+func (r *Registry) WatchTemplateReleases(ctx context.Context, m *managerparam.Manager,
+	repo templaterepo.TemplateRepo, pollInterval time.Duration) {
+	r.RegisterTemplateReleases(ctx, m, repo)
+	err := wait.Poll(pollInterval, 0, func() (done bool, err error) {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		default:
+		}
+
+		r.RegisterTemplateReleases(ctx, m, repo)
+		return false, nil
+	})
+	if err != nil {
+		log.Errorf(ctx, "WatchTemplateReleases polling error: %v", err)
+	}
+}
+
+// RegisterTemplateReleases registers the workload definitions shipped in the charts of all template releases,
+// failures are logged and skipped. Charts which are loaded already are skipped unless they are synced again.
+func (r *Registry) RegisterTemplateReleases(ctx context.Context, m *managerparam.Manager,
+	repo templaterepo.TemplateRepo) {
+	templates, err := m.TemplateMgr.ListTemplate(ctx)
+	if err != nil {
+		log.Errorf(ctx, "failed to list templates: %v", err)
+		return
+	}
+	for _, template := range templates {
+		releases, err := m.TemplateReleaseMgr.ListByTemplateID(ctx, template.ID)
+		if err != nil {
+			log.Errorf(ctx, "failed to list releases of template %s: %v", template.Name, err)
+			continue
+		}
+		for _, release := range releases {
+			key := fmt.Sprintf("%s-%s-%d", release.ChartName, release.ChartVersion, release.LastSyncAt.Unix())
+			if r.isLoaded(key) {
+				continue
+			}
+			chrt, err := repo.GetChart(release.ChartName, release.ChartVersion, release.LastSyncAt)
+			if err != nil {
+				log.Warningf(ctx, "failed to get chart of template %s release %s: %v",
+					template.Name, release.Name, err)
+				continue
+			}
+			if err := r.RegisterChart(ctx, chrt); err != nil {
+				log.Warningf(ctx, "failed to register workloads of template %s release %s: %v",
+					template.Name, release.Name, err)
+			}
+			r.setLoaded(key)
+		}
+	}
+}
+
+func (r *Registry) isLoaded(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.loaded[key]
+}
+
+func (r *Registry) setLoaded(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loaded[key] = true
+}
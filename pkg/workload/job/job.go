@@ -0,0 +1,211 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+)
+
+var (
+	GVRJob = schema.GroupVersionResource{
+		Group:    "batch",
+		Version:  "v1",
+		Resource: "jobs",
+	}
+	GVRPod = schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "pods",
+	}
+)
+
+func init() {
+	workload.Register(ability, GVRJob, GVRPod)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &job{}
+
+type job struct{}
+
+func (*job) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "batch" && gk.Kind == "Job"
+}
+
+func (*job) getJob(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) (*unstructured.Unstructured, error) {
+	obj, err := factory.ForResource(GVRJob).Lister().ByNamespace(node.Namespace).Get(node.Name)
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to get job in k8s: job = %s, ns = %v, err = %v",
+				node.Name, node.Namespace, err))
+	}
+	un, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to convert obj into unstructured: name = %s, ns = %v",
+				node.Name, node.Namespace))
+	}
+	return un, nil
+}
+
+func (*job) getJobByNode(node *v1alpha1.ResourceNode, client *kube.Client) (*batchv1.Job, error) {
+	instance, err := client.Basic.BatchV1().Jobs(node.Namespace).Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get job in k8s"),
+			"failed to get job in k8s: job = %s, ns = %v, err = %v", node.Name, node.Namespace, err)
+	}
+	return instance, nil
+}
+
+// IsHealthy returns whether the job completes, jobs created by cronjobs are always healthy
+// since their statuses are shown in the runs of the cronjobs
+func (j *job) IsHealthy(node *v1alpha1.ResourceNode,
+	client *kube.Client) (bool, error) {
+	instance, err := j.getJobByNode(node, client)
+	if err != nil {
+		return true, err
+	}
+	for _, owner := range instance.OwnerReferences {
+		if owner.Kind == "CronJob" {
+			return true, nil
+		}
+	}
+	completions := int32(1)
+	if instance.Spec.Completions != nil {
+		completions = *instance.Spec.Completions
+	}
+	return instance.Status.Succeeded >= completions, nil
+}
+
+func (j *job) ListPods(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	un, err := j.getJob(node, factory)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := FromUnstructured(un)
+	if err != nil {
+		return nil, err
+	}
+	return ListPodsOf(instance, factory)
+}
+
+func (j *job) ListRuns(node *v1alpha1.ResourceNode,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]workload.Run, error) {
+	un, err := j.getJob(node, factory)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := FromUnstructured(un)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := ListPodsOf(instance, factory)
+	if err != nil {
+		return nil, err
+	}
+	return []workload.Run{RunOf(un, instance, pods)}, nil
+}
+
+func (*job) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	switch actionName {
+	case workload.ActionSuspend:
+		return workload.Suspend(un, true)
+	case workload.ActionResume:
+		return workload.Suspend(un, false)
+	}
+	return un, nil
+}
+
+// ListPodsOf lists the pods of the job
+func ListPodsOf(instance *batchv1.Job,
+	factory dynamicinformer.DynamicSharedInformerFactory) ([]corev1.Pod, error) {
+	if instance.Spec.Selector == nil || len(instance.Spec.Selector.MatchLabels) == 0 {
+		return []corev1.Pod{}, nil
+	}
+	selector := labels.SelectorFromSet(instance.Spec.Selector.MatchLabels)
+	objs, err := factory.ForResource(GVRPod).Lister().ByNamespace(instance.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return workload.ObjIntoPod(objs...), nil
+}
+
+// RunOf returns the run of the job, spec.suspend is read from the unstructured job
+// since it's missing in the job type of this client
+func RunOf(un *unstructured.Unstructured, instance *batchv1.Job, pods []corev1.Pod) workload.Run {
+	run := workload.Run{
+		Name:      instance.Name,
+		Status:    workload.RunStatusRunning,
+		Active:    int(instance.Status.Active),
+		Succeeded: int(instance.Status.Succeeded),
+		Failed:    int(instance.Status.Failed),
+		Pods:      make([]string, 0, len(pods)),
+	}
+	if instance.Status.StartTime != nil {
+		run.StartTime = &instance.Status.StartTime.Time
+	}
+	if instance.Status.CompletionTime != nil {
+		run.CompletionTime = &instance.Status.CompletionTime.Time
+	}
+	for _, condition := range instance.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			run.Status = workload.RunStatusSucceeded
+		case batchv1.JobFailed:
+			run.Status = workload.RunStatusFailed
+		}
+	}
+	if suspended, _, _ := unstructured.NestedBool(un.Object, "spec", "suspend"); suspended &&
+		run.Status == workload.RunStatusRunning {
+		run.Status = workload.RunStatusSuspended
+	}
+	for _, pod := range pods {
+		run.Pods = append(run.Pods, pod.Name)
+	}
+	return run
+}
+
+// FromUnstructured converts the unstructured job into the job type
+func FromUnstructured(un *unstructured.Unstructured) (*batchv1.Job, error) {
+	instance := &batchv1.Job{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, instance); err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to convert unstructured into job: name = %s, ns = %v, err = %v",
+				un.GetName(), un.GetNamespace(), err))
+	}
+	return instance, nil
+}
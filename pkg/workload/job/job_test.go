@@ -0,0 +1,160 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"testing"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+func jobObj(suspend bool, conditionType string) *unstructured.Unstructured {
+	status := map[string]interface{}{
+		"active":    int64(1),
+		"startTime": "2022-01-01T00:00:00Z",
+	}
+	if conditionType != "" {
+		status["conditions"] = []interface{}{map[string]interface{}{
+			"type":   conditionType,
+			"status": "True",
+		}}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":      "demo",
+			"namespace": "test",
+		},
+		"spec": map[string]interface{}{
+			"suspend": suspend,
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"controller-uid": "demo"},
+			},
+		},
+		"status": status,
+	}}
+}
+
+func podObj(name, controllerUID string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "test",
+			"labels":    map[string]interface{}{"controller-uid": controllerUID},
+		},
+	}}
+}
+
+func factoryOf(t *testing.T, objects ...runtime.Object) dynamicinformer.DynamicSharedInformerFactory {
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{GVRJob: "JobList", GVRPod: "PodList"}, objects...)
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	factory.ForResource(GVRJob)
+	factory.ForResource(GVRPod)
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+	return factory
+}
+
+func TestListRuns(t *testing.T) {
+	gk := schema.GroupKind{Group: "batch", Kind: "Job"}
+	a, err := workload.GetAbility(gk)
+	assert.Nil(t, err)
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "batch", Version: "v1", Kind: "Job", Namespace: "test", Name: "demo",
+	}}
+
+	factory := factoryOf(t, jobObj(false, ""), podObj("demo-abc", "demo"), podObj("other-abc", "other"))
+	runs, err := a.(workload.RunsLister).ListRuns(node, factory)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(runs))
+	assert.Equal(t, "demo", runs[0].Name)
+	assert.Equal(t, workload.RunStatusRunning, runs[0].Status)
+	assert.Equal(t, 1, runs[0].Active)
+	assert.NotNil(t, runs[0].StartTime)
+	assert.Equal(t, []string{"demo-abc"}, runs[0].Pods)
+
+	pods, err := a.(workload.PodsLister).ListPods(node, factory)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pods))
+
+	runs, err = a.(workload.RunsLister).ListRuns(node, factoryOf(t, jobObj(true, "")))
+	assert.Nil(t, err)
+	assert.Equal(t, workload.RunStatusSuspended, runs[0].Status)
+
+	runs, err = a.(workload.RunsLister).ListRuns(node, factoryOf(t, jobObj(false, "Failed")))
+	assert.Nil(t, err)
+	assert.Equal(t, workload.RunStatusFailed, runs[0].Status)
+
+	_, err = a.(workload.RunsLister).ListRuns(node, factoryOf(t))
+	assert.NotNil(t, err)
+}
+
+func TestIsHealthy(t *testing.T) {
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{Namespace: "test", Name: "demo"}}
+	completions := int32(2)
+	instance := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "test"},
+		Spec:       batchv1.JobSpec{Completions: &completions},
+		Status:     batchv1.JobStatus{Succeeded: 1},
+	}
+	healthy, err := ability.IsHealthy(node, &kube.Client{Basic: fake.NewSimpleClientset(instance)})
+	assert.Nil(t, err)
+	assert.False(t, healthy)
+
+	instance.Status.Succeeded = 2
+	healthy, err = ability.IsHealthy(node, &kube.Client{Basic: fake.NewSimpleClientset(instance)})
+	assert.Nil(t, err)
+	assert.True(t, healthy)
+
+	// jobs of cronjobs are always healthy
+	instance.Status.Succeeded = 0
+	instance.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: "demo"}}
+	healthy, err = ability.IsHealthy(node, &kube.Client{Basic: fake.NewSimpleClientset(instance)})
+	assert.Nil(t, err)
+	assert.True(t, healthy)
+
+	_, err = ability.IsHealthy(node, &kube.Client{Basic: fake.NewSimpleClientset()})
+	assert.NotNil(t, err)
+}
+
+func TestAction(t *testing.T) {
+	un, err := ability.Action(workload.ActionSuspend, jobObj(false, ""))
+	assert.Nil(t, err)
+	suspended, _, _ := unstructured.NestedBool(un.Object, "spec", "suspend")
+	assert.True(t, suspended)
+
+	un, err = ability.Action(workload.ActionResume, un)
+	assert.Nil(t, err)
+	suspended, _, _ = unstructured.NestedBool(un.Object, "spec", "suspend")
+	assert.False(t, suspended)
+}
@@ -19,6 +19,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	rolloutsv1alpha1 "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
@@ -33,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/kubectl/pkg/polymorphichelpers"
@@ -69,6 +72,10 @@ func (*rollout) MatchGK(gk schema.GroupKind) bool {
 	return gk.Group == "argoproj.io" && gk.Kind == "Rollout"
 }
 
+func (*rollout) HibernatableGVR() (schema.GroupVersionResource, bool) {
+	return GVRRollout, true
+}
+
 func (*rollout) getRollout(node *v1alpha1.ResourceNode,
 	rolloutInformer informers.GenericInformer) (*rolloutsv1alpha1.Rollout, *unstructured.Unstructured, error) {
 	obj, err := rolloutInformer.Lister().ByNamespace(node.Namespace).Get(node.Name)
@@ -171,6 +178,13 @@ OUTTER:
 		return false, nil
 	}
 
+	if instance.Spec.Strategy.BlueGreen != nil {
+		log.Debugf(context.TODO(),
+			"[workload rollout: %v]: active selector = %v, current pod hash = %v",
+			node.Name, instance.Status.BlueGreen.ActiveSelector, instance.Status.CurrentPodHash)
+		return instance.Status.BlueGreen.ActiveSelector == instance.Status.CurrentPodHash, nil
+	}
+
 	if instance.Status.CurrentStepIndex != nil {
 		log.Debugf(context.TODO(),
 			"[workload rollout: %v]: current step = %v, total steps = %v",
@@ -215,6 +229,10 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 		replicasTotal = int(*instance.Spec.Replicas)
 	}
 
+	if instance.Spec.Strategy.BlueGreen != nil {
+		return r.getBlueGreenSteps(instance, replicasTotal, client)
+	}
+
 	if instance.Spec.Strategy.Canary == nil ||
 		len(instance.Spec.Strategy.Canary.Steps) == 0 {
 		return &workload.Step{
@@ -277,7 +295,155 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 	}, nil
 }
 
+// getBlueGreenSteps regards a blue-green release as one step, which is finished after the preview is promoted
+func (r *rollout) getBlueGreenSteps(instance *rolloutsv1alpha1.Rollout,
+	replicasTotal int, client *kube.Client) (*workload.Step, error) {
+	strategy := instance.Spec.Strategy.BlueGreen
+	activeHash := instance.Status.BlueGreen.ActiveSelector
+	previewHash := instance.Status.CurrentPodHash
+	blueGreen := &workload.BlueGreen{
+		ActiveService:  strategy.ActiveService,
+		PreviewService: strategy.PreviewService,
+		Promoted:       activeHash != "" && activeHash == previewHash,
+		Aborted:        instance.Status.Abort,
+	}
+
+	var err error
+	if blueGreen.ActiveReplicaSet, blueGreen.ActivePods, err =
+		r.listPodsOfHash(instance, activeHash, client); err != nil {
+		return nil, err
+	}
+	if blueGreen.PreviewReplicaSet, blueGreen.PreviewPods, err =
+		r.listPodsOfHash(instance, previewHash, client); err != nil {
+		return nil, err
+	}
+	if strategy.PreviewService != "" {
+		blueGreen.PreviewEndpoint = previewEndpoint(instance.Namespace, strategy.PreviewService, client)
+	}
+
+	// auto promotion is enabled by default, the rollout is paused for AutoPromotionSeconds before promoting
+	autoPromote := strategy.AutoPromotionEnabled == nil || *strategy.AutoPromotionEnabled
+	if autoPromote && strategy.AutoPromotionSeconds > 0 && !blueGreen.Promoted {
+		for _, condition := range instance.Status.PauseConditions {
+			if condition.Reason == rolloutsv1alpha1.PauseReasonBlueGreenPause {
+				promoteAt := condition.StartTime.Add(time.Duration(strategy.AutoPromotionSeconds) * time.Second)
+				blueGreen.AutoPromoteAt = &promoteAt
+			}
+		}
+	}
+
+	index := 0
+	if blueGreen.Promoted {
+		index = 1
+	}
+	return &workload.Step{
+		Index:        index,
+		Total:        1,
+		Replicas:     []int{replicasTotal},
+		ManualPaused: instance.Spec.Paused,
+		AutoPromote:  autoPromote,
+		BlueGreen:    blueGreen,
+	}, nil
+}
+
+// listPodsOfHash returns the replicaset and the names of its pods of the pod template hash
+func (*rollout) listPodsOfHash(instance *rolloutsv1alpha1.Rollout,
+	hash string, client *kube.Client) (string, []string, error) {
+	if hash == "" {
+		return "", []string{}, nil
+	}
+	selector := labels.Set{}
+	for k, v := range instance.Spec.Template.ObjectMeta.Labels {
+		selector[k] = v
+	}
+	selector[rolloutsv1alpha1.DefaultRolloutUniqueLabelKey] = hash
+	pods, err := client.Basic.CoreV1().Pods(instance.Namespace).
+		List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String(), ResourceVersion: "0"})
+	if err != nil {
+		return "", nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S, "failed to list pods in k8s"),
+			"failed to list pods of rollout %s with hash %s: %v", instance.Name, hash, err)
+	}
+	names := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("%s-%s", instance.Name, hash), names, nil
+}
+
+// previewEndpoint returns the address of the preview service in the k8s cluster,
+// the port is omitted if the service is not found
+func previewEndpoint(namespace, service string, client *kube.Client) string {
+	host := fmt.Sprintf("%s.%s.svc", service, namespace)
+	svc, err := client.Basic.CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+	if err != nil {
+		log.Warningf(context.TODO(), "failed to get preview service %s/%s: %v", namespace, service, err)
+		return host
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return host
+	}
+	return fmt.Sprintf("%s:%d", host, svc.Spec.Ports[0].Port)
+}
+
+// UpdateDependents scales down the previous replicaset of a blue-green rollout
+// by moving its scale down deadline to now
+func (r *rollout) UpdateDependents(ctx context.Context, actionName string,
+	un *unstructured.Unstructured, client dynamic.Interface) (bool, error) {
+	if actionName != workload.ActionScaleDownPrevious {
+		return false, nil
+	}
+	replicaSets, err := client.Resource(GVRReplicaSet).Namespace(un.GetNamespace()).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return true, herrors.NewErrGetFailed(herrors.ResourceInK8S,
+			fmt.Sprintf("failed to list replicasets of rollout %s: %v", un.GetName(), err))
+	}
+	scaled := 0
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if !ownedBy(rs, un) {
+			continue
+		}
+		annotations := rs.GetAnnotations()
+		if _, ok := annotations[rolloutsv1alpha1.DefaultReplicaSetScaleDownDeadlineAnnotationKey]; !ok {
+			continue
+		}
+		annotations[rolloutsv1alpha1.DefaultReplicaSetScaleDownDeadlineAnnotationKey] =
+			time.Now().UTC().Format(time.RFC3339)
+		rs.SetAnnotations(annotations)
+		if _, err := client.Resource(GVRReplicaSet).Namespace(rs.GetNamespace()).
+			Update(ctx, rs, metav1.UpdateOptions{}); err != nil {
+			return true, herrors.NewErrUpdateFailed(herrors.ResourceInK8S,
+				fmt.Sprintf("failed to update replicaset %s: %v", rs.GetName(), err))
+		}
+		scaled++
+	}
+	if scaled == 0 {
+		return true, perror.Wrapf(herrors.ErrParamInvalid,
+			"no previous replicaset of rollout %s is waiting to be scaled down", un.GetName())
+	}
+	return true, nil
+}
+
+func ownedBy(obj, owner *unstructured.Unstructured) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *rollout) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	switch actionName {
+	case workload.ActionHibernate:
+		return workload.Hibernate(un)
+	case workload.ActionWake:
+		return workload.Wake(un)
+	}
+
 	var instance *rolloutsv1alpha1.Rollout
 	err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.UnstructuredContent(), &instance)
 	if err != nil {
@@ -298,10 +464,13 @@ func (r *rollout) Action(actionName string, un *unstructured.Unstructured) (*uns
 	case "pause":
 		spec["paused"] = true
 	case "promote-full":
-		steps := int32(len(instance.Spec.Strategy.Canary.Steps))
 		spec["paused"] = false
 		delete(status, "pauseConditions")
-		status["currentStepIndex"] = steps
+		if instance.Spec.Strategy.Canary != nil {
+			status["currentStepIndex"] = int64(len(instance.Spec.Strategy.Canary.Steps))
+		} else {
+			status["promoteFull"] = true
+		}
 	case "promote":
 		spec["paused"] = false
 		delete(status, "pauseConditions")
@@ -311,6 +480,8 @@ func (r *rollout) Action(actionName string, un *unstructured.Unstructured) (*uns
 		spec["paused"] = false
 	case "cancel-auto-promote":
 		delete(status, "autoPromote")
+	case workload.ActionAbort:
+		status["abort"] = true
 	default:
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
 	}
@@ -0,0 +1,175 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	rolloutsv1alpha1 "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+)
+
+func blueGreenRollout(t *testing.T, pausedAt time.Time) *unstructured.Unstructured {
+	replicas := int32(2)
+	instance := &rolloutsv1alpha1.Rollout{
+		TypeMeta: metav1.TypeMeta{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: "test",
+			UID:       "rollout-uid",
+		},
+		Spec: rolloutsv1alpha1.RolloutSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+			},
+			Strategy: rolloutsv1alpha1.RolloutStrategy{
+				BlueGreen: &rolloutsv1alpha1.BlueGreenStrategy{
+					ActiveService:        "demo",
+					PreviewService:       "demo-preview",
+					AutoPromotionSeconds: 60,
+				},
+			},
+		},
+		Status: rolloutsv1alpha1.RolloutStatus{
+			CurrentPodHash: "new",
+			BlueGreen: rolloutsv1alpha1.BlueGreenStatus{
+				ActiveSelector:  "old",
+				PreviewSelector: "new",
+			},
+			PauseConditions: []rolloutsv1alpha1.PauseCondition{{
+				Reason:    rolloutsv1alpha1.PauseReasonBlueGreenPause,
+				StartTime: metav1.NewTime(pausedAt),
+			}},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(instance)
+	assert.Nil(t, err)
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func pod(name, hash string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: "test",
+		Labels:    map[string]string{"app": "demo", rolloutsv1alpha1.DefaultRolloutUniqueLabelKey: hash},
+	}}
+}
+
+func TestBlueGreenSteps(t *testing.T) {
+	pausedAt := time.Now().Truncate(time.Second)
+	un := blueGreenRollout(t, pausedAt)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-preview", Namespace: "test"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+	}
+	client := &kube.Client{
+		Basic: fake.NewSimpleClientset(service,
+			pod("demo-old-1", "old"), pod("demo-old-2", "old"), pod("demo-new-1", "new")),
+		Dynamic: fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), un),
+	}
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout", Namespace: "test", Name: "demo",
+	}}
+
+	step, err := ability.GetSteps(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, step.Index)
+	assert.Equal(t, 1, step.Total)
+	assert.Equal(t, []int{2}, step.Replicas)
+	assert.True(t, step.AutoPromote)
+	assert.NotNil(t, step.BlueGreen)
+	assert.Equal(t, "demo-old", step.BlueGreen.ActiveReplicaSet)
+	assert.Equal(t, []string{"demo-old-1", "demo-old-2"}, step.BlueGreen.ActivePods)
+	assert.Equal(t, "demo-new", step.BlueGreen.PreviewReplicaSet)
+	assert.Equal(t, []string{"demo-new-1"}, step.BlueGreen.PreviewPods)
+	assert.Equal(t, "demo-preview.test.svc:8080", step.BlueGreen.PreviewEndpoint)
+	assert.False(t, step.BlueGreen.Promoted)
+	assert.NotNil(t, step.BlueGreen.AutoPromoteAt)
+	assert.Equal(t, pausedAt.Add(time.Minute).Unix(), step.BlueGreen.AutoPromoteAt.Unix())
+
+	healthy, err := ability.IsHealthy(node, client)
+	assert.Nil(t, err)
+	assert.False(t, healthy)
+
+	un, err = ability.Action(workload.ActionPromoteFull, un)
+	assert.Nil(t, err)
+	promoteFull, _, _ := unstructured.NestedBool(un.Object, "status", "promoteFull")
+	assert.True(t, promoteFull)
+	_, found, _ := unstructured.NestedSlice(un.Object, "status", "pauseConditions")
+	assert.False(t, found)
+
+	un, err = ability.Action(workload.ActionAbort, un)
+	assert.Nil(t, err)
+	abort, _, _ := unstructured.NestedBool(un.Object, "status", "abort")
+	assert.True(t, abort)
+}
+
+func TestScaleDownPrevious(t *testing.T) {
+	un := blueGreenRollout(t, time.Now())
+	replicaSet := func(name string, annotations map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "ReplicaSet",
+			"metadata": map[string]interface{}{
+				"name":        name,
+				"namespace":   "test",
+				"annotations": annotations,
+				"ownerReferences": []interface{}{map[string]interface{}{
+					"apiVersion": "argoproj.io/v1alpha1",
+					"kind":       "Rollout",
+					"name":       "demo",
+					"uid":        "rollout-uid",
+				}},
+			},
+		}}
+	}
+	deadline := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(),
+		replicaSet("demo-old", map[string]interface{}{
+			rolloutsv1alpha1.DefaultReplicaSetScaleDownDeadlineAnnotationKey: deadline,
+		}),
+		replicaSet("demo-new", map[string]interface{}{}))
+
+	handled, err := ability.UpdateDependents(context.TODO(), workload.ActionPromote, un, client)
+	assert.Nil(t, err)
+	assert.False(t, handled)
+
+	handled, err = ability.UpdateDependents(context.TODO(), workload.ActionScaleDownPrevious, un, client)
+	assert.Nil(t, err)
+	assert.True(t, handled)
+	rs, err := client.Resource(GVRReplicaSet).Namespace("test").Get(context.TODO(), "demo-old", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.NotEqual(t, deadline,
+		rs.GetAnnotations()[rolloutsv1alpha1.DefaultReplicaSetScaleDownDeadlineAnnotationKey])
+
+	// the previous replicaset is scaled down, nothing to do
+	un.SetUID("other-uid")
+	handled, err = ability.UpdateDependents(context.TODO(), workload.ActionScaleDownPrevious, un, client)
+	assert.NotNil(t, err)
+	assert.True(t, handled)
+}
@@ -60,6 +60,10 @@ func (*statefulsets) MatchGK(gk schema.GroupKind) bool {
 	return gk.Group == "apps" && gk.Kind == "StatefulSet"
 }
 
+func (*statefulsets) HibernatableGVR() (schema.GroupVersionResource, bool) {
+	return GVRStatefulSet, true
+}
+
 func (*statefulsets) getStatefulSet(node *v1alpha1.ResourceNode,
 	factory dynamicinformer.DynamicSharedInformerFactory) (*v1.StatefulSet, error) {
 	obj, err := factory.ForResource(GVRStatefulSet).Lister().ByNamespace(node.Namespace).Get(node.Name)
@@ -134,5 +138,11 @@ func (d *statefulsets) ListPods(node *v1alpha1.ResourceNode,
 }
 
 func (*statefulsets) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	switch actionName {
+	case workload.ActionHibernate:
+		return workload.Hibernate(un)
+	case workload.ActionWake:
+		return workload.Wake(un)
+	}
 	return un, nil
 }
@@ -15,6 +15,8 @@
 package workload
 
 import (
+	"time"
+
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -25,9 +27,58 @@ type Step struct {
 	ManualPaused bool
 	AutoPromote  bool
 	Extra        *string
+	// BlueGreen is not nil if the workload is released by the blue-green strategy
+	BlueGreen *BlueGreen
+}
+
+// BlueGreen is the status of a blue-green release, active serves the traffic
+// and preview is the new version to be promoted
+type BlueGreen struct {
+	ActiveReplicaSet  string   `json:"activeReplicaSet"`
+	ActivePods        []string `json:"activePods"`
+	PreviewReplicaSet string   `json:"previewReplicaSet"`
+	PreviewPods       []string `json:"previewPods"`
+	ActiveService     string   `json:"activeService"`
+	PreviewService    string   `json:"previewService,omitempty"`
+	// PreviewEndpoint is the address of the preview service in the k8s cluster
+	PreviewEndpoint string `json:"previewEndpoint,omitempty"`
+	// Promoted is true if the preview replicaset is promoted to be active
+	Promoted bool `json:"promoted"`
+	Aborted  bool `json:"aborted"`
+	// AutoPromoteAt is the time the preview will be promoted automatically, nil if auto promotion is disabled
+	AutoPromoteAt *time.Time `json:"autoPromoteAt,omitempty"`
 }
 
 type Revision struct {
 	Name string
 	Pods []v1.Pod
 }
+
+// statuses of runs
+const (
+	RunStatusRunning   = "Running"
+	RunStatusSucceeded = "Succeeded"
+	RunStatusFailed    = "Failed"
+	RunStatusSuspended = "Suspended"
+)
+
+// Run is a run of batch workloads, such as a job created by a cronjob
+type Run struct {
+	Name           string     `json:"name"`
+	Status         string     `json:"status"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+	Active         int        `json:"active"`
+	Succeeded      int        `json:"succeeded"`
+	Failed         int        `json:"failed"`
+	// Pods are the names of the pods of the run, their logs are the logs of the run
+	Pods []string `json:"pods"`
+}
+
+// NodeRollout is the rollout progress of the pod on a node of workloads running a pod per node
+type NodeRollout struct {
+	Node    string `json:"node"`
+	Pod     string `json:"pod"`
+	Updated bool   `json:"updated"`
+	Ready   bool   `json:"ready"`
+}
@@ -17,7 +17,11 @@ package workload
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -46,3 +50,91 @@ func ObjUnmarshal(obj runtime.Object, container interface{}) error {
 
 	return runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, container)
 }
+
+const (
+	// ActionHibernate scales the workload to zero replicas
+	ActionHibernate = "hibernate"
+	// ActionWake restores the replicas of a hibernated workload
+	ActionWake = "wake"
+	// ActionRestart restarts the pods of the workload by updating the annotation of its pod template
+	ActionRestart = "restart"
+	// ActionSuspend and ActionResume suspend and resume batch workloads
+	ActionSuspend = "suspend"
+	ActionResume  = "resume"
+	// ActionTrigger runs a cronjob now
+	ActionTrigger = "trigger"
+	// ActionPromote, ActionPromoteFull and ActionAbort promote or abort the release of a rollout
+	ActionPromote     = "promote"
+	ActionPromoteFull = "promote-full"
+	ActionAbort       = "abort"
+	// ActionScaleDownPrevious scales down the previous replicaset kept by a blue-green rollout immediately
+	ActionScaleDownPrevious = "scale-down-previous"
+
+	// RestartedAtAnnotation is the annotation of pod templates updated by restart, same as kubectl
+	RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+	// HibernatedReplicasAnnotation records the replicas before hibernation
+	HibernatedReplicasAnnotation = "cloudnative.music.netease.com/hibernated-replicas"
+)
+
+// Hibernate scales the workload to zero and keeps its replicas in an annotation,
+// hibernating a workload which is already hibernated changes nothing
+func Hibernate(un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	annotations := un.GetAnnotations()
+	if _, ok := annotations[HibernatedReplicasAnnotation]; ok {
+		return un, nil
+	}
+	replicas, found, err := unstructured.NestedInt64(un.Object, "spec", "replicas")
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid replicas: %v", err)
+	}
+	if !found {
+		replicas = 1
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[HibernatedReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+	un.SetAnnotations(annotations)
+	if err := unstructured.SetNestedField(un.Object, int64(0), "spec", "replicas"); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set replicas: %v", err)
+	}
+	return un, nil
+}
+
+// Wake restores the replicas kept by Hibernate,
+// waking a workload which is not hibernated changes nothing
+func Wake(un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	annotations := un.GetAnnotations()
+	value, ok := annotations[HibernatedReplicasAnnotation]
+	if !ok {
+		return un, nil
+	}
+	replicas, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid hibernated replicas: %v", value)
+	}
+	if err := unstructured.SetNestedField(un.Object, replicas, "spec", "replicas"); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set replicas: %v", err)
+	}
+	delete(annotations, HibernatedReplicasAnnotation)
+	un.SetAnnotations(annotations)
+	return un, nil
+}
+
+// Restart updates the restarted time in the annotations of the pod template, so the pods are recreated
+func Restart(un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if err := unstructured.SetNestedField(un.Object, time.Now().Format(time.RFC3339),
+		"spec", "template", "metadata", "annotations", RestartedAtAnnotation); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set restarted time: %v", err)
+	}
+	return un, nil
+}
+
+// Suspend sets spec.suspend of batch workloads
+func Suspend(un *unstructured.Unstructured, suspend bool) (*unstructured.Unstructured, error) {
+	if err := unstructured.SetNestedField(un.Object, suspend, "spec", "suspend"); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set suspend: %v", err)
+	}
+	return un, nil
+}
@@ -0,0 +1,53 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestHibernateAndWake(t *testing.T) {
+	un := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "test"},
+		"spec":     map[string]interface{}{"replicas": int64(3)},
+	}}
+
+	// waking a workload which is not hibernated changes nothing
+	un, err := Wake(un)
+	assert.Nil(t, err)
+	replicas, _, _ := unstructured.NestedInt64(un.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+
+	un, err = Hibernate(un)
+	assert.Nil(t, err)
+	replicas, _, _ = unstructured.NestedInt64(un.Object, "spec", "replicas")
+	assert.Equal(t, int64(0), replicas)
+	assert.Equal(t, "3", un.GetAnnotations()[HibernatedReplicasAnnotation])
+
+	// hibernating twice keeps the original replicas
+	un, err = Hibernate(un)
+	assert.Nil(t, err)
+	assert.Equal(t, "3", un.GetAnnotations()[HibernatedReplicasAnnotation])
+
+	un, err = Wake(un)
+	assert.Nil(t, err)
+	replicas, _, _ = unstructured.NestedInt64(un.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	_, ok := un.GetAnnotations()[HibernatedReplicasAnnotation]
+	assert.False(t, ok)
+}
@@ -15,7 +15,9 @@
 package workload
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -24,14 +26,21 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 )
 
-var abilities = make([]Workload, 0, 4)
+var (
+	// mu protects abilities and Resources, abilities defined declaratively are registered at runtime
+	mu        sync.RWMutex
+	abilities = make([]Workload, 0, 4)
+)
 
 var Resources = make([]regioninformers.Resource, 0, 16)
 
 func Register(ability Workload, gvrs ...schema.GroupVersionResource) {
+	mu.Lock()
+	defer mu.Unlock()
 	abilities = append(abilities, ability)
 	gvrsUnderResource := make([]regioninformers.Resource, 0, len(gvrs))
 	for _, gvr := range gvrs {
@@ -43,6 +52,8 @@ func Register(ability Workload, gvrs ...schema.GroupVersionResource) {
 }
 
 func GetAbility(gk schema.GroupKind) (Workload, error) {
+	mu.RLock()
+	defer mu.RUnlock()
 	for _, ability := range abilities {
 		if ability.MatchGK(gk) {
 			return ability, nil
@@ -55,8 +66,13 @@ func GetAbility(gk schema.GroupKind) (Workload, error) {
 type Handler func(workload Workload) bool
 
 func LoopAbilities(handlers ...Handler) {
+	mu.RLock()
+	snapshot := make([]Workload, len(abilities))
+	copy(snapshot, abilities)
+	mu.RUnlock()
+
 	for _, handler := range handlers {
-		for _, ability := range abilities {
+		for _, ability := range snapshot {
 			if !handler(ability) {
 				break
 			}
@@ -84,3 +100,40 @@ type HealthStatusGetter interface {
 	Workload
 	IsHealthy(node *v1alpha1.ResourceNode, client *kube.Client) (bool, error)
 }
+
+// RunsLister lists the runs of batch workloads, the latest run comes first
+type RunsLister interface {
+	Workload
+	ListRuns(node *v1alpha1.ResourceNode,
+		factory dynamicinformer.DynamicSharedInformerFactory) ([]Run, error)
+}
+
+// NodeRolloutGetter gets the rollout progress per node of workloads running a pod per node
+type NodeRolloutGetter interface {
+	Workload
+	GetNodeRollouts(node *v1alpha1.ResourceNode,
+		factory dynamicinformer.DynamicSharedInformerFactory) ([]NodeRollout, error)
+}
+
+// Hibernator is the workload scaled to zero by ActionHibernate and restored by ActionWake.
+// HibernatableGVR returns the resource to execute the actions on, or false if the workload is not hibernatable.
+type Hibernator interface {
+	Workload
+	HibernatableGVR() (schema.GroupVersionResource, bool)
+}
+
+// Spawner creates new objects from the workload by actions, such as a job triggered from a cronjob.
+// The object is nil if the action does not spawn, then the action is executed by Action.
+type Spawner interface {
+	Workload
+	Spawn(aName string, un *unstructured.Unstructured) (schema.GroupVersionResource,
+		*unstructured.Unstructured, error)
+}
+
+// DependentsUpdater executes actions on the objects depending on the workload, such as scaling down
+// the previous replicaset of a rollout. The workload itself is not updated if the action is handled.
+type DependentsUpdater interface {
+	Workload
+	UpdateDependents(ctx context.Context, aName string, un *unstructured.Unstructured,
+		client dynamic.Interface) (bool, error)
+}